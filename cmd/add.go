@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,19 +14,40 @@ import (
 	"gpm.sh/gpm/gpm-cli/internal/api"
 	"gpm.sh/gpm/gpm-cli/internal/config"
 	"gpm.sh/gpm/gpm-cli/internal/engines"
+	"gpm.sh/gpm/gpm-cli/internal/packaging"
 	"gpm.sh/gpm/gpm-cli/internal/styling"
 	"gpm.sh/gpm/gpm-cli/internal/validation"
 )
 
 var (
-	addProject  string
-	addEngine   string
-	addRegistry string
-	addJSON     bool
+	addProject          string
+	addEngine           string
+	addRegistry         string
+	addJSON             bool
+	addJSONStream       bool
+	addPackagesDir      string
+	addSideBySide       bool
+	addEngineStrict     bool
+	addDryRun           bool
+	addForce            bool
+	addNormalize        bool
+	addSaveBundle       string
+	addNoScopedRegistry bool
+	addRegistryTimeout  time.Duration
+	addTag              string
+	addVerify           bool
+	addYes              bool
+	addNoInteractive    bool
+
+	// addEvents receives --json-stream package events; nil otherwise
+	addEvents *eventStream
+	// addInteractive allows offering similar packages for a name that isn't
+	// found; it is off under --json, --json-stream, --yes and --no-interactive
+	addInteractive bool
 )
 
 var addCmd = &cobra.Command{
-	Use:   "add <package[@version]>",
+	Use:   "add <package[@version]>...",
 	Short: "Add a package to a game project",
 	Long: `Add a package to a game project with Unity as first priority.
 
@@ -34,87 +57,271 @@ This command adds a package to your game project, automatically detecting the en
 Examples:
   gpm add com.unity.analytics          # Add latest version
   gpm add com.unity.analytics@2.1.0    # Add specific version
+  gpm add com.unity.analytics --tag beta  # Add the version tagged beta instead of latest
+  gpm add com.company.sdk com.company.ads  # Add several packages
   gpm add com.company.sdk --engine unity  # Force Unity engine
   gpm add com.package.name --project ./my-project  # Specify project path
-  gpm add com.package.name --registry https://custom.gpm.sh  # Override registry`,
-	Args: cobra.ExactArgs(1),
+  gpm add com.package.name --registry https://custom.gpm.sh  # Override registry
+  gpm add com.package.name --packages-dir UPM/Packages  # Relocated Unity packages directory
+  gpm add com.package.name@2.0.0 --side-by-side  # Keep installed versions (engines that allow it)
+  gpm add com.package.name --engine-strict  # Fail if the package's engines constraints aren't met
+  gpm add com.package.name --dry-run --json  # Preview the manifest changes as JSON
+  gpm add com.company.sdk com.company.ads --json-stream  # One JSON line per package event, then a summary
+  gpm add com.package.name --normalize  # Reindent the manifest with two spaces
+  gpm add com.company.sdk --save-bundle core-tools  # Also record it in a bundle
+  gpm add com.company.sdk --verify  # Check the manifest afterwards, rolling back on problems
+  gpm add com.company.sdk --no-scoped-registry  # Don't touch scopedRegistries
+  gpm add com.company.skd --yes  # Fail on an unknown name instead of offering similar packages
+  gpm add https://registry.gpm.sh/com.package.name/-/com.package.name-1.0.0.tgz  # Add a tarball URL
+  gpm add my-ui@npm:com.vendor.ui@1.0.0  # Record com.vendor.ui under the key my-ui (not Unity)`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: runAddCommand,
 }
 
 type AddOutput struct {
-	Success    bool           `json:"success"`
-	Engine     string         `json:"engine"`
-	Project    string         `json:"project"`
-	Package    string         `json:"package"`
-	Version    string         `json:"version"`
-	Registry   string         `json:"registry"`
-	Changed    bool           `json:"changed"`
-	BackupPath string         `json:"backup_path,omitempty"`
-	Message    string         `json:"message"`
-	Details    map[string]any `json:"details,omitempty"`
-	Error      string         `json:"error,omitempty"`
+	Success    bool                  `json:"success"`
+	Engine     string                `json:"engine"`
+	Project    string                `json:"project"`
+	Package    string                `json:"package"`
+	Alias      string                `json:"alias,omitempty"`
+	Version    string                `json:"version"`
+	Registry   string                `json:"registry"`
+	Changed    bool                  `json:"changed"`
+	BackupPath string                `json:"backup_path,omitempty"`
+	Message    string                `json:"message"`
+	DryRun     bool                  `json:"dry_run,omitempty"`
+	Diff       *engines.ManifestDiff `json:"diff,omitempty"`
+	Details    map[string]any        `json:"details,omitempty"`
+	Warnings   []string              `json:"warnings,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	// Policy explains a package refused by the allowlist or blocklist
+	Policy *config.PolicyError `json:"policy,omitempty"`
 }
 
 func init() {
-	addCmd.Flags().StringVar(&addProject, "project", "", "Project path (default: current directory)")
-	addCmd.Flags().StringVar(&addEngine, "engine", "auto", "Engine type: unity, godot, unreal, auto")
+	addCmd.Flags().StringVar(&addProject, "project", "", "Project path (default: current directory; same as the global --cwd)")
+	addCmd.Flags().StringVar(&addEngine, "engine", "auto", "Engine type: unity, godot, unreal, auto, or any registered engine")
 	addCmd.Flags().StringVar(&addRegistry, "registry", "", "Override registry URL")
 	addCmd.Flags().BoolVar(&addJSON, "json", false, "Output results in JSON format")
+	addCmd.Flags().BoolVar(&addJSONStream, "json-stream", false, "Stream newline-delimited JSON: one object per package event (started, resolved, downloaded, installed, failed), then a summary")
+	addCmd.Flags().StringVar(&addPackagesDir, "packages-dir", "", "Unity packages directory relative to the project (default: $"+engines.UnityPackagesDirEnv+" or Packages)")
+	addCmd.Flags().BoolVar(&addEngineStrict, "engine-strict", false, "Fail instead of warning when the package's engines constraints are not met")
+	addCmd.Flags().BoolVar(&addSideBySide, "side-by-side", false, "Install alongside existing versions instead of replacing them (not supported by Unity)")
+	addCmd.Flags().BoolVar(&addDryRun, "dry-run", false, "Show the manifest changes without writing them")
+	addCmd.Flags().BoolVar(&addForce, "force", false, "Move the package's scope to this registry when another scoped registry already claims it, and replace a manifest.json Unity can't load")
+	addCmd.Flags().BoolVar(&addNormalize, "normalize", false, "Rewrite the manifest with two-space indentation instead of keeping its existing style")
+	addCmd.Flags().BoolVar(&addNoScopedRegistry, "no-scoped-registry", false, "Only update dependencies; leave the Unity manifest's scopedRegistries alone (default: auto_scoped_registry config)")
+	addCmd.Flags().StringVar(&addSaveBundle, "save-bundle", "", "Record the added packages in a bundle in "+BundleFile+", creating it if needed")
+	addCmd.Flags().StringVar(&addTag, "tag", "", "Dist-tag to resolve packages given without a version through (default: default_tag config or latest)")
+	addCmd.Flags().BoolVar(&addVerify, "verify", false, "Re-read the manifest after adding and roll back if the dependency or scoped registry is wrong, or a tarball names another package")
+	addCmd.Flags().BoolVarP(&addYes, "yes", "y", false, "Never prompt; an unknown package name fails instead of offering similar packages")
+	addCmd.Flags().BoolVar(&addNoInteractive, "no-interactive", false, "Don't offer similar packages to pick from when a name isn't found")
+	addCmd.Flags().DurationVar(&addRegistryTimeout, "registry-timeout", 0, "Fail fast when the registry can't be reached within this time (default: registry_timeout config or 3s)")
 }
 
 func runAddCommand(cmd *cobra.Command, args []string) error {
-	packageSpec := args[0]
-
-	output := &AddOutput{
-		Success: false,
-		Details: make(map[string]any),
-	}
-
 	// Check if JSON flag was set for this specific command execution
 	useJSON, _ := cmd.Flags().GetBool("json")
+	useJSONStream, _ := cmd.Flags().GetBool("json-stream")
 
 	// Get flag values before resetting global variables
 	projectFlag, _ := cmd.Flags().GetString("project")
 	engineFlag, _ := cmd.Flags().GetString("engine")
 	registryFlag, _ := cmd.Flags().GetString("registry")
+	packagesDirFlag, _ := cmd.Flags().GetString("packages-dir")
+	sideBySideFlag, _ := cmd.Flags().GetBool("side-by-side")
+	engineStrictFlag, _ := cmd.Flags().GetBool("engine-strict")
+	dryRunFlag, _ := cmd.Flags().GetBool("dry-run")
+	forceFlag, _ := cmd.Flags().GetBool("force")
+	normalizeFlag, _ := cmd.Flags().GetBool("normalize")
+	saveBundleFlag, _ := cmd.Flags().GetString("save-bundle")
+	noScopedRegistryFlag, _ := cmd.Flags().GetBool("no-scoped-registry")
+	registryTimeoutFlag, _ := cmd.Flags().GetDuration("registry-timeout")
+	tagFlag, _ := cmd.Flags().GetString("tag")
+	verifyFlag, _ := cmd.Flags().GetBool("verify")
+	yesFlag, _ := cmd.Flags().GetBool("yes")
+	noInteractiveFlag, _ := cmd.Flags().GetBool("no-interactive")
 
 	// Reset global variables after getting flag values to avoid contamination
 	addProject = ""
 	addEngine = "auto"
 	addRegistry = ""
 	addJSON = false
+	addJSONStream = false
+	addPackagesDir = ""
+	addSideBySide = false
+	addEngineStrict = false
+	addDryRun = false
+	addForce = false
+	addNormalize = false
+	addSaveBundle = ""
+	addNoScopedRegistry = false
+	addRegistryTimeout = 0
+	addTag = ""
+	addVerify = false
+	addYes = false
+	addNoInteractive = false
+
+	if useJSON && useJSONStream {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("--json and --json-stream can't be combined"),
+			styling.Hint("Use --json for one result object or --json-stream for a line per package event"))
+	}
+	if useJSONStream {
+		addEvents = newEventStream(cmd.OutOrStdout())
+		defer func() { addEvents = nil }()
+	}
+	addInteractive = !useJSON && !useJSONStream && !yesFlag && !noInteractiveFlag
+	defer func() { addInteractive = false }()
+
+	opts := addOptions{
+		project:          projectFlag,
+		engine:           engineFlag,
+		registry:         registryFlag,
+		packagesDir:      packagesDirFlag,
+		sideBySide:       sideBySideFlag,
+		engineStrict:     engineStrictFlag,
+		dryRun:           dryRunFlag,
+		force:            forceFlag,
+		normalize:        normalizeFlag,
+		noScopedRegistry: noScopedRegistryFlag || !config.AutoScopedRegistryEnabled(),
+		verify:           verifyFlag,
+		registryTimeout:  registryTimeoutFlag,
+		defaultTag:       defaultDistTag(tagFlag),
+	}
+
+	// Add each package independently so one failure doesn't stop the rest
+	outputs := make([]*AddOutput, 0, len(args))
+	var errs []error
+	for _, packageSpec := range args {
+		output := &AddOutput{
+			Success: false,
+			Package: packageSpec,
+			Details: make(map[string]any),
+		}
+		addEvents.emit(StreamEvent{Event: EventStarted, Package: packageSpec, Project: projectFlag})
+		if err := executeAddWithFlags(packageSpec, output, opts); err != nil {
+			output.Error = err.Error()
+			errors.As(err, &output.Policy)
+			errs = append(errs, err)
+			addEvents.fail(packageSpec, output.Project, err)
+		} else {
+			output.Success = true
+			addEvents.emit(StreamEvent{Event: EventInstalled, Package: output.Package, Version: output.Version, Project: output.Project})
+		}
+		outputs = append(outputs, output)
+	}
+
+	// Record what was added so the set can be reinstalled with --bundle
+	if saveBundleFlag != "" && !dryRunFlag {
+		members := make(map[string]string)
+		projectPath := ""
+		for _, output := range outputs {
+			if output.Success {
+				members[output.Package] = output.Version
+				projectPath = output.Project
+			}
+		}
+		if len(members) > 0 {
+			if err := saveToBundle(projectPath, saveBundleFlag, members); err != nil {
+				return fmt.Errorf("packages were added but saving bundle %q failed: %w", saveBundleFlag, err)
+			}
+			if !useJSON && !useJSONStream {
+				cmd.Printf("%s %s (%d packages)\n", styling.Success("Saved to bundle:"), styling.Value(saveBundleFlag), len(members))
+			}
+		}
+	}
+
+	if useJSONStream {
+		return finishAddStream(outputs, errs)
+	}
 
-	if err := executeAddWithFlags(packageSpec, output, projectFlag, engineFlag, registryFlag); err != nil {
-		output.Error = err.Error()
+	// A single package keeps the original one-object output and error
+	if len(outputs) == 1 {
+		if len(errs) > 0 {
+			if useJSON {
+				_ = printAddJSON(cmd, outputs[0])
+			}
+			return errs[0] // Return error to set proper exit code
+		}
 		if useJSON {
-			_ = printAddJSON(cmd, output)
-			return err // Return error to set proper exit code
+			return printAddJSON(cmd, outputs[0])
 		}
-		return err
+		return printAddHuman(cmd, outputs[0])
 	}
 
-	output.Success = true
 	if useJSON {
-		return printAddJSON(cmd, output)
+		if err := printAddJSON(cmd, outputs); err != nil {
+			return err
+		}
+	} else {
+		for _, output := range outputs {
+			if output.Success {
+				_ = printAddHuman(cmd, output)
+			} else {
+				cmd.Printf("%s %s: %s\n", styling.Error("✗"), output.Package, output.Error)
+			}
+		}
+		cmd.Printf("%s\n", styling.Separator())
+		cmd.Printf("%s %s\n", styling.Label("Summary:"), styling.Value(fmt.Sprintf("%d succeeded, %d failed", len(outputs)-len(errs), len(errs))))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to add %d of %d packages", len(errs), len(outputs))
 	}
+	return nil
+}
 
-	return printAddHuman(cmd, output)
+// addOptions holds the add command's flags, read once per run and shared by
+// every package it adds
+type addOptions struct {
+	project          string
+	engine           string
+	registry         string
+	packagesDir      string
+	sideBySide       bool
+	engineStrict     bool
+	dryRun           bool
+	force            bool
+	normalize        bool
+	noScopedRegistry bool
+	verify           bool
+	registryTimeout  time.Duration
+	defaultTag       string
 }
 
-func executeAddWithFlags(packageSpec string, output *AddOutput, projectFlag, engineFlag, registryFlag string) error {
-	// Parse package specification
-	packageName, version, err := parseAddPackageSpec(packageSpec)
-	if err != nil {
-		return fmt.Errorf("invalid package specification: %w", err)
+func executeAddWithFlags(packageSpec string, output *AddOutput, opts addOptions) error {
+	// An npm: alias installs the package under another dependency key
+	alias, packageSpec := splitAliasSpec(packageSpec)
+	if alias != "" {
+		if isTarballURL(packageSpec) {
+			return fmt.Errorf("invalid package specification: an npm: alias must name a registry package")
+		}
+		if err := validation.ValidatePackageName(alias); err != nil {
+			return fmt.Errorf("invalid alias: %w", err)
+		}
+	}
+
+	// Parse package specification; a tarball URL names its package inside
+	var packageName, version string
+	var err error
+	if !isTarballURL(packageSpec) {
+		packageName, version, err = parseAddPackageSpec(packageSpec)
+		if err != nil {
+			return fmt.Errorf("invalid package specification: %w", err)
+		}
+		if err := config.CheckPackagePolicy(packageName); err != nil {
+			return err
+		}
 	}
 
 	output.Package = packageName
+	output.Alias = alias
 	output.Version = version
 
 	// Determine project path
-	projectPath := projectFlag
+	projectPath := opts.project
 	if projectPath == "" {
-		var err error
 		projectPath, err = os.Getwd()
 		if err != nil {
 			return fmt.Errorf("failed to get current directory: %w", err)
@@ -128,17 +335,25 @@ func executeAddWithFlags(packageSpec string, output *AddOutput, projectFlag, eng
 	output.Project = projectPath
 
 	// Detect or validate engine
-	engineType, err := detectOrValidateEngine(projectPath, engineFlag)
+	engineType, err := detectOrValidateEngine(projectPath, opts.engine)
 	if err != nil {
 		return err
 	}
 	output.Engine = string(engineType)
+	if alias != "" && engineType == engines.EngineUnity {
+		// Checked before any network calls; the adapter rejects it too
+		return fmt.Errorf("unity requires manifest dependencies to use the package's own name, so %s can't be added as %s", packageName, alias)
+	}
 
 	// Get engine adapter
 	adapter, err := engines.GetAdapter(engineType)
 	if err != nil {
 		return fmt.Errorf("engine adapter not available: %w", err)
 	}
+	if unityAdapter, ok := adapter.(*engines.UnityAdapter); ok {
+		unityAdapter.SetPackagesDir(opts.packagesDir)
+		unityAdapter.SetStateDir(projectStateDir(projectPath))
+	}
 
 	// Validate project for the detected engine
 	if err := adapter.ValidateProject(projectPath); err != nil {
@@ -146,9 +361,9 @@ func executeAddWithFlags(packageSpec string, output *AddOutput, projectFlag, eng
 	}
 
 	// Determine registry
-	registryURL := registryFlag
+	registryURL := opts.registry
 	if registryURL == "" {
-		if registryURL, err = getConfiguredRegistry(); err != nil {
+		if registryURL, err = getConfiguredRegistry(projectPath); err != nil {
 			return fmt.Errorf("no registry configured. Please run 'gpm config set registry <url>' or use --registry flag")
 		}
 		if registryURL == "" {
@@ -157,13 +372,22 @@ func executeAddWithFlags(packageSpec string, output *AddOutput, projectFlag, eng
 	}
 	output.Registry = registryURL
 
+	if isTarballURL(packageSpec) {
+		return addFromTarball(adapter, output, projectPath, engineType, packageSpec, registryURL, opts)
+	}
+
 	// Validate package name first (before any network calls)
 	if err := validation.ValidatePackageName(packageName); err != nil {
 		return fmt.Errorf("invalid package name: %w", err)
 	}
 
+	// Fail fast when the registry is down instead of waiting out the client timeout
+	if err := checkRegistryReachable(registryURL, opts.registryTimeout); err != nil {
+		return err
+	}
+
 	// Query registry for package metadata - fail fast if package doesn't exist
-	client := api.NewClient(registryURL, "")
+	client := api.NewClient(registryURL, "").WithContext(commandCtx)
 
 	// Check if package exists in registry
 	packageExists, err := client.CheckPackageExists(packageName)
@@ -171,43 +395,146 @@ func executeAddWithFlags(packageSpec string, output *AddOutput, projectFlag, eng
 		return fmt.Errorf("failed to check package existence: %w", err)
 	}
 	if !packageExists {
-		return fmt.Errorf("package '%s' not found in registry", packageName)
+		notFound := fmt.Errorf("package '%s' not found in registry", packageName)
+		chosen, err := suggestPackage(registryURL, packageName)
+		if err != nil || chosen == "" {
+			return notFound
+		}
+		if err := validation.ValidatePackageName(chosen); err != nil {
+			return notFound
+		}
+		if err := config.CheckPackagePolicy(chosen); err != nil {
+			return err
+		}
+		packageName = chosen
+		output.Package = chosen
 	}
 
-	// Resolve and validate version
-	resolvedVersion, err := client.ResolvePackageVersion(packageName, version)
+	// Resolve and validate version; a bare name goes through the default tag
+	var resolution *api.VersionResolution
+	if version == "" && opts.defaultTag != "" {
+		resolution, err = client.ResolveTaggedVersion(packageName, opts.defaultTag)
+	} else {
+		resolution, err = client.ResolveVersion(packageName, version)
+	}
 	if err != nil {
 		return err // Error messages are already descriptive
 	}
-	version = resolvedVersion
+	if resolution.MissingTag != "" {
+		output.Warnings = append(output.Warnings, fmt.Sprintf("%s has no %q dist-tag; using latest (%s)", packageName, resolution.MissingTag, resolution.Version))
+	}
+	if resolution.SkippedYanked != "" {
+		output.Warnings = append(output.Warnings, fmt.Sprintf("%s@%s has been yanked; using %s instead", packageName, resolution.SkippedYanked, resolution.Version))
+	}
+	if resolution.Yanked {
+		output.Warnings = append(output.Warnings, fmt.Sprintf("%s@%s has been yanked by its publisher; installing it because it was requested explicitly", packageName, resolution.Version))
+	}
+	version = resolution.Version
 	output.Version = version
+	addEvents.emit(StreamEvent{Event: EventResolved, Package: packageName, Version: version, Project: output.Project})
+
+	// Check the package's declared engines against this project
+	compatWarnings, err := checkEngineCompatibility(resolution.Info, compatibilityEnvironment(projectPath, engineType), opts.engineStrict)
+	if err != nil {
+		return err
+	}
+	output.Warnings = append(output.Warnings, compatWarnings...)
+
+	installReq := &engines.PackageInstallRequest{
+		Name:             packageName,
+		Version:          version,
+		Registry:         registryURL,
+		SideBySide:       opts.sideBySide,
+		Force:            opts.force,
+		Normalize:        opts.normalize,
+		NoScopedRegistry: opts.noScopedRegistry,
+		Alias:            alias,
+	}
 
 	// Check if package is already installed with same version
-	existingInfo, _ := adapter.GetPackageInfo(projectPath, packageName)
-	if existingInfo != nil && existingInfo.Version == version {
+	if isVersionInstalled(adapter, projectPath, installReq.DependencyKey(), version) {
 		output.Changed = false
-		output.Message = fmt.Sprintf("Package %s@%s is already installed", packageName, version)
+		output.Message = fmt.Sprintf("Package %s@%s is already installed", installReq.DependencyKey(), version)
+		if opts.dryRun {
+			output.DryRun = true
+			output.Diff = engines.NewManifestDiff()
+		} else if warning := recordPackageOrigin(adapter, projectPath, installReq.DependencyKey(), resolutionOrigin(registryURL, resolution)); warning != "" {
+			output.Warnings = append(output.Warnings, warning)
+		}
+		return nil
+	}
+
+	if opts.noScopedRegistry && engineType == engines.EngineUnity {
+		output.Warnings = append(output.Warnings, scopedRegistrySkippedWarning(packageName, registryURL))
+	}
+
+	// Compute the manifest change in memory and report it without writing
+	if opts.dryRun {
+		installReq.DryRun = true
+		result, err := adapter.InstallPackage(projectPath, installReq)
+		if err != nil {
+			return fmt.Errorf("package installation would fail: %w", err)
+		}
+		output.DryRun = true
+		output.Diff = result.Diff
+		output.Message = result.Message
+		output.Warnings = append(output.Warnings, result.Warnings...)
 		return nil
 	}
 
+	// Unity fetches registry packages itself; other engines get the files
+	// from gpm, so the tarball is downloaded before the manifest changes
+	var tarballPath string
+	if engineType != engines.EngineUnity {
+		tarballURL, err := versionTarballURL(client, packageName, version)
+		if err != nil {
+			return err
+		}
+		if tarballPath, err = fetchTarball(commandCtx, tarballURL, registryURL); err != nil {
+			return err
+		}
+		defer func() { _ = os.Remove(tarballPath) }()
+		info, err := packaging.ExtractPackageInfo(tarballPath)
+		if err != nil {
+			return fmt.Errorf("failed to read package.json from tarball: %w", err)
+		}
+		warning, err := checkPackageIdentity(packageName, version, info, opts.force)
+		if err != nil {
+			return err
+		}
+		if warning != "" {
+			output.Warnings = append(output.Warnings, warning)
+		}
+		addEvents.emit(StreamEvent{Event: EventDownloaded, Package: packageName, Version: version, Project: output.Project})
+
+		stagingDir, err := packageStagingDir(adapter, projectPath)
+		if err != nil {
+			return err
+		}
+		contentsDir, cleanup, err := stagePackage(tarballPath, stagingDir)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		installReq.ContentsDir = contentsDir
+	}
+
 	// Create backup before making changes
-	backupPath, err := createProjectBackup(projectPath, engineType)
+	backupPath, err := createProjectBackup(projectPath, engineType, opts.packagesDir)
 	if err != nil {
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
 	output.BackupPath = backupPath
 
 	// Install package
-	installReq := &engines.PackageInstallRequest{
-		Name:     packageName,
-		Version:  version,
-		Registry: registryURL,
-	}
-
 	result, err := adapter.InstallPackage(projectPath, installReq)
 	if err != nil {
+		// Nothing was written to a read-only project, so there's nothing to restore
+		if readOnlyErr := readOnlyProjectError(err); readOnlyErr != nil {
+			return readOnlyErr
+		}
 		// Attempt to restore from backup
-		if restoreErr := restoreFromBackup(backupPath, projectPath, engineType); restoreErr != nil {
+		if restoreErr := restoreFromBackup(backupPath, projectPath, engineType, opts.packagesDir); restoreErr != nil {
 			return fmt.Errorf("package installation failed and backup restore failed: install error: %w, restore error: %v", err, restoreErr)
 		}
 		return fmt.Errorf("package installation failed (restored from backup): %w", err)
@@ -216,9 +543,18 @@ func executeAddWithFlags(packageSpec string, output *AddOutput, projectFlag, eng
 	if !result.Success {
 		return fmt.Errorf("package installation was not successful: %s", result.Message)
 	}
+	if opts.verify {
+		if err := verifyInstall(adapter, projectPath, installReq, result); err != nil {
+			return undoVerifiedInstall(err, backupPath, projectPath, engineType, opts.packagesDir, "")
+		}
+	}
 
 	output.Changed = true
 	output.Message = result.Message
+	output.Warnings = append(output.Warnings, result.Warnings...)
+	if warning := recordPackageOrigin(adapter, projectPath, installReq.DependencyKey(), resolutionOrigin(registryURL, resolution)); warning != "" {
+		output.Warnings = append(output.Warnings, warning)
+	}
 	if result.Details != nil {
 		for k, v := range result.Details {
 			output.Details[k] = v
@@ -228,6 +564,95 @@ func executeAddWithFlags(packageSpec string, output *AddOutput, projectFlag, eng
 	return nil
 }
 
+// addFromTarball adds a package straight from a tarball URL. The tarball is
+// downloaded first so its package.json can name the package; the manifest
+// change is rolled back from backup on failure. --verify also rejects a tarball
+// whose package.json names another package than its URL.
+func addFromTarball(adapter engines.EngineAdapter, output *AddOutput, projectPath string, engineType engines.EngineType, tarballURL, registryURL string, opts addOptions) error {
+	tarballPath, err := fetchTarball(commandCtx, tarballURL, registryURL)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tarballPath) }()
+	addEvents.emit(StreamEvent{Event: EventDownloaded, Package: tarballURL, Project: output.Project})
+
+	info, err := packaging.ExtractPackageInfo(tarballPath)
+	if err != nil {
+		return fmt.Errorf("failed to read package.json from tarball: %w", err)
+	}
+	output.Package = info.Name
+	output.Version = info.Version
+	if err := config.CheckPackagePolicy(info.Name); err != nil {
+		return err
+	}
+	if opts.verify {
+		if err := checkTarballName(tarballURL, info.Name); err != nil {
+			return err
+		}
+	}
+
+	if isVersionInstalled(adapter, projectPath, info.Name, info.Version) {
+		output.Changed = false
+		output.Message = fmt.Sprintf("Package %s@%s is already installed", info.Name, info.Version)
+		if opts.dryRun {
+			output.DryRun = true
+			output.Diff = engines.NewManifestDiff()
+		}
+		return nil
+	}
+
+	installReq := &engines.PackageInstallRequest{
+		SideBySide: opts.sideBySide,
+		DryRun:     opts.dryRun,
+		Force:      opts.force,
+		Normalize:  opts.normalize,
+	}
+	if opts.dryRun {
+		result, err := installTarball(adapter, projectPath, tarballPath, installReq, false)
+		if err != nil {
+			return fmt.Errorf("package installation would fail: %w", err)
+		}
+		output.DryRun = true
+		output.Diff = result.Diff
+		output.Message = result.Message
+		output.Warnings = append(output.Warnings, result.Warnings...)
+		return nil
+	}
+
+	backupPath, err := createProjectBackup(projectPath, engineType, opts.packagesDir)
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	output.BackupPath = backupPath
+
+	result, err := installTarball(adapter, projectPath, tarballPath, installReq, false)
+	if err != nil {
+		if restoreErr := restoreFromBackup(backupPath, projectPath, engineType, opts.packagesDir); restoreErr != nil {
+			return fmt.Errorf("package installation failed and backup restore failed: install error: %w, restore error: %v", err, restoreErr)
+		}
+		return fmt.Errorf("package installation failed (restored from backup): %w", err)
+	}
+	if opts.verify {
+		if err := verifyInstall(adapter, projectPath, installReq, result); err != nil {
+			return undoVerifiedInstall(err, backupPath, projectPath, engineType, opts.packagesDir, embeddedPackageDir(adapter, result))
+		}
+	}
+
+	output.Changed = true
+	output.Message = result.Message
+	output.Warnings = append(output.Warnings, result.Warnings...)
+	for k, v := range result.Details {
+		output.Details[k] = v
+	}
+	return nil
+}
+
+// scopedRegistrySkippedWarning reminds the user that, with scoped registry
+// injection off, Unity must already know where to find the package
+func scopedRegistrySkippedWarning(packageName, registryURL string) string {
+	return fmt.Sprintf("scopedRegistries left unchanged: make sure Unity can resolve %s (from %s) through a scoped registry you manage", packageName, registryURL)
+}
+
 func detectOrValidateEngine(projectPath, engineFlag string) (engines.EngineType, error) {
 	if engineFlag != "auto" {
 		// Validate specified engine
@@ -239,6 +664,9 @@ func detectOrValidateEngine(projectPath, engineFlag string) (engines.EngineType,
 		case "unreal":
 			return engines.EngineUnreal, nil
 		default:
+			if engines.HasAdapter(engines.EngineType(engineFlag)) {
+				return engines.EngineType(engineFlag), nil
+			}
 			return engines.EngineUnknown, fmt.Errorf("unsupported engine: %s", engineFlag)
 		}
 	}
@@ -266,13 +694,104 @@ func detectOrValidateEngine(projectPath, engineFlag string) (engines.EngineType,
 	switch best.Engine {
 	case engines.EngineUnity:
 		return engines.EngineUnity, nil
-	case engines.EngineUnreal, engines.EngineGodot:
+	case engines.EngineGodot:
+		return engines.EngineGodot, nil
+	case engines.EngineUnreal:
 		return engines.EngineUnknown, fmt.Errorf("detected %s project, but %s engine support is not yet implemented for add command", best.Engine.String(), best.Engine.String())
 	default:
+		if engines.HasAdapter(best.Engine) {
+			return best.Engine, nil
+		}
 		return engines.EngineUnknown, fmt.Errorf("unsupported engine detected: %s", best.Engine.String())
 	}
 }
 
+// isVersionInstalled reports whether the exact version of a package is
+// installed, including side-by-side versions
+func isVersionInstalled(adapter engines.EngineAdapter, projectPath, packageName, version string) bool {
+	packages, err := adapter.ListPackages(projectPath)
+	if err != nil {
+		return false
+	}
+	for _, pkg := range packages {
+		if pkg.Name == packageName && pkg.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEngineCompatibility compares a package version's declared engines, plus
+// its minimum "unity" version, against the environment. Constraints on
+// components missing from the environment are skipped. Unsatisfied
+// constraints are returned as warnings, or as an error when strict is set.
+func checkEngineCompatibility(info *api.PackageVersion, environment map[string]string, strict bool) ([]string, error) {
+	if info == nil {
+		return nil, nil
+	}
+
+	constraints := engineConstraints(info)
+	names := make([]string, 0, len(constraints))
+	for name := range constraints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		current := environment[name]
+		if current == "" {
+			continue
+		}
+		if !api.SatisfiesRange(current, constraints[name]) {
+			problems = append(problems, fmt.Sprintf("%s@%s requires %s %s, but this environment has %s", info.Name, info.Version, name, constraints[name], current))
+		}
+	}
+
+	if strict && len(problems) > 0 {
+		return nil, fmt.Errorf("%s\n\n%s",
+			styling.Error(strings.Join(problems, "\n")),
+			styling.Hint("Pick a compatible version, or drop --engine-strict to install with a warning"))
+	}
+	return problems, nil
+}
+
+// engineConstraints returns the version ranges info places on engines, keyed
+// by lowercased engine name. Unity's "unity" and "unityRelease" fields are a
+// minimum editor version, used unless engines constrains unity itself.
+func engineConstraints(info *api.PackageVersion) map[string]string {
+	constraints := make(map[string]string, len(info.Engines)+1)
+	for name, constraint := range info.Engines {
+		constraints[strings.ToLower(name)] = constraint
+	}
+	if info.Unity != "" && constraints["unity"] == "" {
+		minimum := info.Unity
+		if info.UnityRelease != "" {
+			minimum += "." + info.UnityRelease
+		}
+		constraints["unity"] = ">=" + minimum
+	}
+	return constraints
+}
+
+// compatibilityEnvironment returns the versions a package's engines field can
+// constrain: the running gpm and the project's detected engine version
+func compatibilityEnvironment(projectPath string, engineType engines.EngineType) map[string]string {
+	environment := map[string]string{"gpm": Version}
+
+	results, err := engines.DetectEngine(projectPath)
+	if err != nil {
+		return environment
+	}
+	for _, result := range results {
+		// Godot only reports "3.x"/"4.x", which is too coarse to compare
+		if result.Engine == engineType && result.Version != "" && !strings.Contains(result.Version, "x") && result.Version != "unknown" {
+			environment[string(engineType)] = result.Version
+		}
+	}
+	return environment
+}
+
 func parseAddPackageSpec(spec string) (string, string, error) {
 	if spec == "" {
 		return "", "", fmt.Errorf("package specification cannot be empty")
@@ -289,8 +808,50 @@ func parseAddPackageSpec(spec string) (string, string, error) {
 	return "", "", fmt.Errorf("invalid package specification format")
 }
 
-func getConfiguredRegistry() (string, error) {
-	registry := config.GetRegistry()
+// recordPackageOrigin remembers where a Unity dependency came from and the
+// dist-tag or npm range it was asked for, so a later update can honor the
+// request even though the manifest only holds the resolved version. It
+// returns a warning when the origin can't be saved.
+func recordPackageOrigin(adapter engines.EngineAdapter, projectPath, name string, origin engines.PackageOrigin) string {
+	unityAdapter, ok := adapter.(*engines.UnityAdapter)
+	if !ok {
+		return ""
+	}
+	if err := unityAdapter.SetPackageOrigin(projectPath, name, &origin); err != nil {
+		return fmt.Sprintf("failed to record where %s came from in %s: %v", name, engines.UnityPackagesFile, err)
+	}
+	return ""
+}
+
+// resolutionOrigin is the origin recorded for a package resolved from
+// registryURL. A dist-tag the package lacks is kept as asked for, so the
+// channel is honored once the tag is published.
+func resolutionOrigin(registryURL string, resolution *api.VersionResolution) engines.PackageOrigin {
+	tag := resolution.Tag
+	if resolution.MissingTag != "" {
+		tag = resolution.MissingTag
+	}
+	return engines.PackageOrigin{Registry: registryURL, Tag: tag, Range: resolution.Range, Version: resolution.Version}
+}
+
+// defaultDistTag returns the dist-tag packages given without a version are
+// resolved through: --tag, then the default_tag setting. Empty means latest.
+func defaultDistTag(tagFlag string) string {
+	if tagFlag != "" {
+		return tagFlag
+	}
+	return config.GetConfig().DefaultTag
+}
+
+// getConfiguredRegistry returns the registry to use without --registry:
+// GPM_REGISTRY, the active profile, the project's own settings, or the user
+// config, in that order
+func getConfiguredRegistry(projectPath string) (string, error) {
+	resolved := config.ResolveRegistry("", projectPath)
+	registry := resolved.Value
+	if resolved.Source == config.SourceDefault {
+		registry = config.GetRegistry()
+	}
 	if registry == "" {
 		return "", fmt.Errorf("no registry configured")
 	}
@@ -298,9 +859,35 @@ func getConfiguredRegistry() (string, error) {
 	return registry, nil
 }
 
-func createProjectBackup(projectPath string, engineType engines.EngineType) (string, error) {
+// checkRegistryReachable runs the connect preflight against registryURL. A
+// zero timeout uses the registry_timeout setting.
+func checkRegistryReachable(registryURL string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = config.GetRegistryTimeout()
+	}
+	if err := api.CheckRegistryReachable(registryURL, timeout); err != nil {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(err.Error()),
+			styling.Hint("Check your network connection and registry URL, or allow more time with --registry-timeout"))
+	}
+	return nil
+}
+
+// readOnlyProjectError turns an adapter's ReadOnlyError into a user-facing
+// error with a hint; it returns nil for any other error
+func readOnlyProjectError(err error) error {
+	var readOnly *engines.ReadOnlyError
+	if !errors.As(err, &readOnly) {
+		return nil
+	}
+	return fmt.Errorf("%s\n\n%s", styling.Error(readOnly.Error()), styling.Hint(engines.ReadOnlyHint))
+}
+
+// createProjectBackup copies the project's manifest into a timestamped
+// directory under backups/ in the project's state directory
+func createProjectBackup(projectPath string, engineType engines.EngineType, packagesDir string) (string, error) {
 	timestamp := time.Now().Format("20060102-150405")
-	backupDir := filepath.Join(os.TempDir(), fmt.Sprintf("gpm-backup-%s", timestamp))
+	backupDir := filepath.Join(projectStateDir(projectPath), "backups", timestamp)
 
 	if err := os.MkdirAll(backupDir, 0750); err != nil {
 		return "", fmt.Errorf("failed to create backup directory: %w", err)
@@ -308,14 +895,20 @@ func createProjectBackup(projectPath string, engineType engines.EngineType) (str
 
 	switch engineType {
 	case engines.EngineUnity:
-		return backupUnityProject(projectPath, backupDir)
+		return backupUnityProject(projectPath, backupDir, packagesDir)
+	case engines.EngineGodot:
+		return backupGodotProject(projectPath, backupDir)
 	default:
 		return "", fmt.Errorf("backup not implemented for engine type: %s", engineType)
 	}
 }
 
-func backupUnityProject(projectPath, backupDir string) (string, error) {
-	manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+func backupUnityProject(projectPath, backupDir, packagesDir string) (string, error) {
+	resolvedDir, err := engines.ResolveUnityPackagesDir(projectPath, packagesDir)
+	if err != nil {
+		return "", err
+	}
+	manifestPath := filepath.Join(resolvedDir, "manifest.json")
 	if !fileExists(manifestPath) {
 		// No existing manifest to backup
 		return backupDir, nil
@@ -338,23 +931,29 @@ func backupUnityProject(projectPath, backupDir string) (string, error) {
 	return backupDir, nil
 }
 
-func restoreFromBackup(backupPath, projectPath string, engineType engines.EngineType) error {
+func restoreFromBackup(backupPath, projectPath string, engineType engines.EngineType, packagesDir string) error {
 	switch engineType {
 	case engines.EngineUnity:
-		return restoreUnityProject(backupPath, projectPath)
+		return restoreUnityProject(backupPath, projectPath, packagesDir)
+	case engines.EngineGodot:
+		return restoreGodotProject(backupPath, projectPath)
 	default:
 		return fmt.Errorf("restore not implemented for engine type: %s", engineType)
 	}
 }
 
-func restoreUnityProject(backupPath, projectPath string) error {
+func restoreUnityProject(backupPath, projectPath, packagesDir string) error {
 	backupManifestPath := filepath.Join(backupPath, "manifest.json")
 	if !fileExists(backupManifestPath) {
 		// Nothing to restore
 		return nil
 	}
 
-	manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+	resolvedDir, err := engines.ResolveUnityPackagesDir(projectPath, packagesDir)
+	if err != nil {
+		return err
+	}
+	manifestPath := filepath.Join(resolvedDir, "manifest.json")
 	// Validate path to prevent directory traversal
 	if !strings.HasPrefix(filepath.Clean(backupManifestPath), backupPath) {
 		return fmt.Errorf("invalid backup manifest path")
@@ -364,7 +963,49 @@ func restoreUnityProject(backupPath, projectPath string) error {
 		return fmt.Errorf("failed to read backup manifest: %w", err)
 	}
 
-	return os.WriteFile(manifestPath, data, 0600)
+	return engines.WriteManifest(manifestPath, data)
+}
+
+func backupGodotProject(projectPath, backupDir string) (string, error) {
+	manifestPath := filepath.Join(projectPath, engines.GodotManifestFile)
+	if !fileExists(manifestPath) {
+		// No existing manifest to backup
+		return backupDir, nil
+	}
+
+	// Validate path to prevent directory traversal
+	if !strings.HasPrefix(filepath.Clean(manifestPath), projectPath) {
+		return "", fmt.Errorf("invalid manifest path")
+	}
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read manifest for backup: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(backupDir, engines.GodotManifestFile), data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+
+	return backupDir, nil
+}
+
+func restoreGodotProject(backupPath, projectPath string) error {
+	backupManifestPath := filepath.Join(backupPath, engines.GodotManifestFile)
+	if !fileExists(backupManifestPath) {
+		// Nothing to restore
+		return nil
+	}
+
+	// Validate path to prevent directory traversal
+	if !strings.HasPrefix(filepath.Clean(backupManifestPath), backupPath) {
+		return fmt.Errorf("invalid backup manifest path")
+	}
+	data, err := os.ReadFile(backupManifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read backup manifest: %w", err)
+	}
+
+	return engines.WriteManifest(filepath.Join(projectPath, engines.GodotManifestFile), data)
 }
 
 func fileExists(path string) bool {
@@ -372,7 +1013,34 @@ func fileExists(path string) bool {
 	return err == nil
 }
 
-func printAddJSON(cmd *cobra.Command, output *AddOutput) error {
+// printAddJSON prints one AddOutput, or a list of them for several packages
+// finishAddStream writes the --json-stream summary of an add and returns its
+// error, matching the exit status of the other output modes
+func finishAddStream(outputs []*AddOutput, errs []error) error {
+	summary := StreamSummary{Success: len(errs) == 0}
+	for _, output := range outputs {
+		if output.Success {
+			summary.Packages = append(summary.Packages, output.Package+"@"+output.Version)
+		}
+		summary.Warnings = append(summary.Warnings, output.Warnings...)
+	}
+	var err error
+	switch {
+	case len(outputs) == 1 && len(errs) == 1:
+		err = errs[0]
+	case len(errs) > 0:
+		err = fmt.Errorf("failed to add %d of %d packages", len(errs), len(outputs))
+	}
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	if streamErr := addEvents.summary(summary); streamErr != nil {
+		return fmt.Errorf("failed to write JSON stream: %w", streamErr)
+	}
+	return err
+}
+
+func printAddJSON(cmd *cobra.Command, output any) error {
 	data, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON output: %w", err)
@@ -382,6 +1050,26 @@ func printAddJSON(cmd *cobra.Command, output *AddOutput) error {
 }
 
 func printAddHuman(cmd *cobra.Command, output *AddOutput) error {
+	for _, warning := range output.Warnings {
+		cmd.Printf("%s\n", styling.Warning("⚠ "+warning))
+	}
+
+	if output.DryRun {
+		cmd.Println(styling.Header("🧪 Dry Run - Manifest Changes"))
+		cmd.Println(styling.Separator())
+		cmd.Println(styling.Label("Dependencies:"))
+		for _, line := range manifestDiffLines(output.Diff) {
+			cmd.Println(line)
+		}
+		cmd.Println(styling.Label("Scoped Registries:"))
+		for _, line := range scopedRegistryLines(output.Diff) {
+			cmd.Println(line)
+		}
+		cmd.Println(styling.Separator())
+		cmd.Printf("%s %s\n", styling.Info("ℹ"), output.Message)
+		return nil
+	}
+
 	if !output.Changed {
 		cmd.Printf("%s %s\n", styling.Info("ℹ"), output.Message)
 		return nil
@@ -401,3 +1089,50 @@ func printAddHuman(cmd *cobra.Command, output *AddOutput) error {
 
 	return nil
 }
+
+// manifestDiffLines renders a dry-run manifest diff, one line per change
+func manifestDiffLines(diff *engines.ManifestDiff) []string {
+	if diff == nil || len(diff.Added)+len(diff.Updated)+len(diff.Removed) == 0 {
+		return []string{styling.Muted("No dependency changes")}
+	}
+
+	var lines []string
+	for _, change := range diff.Added {
+		lines = append(lines, fmt.Sprintf("  %s %s %s", styling.Success("+"), styling.Package(change.Name), styling.Version(change.To)))
+	}
+	for _, change := range diff.Updated {
+		lines = append(lines, fmt.Sprintf("  %s %s %s → %s", styling.Warning("~"), styling.Package(change.Name), styling.Version(change.From), styling.Version(change.To)))
+	}
+	for _, change := range diff.Removed {
+		lines = append(lines, fmt.Sprintf("  %s %s %s", styling.Error("-"), styling.Package(change.Name), styling.Version(change.From)))
+	}
+	return lines
+}
+
+// scopedRegistryLines lists the scoped registries a dry run would add, change
+// or remove. Unity users often don't expect adding a package to touch
+// scopedRegistries, so dry runs show these apart from the dependencies.
+func scopedRegistryLines(diff *engines.ManifestDiff) []string {
+	if diff == nil || len(diff.ScopedRegistries) == 0 {
+		return []string{styling.Muted("No scoped registry changes")}
+	}
+
+	var lines []string
+	for _, change := range diff.ScopedRegistries {
+		switch change.Action {
+		case "added":
+			lines = append(lines, fmt.Sprintf("  %s %s %s", styling.Success("+"), styling.Value(change.Name), styling.URL(change.URL)))
+		case "removed":
+			lines = append(lines, fmt.Sprintf("  %s %s %s", styling.Error("-"), styling.Value(change.Name), styling.URL(change.URL)))
+		default:
+			lines = append(lines, fmt.Sprintf("  %s %s %s", styling.Warning("~"), styling.Value(change.Name), styling.URL(change.URL)))
+		}
+		if len(change.Scopes) > 0 {
+			lines = append(lines, fmt.Sprintf("      %s %s", styling.Label("scopes added:"), strings.Join(change.Scopes, ", ")))
+		}
+		if len(change.RemovedScopes) > 0 {
+			lines = append(lines, fmt.Sprintf("      %s %s", styling.Label("scopes removed:"), strings.Join(change.RemovedScopes, ", ")))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+// maxPackageSuggestions caps the search matches offered for a name that
+// wasn't found
+const maxPackageSuggestions = 5
+
+// packageSuggestion is a search match offered in place of a mistyped name
+type packageSuggestion struct {
+	Name        string
+	Version     string
+	Description string
+}
+
+// terminalIsInteractive reports whether gpm can prompt: stdin and stdout are
+// both terminals. A variable so tests can stub it.
+var terminalIsInteractive = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// choosePackage asks which suggestion was meant, returning "" when the user
+// picks none. A variable so tests can stub it.
+var choosePackage = func(name string, suggestions []packageSuggestion) (string, error) {
+	fmt.Printf("%s\n", styling.Warning(fmt.Sprintf("⚠ Package %s was not found. Did you mean:", name)))
+	for i, suggestion := range suggestions {
+		line := fmt.Sprintf("  %d) %s", i+1, styling.Package(suggestion.Name))
+		if suggestion.Version != "" {
+			line += "@" + styling.Version(suggestion.Version)
+		}
+		if suggestion.Description != "" {
+			line += "  " + styling.Muted(suggestion.Description)
+		}
+		fmt.Println(line)
+	}
+	fmt.Print(styling.Label(fmt.Sprintf("Add which package? [1-%d, Enter to cancel]: ", len(suggestions))))
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", nil
+	}
+	choice, err := strconv.Atoi(line)
+	if err != nil || choice < 1 || choice > len(suggestions) {
+		return "", fmt.Errorf("invalid choice %q", line)
+	}
+	return suggestions[choice-1].Name, nil
+}
+
+// suggestPackage searches the registry for packages named like one that
+// wasn't found and lets the user pick one, returning "" when add may not
+// prompt, nothing similar exists or the user picked none
+func suggestPackage(registryURL, name string) (string, error) {
+	if !addInteractive || !terminalIsInteractive() {
+		return "", nil
+	}
+
+	suggestions, err := similarPackages(registryURL, name)
+	if err != nil || len(suggestions) == 0 {
+		return "", err
+	}
+	return choosePackage(name, suggestions)
+}
+
+// similarPackages searches for a mistyped name, then for its last segment
+// ("analytcs" in com.unity.analytcs) when the full name matches nothing
+func similarPackages(registryURL, name string) ([]packageSuggestion, error) {
+	terms := []string{name}
+	if i := strings.LastIndexAny(name, "./"); i >= 0 && i < len(name)-1 {
+		terms = append(terms, name[i+1:])
+	}
+	for _, searchTerm := range terms {
+		result, err := searchRegistry(registryURL, searchTerm, maxPackageSuggestions)
+		if err != nil {
+			return nil, err
+		}
+		suggestions := make([]packageSuggestion, 0, len(result.Objects))
+		for _, object := range result.Objects {
+			if object.Package.Name == "" || object.Package.Name == name {
+				continue
+			}
+			description, _, _ := strings.Cut(object.Package.Description, "\n")
+			suggestions = append(suggestions, packageSuggestion{
+				Name:        object.Package.Name,
+				Version:     object.Package.Version,
+				Description: description,
+			})
+		}
+		if len(suggestions) > 0 {
+			return suggestions, nil
+		}
+	}
+	return nil, nil
+}
@@ -1,12 +1,22 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/config"
 	"gpm.sh/gpm/gpm-cli/internal/engines"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
 )
 
 func TestParseAddPackageSpec(t *testing.T) {
@@ -145,7 +155,7 @@ func TestUnityProjectBackupAndRestore(t *testing.T) {
 	}
 
 	// Test backup
-	backupPath, err := createProjectBackup(projectPath, engines.EngineUnity)
+	backupPath, err := createProjectBackup(projectPath, engines.EngineUnity, "")
 	if err != nil {
 		t.Fatalf("backup failed: %v", err)
 	}
@@ -178,7 +188,7 @@ func TestUnityProjectBackupAndRestore(t *testing.T) {
 	}
 
 	// Test restore
-	if err := restoreFromBackup(backupPath, projectPath, engines.EngineUnity); err != nil {
+	if err := restoreFromBackup(backupPath, projectPath, engines.EngineUnity, ""); err != nil {
 		t.Fatalf("restore failed: %v", err)
 	}
 
@@ -385,3 +395,1486 @@ func TestAddCommandIntegration(t *testing.T) {
 		t.Fatalf("project validation failed: %v", err)
 	}
 }
+
+func TestUnityInstallCustomPackagesDir(t *testing.T) {
+	projectPath := t.TempDir()
+	for _, dir := range []string{"Assets", "ProjectSettings"} {
+		if err := os.MkdirAll(filepath.Join(projectPath, dir), 0755); err != nil {
+			t.Fatalf("failed to create %s directory: %v", dir, err)
+		}
+	}
+
+	req := &engines.PackageInstallRequest{
+		Name:     "com.test.package",
+		Version:  "1.0.0",
+		Registry: "https://test.gpm.sh",
+	}
+
+	t.Run("flag override", func(t *testing.T) {
+		adapter := engines.NewUnityAdapter()
+		adapter.SetPackagesDir(filepath.Join("UPM", "Packages"))
+
+		if _, err := adapter.InstallPackage(projectPath, req); err != nil {
+			t.Fatalf("install failed: %v", err)
+		}
+
+		customManifest := filepath.Join(projectPath, "UPM", "Packages", "manifest.json")
+		if !fileExists(customManifest) {
+			t.Errorf("manifest not written to custom packages dir %s", customManifest)
+		}
+		if fileExists(filepath.Join(projectPath, "Packages", "manifest.json")) {
+			t.Errorf("default manifest should not be written when packages dir is overridden")
+		}
+
+		info, err := adapter.GetPackageInfo(projectPath, "com.test.package")
+		if err != nil {
+			t.Fatalf("failed to read package from custom manifest: %v", err)
+		}
+		if info.Version != "1.0.0" {
+			t.Errorf("wrong version: got %q, want %q", info.Version, "1.0.0")
+		}
+	})
+
+	t.Run("environment override", func(t *testing.T) {
+		t.Setenv(engines.UnityPackagesDirEnv, "EnvPackages")
+
+		adapter := engines.NewUnityAdapter()
+		if _, err := adapter.InstallPackage(projectPath, req); err != nil {
+			t.Fatalf("install failed: %v", err)
+		}
+
+		if !fileExists(filepath.Join(projectPath, "EnvPackages", "manifest.json")) {
+			t.Errorf("manifest not written to packages dir from %s", engines.UnityPackagesDirEnv)
+		}
+	})
+
+	t.Run("outside project rejected", func(t *testing.T) {
+		adapter := engines.NewUnityAdapter()
+		adapter.SetPackagesDir(filepath.Join("..", "elsewhere"))
+
+		if _, err := adapter.InstallPackage(projectPath, req); err == nil {
+			t.Errorf("expected error for packages dir outside the project")
+		}
+	})
+
+	t.Run("backup and restore follow packages dir", func(t *testing.T) {
+		packagesDir := filepath.Join("UPM", "Packages")
+		manifestPath := filepath.Join(projectPath, packagesDir, "manifest.json")
+		original, err := os.ReadFile(manifestPath)
+		if err != nil {
+			t.Fatalf("failed to read manifest: %v", err)
+		}
+
+		backupPath, err := createProjectBackup(projectPath, engines.EngineUnity, packagesDir)
+		if err != nil {
+			t.Fatalf("backup failed: %v", err)
+		}
+
+		if err := os.WriteFile(manifestPath, []byte(`{}`), 0644); err != nil {
+			t.Fatalf("failed to modify manifest: %v", err)
+		}
+
+		if err := restoreFromBackup(backupPath, projectPath, engines.EngineUnity, packagesDir); err != nil {
+			t.Fatalf("restore failed: %v", err)
+		}
+
+		restored, err := os.ReadFile(manifestPath)
+		if err != nil {
+			t.Fatalf("failed to read restored manifest: %v", err)
+		}
+		if string(restored) != string(original) {
+			t.Errorf("restore content mismatch:\ngot:  %s\nwant: %s", restored, original)
+		}
+	})
+}
+
+func TestSideBySideInstall(t *testing.T) {
+	t.Run("unity rejects a second version", func(t *testing.T) {
+		projectPath := t.TempDir()
+		for _, dir := range []string{"Assets", "ProjectSettings"} {
+			if err := os.MkdirAll(filepath.Join(projectPath, dir), 0755); err != nil {
+				t.Fatalf("failed to create %s directory: %v", dir, err)
+			}
+		}
+
+		adapter := engines.NewUnityAdapter()
+		if adapter.SupportsMultiVersion() {
+			t.Fatalf("unity adapter should not support multiple versions")
+		}
+
+		if _, err := adapter.InstallPackage(projectPath, &engines.PackageInstallRequest{Name: "com.x.pkg", Version: "1.0.0"}); err != nil {
+			t.Fatalf("install failed: %v", err)
+		}
+
+		_, err := adapter.InstallPackage(projectPath, &engines.PackageInstallRequest{Name: "com.x.pkg", Version: "2.0.0", SideBySide: true})
+		if err == nil || !strings.Contains(err.Error(), "side-by-side") {
+			t.Fatalf("expected side-by-side error, got %v", err)
+		}
+
+		info, err := adapter.GetPackageInfo(projectPath, "com.x.pkg")
+		if err != nil {
+			t.Fatalf("failed to get package info: %v", err)
+		}
+		if info.Version != "1.0.0" {
+			t.Errorf("rejected install changed the manifest: got %q, want %q", info.Version, "1.0.0")
+		}
+	})
+
+	t.Run("godot keeps both versions", func(t *testing.T) {
+		projectPath := t.TempDir()
+		if err := os.WriteFile(filepath.Join(projectPath, "project.godot"), []byte("config_version=5\n"), 0644); err != nil {
+			t.Fatalf("failed to create project.godot: %v", err)
+		}
+
+		adapter, err := engines.GetAdapter(engines.EngineGodot)
+		if err != nil {
+			t.Fatalf("failed to get adapter: %v", err)
+		}
+		if !adapter.SupportsMultiVersion() {
+			t.Fatalf("godot adapter should support multiple versions")
+		}
+
+		if _, err := adapter.InstallPackage(projectPath, &engines.PackageInstallRequest{Name: "com.x.pkg", Version: "1.0.0"}); err != nil {
+			t.Fatalf("install failed: %v", err)
+		}
+		if _, err := adapter.InstallPackage(projectPath, &engines.PackageInstallRequest{Name: "com.x.pkg", Version: "2.0.0", SideBySide: true}); err != nil {
+			t.Fatalf("side-by-side install failed: %v", err)
+		}
+
+		if !isVersionInstalled(adapter, projectPath, "com.x.pkg", "1.0.0") || !isVersionInstalled(adapter, projectPath, "com.x.pkg", "2.0.0") {
+			t.Errorf("expected both versions to be installed")
+		}
+		for _, dir := range []string{"com.x.pkg", "com.x.pkg@2.0.0"} {
+			if _, err := os.Stat(filepath.Join(projectPath, "addons", dir)); err != nil {
+				t.Errorf("expected addon directory %s: %v", dir, err)
+			}
+		}
+
+		// A regular install replaces every installed version
+		if _, err := adapter.InstallPackage(projectPath, &engines.PackageInstallRequest{Name: "com.x.pkg", Version: "3.0.0"}); err != nil {
+			t.Fatalf("install failed: %v", err)
+		}
+		packages, err := adapter.ListPackages(projectPath)
+		if err != nil {
+			t.Fatalf("failed to list packages: %v", err)
+		}
+		if len(packages) != 1 || packages[0].Version != "3.0.0" {
+			t.Errorf("expected only 3.0.0 after replace, got %d packages", len(packages))
+		}
+		if _, err := os.Stat(filepath.Join(projectPath, "addons", "com.x.pkg@2.0.0")); !os.IsNotExist(err) {
+			t.Errorf("side-by-side directory should be removed on replace")
+		}
+	})
+}
+
+func TestCheckEngineCompatibility(t *testing.T) {
+	info := &api.PackageVersion{
+		Name:    "com.test.compat",
+		Version: "1.0.0",
+		Unity:   "2021.3",
+		Engines: map[string]string{"gpm": ">=0.1.0"},
+	}
+
+	t.Run("satisfied", func(t *testing.T) {
+		warnings, err := checkEngineCompatibility(info, map[string]string{"gpm": "v0.2.0", "unity": "2022.3.10f1"}, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(warnings) != 0 {
+			t.Errorf("expected no warnings, got %v", warnings)
+		}
+	})
+
+	t.Run("unsatisfied warns", func(t *testing.T) {
+		warnings, err := checkEngineCompatibility(info, map[string]string{"gpm": "v0.2.0", "unity": "2020.3.1f1"}, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(warnings) != 1 || !strings.Contains(warnings[0], "requires unity >=2021.3") {
+			t.Errorf("expected a unity warning, got %v", warnings)
+		}
+	})
+
+	t.Run("unsatisfied strict", func(t *testing.T) {
+		_, err := checkEngineCompatibility(info, map[string]string{"gpm": "v0.0.9", "unity": "2022.3.10f1"}, true)
+		if err == nil || !strings.Contains(err.Error(), "requires gpm >=0.1.0") {
+			t.Errorf("expected strict gpm error, got %v", err)
+		}
+	})
+
+	t.Run("unknown environment is skipped", func(t *testing.T) {
+		warnings, err := checkEngineCompatibility(info, map[string]string{}, true)
+		if err != nil || len(warnings) != 0 {
+			t.Errorf("expected constraints to be skipped, got %v, %v", warnings, err)
+		}
+	})
+}
+
+func TestAddDryRunManifestDiff(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.test.package", &api.PackageMetadata{
+		Name:     "com.test.package",
+		DistTags: map[string]string{"latest": "1.0.0"},
+		Versions: map[string]*api.PackageVersion{
+			"1.0.0": {Name: "com.test.package", Version: "1.0.0"},
+		},
+	})
+
+	projectPath := t.TempDir()
+	if err := setupUnityProject(projectPath); err != nil {
+		t.Fatalf("failed to setup Unity project: %v", err)
+	}
+	manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+		t.Fatalf("failed to create Packages directory: %v", err)
+	}
+	original := `{"dependencies": {"com.unity.ugui": "1.0.0"}}`
+	if err := os.WriteFile(manifestPath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	output := &AddOutput{Details: make(map[string]any)}
+	if err := executeAddWithFlags("com.test.package@1.0.0", output, addOptions{project: projectPath, engine: "auto", registry: mockRegistry.URL(), dryRun: true}); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+
+	data, err := json.Marshal(output)
+	if err != nil {
+		t.Fatalf("failed to marshal output: %v", err)
+	}
+	var decoded struct {
+		DryRun bool `json:"dry_run"`
+		Diff   struct {
+			Added []struct {
+				Name string `json:"name"`
+				From string `json:"from"`
+				To   string `json:"to"`
+			} `json:"added"`
+			Updated          []any `json:"updated"`
+			ScopedRegistries []struct {
+				URL    string   `json:"url"`
+				Action string   `json:"action"`
+				Scopes []string `json:"scopes"`
+			} `json:"scoped_registries"`
+		} `json:"diff"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to decode output: %v", err)
+	}
+
+	if !decoded.DryRun {
+		t.Errorf("expected dry_run to be true")
+	}
+	if len(decoded.Diff.Added) != 1 || decoded.Diff.Added[0].Name != "com.test.package" || decoded.Diff.Added[0].To != "1.0.0" || decoded.Diff.Added[0].From != "" {
+		t.Errorf("unexpected added dependencies: %+v", decoded.Diff.Added)
+	}
+	if len(decoded.Diff.Updated) != 0 {
+		t.Errorf("expected no updated dependencies, got %v", decoded.Diff.Updated)
+	}
+	if len(decoded.Diff.ScopedRegistries) != 1 {
+		t.Fatalf("expected one scoped registry change, got %+v", decoded.Diff.ScopedRegistries)
+	}
+	registry := decoded.Diff.ScopedRegistries[0]
+	if registry.URL != mockRegistry.URL() || registry.Action != "added" || len(registry.Scopes) != 1 || registry.Scopes[0] != "com.test" {
+		t.Errorf("unexpected scoped registry change: %+v", registry)
+	}
+
+	// Nothing is written
+	data, err = os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+	if string(data) != original {
+		t.Errorf("dry run modified the manifest:\n%s", data)
+	}
+	if output.BackupPath != "" {
+		t.Errorf("dry run should not create a backup, got %s", output.BackupPath)
+	}
+}
+
+func TestAddDryRunScopedRegistrySection(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.test.package", &api.PackageMetadata{
+		Name:     "com.test.package",
+		DistTags: map[string]string{"latest": "1.0.0"},
+		Versions: map[string]*api.PackageVersion{
+			"1.0.0": {Name: "com.test.package", Version: "1.0.0"},
+		},
+	})
+
+	noColor := styling.NoColor
+	styling.NoColor = true
+	defer func() { styling.NoColor = noColor }()
+
+	dryRun := func(t *testing.T, manifest string, force bool) string {
+		projectPath := t.TempDir()
+		if err := setupUnityProject(projectPath); err != nil {
+			t.Fatalf("failed to setup Unity project: %v", err)
+		}
+		manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+		if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+			t.Fatalf("failed to create Packages directory: %v", err)
+		}
+		if err := os.WriteFile(manifestPath, []byte(manifest), 0644); err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+
+		output := &AddOutput{Details: make(map[string]any)}
+		if err := executeAddWithFlags("com.test.package@1.0.0", output, addOptions{project: projectPath, engine: "auto", registry: mockRegistry.URL(), dryRun: true, force: force}); err != nil {
+			t.Fatalf("dry run failed: %v", err)
+		}
+		var buf bytes.Buffer
+		addCmd.SetOut(&buf)
+		defer addCmd.SetOut(nil)
+		if err := printAddHuman(addCmd, output); err != nil {
+			t.Fatalf("failed to print dry run: %v", err)
+		}
+		return buf.String()
+	}
+
+	t.Run("first add reports the new scoped registry", func(t *testing.T) {
+		out := dryRun(t, `{"dependencies": {}}`, false)
+		section := out[strings.Index(out, "Scoped Registries:"):]
+		for _, want := range []string{"+ GPM Registry (com.test) " + mockRegistry.URL(), "scopes added: com.test"} {
+			if !strings.Contains(section, want) {
+				t.Errorf("expected %q in the scoped registry section:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("moving a scope reports the registry losing it", func(t *testing.T) {
+		out := dryRun(t, `{"dependencies": {}, "scopedRegistries": [{"name": "Old", "url": "https://old.example.com", "scopes": ["com.test"]}]}`, true)
+		for _, want := range []string{"- Old https://old.example.com", "scopes removed: com.test", "+ GPM Registry (com.test)"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("expected %q in the dry run output:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("no registry changes are said so", func(t *testing.T) {
+		out := dryRun(t, `{"dependencies": {}, "scopedRegistries": [{"name": "Mine", "url": "`+mockRegistry.URL()+`", "scopes": ["com.test"]}]}`, false)
+		if !strings.Contains(out, "No scoped registry changes") {
+			t.Errorf("expected no scoped registry changes:\n%s", out)
+		}
+	})
+}
+
+func TestAddMultiplePackagesPartialFailure(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	for _, name := range []string{"com.test.first", "com.test.third"} {
+		mockRegistry.AddPackage(name, &api.PackageMetadata{
+			Name:     name,
+			DistTags: map[string]string{"latest": "1.0.0"},
+			Versions: map[string]*api.PackageVersion{
+				"1.0.0": {Name: name, Version: "1.0.0"},
+			},
+		})
+	}
+
+	projectPath := t.TempDir()
+	if err := setupUnityProject(projectPath); err != nil {
+		t.Fatalf("failed to setup Unity project: %v", err)
+	}
+
+	var buf bytes.Buffer
+	addCmd.SetOut(&buf)
+	defer addCmd.SetOut(nil)
+	for flag, value := range map[string]string{"project": projectPath, "registry": mockRegistry.URL(), "json": "true"} {
+		if err := addCmd.Flags().Set(flag, value); err != nil {
+			t.Fatalf("failed to set --%s: %v", flag, err)
+		}
+	}
+
+	err := runAddCommand(addCmd, []string{"com.test.first@1.0.0", "com.test.missing", "com.test.third@1.0.0"})
+	if err == nil || !strings.Contains(err.Error(), "failed to add 1 of 3 packages") {
+		t.Fatalf("expected a partial failure error, got %v", err)
+	}
+
+	var outputs []AddOutput
+	if err := json.Unmarshal(buf.Bytes(), &outputs); err != nil {
+		t.Fatalf("expected a JSON array of results: %v\n%s", err, buf.String())
+	}
+	if len(outputs) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(outputs))
+	}
+	for i, want := range []struct {
+		pkg     string
+		success bool
+	}{
+		{"com.test.first", true},
+		{"com.test.missing", false},
+		{"com.test.third", true},
+	} {
+		if outputs[i].Package != want.pkg || outputs[i].Success != want.success {
+			t.Errorf("result %d: got %s success=%v, want %s success=%v", i, outputs[i].Package, outputs[i].Success, want.pkg, want.success)
+		}
+	}
+	if !strings.Contains(outputs[1].Error, "not found in registry") {
+		t.Errorf("expected a not found error, got %q", outputs[1].Error)
+	}
+
+	// The failure in the middle didn't stop the last package
+	adapter := engines.NewUnityAdapter()
+	for _, name := range []string{"com.test.first", "com.test.third"} {
+		if !isVersionInstalled(adapter, projectPath, name, "1.0.0") {
+			t.Errorf("expected %s to be installed", name)
+		}
+	}
+}
+
+func TestAddJSONStream(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	for _, name := range []string{"com.test.first", "com.test.third"} {
+		mockRegistry.AddPackage(name, &api.PackageMetadata{
+			Name:     name,
+			DistTags: map[string]string{"latest": "1.0.0"},
+			Versions: map[string]*api.PackageVersion{
+				"1.0.0": {Name: name, Version: "1.0.0"},
+			},
+		})
+	}
+
+	projectPath := t.TempDir()
+	if err := setupUnityProject(projectPath); err != nil {
+		t.Fatalf("failed to setup Unity project: %v", err)
+	}
+
+	var buf bytes.Buffer
+	addCmd.SetOut(&buf)
+	defer addCmd.SetOut(nil)
+	for flag, value := range map[string]string{"project": projectPath, "registry": mockRegistry.URL(), "json-stream": "true"} {
+		if err := addCmd.Flags().Set(flag, value); err != nil {
+			t.Fatalf("failed to set --%s: %v", flag, err)
+		}
+	}
+	defer func() { _ = addCmd.Flags().Set("json-stream", "false") }()
+
+	err := runAddCommand(addCmd, []string{"com.test.first@1.0.0", "com.test.missing", "com.test.third@1.0.0"})
+	if err == nil || !strings.Contains(err.Error(), "failed to add 1 of 3 packages") {
+		t.Fatalf("expected a partial failure error, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var outcomes []string
+	for _, line := range lines[:len(lines)-1] {
+		var event StreamEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("expected one JSON object per line: %v\n%s", err, line)
+		}
+		if event.Event == EventInstalled || event.Event == EventFailed {
+			outcomes = append(outcomes, event.Event+" "+event.Package)
+		}
+	}
+	want := []string{"installed com.test.first", "failed com.test.missing", "installed com.test.third"}
+	if strings.Join(outcomes, ", ") != strings.Join(want, ", ") {
+		t.Errorf("expected one outcome per package %v, got %v", want, outcomes)
+	}
+
+	var summary StreamSummary
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &summary); err != nil {
+		t.Fatalf("expected a summary line: %v", err)
+	}
+	if summary.Event != EventSummary || summary.Success || summary.Succeeded != 2 || summary.Failed != 1 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if strings.Join(summary.Packages, ",") != "com.test.first@1.0.0,com.test.third@1.0.0" {
+		t.Errorf("unexpected summary packages: %v", summary.Packages)
+	}
+}
+
+func TestAddRegistryUnreachableFailsFast(t *testing.T) {
+	projectPath := t.TempDir()
+	if err := setupUnityProject(projectPath); err != nil {
+		t.Fatalf("failed to setup Unity project: %v", err)
+	}
+
+	// 10.255.255.1 is unroutable, so connecting hangs until the preflight gives up
+	const registry = "http://10.255.255.1:8080"
+	const preflight = 300 * time.Millisecond
+
+	for i, spec := range []string{"com.test.first", "com.test.second"} {
+		output := &AddOutput{Details: make(map[string]any)}
+		start := time.Now()
+		err := executeAddWithFlags(spec, output, addOptions{project: projectPath, engine: "auto", registry: registry, registryTimeout: preflight})
+		elapsed := time.Since(start)
+
+		if err == nil || !strings.Contains(err.Error(), "registry unreachable") {
+			t.Fatalf("expected a registry unreachable error, got %v", err)
+		}
+		if elapsed > preflight+2*time.Second {
+			t.Errorf("add took %v, expected to fail within the %v preflight", elapsed, preflight)
+		}
+		if i > 0 && elapsed > 100*time.Millisecond {
+			t.Errorf("second package took %v, expected the cached unreachable result", elapsed)
+		}
+	}
+}
+
+func TestAddReadOnlyProject(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("directory permissions don't stop root, and Windows ignores them")
+	}
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.test.package", &api.PackageMetadata{
+		Name:     "com.test.package",
+		DistTags: map[string]string{"latest": "1.0.0"},
+		Versions: map[string]*api.PackageVersion{
+			"1.0.0": {Name: "com.test.package", Version: "1.0.0"},
+		},
+	})
+
+	projectPath := t.TempDir()
+	if err := setupUnityProject(projectPath); err != nil {
+		t.Fatalf("failed to setup Unity project: %v", err)
+	}
+	// Without a Packages directory the project folder itself has to be writable
+	if err := os.Chmod(projectPath, 0500); err != nil {
+		t.Fatalf("failed to make the project read-only: %v", err)
+	}
+	defer func() { _ = os.Chmod(projectPath, 0750) }()
+
+	noColor := styling.NoColor
+	styling.NoColor = true
+	defer func() { styling.NoColor = noColor }()
+
+	output := &AddOutput{Details: make(map[string]any)}
+	err := executeAddWithFlags("com.test.package@1.0.0", output, addOptions{project: projectPath, engine: "auto", registry: mockRegistry.URL()})
+	if err == nil {
+		t.Fatal("expected adding to a read-only project to fail")
+	}
+	want := fmt.Sprintf("cannot write to %s: project appears read-only or you lack permissions", projectPath)
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+	if !strings.Contains(err.Error(), "Check the folder's permissions") {
+		t.Errorf("expected a hint about permissions, got %v", err)
+	}
+	if strings.Contains(err.Error(), "restore") {
+		t.Errorf("nothing was written, so nothing should be restored: %v", err)
+	}
+}
+
+func TestAddRefusesBlockedPackage(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.vendor.gpl-physics", &api.PackageMetadata{
+		Name:     "com.vendor.gpl-physics",
+		DistTags: map[string]string{"latest": "1.0.0"},
+		Versions: map[string]*api.PackageVersion{
+			"1.0.0": {Name: "com.vendor.gpl-physics", Version: "1.0.0"},
+		},
+	})
+	config.SetConfigForTesting(&config.Config{
+		Blocklist: []config.BlockedPackage{{Package: "com.vendor.gpl-*", Reason: "GPL-3.0 is not allowed in shipped games"}},
+	})
+	defer config.ResetConfigForTesting()
+
+	projectPath := t.TempDir()
+	if err := setupUnityProject(projectPath); err != nil {
+		t.Fatalf("failed to setup Unity project: %v", err)
+	}
+
+	var buf bytes.Buffer
+	addCmd.SetOut(&buf)
+	defer addCmd.SetOut(nil)
+	for flag, value := range map[string]string{"project": projectPath, "registry": mockRegistry.URL(), "json": "true"} {
+		if err := addCmd.Flags().Set(flag, value); err != nil {
+			t.Fatalf("failed to set --%s: %v", flag, err)
+		}
+	}
+
+	err := runAddCommand(addCmd, []string{"com.vendor.gpl-physics@1.0.0"})
+	if err == nil || !strings.Contains(err.Error(), "GPL-3.0 is not allowed in shipped games") {
+		t.Fatalf("expected the blocklist reason, got %v", err)
+	}
+
+	var output AddOutput
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("expected a JSON result: %v\n%s", err, buf.String())
+	}
+	if output.Success || output.Policy == nil || output.Policy.Rule != "com.vendor.gpl-*" || output.Policy.Reason != "GPL-3.0 is not allowed in shipped games" {
+		t.Errorf("expected the policy in the JSON error, got %+v", output)
+	}
+	if fileExists(filepath.Join(projectPath, "Packages", "manifest.json")) {
+		t.Error("a refused package must not create a manifest")
+	}
+}
+
+func TestUnityScopedRegistryConflict(t *testing.T) {
+	writeManifest := func(t *testing.T, manifest string) string {
+		projectPath := t.TempDir()
+		if err := setupUnityProject(projectPath); err != nil {
+			t.Fatalf("failed to setup Unity project: %v", err)
+		}
+		manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+		if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+			t.Fatalf("failed to create Packages directory: %v", err)
+		}
+		if err := os.WriteFile(manifestPath, []byte(manifest), 0644); err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+		return projectPath
+	}
+	readRegistries := func(t *testing.T, projectPath string) []*engines.ScopedRegistry {
+		data, err := os.ReadFile(filepath.Join(projectPath, "Packages", "manifest.json"))
+		if err != nil {
+			t.Fatalf("failed to read manifest: %v", err)
+		}
+		var manifest engines.UnityManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			t.Fatalf("failed to parse manifest: %v", err)
+		}
+		return manifest.ScopedRegistries
+	}
+
+	const existing = `{
+		"dependencies": {"com.homa.core": "1.0.0"},
+		"scopedRegistries": [{"name": "Homa", "url": "https://a.gpm.sh", "scopes": ["com.homa"]}]
+	}`
+	adapter := engines.NewUnityAdapter()
+
+	t.Run("same scope, different URL", func(t *testing.T) {
+		projectPath := writeManifest(t, existing)
+		_, err := adapter.InstallPackage(projectPath, &engines.PackageInstallRequest{
+			Name:     "com.homa.sdk",
+			Version:  "1.0.0",
+			Registry: "https://b.gpm.sh",
+		})
+		if err == nil || !strings.Contains(err.Error(), "scope com.homa is already mapped to https://a.gpm.sh") {
+			t.Fatalf("expected a scope conflict error, got %v", err)
+		}
+
+		registries := readRegistries(t, projectPath)
+		if len(registries) != 1 || registries[0].URL != "https://a.gpm.sh" {
+			t.Errorf("manifest should be unchanged after a conflict, got %+v", registries)
+		}
+	})
+
+	t.Run("force moves the scope", func(t *testing.T) {
+		projectPath := writeManifest(t, existing)
+		_, err := adapter.InstallPackage(projectPath, &engines.PackageInstallRequest{
+			Name:     "com.homa.sdk",
+			Version:  "1.0.0",
+			Registry: "https://b.gpm.sh",
+			Force:    true,
+		})
+		if err != nil {
+			t.Fatalf("forced install failed: %v", err)
+		}
+
+		registries := readRegistries(t, projectPath)
+		if len(registries) != 1 || registries[0].URL != "https://b.gpm.sh" || len(registries[0].Scopes) != 1 || registries[0].Scopes[0] != "com.homa" {
+			t.Errorf("expected com.homa to move to https://b.gpm.sh, got %+v", registries)
+		}
+	})
+
+	t.Run("same scope, same URL", func(t *testing.T) {
+		projectPath := writeManifest(t, existing)
+		_, err := adapter.InstallPackage(projectPath, &engines.PackageInstallRequest{
+			Name:     "com.homa.sdk",
+			Version:  "1.0.0",
+			Registry: "https://a.gpm.sh/",
+		})
+		if err != nil {
+			t.Fatalf("install from the mapped registry failed: %v", err)
+		}
+
+		registries := readRegistries(t, projectPath)
+		if len(registries) != 1 || len(registries[0].Scopes) != 1 {
+			t.Errorf("expected the existing registry to be reused, got %+v", registries)
+		}
+	})
+
+	t.Run("registries sharing a URL are merged", func(t *testing.T) {
+		projectPath := writeManifest(t, `{
+			"dependencies": {},
+			"scopedRegistries": [
+				{"name": "Homa", "url": "https://a.gpm.sh", "scopes": ["com.homa"]},
+				{"name": "Homa again", "url": "https://a.gpm.sh/", "scopes": ["com.homa", "com.other"]}
+			]
+		}`)
+		_, err := adapter.InstallPackage(projectPath, &engines.PackageInstallRequest{
+			Name:     "com.homa.sdk",
+			Version:  "1.0.0",
+			Registry: "https://a.gpm.sh",
+		})
+		if err != nil {
+			t.Fatalf("install failed: %v", err)
+		}
+
+		registries := readRegistries(t, projectPath)
+		if len(registries) != 1 {
+			t.Fatalf("expected duplicate registries to be merged, got %+v", registries)
+		}
+		if strings.Join(registries[0].Scopes, ",") != "com.homa,com.other" {
+			t.Errorf("unexpected merged scopes: %v", registries[0].Scopes)
+		}
+	})
+}
+
+func TestAddPreservesManifestIndentation(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	for _, name := range []string{"com.test.first", "com.test.second"} {
+		mockRegistry.AddPackage(name, &api.PackageMetadata{
+			Name:     name,
+			DistTags: map[string]string{"latest": "1.0.0"},
+			Versions: map[string]*api.PackageVersion{
+				"1.0.0": {Name: name, Version: "1.0.0"},
+			},
+		})
+	}
+
+	projectPath := t.TempDir()
+	if err := setupUnityProject(projectPath); err != nil {
+		t.Fatalf("failed to setup Unity project: %v", err)
+	}
+	manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+		t.Fatalf("failed to create Packages directory: %v", err)
+	}
+	original := "{\n\t\"dependencies\": {\n\t\t\"com.unity.ugui\": \"1.0.0\"\n\t}\n}\n"
+	if err := os.WriteFile(manifestPath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	add := func(spec string, normalize bool) string {
+		output := &AddOutput{Details: make(map[string]any)}
+		if err := executeAddWithFlags(spec, output, addOptions{project: projectPath, engine: "auto", registry: mockRegistry.URL(), normalize: normalize}); err != nil {
+			t.Fatalf("add %s failed: %v", spec, err)
+		}
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			t.Fatalf("failed to read manifest: %v", err)
+		}
+		return string(data)
+	}
+
+	manifest := add("com.test.first@1.0.0", false)
+	if !strings.Contains(manifest, "\n\t\"dependencies\": {\n\t\t\"com.test.first\": \"1.0.0\"") {
+		t.Errorf("Expected the manifest to stay tab-indented, got:\n%s", manifest)
+	}
+	if !strings.HasSuffix(manifest, "}\n") {
+		t.Errorf("Expected the trailing newline to be kept, got %q", manifest)
+	}
+
+	manifest = add("com.test.second@1.0.0", true)
+	if strings.Contains(manifest, "\t") || !strings.Contains(manifest, "\n  \"dependencies\": {\n    \"com.test.first\"") {
+		t.Errorf("Expected --normalize to reindent with two spaces, got:\n%s", manifest)
+	}
+}
+
+func TestConcurrentAddsKeepEveryEntry(t *testing.T) {
+	projectPath := t.TempDir()
+	if err := setupUnityProject(projectPath); err != nil {
+		t.Fatalf("failed to setup Unity project: %v", err)
+	}
+
+	const adds = 8
+	errs := make(chan error, adds)
+	for i := 0; i < adds; i++ {
+		go func(i int) {
+			_, err := engines.NewUnityAdapter().InstallPackage(projectPath, &engines.PackageInstallRequest{
+				Name:    fmt.Sprintf("com.test.concurrent%d", i),
+				Version: "1.0.0",
+			})
+			errs <- err
+		}(i)
+	}
+	for i := 0; i < adds; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("concurrent add failed: %v", err)
+		}
+	}
+
+	packages, err := engines.NewUnityAdapter().ListPackages(projectPath)
+	if err != nil {
+		t.Fatalf("failed to list packages: %v", err)
+	}
+	installed := make(map[string]bool)
+	for _, pkg := range packages {
+		installed[pkg.Name] = true
+	}
+	for i := 0; i < adds; i++ {
+		if name := fmt.Sprintf("com.test.concurrent%d", i); !installed[name] {
+			t.Errorf("Expected %s in the manifest after concurrent adds, got %v", name, packages)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "Packages", "manifest.json.lock")); !os.IsNotExist(err) {
+		t.Errorf("Expected the manifest lock to be released")
+	}
+}
+
+func TestAddWithoutScopedRegistry(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.homa.sdk", &api.PackageMetadata{
+		Name:     "com.homa.sdk",
+		DistTags: map[string]string{"latest": "1.0.0"},
+		Versions: map[string]*api.PackageVersion{
+			"1.0.0": {Name: "com.homa.sdk", Version: "1.0.0"},
+		},
+	})
+
+	projectPath := t.TempDir()
+	if err := setupUnityProject(projectPath); err != nil {
+		t.Fatalf("failed to setup Unity project: %v", err)
+	}
+	manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+		t.Fatalf("failed to create Packages directory: %v", err)
+	}
+	// A studio-managed registry that deliberately doesn't cover com.homa
+	original := `{
+  "dependencies": {
+    "com.unity.ugui": "1.0.0"
+  },
+  "scopedRegistries": [
+    {
+      "name": "Studio",
+      "url": "https://upm.studio.example",
+      "scopes": [
+        "com.studio"
+      ]
+    }
+  ]
+}`
+	if err := os.WriteFile(manifestPath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	output := &AddOutput{Details: make(map[string]any)}
+	if err := executeAddWithFlags("com.homa.sdk@1.0.0", output, addOptions{project: projectPath, engine: "auto", registry: mockRegistry.URL(), noScopedRegistry: true}); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+	var manifest engines.UnityManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("failed to parse manifest: %v", err)
+	}
+
+	if manifest.Dependencies["com.homa.sdk"] != "1.0.0" {
+		t.Errorf("Expected com.homa.sdk@1.0.0 in dependencies, got %v", manifest.Dependencies)
+	}
+	if len(manifest.ScopedRegistries) != 1 || manifest.ScopedRegistries[0].URL != "https://upm.studio.example" ||
+		len(manifest.ScopedRegistries[0].Scopes) != 1 || manifest.ScopedRegistries[0].Scopes[0] != "com.studio" {
+		t.Errorf("Expected scopedRegistries to be left alone, got %+v", manifest.ScopedRegistries)
+	}
+
+	warned := false
+	for _, warning := range output.Warnings {
+		if strings.Contains(warning, "make sure Unity can resolve com.homa.sdk") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Errorf("Expected a warning about resolving the package, got %v", output.Warnings)
+	}
+}
+
+func TestAddAliasSpec(t *testing.T) {
+	t.Run("parse", func(t *testing.T) {
+		alias, target := splitAliasSpec("my-ui@npm:com.vendor.ui@^1")
+		if alias != "my-ui" || target != "com.vendor.ui@^1" {
+			t.Errorf("splitAliasSpec = %q, %q", alias, target)
+		}
+		if alias, target := splitAliasSpec("com.vendor.ui@1.0.0"); alias != "" || target != "com.vendor.ui@1.0.0" {
+			t.Errorf("spec without alias changed: %q, %q", alias, target)
+		}
+
+		spec := parsePackageSpec("my-ui@npm:com.vendor.ui@1.0.0")
+		if spec.Alias != "my-ui" || spec.Name != "com.vendor.ui" || spec.Version != "1.0.0" || spec.Source != "registry" {
+			t.Errorf("unexpected spec %+v", spec)
+		}
+	})
+
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.vendor.ui", &api.PackageMetadata{
+		Name:     "com.vendor.ui",
+		DistTags: map[string]string{"latest": "1.2.0"},
+		Versions: map[string]*api.PackageVersion{
+			"1.0.0": {Name: "com.vendor.ui", Version: "1.0.0"},
+			"1.2.0": {Name: "com.vendor.ui", Version: "1.2.0"},
+		},
+	})
+	tarball, integrity := writeTestTarball(t, map[string]string{
+		"package/package.json": `{"name": "com.vendor.ui", "version": "1.0.0"}`,
+		"package/plugin.cfg":   "[plugin]\n",
+	})
+	mockRegistry.AddTarball("com.vendor.ui", "1.0.0", tarball, integrity)
+	allowLoopbackTarballs(t)
+
+	t.Run("godot records the alias", func(t *testing.T) {
+		projectPath := t.TempDir()
+		if err := os.WriteFile(filepath.Join(projectPath, "project.godot"), []byte("config_version=5\n"), 0644); err != nil {
+			t.Fatalf("failed to create project.godot: %v", err)
+		}
+
+		output := &AddOutput{Details: make(map[string]any)}
+		if err := executeAddWithFlags("my-ui@npm:com.vendor.ui@1.0.0", output, addOptions{project: projectPath, engine: "auto", registry: mockRegistry.URL()}); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+		if output.Package != "com.vendor.ui" || output.Alias != "my-ui" || output.Version != "1.0.0" {
+			t.Errorf("unexpected output %+v", output)
+		}
+
+		data, err := os.ReadFile(filepath.Join(projectPath, engines.GodotManifestFile))
+		if err != nil {
+			t.Fatalf("failed to read manifest: %v", err)
+		}
+		var manifest engines.GodotManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			t.Fatalf("failed to parse manifest: %v", err)
+		}
+		if versions := manifest.Dependencies["my-ui"]; len(versions) != 1 || versions[0] != "1.0.0" {
+			t.Errorf("expected my-ui@1.0.0 in dependencies, got %v", manifest.Dependencies)
+		}
+		if _, ok := manifest.Dependencies["com.vendor.ui"]; ok {
+			t.Errorf("aliased package should not be recorded under its own name")
+		}
+		if manifest.Aliases["my-ui"] != "com.vendor.ui" {
+			t.Errorf("expected my-ui to alias com.vendor.ui, got %v", manifest.Aliases)
+		}
+		if _, err := os.Stat(filepath.Join(projectPath, "addons", "my-ui", "plugin.cfg")); err != nil {
+			t.Errorf("expected the addon extracted into a directory named after the alias: %v", err)
+		}
+
+		// Removing the alias drops its entry from the aliases map too
+		adapter := engines.NewGodotAdapter()
+		if err := adapter.RemovePackage(projectPath, "my-ui"); err != nil {
+			t.Fatalf("remove failed: %v", err)
+		}
+		data, err = os.ReadFile(filepath.Join(projectPath, engines.GodotManifestFile))
+		if err != nil {
+			t.Fatalf("failed to read manifest: %v", err)
+		}
+		if strings.Contains(string(data), "aliases") {
+			t.Errorf("expected aliases to be removed, got %s", data)
+		}
+	})
+
+	t.Run("unity rejects aliases", func(t *testing.T) {
+		projectPath := t.TempDir()
+		if err := setupUnityProject(projectPath); err != nil {
+			t.Fatalf("failed to setup Unity project: %v", err)
+		}
+
+		output := &AddOutput{Details: make(map[string]any)}
+		err := executeAddWithFlags("my-ui@npm:com.vendor.ui@1.0.0", output, addOptions{project: projectPath, engine: "auto", registry: mockRegistry.URL()})
+		if err == nil || !strings.Contains(err.Error(), "can't be added as my-ui") {
+			t.Fatalf("expected an alias error, got %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(projectPath, "Packages", "manifest.json")); !os.IsNotExist(err) {
+			t.Errorf("manifest should not be written")
+		}
+
+		_, err = engines.NewUnityAdapter().InstallPackage(projectPath, &engines.PackageInstallRequest{Name: "com.vendor.ui", Version: "1.0.0", Alias: "my-ui"})
+		if err == nil || !strings.Contains(err.Error(), "package's own name") {
+			t.Errorf("expected the Unity adapter to reject aliases, got %v", err)
+		}
+	})
+}
+
+func TestCaseOnlyNameCollision(t *testing.T) {
+	t.Run("unity replaces a miscased entry", func(t *testing.T) {
+		projectPath := t.TempDir()
+		if err := setupUnityProject(projectPath); err != nil {
+			t.Fatalf("failed to setup Unity project: %v", err)
+		}
+		manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+		if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+			t.Fatalf("failed to create Packages directory: %v", err)
+		}
+		if err := os.WriteFile(manifestPath, []byte(`{"dependencies": {"com.X.y": "1.0.0"}}`), 0644); err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+
+		adapter := engines.NewUnityAdapter()
+		result, err := adapter.InstallPackage(projectPath, &engines.PackageInstallRequest{Name: "com.x.y", Version: "1.1.0"})
+		if err != nil {
+			t.Fatalf("install failed: %v", err)
+		}
+		if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "replaced com.X.y with com.x.y") {
+			t.Errorf("expected the collision to be reported, got %v", result.Warnings)
+		}
+
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			t.Fatalf("failed to read manifest: %v", err)
+		}
+		var manifest engines.UnityManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			t.Fatalf("failed to parse manifest: %v", err)
+		}
+		if len(manifest.Dependencies) != 1 || manifest.Dependencies["com.x.y"] != "1.1.0" {
+			t.Errorf("expected only com.x.y@1.1.0, got %v", manifest.Dependencies)
+		}
+
+		// The miscased spelling never replaces a valid one
+		_, err = adapter.InstallPackage(projectPath, &engines.PackageInstallRequest{Name: "com.X.y", Version: "1.0.0"})
+		if err == nil || !strings.Contains(err.Error(), "only by case") {
+			t.Errorf("expected a case collision error, got %v", err)
+		}
+	})
+
+	t.Run("godot rejects the collision", func(t *testing.T) {
+		projectPath := t.TempDir()
+		if err := os.WriteFile(filepath.Join(projectPath, "project.godot"), []byte("config_version=5\n"), 0644); err != nil {
+			t.Fatalf("failed to create project.godot: %v", err)
+		}
+
+		adapter := engines.NewGodotAdapter()
+		if _, err := adapter.InstallPackage(projectPath, &engines.PackageInstallRequest{Name: "com.X.y", Version: "1.0.0"}); err != nil {
+			t.Fatalf("install failed: %v", err)
+		}
+		_, err := adapter.InstallPackage(projectPath, &engines.PackageInstallRequest{Name: "com.x.y", Version: "1.0.0"})
+		if err == nil || !strings.Contains(err.Error(), "already installed as com.X.y") {
+			t.Errorf("expected a case collision error, got %v", err)
+		}
+	})
+}
+
+func TestAddDefaultDistTag(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.test.channel", &api.PackageMetadata{
+		Name:     "com.test.channel",
+		DistTags: map[string]string{"latest": "1.0.0", "beta": "2.0.0-beta.1"},
+		Versions: map[string]*api.PackageVersion{
+			"1.0.0":        {Name: "com.test.channel", Version: "1.0.0"},
+			"2.0.0-beta.1": {Name: "com.test.channel", Version: "2.0.0-beta.1"},
+		},
+	})
+
+	add := func(t *testing.T, spec, tag string) (*AddOutput, map[string]string) {
+		projectPath := t.TempDir()
+		if err := setupUnityProject(projectPath); err != nil {
+			t.Fatalf("failed to setup Unity project: %v", err)
+		}
+		output := &AddOutput{Details: make(map[string]any)}
+		if err := executeAddWithFlags(spec, output, addOptions{project: projectPath, engine: "auto", registry: mockRegistry.URL(), defaultTag: tag}); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(projectPath, "Packages", "manifest.json"))
+		if err != nil {
+			t.Fatalf("failed to read manifest: %v", err)
+		}
+		var manifest engines.UnityManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			t.Fatalf("failed to parse manifest: %v", err)
+		}
+		return output, manifest.Dependencies
+	}
+
+	t.Run("bare name resolves through the tag", func(t *testing.T) {
+		output, deps := add(t, "com.test.channel", "beta")
+		if deps["com.test.channel"] != "2.0.0-beta.1" || output.Version != "2.0.0-beta.1" {
+			t.Errorf("expected the beta version, got %v", deps)
+		}
+		if len(output.Warnings) != 0 {
+			t.Errorf("expected no warnings, got %v", output.Warnings)
+		}
+	})
+
+	t.Run("explicit version wins", func(t *testing.T) {
+		_, deps := add(t, "com.test.channel@1.0.0", "beta")
+		if deps["com.test.channel"] != "1.0.0" {
+			t.Errorf("expected 1.0.0, got %v", deps)
+		}
+	})
+
+	t.Run("explicit tag", func(t *testing.T) {
+		_, deps := add(t, "com.test.channel@beta", "")
+		if deps["com.test.channel"] != "2.0.0-beta.1" {
+			t.Errorf("expected the beta version, got %v", deps)
+		}
+	})
+
+	t.Run("missing tag falls back to latest", func(t *testing.T) {
+		output, deps := add(t, "com.test.channel", "stable")
+		if deps["com.test.channel"] != "1.0.0" {
+			t.Errorf("expected latest, got %v", deps)
+		}
+		if len(output.Warnings) != 1 || !strings.Contains(output.Warnings[0], `no "stable" dist-tag; using latest (1.0.0)`) {
+			t.Errorf("expected a missing tag warning, got %v", output.Warnings)
+		}
+	})
+
+	t.Run("default_tag config", func(t *testing.T) {
+		config.SetConfigForTesting(&config.Config{DefaultTag: "beta"})
+		defer config.ResetConfigForTesting()
+		if tag := defaultDistTag(""); tag != "beta" {
+			t.Errorf("expected the configured tag, got %q", tag)
+		}
+		if tag := defaultDistTag("rc"); tag != "rc" {
+			t.Errorf("expected --tag to win, got %q", tag)
+		}
+	})
+}
+
+func TestAddRecordsPackageOrigin(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.test.channel", &api.PackageMetadata{
+		Name:     "com.test.channel",
+		DistTags: map[string]string{"latest": "1.0.0", "beta": "1.1.0-beta.2"},
+		Versions: map[string]*api.PackageVersion{
+			"1.0.0":        {Name: "com.test.channel", Version: "1.0.0"},
+			"1.0.1":        {Name: "com.test.channel", Version: "1.0.1"},
+			"1.1.0-beta.2": {Name: "com.test.channel", Version: "1.1.0-beta.2"},
+		},
+	})
+
+	projectPath := t.TempDir()
+	if err := setupUnityProject(projectPath); err != nil {
+		t.Fatalf("failed to setup Unity project: %v", err)
+	}
+	// A project upgraded from gpm-ranges.json keeps its ranges
+	if err := os.MkdirAll(filepath.Join(projectPath, "Packages"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectPath, "Packages", engines.UnityRangesFile), []byte(`{"com.test.other": "~2.0.0"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		spec       string
+		defaultTag string
+		want       engines.PackageOrigin
+	}{
+		{"com.test.channel", "", engines.PackageOrigin{Tag: "latest", Version: "1.0.0"}},
+		{"com.test.channel@beta", "", engines.PackageOrigin{Tag: "beta", Version: "1.1.0-beta.2"}},
+		{"com.test.channel", "rc", engines.PackageOrigin{Tag: "rc", Version: "1.0.0"}},
+		{"com.test.channel@~1.0.0", "", engines.PackageOrigin{Range: "~1.0.0", Version: "1.0.1"}},
+		{"com.test.channel@1.0.0", "", engines.PackageOrigin{Version: "1.0.0"}},
+	}
+	for _, tt := range tests {
+		output := &AddOutput{Details: make(map[string]any)}
+		if err := executeAddWithFlags(tt.spec, output, addOptions{project: projectPath, engine: "auto", registry: mockRegistry.URL(), defaultTag: tt.defaultTag}); err != nil {
+			t.Fatalf("add %s failed: %v", tt.spec, err)
+		}
+		origins, err := engines.NewUnityAdapter().PackageOrigins(projectPath)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", engines.UnityPackagesFile, err)
+		}
+		tt.want.Registry = mockRegistry.URL()
+		if origins["com.test.channel"] != tt.want {
+			t.Errorf("add %s (default tag %q): expected %+v, got %+v", tt.spec, tt.defaultTag, tt.want, origins["com.test.channel"])
+		}
+		if origins["com.test.other"].Range != "~2.0.0" {
+			t.Errorf("expected the legacy range to be kept, got %+v", origins)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectPath, "Packages", "manifest.json"))
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+	if !strings.Contains(string(data), `"com.test.channel": "1.0.0"`) {
+		t.Errorf("expected the manifest to hold only the exact version:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "Packages", engines.UnityRangesFile)); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be folded into %s, got %v", engines.UnityRangesFile, engines.UnityPackagesFile, err)
+	}
+}
+
+func TestAddKeepsStateInProjectStateDir(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.test.state", &api.PackageMetadata{
+		Name:     "com.test.state",
+		DistTags: map[string]string{"latest": "1.0.0"},
+		Versions: map[string]*api.PackageVersion{
+			"1.0.0": {Name: "com.test.state", Version: "1.0.0"},
+		},
+	})
+
+	projectPath := t.TempDir()
+	if err := setupUnityProject(projectPath); err != nil {
+		t.Fatalf("failed to setup Unity project: %v", err)
+	}
+	// A sidecar older gpm versions wrote next to the manifest moves over
+	legacyPath := filepath.Join(projectPath, "Packages", engines.UnityPackagesFile)
+	if err := os.MkdirAll(filepath.Dir(legacyPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectPath, "Packages", "manifest.json"), []byte(`{"dependencies": {}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacyPath, []byte(`{"com.test.other": {"range": "~2.0.0", "version": "2.0.1"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	output := &AddOutput{Details: make(map[string]any)}
+	if err := executeAddWithFlags("com.test.state", output, addOptions{project: projectPath, engine: "auto", registry: mockRegistry.URL()}); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	stateDir := filepath.Join(projectPath, engines.StateDirName)
+	if filepath.Dir(output.BackupPath) != filepath.Join(stateDir, "backups") {
+		t.Errorf("expected the backup under %s, got %s", stateDir, output.BackupPath)
+	}
+	if _, err := os.Stat(filepath.Join(output.BackupPath, "manifest.json")); err != nil {
+		t.Errorf("expected the manifest to be backed up: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(stateDir, engines.UnityPackagesFile))
+	if err != nil {
+		t.Fatalf("expected %s in the state directory: %v", engines.UnityPackagesFile, err)
+	}
+	if !strings.Contains(string(data), "com.test.state") || !strings.Contains(string(data), "com.test.other") {
+		t.Errorf("expected both packages to be recorded:\n%s", data)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Errorf("expected the legacy %s to be removed, got %v", engines.UnityPackagesFile, err)
+	}
+
+	t.Run("found from a subdirectory", func(t *testing.T) {
+		if got := projectStateDir(filepath.Join(projectPath, "Assets")); got != stateDir {
+			t.Errorf("expected %s, got %s", stateDir, got)
+		}
+	})
+
+	t.Run("state_dir relocates it", func(t *testing.T) {
+		config.SetConfigForTesting(&config.Config{StateDir: filepath.Join("Library", "gpm")})
+		defer config.ResetConfigForTesting()
+		if got := projectStateDir(projectPath); got != filepath.Join(projectPath, "Library", "gpm") {
+			t.Errorf("expected a relative state_dir to be inside the project, got %s", got)
+		}
+
+		shared := t.TempDir()
+		config.SetConfigForTesting(&config.Config{StateDir: shared})
+		got := projectStateDir(projectPath)
+		if filepath.Dir(got) != shared || !strings.HasPrefix(filepath.Base(got), filepath.Base(projectPath)+"-") {
+			t.Errorf("expected a per-project directory under %s, got %s", shared, got)
+		}
+		if other := projectStateDir(t.TempDir()); other == got {
+			t.Errorf("expected projects to get their own directories, both got %s", got)
+		}
+	})
+}
+
+func TestAddResolvesRange(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.test.ranged", &api.PackageMetadata{
+		Name:     "com.test.ranged",
+		DistTags: map[string]string{"latest": "2.0.0"},
+		Versions: map[string]*api.PackageVersion{
+			"1.1.0":        {Name: "com.test.ranged", Version: "1.1.0"},
+			"1.2.0":        {Name: "com.test.ranged", Version: "1.2.0"},
+			"1.3.0":        {Name: "com.test.ranged", Version: "1.3.0"},
+			"1.4.0-beta.1": {Name: "com.test.ranged", Version: "1.4.0-beta.1"},
+			"2.0.0":        {Name: "com.test.ranged", Version: "2.0.0"},
+		},
+	})
+
+	projectPath := t.TempDir()
+	if err := setupUnityProject(projectPath); err != nil {
+		t.Fatalf("failed to setup Unity project: %v", err)
+	}
+	add := func(spec string) map[string]string {
+		output := &AddOutput{Details: make(map[string]any)}
+		if err := executeAddWithFlags(spec, output, addOptions{project: projectPath, engine: "auto", registry: mockRegistry.URL()}); err != nil {
+			t.Fatalf("add %s failed: %v", spec, err)
+		}
+		data, err := os.ReadFile(filepath.Join(projectPath, "Packages", "manifest.json"))
+		if err != nil {
+			t.Fatalf("failed to read manifest: %v", err)
+		}
+		var manifest engines.UnityManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			t.Fatalf("failed to parse manifest: %v", err)
+		}
+		return manifest.Dependencies
+	}
+
+	deps := add("com.test.ranged@^1.2.0")
+	if deps["com.test.ranged"] != "1.3.0" {
+		t.Errorf("expected ^1.2.0 to write 1.3.0 into the manifest, got %q", deps["com.test.ranged"])
+	}
+	ranges, err := engines.NewUnityAdapter().RequestedRanges(projectPath)
+	if err != nil {
+		t.Fatalf("failed to read ranges: %v", err)
+	}
+	if ranges["com.test.ranged"] != "^1.2.0" {
+		t.Errorf("expected the range to be recorded, got %v", ranges)
+	}
+
+	// Pinning an exact version forgets the range
+	add("com.test.ranged@1.2.0")
+	if _, err := os.Stat(filepath.Join(projectPath, "Packages", engines.UnityRangesFile)); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", engines.UnityRangesFile, err)
+	}
+
+	output := &AddOutput{Details: make(map[string]any)}
+	err = executeAddWithFlags("com.test.ranged@^3.0.0", output, addOptions{project: projectPath, engine: "auto", registry: mockRegistry.URL()})
+	if err == nil || !strings.Contains(err.Error(), "not available") {
+		t.Errorf("expected an unsatisfiable range to fail, got %v", err)
+	}
+}
+
+func TestAddVerify(t *testing.T) {
+	t.Run("rejects a tarball naming another package", func(t *testing.T) {
+		tarball, _ := writeTestTarball(t, map[string]string{
+			"package/package.json": `{"name": "com.test.other", "version": "1.0.0"}`,
+		})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(tarball)
+		}))
+		defer server.Close()
+		allowLoopbackTarballs(t)
+
+		projectPath := t.TempDir()
+		if err := setupUnityProject(projectPath); err != nil {
+			t.Fatalf("failed to set up project: %v", err)
+		}
+
+		output := &AddOutput{Details: make(map[string]any)}
+		err := executeAddWithFlags(server.URL+"/com.test.expected/-/com.test.expected-1.0.0.tgz", output, addOptions{project: projectPath, engine: "auto", registry: server.URL, verify: true})
+		if err == nil || !strings.Contains(err.Error(), "Tarball for com.test.expected contains com.test.other") {
+			t.Fatalf("expected the renamed tarball to be rejected, got %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(projectPath, "Packages", "manifest.json")); !os.IsNotExist(err) {
+			t.Errorf("expected no manifest to be written, got %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(projectPath, "Packages", "com.test.other")); !os.IsNotExist(err) {
+			t.Errorf("expected nothing to be extracted, got %v", err)
+		}
+	})
+
+	t.Run("restores the manifest when verification fails", func(t *testing.T) {
+		mockRegistry := NewMockRegistry()
+		defer mockRegistry.Close()
+		mockRegistry.AddPackage("com.test.verified", &api.PackageMetadata{
+			Name:     "com.test.verified",
+			DistTags: map[string]string{"latest": "1.0.0"},
+			Versions: map[string]*api.PackageVersion{
+				"1.0.0": {Name: "com.test.verified", Version: "1.0.0"},
+			},
+		})
+
+		projectPath := t.TempDir()
+		if err := setupUnityProject(projectPath); err != nil {
+			t.Fatalf("failed to set up project: %v", err)
+		}
+		manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+		if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+			t.Fatalf("failed to create Packages: %v", err)
+		}
+		broken := `{"dependencies": {}, "scopedRegistries": [{"name": "", "url": "https://stale.example.com", "scopes": ["com.stale"]}]}`
+		if err := os.WriteFile(manifestPath, []byte(broken), 0644); err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+
+		output := &AddOutput{Details: make(map[string]any)}
+		err := executeAddWithFlags("com.test.verified@1.0.0", output, addOptions{project: projectPath, engine: "auto", registry: mockRegistry.URL(), verify: true})
+		if err == nil || !strings.Contains(err.Error(), "scoped registry https://stale.example.com has no name") {
+			t.Fatalf("expected verification to fail, got %v", err)
+		}
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			t.Fatalf("failed to read manifest: %v", err)
+		}
+		if string(data) != broken {
+			t.Errorf("expected the manifest to be restored, got %s", data)
+		}
+	})
+}
+
+func TestAddSuggestsSimilarPackages(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.unity.analytics", &api.PackageMetadata{
+		Name:     "com.unity.analytics",
+		DistTags: map[string]string{"latest": "1.0.0"},
+		Versions: map[string]*api.PackageVersion{
+			"1.0.0": {Name: "com.unity.analytics", Version: "1.0.0", Dist: &api.PackageDist{Tarball: "http://example.invalid/analytics-1.0.0.tgz"}},
+		},
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/-/v1/search" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"objects":[{"package":{"name":"com.unity.analytics","version":"1.0.0","description":"Analytics"}}],"total":1}`)
+			return
+		}
+		if r.URL.Path == "/com.unity.analytcs" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":"not found"}`)
+			return
+		}
+		mockRegistry.handler(w, r)
+	}))
+	defer server.Close()
+
+	interactive, choose := terminalIsInteractive, choosePackage
+	defer func() { terminalIsInteractive, choosePackage = interactive, choose }()
+	terminalIsInteractive = func() bool { return true }
+
+	projectPath := t.TempDir()
+	if err := setupUnityProject(projectPath); err != nil {
+		t.Fatalf("failed to setup Unity project: %v", err)
+	}
+	// runAddCommand resets its flags, so each run sets them again
+	setFlags := func(t *testing.T, yes string) {
+		for flag, value := range map[string]string{"project": projectPath, "registry": server.URL, "dry-run": "true", "yes": yes} {
+			if err := addCmd.Flags().Set(flag, value); err != nil {
+				t.Fatalf("failed to set --%s: %v", flag, err)
+			}
+		}
+	}
+
+	t.Run("--yes skips the prompt", func(t *testing.T) {
+		prompted := false
+		choosePackage = func(string, []packageSuggestion) (string, error) {
+			prompted = true
+			return "", nil
+		}
+		setFlags(t, "true")
+
+		var err error
+		captureStdout(t, func() error {
+			err = runAddCommand(addCmd, []string{"com.unity.analytcs"})
+			return nil
+		})
+		if err == nil || !strings.Contains(err.Error(), "package 'com.unity.analytcs' not found in registry") {
+			t.Fatalf("expected the not-found error, got %v", err)
+		}
+		if prompted {
+			t.Error("--yes must not prompt")
+		}
+	})
+
+	t.Run("the picked package is added", func(t *testing.T) {
+		var offered []packageSuggestion
+		choosePackage = func(_ string, suggestions []packageSuggestion) (string, error) {
+			offered = suggestions
+			return suggestions[0].Name, nil
+		}
+		setFlags(t, "false")
+
+		var buf bytes.Buffer
+		addCmd.SetOut(&buf)
+		defer addCmd.SetOut(nil)
+		err := runAddCommand(addCmd, []string{"com.unity.analytcs"})
+		output := buf.String()
+		if err != nil {
+			t.Fatalf("expected the picked package to be added: %v", err)
+		}
+		if len(offered) != 1 || offered[0].Name != "com.unity.analytics" || offered[0].Version != "1.0.0" {
+			t.Errorf("unexpected suggestions %+v", offered)
+		}
+		if !strings.Contains(output, "com.unity.analytics") {
+			t.Errorf("expected the picked package in the output:\n%s", output)
+		}
+	})
+}
@@ -19,11 +19,13 @@ import (
 type MockRegistry struct {
 	server   *httptest.Server
 	packages map[string]*api.PackageMetadata
+	tarballs map[string][]byte
 }
 
 func NewMockRegistry() *MockRegistry {
 	mr := &MockRegistry{
 		packages: make(map[string]*api.PackageMetadata),
+		tarballs: make(map[string][]byte),
 	}
 
 	mr.server = httptest.NewServer(http.HandlerFunc(mr.handler))
@@ -42,7 +44,20 @@ func (mr *MockRegistry) AddPackage(name string, metadata *api.PackageMetadata) {
 	mr.packages[name] = metadata
 }
 
+// AddTarball serves tarball as a version of a package already added, and
+// points the version's dist at it
+func (mr *MockRegistry) AddTarball(name, version string, tarball []byte, integrity string) {
+	path := "/" + name + "/-/" + name + "-" + version + ".tgz"
+	mr.tarballs[path] = tarball
+	mr.packages[name].Versions[version].Dist = &api.PackageDist{Tarball: mr.server.URL + path, Integrity: integrity}
+}
+
 func (mr *MockRegistry) handler(w http.ResponseWriter, r *http.Request) {
+	if tarball, ok := mr.tarballs[r.URL.Path]; ok {
+		_, _ = w.Write(tarball)
+		return
+	}
+
 	// Handle package metadata requests
 	if strings.HasPrefix(r.URL.Path, "/") && !strings.HasPrefix(r.URL.Path, "/-/") {
 		packageName := strings.TrimPrefix(r.URL.Path, "/")
@@ -87,6 +102,10 @@ func marshalVersions(versions map[string]*api.PackageVersion) string {
 
 	parts := []string{}
 	for k, v := range versions {
+		if v.Dist != nil {
+			parts = append(parts, fmt.Sprintf(`"%s":{"name":"%s","version":"%s","dist":{"tarball":"%s","integrity":"%s"}}`, k, v.Name, v.Version, v.Dist.Tarball, v.Dist.Integrity))
+			continue
+		}
 		parts = append(parts, fmt.Sprintf(`"%s":{"name":"%s","version":"%s"}`, k, v.Name, v.Version))
 	}
 	return "{" + strings.Join(parts, ",") + "}"
@@ -407,7 +426,7 @@ func TestAddRollbackOnFailure(t *testing.T) {
 	}
 
 	// Create backup
-	backupPath, err := createProjectBackup(tmpDir, engines.EngineUnity)
+	backupPath, err := createProjectBackup(tmpDir, engines.EngineUnity, "")
 	if err != nil {
 		t.Fatalf("backup failed: %v", err)
 	}
@@ -419,7 +438,7 @@ func TestAddRollbackOnFailure(t *testing.T) {
 	}
 
 	// Test restore
-	if err := restoreFromBackup(backupPath, tmpDir, engines.EngineUnity); err != nil {
+	if err := restoreFromBackup(backupPath, tmpDir, engines.EngineUnity, ""); err != nil {
 		t.Fatalf("restore failed: %v", err)
 	}
 
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+	"gpm.sh/gpm/gpm-cli/internal/validation"
+)
+
+// BundleFile is the project file that stores named package bundles
+const BundleFile = "gpm-bundle.json"
+
+var bundleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+var bundleProjectDir string
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Manage named package bundles",
+	Long: `Manage named bundles: curated sets of packages and versions that are
+installed together with 'gpm install --bundle <name>'.
+
+Bundles are stored in ` + BundleFile + ` at the project root so they can be
+committed and shared.
+
+Examples:
+  gpm bundle create core-tools com.company.sdk@1.2.0 com.company.ads@2.0.1
+  gpm bundle add core-tools com.company.analytics@1.0.0
+  gpm bundle ls
+  gpm install --bundle core-tools`,
+}
+
+var bundleCreateCmd = &cobra.Command{
+	Use:   "create <name> [package[@version]...]",
+	Short: "Create a bundle",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createBundle(bundleDir(), args[0], args[1:])
+	},
+}
+
+var bundleAddCmd = &cobra.Command{
+	Use:   "add <name> <package[@version]>...",
+	Short: "Add packages to a bundle",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return addToBundle(bundleDir(), args[0], args[1:])
+	},
+}
+
+var bundleListCmd = &cobra.Command{
+	Use:     "ls [name]",
+	Aliases: []string{"list"},
+	Short:   "List bundles and their packages",
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		return listBundles(bundleDir(), name)
+	},
+}
+
+func init() {
+	bundleCmd.AddCommand(bundleCreateCmd)
+	bundleCmd.AddCommand(bundleAddCmd)
+	bundleCmd.AddCommand(bundleListCmd)
+
+	bundleCmd.PersistentFlags().StringVar(&bundleProjectDir, "project-dir", "", "Project directory holding "+BundleFile+" (default: current directory)")
+}
+
+// Bundles maps bundle names to their members, package name to version
+type Bundles map[string]map[string]string
+
+type bundleFileContent struct {
+	Bundles Bundles `json:"bundles"`
+}
+
+func bundleDir() string {
+	if bundleProjectDir != "" {
+		return bundleProjectDir
+	}
+	return "."
+}
+
+// loadBundles reads the bundles defined in projectDir. A missing file means
+// no bundles.
+func loadBundles(projectDir string) (Bundles, error) {
+	path := filepath.Join(projectDir, BundleFile)
+	data, err := os.ReadFile(path) // #nosec G304 - fixed file name inside the project directory
+	if os.IsNotExist(err) {
+		return Bundles{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", BundleFile, err)
+	}
+
+	var content bundleFileContent
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", BundleFile, err)
+	}
+	if content.Bundles == nil {
+		content.Bundles = Bundles{}
+	}
+	return content.Bundles, nil
+}
+
+func saveBundles(projectDir string, bundles Bundles) error {
+	data, err := json.MarshalIndent(bundleFileContent{Bundles: bundles}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundles: %w", err)
+	}
+	return os.WriteFile(filepath.Join(projectDir, BundleFile), append(data, '\n'), 0600)
+}
+
+// bundleSpecs returns a bundle's members as package@version specs, sorted by
+// package name
+func bundleSpecs(projectDir, name string) ([]string, error) {
+	bundles, err := loadBundles(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	members, ok := bundles[name]
+	if !ok {
+		return nil, fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("bundle %q not found in %s", name, BundleFile)),
+			styling.Hint("Create it with 'gpm bundle create "+name+" <package[@version]>...'"))
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("bundle %q has no packages", name)
+	}
+
+	specs := make([]string, 0, len(members))
+	for _, pkg := range sortedBundleMembers(members) {
+		specs = append(specs, pkg+"@"+members[pkg])
+	}
+	return specs, nil
+}
+
+// setBundleMembers parses package[@version] specs into members; a spec
+// without a version installs the latest one
+func setBundleMembers(members map[string]string, specs []string) error {
+	for _, spec := range specs {
+		name, version, err := parseAddPackageSpec(spec)
+		if err != nil {
+			return fmt.Errorf("invalid package specification %q: %w", spec, err)
+		}
+		if err := validation.ValidatePackageName(name); err != nil {
+			return fmt.Errorf("invalid package name: %w", err)
+		}
+		if version == "" {
+			version = "latest"
+		}
+		members[name] = version
+	}
+	return nil
+}
+
+func validateBundleName(name string) error {
+	if !bundleNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid bundle name %q: use lowercase letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+func createBundle(projectDir, name string, specs []string) error {
+	if err := validateBundleName(name); err != nil {
+		return err
+	}
+
+	bundles, err := loadBundles(projectDir)
+	if err != nil {
+		return err
+	}
+	if _, exists := bundles[name]; exists {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("bundle %q already exists", name)),
+			styling.Hint("Add packages to it with 'gpm bundle add "+name+" <package[@version]>...'"))
+	}
+
+	members := make(map[string]string)
+	if err := setBundleMembers(members, specs); err != nil {
+		return err
+	}
+	bundles[name] = members
+	if err := saveBundles(projectDir, bundles); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s (%d packages)\n", styling.Success("Bundle created:"), styling.Value(name), len(members))
+	return nil
+}
+
+func addToBundle(projectDir, name string, specs []string) error {
+	bundles, err := loadBundles(projectDir)
+	if err != nil {
+		return err
+	}
+	members, exists := bundles[name]
+	if !exists {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("bundle %q not found in %s", name, BundleFile)),
+			styling.Hint("Create it with 'gpm bundle create "+name+"'"))
+	}
+	if members == nil {
+		members = make(map[string]string)
+		bundles[name] = members
+	}
+
+	if err := setBundleMembers(members, specs); err != nil {
+		return err
+	}
+	if err := saveBundles(projectDir, bundles); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s (%d packages)\n", styling.Success("Bundle updated:"), styling.Value(name), len(members))
+	return nil
+}
+
+// saveToBundle sets members in a bundle, creating the bundle if it doesn't
+// exist yet
+func saveToBundle(projectDir, name string, members map[string]string) error {
+	if err := validateBundleName(name); err != nil {
+		return err
+	}
+
+	bundles, err := loadBundles(projectDir)
+	if err != nil {
+		return err
+	}
+	if bundles[name] == nil {
+		bundles[name] = make(map[string]string)
+	}
+	for pkg, version := range members {
+		bundles[name][pkg] = version
+	}
+	return saveBundles(projectDir, bundles)
+}
+
+func listBundles(projectDir, name string) error {
+	bundles, err := loadBundles(projectDir)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(bundles))
+	for bundle := range bundles {
+		names = append(names, bundle)
+	}
+	sort.Strings(names)
+	if name != "" {
+		if _, exists := bundles[name]; !exists {
+			return fmt.Errorf("bundle %q not found in %s", name, BundleFile)
+		}
+		names = []string{name}
+	}
+
+	fmt.Println(styling.Header("GPM Bundles"))
+	fmt.Println(styling.Separator())
+	if len(names) == 0 {
+		fmt.Println(styling.Muted("No bundles defined. Create one with 'gpm bundle create <name>'."))
+		return nil
+	}
+	for _, bundle := range names {
+		fmt.Printf("%s %s\n", styling.Value(bundle), styling.Muted(fmt.Sprintf("(%d packages)", len(bundles[bundle]))))
+		for _, pkg := range sortedBundleMembers(bundles[bundle]) {
+			fmt.Printf("  %s@%s\n", styling.Package(pkg), styling.Version(bundles[bundle][pkg]))
+		}
+	}
+	return nil
+}
+
+func sortedBundleMembers(members map[string]string) []string {
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
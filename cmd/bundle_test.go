@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+)
+
+func TestBundleCreateAndInstall(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	for _, pkg := range []struct{ name, version string }{
+		{"com.studio.core", "1.2.0"},
+		{"com.studio.ads", "2.0.1"},
+		{"com.studio.analytics", "1.0.0"},
+	} {
+		mockRegistry.AddPackage(pkg.name, &api.PackageMetadata{
+			Name:     pkg.name,
+			DistTags: map[string]string{"latest": pkg.version},
+			Versions: map[string]*api.PackageVersion{
+				pkg.version: {Name: pkg.name, Version: pkg.version},
+			},
+		})
+	}
+
+	projectDir := t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+
+	require.NoError(t, createBundle(projectDir, "core-tools", []string{"com.studio.core@1.2.0", "com.studio.ads@2.0.1"}))
+	require.NoError(t, addToBundle(projectDir, "core-tools", []string{"com.studio.analytics@1.0.0"}))
+	assert.Error(t, createBundle(projectDir, "core-tools", nil), "duplicate bundles are rejected")
+	assert.Error(t, addToBundle(projectDir, "missing", []string{"com.studio.core"}))
+	assert.Error(t, createBundle(projectDir, "Core Tools", nil), "bundle names are validated")
+	require.NoError(t, listBundles(projectDir, ""))
+
+	specs, err := bundleSpecs(projectDir, "core-tools")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"com.studio.ads@2.0.1", "com.studio.analytics@1.0.0", "com.studio.core@1.2.0"}, specs)
+
+	installBundle = "core-tools"
+	installProjectDir = projectDir
+	installRegistry = mockRegistry.URL()
+	installJSON = true
+	defer func() {
+		installBundle = ""
+		installProjectDir = ""
+		installRegistry = ""
+		installJSON = false
+	}()
+	require.NoError(t, install(installCmd, nil))
+
+	packages, err := engines.NewUnityAdapter().ListPackages(projectDir)
+	require.NoError(t, err)
+	installed := make(map[string]string)
+	for _, pkg := range packages {
+		installed[pkg.Name] = pkg.Version
+	}
+	assert.Equal(t, "1.2.0", installed["com.studio.core"])
+	assert.Equal(t, "2.0.1", installed["com.studio.ads"])
+	assert.Equal(t, "1.0.0", installed["com.studio.analytics"])
+
+	installBundle = "missing"
+	assert.Error(t, install(installCmd, nil))
+}
+
+func TestSaveToBundleCreatesBundle(t *testing.T) {
+	projectDir := t.TempDir()
+
+	require.NoError(t, saveToBundle(projectDir, "ui", map[string]string{"com.studio.ui": "3.1.0"}))
+	require.NoError(t, saveToBundle(projectDir, "ui", map[string]string{"com.studio.icons": "1.0.0"}))
+
+	data, err := os.ReadFile(filepath.Join(projectDir, BundleFile))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bundles": {"ui": {"com.studio.ui": "3.1.0", "com.studio.icons": "1.0.0"}}}`, string(data))
+}
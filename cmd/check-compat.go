@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/config"
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+	"gpm.sh/gpm/gpm-cli/internal/validation"
+)
+
+var (
+	checkCompatUnity       string
+	checkCompatJSON        bool
+	checkCompatStrict      bool
+	checkCompatRegistry    string
+	checkCompatPackagesDir string
+)
+
+// unityEditorVersionPattern matches editor versions such as 2022.3 and
+// 2022.3.10f1
+var unityEditorVersionPattern = regexp.MustCompile(`^\d+\.\d+(\.\d+([abfp]\d+)?)?$`)
+
+var checkCompatCmd = &cobra.Command{
+	Use:   "check-compat --unity <version>",
+	Short: "Check installed packages against a Unity editor version",
+	Long: `Check whether the project's installed packages support a Unity editor
+version before upgrading to it.
+
+Each package's minimum editor version is read from the registry: its "unity"
+and "unityRelease" fields, or a "unity" range in engines. Packages that
+weren't installed from a registry, or that the registry doesn't know, are
+reported as unknown.
+
+Examples:
+  gpm check-compat --unity 2023.2            # Report incompatible packages
+  gpm check-compat --unity 2023.2.5f1 --json # Machine-readable report
+  gpm check-compat --unity 2023.2 --strict   # Fail when any is incompatible`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectDir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		return checkCompat(projectDir)
+	},
+}
+
+func init() {
+	checkCompatCmd.Flags().StringVar(&checkCompatUnity, "unity", "", "Unity editor version to check against, such as 2023.2 or 2023.2.5f1 (required)")
+	checkCompatCmd.Flags().BoolVar(&checkCompatJSON, "json", false, "Output the report as JSON")
+	checkCompatCmd.Flags().BoolVar(&checkCompatStrict, "strict", false, "Exit with an error when any package is incompatible")
+	checkCompatCmd.Flags().StringVar(&checkCompatRegistry, "registry", "", "Registry to read package requirements from")
+	checkCompatCmd.Flags().StringVar(&checkCompatPackagesDir, "packages-dir", "", "Unity packages directory relative to the project (default: $"+engines.UnityPackagesDirEnv+" or Packages)")
+	_ = checkCompatCmd.MarkFlagRequired("unity")
+}
+
+// Compatibility statuses reported by check-compat
+const (
+	compatCompatible   = "compatible"
+	compatIncompatible = "incompatible"
+	compatUnknown      = "unknown"
+)
+
+// PackageCompatibility is one installed package's compatibility with the
+// target editor version
+type PackageCompatibility struct {
+	Package string `json:"package"`
+	Version string `json:"version"`
+	// Requires is the package's unity range, empty when it has none
+	Requires string `json:"requires,omitempty"`
+	Status   string `json:"status"`
+	// Reason explains an incompatible or unknown status
+	Reason string `json:"reason,omitempty"`
+}
+
+// CompatOutput is the check-compat report
+type CompatOutput struct {
+	Unity      string                 `json:"unity"`
+	Compatible bool                   `json:"compatible"`
+	Packages   []PackageCompatibility `json:"packages"`
+}
+
+func checkCompat(projectDir string) error {
+	if !unityEditorVersionPattern.MatchString(checkCompatUnity) {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("Invalid Unity version %q", checkCompatUnity)),
+			styling.Hint("Pass an editor version such as 2023.2 or 2023.2.5f1"))
+	}
+
+	adapter := engines.NewUnityAdapter()
+	adapter.SetPackagesDir(checkCompatPackagesDir)
+	adapter.SetStateDir(projectStateDir(projectDir))
+	packages, err := adapter.ListPackages(projectDir)
+	if err != nil {
+		return fmt.Errorf("failed to list installed packages: %w", err)
+	}
+	origins, err := adapter.PackageOrigins(projectDir)
+	if err != nil {
+		return err
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+
+	defaultRegistry := config.ResolveRegistry(checkCompatRegistry, projectDir)
+	clients := make(map[string]*api.Client)
+	output := &CompatOutput{Unity: checkCompatUnity, Compatible: true, Packages: []PackageCompatibility{}}
+	incompatible := 0
+	for _, pkg := range packages {
+		registry := origins[pkg.Name].Registry
+		if registry == "" || checkCompatRegistry != "" {
+			registry = config.ResolveScopedRegistry(config.PackageScope(pkg.Name), checkCompatRegistry, projectDir).Value
+		}
+		client := clients[registry]
+		if client == nil {
+			client = api.NewClient(registry, registryToken(registry, defaultRegistry, projectDir)).WithContext(commandCtx)
+			clients[registry] = client
+		}
+
+		result := packageCompatibility(client, pkg.Name, pkg.Version, checkCompatUnity)
+		if result.Status == compatIncompatible {
+			incompatible++
+			output.Compatible = false
+		}
+		output.Packages = append(output.Packages, result)
+	}
+
+	if checkCompatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(output); err != nil {
+			return err
+		}
+	} else {
+		printCompatReport(output)
+	}
+
+	if checkCompatStrict && incompatible > 0 {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("%d package(s) are incompatible with Unity %s", incompatible, checkCompatUnity)),
+			styling.Hint("Update or remove them before upgrading, or drop --strict to only report them"))
+	}
+	return nil
+}
+
+// packageCompatibility checks name@version's unity requirement, read from
+// the registry, against the target editor version
+func packageCompatibility(client *api.Client, name, version, unity string) PackageCompatibility {
+	result := PackageCompatibility{Package: name, Version: version, Status: compatUnknown}
+	if validation.ValidateVersion(version) != nil {
+		result.Reason = "not installed from a registry"
+		return result
+	}
+
+	metadata, err := client.GetPackageMetadata(name)
+	var notFound *api.PackageNotFoundError
+	if errors.As(err, &notFound) {
+		result.Reason = "not found in the registry"
+		return result
+	}
+	if err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+	info := metadata.Versions[version]
+	if info == nil {
+		result.Reason = fmt.Sprintf("version %s not found in the registry", version)
+		return result
+	}
+
+	result.Requires = engineConstraints(info)["unity"]
+	problems, _ := checkEngineCompatibility(info, map[string]string{"unity": unity}, false)
+	if len(problems) > 0 {
+		result.Status = compatIncompatible
+		result.Reason = problems[0]
+		return result
+	}
+	result.Status = compatCompatible
+	return result
+}
+
+func printCompatReport(output *CompatOutput) {
+	fmt.Println(styling.Header("🔍  Compatibility with Unity " + output.Unity))
+	fmt.Println(styling.Separator())
+
+	counts := make(map[string]int)
+	for _, pkg := range output.Packages {
+		counts[pkg.Status]++
+		name := styling.Package(pkg.Package) + "@" + styling.Version(pkg.Version)
+		switch pkg.Status {
+		case compatCompatible:
+			requires := "no unity requirement"
+			if pkg.Requires != "" {
+				requires = "requires unity " + pkg.Requires
+			}
+			fmt.Printf("%s %s %s\n", styling.Success("✓"), name, styling.Muted("("+requires+")"))
+		case compatIncompatible:
+			fmt.Printf("%s %s requires unity %s\n", styling.Error("✗"), name, pkg.Requires)
+		default:
+			fmt.Printf("%s %s %s\n", styling.Warning("?"), name, styling.Muted("("+pkg.Reason+")"))
+		}
+	}
+
+	fmt.Println()
+	summary := fmt.Sprintf("%d compatible, %d incompatible, %d unknown", counts[compatCompatible], counts[compatIncompatible], counts[compatUnknown])
+	if output.Compatible {
+		fmt.Println(styling.Success("✓ " + summary))
+	} else {
+		fmt.Println(styling.Error("✗ " + summary))
+	}
+}
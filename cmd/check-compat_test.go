@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+func TestCheckCompat(t *testing.T) {
+	packages := map[string]*api.PackageVersion{
+		"com.test.old":     {Unity: "2020.3"},
+		"com.test.release": {Unity: "2023.2", UnityRelease: "10f1"},
+		"com.test.new":     {Unity: "2024.1"},
+		"com.test.ranged":  {Engines: map[string]string{"unity": ">=2021.3 <2023.0"}},
+		"com.test.plain":   {},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path[1:]
+		info, ok := packages[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		info.Name, info.Version = name, "1.0.0"
+		_ = json.NewEncoder(w).Encode(&api.PackageMetadata{Name: name, Versions: map[string]*api.PackageVersion{"1.0.0": info}})
+	}))
+	defer server.Close()
+
+	projectDir := t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "Packages"), 0755))
+	manifest := `{"dependencies": {
+		"com.test.old": "1.0.0", "com.test.release": "1.0.0", "com.test.new": "1.0.0",
+		"com.test.ranged": "1.0.0", "com.test.plain": "1.0.0",
+		"com.test.local": "file:../local", "com.test.unpublished": "1.0.0"
+	}}`
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Packages", "manifest.json"), []byte(manifest), 0644))
+
+	styling.NoColor = true
+	checkCompatRegistry = server.URL
+	defer func() {
+		styling.NoColor = false
+		checkCompatRegistry = ""
+		checkCompatUnity = ""
+		checkCompatJSON = false
+		checkCompatStrict = false
+	}()
+
+	t.Run("json report", func(t *testing.T) {
+		checkCompatUnity = "2023.2.5f1"
+		checkCompatJSON = true
+		defer func() { checkCompatJSON = false }()
+
+		out := captureStdout(t, func() error { return checkCompat(projectDir) })
+		var output CompatOutput
+		require.NoError(t, json.Unmarshal([]byte(out), &output))
+		assert.Equal(t, "2023.2.5f1", output.Unity)
+		assert.False(t, output.Compatible)
+
+		statuses := make(map[string]string)
+		for _, pkg := range output.Packages {
+			statuses[pkg.Package] = pkg.Status
+		}
+		assert.Equal(t, map[string]string{
+			"com.test.local":       compatUnknown,
+			"com.test.new":         compatIncompatible,
+			"com.test.old":         compatCompatible,
+			"com.test.plain":       compatCompatible,
+			"com.test.ranged":      compatIncompatible,
+			"com.test.release":     compatIncompatible,
+			"com.test.unpublished": compatUnknown,
+		}, statuses)
+		assert.Equal(t, "com.test.local", output.Packages[0].Package, "packages are sorted by name")
+		assert.Equal(t, ">=2023.2.10f1", output.Packages[5].Requires)
+	})
+
+	t.Run("human report", func(t *testing.T) {
+		checkCompatUnity = "2022.3"
+		out := captureStdout(t, func() error { return checkCompat(projectDir) })
+		assert.Contains(t, out, "✓ com.test.old@1.0.0 (requires unity >=2020.3)")
+		assert.Contains(t, out, "✓ com.test.ranged@1.0.0 (requires unity >=2021.3 <2023.0)")
+		assert.Contains(t, out, "✗ com.test.new@1.0.0 requires unity >=2024.1")
+		assert.Contains(t, out, "? com.test.local@file:../local (not installed from a registry)")
+		assert.Contains(t, out, "? com.test.unpublished@1.0.0 (not found in the registry)")
+		assert.Contains(t, out, "3 compatible, 2 incompatible, 2 unknown")
+	})
+
+	t.Run("strict fails on incompatible packages", func(t *testing.T) {
+		checkCompatUnity = "2022.3"
+		checkCompatStrict = true
+		defer func() { checkCompatStrict = false }()
+
+		var err error
+		captureStdout(t, func() error {
+			err = checkCompat(projectDir)
+			return nil
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2 package(s) are incompatible with Unity 2022.3")
+	})
+
+	t.Run("strict passes when everything is compatible", func(t *testing.T) {
+		delete(packages, "com.test.new")
+		delete(packages, "com.test.release")
+		checkCompatUnity = "2022.3.1f1"
+		checkCompatStrict = true
+		defer func() { checkCompatStrict = false }()
+
+		out := captureStdout(t, func() error { return checkCompat(projectDir) })
+		assert.Contains(t, out, "3 compatible, 0 incompatible, 4 unknown")
+	})
+
+	t.Run("invalid target version", func(t *testing.T) {
+		checkCompatUnity = "latest"
+		err := checkCompat(projectDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Invalid Unity version")
+	})
+}
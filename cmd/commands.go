@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var commandsNoDescriptions bool
+
+var commandsCmd = &cobra.Command{
+	Use:   "__commands",
+	Short: "Print the command tree as JSON for editor integrations",
+	Long: `Print every command, its flags, and descriptions as structured JSON.
+
+Commands and flags are sorted by name so the output is stable across
+releases, letting Unity and VS Code plugins build menus without scraping
+help text.`,
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(describeCommand(cmd.Root(), !commandsNoDescriptions))
+	},
+}
+
+// CommandSpec is the JSON description of a command
+type CommandSpec struct {
+	Name        string        `json:"name"`
+	Use         string        `json:"use"`
+	Description string        `json:"description,omitempty"`
+	Aliases     []string      `json:"aliases,omitempty"`
+	Flags       []FlagSpec    `json:"flags"`
+	Commands    []CommandSpec `json:"commands,omitempty"`
+}
+
+// FlagSpec is the JSON description of a command flag
+type FlagSpec struct {
+	Name        string `json:"name"`
+	Shorthand   string `json:"shorthand,omitempty"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+	Persistent  bool   `json:"persistent,omitempty"`
+}
+
+func init() {
+	commandsCmd.Flags().BoolVar(&commandsNoDescriptions, "no-descriptions", false, "Omit descriptions for terse output")
+}
+
+func describeCommand(cmd *cobra.Command, withDescriptions bool) CommandSpec {
+	spec := CommandSpec{
+		Name:    cmd.Name(),
+		Use:     cmd.Use,
+		Aliases: cmd.Aliases,
+		Flags:   []FlagSpec{},
+	}
+	if withDescriptions {
+		spec.Description = cmd.Short
+	}
+
+	persistent := cmd.PersistentFlags()
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden || flag.Deprecated != "" {
+			return
+		}
+		flagSpec := FlagSpec{
+			Name:       flag.Name,
+			Shorthand:  flag.Shorthand,
+			Type:       flag.Value.Type(),
+			Default:    flag.DefValue,
+			Persistent: persistent.Lookup(flag.Name) != nil,
+		}
+		if withDescriptions {
+			flagSpec.Description = flag.Usage
+		}
+		spec.Flags = append(spec.Flags, flagSpec)
+	})
+	sort.Slice(spec.Flags, func(i, j int) bool {
+		return spec.Flags[i].Name < spec.Flags[j].Name
+	})
+
+	for _, child := range cmd.Commands() {
+		if child.Hidden || child.Deprecated != "" {
+			continue
+		}
+		spec.Commands = append(spec.Commands, describeCommand(child, withDescriptions))
+	}
+	sort.Slice(spec.Commands, func(i, j int) bool {
+		return spec.Commands[i].Name < spec.Commands[j].Name
+	})
+
+	return spec
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandsJSON(t *testing.T) {
+	runCommands := func(args ...string) CommandSpec {
+		rootCmd := &cobra.Command{Use: "gpm", Short: "GPM CLI"}
+		rootCmd.PersistentFlags().Bool("json", false, "Output in JSON format")
+		AddCommands(rootCmd)
+		defer func() { commandsNoDescriptions = false }()
+
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetArgs(append([]string{"__commands"}, args...))
+		require.NoError(t, rootCmd.Execute())
+
+		var spec CommandSpec
+		require.NoError(t, json.Unmarshal(out.Bytes(), &spec))
+		return spec
+	}
+
+	spec := runCommands()
+	assert.Equal(t, "gpm", spec.Name)
+	require.Len(t, spec.Flags, 1)
+	assert.Equal(t, "json", spec.Flags[0].Name)
+	assert.True(t, spec.Flags[0].Persistent)
+
+	byName := make(map[string]CommandSpec)
+	var names []string
+	for _, child := range spec.Commands {
+		byName[child.Name] = child
+		names = append(names, child.Name)
+	}
+	assert.IsNonDecreasing(t, names, "commands must be sorted")
+	assert.NotContains(t, byName, "__commands", "hidden commands are not listed")
+
+	rootCmd := &cobra.Command{Use: "gpm"}
+	AddCommands(rootCmd)
+	for _, registered := range rootCmd.Commands() {
+		if registered.Hidden {
+			continue
+		}
+		child, ok := byName[registered.Name()]
+		require.True(t, ok, "command %s should be listed", registered.Name())
+		assert.Equal(t, registered.Short, child.Description)
+
+		var flagNames []string
+		for _, flag := range child.Flags {
+			flagNames = append(flagNames, flag.Name)
+			assert.NotNil(t, registered.Flags().Lookup(flag.Name), "%s --%s", registered.Name(), flag.Name)
+		}
+		assert.IsNonDecreasing(t, flagNames, "flags of %s must be sorted", registered.Name())
+	}
+
+	var publishFlags []string
+	for _, flag := range byName["publish"].Flags {
+		publishFlags = append(publishFlags, flag.Name)
+	}
+	assert.Equal(t, []string{
+		"access", "allow-secrets", "auth-only", "checksums", "compression-level",
+		"dry-run", "fail-fast", "follow-symlinks", "if-present", "ignore", "json",
+		"normalize-eol", "otp", "registry", "scan-secrets", "show-payload",
+		"strict", "tag", "verbose",
+	}, publishFlags)
+
+	terse := runCommands("--no-descriptions")
+	for _, child := range terse.Commands {
+		assert.Empty(t, child.Description)
+		for _, flag := range child.Flags {
+			assert.Empty(t, flag.Description)
+		}
+	}
+}
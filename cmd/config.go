@@ -2,9 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/api"
 	"gpm.sh/gpm/gpm-cli/internal/config"
+	"gpm.sh/gpm/gpm-cli/internal/engines"
 	"gpm.sh/gpm/gpm-cli/internal/styling"
 )
 
@@ -21,27 +29,134 @@ var (
 	configSetCmd = &cobra.Command{
 		Use:   "set [key] [value]",
 		Short: "Set a configuration value",
-		Long:  `Set a configuration key to a specific value`,
-		Args:  cobra.ExactArgs(2),
+		Long: `Set a configuration key to a specific value.
+
+Scoped packages can be routed to their own registry with an npm-style key:
+  gpm config set @homa:registry https://registry.homa.io
+
+Registries that don't take a bearer token can be sent it another way:
+  gpm config set //registry.homa.io/:auth_scheme basic   # or legacy, bearer
+
+A <command>.<flag> key sets a flag's default; flags given on the command
+line still override it:
+  gpm config set publish.access private
+  gpm config set dist-tag.add.registry https://registry.homa.io
+
+allowlist and blocklist take comma-separated package patterns: name globs,
+"@scope"s or reverse-domain prefixes. A blocklist:<pattern> key blocks one
+pattern with a reason add and install report when refusing it:
+  gpm config set allowlist "com.homa,@homa"
+  gpm config set blocklist:com.vendor.gpl-* "GPL-3.0 is not allowed in shipped games"`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return setConfig(args[0], args[1])
 		},
 	}
 
+	configProfileCmd = &cobra.Command{
+		Use:   "profile",
+		Short: "Manage configuration profiles",
+		Long: `Profiles bundle a registry, token, studio, and scope mappings so you can
+switch between studios in one step. Every command uses the active profile;
+set GPM_PROFILE to override it for a single invocation.
+
+While a profile is active, 'gpm config set' updates that profile.
+
+Examples:
+  gpm config profile create homa --registry https://registry.homa.io
+  gpm config profile use homa
+  gpm config profile use default    # Back to the top-level settings
+  GPM_PROFILE=homa gpm publish`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listProfiles()
+		},
+	}
+
+	configProfileCreateCmd = &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a configuration profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return createProfile(args[0])
+		},
+	}
+
+	configProfileUseCmd = &cobra.Command{
+		Use:   "use <name>",
+		Short: "Switch the active configuration profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return useProfile(args[0])
+		},
+	}
+
+	configProfileListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List configuration profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listProfiles()
+		},
+	}
+
+	configProfileDeleteCmd = &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a configuration profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return deleteProfile(args[0])
+		},
+	}
+
 	configGetCmd = &cobra.Command{
 		Use:   "get [key]",
 		Short: "Get a configuration value",
-		Long:  `Get the value of a configuration key`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Get the value of a configuration key.
+
+With --effective, registry, token, and @scope:registry show the value
+commands resolve to and where it comes from. Sources rank, highest first:
+flag > env (GPM_REGISTRY, GPM_TOKEN) > profile > project .npmrc > user config > default.
+
+Examples:
+  gpm config get registry --effective
+  gpm config get @homa:registry --effective`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if configGetEffective {
+				return getEffectiveConfig(args[0], "")
+			}
 			return getConfig(args[0])
 		},
 	}
 )
 
+var (
+	profileRegistry         string
+	profileToken            string
+	profileUsername         string
+	profileStudio           string
+	profileScopedRegistries map[string]string
+
+	configGetEffective bool
+)
+
 func init() {
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configProfileCmd)
+
+	configProfileCmd.AddCommand(configProfileCreateCmd)
+	configProfileCmd.AddCommand(configProfileUseCmd)
+	configProfileCmd.AddCommand(configProfileListCmd)
+	configProfileCmd.AddCommand(configProfileDeleteCmd)
+
+	configGetCmd.Flags().BoolVar(&configGetEffective, "effective", false, "Show the resolved value and its source (registry, token, @scope:registry)")
+
+	configProfileCreateCmd.Flags().StringVar(&profileRegistry, "registry", "", "Registry URL (default: https://registry.gpm.sh)")
+	configProfileCreateCmd.Flags().StringVar(&profileToken, "token", "", "Authentication token")
+	configProfileCreateCmd.Flags().StringVar(&profileUsername, "username", "", "Username")
+	configProfileCreateCmd.Flags().StringVar(&profileStudio, "studio", "", "Studio the profile belongs to")
+	configProfileCreateCmd.Flags().StringToStringVar(&profileScopedRegistries, "scoped-registry", nil, "Scope mapping as @scope=url (repeatable)")
 }
 
 func showConfig() error {
@@ -49,8 +164,35 @@ func showConfig() error {
 
 	fmt.Println(styling.Header("GPM Configuration"))
 	fmt.Println(styling.Separator())
+	fmt.Printf("%s %s\n", styling.Label("Profile:"), styling.Value(config.CurrentProfile()))
 	fmt.Printf("%s %s\n", styling.Label("Registry:"), styling.URL(cfg.Registry))
 	fmt.Printf("%s %s\n", styling.Label("Username:"), styling.Value(cfg.Username))
+	if cfg.Studio != "" {
+		fmt.Printf("%s %s\n", styling.Label("Studio:"), styling.Value(cfg.Studio))
+	}
+	if len(cfg.TarballHosts) > 0 {
+		fmt.Printf("%s %s\n", styling.Label("Tarball Hosts:"), styling.Value(strings.Join(cfg.TarballHosts, ", ")))
+	}
+	if len(cfg.RegistryFallbacks) > 0 {
+		fmt.Printf("%s %s\n", styling.Label("Registry Fallbacks:"), styling.Value(strings.Join(cfg.RegistryFallbacks, ", ")))
+	}
+	if len(cfg.Allowlist) > 0 {
+		fmt.Printf("%s %s\n", styling.Label("Allowlist:"), styling.Value(strings.Join(cfg.Allowlist, ", ")))
+	}
+	for _, blocked := range cfg.Blocklist {
+		fmt.Printf("%s %s\n", styling.Label("Blocked "+blocked.Package+":"), styling.Value(blocked.Reason))
+	}
+	if cfg.StateDir != "" {
+		fmt.Printf("%s %s\n", styling.Label("State Directory:"), styling.File(cfg.StateDir))
+	}
+	for _, scope := range sortedScopes(cfg.ScopedRegistries) {
+		fmt.Printf("%s %s\n", styling.Label(scope+":registry"), styling.URL(cfg.ScopedRegistries[scope]))
+	}
+	for _, command := range sortedKeys(cfg.Defaults) {
+		for _, flag := range sortedKeys(cfg.Defaults[command]) {
+			fmt.Printf("%s %s\n", styling.Label(fmt.Sprintf("%s --%s:", command, flag)), styling.Value(cfg.Defaults[command][flag]))
+		}
+	}
 
 	if cfg.Token != "" {
 		tokenDisplay := cfg.Token
@@ -65,7 +207,151 @@ func showConfig() error {
 	return nil
 }
 
+// configValueType is the kind of value a config key holds. 'config set'
+// checks a value against it before anything is changed or saved.
+type configValueType int
+
+const (
+	configText configValueType = iota
+	configURL
+	configBool
+	configDuration
+	configCompressionLevel
+	configDistTag
+	configHostList
+	configAuthScheme
+	configPackagePatterns
+	configURLList
+)
+
+// configKeyTypes lists the keys 'config set' accepts. Scoped registry keys
+// ("@scope:registry") are URLs and registry auth scheme keys
+// ("//host/:auth_scheme") are auth schemes.
+var configKeyTypes = map[string]configValueType{
+	"registry":             configURL,
+	"token":                configText,
+	"username":             configText,
+	"studio":               configText,
+	"compression_level":    configCompressionLevel,
+	"update_check":         configBool,
+	"update_url":           configURL,
+	"user_agent":           configText,
+	"auto_scoped_registry": configBool,
+	"default_tag":          configDistTag,
+	"registry_timeout":     configDuration,
+	"tarball_hosts":        configHostList,
+	"allowlist":            configPackagePatterns,
+	"registry_fallbacks":   configURLList,
+	"blocklist":            configPackagePatterns,
+	"state_dir":            configText,
+}
+
+// validateConfigValue parses value as the key's type, explaining what was
+// expected when it doesn't fit
+func validateConfigValue(key, value string) error {
+	valueType, ok := configKeyTypes[key]
+	if _, scoped := config.ParseScopeRegistryKey(key); scoped {
+		valueType, ok = configURL, true
+	}
+	if _, authScheme := config.ParseAuthSchemeKey(key); authScheme {
+		valueType, ok = configAuthScheme, true
+	}
+	if !ok {
+		return fmt.Errorf("unknown configuration key: %s", key)
+	}
+
+	var expected string
+	switch valueType {
+	case configURL:
+		if !config.SecureURL(value) {
+			expected = "an https:// URL (http:// only for localhost)"
+		}
+	case configBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			expected = "true|false"
+		}
+	case configDuration:
+		if timeout, err := time.ParseDuration(value); err != nil || timeout <= 0 {
+			expected = "a positive duration such as 3s or 500ms"
+		}
+	case configCompressionLevel:
+		if level, err := strconv.Atoi(value); err != nil || level < 0 || level > 9 {
+			expected = "a number between 0 and 9"
+		}
+	case configDistTag:
+		if err := validateDistTag(value); err != nil {
+			expected = "a dist-tag name such as beta (" + err.Error() + ")"
+		}
+	case configAuthScheme:
+		if !slices.Contains(config.ValidAuthSchemes, value) {
+			expected = strings.Join(config.ValidAuthSchemes, "|")
+		}
+	case configHostList:
+		for _, host := range strings.Split(value, ",") {
+			host = strings.TrimSpace(host)
+			if strings.ContainsAny(host, "/: ") {
+				expected = "comma-separated host names such as cdn.gpm.sh"
+				break
+			}
+		}
+	case configURLList:
+		for _, registry := range strings.Split(value, ",") {
+			registry = strings.TrimSpace(registry)
+			if registry == "" {
+				continue
+			}
+			if !config.SecureURL(registry) {
+				expected = "comma-separated https:// URLs (http:// only for localhost)"
+				break
+			}
+		}
+	case configPackagePatterns:
+		for _, pattern := range strings.Split(value, ",") {
+			if strings.TrimSpace(pattern) == "" {
+				continue
+			}
+			if err := config.ValidatePackagePattern(strings.TrimSpace(pattern)); err != nil {
+				expected = "comma-separated package patterns such as com.company.* or @company"
+				break
+			}
+		}
+	}
+	if expected != "" {
+		return fmt.Errorf("invalid value %q for %s: expected %s", value, key, expected)
+	}
+	return nil
+}
+
 func setConfig(key, value string) error {
+	if _, scoped := config.ParseScopeRegistryKey(key); !scoped {
+		if command, flag, ok := parseFlagDefaultKey(key); ok {
+			return setFlagDefault(command, flag, value)
+		}
+	}
+	if pattern, ok := strings.CutPrefix(key, "blocklist:"); ok {
+		if err := config.ValidatePackagePattern(pattern); err != nil {
+			return err
+		}
+		config.BlockPackage(pattern, value)
+		fmt.Printf("%s %s\n", styling.Success(fmt.Sprintf("Blocked %s:", pattern)), styling.Value(value))
+		return config.SaveConfig()
+	}
+	if err := validateConfigValue(key, value); err != nil {
+		return err
+	}
+
+	if scope, ok := config.ParseScopeRegistryKey(key); ok {
+		config.SetScopedRegistry(scope, value)
+		fmt.Printf("%s %s\n", styling.Success(fmt.Sprintf("Registry for %s set to:", scope)), styling.Value(value))
+		return config.SaveConfig()
+	}
+	if registryKey, ok := config.ParseAuthSchemeKey(key); ok {
+		config.SetAuthScheme(registryKey, value)
+		fmt.Printf("%s %s\n", styling.Success(fmt.Sprintf("Auth scheme for %s set to:", registryKey)), styling.Value(value))
+		return config.SaveConfig()
+	}
+
+	// Values have been validated against configKeyTypes above
 	switch key {
 	case "registry":
 		config.SetRegistry(value)
@@ -76,16 +362,108 @@ func setConfig(key, value string) error {
 	case "username":
 		config.SetUsername(value)
 		fmt.Printf("%s %s\n", styling.Success("Username set to:"), styling.Value(value))
-	default:
-		return fmt.Errorf("unknown configuration key: %s", key)
+	case "studio":
+		config.SetStudio(value)
+		fmt.Printf("%s %s\n", styling.Success("Studio set to:"), styling.Value(value))
+	case "compression_level":
+		level, _ := strconv.Atoi(value)
+		config.SetCompressionLevel(level)
+		fmt.Printf("%s %s\n", styling.Success("Compression level set to:"), styling.Value(value))
+	case "update_check":
+		enabled, _ := strconv.ParseBool(value)
+		config.SetUpdateCheck(enabled)
+		fmt.Printf("%s %s\n", styling.Success("Update check set to:"), styling.Value(strconv.FormatBool(enabled)))
+	case "update_url":
+		config.SetUpdateURL(value)
+		fmt.Printf("%s %s\n", styling.Success("Update URL set to:"), styling.Value(value))
+	case "user_agent":
+		config.SetUserAgent(value)
+		ConfigureUserAgent()
+		fmt.Printf("%s %s\n", styling.Success("User-Agent set to:"), styling.Value(api.UserAgent()))
+	case "auto_scoped_registry":
+		enabled, _ := strconv.ParseBool(value)
+		config.SetAutoScopedRegistry(enabled)
+		fmt.Printf("%s %s\n", styling.Success("Automatic scoped registries set to:"), styling.Value(strconv.FormatBool(enabled)))
+	case "default_tag":
+		config.SetDefaultTag(value)
+		fmt.Printf("%s %s\n", styling.Success("Default dist-tag set to:"), styling.Value(value))
+	case "registry_timeout":
+		config.SetRegistryTimeout(value)
+		fmt.Printf("%s %s\n", styling.Success("Registry timeout set to:"), styling.Value(value))
+	case "tarball_hosts":
+		config.SetTarballHosts(value)
+		fmt.Printf("%s %s\n", styling.Success("Tarball hosts set to:"), styling.Value(strings.Join(config.GetConfig().TarballHosts, ", ")))
+	case "registry_fallbacks":
+		config.SetRegistryFallbacks(value)
+		fmt.Printf("%s %s\n", styling.Success("Registry fallbacks set to:"), styling.Value(strings.Join(config.GetConfig().RegistryFallbacks, ", ")))
+	case "allowlist":
+		config.SetAllowlist(value)
+		fmt.Printf("%s %s\n", styling.Success("Allowlist set to:"), styling.Value(strings.Join(config.GetConfig().Allowlist, ", ")))
+	case "blocklist":
+		config.SetBlocklist(value)
+		fmt.Printf("%s %s\n", styling.Success("Blocklist set to:"), styling.Value(strings.Join(config.BlocklistPatterns(), ", ")))
+	case "state_dir":
+		config.SetStateDir(value)
+		fmt.Printf("%s %s\n", styling.Success("Project state directory set to:"), styling.Value(value))
 	}
 
 	return config.SaveConfig()
 }
 
+// setFlagDefault validates a flag default against the command tree before
+// saving it
+func setFlagDefault(command, flag, value string) error {
+	key := flagDefaultKey(command, flag)
+	f, err := lookupDefaultFlag(configCmd.Root(), command, flag)
+	if err != nil {
+		return fmt.Errorf("unknown configuration key: %s (%w)", key, err)
+	}
+	if err := validateFlagDefault(key, f, value); err != nil {
+		return err
+	}
+	config.SetFlagDefault(command, flag, value)
+	fmt.Printf("%s %s\n", styling.Success(fmt.Sprintf("Default for %s --%s set to:", command, flag)), styling.Value(value))
+	return config.SaveConfig()
+}
+
 func getConfig(key string) error {
 	cfg := config.GetConfig()
 
+	if _, scoped := config.ParseScopeRegistryKey(key); !scoped {
+		if command, flag, ok := parseFlagDefaultKey(key); ok {
+			if value, set := config.GetFlagDefault(command, flag); set {
+				fmt.Printf("%s\n", styling.Value(value))
+			} else {
+				fmt.Printf("%s\n", styling.Warning("Not set"))
+			}
+			return nil
+		}
+	}
+
+	if pattern, ok := strings.CutPrefix(key, "blocklist:"); ok {
+		for _, blocked := range cfg.Blocklist {
+			if blocked.Package == pattern {
+				fmt.Printf("%s\n", styling.Value(blocked.Reason))
+				return nil
+			}
+		}
+		fmt.Printf("%s\n", styling.Warning("Not set"))
+		return nil
+	}
+
+	if scope, ok := config.ParseScopeRegistryKey(key); ok {
+		if registry := config.GetScopedRegistry(scope); registry != "" {
+			fmt.Printf("%s\n", styling.Value(registry))
+		} else {
+			fmt.Printf("%s\n", styling.Warning("Not set"))
+		}
+		return nil
+	}
+	if registryKey, ok := config.ParseAuthSchemeKey(key); ok {
+		fmt.Printf("%s\n", styling.Value(config.AuthSchemeFor("https:"+registryKey)))
+		return nil
+	}
+
 	switch key {
 	case "registry":
 		fmt.Printf("%s\n", styling.Value(cfg.Registry))
@@ -97,9 +475,193 @@ func getConfig(key string) error {
 		}
 	case "username":
 		fmt.Printf("%s\n", styling.Value(cfg.Username))
+	case "studio":
+		fmt.Printf("%s\n", styling.Value(cfg.Studio))
+	case "profile":
+		fmt.Printf("%s\n", styling.Value(config.CurrentProfile()))
+	case "update_check":
+		fmt.Printf("%s\n", styling.Value(strconv.FormatBool(config.UpdateCheckEnabled())))
+	case "update_url":
+		fmt.Printf("%s\n", styling.Value(updateURL()))
+	case "user_agent":
+		fmt.Printf("%s\n", styling.Value(api.UserAgent()))
+	case "registry_timeout":
+		fmt.Printf("%s\n", styling.Value(config.GetRegistryTimeout().String()))
+	case "default_tag":
+		if cfg.DefaultTag != "" {
+			fmt.Printf("%s\n", styling.Value(cfg.DefaultTag))
+		} else {
+			fmt.Printf("%s\n", styling.Value("latest"))
+		}
+	case "auto_scoped_registry":
+		fmt.Printf("%s\n", styling.Value(strconv.FormatBool(config.AutoScopedRegistryEnabled())))
+	case "tarball_hosts":
+		fmt.Printf("%s\n", styling.Value(strings.Join(cfg.TarballHosts, ",")))
+	case "registry_fallbacks":
+		fmt.Printf("%s\n", styling.Value(strings.Join(cfg.RegistryFallbacks, ",")))
+	case "allowlist":
+		fmt.Printf("%s\n", styling.Value(strings.Join(cfg.Allowlist, ",")))
+	case "blocklist":
+		fmt.Printf("%s\n", styling.Value(strings.Join(config.BlocklistPatterns(), ",")))
+	case "state_dir":
+		if cfg.StateDir != "" {
+			fmt.Printf("%s\n", styling.Value(cfg.StateDir))
+		} else {
+			fmt.Printf("%s\n", styling.Value(engines.StateDirName))
+		}
+	case "compression_level":
+		if cfg.CompressionLevel != nil {
+			fmt.Printf("%s\n", styling.Value(strconv.Itoa(*cfg.CompressionLevel)))
+		} else {
+			fmt.Printf("%s\n", styling.Warning("Not set"))
+		}
 	default:
 		return fmt.Errorf("unknown configuration key: %s", key)
 	}
 
 	return nil
 }
+
+// getEffectiveConfig prints the resolved value of a key and the source it
+// came from. projectDir defaults to the current directory.
+func getEffectiveConfig(key, projectDir string) error {
+	if projectDir == "" {
+		if wd, err := os.Getwd(); err == nil {
+			projectDir = wd
+		}
+	}
+
+	var resolved config.Resolved
+	if scope, ok := config.ParseScopeRegistryKey(key); ok {
+		resolved = config.ResolveScopedRegistry(scope, "", projectDir)
+	} else {
+		switch key {
+		case "registry":
+			resolved = config.ResolveRegistry("", projectDir)
+		case "token":
+			resolved = config.ResolveToken("", projectDir)
+		default:
+			return fmt.Errorf("%s\n\n%s",
+				styling.Error(fmt.Sprintf("--effective is not supported for %s", key)),
+				styling.Hint("Use it with registry, token, or @scope:registry"))
+		}
+	}
+
+	if resolved.Value != "" {
+		fmt.Printf("%s\n", styling.Value(resolved.Value))
+	} else {
+		fmt.Printf("%s\n", styling.Warning("Not set"))
+	}
+	source := resolved.Source
+	if resolved.Origin != "" {
+		source = fmt.Sprintf("%s (%s)", resolved.Source, resolved.Origin)
+	}
+	fmt.Printf("%s %s\n", styling.Label("Source:"), styling.Muted(source))
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedScopes(scopedRegistries map[string]string) []string {
+	return sortedKeys(scopedRegistries)
+}
+
+func createProfile(name string) error {
+	profile := config.Profile{
+		Registry: profileRegistry,
+		Token:    profileToken,
+		Username: profileUsername,
+		Studio:   profileStudio,
+	}
+	for key, registry := range profileScopedRegistries {
+		scope, ok := config.ParseScopeRegistryKey(key + ":registry")
+		if !ok {
+			return fmt.Errorf("%s\n\n%s",
+				styling.Error(fmt.Sprintf("Invalid scope %q", key)),
+				styling.Hint("Use --scoped-registry @scope=https://registry.example.com"))
+		}
+		if profile.ScopedRegistries == nil {
+			profile.ScopedRegistries = make(map[string]string)
+		}
+		profile.ScopedRegistries[scope] = registry
+	}
+
+	if err := config.CreateProfile(name, profile); err != nil {
+		return err
+	}
+	if err := config.SaveConfig(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s\n", styling.Success("Profile created:"), styling.Value(config.NormalizeProfileName(name)))
+	fmt.Printf("%s\n", styling.Hint(fmt.Sprintf("Switch to it with 'gpm config profile use %s'", config.NormalizeProfileName(name))))
+	return nil
+}
+
+func useProfile(name string) error {
+	if err := config.UseProfile(name); err != nil {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(err.Error()),
+			styling.Hint("Run 'gpm config profile list' to see available profiles"))
+	}
+	if err := config.SaveConfig(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s\n", styling.Success("Active profile:"), styling.Value(config.CurrentProfile()))
+	fmt.Printf("%s %s\n", styling.Label("Registry:"), styling.URL(config.GetRegistry()))
+	if env := os.Getenv(config.ProfileEnv); env != "" {
+		fmt.Printf("%s\n", styling.Warning(fmt.Sprintf("⚠ %s=%s overrides the active profile in this shell", config.ProfileEnv, env)))
+	}
+	return nil
+}
+
+func listProfiles() error {
+	current := config.CurrentProfile()
+
+	fmt.Println(styling.Header("GPM Profiles"))
+	fmt.Println(styling.Separator())
+	for _, name := range append([]string{config.DefaultProfile}, config.ProfileNames()...) {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		fmt.Printf("%s %s %s\n", marker, styling.Value(name), styling.URL(config.ProfileRegistry(name)))
+	}
+	return nil
+}
+
+func deleteProfile(name string) error {
+	if err := config.DeleteProfile(name); err != nil {
+		return err
+	}
+	if err := config.SaveConfig(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s\n", styling.Success("Profile deleted:"), styling.Value(config.NormalizeProfileName(name)))
+	return nil
+}
+
+// PrintConfigWarnings reports problems met while loading the config on
+// stderr, keeping stdout clean for --json and other machine output
+func PrintConfigWarnings() {
+	for _, warning := range config.Warnings() {
+		fmt.Fprintf(os.Stderr, "%s\n", styling.Warning("Warning: "+warning))
+	}
+}
+
+// ConfigureAuthSchemes makes every registry client send its token in the
+// scheme configured for its registry
+func ConfigureAuthSchemes() {
+	api.SetAuthSchemes(func(registryURL string) api.AuthScheme {
+		return api.AuthScheme(config.AuthSchemeFor(registryURL))
+	})
+}
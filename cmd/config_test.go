@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gpm.sh/gpm/gpm-cli/internal/config"
@@ -152,3 +154,160 @@ func TestConfigPersistence(t *testing.T) {
 	assert.Equal(t, testRegistry, cfg.Registry)
 	assert.Equal(t, testUsername, cfg.Username)
 }
+
+func TestConfigSetValidatesValues(t *testing.T) {
+	tempDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	defer func() {
+		_ = os.Setenv("HOME", originalHome)
+		config.ResetConfigForTesting()
+	}()
+	_ = os.Setenv("HOME", tempDir)
+	config.InitConfig()
+
+	tests := []struct {
+		key      string
+		valid    string
+		invalid  string
+		expected string
+	}{
+		{"registry", "https://registry.gpm.sh", "registry.gpm.sh", "expected an https:// URL"},
+		{"registry", "https://registry.gpm.sh", "http://x", "expected an https:// URL"},
+		{"@homa:registry", "http://localhost:4873", "ftp://registry.homa.io", "expected an https:// URL"},
+		{"registry_fallbacks", "https://registry.gpm.sh,http://127.0.0.1:4873", "https://registry.gpm.sh,http://registry.npmjs.org", "expected comma-separated https:// URLs"},
+		{"update_url", "https://releases.gpm.sh/cli/latest.json", "http://releases.gpm.sh/cli/latest.json", "expected an https:// URL"},
+		{"update_check", "false", "maybe", "expected true|false"},
+		{"registry_timeout", "10s", "abc", "expected a positive duration"},
+		{"compression_level", "9", "10", "expected a number between 0 and 9"},
+		{"default_tag", "beta", "pre release", "expected a dist-tag name"},
+		{"tarball_hosts", "cdn.gpm.sh, assets.gpm.sh", "https://cdn.gpm.sh", "expected comma-separated host names"},
+		{"//registry.homa.io/:auth_scheme", "basic", "digest", "expected bearer|basic|legacy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			require.NoError(t, setConfig(tt.key, tt.valid))
+			saved, err := os.ReadFile(filepath.Join(tempDir, ".gpmrc"))
+			require.NoError(t, err)
+
+			err = setConfig(tt.key, tt.invalid)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.key)
+			assert.Contains(t, err.Error(), tt.expected)
+
+			after, err := os.ReadFile(filepath.Join(tempDir, ".gpmrc"))
+			require.NoError(t, err)
+			assert.Equal(t, string(saved), string(after), "an invalid value must not be saved")
+		})
+	}
+
+	assert.Equal(t, "https://registry.gpm.sh", config.GetConfig().Registry, "an invalid value must not change the loaded config")
+}
+
+func TestConfigProfileCommands(t *testing.T) {
+	tempDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	defer func() {
+		_ = os.Setenv("HOME", originalHome)
+		config.ResetConfigForTesting()
+		profileRegistry, profileToken, profileScopedRegistries = "", "", nil
+	}()
+	_ = os.Setenv("HOME", tempDir)
+	t.Setenv(config.ProfileEnv, "")
+
+	config.ResetConfigForTesting()
+	config.InitConfig()
+	require.NoError(t, setConfig("registry", "https://registry.gpm.sh"))
+	require.NoError(t, setConfig("token", "personal-token"))
+
+	profileRegistry = "https://registry.homa.io"
+	profileToken = "homa-token"
+	profileScopedRegistries = map[string]string{"@homa": "https://npm.homa.io"}
+	require.NoError(t, createProfile("homa"))
+
+	profileScopedRegistries = map[string]string{"homa": "https://npm.homa.io"}
+	assert.Error(t, createProfile("broken"), "scope without @ is rejected")
+
+	require.NoError(t, useProfile("homa"))
+	assert.Equal(t, "https://registry.homa.io", config.GetRegistry())
+	assert.Equal(t, "homa-token", config.GetToken())
+	assert.Equal(t, "https://npm.homa.io", config.GetScopedRegistry("@homa"))
+	assert.NoError(t, listProfiles())
+
+	err := useProfile("unknown")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "profile list")
+
+	require.NoError(t, useProfile("default"))
+	assert.Equal(t, "https://registry.gpm.sh", config.GetRegistry())
+	assert.Equal(t, "personal-token", config.GetToken())
+}
+
+func TestConfigFlagDefaults(t *testing.T) {
+	tempDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	defer func() {
+		_ = os.Setenv("HOME", originalHome)
+		config.ResetConfigForTesting()
+	}()
+	_ = os.Setenv("HOME", tempDir)
+	config.InitConfig()
+
+	rootCmd := &cobra.Command{Use: "gpm"}
+	AddCommands(rootCmd)
+	access := publishCmd.Flags().Lookup("access")
+	projects := installCmd.Flags().Lookup("projects")
+	defer func() {
+		for _, f := range []*pflag.Flag{access, projects} {
+			f.DefValue = ""
+			f.Changed = false
+		}
+		publishAccess = ""
+		installProjects = nil
+	}()
+
+	t.Run("rejects unknown commands, flags and invalid values", func(t *testing.T) {
+		err := setConfig("nope.access", "private")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown command "nope"`)
+
+		err = setConfig("publish.nope", "private")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown flag --nope for gpm publish")
+
+		err = setConfig("add.dry-run", "maybe")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid value "maybe" for add.dry-run: expected true|false`)
+	})
+
+	require.NoError(t, setConfig("publish.access", "private"))
+	require.NoError(t, setConfig("install.projects", "game,tools"))
+
+	// A fresh process reads the saved defaults before parsing flags
+	config.ResetConfigForTesting()
+	config.InitConfig()
+	require.NoError(t, ApplyFlagDefaults(rootCmd))
+
+	t.Run("configured default applies", func(t *testing.T) {
+		require.NoError(t, publishCmd.ParseFlags(nil))
+		assert.Equal(t, "private", publishAccess)
+		assert.Equal(t, "private", access.DefValue)
+
+		require.NoError(t, installCmd.ParseFlags(nil))
+		assert.Equal(t, []string{"game", "tools"}, installProjects)
+	})
+
+	t.Run("explicit flag overrides it", func(t *testing.T) {
+		require.NoError(t, publishCmd.ParseFlags([]string{"--access", "public"}))
+		assert.Equal(t, "public", publishAccess)
+
+		require.NoError(t, installCmd.ParseFlags([]string{"--projects", "other"}))
+		assert.Equal(t, []string{"other"}, installProjects)
+	})
+
+	t.Run("unknown defaults in the config are reported", func(t *testing.T) {
+		config.SetFlagDefault("publish", "nope", "x")
+		err := ApplyFlagDefaults(rootCmd)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "publish.nope: unknown flag --nope")
+	})
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+var workingDir string
+
+// AddCwdFlag registers the global --cwd/-C flag on the root command. Like
+// git -C, it makes any command behave as if gpm was started in that
+// directory.
+func AddCwdFlag(rootCmd *cobra.Command) {
+	rootCmd.PersistentFlags().StringVarP(&workingDir, "cwd", "C", "", "Run as if gpm was started in this directory")
+}
+
+// ChangeWorkingDir switches to the --cwd directory, if one was given. It runs
+// before every command.
+func ChangeWorkingDir() error {
+	if workingDir == "" {
+		return nil
+	}
+	info, err := os.Stat(workingDir)
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("--cwd %s is not a directory", workingDir)),
+			styling.Hint("Pass the directory gpm should run in, e.g. 'gpm -C packages/my-package pack'"))
+	}
+	if err := os.Chdir(workingDir); err != nil {
+		return fmt.Errorf("failed to change to %s: %w", workingDir, err)
+	}
+	return nil
+}
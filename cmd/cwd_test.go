@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// executeWithCwd runs args through a root command wired like main's
+func executeWithCwd(t *testing.T, args ...string) error {
+	oldWd, _ := os.Getwd()
+	t.Cleanup(func() {
+		_ = os.Chdir(oldWd)
+		workingDir = ""
+	})
+
+	rootCmd := &cobra.Command{
+		Use: "gpm",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return ChangeWorkingDir()
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	AddCwdFlag(rootCmd)
+	AddCommands(rootCmd)
+	rootCmd.SetArgs(args)
+	return rootCmd.Execute()
+}
+
+func TestCwdFlag(t *testing.T) {
+	t.Run("init writes package.json in the directory", func(t *testing.T) {
+		packageDir := t.TempDir()
+		t.Cleanup(func() {
+			_ = initCmd.Flags().Set("yes", "false")
+			_ = initCmd.Flags().Set("name", "")
+		})
+
+		require.NoError(t, executeWithCwd(t, "--cwd", packageDir, "init", "--yes", "--name", "com.test.cwd"))
+
+		data, err := os.ReadFile(filepath.Join(packageDir, "package.json"))
+		require.NoError(t, err)
+		var pkg PackageJSON
+		require.NoError(t, json.Unmarshal(data, &pkg))
+		assert.Equal(t, "com.test.cwd", pkg.Name)
+	})
+
+	t.Run("pack builds the package in the directory", func(t *testing.T) {
+		packageDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(packageDir, "package.json"), []byte(`{"name": "com.test.cwd", "version": "1.0.0"}`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(packageDir, "Cwd.cs"), []byte("// test"), 0644))
+		runDir := t.TempDir()
+		oldWd, _ := os.Getwd()
+		require.NoError(t, os.Chdir(runDir))
+		defer func() { _ = os.Chdir(oldWd) }()
+
+		require.NoError(t, executeWithCwd(t, "-C", packageDir, "pack"))
+
+		_, err := os.Stat(filepath.Join(packageDir, "com.test.cwd-1.0.0.tgz"))
+		assert.NoError(t, err)
+		entries, err := os.ReadDir(runDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "nothing should be written to the original directory")
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		err := executeWithCwd(t, "--cwd", filepath.Join(t.TempDir(), "missing"), "pack")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not a directory")
+	})
+}
@@ -105,7 +105,7 @@ func outputDetectionHuman(results engines.DetectionResults, projectDir string) e
 
 	// Show best result first
 	best := results.Best()
-	fmt.Printf("%s %s\n", styling.Label("Best Match:"), getEngineIcon(best.Engine)+" "+styling.Value(best.Engine.String()))
+	fmt.Printf("%s %s\n", styling.Label("Best Match:"), engineLabel(best.Engine))
 	fmt.Printf("%s %s\n", styling.Label("Confidence:"), getConfidenceStyle(best.Confidence))
 
 	if best.Version != "" {
@@ -129,10 +129,7 @@ func outputDetectionHuman(results engines.DetectionResults, projectDir string) e
 			fmt.Println()
 		}
 
-		fmt.Printf("%s %s %s\n",
-			getEngineIcon(result.Engine),
-			styling.Value(result.Engine.String()),
-			getConfidenceStyle(result.Confidence))
+		fmt.Printf("%s %s\n", engineLabel(result.Engine), getConfidenceStyle(result.Confidence))
 
 		if result.Version != "" {
 			fmt.Printf("    Version: %s\n", result.Version)
@@ -168,6 +165,14 @@ func outputDetectionHuman(results engines.DetectionResults, projectDir string) e
 	return nil
 }
 
+// engineLabel names an engine after its icon; plain output has no icons
+func engineLabel(engine engines.EngineType) string {
+	if styling.Plain {
+		return styling.Value(engine.String())
+	}
+	return getEngineIcon(engine) + " " + styling.Value(engine.String())
+}
+
 func getEngineIcon(engine engines.EngineType) string {
 	switch engine {
 	case engines.EngineUnity:
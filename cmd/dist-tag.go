@@ -46,8 +46,7 @@ func distTagAdd(cmd *cobra.Command, args []string) error {
 	tag := args[1]
 	version := args[2]
 
-	cfg := config.GetConfig()
-	if cfg.Token == "" {
+	if config.ResolveToken("", ".").Value == "" {
 		return fmt.Errorf("%s", styling.Error("not logged in. Run 'gpm login' first"))
 	}
 
@@ -67,8 +66,7 @@ func distTagRemove(cmd *cobra.Command, args []string) error {
 	packageName := args[0]
 	tag := args[1]
 
-	cfg := config.GetConfig()
-	if cfg.Token == "" {
+	if config.ResolveToken("", ".").Value == "" {
 		return fmt.Errorf("%s", styling.Error("not logged in. Run 'gpm login' first"))
 	}
 
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+	"gpm.sh/gpm/gpm-cli/internal/store"
+)
+
+// tarballDownloads shares downloaded tarballs between the installs of one gpm
+// invocation, so a package installed into several projects (a Unity and a
+// Godot project in the same repo, say) is fetched once and extracted into
+// each. Downloads are keyed by integrity, falling back to the URL when the
+// integrity isn't known up front.
+//
+// Across invocations, tarballs are shared through the store: one pinned by a
+// sha512 integrity is read from it when present, and every download is added
+// to it.
+type tarballDownloads struct {
+	mu    sync.Mutex
+	paths map[string]string
+	files []string
+	// sha512 integrity of each file, for recording its use in the store
+	integrities map[string]string
+	// pending is closed when the download of a URL in progress finishes, so
+	// concurrent requests for it wait instead of downloading it again
+	pending map[string]chan struct{}
+}
+
+// installDownloads is reset at the end of each install command
+var installDownloads = &tarballDownloads{}
+
+// fetch returns a local copy of tarballURL, downloading it with fetchTarball
+// unless the same tarball was already downloaded. The file stays valid until
+// clear is called. It is safe to call concurrently; different tarballs
+// download in parallel.
+func (d *tarballDownloads) fetch(ctx context.Context, tarballURL, registryURL string) (string, error) {
+	plainURL, integrity, _ := strings.Cut(tarballURL, "#")
+	for {
+		d.mu.Lock()
+		if path, ok, err := d.downloaded(plainURL, integrity); ok || err != nil {
+			d.mu.Unlock()
+			return path, err
+		}
+		if wait, ok := d.pending[plainURL]; ok {
+			d.mu.Unlock()
+			select {
+			case <-wait:
+				continue
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+		if d.pending == nil {
+			d.pending = make(map[string]chan struct{})
+		}
+		done := make(chan struct{})
+		d.pending[plainURL] = done
+		d.mu.Unlock()
+
+		path, err := d.download(ctx, tarballURL, registryURL)
+
+		d.mu.Lock()
+		delete(d.pending, plainURL)
+		close(done)
+		d.mu.Unlock()
+		return path, err
+	}
+}
+
+// downloaded returns the copy of a tarball already downloaded, checking it
+// against integrity when an earlier, unpinned download of the same URL is
+// reused. The caller holds d.mu.
+func (d *tarballDownloads) downloaded(plainURL, integrity string) (string, bool, error) {
+	if path, ok := d.paths[integrity]; ok && integrity != "" {
+		return path, true, nil
+	}
+	path, ok := d.paths[plainURL]
+	if !ok {
+		return "", false, nil
+	}
+	if integrity == "" {
+		return path, true, nil
+	}
+	// Same URL, but this request pins an integrity: check the copy
+	// against it rather than trusting the earlier, unpinned download
+	if err := checkTarballIntegrity(path, integrity); err != nil {
+		return "", false, fmt.Errorf("integrity check failed for %s: %w", plainURL, err)
+	}
+	d.paths[integrity] = path
+	return path, true, nil
+}
+
+// download fetches tarballURL, from the store when it has it, and records
+// the copy
+func (d *tarballDownloads) download(ctx context.Context, tarballURL, registryURL string) (string, error) {
+	plainURL, integrity, _ := strings.Cut(tarballURL, "#")
+	tarballStore := openTarballStore()
+	path, fromStore := d.fromStore(tarballStore, integrity)
+	if !fromStore {
+		var err error
+		if path, err = fetchTarball(ctx, tarballURL, registryURL); err != nil {
+			return "", err
+		}
+	}
+
+	storeIntegrity := integrity
+	if store.Key(storeIntegrity) == "" {
+		if _, sha512Bytes, err := calculateTarballHashes(path); err == nil {
+			storeIntegrity = "sha512-" + base64.StdEncoding.EncodeToString(sha512Bytes)
+		}
+	}
+
+	d.mu.Lock()
+	if d.paths == nil {
+		d.paths = make(map[string]string)
+		d.integrities = make(map[string]string)
+	}
+	d.files = append(d.files, path)
+	d.paths[plainURL] = path
+	if integrity != "" {
+		d.paths[integrity] = path
+	}
+	if storeIntegrity != integrity {
+		d.paths[storeIntegrity] = path
+	}
+	d.integrities[path] = storeIntegrity
+	d.mu.Unlock()
+
+	if tarballStore != nil && !fromStore {
+		// Best effort: an install doesn't fail because the store can't be written
+		_ = tarballStore.Add(path, storeIntegrity)
+	}
+	return path, nil
+}
+
+// fromStore copies the tarball with integrity out of the store into a new
+// temporary file, reporting whether the store had it
+func (d *tarballDownloads) fromStore(tarballStore *store.Store, integrity string) (string, bool) {
+	if tarballStore == nil || store.Key(integrity) == "" {
+		return "", false
+	}
+	file, err := os.CreateTemp("", "gpm-tarball-*.tgz")
+	if err != nil {
+		return "", false
+	}
+	path := file.Name()
+	_ = file.Close()
+	if ok, err := tarballStore.Get(integrity, path); !ok || err != nil {
+		_ = os.Remove(path)
+		return "", false
+	}
+	return path, true
+}
+
+// use records in the store that project uses the downloaded tarball at path,
+// so 'gpm store gc' keeps it
+func (d *tarballDownloads) use(project, path string) {
+	d.mu.Lock()
+	integrity := d.integrities[path]
+	d.mu.Unlock()
+
+	tarballStore := openTarballStore()
+	if tarballStore == nil || integrity == "" {
+		return
+	}
+	if root, err := filepath.Abs(engines.FindProjectRoot(project)); err == nil {
+		_ = tarballStore.Use(root, integrity)
+	}
+}
+
+// clear removes every downloaded tarball
+func (d *tarballDownloads) clear() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, path := range d.files {
+		_ = os.Remove(path)
+	}
+	d.paths = nil
+	d.files = nil
+	d.integrities = nil
+}
+
+// checkTarballIntegrity compares a downloaded file with an integrity string
+func checkTarballIntegrity(path, integrity string) error {
+	hasher, err := integrityHash(integrity)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path) // #nosec G304 - temporary file written by fetchTarball
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return err
+	}
+	algorithm, _, _ := strings.Cut(integrity, "-")
+	if actual := algorithm + "-" + base64.StdEncoding.EncodeToString(hasher.Sum(nil)); actual != integrity {
+		return fmt.Errorf("expected %s, got %s", integrity, actual)
+	}
+	return nil
+}
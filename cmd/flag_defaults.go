@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gpm.sh/gpm/gpm-cli/internal/config"
+)
+
+// parseFlagDefaultKey splits a "publish.access" style config key into a
+// command path and a flag name. Subcommands are separated by dots too, as in
+// "dist-tag.add.registry".
+func parseFlagDefaultKey(key string) (string, string, bool) {
+	if strings.ContainsAny(key, ": ") {
+		return "", "", false
+	}
+	i := strings.LastIndex(key, ".")
+	if i <= 0 || i == len(key)-1 {
+		return "", "", false
+	}
+	return strings.ReplaceAll(key[:i], ".", " "), key[i+1:], true
+}
+
+// flagDefaultKey is the inverse of parseFlagDefaultKey
+func flagDefaultKey(command, flag string) string {
+	return strings.ReplaceAll(command, " ", ".") + "." + flag
+}
+
+// lookupDefaultFlag finds the flag a flag default names. Only flags defined
+// on the command itself qualify; global flags such as --json are shared by
+// every command and can't be defaulted per command.
+func lookupDefaultFlag(root *cobra.Command, command, flag string) (*pflag.Flag, error) {
+	target, rest, err := root.Find(strings.Fields(command))
+	if err != nil || len(rest) > 0 || target == root {
+		return nil, fmt.Errorf("unknown command %q", command)
+	}
+	f := target.Flags().Lookup(flag)
+	if f == nil || flag == "help" {
+		return nil, fmt.Errorf("unknown flag --%s for %s", flag, target.CommandPath())
+	}
+	return f, nil
+}
+
+// validateFlagDefault checks that value parses as the flag's type; key names
+// the default in the error
+func validateFlagDefault(key string, f *pflag.Flag, value string) error {
+	var expected string
+	switch f.Value.Type() {
+	case "bool":
+		if _, err := strconv.ParseBool(value); err != nil {
+			expected = "true|false"
+		}
+	case "int", "int64":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			expected = "a whole number"
+		}
+	case "float64":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			expected = "a number"
+		}
+	case "duration":
+		if _, err := time.ParseDuration(value); err != nil {
+			expected = "a duration such as 3s or 500ms"
+		}
+	}
+	if expected != "" {
+		return fmt.Errorf("invalid value %q for %s: expected %s", value, key, expected)
+	}
+	return nil
+}
+
+// applyFlagDefault makes value the flag's default, shown in its help
+func applyFlagDefault(f *pflag.Flag, value string) error {
+	// Slices replace their default instead of appending to it, so a flag given
+	// on the command line still replaces the configured list
+	if slice, ok := f.Value.(pflag.SliceValue); ok {
+		if err := slice.Replace(strings.Split(value, ",")); err != nil {
+			return err
+		}
+	} else if err := f.Value.Set(value); err != nil {
+		return err
+	}
+	f.DefValue = f.Value.String()
+	return nil
+}
+
+// ApplyFlagDefaults replaces the built-in flag defaults of root's commands
+// with those from the config. It has to run before the command line is
+// parsed so explicit flags still override them. Defaults naming unknown
+// commands or flags, or holding invalid values, are skipped and reported.
+func ApplyFlagDefaults(root *cobra.Command) error {
+	defaults := config.FlagDefaults()
+	var errs []error
+	for _, command := range sortedKeys(defaults) {
+		for _, flag := range sortedKeys(defaults[command]) {
+			key := flagDefaultKey(command, flag)
+			value := defaults[command][flag]
+			f, err := lookupDefaultFlag(root, command, flag)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				continue
+			}
+			if err := validateFlagDefault(key, f, value); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if err := applyFlagDefault(f, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
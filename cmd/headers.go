@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/config"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+var (
+	requestHeaders      []string
+	allowHeaderOverride bool
+)
+
+// AddHeaderFlags registers the global --header and --allow-header-override
+// flags on the root command
+func AddHeaderFlags(rootCmd *cobra.Command) {
+	rootCmd.PersistentFlags().StringArrayVar(&requestHeaders, "header", nil, "Send a 'Key: Value' header with every registry request, e.g. an API gateway key (repeatable; adds to extra_headers)")
+	rootCmd.PersistentFlags().BoolVar(&allowHeaderOverride, "allow-header-override", false, "Let --header and extra_headers replace Authorization and other headers gpm sets itself")
+}
+
+// ConfigureHeaders hands the registry request headers to the API client: the
+// config's extra_headers, then --header, which wins for the same name. It
+// runs before every command.
+func ConfigureHeaders() error {
+	cfg := config.GetConfig()
+	headers := make(map[string]string, len(cfg.ExtraHeaders)+len(requestHeaders))
+	for name, value := range cfg.ExtraHeaders {
+		parsedName, parsedValue, err := api.ParseHeader(name + ": " + value)
+		if err != nil {
+			return fmt.Errorf("%s\n\n%s",
+				styling.Error("extra_headers: "+err.Error()),
+				styling.Hint("Fix the entry in your .gpmrc"))
+		}
+		headers[parsedName] = parsedValue
+	}
+	for _, header := range requestHeaders {
+		name, value, err := api.ParseHeader(header)
+		if err != nil {
+			return fmt.Errorf("%s\n\n%s",
+				styling.Error("--header: "+err.Error()),
+				styling.Hint("Pass headers as --header 'X-Api-Key: <key>'"))
+		}
+		headers[name] = value
+	}
+
+	if !allowHeaderOverride && !cfg.AllowHeaderOverride {
+		for name := range headers {
+			if api.IsProtectedHeader(name) {
+				return fmt.Errorf("%s\n\n%s",
+					styling.Error(fmt.Sprintf("refusing to replace the %s header gpm sets itself", name)),
+					styling.Hint("Pass --allow-header-override (or set allow_header_override in your .gpmrc) if your registry gateway needs it"))
+			}
+		}
+	}
+
+	api.AddSensitiveHeaders(cfg.SensitiveHeaders...)
+	api.SetExtraHeaders(headers)
+	return nil
+}
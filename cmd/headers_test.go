@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/config"
+)
+
+func TestConfigureHeaders(t *testing.T) {
+	defer func() {
+		requestHeaders, allowHeaderOverride = nil, false
+		api.SetExtraHeaders(nil)
+		config.ResetConfigForTesting()
+	}()
+
+	config.SetConfigForTesting(&config.Config{ExtraHeaders: map[string]string{"x-api-key": "from-config", "x-team": "tools"}})
+	requestHeaders = []string{"X-Api-Key: from-flag"}
+	require.NoError(t, ConfigureHeaders())
+
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		_, _ = w.Write([]byte(`{"username":"dev"}`))
+	}))
+	defer server.Close()
+	_, err := api.NewClient(server.URL, "").Whoami()
+	require.NoError(t, err)
+	assert.Equal(t, "from-flag", received.Get("X-Api-Key"), "--header wins over extra_headers")
+	assert.Equal(t, "tools", received.Get("X-Team"))
+
+	requestHeaders = []string{"Authorization: Basic abc"}
+	err = ConfigureHeaders()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "refusing to replace the Authorization header")
+
+	allowHeaderOverride = true
+	assert.NoError(t, ConfigureHeaders())
+
+	requestHeaders = []string{"no colon"}
+	err = ConfigureHeaders()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected 'Key: Value'")
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/config"
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+	"gpm.sh/gpm/gpm-cli/internal/validation"
+)
+
+var (
+	importDryRun      bool
+	importPackagesDir string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Start tracking a hand-edited Unity manifest's registry packages",
+	Long: `Adopt an existing Unity project: record where the manifest's packages come
+from, so gpm tracks them as if it had installed them.
+
+Each dependency mapped to a scoped registry is looked up there, and the ones
+the registry serves are recorded in ` + engines.UnityPackagesFile + ` with their
+current version and registry. Unity's own packages, and packages installed from
+disk or git, are left untouched, as are packages gpm already tracks.
+manifest.json itself is never changed.
+
+Examples:
+  gpm import             # Track the manifest's registry packages
+  gpm import --dry-run   # Show what would be tracked`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectDir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		return importManifest(projectDir)
+	},
+}
+
+func init() {
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Show what would be tracked without recording anything")
+	importCmd.Flags().StringVar(&importPackagesDir, "packages-dir", "", "Unity packages directory relative to the project (default: $"+engines.UnityPackagesDirEnv+" or Packages)")
+}
+
+// unityRegistryURL is where Unity resolves packages no scoped registry claims
+const unityRegistryURL = "https://packages.unity.com"
+
+func importManifest(projectDir string) error {
+	adapter := engines.NewUnityAdapter()
+	adapter.SetPackagesDir(importPackagesDir)
+	adapter.SetStateDir(projectStateDir(projectDir))
+	if err := adapter.ValidateProject(projectDir); err != nil {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("gpm import needs a Unity project: "+err.Error()),
+			styling.Hint("Run it from the project's root directory"))
+	}
+
+	packages, err := adapter.ListPackages(projectDir)
+	if err != nil {
+		return fmt.Errorf("failed to list installed packages: %w", err)
+	}
+	registries, err := adapter.ScopedRegistries(projectDir)
+	if err != nil {
+		return err
+	}
+	origins, err := adapter.PackageOrigins(projectDir)
+	if err != nil {
+		return err
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+
+	fmt.Println(styling.Header("📥  Importing manifest.json"))
+	fmt.Println(styling.Separator())
+
+	defaultRegistry := config.ResolveRegistry("", projectDir)
+	clients := make(map[string]*api.Client)
+	imported := 0
+	for _, pkg := range packages {
+		name := styling.Package(pkg.Name) + "@" + styling.Version(pkg.Version)
+		skip := func(reason string) {
+			fmt.Printf("%s %s %s\n", styling.Muted("-"), name, styling.Muted("("+reason+")"))
+		}
+
+		if _, tracked := origins[pkg.Name]; tracked {
+			skip("already tracked")
+			continue
+		}
+		if validation.ValidateVersion(pkg.Version) != nil {
+			skip("not installed from a registry")
+			continue
+		}
+		scoped := engines.ScopedRegistryFor(registries, pkg.Name)
+		if scoped == nil || strings.HasPrefix(pkg.Name, "com.unity.") || strings.TrimSuffix(scoped.URL, "/") == unityRegistryURL {
+			skip("resolves from Unity's registry, left untouched")
+			continue
+		}
+
+		registry := strings.TrimSuffix(scoped.URL, "/")
+		client := clients[registry]
+		if client == nil {
+			client = api.NewClient(registry, registryToken(registry, defaultRegistry, projectDir)).WithContext(commandCtx)
+			clients[registry] = client
+		}
+		if reason := registryServes(client, pkg.Name, pkg.Version); reason != "" {
+			skip(reason + " in " + registry)
+			continue
+		}
+
+		if !importDryRun {
+			if err := adapter.SetPackageOrigin(projectDir, pkg.Name, &engines.PackageOrigin{Registry: registry, Version: pkg.Version}); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("%s %s %s\n", styling.Success("✓"), name, styling.Muted("from "+registry))
+		imported++
+	}
+
+	fmt.Println()
+	switch {
+	case imported == 0:
+		fmt.Println(styling.Info("No registry packages to import"))
+	case importDryRun:
+		fmt.Println(styling.Info(fmt.Sprintf("Would track %d package(s) (dry run)", imported)))
+	default:
+		fmt.Println(styling.Success(fmt.Sprintf("✓ Tracking %d package(s) in %s", imported, engines.UnityPackagesFile)))
+	}
+	return nil
+}
+
+// registryServes returns why name@version can't be tracked from the client's
+// registry, or "" when the registry has it
+func registryServes(client *api.Client, name, version string) string {
+	metadata, err := client.GetAbbreviatedMetadata(name)
+	var notFound *api.PackageNotFoundError
+	switch {
+	case errors.As(err, &notFound):
+		return "not found"
+	case err != nil:
+		return "lookup failed: " + err.Error()
+	case metadata.Versions[version] == nil:
+		return "version " + version + " not found"
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+func TestImportManifest(t *testing.T) {
+	published := map[string][]string{
+		"com.studio.analytics": {"1.2.0", "1.3.0"},
+		"com.studio.ads":       {"2.0.0"},
+		"com.studio.sdk.core":  {"0.9.0"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		versions, ok := published[r.URL.Path[1:]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		metadata := &api.PackageMetadata{Name: r.URL.Path[1:], Versions: map[string]*api.PackageVersion{}}
+		for _, version := range versions {
+			metadata.Versions[version] = &api.PackageVersion{Version: version}
+		}
+		_ = json.NewEncoder(w).Encode(metadata)
+	}))
+	defer server.Close()
+	sdkRegistry := httptest.NewServer(http.NotFoundHandler())
+	defer sdkRegistry.Close()
+
+	projectDir := t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "Packages"), 0755))
+	manifest := `{
+  "dependencies": {
+    "com.unity.textmeshpro": "3.0.6",
+    "com.unity.ugui": "1.0.0",
+    "com.studio.analytics": "1.2.0",
+    "com.studio.ads": "1.0.0",
+    "com.studio.local": "file:../local",
+    "com.studio.sdk.core": "0.9.0",
+    "com.vendor.tool": "4.1.0"
+  },
+  "scopedRegistries": [
+    {"name": "Studio", "url": "` + server.URL + `/", "scopes": ["com.studio"]},
+    {"name": "SDK", "url": "` + sdkRegistry.URL + `", "scopes": ["com.studio.sdk"]}
+  ]
+}
+`
+	manifestPath := filepath.Join(projectDir, "Packages", "manifest.json")
+	require.NoError(t, os.WriteFile(manifestPath, []byte(manifest), 0644))
+
+	styling.NoColor = true
+	defer func() {
+		styling.NoColor = false
+		importDryRun = false
+	}()
+
+	adapter := engines.NewUnityAdapter()
+	adapter.SetStateDir(projectStateDir(projectDir))
+
+	t.Run("dry run records nothing", func(t *testing.T) {
+		importDryRun = true
+		defer func() { importDryRun = false }()
+
+		out := captureStdout(t, func() error { return importManifest(projectDir) })
+		assert.Contains(t, out, "Would track 1 package(s)")
+		origins, err := adapter.PackageOrigins(projectDir)
+		require.NoError(t, err)
+		assert.Empty(t, origins)
+	})
+
+	t.Run("only registry packages are tracked", func(t *testing.T) {
+		out := captureStdout(t, func() error { return importManifest(projectDir) })
+		assert.Contains(t, out, "✓ com.studio.analytics@1.2.0 from "+server.URL)
+		assert.Contains(t, out, "- com.unity.ugui@1.0.0 (resolves from Unity's registry, left untouched)")
+		assert.Contains(t, out, "- com.vendor.tool@4.1.0 (resolves from Unity's registry, left untouched)")
+		assert.Contains(t, out, "- com.studio.local@file:../local (not installed from a registry)")
+		assert.Contains(t, out, "- com.studio.ads@1.0.0 (version 1.0.0 not found in "+server.URL+")")
+		assert.Contains(t, out, "- com.studio.sdk.core@0.9.0 (not found in "+sdkRegistry.URL+")", "the most specific scope decides the registry")
+		assert.Contains(t, out, "Tracking 1 package(s) in "+engines.UnityPackagesFile)
+
+		origins, err := adapter.PackageOrigins(projectDir)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]engines.PackageOrigin{
+			"com.studio.analytics": {Registry: server.URL, Version: "1.2.0"},
+		}, origins)
+
+		data, err := os.ReadFile(manifestPath)
+		require.NoError(t, err)
+		assert.Equal(t, manifest, string(data), "the manifest is left as it was")
+	})
+
+	t.Run("tracked packages are kept", func(t *testing.T) {
+		require.NoError(t, adapter.SetPackageOrigin(projectDir, "com.studio.analytics", &engines.PackageOrigin{Registry: server.URL, Range: "^1.2.0", Version: "1.2.0"}))
+
+		out := captureStdout(t, func() error { return importManifest(projectDir) })
+		assert.Contains(t, out, "- com.studio.analytics@1.2.0 (already tracked)")
+		assert.Contains(t, out, "No registry packages to import")
+		origins, err := adapter.PackageOrigins(projectDir)
+		require.NoError(t, err)
+		assert.Equal(t, "^1.2.0", origins["com.studio.analytics"].Range)
+	})
+}
@@ -2,33 +2,67 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/api"
 	"gpm.sh/gpm/gpm-cli/internal/config"
+	gpmerrors "gpm.sh/gpm/gpm-cli/internal/errors"
 	"gpm.sh/gpm/gpm-cli/internal/styling"
+	"gpm.sh/gpm/gpm-cli/internal/validation"
 )
 
 var (
-	infoVersion string
-	infoVerbose bool
-	infoJSON    bool
+	infoVersion    string
+	infoVerbose    bool
+	infoJSON       bool
+	infoVersions   bool
+	infoLimit      int
+	infoNoTruncate bool
+	infoNoPager    bool
+	infoHealth     bool
+	infoStats      bool
+	infoRegistry   string
 )
 
+// defaultInfoListLimit is how many dependencies or versions info lists on a
+// terminal before cutting the list short
+const defaultInfoListLimit = 20
+
 var infoCmd = &cobra.Command{
-	Use:   "info <package>",
-	Short: "Show package information",
+	Use:     "info <package>",
+	Aliases: []string{"view"},
+	Short:   "Show package information",
 	Long: `Display detailed information about a package from the registry.
 
 Examples:
   gpm info com.unity.ugui
+  gpm info com.unity.ugui@1.0.0
   gpm info com.unity.ugui --version 1.0.0
-  gpm info com.company.package --verbose`,
+  gpm info com.company.package --verbose
+  gpm info com.company.package --versions
+  gpm info com.company.package --verbose --limit 5
+  gpm info com.company.package --health
+  gpm info com.company.package --stats
+
+On a terminal, dependency and version lists longer than 20 entries are cut
+short and long output is shown through $PAGER. Piped output and --json are
+never paged or truncated unless --limit is given.
+
+--health summarizes what to check before depending on a package: how many
+versions are deprecated, how recently it was published, whether latest is a
+prerelease, and whether it declares a license and repository.
+
+--stats adds last-week and last-month download counts from the registry's
+npm-style /downloads/point endpoint. Registries that don't count downloads
+are skipped silently.`,
 	Args: cobra.ExactArgs(1),
 	RunE: info,
 }
@@ -37,52 +71,189 @@ func init() {
 	infoCmd.Flags().StringVar(&infoVersion, "version", "", "Show info for specific version")
 	infoCmd.Flags().BoolVarP(&infoVerbose, "verbose", "v", false, "Show detailed information")
 	infoCmd.Flags().BoolVar(&infoJSON, "json", false, "Output in JSON format")
+	infoCmd.Flags().BoolVar(&infoVersions, "versions", false, "List all published versions, one per line")
+	infoCmd.Flags().BoolVar(&infoVersions, "all", false, "Alias for --versions")
+	infoCmd.Flags().IntVar(&infoLimit, "limit", 0, "Maximum number of dependencies or versions to list")
+	infoCmd.Flags().BoolVar(&infoNoTruncate, "no-truncate", false, "List every dependency and version, ignoring --limit")
+	infoCmd.Flags().BoolVar(&infoNoPager, "no-pager", false, "Don't page long output through $PAGER")
+	infoCmd.Flags().BoolVar(&infoHealth, "health", false, "Summarize deprecations, publish recency and missing metadata across all versions")
+	infoCmd.Flags().BoolVar(&infoStats, "stats", false, "Show download counts when the registry provides them")
+	infoCmd.Flags().StringVar(&infoRegistry, "registry", "", "Registry to read the package from (default: the registry its scope maps to)")
 }
 
 func info(cmd *cobra.Command, args []string) error {
-	packageName := args[0]
-
-	cfg := config.GetConfig()
+	packageName, version := args[0], infoVersion
+	// "<package>@<version>" is shorthand for --version
+	if name, specVersion, ok := strings.Cut(args[0], "@"); ok && name != "" && specVersion != "" {
+		if version != "" && version != specVersion {
+			return fmt.Errorf("%s\n\n%s",
+				styling.Error(fmt.Sprintf("Conflicting versions: %s and --version %s", args[0], version)),
+				styling.Hint("Give the version either after @ or with --version"))
+		}
+		packageName, version = name, specVersion
+	}
 
-	// Fetch package metadata
-	baseURL, err := url.Parse(cfg.Registry)
-	if err != nil {
+	// Private and scoped packages are read with the user's credentials from
+	// the registry their scope maps to
+	projectDir, _ := os.Getwd()
+	registry := config.ResolveScopedRegistry(config.PackageScope(packageName), infoRegistry, projectDir).Value
+	if _, err := url.Parse(registry); err != nil {
 		return fmt.Errorf("%s\n\n%s",
 			styling.Error("Invalid registry URL: "+err.Error()),
 			styling.Hint("Check your registry URL with 'gpm config get registry'"))
 	}
-	packageURL := baseURL.JoinPath(packageName).String()
-	// #nosec G107 - URL is validated using url.Parse and JoinPath above
-	resp, err := http.Get(packageURL)
+	token := registryToken(registry, config.ResolveRegistry(infoRegistry, projectDir), projectDir)
+	client := api.NewClient(registry, token).WithContext(commandCtx)
+
+	packageInfo, err := client.GetPackageDocument(packageName)
 	if err != nil {
-		return fmt.Errorf("%s\n\n%s",
-			styling.Error("Failed to fetch package information: "+err.Error()),
-			styling.Hint("Check your internet connection and verify the package name"))
+		return packageLookupError(err, packageName, registry, token != "")
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode == 404 {
-		return fmt.Errorf("%s\n\n%s",
-			styling.Error("Package not found: "+packageName),
-			styling.Hint("Check the package name spelling or search with 'gpm search "+packageName+"'"))
+	// Decided before paging, which redirects stdout
+	terminal := stdoutIsTerminal()
+
+	if infoVersions {
+		if infoJSON {
+			return displayVersionList(packageInfo, 0)
+		}
+		return withPager(infoNoPager, func() error {
+			return displayVersionList(packageInfo, infoListLimit(false))
+		})
 	}
 
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("%s\n\n%s",
-			styling.Error(fmt.Sprintf("Registry error (HTTP %d)", resp.StatusCode)),
-			styling.Hint("The registry may be experiencing issues. Try again later."))
+	if infoHealth {
+		return displayPackageHealth(packageHealth(packageInfo, time.Now()), infoListLimit(terminal))
 	}
 
-	var packageInfo map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&packageInfo); err != nil {
-		return fmt.Errorf("failed to parse package information: %w", err)
+	published := hasPublishedVersions(packageInfo)
+	var stats *api.DownloadStats
+	if infoStats && published {
+		stats = downloadStats(client, packageName)
 	}
 
 	// Handle JSON output
 	if infoJSON {
+		packageInfo["published"] = published
+		if stats != nil {
+			packageInfo["downloads"] = stats
+		}
+		// repository keeps its raw value; repositoryUrl is the browseable form
+		if repoURL := packageRepositoryURL(packageInfo); repoURL != "" {
+			packageInfo["repositoryUrl"] = repoURL
+		}
+		if published {
+			if version == "" {
+				version, _ = getMapField(packageInfo, "dist-tags")["latest"].(string)
+			}
+			if publishedAt, raw, ok := versionPublishTime(packageInfo, version); ok {
+				packageInfo["versionPublished"] = map[string]interface{}{
+					"version": version,
+					"time":    raw,
+					"date":    publishedAt.Format("2006-01-02 15:04:05"),
+				}
+			}
+		}
 		return outputJSON(packageInfo)
 	}
 
+	limit := infoListLimit(terminal)
+	return withPager(infoNoPager, func() error {
+		displayPackageInfo(packageInfo, version, published, limit, stats)
+		return nil
+	})
+}
+
+// packageLookupError explains why the registry didn't return packageName.
+// Registries hide private packages from users who can't read them behind a
+// 404, so with credentials a 404 may also mean no access.
+func packageLookupError(err error, packageName, registry string, authenticated bool) error {
+	var notFound *api.PackageNotFoundError
+	var httpErr *api.HTTPError
+	var gpmErr *gpmerrors.GPMError
+	switch {
+	case errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnauthorized,
+		errors.As(err, &gpmErr) && strings.Contains(gpmErr.Code, "UNAUTHORIZED"):
+		if authenticated {
+			return fmt.Errorf("%s\n\n%s",
+				styling.Error("Authentication failed for "+registry+": your token is invalid or expired"),
+				styling.Hint("Run 'gpm login' and try again"))
+		}
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("Authentication required: "+packageName+" is private on "+registry),
+			styling.Hint("Run 'gpm login' and try again"))
+	case errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("No access to "+packageName+" on "+registry),
+			styling.Hint("Your account can't read this package; ask its owner for access"))
+	case errors.As(err, &notFound) && authenticated:
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("Package not found or no access: "+packageName),
+			styling.Hint("Check the package name spelling, and that your account can read it on "+registry))
+	case errors.As(err, &notFound):
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("Package not found: "+packageName),
+			styling.Hint("Check the package name spelling or search with 'gpm search "+packageName+"'. Private packages need 'gpm login' first"))
+	case errors.As(err, &httpErr):
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("Registry error (HTTP %d)", httpErr.StatusCode)),
+			styling.Hint("The registry may be experiencing issues. Try again later."))
+	default:
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("Failed to fetch package information: "+err.Error()),
+			styling.Hint("Check your internet connection and verify the package name"))
+	}
+}
+
+// infoListLimit returns how many entries info lists before cutting a list
+// short, or 0 for all of them
+func infoListLimit(terminal bool) int {
+	switch {
+	case infoNoTruncate:
+		return 0
+	case infoLimit > 0:
+		return infoLimit
+	case terminal:
+		return defaultInfoListLimit
+	default:
+		return 0
+	}
+}
+
+// limitList keeps the first limit items, returning how many were left out
+func limitList(items []string, limit int) ([]string, int) {
+	if limit <= 0 || len(items) <= limit {
+		return items, 0
+	}
+	return items[:limit], len(items) - limit
+}
+
+// limitNewest keeps the last limit items of an ascending version list
+func limitNewest(versions []string, limit int) ([]string, int) {
+	if limit <= 0 || len(versions) <= limit {
+		return versions, 0
+	}
+	return versions[len(versions)-limit:], len(versions) - limit
+}
+
+func displayOmitted(omitted int) {
+	if omitted > 0 {
+		fmt.Printf("  %s\n", styling.Muted(fmt.Sprintf("... and %d more (use --no-truncate to show all)", omitted)))
+	}
+}
+
+// downloadStats fetches a package's download counts, warning on stderr and
+// returning nil when they can't be had
+func downloadStats(client *api.Client, packageName string) *api.DownloadStats {
+	stats, err := client.GetDownloadStats(packageName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, styling.Warning("⚠ Download stats unavailable: "+err.Error()))
+		return nil
+	}
+	return stats
+}
+
+func displayPackageInfo(packageInfo map[string]interface{}, version string, published bool, limit int, stats *api.DownloadStats) {
 	// Display formatted output
 	fmt.Println(styling.Header("ℹ️   Package Information"))
 	fmt.Println(styling.Separator())
@@ -90,19 +261,39 @@ func info(cmd *cobra.Command, args []string) error {
 	// Display basic information
 	displayBasicInfo(packageInfo)
 
+	if !published {
+		displayNoPublishedVersions(packageInfo)
+		fmt.Println(styling.Separator())
+		return
+	}
+
 	// Display version information
-	if infoVersion != "" {
-		displayVersionInfo(packageInfo, infoVersion)
+	if version != "" {
+		displayVersionInfo(packageInfo, version, limit)
 	} else {
-		displayLatestVersion(packageInfo)
+		displayLatestVersion(packageInfo, limit)
 	}
 
 	if infoVerbose {
-		displayDetailedInfo(packageInfo)
+		displayDetailedInfo(packageInfo, limit)
+	}
+
+	if stats != nil {
+		fmt.Println()
+		fmt.Printf("%s %s\n", styling.Label("Downloads:"), styling.Value(fmt.Sprintf("%d last week, %d last month", stats.LastWeek, stats.LastMonth)))
 	}
 
 	fmt.Println(styling.Separator())
-	return nil
+}
+
+// packageRepositoryURL returns the browseable repository of a packument,
+// preferring the document-level field over the latest version's
+func packageRepositoryURL(pkg map[string]interface{}) string {
+	if repoURL := repositoryURL(pkg["repository"]); repoURL != "" {
+		return repoURL
+	}
+	latest, _ := getMapField(pkg, "dist-tags")["latest"].(string)
+	return repositoryURL(getMapField(getMapField(pkg, "versions"), latest)["repository"])
 }
 
 func displayBasicInfo(pkg map[string]interface{}) {
@@ -148,18 +339,20 @@ func displayBasicInfo(pkg map[string]interface{}) {
 	fmt.Println()
 }
 
-func displayLatestVersion(pkg map[string]interface{}) {
-	distTags, ok := pkg["dist-tags"].(map[string]interface{})
+func displayLatestVersion(pkg map[string]interface{}, limit int) {
+	latest, ok := getMapField(pkg, "dist-tags")["latest"].(string)
 	if !ok {
-		return
-	}
-
-	latest, ok := distTags["latest"].(string)
-	if !ok {
-		return
+		// Without a latest tag, fall back to the highest published version
+		versions := sortedVersionKeys(pkg)
+		if len(versions) == 0 {
+			return
+		}
+		latest = versions[len(versions)-1]
+		fmt.Printf("%s %s %s\n", styling.Label("Latest Version:"), styling.Version(latest), styling.Muted("(no latest dist-tag)"))
+	} else {
+		fmt.Printf("%s %s\n", styling.Label("Latest Version:"), styling.Version(latest))
 	}
-
-	fmt.Printf("%s %s\n", styling.Label("Latest Version:"), styling.Version(latest))
+	displayPublishTime(pkg, latest)
 
 	versions, ok := pkg["versions"].(map[string]interface{})
 	if !ok {
@@ -171,10 +364,10 @@ func displayLatestVersion(pkg map[string]interface{}) {
 		return
 	}
 
-	displayVersionDetails(versionInfo)
+	displayVersionDetails(versionInfo, limit)
 }
 
-func displayVersionInfo(pkg map[string]interface{}, version string) {
+func displayVersionInfo(pkg map[string]interface{}, version string, limit int) {
 	versions, ok := pkg["versions"].(map[string]interface{})
 	if !ok {
 		fmt.Printf("%s\n", styling.Error("No version information available"))
@@ -187,25 +380,29 @@ func displayVersionInfo(pkg map[string]interface{}, version string) {
 
 		// Show available versions
 		fmt.Printf("\n%s\n", styling.Label("Available versions:"))
-		for v := range versions {
+		available, omitted := limitNewest(sortedVersionKeys(pkg), limit)
+		for _, v := range available {
 			fmt.Printf("  %s\n", styling.Version(v))
 		}
+		displayOmitted(omitted)
 		return
 	}
 
 	fmt.Printf("%s %s\n", styling.Label("Version:"), styling.Version(version))
-	displayVersionDetails(versionInfo)
+	displayPublishTime(pkg, version)
+	displayVersionDetails(versionInfo, limit)
 }
 
-func displayVersionDetails(versionInfo map[string]interface{}) {
-	if author := getMapField(versionInfo, "author"); author != nil {
-		if name := getStringField(author, "name"); name != "" {
-			fmt.Printf("%s %s", styling.Label("Author:"), styling.Value(name))
-			if email := getStringField(author, "email"); email != "" {
-				fmt.Printf(" <%s>", styling.Muted(email))
-			}
-			fmt.Println()
+func displayVersionDetails(versionInfo map[string]interface{}, limit int) {
+	if author := validation.AuthorFromValue(versionInfo["author"]); author != nil && author.Name != "" {
+		fmt.Printf("%s %s", styling.Label("Author:"), styling.Value(author.Name))
+		if author.Email != "" {
+			fmt.Printf(" <%s>", styling.Muted(author.Email))
+		}
+		if author.URL != "" {
+			fmt.Printf(" (%s)", styling.URL(author.URL))
 		}
+		fmt.Println()
 	}
 
 	// Show all maintainers
@@ -233,10 +430,8 @@ func displayVersionDetails(versionInfo map[string]interface{}) {
 		fmt.Printf("%s %s\n", styling.Label("Homepage:"), styling.URL(homepage))
 	}
 
-	if repository := getMapField(versionInfo, "repository"); repository != nil {
-		if url := getStringField(repository, "url"); url != "" {
-			fmt.Printf("%s %s\n", styling.Label("Repository:"), styling.URL(url))
-		}
+	if repoURL := repositoryURL(versionInfo["repository"]); repoURL != "" {
+		fmt.Printf("%s %s\n", styling.Label("Repository:"), styling.URL(repoURL))
 	}
 
 	if keywords := getArrayField(versionInfo, "keywords"); len(keywords) > 0 {
@@ -274,17 +469,24 @@ func displayVersionDetails(versionInfo map[string]interface{}) {
 	// Display dependencies
 	if deps := getMapField(versionInfo, "dependencies"); len(deps) > 0 {
 		fmt.Printf("\n%s\n", styling.SubHeader("Dependencies:"))
-		for name, version := range deps {
-			if versionStr, ok := version.(string); ok {
+		names := make([]string, 0, len(deps))
+		for name := range deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		names, omitted := limitList(names, limit)
+		for _, name := range names {
+			if versionStr, ok := deps[name].(string); ok {
 				fmt.Printf("  %s@%s\n", styling.Package(name), styling.Version(versionStr))
 			}
 		}
+		displayOmitted(omitted)
 	}
 
 	fmt.Println()
 }
 
-func displayDetailedInfo(pkg map[string]interface{}) {
+func displayDetailedInfo(pkg map[string]interface{}, limit int) {
 	fmt.Printf("%s\n", styling.SubHeader("All Versions:"))
 
 	versions, ok := pkg["versions"].(map[string]interface{})
@@ -296,12 +498,17 @@ func displayDetailedInfo(pkg map[string]interface{}) {
 	// Show time information from package level
 	if timeInfo := getMapField(pkg, "time"); len(timeInfo) > 0 {
 		fmt.Printf("\n%s\n", styling.SubHeader("Version History:"))
-		for version, timestamp := range timeInfo {
-			if version == "created" || version == "modified" {
-				continue
+		var history []string
+		for version := range timeInfo {
+			if version != "created" && version != "modified" && version != "unpublished" {
+				history = append(history, version)
 			}
+		}
+		sortVersions(history)
+		history, omitted := limitNewest(history, limit)
+		for _, version := range history {
 			fmt.Printf("  %s", styling.Version(version))
-			if timeStr, ok := timestamp.(string); ok {
+			if timeStr, ok := timeInfo[version].(string); ok {
 				if parsedTime, err := time.Parse(time.RFC3339, timeStr); err == nil {
 					fmt.Printf(" - %s", styling.Muted(parsedTime.Format("2006-01-02 15:04:05")))
 				}
@@ -315,10 +522,12 @@ func displayDetailedInfo(pkg map[string]interface{}) {
 			}
 			fmt.Println()
 		}
+		displayOmitted(omitted)
 	} else {
 		// Fallback to old format
-		for version, versionData := range versions {
-			versionMap, ok := versionData.(map[string]interface{})
+		all, omitted := limitNewest(sortedVersionKeys(pkg), limit)
+		for _, version := range all {
+			versionMap, ok := versions[version].(map[string]interface{})
 			if !ok {
 				continue
 			}
@@ -337,7 +546,103 @@ func displayDetailedInfo(pkg map[string]interface{}) {
 
 			fmt.Println()
 		}
+		displayOmitted(omitted)
+	}
+}
+
+// versionPublishTime looks a version up in the packument's time map,
+// returning the parsed time and the registry's RFC3339 string
+func versionPublishTime(pkg map[string]interface{}, version string) (time.Time, string, bool) {
+	raw := getStringField(getMapField(pkg, "time"), version)
+	if raw == "" {
+		return time.Time{}, "", false
+	}
+	publishedAt, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return publishedAt, raw, true
+}
+
+func displayPublishTime(pkg map[string]interface{}, version string) {
+	if publishedAt, _, ok := versionPublishTime(pkg, version); ok {
+		fmt.Printf("%s %s\n", styling.Label("Published:"), styling.Value(publishedAt.Format("2006-01-02 15:04:05")))
+	}
+}
+
+// hasPublishedVersions reports whether the packument lists any versions. A
+// package whose versions were all unpublished still exists in the registry,
+// unlike one that returns 404.
+func hasPublishedVersions(pkg map[string]interface{}) bool {
+	return len(getMapField(pkg, "versions")) > 0
+}
+
+func displayNoPublishedVersions(pkg map[string]interface{}) {
+	fmt.Printf("%s\n", styling.Warning("Package "+getStringField(pkg, "name")+" has no published versions"))
+	if unpublished := getMapField(getMapField(pkg, "time"), "unpublished"); unpublished != nil {
+		if timeStr := getStringField(unpublished, "time"); timeStr != "" {
+			fmt.Printf("%s %s\n", styling.Label("Unpublished:"), styling.Value(timeStr))
+		}
+	}
+	fmt.Println()
+}
+
+// displayVersionList prints every published version in ascending semver order,
+// one per line, so the output can be piped into scripts. A limit keeps only
+// the newest versions.
+func displayVersionList(pkg map[string]interface{}, limit int) error {
+	versions, omitted := limitNewest(sortedVersionKeys(pkg), limit)
+
+	if infoJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(versions)
+	}
+
+	if len(versions) == 0 {
+		// Keep stdout empty for scripts
+		fmt.Fprintln(os.Stderr, styling.Warning("Package "+getStringField(pkg, "name")+" has no published versions"))
+		return nil
+	}
+
+	// Invert dist-tags so each version can show the tags pointing at it
+	tagsByVersion := make(map[string][]string)
+	for tag, v := range getMapField(pkg, "dist-tags") {
+		if version, ok := v.(string); ok {
+			tagsByVersion[version] = append(tagsByVersion[version], tag)
+		}
+	}
+
+	versionData := getMapField(pkg, "versions")
+	for _, version := range versions {
+		line := version
+		if tags := tagsByVersion[version]; len(tags) > 0 {
+			sort.Strings(tags)
+			line += " " + styling.Version("("+strings.Join(tags, ", ")+")")
+		}
+		if versionMap := getMapField(versionData, version); versionMap != nil {
+			if getStringField(versionMap, "deprecated") != "" {
+				line += " " + styling.Error("[DEPRECATED]")
+			}
+		}
+		fmt.Println(line)
+	}
+	if omitted > 0 {
+		// Keep stdout to one version per line
+		fmt.Fprintln(os.Stderr, styling.Muted(fmt.Sprintf("%d older version(s) not shown", omitted)))
+	}
+
+	return nil
+}
+
+// sortedVersionKeys returns the keys of the packument's versions map in semver order
+func sortedVersionKeys(pkg map[string]interface{}) []string {
+	versions := []string{}
+	for version := range getMapField(pkg, "versions") {
+		versions = append(versions, version)
 	}
+	sortVersions(versions)
+	return versions
 }
 
 func getStringField(m map[string]interface{}, key string) string {
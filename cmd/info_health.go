@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+// staleAfter is how long since the last publish before a package counts as
+// unmaintained
+const staleAfter = 365 * 24 * time.Hour
+
+// PackageHealth summarizes what studios check when vetting a dependency
+type PackageHealth struct {
+	Name       string   `json:"name"`
+	Versions   int      `json:"versions"`
+	Deprecated []string `json:"deprecated"`
+	Latest     string   `json:"latest,omitempty"`
+	// LatestPrerelease is set when the latest dist-tag points at a prerelease
+	LatestPrerelease bool `json:"latestPrerelease"`
+	LatestDeprecated bool `json:"latestDeprecated"`
+	// LastPublished is the newest version's publish time, in RFC 3339
+	LastPublished    string `json:"lastPublished,omitempty"`
+	DaysSincePublish int    `json:"daysSincePublish,omitempty"`
+	// MissingMetadata lists the fields the latest version lacks, such as
+	// "license" or "repository"
+	MissingMetadata []string `json:"missingMetadata"`
+	// Problems are the findings above worth acting on, one sentence each
+	Problems []string `json:"problems"`
+}
+
+// packageHealth derives the health summary from a packument, measuring
+// recency against now
+func packageHealth(pkg map[string]interface{}, now time.Time) PackageHealth {
+	health := PackageHealth{
+		Name:            getStringField(pkg, "name"),
+		Deprecated:      []string{},
+		MissingMetadata: []string{},
+		Problems:        []string{},
+	}
+
+	versions := sortedVersionKeys(pkg)
+	health.Versions = len(versions)
+	if len(versions) == 0 {
+		health.Problems = append(health.Problems, "no published versions")
+		return health
+	}
+
+	versionData := getMapField(pkg, "versions")
+	var lastPublished time.Time
+	for _, version := range versions {
+		if getStringField(getMapField(versionData, version), "deprecated") != "" {
+			health.Deprecated = append(health.Deprecated, version)
+		}
+		if publishedAt, raw, ok := versionPublishTime(pkg, version); ok && publishedAt.After(lastPublished) {
+			lastPublished = publishedAt
+			health.LastPublished = raw
+		}
+	}
+
+	latest, _ := getMapField(pkg, "dist-tags")["latest"].(string)
+	if latest == "" {
+		latest = versions[len(versions)-1]
+		health.Problems = append(health.Problems, "no latest dist-tag")
+	}
+	health.Latest = latest
+	latestInfo := getMapField(versionData, latest)
+
+	if api.IsPrerelease(latest) {
+		health.LatestPrerelease = true
+		health.Problems = append(health.Problems, fmt.Sprintf("latest points at prerelease %s", latest))
+	}
+	if getStringField(latestInfo, "deprecated") != "" {
+		health.LatestDeprecated = true
+		health.Problems = append(health.Problems, fmt.Sprintf("latest version %s is deprecated", latest))
+	}
+	if len(health.Deprecated) > 0 {
+		health.Problems = append(health.Problems, fmt.Sprintf("%d of %d versions are deprecated", len(health.Deprecated), len(versions)))
+	}
+
+	if !lastPublished.IsZero() {
+		age := now.Sub(lastPublished)
+		health.DaysSincePublish = int(age.Hours() / 24)
+		if age > staleAfter {
+			health.Problems = append(health.Problems, fmt.Sprintf("nothing published in %d days", health.DaysSincePublish))
+		}
+	}
+
+	if getStringField(latestInfo, "license") == "" && getStringField(pkg, "license") == "" {
+		health.MissingMetadata = append(health.MissingMetadata, "license")
+	}
+	if packageRepositoryURL(pkg) == "" {
+		health.MissingMetadata = append(health.MissingMetadata, "repository")
+	}
+	if len(health.MissingMetadata) > 0 {
+		health.Problems = append(health.Problems, "missing "+strings.Join(health.MissingMetadata, " and "))
+	}
+
+	return health
+}
+
+func displayPackageHealth(health PackageHealth, limit int) error {
+	if infoJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(health)
+	}
+
+	fmt.Println(styling.Header("🩺 Package Health"))
+	fmt.Println(styling.Separator())
+	fmt.Printf("%s %s\n", styling.Label("Name:"), styling.Package(health.Name))
+	fmt.Printf("%s %s\n", styling.Label("Versions:"), styling.Value(fmt.Sprintf("%d (%d deprecated)", health.Versions, len(health.Deprecated))))
+	if health.Latest != "" {
+		latest := styling.Version(health.Latest)
+		if health.LatestPrerelease {
+			latest += " " + styling.Warning("(prerelease)")
+		}
+		if health.LatestDeprecated {
+			latest += " " + styling.Error("[DEPRECATED]")
+		}
+		fmt.Printf("%s %s\n", styling.Label("Latest:"), latest)
+	}
+	if publishedAt, err := time.Parse(time.RFC3339, health.LastPublished); err == nil {
+		fmt.Printf("%s %s %s\n", styling.Label("Last Published:"), styling.Value(publishedAt.Format("2006-01-02")), styling.Muted(fmt.Sprintf("(%d days ago)", health.DaysSincePublish)))
+	}
+	if len(health.Deprecated) > 0 {
+		fmt.Printf("%s\n", styling.Label("Deprecated Versions:"))
+		deprecated, omitted := limitNewest(health.Deprecated, limit)
+		for _, version := range deprecated {
+			fmt.Printf("  %s\n", styling.Version(version))
+		}
+		displayOmitted(omitted)
+	}
+	fmt.Println(styling.Separator())
+
+	if len(health.Problems) == 0 {
+		fmt.Println(styling.Success("✓ No problems found"))
+		return nil
+	}
+	for _, problem := range health.Problems {
+		fmt.Printf("%s %s\n", styling.Warning("⚠"), problem)
+	}
+	return nil
+}
@@ -1,13 +1,19 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gpm.sh/gpm/gpm-cli/internal/config"
 )
 
@@ -80,3 +86,499 @@ func TestInfoCmdStructure(t *testing.T) {
 	assert.NotNil(t, infoCmd.RunE)
 	assert.False(t, infoCmd.HasSubCommands())
 }
+
+func TestSortVersions(t *testing.T) {
+	versions := []string{
+		"1.10.0",
+		"1.0.0",
+		"2.0.0-rc.1",
+		"1.0.0-beta.11",
+		"1.0.0-alpha",
+		"1.2.0",
+		"1.0.0-beta.2",
+		"1.0.0-alpha.1",
+		"2.0.0",
+		"1.0.0-rc.1",
+	}
+
+	sortVersions(versions)
+
+	assert.Equal(t, []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.2.0",
+		"1.10.0",
+		"2.0.0-rc.1",
+		"2.0.0",
+	}, versions)
+}
+
+func TestInfoVersionsList(t *testing.T) {
+	tempDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	defer func() {
+		_ = os.Setenv("HOME", originalHome)
+		infoVersions = false
+		infoJSON = false
+	}()
+	_ = os.Setenv("HOME", tempDir)
+
+	config.InitConfig()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mockResponse := map[string]interface{}{
+			"name": "com.test.package",
+			"versions": map[string]interface{}{
+				"1.10.0":       map[string]interface{}{"version": "1.10.0"},
+				"1.2.0":        map[string]interface{}{"version": "1.2.0", "deprecated": "use 1.10.0"},
+				"2.0.0-beta.1": map[string]interface{}{"version": "2.0.0-beta.1"},
+			},
+			"dist-tags": map[string]interface{}{
+				"latest": "1.10.0",
+				"next":   "2.0.0-beta.1",
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(mockResponse)
+	}))
+	defer server.Close()
+
+	config.SetRegistry(server.URL)
+	infoVersions = true
+
+	captureInfo := func() string {
+		originalStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		err := info(nil, []string{"com.test.package"})
+
+		_ = w.Close()
+		os.Stdout = originalStdout
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		return buf.String()
+	}
+
+	t.Run("plain output", func(t *testing.T) {
+		infoJSON = false
+		lines := strings.Split(strings.TrimSpace(captureInfo()), "\n")
+		require.Len(t, lines, 3)
+		assert.True(t, strings.HasPrefix(lines[0], "1.2.0"))
+		assert.Contains(t, lines[0], "[DEPRECATED]")
+		assert.True(t, strings.HasPrefix(lines[1], "1.10.0"))
+		assert.Contains(t, lines[1], "latest")
+		assert.True(t, strings.HasPrefix(lines[2], "2.0.0-beta.1"))
+		assert.Contains(t, lines[2], "next")
+	})
+
+	t.Run("json output", func(t *testing.T) {
+		infoJSON = true
+		var versions []string
+		require.NoError(t, json.Unmarshal([]byte(captureInfo()), &versions))
+		assert.Equal(t, []string{"1.2.0", "1.10.0", "2.0.0-beta.1"}, versions)
+	})
+}
+
+func TestInfoNoPublishedVersions(t *testing.T) {
+	tempDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	defer func() {
+		_ = os.Setenv("HOME", originalHome)
+		infoJSON = false
+	}()
+	_ = os.Setenv("HOME", tempDir)
+
+	config.InitConfig()
+
+	packuments := map[string]map[string]interface{}{
+		"/com.test.unpublished": {
+			"name":      "com.test.unpublished",
+			"versions":  map[string]interface{}{},
+			"dist-tags": map[string]interface{}{},
+			"time": map[string]interface{}{
+				"unpublished": map[string]interface{}{"time": "2024-01-01T00:00:00.000Z"},
+			},
+		},
+		"/com.test.untagged": {
+			"name": "com.test.untagged",
+			"versions": map[string]interface{}{
+				"1.2.0":  map[string]interface{}{"version": "1.2.0", "license": "MIT"},
+				"1.10.0": map[string]interface{}{"version": "1.10.0", "license": "Apache-2.0"},
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		packument, ok := packuments[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(packument)
+	}))
+	defer server.Close()
+
+	config.SetRegistry(server.URL)
+
+	captureInfo := func(name string) (string, error) {
+		originalStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		err := info(nil, []string{name})
+
+		_ = w.Close()
+		os.Stdout = originalStdout
+
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		return buf.String(), err
+	}
+
+	t.Run("empty versions", func(t *testing.T) {
+		infoJSON = false
+		output, err := captureInfo("com.test.unpublished")
+		require.NoError(t, err, "an existing package without versions is not an error")
+		assert.Contains(t, output, "Package com.test.unpublished has no published versions")
+		assert.Contains(t, output, "Unpublished:")
+	})
+
+	t.Run("empty versions json", func(t *testing.T) {
+		infoJSON = true
+		output, err := captureInfo("com.test.unpublished")
+		require.NoError(t, err)
+
+		var result map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(output), &result))
+		assert.Equal(t, false, result["published"])
+	})
+
+	t.Run("no latest dist-tag", func(t *testing.T) {
+		infoJSON = false
+		output, err := captureInfo("com.test.untagged")
+		require.NoError(t, err)
+		assert.Contains(t, output, "1.10.0")
+		assert.Contains(t, output, "no latest dist-tag")
+		assert.Contains(t, output, "Apache-2.0", "details of the highest version should be shown")
+		assert.NotContains(t, output, "no published versions")
+
+		infoJSON = true
+		output, err = captureInfo("com.test.untagged")
+		require.NoError(t, err)
+		var result map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(output), &result))
+		assert.Equal(t, true, result["published"])
+	})
+
+	t.Run("not found is still an error", func(t *testing.T) {
+		infoJSON = false
+		_, err := captureInfo("com.test.missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Package not found")
+	})
+}
+
+func TestInfoLimitAndJSON(t *testing.T) {
+	tempDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	defer func() {
+		_ = os.Setenv("HOME", originalHome)
+		infoJSON = false
+		infoVerbose = false
+		infoLimit = 0
+		infoNoTruncate = false
+		infoNoPager = false
+	}()
+	_ = os.Setenv("HOME", tempDir)
+
+	config.InitConfig()
+
+	dependencies := map[string]interface{}{}
+	versions := map[string]interface{}{}
+	for i := 0; i < 30; i++ {
+		dependencies[fmt.Sprintf("com.test.dep%02d", i)] = "1.0.0"
+		version := fmt.Sprintf("1.%d.0", i)
+		versions[version] = map[string]interface{}{"version": version}
+	}
+	versions["1.29.0"] = map[string]interface{}{"version": "1.29.0", "dependencies": dependencies}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"name":      "com.test.package",
+			"versions":  versions,
+			"dist-tags": map[string]interface{}{"latest": "1.29.0"},
+		})
+	}))
+	defer server.Close()
+	config.SetRegistry(server.URL)
+
+	runInfo := func() error { return info(nil, []string{"com.test.package"}) }
+
+	t.Run("piped output is complete", func(t *testing.T) {
+		output := captureStdout(t, runInfo)
+		assert.Equal(t, 30, strings.Count(output, "com.test.dep"))
+		assert.NotContains(t, output, "more (use --no-truncate")
+	})
+
+	t.Run("limit bounds listed dependencies and versions", func(t *testing.T) {
+		infoLimit = 5
+		infoVerbose = true
+		output := captureStdout(t, runInfo)
+		infoVerbose = false
+
+		assert.Equal(t, 5, strings.Count(output, "com.test.dep"))
+		assert.Contains(t, output, "com.test.dep04")
+		assert.NotContains(t, output, "com.test.dep05")
+		assert.Contains(t, output, "... and 25 more")
+		assert.Contains(t, output, "1.25.0", "the newest versions are kept")
+		assert.NotContains(t, output, "1.24.0")
+
+		infoNoTruncate = true
+		output = captureStdout(t, runInfo)
+		infoNoTruncate = false
+		assert.Equal(t, 30, strings.Count(output, "com.test.dep"))
+		infoLimit = 0
+	})
+
+	t.Run("json is never paged or truncated", func(t *testing.T) {
+		paged := fakeTerminalPager(t)
+		infoJSON = true
+		infoLimit = 5
+		output := captureStdout(t, runInfo)
+		infoLimit = 0
+		infoJSON = false
+		assert.NoFileExists(t, paged)
+
+		var result map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(output), &result))
+		assert.Len(t, getMapField(result, "versions"), 30)
+		latest := getMapField(getMapField(result, "versions"), "1.29.0")
+		assert.Len(t, getMapField(latest, "dependencies"), 30)
+	})
+}
+
+func TestInfoVersionPublishTime(t *testing.T) {
+	tempDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	defer func() {
+		_ = os.Setenv("HOME", originalHome)
+		infoJSON = false
+		infoVersion = ""
+	}()
+	_ = os.Setenv("HOME", tempDir)
+
+	config.InitConfig()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"name": "com.test.package",
+			"versions": map[string]interface{}{
+				"1.0.0": map[string]interface{}{"version": "1.0.0"},
+				"2.0.0": map[string]interface{}{"version": "2.0.0"},
+			},
+			"dist-tags": map[string]interface{}{"latest": "2.0.0"},
+			"time": map[string]interface{}{
+				"created": "2024-01-01T00:00:00.000Z",
+				"1.0.0":   "2024-03-05T10:20:30.000Z",
+				"2.0.0":   "2024-06-01T08:00:00.000Z",
+			},
+		})
+	}))
+	defer server.Close()
+	config.SetRegistry(server.URL)
+
+	t.Run("human output", func(t *testing.T) {
+		output := captureStdout(t, func() error { return info(nil, []string{"com.test.package@1.0.0"}) })
+		assert.Contains(t, output, "Published:")
+		assert.Contains(t, output, "2024-03-05 10:20:30")
+		assert.NotContains(t, output, "2024-06-01")
+	})
+
+	t.Run("json output", func(t *testing.T) {
+		infoJSON = true
+		infoVersion = "1.0.0"
+		output := captureStdout(t, func() error { return info(nil, []string{"com.test.package"}) })
+
+		var result map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(output), &result))
+		published := getMapField(result, "versionPublished")
+		require.NotNil(t, published)
+		assert.Equal(t, "1.0.0", published["version"])
+		assert.Equal(t, "2024-03-05 10:20:30", published["date"])
+		publishedAt, err := time.Parse(time.RFC3339, getStringField(published, "time"))
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 3, 5, 10, 20, 30, 0, time.UTC), publishedAt)
+	})
+
+	t.Run("conflicting versions", func(t *testing.T) {
+		infoJSON = false
+		infoVersion = "2.0.0"
+		err := info(nil, []string{"com.test.package@1.0.0"})
+		assert.ErrorContains(t, err, "Conflicting versions")
+	})
+}
+
+func TestInfoHealth(t *testing.T) {
+	tempDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	defer func() {
+		_ = os.Setenv("HOME", originalHome)
+		infoJSON = false
+		infoHealth = false
+	}()
+	_ = os.Setenv("HOME", tempDir)
+
+	config.InitConfig()
+
+	packument := map[string]interface{}{
+		"name": "com.test.package",
+		"versions": map[string]interface{}{
+			"1.0.0":      map[string]interface{}{"version": "1.0.0", "deprecated": "Use 2.x"},
+			"1.1.0":      map[string]interface{}{"version": "1.1.0", "deprecated": "Security issue"},
+			"2.0.0":      map[string]interface{}{"version": "2.0.0", "license": "MIT"},
+			"2.1.0-rc.1": map[string]interface{}{"version": "2.1.0-rc.1", "license": "MIT"},
+		},
+		"dist-tags": map[string]interface{}{"latest": "2.1.0-rc.1"},
+		"time": map[string]interface{}{
+			"1.0.0":      "2023-01-01T00:00:00.000Z",
+			"1.1.0":      "2023-06-01T00:00:00.000Z",
+			"2.0.0":      "2024-01-01T00:00:00.000Z",
+			"2.1.0-rc.1": "2024-02-01T00:00:00.000Z",
+		},
+	}
+
+	t.Run("summary", func(t *testing.T) {
+		health := packageHealth(packument, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, 4, health.Versions)
+		assert.Equal(t, []string{"1.0.0", "1.1.0"}, health.Deprecated)
+		assert.Equal(t, "2.1.0-rc.1", health.Latest)
+		assert.True(t, health.LatestPrerelease)
+		assert.False(t, health.LatestDeprecated)
+		assert.Equal(t, "2024-02-01T00:00:00.000Z", health.LastPublished)
+		assert.Equal(t, 30, health.DaysSincePublish)
+		assert.Equal(t, []string{"repository"}, health.MissingMetadata)
+		assert.Contains(t, health.Problems, "2 of 4 versions are deprecated")
+		assert.Contains(t, health.Problems, "latest points at prerelease 2.1.0-rc.1")
+		assert.Contains(t, health.Problems, "missing repository")
+
+		stale := packageHealth(packument, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+		assert.Contains(t, strings.Join(stale.Problems, "\n"), "nothing published in")
+	})
+
+	t.Run("healthy package", func(t *testing.T) {
+		healthy := map[string]interface{}{
+			"name":       "com.test.healthy",
+			"repository": map[string]interface{}{"type": "git", "url": "https://github.com/test/healthy"},
+			"versions": map[string]interface{}{
+				"1.0.0": map[string]interface{}{"version": "1.0.0", "license": "MIT"},
+			},
+			"dist-tags": map[string]interface{}{"latest": "1.0.0"},
+			"time":      map[string]interface{}{"1.0.0": "2024-01-01T00:00:00.000Z"},
+		}
+		health := packageHealth(healthy, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+		assert.Empty(t, health.Problems)
+		assert.Empty(t, health.Deprecated)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(packument)
+	}))
+	defer server.Close()
+	config.SetRegistry(server.URL)
+	infoHealth = true
+
+	t.Run("human output", func(t *testing.T) {
+		output := captureStdout(t, func() error { return info(nil, []string{"com.test.package"}) })
+		assert.Contains(t, output, "Package Health")
+		assert.Contains(t, output, "4 (2 deprecated)")
+		assert.Contains(t, output, "(prerelease)")
+		assert.Contains(t, output, "missing repository")
+	})
+
+	t.Run("json output", func(t *testing.T) {
+		infoJSON = true
+		output := captureStdout(t, func() error { return info(nil, []string{"com.test.package"}) })
+		var health PackageHealth
+		require.NoError(t, json.Unmarshal([]byte(output), &health))
+		assert.Equal(t, []string{"1.0.0", "1.1.0"}, health.Deprecated)
+		assert.True(t, health.LatestPrerelease)
+	})
+}
+
+func TestInfoDownloadStats(t *testing.T) {
+	tempDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	defer func() {
+		_ = os.Setenv("HOME", originalHome)
+		infoJSON = false
+		infoStats = false
+	}()
+	_ = os.Setenv("HOME", tempDir)
+
+	config.InitConfig()
+
+	countsDownloads := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/downloads/point/"):
+			if !countsDownloads {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error": "not found"}`))
+				return
+			}
+			downloads := 1200
+			if strings.Contains(r.URL.Path, "/last-month/") {
+				downloads = 4800
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"downloads": downloads, "package": "com.test.package"})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"name":      "com.test.package",
+				"versions":  map[string]interface{}{"1.0.0": map[string]interface{}{"version": "1.0.0"}},
+				"dist-tags": map[string]interface{}{"latest": "1.0.0"},
+			})
+		}
+	}))
+	defer server.Close()
+	config.SetRegistry(server.URL)
+
+	runInfo := func() error { return info(nil, []string{"com.test.package"}) }
+	infoStats = true
+
+	t.Run("stats are shown", func(t *testing.T) {
+		output := captureStdout(t, runInfo)
+		assert.Contains(t, output, "Downloads:")
+		assert.Contains(t, output, "1200 last week, 4800 last month")
+
+		infoJSON = true
+		output = captureStdout(t, runInfo)
+		infoJSON = false
+		var result map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(output), &result))
+		assert.Equal(t, map[string]interface{}{"lastWeek": float64(1200), "lastMonth": float64(4800)}, result["downloads"])
+	})
+
+	t.Run("stats are omitted when the registry has none", func(t *testing.T) {
+		countsDownloads = false
+		output := captureStdout(t, runInfo)
+		assert.NotContains(t, output, "Downloads:")
+		assert.Contains(t, output, "com.test.package")
+
+		infoJSON = true
+		output = captureStdout(t, runInfo)
+		infoJSON = false
+		var result map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(output), &result))
+		assert.NotContains(t, result, "downloads")
+	})
+}
@@ -15,19 +15,19 @@ import (
 )
 
 type PackageJSON struct {
-	Name         string            `json:"name"`
-	Version      string            `json:"version"`
-	DisplayName  string            `json:"displayName,omitempty"`
-	Description  string            `json:"description,omitempty"`
-	Unity        string            `json:"unity,omitempty"`
-	License      string            `json:"license,omitempty"`
-	Author       interface{}       `json:"author,omitempty"`
-	Keywords     []string          `json:"keywords,omitempty"`
-	Category     string            `json:"category,omitempty"`
-	Dependencies map[string]string `json:"dependencies,omitempty"`
-	Repository   interface{}       `json:"repository,omitempty"`
-	Bugs         interface{}       `json:"bugs,omitempty"`
-	Homepage     string            `json:"homepage,omitempty"`
+	Name         string             `json:"name"`
+	Version      string             `json:"version"`
+	DisplayName  string             `json:"displayName,omitempty"`
+	Description  string             `json:"description,omitempty"`
+	Unity        string             `json:"unity,omitempty"`
+	License      string             `json:"license,omitempty"`
+	Author       *validation.Author `json:"author,omitempty"`
+	Keywords     []string           `json:"keywords,omitempty"`
+	Category     string             `json:"category,omitempty"`
+	Dependencies map[string]string  `json:"dependencies,omitempty"`
+	Repository   interface{}        `json:"repository,omitempty"`
+	Bugs         interface{}        `json:"bugs,omitempty"`
+	Homepage     string             `json:"homepage,omitempty"`
 }
 
 var initCmd = &cobra.Command{
@@ -47,6 +47,7 @@ func init() {
 	initCmd.Flags().StringP("description", "d", "", "Package description")
 	initCmd.Flags().String("unity", "2021.3", "Minimum Unity version")
 	initCmd.Flags().String("license", "MIT", "Package license")
+	initCmd.Flags().String("author", "", `Package author as "Name <email> (url)"`)
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
@@ -104,6 +105,8 @@ func setDefaults(cmd *cobra.Command, pkg *PackageJSON) error {
 	pkg.Description, _ = cmd.Flags().GetString("description")
 	pkg.Unity, _ = cmd.Flags().GetString("unity")
 	pkg.License, _ = cmd.Flags().GetString("license")
+	author, _ := cmd.Flags().GetString("author")
+	pkg.Author = parseInitAuthor(author)
 
 	if pkg.Description == "" {
 		pkg.Description = "A Unity package"
@@ -130,6 +133,7 @@ func promptForValues(cmd *cobra.Command, pkg *PackageJSON) error {
 	pkg.Description = promptWithDefault(reader, "description", "A Unity package")
 	pkg.Unity = promptWithDefault(reader, "unity", "2021.3")
 	pkg.License = promptWithDefault(reader, "license", "MIT")
+	pkg.Author = parseInitAuthor(promptWithDefault(reader, "author", ""))
 	pkg.Category = promptWithDefault(reader, "category", "Libraries")
 
 	keywords := promptWithDefault(reader, "keywords", "unity,package")
@@ -143,6 +147,16 @@ func promptForValues(cmd *cobra.Command, pkg *PackageJSON) error {
 	return nil
 }
 
+// parseInitAuthor turns an author string into the structured form, or nil
+// when no author was given
+func parseInitAuthor(value string) *validation.Author {
+	author := validation.ParseAuthor(value)
+	if author.IsZero() {
+		return nil
+	}
+	return &author
+}
+
 func getDefaultName(cmd *cobra.Command) (string, error) {
 	name, _ := cmd.Flags().GetString("name")
 	if name != "" {
@@ -3,22 +3,37 @@ package cmd
 import (
 	"archive/tar"
 	"compress/gzip"
+	"context"
+	"crypto/sha1" // #nosec G505 - only used to verify sha1 integrity strings
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/api"
 	"gpm.sh/gpm/gpm-cli/internal/config"
 	"gpm.sh/gpm/gpm-cli/internal/engines"
+	"gpm.sh/gpm/gpm-cli/internal/packaging"
 	"gpm.sh/gpm/gpm-cli/internal/styling"
+	"gpm.sh/gpm/gpm-cli/internal/validation"
 )
 
 func validatePath(filePath, destDir string) error {
@@ -41,8 +56,9 @@ func validatePath(filePath, destDir string) error {
 	return nil
 }
 
-// isValidPackageURL validates that the package URL is safe and belongs to the expected host
-func isValidPackageURL(packageURL, expectedHost string) bool {
+// isValidPackageURL validates that the package URL is safe and belongs to one
+// of the allowed hosts (the registry, plus any configured tarball CDN hosts)
+func isValidPackageURL(packageURL string, allowedHosts ...string) bool {
 	parsedURL, err := url.Parse(packageURL)
 	if err != nil {
 		return false
@@ -53,21 +69,55 @@ func isValidPackageURL(packageURL, expectedHost string) bool {
 		return false
 	}
 
-	// Ensure the host matches the expected host
-	if parsedURL.Host != expectedHost {
+	// Ensure the host is one we trust to serve packages
+	allowed := false
+	for _, host := range allowedHosts {
+		if strings.EqualFold(parsedURL.Host, host) || strings.EqualFold(parsedURL.Hostname(), host) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
 		return false
 	}
 
 	// Prevent localhost and private IP ranges
-	if strings.HasPrefix(parsedURL.Host, "localhost") ||
-		strings.HasPrefix(parsedURL.Host, "127.") ||
-		strings.HasPrefix(parsedURL.Host, "192.168.") ||
-		strings.HasPrefix(parsedURL.Host, "10.") ||
-		strings.HasPrefix(parsedURL.Host, "172.") {
+	return !blockedTarballHost(parsedURL.Hostname())
+}
+
+// httpGetPackageURL fetches a URL that passed isValidPackageURL, holding every
+// redirect to the same hosts
+func httpGetPackageURL(ctx context.Context, packageURL string, allowedHosts ...string) (*http.Response, error) {
+	return api.HTTPGetAllowed(ctx, packageURL, func(target string) bool {
+		return isValidPackageURL(target, allowedHosts...)
+	})
+}
+
+// maxTarballSize caps a downloaded tarball and each file extracted from it
+const maxTarballSize = 100 * 1024 * 1024
+
+// blockedTarballHost rejects localhost and private addresses; a variable so
+// tests can download from httptest servers
+var blockedTarballHost = isPrivateHost
+
+// isPrivateHost reports whether a hostname is localhost or a loopback,
+// private, link-local or unspecified IP address
+func isPrivateHost(hostname string) bool {
+	hostname = strings.ToLower(hostname)
+	if hostname == "localhost" || strings.HasSuffix(hostname, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(hostname)
+	if ip == nil {
 		return false
 	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+}
 
-	return true
+// tarballAllowedHosts returns the hosts install may download tarballs from:
+// the registry itself plus the CDN hosts configured under tarball_hosts
+func tarballAllowedHosts(registryHost string) []string {
+	return append([]string{registryHost}, config.GetConfig().TarballHosts...)
 }
 
 //nolint:unused
@@ -96,18 +146,72 @@ func validateSafetyPath(path string) error {
 }
 
 var (
-	installGlobal     bool
-	installVersion    string
-	installSave       bool
-	installSaveDev    bool
-	installUnity      bool
-	installUnreal     bool
-	installGodot      bool
-	installCocos      bool
-	installProjectDir string
-	installRegistry   string
+	installGlobal           bool
+	installVersion          string
+	installSave             bool
+	installNoSave           bool
+	installSaveDev          bool
+	installUnity            bool
+	installUnreal           bool
+	installGodot            bool
+	installCocos            bool
+	installProjectDir       string
+	installProjects         []string
+	installCI               bool
+	installRegistry         string
+	installPackagesDir      string
+	installSideBySide       bool
+	installIfPresent        bool
+	installEngineStrict     bool
+	installStrict           bool
+	installDryRun           bool
+	installJSON             bool
+	installJSONStream       bool
+	installForce            bool
+	installNormalize        bool
+	installBundle           string
+	installNoScopedRegistry bool
+	installRegistryTimeout  time.Duration
+	installTag              string
+	installVerify           bool
+	installVerbose          bool
+	// installDownloadConcurrency and installExtractConcurrency bound the
+	// install pipeline's worker pools
+	installDownloadConcurrency int
+	installExtractConcurrency  int
+	installAuditSignatures     bool
+	installCheck               bool
+
+	// installEvents receives --json-stream package events; nil otherwise
+	installEvents *eventStream
 )
 
+// InstallOutput is the --json result of installing packages by name
+type InstallOutput struct {
+	Success  bool                  `json:"success"`
+	DryRun   bool                  `json:"dry_run,omitempty"`
+	Engine   string                `json:"engine,omitempty"`
+	Project  string                `json:"project,omitempty"`
+	Bundle   string                `json:"bundle,omitempty"`
+	Packages []string              `json:"packages"`
+	Diff     *engines.ManifestDiff `json:"diff,omitempty"`
+	// Inspect maps package@version to the temporary directory a --no-save
+	// Unity install downloaded it into
+	Inspect map[string]string `json:"inspect,omitempty"`
+	// Projects maps each --projects directory to its detected engine
+	Projects map[string]string `json:"projects,omitempty"`
+	// Registries maps packages to the registry that served them when
+	// registry_fallbacks are configured
+	Registries map[string]string `json:"registries,omitempty"`
+	Warnings   []string          `json:"warnings,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	// Policy explains a package refused by the allowlist or blocklist
+	Policy *config.PolicyError `json:"policy,omitempty"`
+	// Drift lists the packages --check found out of line with their
+	// declared versions
+	Drift []PackageDrift `json:"drift,omitempty"`
+}
+
 var installCmd = &cobra.Command{
 	Use:   "install [package[@version]...]",
 	Short: "Install packages with multi-engine support",
@@ -130,9 +234,13 @@ Engine-Specific Installation:
 
 Basic Examples:
   gpm install                              # Install from package.json (auto-detect engine)
+  gpm install --if-present                 # No-op when there is no package.json
   gpm install package-name                 # Install package (auto-detect engine)
   gpm install package-name@1.0.0           # Install specific version
+  gpm install --tag beta package-name      # Install the version tagged beta instead of latest
   gpm install pkg1 pkg2 pkg3               # Install multiple packages
+  gpm install --bundle core-tools          # Install every package in a bundle
+  gpm install my-ui@npm:com.vendor.ui@1.0.0  # Install under the alias my-ui (not Unity)
 
 Engine-Specific Examples:
   gpm install --unity com.unity.textmeshpro     # Force Unity engine
@@ -143,8 +251,24 @@ Engine-Specific Examples:
 Registry Examples:
   gpm install --registry https://homa.gpm.sh homa-analytics
   gpm install --project-dir /path/to/project package-name
+  gpm install --projects unity-game,godot-game pkg.tgz  # Install into several projects, downloading once
+  gpm install --packages-dir UPM/Packages package-name  # Relocated Unity packages directory
+  gpm install --godot --side-by-side package@2.0.0  # Keep installed versions side-by-side
+  gpm install --dry-run --json package-name  # Preview the manifest changes as JSON
+  gpm install --json-stream pkg1 pkg2 pkg3  # One JSON line per package event, then a summary
+  gpm install --no-save package-name        # Try a package without recording it
+  gpm install --ci                          # Clean, lock-checked install for CI
+  gpm install --check                       # Report packages that drifted from their declared versions
+  gpm install --verify package-name         # Check the manifest afterwards, rolling back on problems
+  gpm install --audit-signatures package-name  # Check the registry's signature of each package
+
+Installed packages are saved to the engine manifest by default. With
+--no-save (or --save=false) the manifest is left alone: Godot addons are
+still extracted, while Unity packages, which Unity only loads through its
+manifest, are downloaded into a temporary directory for inspection.
 
 Advanced:
+  gpm install https://registry.gpm.sh/pkg/-/pkg-1.0.0.tgz#sha512-...  # Install a tarball URL, checking its integrity
   gpm install git+https://github.com/user/repo.git  # Install from Git
   gpm install file:../local-package                 # Install from local directory`,
 	RunE: install,
@@ -153,7 +277,8 @@ Advanced:
 func init() {
 	installCmd.Flags().BoolVarP(&installGlobal, "global", "g", false, "Install package globally")
 	installCmd.Flags().StringVar(&installVersion, "version", "", "Specific version to install")
-	installCmd.Flags().BoolVar(&installSave, "save", false, "Save to package.json dependencies")
+	installCmd.Flags().BoolVar(&installSave, "save", true, "Save installed packages to the engine manifest")
+	installCmd.Flags().BoolVar(&installNoSave, "no-save", false, "Install without writing the manifest (Unity: download into a temporary directory for inspection)")
 	installCmd.Flags().BoolVar(&installSaveDev, "save-dev", false, "Save to package.json devDependencies")
 
 	// Engine-specific flags
@@ -163,18 +288,198 @@ func init() {
 	installCmd.Flags().BoolVar(&installCocos, "cocos", false, "Force Cocos Creator engine adapter")
 
 	// Advanced options
-	installCmd.Flags().StringVar(&installProjectDir, "project-dir", "", "Project directory (default: current directory)")
+	installCmd.Flags().StringVar(&installProjectDir, "project-dir", "", "Project directory (default: current directory; same as the global --cwd)")
+	installCmd.Flags().BoolVar(&installCheck, "check", false, "Report packages whose installed version no longer matches package.json or their recorded range, changing nothing; exits non-zero on drift")
+	installCmd.Flags().BoolVar(&installCI, "ci", false, "Reproducible CI install: require the lockfile to match the manifest, never update it, and clear installed packages first")
+	installCmd.Flags().StringSliceVar(&installProjects, "projects", nil, "Install into each of these project directories, detecting each one's engine; tarballs are downloaded once")
 	installCmd.Flags().StringVar(&installRegistry, "registry", "", "Override registry URL for this installation")
+	installCmd.Flags().StringVar(&installPackagesDir, "packages-dir", "", "Unity packages directory relative to the project (default: $"+engines.UnityPackagesDirEnv+" or Packages)")
+	installCmd.Flags().BoolVar(&installSideBySide, "side-by-side", false, "Install alongside existing versions instead of replacing them (not supported by Unity)")
+	installCmd.Flags().BoolVar(&installEngineStrict, "engine-strict", false, "Fail instead of warning when a package's engines constraints are not met")
+	installCmd.Flags().BoolVar(&installStrict, "strict", false, "Fail instead of warning when an extracted Unity package reuses asset GUIDs from the project's Assets/, or --audit-signatures finds a package unsigned")
+	installCmd.Flags().BoolVar(&installAuditSignatures, "audit-signatures", false, "Verify each registry package's dist.signatures against the registry's signing keys, failing on an invalid signature")
+	installCmd.Flags().BoolVar(&installIfPresent, "if-present", false, "Succeed without installing when no package.json is found")
+	installCmd.Flags().BoolVar(&installDryRun, "dry-run", false, "Show the manifest changes without writing them")
+	installCmd.Flags().BoolVar(&installJSON, "json", false, "Output results in JSON format")
+	installCmd.Flags().BoolVar(&installJSONStream, "json-stream", false, "Stream newline-delimited JSON: one object per package event (started, resolved, downloaded, installed, failed), then a summary")
+	installCmd.Flags().BoolVar(&installForce, "force", false, "Move a package's scope to this registry when another scoped registry already claims it, replace a manifest.json Unity can't load, and accept a tarball whose package.json names another package or version than requested")
+	installCmd.Flags().BoolVar(&installNoScopedRegistry, "no-scoped-registry", false, "Only update dependencies; leave the Unity manifest's scopedRegistries alone (default: auto_scoped_registry config)")
+	installCmd.Flags().StringVar(&installBundle, "bundle", "", "Install every package in a bundle from "+BundleFile)
+	installCmd.Flags().BoolVar(&installNormalize, "normalize", false, "Rewrite the manifest with two-space indentation instead of keeping its existing style")
+	installCmd.Flags().StringVar(&installTag, "tag", "", "Dist-tag to resolve packages given without a version through (default: default_tag config or latest)")
+	installCmd.Flags().BoolVar(&installVerify, "verify", false, "Re-read the Unity manifest after installing and roll back if a dependency or scoped registry is wrong, or a tarball names another package")
+	installCmd.Flags().BoolVarP(&installVerbose, "verbose", "v", false, "Explain how package versions were resolved")
+	installCmd.Flags().IntVar(&installDownloadConcurrency, "download-concurrency", defaultDownloadConcurrency, "Tarballs to download at once when installing several")
+	installCmd.Flags().IntVar(&installExtractConcurrency, "extract-concurrency", defaultExtractConcurrency, "Downloaded tarballs to extract at once while the rest download")
+	installCmd.Flags().DurationVar(&installRegistryTimeout, "registry-timeout", 0, "Fail fast when the registry can't be reached within this time (default: registry_timeout config or 3s)")
 }
 
 func install(cmd *cobra.Command, args []string) error {
+	if installJSON && installJSONStream {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("--json and --json-stream can't be combined"),
+			styling.Hint("Use --json for one result object or --json-stream for a line per package event"))
+	}
+	if installDownloadConcurrency < 1 || installExtractConcurrency < 1 {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("--download-concurrency and --extract-concurrency must be at least 1"),
+			styling.Hint("Use 1 to download or extract one tarball at a time"))
+	}
+	if installJSONStream {
+		installEvents = newEventStream(os.Stdout)
+		defer func() { installEvents = nil }()
+	}
+
+	output := &InstallOutput{Packages: []string{}, DryRun: installDryRun, Bundle: installBundle}
+	defer installDownloads.clear()
+
+	if installCI {
+		return finishInstallOutput(output, ciInstall(args, output))
+	}
+	if installCheck {
+		return finishInstallOutput(output, checkInstall(args, output))
+	}
+
+	// A bundle expands into its members, installed alongside any other args
+	if installBundle != "" {
+		projectDir := installProjectDir
+		if projectDir == "" {
+			projectDir = "."
+		}
+		members, err := bundleSpecs(projectDir, installBundle)
+		if err != nil {
+			return err
+		}
+		args = append(members, args...)
+	}
+
 	// Handle no arguments - install from package.json
 	if len(args) == 0 {
-		return installFromPackageJSON()
+		return finishInstallOutput(output, installFromPackageJSON(output))
 	}
 
-	fmt.Println(styling.Header("📦  Multi-Engine Package Installation"))
-	fmt.Println(styling.Separator())
+	if installDryRun {
+		output.Diff = engines.NewManifestDiff()
+	}
+
+	err := installPackagesWithEngine(args, output)
+	if err == nil && installBundle != "" && !installDryRun {
+		installPrintf("%s %s (%d packages)\n", styling.Success("✓ Installed bundle"), styling.Value(installBundle), len(output.Packages))
+	}
+	return finishInstallOutput(output, err)
+}
+
+// finishInstallOutput prints the --json result or --json-stream summary of an
+// install and passes its error through
+func finishInstallOutput(output *InstallOutput, err error) error {
+	if installEvents != nil {
+		summary := StreamSummary{Success: err == nil, Packages: output.Packages, Warnings: output.Warnings}
+		if err != nil {
+			summary.Error = err.Error()
+		}
+		if streamErr := installEvents.summary(summary); streamErr != nil {
+			return fmt.Errorf("failed to write JSON stream: %w", streamErr)
+		}
+		return err
+	}
+	if !installJSON {
+		return err
+	}
+
+	output.Success = err == nil
+	if err != nil {
+		output.Error = err.Error()
+		errors.As(err, &output.Policy)
+	}
+	data, marshalErr := json.MarshalIndent(output, "", "  ")
+	if marshalErr != nil {
+		return fmt.Errorf("failed to marshal JSON output: %w", marshalErr)
+	}
+	fmt.Println(string(data))
+	return err
+}
+
+// installSkipsSave reports whether --no-save or --save=false was given
+func installSkipsSave() bool {
+	return installNoSave || !installSave
+}
+
+// ciInstall is install --ci, like npm ci for Unity projects: the manifest
+// must agree with Packages/packages-lock.json, which is never modified, and
+// Library/PackageCache is removed so Unity extracts exactly the locked
+// versions. Godot projects have no lockfile and are rejected.
+func ciInstall(args []string, output *InstallOutput) error {
+	if len(args) > 0 || installBundle != "" {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("--ci installs exactly what the lockfile records and takes no packages"),
+			styling.Hint("Add packages with 'gpm install <package>' and commit the updated lockfile"))
+	}
+
+	projectDir := installProjectDir
+	if projectDir == "" {
+		projectDir = "."
+	}
+	engineType, _, err := determineEngineType(projectDir)
+	if err != nil {
+		return fmt.Errorf("engine detection failed: %w", err)
+	}
+	if engineType != engines.EngineUnity {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("--ci needs a lockfile, which %s projects don't have", engineType.String())),
+			styling.Hint("Use 'gpm install' instead"))
+	}
+	adapter := engines.NewUnityAdapter()
+	adapter.SetPackagesDir(installPackagesDir)
+	if err := adapter.ValidateProject(projectDir); err != nil {
+		return fmt.Errorf("project validation failed: %w", err)
+	}
+	output.Engine = string(engineType)
+	output.Project = projectDir
+
+	mismatches, err := adapter.LockMismatches(projectDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("--ci requires "+engines.UnityPackagesLockFile+" next to manifest.json"),
+			styling.Hint("Open the project in Unity to generate it, then commit it"))
+	}
+	if err != nil {
+		return err
+	}
+	if len(mismatches) > 0 {
+		output.Warnings = append(output.Warnings, mismatches...)
+		for _, mismatch := range mismatches {
+			installPrintf("%s %s\n", styling.Error("✗"), mismatch)
+		}
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("manifest.json and %s disagree on %d package(s)", engines.UnityPackagesLockFile, len(mismatches))),
+			styling.Hint("Open the project in Unity to update the lockfile, then commit both files"))
+	}
+
+	if installDryRun {
+		installPrintf("%s %s\n", styling.Label("Would remove:"), styling.File(engines.UnityPackageCacheDir))
+		return nil
+	}
+	if err := adapter.CleanPackageCache(projectDir); err != nil {
+		return err
+	}
+	installPrintf("%s\n", styling.Success("✓ manifest.json matches "+engines.UnityPackagesLockFile))
+	installPrintf("%s\n", styling.Muted("Removed "+engines.UnityPackageCacheDir+"; Unity installs the locked versions when it next opens the project"))
+	return nil
+}
+
+// installPrintf prints human-readable install progress; --json and
+// --json-stream suppress it
+func installPrintf(format string, a ...any) {
+	if !installJSON && !installJSONStream {
+		fmt.Printf(format, a...)
+	}
+}
+
+// installPackagesWithEngine installs package specs into the detected project,
+// recording the outcome in output
+func installPackagesWithEngine(args []string, output *InstallOutput) error {
+	installPrintf("%s\n", styling.Header("📦  Multi-Engine Package Installation"))
+	installPrintf("%s\n", styling.Separator())
 
 	// Global installation not supported yet
 	if installGlobal {
@@ -183,16 +488,66 @@ func install(cmd *cobra.Command, args []string) error {
 			styling.Hint("Use engine-specific local installation instead"))
 	}
 
-	// Determine project directory
-	projectDir := installProjectDir
-	if projectDir == "" {
-		var err error
-		projectDir, err = os.Getwd()
-		if err != nil {
-			return fmt.Errorf("failed to get current directory: %w", err)
+	if len(installProjects) > 0 {
+		if installProjectDir != "" {
+			return fmt.Errorf("%s\n\n%s",
+				styling.Error("--project-dir and --projects can't be combined"),
+				styling.Hint("List every project directory in --projects"))
+		}
+		output.Projects = make(map[string]string)
+		for _, projectDir := range installProjects {
+			projectOutput := &InstallOutput{Packages: []string{}, Diff: output.Diff}
+			err := installIntoProject(projectDir, args, projectOutput)
+			output.Packages = append(output.Packages, projectOutput.Packages...)
+			output.Warnings = append(output.Warnings, projectOutput.Warnings...)
+			for spec, dir := range projectOutput.Inspect {
+				if output.Inspect == nil {
+					output.Inspect = make(map[string]string)
+				}
+				output.Inspect[spec] = dir
+			}
+			if projectOutput.Engine != "" {
+				output.Projects[projectDir] = projectOutput.Engine
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", projectDir, err)
+			}
+			installPrintf("%s\n", styling.Separator())
+		}
+	} else {
+		projectDir := installProjectDir
+		if projectDir == "" {
+			var err error
+			projectDir, err = os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+		}
+		if err := installIntoProject(projectDir, args, output); err != nil {
+			return err
 		}
 	}
 
+	if installDryRun {
+		installPrintf("%s\n", styling.Header("🧪 Dry Run - Manifest Changes"))
+		installPrintf("%s\n", styling.Label("Dependencies:"))
+		for _, line := range manifestDiffLines(output.Diff) {
+			installPrintf("%s\n", line)
+		}
+		installPrintf("%s\n", styling.Label("Scoped Registries:"))
+		for _, line := range scopedRegistryLines(output.Diff) {
+			installPrintf("%s\n", line)
+		}
+		return nil
+	}
+
+	installPrintf("%s\n", styling.Success("✓ All packages installed successfully!"))
+	return nil
+}
+
+// installIntoProject detects the engine of one project and installs every
+// package spec into it
+func installIntoProject(projectDir string, args []string, output *InstallOutput) error {
 	// Detect or determine engine type
 	engineType, detectionResult, err := determineEngineType(projectDir)
 	if err != nil {
@@ -201,13 +556,13 @@ func install(cmd *cobra.Command, args []string) error {
 
 	// Show detection results
 	if detectionResult != nil {
-		fmt.Printf("%s %s\n", styling.Label("Detected Engine:"), styling.Value(detectionResult.Engine.String()))
-		fmt.Printf("%s %s\n", styling.Label("Confidence:"), styling.Value(detectionResult.Confidence.String()))
+		installPrintf("%s %s\n", styling.Label("Detected Engine:"), styling.Value(detectionResult.Engine.String()))
+		installPrintf("%s %s\n", styling.Label("Confidence:"), styling.Value(detectionResult.Confidence.String()))
 		if detectionResult.Version != "" {
-			fmt.Printf("%s %s\n", styling.Label("Version:"), styling.Value(detectionResult.Version))
+			installPrintf("%s %s\n", styling.Label("Version:"), styling.Value(detectionResult.Version))
 		}
-		fmt.Printf("%s %s\n", styling.Label("Project Path:"), styling.File(detectionResult.ProjectPath))
-		fmt.Println(styling.Separator())
+		installPrintf("%s %s\n", styling.Label("Project Path:"), styling.File(detectionResult.ProjectPath))
+		installPrintf("%s\n", styling.Separator())
 	}
 
 	// Get engine adapter
@@ -215,11 +570,35 @@ func install(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to get engine adapter: %w", err)
 	}
+	if unityAdapter, ok := adapter.(*engines.UnityAdapter); ok {
+		unityAdapter.SetPackagesDir(installPackagesDir)
+		unityAdapter.SetStateDir(projectStateDir(projectDir))
+	}
 
 	// Validate project
 	if err := adapter.ValidateProject(projectDir); err != nil {
 		return fmt.Errorf("project validation failed: %w", err)
 	}
+	output.Engine = string(engineType)
+	output.Project = projectDir
+
+	// Download and extract tarballs ahead of the loop, which then only has
+	// to move them into place and write the manifest
+	var tarballURLs []string
+	for _, specStr := range args {
+		if spec := parsePackageSpec(specStr); spec.Source == "tarball" {
+			tarballURLs = append(tarballURLs, spec.URL)
+		}
+	}
+	if len(tarballURLs) > 1 {
+		registryURL := config.ResolveRegistry(installRegistry, projectDir).Value
+		extract := !installDryRun && !installSkipsSave()
+		installPrefetch = startInstallPipeline(commandCtx, tarballURLs, registryURL, installDownloadConcurrency, installExtractConcurrency, extract)
+		defer func() {
+			installPrefetch.stop()
+			installPrefetch = nil
+		}()
+	}
 
 	// Install each package
 	for _, specStr := range args {
@@ -231,12 +610,12 @@ func install(cmd *cobra.Command, args []string) error {
 		}
 
 		// Install package using engine adapter
-		if err := installPackageWithEngine(adapter, projectDir, spec); err != nil {
-			return fmt.Errorf("failed to install %s: %w", spec.Name, err)
+		installEvents.emit(StreamEvent{Event: EventStarted, Package: specStr, Project: projectDir})
+		if err := installPackageWithEngine(adapter, projectDir, spec, output); err != nil {
+			installEvents.fail(specStr, projectDir, err)
+			return fmt.Errorf("failed to install %s: %w", specStr, err)
 		}
 	}
-
-	fmt.Println(styling.Success("✓ All packages installed successfully!"))
 	return nil
 }
 
@@ -264,12 +643,12 @@ func determineEngineType(projectDir string) (engines.EngineType, *engines.Detect
 
 	// If engine explicitly specified, return it
 	if flagCount == 1 {
-		fmt.Printf("%s %s\n", styling.Label("Forced Engine:"), styling.Value(selectedEngine.String()))
+		installPrintf("%s %s\n", styling.Label("Forced Engine:"), styling.Value(selectedEngine.String()))
 		return selectedEngine, nil, nil
 	}
 
 	// Auto-detect engine
-	fmt.Printf("%s %s\n", styling.Label("Auto-detecting engine in:"), styling.File(projectDir))
+	installPrintf("%s %s\n", styling.Label("Auto-detecting engine in:"), styling.File(projectDir))
 
 	results, err := engines.DetectEngine(projectDir)
 	if err != nil {
@@ -290,10 +669,10 @@ func determineEngineType(projectDir string) (engines.EngineType, *engines.Detect
 
 	// Handle ambiguous detection
 	if results.HasAmbiguous() {
-		fmt.Println(styling.Warning("⚠ Multiple engines detected:"))
+		installPrintf("%s\n", styling.Warning("⚠ Multiple engines detected:"))
 		for i, result := range results {
 			if result.Confidence >= engines.ConfidenceHigh {
-				fmt.Printf("  %d. %s (%s confidence)\n", i+1, result.Engine.String(), result.Confidence.String())
+				installPrintf("  %d. %s (%s confidence)\n", i+1, result.Engine.String(), result.Confidence.String())
 			}
 		}
 		return engines.EngineUnknown, nil, fmt.Errorf("%s\n\n%s",
@@ -313,62 +692,238 @@ func determineEngineType(projectDir string) (engines.EngineType, *engines.Detect
 }
 
 // installPackageWithEngine installs a package using the appropriate engine adapter
-func installPackageWithEngine(adapter engines.EngineAdapter, projectDir string, spec PackageSpec) error {
+func installPackageWithEngine(adapter engines.EngineAdapter, projectDir string, spec PackageSpec, output *InstallOutput) error {
+	if spec.Alias != "" {
+		if spec.Source != "registry" {
+			return fmt.Errorf("an npm: alias must name a registry package, not a %s", spec.Source)
+		}
+		if err := validation.ValidatePackageName(spec.Alias); err != nil {
+			return fmt.Errorf("invalid alias: %w", err)
+		}
+	}
+
 	switch spec.Source {
 	case "registry":
-		return installFromRegistryWithEngine(adapter, projectDir, spec)
+		return installFromRegistryWithEngine(adapter, projectDir, spec, output)
 	case "git":
 		return installFromGitWithEngine(spec)
 	case "file":
 		return installFromFileWithEngine(spec)
+	case "tarball":
+		return installFromTarballWithEngine(adapter, projectDir, spec, output)
 	default:
 		return fmt.Errorf("unsupported package source: %s", spec.Source)
 	}
 }
 
 // installFromRegistryWithEngine installs a package from registry using engine adapter
-func installFromRegistryWithEngine(adapter engines.EngineAdapter, projectDir string, spec PackageSpec) error {
-	fmt.Printf("%s %s@%s\n", styling.Label("Installing:"), styling.Package(spec.Name), styling.Version(spec.Version))
-
-	// Use default or override registry
-	registryURL := "https://registry.gpm.sh" // Default GPM registry
-	if installRegistry != "" {
-		registryURL = installRegistry
-		fmt.Printf("%s %s\n", styling.Label("Registry (override):"), styling.URL(installRegistry))
-	} else {
-		fmt.Printf("%s %s\n", styling.Label("Registry:"), styling.URL(registryURL))
+func installFromRegistryWithEngine(adapter engines.EngineAdapter, projectDir string, spec PackageSpec, output *InstallOutput) error {
+	installPrintf("%s %s@%s\n", styling.Label("Installing:"), styling.Package(spec.Name), styling.Version(spec.Version))
+	if err := config.CheckPackagePolicy(spec.Name); err != nil {
+		return err
 	}
 
-	// Resolve version if it's "latest" or "*"
+	// Use the override, the project's registry, or the configured one
+	resolved := config.ResolveRegistry(installRegistry, projectDir)
+	switch resolved.Source {
+	case config.SourceFlag:
+		installPrintf("%s %s\n", styling.Label("Registry (override):"), styling.URL(resolved.Value))
+	case config.SourceDefault:
+		installPrintf("%s %s\n", styling.Label("Registry:"), styling.URL(resolved.Value))
+	default:
+		installPrintf("%s %s %s\n", styling.Label("Registry:"), styling.URL(resolved.Value), styling.Muted("(from "+resolved.Source+")"))
+	}
+
+	// Fail fast when the registry is down instead of waiting out the client
+	// timeout; with fallbacks, the first registry that has the package wins
+	chain := registryChain(resolved, spec.Name, projectDir)
+	registryURL, err := findPackageRegistry(chain, resolved, projectDir, spec.Name, installRegistryTimeout)
+	if err != nil {
+		return err
+	}
+	token := registryToken(registryURL, resolved, projectDir)
+	if len(chain) > 1 {
+		if registryURL != resolved.Value {
+			installPrintf("%s %s\n", styling.Label("Registry (fallback):"), styling.URL(registryURL))
+		}
+		if output.Registries == nil {
+			output.Registries = make(map[string]string)
+		}
+		output.Registries[spec.Name] = registryURL
+	}
+
+	// Resolve version through the default dist-tag, if it's "latest" or "*",
+	// or if it's a range Unity couldn't resolve itself
 	resolvedVersion := spec.Version
-	if spec.Version == "latest" || spec.Version == "*" {
-		actualVersion, err := resolveLatestVersionFromRegistry(spec.Name, registryURL)
+	origin := engines.PackageOrigin{Registry: registryURL}
+	if tag := defaultDistTag(installTag); spec.Unversioned && tag != "" && tag != "latest" {
+		resolution, err := api.NewClient(registryURL, token).WithContext(commandCtx).ResolveTaggedVersion(spec.Name, tag)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s version: %w", tag, err)
+		}
+		if resolution.MissingTag != "" {
+			warning := fmt.Sprintf("%s has no %q dist-tag; using latest (%s)", spec.Name, resolution.MissingTag, resolution.Version)
+			installPrintf("%s\n", styling.Warning("⚠ "+warning))
+			output.Warnings = append(output.Warnings, warning)
+			tag = "latest"
+		}
+		resolvedVersion = resolution.Version
+		origin = resolutionOrigin(registryURL, resolution)
+		installPrintf("%s %s@%s (resolved from %s)\n", styling.Label("Resolved:"), styling.Package(spec.Name), styling.Version(resolvedVersion), styling.Version(tag))
+	} else if spec.Version == "latest" || spec.Version == "*" {
+		actualVersion, err := resolveInstallLatest(api.NewClient(registryURL, token).WithContext(commandCtx), spec.Name, output)
 		if err != nil {
 			return fmt.Errorf("failed to resolve latest version: %w", err)
 		}
 		resolvedVersion = actualVersion
-		fmt.Printf("%s %s@%s (resolved from %s)\n", styling.Label("Resolved:"), styling.Package(spec.Name), styling.Version(resolvedVersion), styling.Version(spec.Version))
+		if spec.Version == "*" {
+			origin.Range = spec.Version
+		} else {
+			origin.Tag = spec.Version
+		}
+		installPrintf("%s %s@%s (resolved from %s)\n", styling.Label("Resolved:"), styling.Package(spec.Name), styling.Version(resolvedVersion), styling.Version(spec.Version))
+	} else if isVersionRange(spec.Version) {
+		resolution, err := api.NewClient(registryURL, token).WithContext(commandCtx).ResolveVersion(spec.Name, spec.Version)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", spec.Version, err)
+		}
+		resolvedVersion = resolution.Version
+		origin = resolutionOrigin(registryURL, resolution)
+		installPrintf("%s %s@%s (resolved from %s)\n", styling.Label("Resolved:"), styling.Package(spec.Name), styling.Version(resolvedVersion), styling.Version(spec.Version))
+	}
+	installEvents.emit(StreamEvent{Event: EventResolved, Package: spec.Name, Version: resolvedVersion, Project: projectDir})
+
+	// Check the package's declared engines against this project
+	client := api.NewClient(registryURL, token).WithContext(commandCtx)
+	metadata, metadataErr := client.GetAbbreviatedMetadata(spec.Name)
+	if metadataErr == nil {
+		warnings, err := checkEngineCompatibility(metadata.Versions[resolvedVersion], compatibilityEnvironment(projectDir, adapter.GetEngineType()), installEngineStrict)
+		if err != nil {
+			return err
+		}
+		for _, warning := range warnings {
+			installPrintf("%s\n", styling.Warning("⚠ "+warning))
+		}
+		output.Warnings = append(output.Warnings, warnings...)
+	}
+
+	if installAuditSignatures {
+		if metadataErr != nil {
+			return fmt.Errorf("failed to fetch package metadata: %w", metadataErr)
+		}
+		warning, err := auditSignature(client, registryURL, spec.Name, resolvedVersion, metadata, installStrict)
+		if err != nil {
+			return err
+		}
+		if warning != "" {
+			installPrintf("%s\n", styling.Warning("⚠ "+warning))
+			output.Warnings = append(output.Warnings, warning)
+		} else {
+			installPrintf("%s %s\n", styling.Label("Signature:"), styling.Success("verified by the registry's signing key"))
+		}
+	}
+
+	if installSkipsSave() && adapter.GetEngineType() == engines.EngineUnity {
+		if err := downloadForInspection(client, spec.Name, resolvedVersion, registryURL, output); err != nil {
+			return err
+		}
+		installEvents.emit(StreamEvent{Event: EventDownloaded, Package: spec.Name, Version: resolvedVersion, Project: projectDir})
+		return nil
 	}
 
 	// Create install request
 	req := &engines.PackageInstallRequest{
-		Name:     spec.Name,
-		Version:  resolvedVersion,
-		Registry: registryURL,
-		IsDev:    installSaveDev,
+		Name:             spec.Name,
+		Version:          resolvedVersion,
+		Registry:         registryURL,
+		IsDev:            installSaveDev,
+		SideBySide:       installSideBySide,
+		DryRun:           installDryRun,
+		Force:            installForce,
+		Normalize:        installNormalize,
+		NoScopedRegistry: installNoScopedRegistry || !config.AutoScopedRegistryEnabled(),
+		NoSave:           installSkipsSave(),
+		Alias:            spec.Alias,
+	}
+	if req.NoScopedRegistry && adapter.GetEngineType() == engines.EngineUnity {
+		warning := scopedRegistrySkippedWarning(spec.Name, registryURL)
+		installPrintf("%s\n", styling.Warning("⚠ "+warning))
+		output.Warnings = append(output.Warnings, warning)
+	}
+
+	// Unity fetches registry packages itself; other engines get the files
+	// from gpm, so the tarball is downloaded before the manifest changes
+	var tarballPath string
+	if adapter.GetEngineType() != engines.EngineUnity && !installDryRun {
+		tarballURL, err := versionTarballURL(client, spec.Name, resolvedVersion)
+		if err != nil {
+			return err
+		}
+		if tarballPath, err = downloadVersionTarball(tarballURL, spec.Name, resolvedVersion, registryURL, output); err != nil {
+			return err
+		}
+		installEvents.emit(StreamEvent{Event: EventDownloaded, Package: spec.Name, Version: resolvedVersion, Project: projectDir})
+
+		stagingDir, err := packageStagingDir(adapter, projectDir)
+		if err != nil {
+			return err
+		}
+		contentsDir, cleanup, err := stagePackage(tarballPath, stagingDir)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		req.ContentsDir = contentsDir
+	}
+
+	backupPath, err := verifyBackup(adapter, projectDir)
+	if err != nil {
+		return err
 	}
 
 	// Install package
 	result, err := adapter.InstallPackage(projectDir, req)
 	if err != nil {
+		if readOnlyErr := readOnlyProjectError(err); readOnlyErr != nil {
+			return readOnlyErr
+		}
 		return fmt.Errorf("installation failed: %w", err)
 	}
+	if tarballPath != "" && result.Success {
+		installDownloads.use(projectDir, tarballPath)
+	}
+	if backupPath != "" && result.Success {
+		if err := verifyInstall(adapter, projectDir, req, result); err != nil {
+			return undoVerifiedInstall(err, backupPath, projectDir, adapter.GetEngineType(), installPackagesDir, "")
+		}
+	}
+
+	for _, warning := range result.Warnings {
+		installPrintf("%s\n", styling.Warning("⚠ "+warning))
+	}
+	output.Warnings = append(output.Warnings, result.Warnings...)
 
 	if result.Success {
-		fmt.Printf("%s %s\n", styling.Success("✓"), result.Message)
+		installed := spec.Name + "@" + resolvedVersion
+		if spec.Alias != "" {
+			installed = spec.Alias + npmAliasSeparator + installed
+		}
+		output.Packages = append(output.Packages, installed)
+		if installDryRun && result.Diff != nil {
+			output.Diff.Merge(result.Diff)
+		}
+		if !installDryRun && !req.NoSave {
+			origin.Version = resolvedVersion
+			if warning := recordPackageOrigin(adapter, projectDir, req.DependencyKey(), origin); warning != "" {
+				installPrintf("%s\n", styling.Warning("⚠ "+warning))
+				output.Warnings = append(output.Warnings, warning)
+			}
+		}
+		installEvents.emit(StreamEvent{Event: EventInstalled, Package: spec.Name, Version: resolvedVersion, Project: projectDir})
+		installPrintf("%s %s\n", styling.Success("✓"), result.Message)
 		if result.Details != nil {
 			for key, value := range result.Details {
-				fmt.Printf("%s %v\n", styling.Label(fmt.Sprintf("  %s:", key)), value)
+				installPrintf("%s %v\n", styling.Label(fmt.Sprintf("  %s:", key)), value)
 			}
 		}
 	} else {
@@ -378,6 +933,172 @@ func installFromRegistryWithEngine(adapter engines.EngineAdapter, projectDir str
 	return nil
 }
 
+// installFromTarballWithEngine installs a package straight from a tarball URL
+func installFromTarballWithEngine(adapter engines.EngineAdapter, projectDir string, spec PackageSpec, output *InstallOutput) error {
+	installPrintf("%s %s\n", styling.Label("Installing:"), styling.URL(spec.URL))
+
+	tarballPath, err := fetchInstallTarball(projectDir, spec.URL)
+	if err != nil {
+		return err
+	}
+	installEvents.emit(StreamEvent{Event: EventDownloaded, Package: spec.URL, Project: projectDir})
+
+	if installSkipsSave() && adapter.GetEngineType() == engines.EngineUnity {
+		info, err := packaging.ExtractPackageInfo(tarballPath)
+		if err != nil {
+			return fmt.Errorf("failed to read package.json from tarball: %w", err)
+		}
+		return inspectTarball(tarballPath, info.Name, info.Version, output)
+	}
+
+	if installVerify {
+		info, err := packaging.ExtractPackageInfo(tarballPath)
+		if err != nil {
+			return fmt.Errorf("failed to read package.json from tarball: %w", err)
+		}
+		if err := checkTarballName(spec.URL, info.Name); err != nil {
+			return err
+		}
+	}
+	backupPath, err := verifyBackup(adapter, projectDir)
+	if err != nil {
+		return err
+	}
+
+	req := &engines.PackageInstallRequest{
+		IsDev:      installSaveDev,
+		SideBySide: installSideBySide,
+		DryRun:     installDryRun,
+		Force:      installForce,
+		Normalize:  installNormalize,
+		NoSave:     installSkipsSave(),
+	}
+	result, err := installTarball(adapter, projectDir, tarballPath, req, installStrict)
+	if err != nil {
+		return err
+	}
+	if backupPath != "" {
+		if err := verifyInstall(adapter, projectDir, req, result); err != nil {
+			return undoVerifiedInstall(err, backupPath, projectDir, adapter.GetEngineType(), installPackagesDir, embeddedPackageDir(adapter, result))
+		}
+	}
+
+	for _, warning := range result.Warnings {
+		installPrintf("%s\n", styling.Warning("⚠ "+warning))
+	}
+	output.Warnings = append(output.Warnings, result.Warnings...)
+	output.Packages = append(output.Packages, result.PackageName+"@"+result.Version)
+	if installDryRun && result.Diff != nil {
+		output.Diff.Merge(result.Diff)
+	}
+	if !installDryRun {
+		installDownloads.use(projectDir, tarballPath)
+	}
+	installEvents.emit(StreamEvent{Event: EventInstalled, Package: result.PackageName, Version: result.Version, Project: projectDir})
+	installPrintf("%s %s\n", styling.Success("✓"), result.Message)
+	return nil
+}
+
+// fetchInstallTarball returns the downloaded tarball at tarballURL, waiting
+// for the install pipeline when it is fetching it
+func fetchInstallTarball(projectDir, tarballURL string) (string, error) {
+	if installPrefetch != nil {
+		if tarballPath, ok, err := installPrefetch.wait(commandCtx, tarballURL); ok {
+			return tarballPath, err
+		}
+	}
+	registryURL := config.ResolveRegistry(installRegistry, projectDir).Value
+	return installDownloads.fetch(commandCtx, tarballURL, registryURL)
+}
+
+// downloadForInspection fetches a package version's tarball and extracts it
+// into a temporary directory instead of installing it. Unity only loads
+// packages listed in its manifest, so this is what --no-save means there.
+func downloadForInspection(client *api.Client, packageName, version, registryURL string, output *InstallOutput) error {
+	tarballURL, err := versionTarballURL(client, packageName, version)
+	if err != nil {
+		return err
+	}
+	if installDryRun {
+		installPrintf("%s %s@%s\n", styling.Label("Would download for inspection:"), styling.Package(packageName), styling.Version(version))
+		return nil
+	}
+
+	tarballPath, err := downloadVersionTarball(tarballURL, packageName, version, registryURL, output)
+	if err != nil {
+		return err
+	}
+	return inspectTarball(tarballPath, packageName, version, output)
+}
+
+// versionTarballURL returns the tarball URL of a package version, with the
+// registry's integrity as its fragment so the download is checked against it
+func versionTarballURL(client *api.Client, packageName, version string) (string, error) {
+	metadata, err := client.GetAbbreviatedMetadata(packageName)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch package metadata: %w", err)
+	}
+	versionInfo := metadata.Versions[version]
+	if versionInfo == nil || versionInfo.Dist == nil || versionInfo.Dist.Tarball == "" {
+		return "", fmt.Errorf("no tarball found for %s@%s", packageName, version)
+	}
+	tarballURL := versionInfo.Dist.Tarball
+	if versionInfo.Dist.Integrity != "" {
+		tarballURL += "#" + versionInfo.Dist.Integrity
+	}
+	return tarballURL, nil
+}
+
+// downloadVersionTarball downloads a package version's tarball and checks
+// that its package.json is the package that was asked for
+func downloadVersionTarball(tarballURL, packageName, version, registryURL string, output *InstallOutput) (string, error) {
+	tarballPath, err := installDownloads.fetch(commandCtx, tarballURL, registryURL)
+	if err != nil {
+		return "", err
+	}
+	info, err := packaging.ExtractPackageInfo(tarballPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read package.json from tarball: %w", err)
+	}
+	warning, err := checkPackageIdentity(packageName, version, info, installForce)
+	if err != nil {
+		return "", err
+	}
+	if warning != "" {
+		installPrintf("%s\n", styling.Warning("⚠ "+warning))
+		output.Warnings = append(output.Warnings, warning)
+	}
+	return tarballPath, nil
+}
+
+// inspectTarball extracts a downloaded tarball into a new temporary directory
+// and records it in output, leaving the project untouched
+func inspectTarball(tarballPath, packageName, version string, output *InstallOutput) error {
+	dir, err := os.MkdirTemp("", "gpm-"+packageName+"-"+version+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create inspection directory: %w", err)
+	}
+
+	file, err := os.Open(tarballPath) // #nosec G304 - temporary file written by fetchTarball
+	if err != nil {
+		return fmt.Errorf("failed to open tarball: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	if err := extractPackageTarball(file, dir); err != nil {
+		return err
+	}
+
+	spec := packageName + "@" + version
+	output.Packages = append(output.Packages, spec)
+	if output.Inspect == nil {
+		output.Inspect = make(map[string]string)
+	}
+	output.Inspect[spec] = dir
+	installPrintf("%s %s %s\n", styling.Success("✓ Downloaded"), styling.Package(spec), styling.Muted("(manifest not changed)"))
+	installPrintf("%s %s\n", styling.Label("  Inspect at:"), styling.File(dir))
+	return nil
+}
+
 // installFromGitWithEngine installs a package from git using engine adapter (placeholder)
 func installFromGitWithEngine(spec PackageSpec) error {
 	return fmt.Errorf("git installation with engine adapters not yet implemented")
@@ -391,10 +1112,28 @@ func installFromFileWithEngine(spec PackageSpec) error {
 type PackageSpec struct {
 	Name     string
 	Version  string
-	Source   string // "registry", "git", "file"
+	Source   string // "registry", "git", "file", "tarball"
 	URL      string
 	Branch   string
 	FilePath string
+	Alias    string // dependency key to record the package under, if not Name
+	// Unversioned is set when no version was given, so the default
+	// dist-tag applies
+	Unversioned bool
+}
+
+// npmAliasSeparator separates an alias from the package it stands for, as in
+// "local-name@npm:com.vendor.pkg@1.0.0"
+const npmAliasSeparator = "@npm:"
+
+// splitAliasSpec splits an alias spec into the alias and the spec of the
+// aliased package. Specs without an alias come back unchanged.
+func splitAliasSpec(spec string) (string, string) {
+	alias, target, ok := strings.Cut(spec, npmAliasSeparator)
+	if !ok || alias == "" {
+		return "", spec
+	}
+	return alias, target
 }
 
 func parsePackageSpec(spec string) PackageSpec {
@@ -406,6 +1145,16 @@ func parsePackageSpec(spec string) PackageSpec {
 		return parseFileSpec(spec)
 	}
 
+	if isTarballURL(spec) {
+		return PackageSpec{Source: "tarball", URL: spec}
+	}
+
+	if alias, target := splitAliasSpec(spec); alias != "" {
+		parsed := parsePackageSpec(target)
+		parsed.Alias = alias
+		return parsed
+	}
+
 	if strings.Contains(spec, "@") {
 		parts := strings.Split(spec, "@")
 		version := parts[1]
@@ -421,9 +1170,10 @@ func parsePackageSpec(spec string) PackageSpec {
 	}
 
 	return PackageSpec{
-		Name:    spec,
-		Version: "latest",
-		Source:  "registry",
+		Name:        spec,
+		Version:     "latest",
+		Source:      "registry",
+		Unversioned: true,
 	}
 }
 
@@ -475,9 +1225,13 @@ func extractPackageNameFromGit(gitURL string) string {
 	return "unknown-package"
 }
 
-func installFromPackageJSON() error {
+func installFromPackageJSON(output *InstallOutput) error {
 	packageJSONPath := "package.json"
 	if _, err := os.Stat(packageJSONPath); os.IsNotExist(err) {
+		if installIfPresent {
+			printIfPresentSkip("install", ".")
+			return nil
+		}
 		return fmt.Errorf("%s\n\n%s",
 			styling.Error("No package.json found in current directory"),
 			styling.Hint("Run 'npm init' or create a package.json file first, or specify a package name to install"))
@@ -497,34 +1251,88 @@ func installFromPackageJSON() error {
 		return fmt.Errorf("invalid package.json: %w", err)
 	}
 
-	fmt.Println(styling.Info("Installing dependencies from package.json..."))
+	installPrintf("%s\n", styling.Info("Installing dependencies from package.json..."))
 
 	// Install production dependencies
 	for name, version := range pkg.Dependencies {
+		if strings.HasPrefix(version, packaging.WorkspaceProtocol) {
+			if err := linkWorkspacePackage(name); err != nil {
+				return err
+			}
+			continue
+		}
 		// Handle "*" as a wildcard for latest version
 		if version == "*" {
 			version = "latest"
 		}
-		if err := downloadAndInstallPackage(name, version, false); err != nil {
-			fmt.Printf("%s %s@%s\n", styling.Error("✗ Failed to install"), name, version)
+		if err := downloadAndInstallPackage(name, version, false, output); err != nil {
+			installPrintf("%s %s@%s\n", styling.Error("✗ Failed to install"), name, version)
 			return err
 		}
-		fmt.Printf("%s %s@%s\n", styling.Success("✓ Installed"), name, version)
+		output.Packages = append(output.Packages, name+"@"+version)
+		installPrintf("%s %s@%s\n", styling.Success("✓ Installed"), name, version)
 	}
 
 	// Install dev dependencies
 	for name, version := range pkg.DevDependencies {
+		if strings.HasPrefix(version, packaging.WorkspaceProtocol) {
+			if err := linkWorkspacePackage(name); err != nil {
+				return err
+			}
+			continue
+		}
 		// Handle "*" as a wildcard for latest version
 		if version == "*" {
 			version = "latest"
 		}
-		if err := downloadAndInstallPackage(name, version, true); err != nil {
-			fmt.Printf("%s %s@%s (dev)\n", styling.Error("✗ Failed to install"), name, version)
+		if err := downloadAndInstallPackage(name, version, true, output); err != nil {
+			installPrintf("%s %s@%s (dev)\n", styling.Error("✗ Failed to install"), name, version)
 			return err
 		}
-		fmt.Printf("%s %s@%s (dev)\n", styling.Success("✓ Installed"), name, version)
+		output.Packages = append(output.Packages, name+"@"+version)
+		installPrintf("%s %s@%s (dev)\n", styling.Success("✓ Installed"), name, version)
+	}
+
+	return nil
+}
+
+// linkWorkspacePackage points the Unity manifest at a sibling workspace
+// package's folder instead of downloading a published copy, so edits to the
+// sibling show up in the project straight away
+func linkWorkspacePackage(name string) error {
+	workspace, err := packaging.FindWorkspace(".")
+	if err != nil {
+		return err
+	}
+	if workspace == nil {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("%s uses the %s protocol, but this project is not inside a workspace", name, packaging.WorkspaceProtocol)),
+			styling.Hint("List the package's folder in \"workspaces\" in the root package.json"))
+	}
+	member, ok := workspace.Packages[name]
+	if !ok {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("No package in the workspace at %s is named %s", workspace.Root, name)),
+			styling.Hint("Check the package's name and the \"workspaces\" globs in the root package.json"))
 	}
 
+	packagesDir, err := unityPackagesDir()
+	if err != nil {
+		return err
+	}
+	absPackagesDir, err := filepath.Abs(packagesDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve packages directory: %w", err)
+	}
+	// Unity resolves file: references relative to the packages directory
+	relative, err := filepath.Rel(absPackagesDir, member.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to link %s: %w", name, err)
+	}
+	if err := setUnityManifestDependency(name, "file:"+filepath.ToSlash(relative)); err != nil {
+		return err
+	}
+	installPrintf("%s %s → %s\n", styling.Success("✓ Linked"), name, styling.File(member.Dir))
 	return nil
 }
 
@@ -549,7 +1357,7 @@ func installFromRegistry(spec PackageSpec) error {
 		styling.Package(spec.Name),
 		styling.Version(spec.Version))
 
-	if err := downloadAndInstallPackage(spec.Name, spec.Version, installSaveDev); err != nil {
+	if err := downloadAndInstallPackage(spec.Name, spec.Version, installSaveDev, &InstallOutput{}); err != nil {
 		return err
 	}
 
@@ -613,107 +1421,182 @@ func installFromFile(spec PackageSpec) error {
 	return nil
 }
 
-func downloadAndInstallPackage(packageName, version string, isDev bool) error {
+func downloadAndInstallPackage(packageName, version string, isDev bool, output *InstallOutput) error {
+	if err := config.CheckPackagePolicy(packageName); err != nil {
+		return err
+	}
 	cfg := config.GetConfig()
 
 	// Create Packages directory if it doesn't exist
-	packagesDir := "Packages"
+	packagesDir, err := unityPackagesDir()
+	if err != nil {
+		return err
+	}
+	if err := checkManifestWritable(filepath.Join(packagesDir, "manifest.json")); err != nil {
+		return err
+	}
 	if err := os.MkdirAll(packagesDir, 0750); err != nil {
 		return fmt.Errorf("failed to create Packages directory: %w", err)
 	}
 
-	// Download package metadata to get tarball URL
-	baseURL, err := url.Parse(cfg.Registry)
-	if err != nil {
-		return fmt.Errorf("invalid registry URL: %w", err)
-	}
-	packageURL := baseURL.JoinPath(packageName).String()
-	// #nosec G107 - URL is validated using url.Parse and JoinPath above
-	resp, err := http.Get(packageURL)
-	if err != nil {
-		return fmt.Errorf("failed to fetch package metadata: %w", err)
+	// Download package metadata to get tarball URL, from the first registry
+	// in the fallback chain that has the package
+	chain := registryChain(config.Resolved{Value: cfg.Registry, Source: config.SourceUser}, packageName, ".")
+	var baseURL *url.URL
+	var packageInfo *api.PackageMetadata
+	for i, registry := range chain {
+		registryURL, err := url.Parse(registry)
+		if err != nil {
+			return fmt.Errorf("invalid registry URL: %w", err)
+		}
+		if packageInfo, err = fetchPackageDocument(registryURL, packageName); err != nil {
+			return err
+		}
+		if packageInfo != nil {
+			baseURL = registryURL
+			if i > 0 {
+				installPrintf("%s %s\n", styling.Label("Registry (fallback):"), styling.URL(registry))
+			}
+			break
+		}
 	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode == 404 {
+	if packageInfo == nil {
 		return fmt.Errorf("package not found: %s", packageName)
 	}
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("registry error (HTTP %d) for package: %s", resp.StatusCode, packageName)
-	}
-
-	var packageInfo map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&packageInfo); err != nil {
-		return fmt.Errorf("failed to parse package metadata: %w", err)
-	}
 
 	// Get the version to install
-	actualVersion, tarballURL, err := getVersionInfo(packageInfo, version)
+	actualVersion, tarballURL, err := getVersionInfo(packageInfo, version, output)
 	if err != nil {
 		return err
 	}
 
+	// Tarballs may live on a CDN, but only on hosts we were told to trust
+	if !isValidPackageURL(tarballURL, tarballAllowedHosts(baseURL.Host)...) {
+		return fmt.Errorf("refusing to download %s: host is not the registry or a configured tarball host", tarballURL)
+	}
+
 	// Download and extract the package
 	packageDir := filepath.Join(packagesDir, packageName)
-	if err := downloadAndExtractPackage(tarballURL, packageDir); err != nil {
+	if err := downloadAndExtractPackage(commandCtx, tarballURL, baseURL.String(), packageDir, packageName, actualVersion, output); err != nil {
 		return fmt.Errorf("failed to download package: %w", err)
 	}
+	warnings, err := checkUnityGUIDs(engines.NewUnityAdapter(), ".", packageDir, installStrict)
+	if err != nil {
+		_ = os.RemoveAll(packageDir)
+		return err
+	}
+	for _, warning := range warnings {
+		installPrintf("%s\n", styling.Warning("⚠ "+warning))
+	}
+	output.Warnings = append(output.Warnings, warnings...)
 
 	// Create or update Unity manifest.json
 	if err := updateUnityManifest(packageName, actualVersion, isDev); err != nil {
-		fmt.Printf("%s\n", styling.Warning("Package installed but failed to update manifest.json: "+err.Error()))
+		warning := "Package installed but failed to update manifest.json: " + err.Error()
+		installPrintf("%s\n", styling.Warning(warning))
+		output.Warnings = append(output.Warnings, warning)
 	}
 
 	return nil
 }
 
-func getVersionInfo(packageInfo map[string]interface{}, requestedVersion string) (string, string, error) {
-	versions, ok := packageInfo["versions"].(map[string]interface{})
-	if !ok {
+// fetchPackageDocument fetches a package's registry document, or returns
+// nil when the registry doesn't have the package
+func fetchPackageDocument(registryURL *url.URL, packageName string) (*api.PackageMetadata, error) {
+	packageURL := registryURL.JoinPath(packageName).String()
+	// #nosec G107 - URL is validated using url.Parse and JoinPath
+	resp, err := api.HTTPGetPackageDocument(packageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch package metadata: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == 404 {
+		return nil, nil
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("registry error (HTTP %d) for package: %s", resp.StatusCode, packageName)
+	}
+
+	var packageInfo api.PackageMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&packageInfo); err != nil {
+		return nil, fmt.Errorf("failed to parse package metadata: %w", err)
+	}
+	return &packageInfo, nil
+}
+
+func getVersionInfo(packageInfo *api.PackageMetadata, requestedVersion string, output *InstallOutput) (string, string, error) {
+	versions := packageInfo.Versions
+	if versions == nil {
 		return "", "", fmt.Errorf("no versions available for package")
 	}
 
 	// Handle "latest" version
 	var actualVersion string
 	if requestedVersion == "latest" {
-		distTags, ok := packageInfo["dist-tags"].(map[string]interface{})
-		if !ok {
+		if packageInfo.DistTags == nil {
 			return "", "", fmt.Errorf("no dist-tags available")
 		}
-		latest, ok := distTags["latest"].(string)
+		latest, ok := packageInfo.DistTags["latest"]
 		if !ok {
 			return "", "", fmt.Errorf("no latest version found")
 		}
+		if versions[latest].IsYanked() {
+			fallback := api.HighestUnyankedVersion(versions, latest)
+			if fallback == "" {
+				return "", "", fmt.Errorf("latest version %s has been yanked and no earlier version is available", latest)
+			}
+			warning := fmt.Sprintf("Version %s has been yanked; using %s instead", latest, fallback)
+			installPrintf("%s\n", styling.Warning("⚠ "+warning))
+			output.Warnings = append(output.Warnings, warning)
+			latest = fallback
+		}
 		actualVersion = latest
 	} else if isVersionRange(requestedVersion) {
 		// Handle version ranges (^1.0.0, ~1.2.0, >=1.0.0, etc.)
-		matchedVersion, err := findMatchingVersion(versions, requestedVersion)
+		matchedVersion, err := findMatchingVersion(unyankedVersions(versions), requestedVersion)
 		if err != nil {
 			return "", "", err
 		}
 		actualVersion = matchedVersion
 	} else {
 		actualVersion = requestedVersion
+		if versions[actualVersion].IsYanked() {
+			warning := fmt.Sprintf("Version %s has been yanked by its publisher; installing it because it was requested explicitly", actualVersion)
+			installPrintf("%s\n", styling.Warning("⚠ "+warning))
+			output.Warnings = append(output.Warnings, warning)
+		}
 	}
 
 	// Get version info
-	versionInfo, ok := versions[actualVersion].(map[string]interface{})
-	if !ok {
+	versionInfo, ok := versions[actualVersion]
+	if !ok || versionInfo == nil {
 		return "", "", fmt.Errorf("version %s not found", actualVersion)
 	}
 
 	// Get tarball URL
-	dist, ok := versionInfo["dist"].(map[string]interface{})
-	if !ok {
+	if versionInfo.Dist == nil {
 		return "", "", fmt.Errorf("no distribution info for version %s", actualVersion)
 	}
-
-	tarballURL, ok := dist["tarball"].(string)
-	if !ok {
+	if versionInfo.Dist.Tarball == "" {
 		return "", "", fmt.Errorf("no tarball URL for version %s", actualVersion)
 	}
 
-	return actualVersion, tarballURL, nil
+	return actualVersion, versionInfo.Dist.Tarball, nil
+}
+
+// unyankedVersions returns the packument versions without yanked entries
+func unyankedVersions(versions map[string]*api.PackageVersion) map[string]*api.PackageVersion {
+	if versions == nil {
+		return nil
+	}
+	filtered := make(map[string]*api.PackageVersion, len(versions))
+	for version, info := range versions {
+		if !info.IsYanked() {
+			filtered[version] = info
+		}
+	}
+	return filtered
 }
 
 func isVersionRange(version string) bool {
@@ -724,10 +1607,12 @@ func isVersionRange(version string) bool {
 		strings.HasPrefix(version, "<=") ||
 		strings.HasPrefix(version, ">") ||
 		strings.HasPrefix(version, "<") ||
-		strings.Contains(version, " - ")
+		strings.Contains(version, " - ") ||
+		strings.Contains(version, "||") ||
+		strings.HasSuffix(version, ".x")
 }
 
-func findMatchingVersion(versions map[string]interface{}, versionRange string) (string, error) {
+func findMatchingVersion(versions map[string]*api.PackageVersion, versionRange string) (string, error) {
 	var availableVersions []string
 	for version := range versions {
 		availableVersions = append(availableVersions, version)
@@ -875,13 +1760,15 @@ func findGreaterOrEqualMatch(versions []string, baseVersion string) (string, err
 	return bestMatch, nil
 }
 
+// leadingDigits matches the numeric start of a version component
+var leadingDigits = regexp.MustCompile(`^(\d+)`)
+
 func parseVersion(version string) []int {
 	parts := strings.Split(version, ".")
-	var nums []int
+	nums := make([]int, 0, len(parts))
 	for _, part := range parts {
 		// Remove any non-numeric suffixes (like -alpha, -beta)
-		re := regexp.MustCompile(`^(\d+)`)
-		matches := re.FindStringSubmatch(part)
+		matches := leadingDigits.FindStringSubmatch(part)
 		if len(matches) > 1 {
 			if num, err := strconv.Atoi(matches[1]); err == nil {
 				nums = append(nums, num)
@@ -916,9 +1803,19 @@ func compareVersions(a, b []int) int {
 	return 0
 }
 
+// sortVersions sorts version strings in ascending semver order
+func sortVersions(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return api.CompareVersions(versions[i], versions[j]) < 0
+	})
+}
+
 //nolint:unused
 func cloneAndInstallGitPackage(spec PackageSpec) error {
-	packagesDir := "Packages"
+	packagesDir, err := unityPackagesDir()
+	if err != nil {
+		return err
+	}
 	packageDir := filepath.Join(packagesDir, spec.Name)
 
 	// Create Packages directory if it doesn't exist
@@ -974,7 +1871,10 @@ func cloneAndInstallGitPackage(spec PackageSpec) error {
 
 //nolint:unused
 func copyLocalPackage(spec PackageSpec) error {
-	packagesDir := "Packages"
+	packagesDir, err := unityPackagesDir()
+	if err != nil {
+		return err
+	}
 	packageDir := filepath.Join(packagesDir, spec.Name)
 
 	// Create Packages directory if it doesn't exist
@@ -1074,28 +1974,58 @@ func copyDir(src, dst string) error {
 	})
 }
 
-func downloadAndExtractPackage(tarballURL, packageDir string) error {
-	// Download tarball
-	// #nosec G107 - tarballURL comes from trusted registry response
-	resp, err := http.Get(tarballURL)
+// downloadAndExtractPackage downloads the tarball the registry lists for
+// name@version and extracts it into packageDir, once its package.json shows
+// it is that package. A corrupt download is fetched again once. A canceled
+// ctx stops the download; a failed extraction removes what it extracted.
+func downloadAndExtractPackage(ctx context.Context, tarballURL, registryURL, packageDir, name, version string, output *InstallOutput) error {
+	tarballPath, err := fetchCompleteTarball(ctx, tarballURL, registryURL, output)
 	if err != nil {
-		return fmt.Errorf("failed to download tarball: %w", err)
+		return err
 	}
-	defer func() { _ = resp.Body.Close() }()
+	defer func() { _ = os.Remove(tarballPath) }()
 
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("failed to download tarball (HTTP %d)", resp.StatusCode)
+	info, err := packaging.ExtractPackageInfo(tarballPath)
+	if err != nil {
+		return fmt.Errorf("failed to read package.json from tarball: %w", err)
+	}
+	warning, err := checkPackageIdentity(name, version, info, installForce)
+	if err != nil {
+		return err
 	}
+	if warning != "" {
+		installPrintf("%s\n", styling.Warning("⚠ "+warning))
+		output.Warnings = append(output.Warnings, warning)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	file, err := os.Open(tarballPath) // #nosec G304 - temporary file written by fetchTarball
+	if err != nil {
+		return fmt.Errorf("failed to open tarball: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	if err := extractPackageTarball(file, packageDir); err != nil {
+		_ = os.RemoveAll(packageDir)
+		return err
+	}
+	return nil
+}
 
+// extractPackageTarball unpacks a gzipped npm-style tarball into packageDir,
+// replacing anything already there
+func extractPackageTarball(r io.Reader, packageDir string) error {
 	// Create gzip reader
-	gzReader, err := gzip.NewReader(resp.Body)
+	gzReader, err := gzip.NewReader(r)
 	if err != nil {
-		return fmt.Errorf("failed to create gzip reader: %w", err)
+		return corruptTarballError(err, true)
 	}
 	defer func() { _ = gzReader.Close() }()
 
-	// Create tar reader
-	tarReader := tar.NewReader(gzReader)
+	// Create tar reader, noting whether a failure comes from the gzip stream
+	gzErrs := &errorRecordingReader{r: gzReader}
+	tarReader := tar.NewReader(gzErrs)
 
 	// Remove existing package directory
 	if err := os.RemoveAll(packageDir); err != nil {
@@ -1109,7 +2039,7 @@ func downloadAndExtractPackage(tarballURL, packageDir string) error {
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("failed to read tar header: %w", err)
+			return corruptTarballError(err, gzErrs.err != nil)
 		}
 
 		// Remove "package/" prefix from path (npm/UPM standard)
@@ -1146,10 +2076,13 @@ func downloadAndExtractPackage(tarballURL, packageDir string) error {
 				return fmt.Errorf("failed to create file %s: %w", fullPath, err)
 			}
 
-			// Limit extraction size to prevent decompression bombs (100MB limit)
-			limitReader := io.LimitReader(tarReader, 100*1024*1024)
+			// Limit extraction size to prevent decompression bombs
+			limitReader := io.LimitReader(tarReader, maxTarballSize)
 			if _, err := io.Copy(outFile, limitReader); err != nil {
 				_ = outFile.Close() // Best effort cleanup
+				if gzErrs.err != nil || errors.Is(err, io.ErrUnexpectedEOF) {
+					return corruptTarballError(err, gzErrs.err != nil)
+				}
 				return fmt.Errorf("failed to extract file %s: %w", fullPath, err)
 			}
 			if err := outFile.Close(); err != nil {
@@ -1161,8 +2094,429 @@ func downloadAndExtractPackage(tarballURL, packageDir string) error {
 	return nil
 }
 
+// errorRecordingReader remembers the first read error other than io.EOF, so
+// a tar failure can be told apart from the gzip stream under it failing
+type errorRecordingReader struct {
+	r   io.Reader
+	err error
+}
+
+func (e *errorRecordingReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err != nil && err != io.EOF && e.err == nil {
+		e.err = err
+	}
+	return n, err
+}
+
+// corruptTarballError explains a gzip or tar failure as a broken download
+// rather than the reader's bare "unexpected EOF"
+func corruptTarballError(err error, inGzip bool) error {
+	detail := "the tar archive inside is malformed"
+	if inGzip {
+		detail = "the gzip stream is cut short or damaged"
+		if !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			detail = "it is not a valid gzip file"
+		}
+	} else if errors.Is(err, io.ErrUnexpectedEOF) {
+		detail = "the tar archive inside ends early"
+	}
+	return fmt.Errorf("%s\n\n%s",
+		styling.Error(fmt.Sprintf("downloaded package appears corrupt or truncated: %s (%v)", detail, err)),
+		styling.Hint("Retry the command, or clear any proxy or CDN cache between gpm and the registry"))
+}
+
+// checkTarball reads a downloaded tarball to the end through gzip and tar,
+// so a truncated download fails before anything is extracted
+func checkTarball(tarballPath string) error {
+	file, err := os.Open(tarballPath) // #nosec G304 - temporary file written by fetchTarball
+	if err != nil {
+		return fmt.Errorf("failed to open tarball: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	gzReader, err := gzip.NewReader(file)
+	if err != nil {
+		return corruptTarballError(err, true)
+	}
+	defer func() { _ = gzReader.Close() }()
+
+	gzErrs := &errorRecordingReader{r: gzReader}
+	tarReader := tar.NewReader(gzErrs)
+	for {
+		_, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err == nil {
+			_, err = io.Copy(io.Discard, io.LimitReader(tarReader, maxTarballSize))
+		}
+		if err != nil {
+			return corruptTarballError(err, gzErrs.err != nil)
+		}
+	}
+}
+
+// fetchCompleteTarball fetches a tarball like fetchTarball, downloading it a
+// second time when the first copy turns out to be corrupt or truncated
+func fetchCompleteTarball(ctx context.Context, tarballURL, registryURL string, output *InstallOutput) (string, error) {
+	tarballPath, err := fetchTarball(ctx, tarballURL, registryURL)
+	if err != nil {
+		return "", err
+	}
+	if checkTarball(tarballPath) == nil {
+		return tarballPath, nil
+	}
+	_ = os.Remove(tarballPath)
+
+	warning := "The downloaded package appears corrupt or truncated, downloading it again"
+	installPrintf("%s\n", styling.Warning("⚠ "+warning))
+	output.Warnings = append(output.Warnings, warning)
+	if tarballPath, err = fetchTarball(ctx, tarballURL, registryURL); err != nil {
+		return "", err
+	}
+	if err := checkTarball(tarballPath); err != nil {
+		_ = os.Remove(tarballPath)
+		return "", err
+	}
+	return tarballPath, nil
+}
+
+// isTarballURL reports whether a package spec is a direct http(s) link to a
+// package tarball, such as a version's dist.tarball
+func isTarballURL(spec string) bool {
+	parsed, err := url.Parse(spec)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return false
+	}
+	return strings.HasSuffix(parsed.Path, ".tgz") || strings.HasSuffix(parsed.Path, ".tar.gz")
+}
+
+// fetchTarball downloads a tarball URL to a temporary file. The URL must be on
+// the registry or a configured tarball host, and the download is capped at
+// maxTarballSize. An integrity fragment ("#sha512-...") is verified against
+// the downloaded bytes. Canceling ctx aborts the download and removes the
+// partial file. The caller removes the returned file.
+func fetchTarball(ctx context.Context, tarballURL, registryURL string) (string, error) {
+	tarballURL, integrity, _ := strings.Cut(tarballURL, "#")
+
+	registry, err := url.Parse(registryURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid registry URL: %w", err)
+	}
+	if !isValidPackageURL(tarballURL, tarballAllowedHosts(registry.Host)...) {
+		return "", fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("refusing to download %s: host is not the registry or a configured tarball host", tarballURL)),
+			styling.Hint("Trust the host with 'gpm config set tarball_hosts <host>' or pass its registry with --registry"))
+	}
+
+	var hasher hash.Hash
+	if integrity != "" {
+		if hasher, err = integrityHash(integrity); err != nil {
+			return "", err
+		}
+	}
+
+	// #nosec G107 - tarballURL is checked by isValidPackageURL above
+	resp, err := httpGetPackageURL(ctx, tarballURL, tarballAllowedHosts(registry.Host)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to download tarball: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("failed to download tarball (HTTP %d)", resp.StatusCode)
+	}
+	if resp.ContentLength > maxTarballSize {
+		return "", fmt.Errorf("tarball is %d bytes, over the %d byte limit", resp.ContentLength, maxTarballSize)
+	}
+
+	file, err := os.CreateTemp("", "gpm-tarball-*.tgz")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	path := file.Name()
+	fail := func(err error) (string, error) {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return "", err
+	}
+
+	var w io.Writer = file
+	if hasher != nil {
+		w = io.MultiWriter(file, hasher)
+	}
+	written, err := io.Copy(w, io.LimitReader(resp.Body, maxTarballSize+1))
+	if err != nil {
+		return fail(fmt.Errorf("failed to download tarball: %w", err))
+	}
+	if written > maxTarballSize {
+		return fail(fmt.Errorf("tarball is over the %d byte limit", maxTarballSize))
+	}
+	if hasher != nil {
+		algorithm, _, _ := strings.Cut(integrity, "-")
+		if actual := algorithm + "-" + base64.StdEncoding.EncodeToString(hasher.Sum(nil)); actual != integrity {
+			return fail(fmt.Errorf("integrity check failed for %s: expected %s, got %s", tarballURL, integrity, actual))
+		}
+	}
+	if err := file.Close(); err != nil {
+		return fail(fmt.Errorf("failed to write tarball: %w", err))
+	}
+	return path, nil
+}
+
+// integrityHash returns the hash for a subresource integrity string such as
+// "sha512-<base64>"
+func integrityHash(integrity string) (hash.Hash, error) {
+	algorithm, digest, _ := strings.Cut(integrity, "-")
+	if _, err := base64.StdEncoding.DecodeString(digest); err != nil || digest == "" {
+		return nil, fmt.Errorf("invalid integrity %q: expected <algorithm>-<base64 digest>", integrity)
+	}
+	switch algorithm {
+	case "sha512":
+		return sha512.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	case "sha1":
+		return sha1.New(), nil // #nosec G401 - sha1 integrity is still published by older registries
+	default:
+		return nil, fmt.Errorf("unsupported integrity algorithm %q (use sha512, sha256, or sha1)", algorithm)
+	}
+}
+
+// installTarball installs a downloaded tarball. The package name and version
+// in req come from the tarball's package.json; unless this is a dry run the
+// contents are unpacked before the manifest changes and moved to where the
+// engine loads the package from, putting back the files they replaced when
+// the adapter fails. Unity packages are checked for asset GUIDs
+// the project already uses; strict makes a collision undo the install.
+func installTarball(adapter engines.EngineAdapter, projectDir, tarballPath string, req *engines.PackageInstallRequest, strict bool) (*engines.PackageInstallResult, error) {
+	info, err := packaging.ExtractPackageInfo(tarballPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package.json from tarball: %w", err)
+	}
+	if err := validation.ValidatePackageName(info.Name); err != nil {
+		return nil, fmt.Errorf("invalid package name in tarball: %w", err)
+	}
+	if err := config.CheckPackagePolicy(info.Name); err != nil {
+		return nil, err
+	}
+	if info.Version == "" {
+		return nil, fmt.Errorf("tarball package.json for %s has no version", info.Name)
+	}
+	req.Name = info.Name
+	req.Version = info.Version
+
+	// The contents are unpacked before the manifest changes, so a broken
+	// tarball leaves the project as it was
+	var restore func()
+	if !req.DryRun {
+		stagingDir, err := packageStagingDir(adapter, projectDir)
+		if err != nil {
+			return nil, err
+		}
+		contentsDir, cleanup, err := stagePackage(tarballPath, stagingDir)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		if adapter.GetEngineType() == engines.EngineUnity {
+			// Unity embeds the package next to its manifest
+			if restore, err = swapPackageDir(contentsDir, filepath.Join(stagingDir, info.Name)); err != nil {
+				return nil, err
+			}
+		} else {
+			req.ContentsDir = contentsDir
+		}
+	}
+
+	result, err := adapter.InstallPackage(projectDir, req)
+	if err != nil {
+		if restore != nil {
+			restore()
+		}
+		if readOnlyErr := readOnlyProjectError(err); readOnlyErr != nil {
+			return nil, readOnlyErr
+		}
+		return nil, fmt.Errorf("installation failed: %w", err)
+	}
+	if req.DryRun {
+		return result, nil
+	}
+
+	if unityAdapter, ok := adapter.(*engines.UnityAdapter); ok {
+		packageDir := filepath.Join(filepath.Dir(result.InstallPath), info.Name)
+		warnings, err := checkUnityGUIDs(unityAdapter, projectDir, packageDir, strict)
+		if err != nil {
+			restore()
+			_ = adapter.RemovePackage(projectDir, req.DependencyKey())
+			return nil, err
+		}
+		result.Warnings = append(result.Warnings, warnings...)
+	}
+	return result, nil
+}
+
+// verifyBackup backs up the Unity manifest before an install that --verify
+// will check, returning "" when there is nothing to verify
+func verifyBackup(adapter engines.EngineAdapter, projectDir string) (string, error) {
+	if !installVerify || installDryRun || adapter.GetEngineType() != engines.EngineUnity {
+		return "", nil
+	}
+	backupPath, err := createProjectBackup(projectDir, engines.EngineUnity, installPackagesDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup: %w", err)
+	}
+	return backupPath, nil
+}
+
+// verifyInstall is the --verify check after an install. For Unity it re-reads
+// the manifest and fails when the dependency or its scoped registry isn't
+// what Unity needs to resolve the package; other engines aren't checked.
+func verifyInstall(adapter engines.EngineAdapter, projectDir string, req *engines.PackageInstallRequest, result *engines.PackageInstallResult) error {
+	unityAdapter, ok := adapter.(*engines.UnityAdapter)
+	if !ok {
+		return nil
+	}
+	problems, err := unityAdapter.VerifyInstall(projectDir, req, result.Version)
+	if err != nil {
+		return fmt.Errorf("failed to verify installation: %w", err)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s\n  %s\n\n%s",
+		styling.Error(fmt.Sprintf("Verification of %s failed after installing:", req.Name)),
+		strings.Join(problems, "\n  "),
+		styling.Hint("The manifest was restored from backup; fix manifest.json and install again"))
+}
+
+// undoVerifiedInstall rolls back an install that failed --verify: the
+// manifest is restored from backupPath and packageDir, the copy a tarball
+// install extracted, is removed when set
+func undoVerifiedInstall(verifyErr error, backupPath, projectDir string, engineType engines.EngineType, packagesDir, packageDir string) error {
+	if packageDir != "" {
+		_ = os.RemoveAll(packageDir)
+	}
+	if err := restoreFromBackup(backupPath, projectDir, engineType, packagesDir); err != nil {
+		return fmt.Errorf("%w\n\nrestoring the manifest also failed: %v", verifyErr, err)
+	}
+	return verifyErr
+}
+
+// embeddedPackageDir is where installTarball extracted a Unity package, or ""
+// for other engines
+func embeddedPackageDir(adapter engines.EngineAdapter, result *engines.PackageInstallResult) string {
+	if adapter.GetEngineType() != engines.EngineUnity {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(result.InstallPath), result.PackageName)
+}
+
+// tarballURLPackageName returns the package a registry-style tarball URL
+// (".../<name>/-/<name>-<version>.tgz") is published under, or "" when the
+// URL doesn't follow that layout
+func tarballURLPackageName(tarballURL string) string {
+	parsed, err := url.Parse(tarballURL)
+	if err != nil {
+		return ""
+	}
+	dir, file, ok := strings.Cut(parsed.Path, "/-/")
+	if !ok {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(dir, "/"), "/")
+	name := segments[len(segments)-1]
+	if len(segments) >= 2 && strings.HasPrefix(segments[len(segments)-2], "@") {
+		name = segments[len(segments)-2] + "/" + name
+	}
+	if !strings.HasPrefix(path.Base(file), path.Base(name)+"-") {
+		return ""
+	}
+	return name
+}
+
+// checkTarballName rejects a tarball whose package.json names another package
+// than the URL it was published under, which would otherwise install under a
+// name nobody asked for
+func checkTarballName(tarballURL, name string) error {
+	expected := tarballURLPackageName(tarballURL)
+	if expected == "" || expected == name {
+		return nil
+	}
+	return fmt.Errorf("%s\n\n%s",
+		styling.Error(fmt.Sprintf("Tarball for %s contains %s", expected, name)),
+		styling.Hint("The registry served a package under the wrong name; report it to the publisher instead of installing it"))
+}
+
+// checkPackageIdentity rejects a tarball the registry served for name@version
+// whose package.json declares another package or version, so a malicious or
+// misconfigured registry can't install one package under another's name.
+// force accepts the mismatch for known-good republishes, returning it as a
+// warning instead.
+func checkPackageIdentity(name, version string, info *packaging.PackageInfo, force bool) (string, error) {
+	if info.Name == name && info.Version == version {
+		return "", nil
+	}
+	mismatch := fmt.Sprintf("Tarball for %s@%s contains %s@%s", name, version, info.Name, info.Version)
+	if force {
+		return mismatch + "; installing it anyway because of --force", nil
+	}
+	return "", fmt.Errorf("%s\n\n%s",
+		styling.Error(mismatch),
+		styling.Hint("The registry served another package than the one requested; report it to the registry, or pass --force if this is a known-good republish"))
+}
+
+// checkUnityGUIDs looks for asset GUIDs in an extracted Unity package that the
+// project's Assets/ already uses. Collisions are returned as warnings, or as
+// an error when strict.
+func checkUnityGUIDs(adapter *engines.UnityAdapter, projectDir, packageDir string, strict bool) ([]string, error) {
+	collisions, err := adapter.GUIDCollisions(projectDir, packageDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(collisions) == 0 {
+		return nil, nil
+	}
+	if strict {
+		return nil, fmt.Errorf("%s\n  %s\n\n%s",
+			styling.Error("Package assets reuse GUIDs from the project's Assets/ folder:"),
+			strings.Join(collisions, "\n  "),
+			styling.Hint("Unity would import only one asset per GUID; regenerate the GUIDs in the package or the project"))
+	}
+	warnings := make([]string, 0, len(collisions))
+	for _, collision := range collisions {
+		warnings = append(warnings, "GUID collision: "+collision)
+	}
+	return warnings, nil
+}
+
 func updateUnityManifest(packageName, version string, isDev bool) error {
-	manifestPath := "Packages/manifest.json"
+	// For local packages, use file: protocol
+	return setUnityManifestDependency(packageName, "file:./"+packageName)
+}
+
+// setUnityManifestDependency records reference as the package's entry in the
+// Unity manifest, creating the manifest if needed
+// checkManifestWritable is engines.CheckManifestWritable with the
+// user-facing read-only error
+func checkManifestWritable(manifestPath string) error {
+	err := engines.CheckManifestWritable(manifestPath)
+	if readOnlyErr := readOnlyProjectError(err); readOnlyErr != nil {
+		return readOnlyErr
+	}
+	return err
+}
+
+func setUnityManifestDependency(packageName, reference string) error {
+	packagesDir, err := unityPackagesDir()
+	if err != nil {
+		return err
+	}
+	manifestPath := filepath.Join(packagesDir, "manifest.json")
+	if err := checkManifestWritable(manifestPath); err != nil {
+		return err
+	}
 
 	var manifest map[string]interface{}
 
@@ -1176,7 +2530,7 @@ func updateUnityManifest(packageName, version string, isDev bool) error {
 			"dependencies": make(map[string]interface{}),
 		}
 		// Ensure Packages directory exists
-		if err := os.MkdirAll("Packages", 0750); err != nil {
+		if err := os.MkdirAll(packagesDir, 0750); err != nil {
 			return fmt.Errorf("failed to create Packages directory: %w", err)
 		}
 	}
@@ -1188,8 +2542,7 @@ func updateUnityManifest(packageName, version string, isDev bool) error {
 		manifest["dependencies"] = deps
 	}
 
-	// For local packages, use file: protocol
-	deps[packageName] = "file:./" + packageName
+	deps[packageName] = reference
 
 	// Write updated manifest
 	updatedData, err := json.MarshalIndent(manifest, "", "  ")
@@ -1197,7 +2550,13 @@ func updateUnityManifest(packageName, version string, isDev bool) error {
 		return fmt.Errorf("failed to marshal manifest: %w", err)
 	}
 
-	return os.WriteFile(manifestPath, updatedData, 0600)
+	return engines.WriteManifest(manifestPath, updatedData)
+}
+
+// unityPackagesDir resolves the packages directory used by the direct download
+// install path, honoring --packages-dir and $GPM_UNITY_PACKAGES_DIR
+func unityPackagesDir() (string, error) {
+	return engines.ResolveUnityPackagesDir(".", installPackagesDir)
 }
 
 //nolint:unused
@@ -1244,100 +2603,181 @@ func updatePackageJSON(packageName, version string, isDev bool) error {
 	return os.WriteFile(packageJSONPath, updatedData, 0600)
 }
 
-// resolveLatestVersionFromRegistry fetches the latest version from a registry
-func resolveLatestVersionFromRegistry(packageName, registryURL string) (string, error) {
-	// Parse the registry URL
-	baseURL, err := url.Parse(registryURL)
-	if err != nil {
-		return "", fmt.Errorf("invalid registry URL: %w", err)
+// registryChain lists the registries to try for a package, in order: the
+// resolved registry, then the configured fallbacks. An explicit --registry
+// or a mapped @scope pins the package to a single registry.
+func registryChain(resolved config.Resolved, packageName, projectDir string) []string {
+	chain := []string{resolved.Value}
+	if resolved.Source == config.SourceFlag {
+		return chain
 	}
-
-	// Construct the package info URL
-	packageURL := baseURL.JoinPath(packageName).String()
-
-	// Validate URL to prevent SSRF attacks
-	if !isValidPackageURL(packageURL, baseURL.Host) {
-		return "", fmt.Errorf("invalid package URL: %s", packageURL)
+	if scope := config.PackageScope(packageName); scope != "" && config.ResolveScopedRegistry(scope, "", projectDir).Value != resolved.Value {
+		return chain
 	}
-
-	// Fetch package metadata
-	resp, err := http.Get(packageURL) // #nosec G107 -- URL is validated by isValidPackageURL
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch package metadata: %w", err)
+	for _, registry := range config.GetConfig().RegistryFallbacks {
+		if !slices.Contains(chain, registry) {
+			chain = append(chain, registry)
+		}
 	}
-	defer func() { _ = resp.Body.Close() }()
+	return chain
+}
 
-	if resp.StatusCode == 404 {
-		return "", fmt.Errorf("package not found: %s", packageName)
+// registryToken returns the token to send to registry. Only the resolved
+// registry gets the configured token; fallbacks are queried anonymously so
+// studio credentials never reach a public registry. A registry the project
+// chose (.gpmrc, package.json or .npmrc) only gets the token when it is the
+// registry the token was configured for, so a cloned repository can't
+// collect it by pointing at its own host. The token itself is resolved
+// like `gpm config get token --effective` reports it; one from the project
+// .npmrc is keyed by its registry and only sent there.
+func registryToken(registry string, resolved config.Resolved, projectDir string) string {
+	if registry != resolved.Value {
+		return ""
+	}
+	token := config.ResolveToken("", projectDir)
+	if token.Source == config.SourceNpmrc {
+		if registry != config.ResolveRegistry("", projectDir).Value {
+			return ""
+		}
+		return token.Value
 	}
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("registry error (HTTP %d) for package: %s", resp.StatusCode, packageName)
+	switch resolved.Source {
+	case config.SourceFlag, config.SourceEnv, config.SourceProfile:
+		return token.Value
 	}
-
-	// Parse the response
-	var packageInfo map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&packageInfo); err != nil {
-		return "", fmt.Errorf("failed to parse package metadata: %w", err)
+	if strings.TrimSuffix(registry, "/") != strings.TrimSuffix(config.GetConfig().Registry, "/") {
+		return ""
 	}
+	return token.Value
+}
+
+// effectiveClient returns a client for the effective registry, --registry
+// when flagValue is set, sent the token configured for it
+func effectiveClient(flagValue, projectDir string) *api.Client {
+	resolved := config.ResolveRegistry(flagValue, projectDir)
+	return api.NewClient(resolved.Value, registryToken(resolved.Value, resolved, projectDir))
+}
 
-	// First try to get the latest version from dist-tags
-	if distTags, ok := packageInfo["dist-tags"].(map[string]interface{}); ok {
-		if latest, ok := distTags["latest"].(string); ok && latest != "" {
-			return latest, nil
+// findPackageRegistry returns the first registry in chain that has the
+// package. Unreachable registries are skipped; the error lists every
+// registry tried when none has it.
+func findPackageRegistry(chain []string, resolved config.Resolved, projectDir, packageName string, timeout time.Duration) (string, error) {
+	if len(chain) == 1 {
+		return chain[0], checkRegistryReachable(chain[0], timeout)
+	}
+	if timeout <= 0 {
+		timeout = config.GetRegistryTimeout()
+	}
+	var errs []string
+	for _, registry := range chain {
+		if err := api.CheckRegistryReachable(registry, timeout); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		_, err := api.NewClient(registry, registryToken(registry, resolved, projectDir)).GetAbbreviatedMetadata(packageName)
+		if err == nil {
+			return registry, nil
+		}
+		var notFound *api.PackageNotFoundError
+		if !errors.As(err, &notFound) {
+			errs = append(errs, fmt.Sprintf("%s: %v", registry, err))
 		}
 	}
+	message := fmt.Sprintf("package %s not found in any registry (tried %s)", packageName, strings.Join(chain, ", "))
+	if len(errs) > 0 {
+		message += "; " + strings.Join(errs, "; ")
+	}
+	return "", errors.New(message)
+}
 
-	// If no dist-tags or latest tag, get all versions and find the highest one
-	versions, ok := packageInfo["versions"].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("no versions available for package: %s", packageName)
+// resolveInstallLatest resolves a package through its latest dist-tag,
+// skipping a yanked latest with a warning. A package without the tag gets
+// its highest stable version, or its highest prerelease when nothing stable
+// is published.
+func resolveInstallLatest(client *api.Client, packageName string, output *InstallOutput) (string, error) {
+	resolution, err := client.ResolveVersion(packageName, "latest")
+	var noLatest *api.NoLatestTagError
+	if errors.As(err, &noLatest) {
+		return resolveUntaggedVersion(client, packageName)
+	}
+	if err != nil {
+		return "", err
 	}
 
-	if len(versions) == 0 {
-		return "", fmt.Errorf("no versions available for package: %s", packageName)
+	if resolution.SkippedYanked != "" {
+		warning := fmt.Sprintf("%s@%s has been yanked; using %s instead", packageName, resolution.SkippedYanked, resolution.Version)
+		installPrintf("%s\n", styling.Warning("⚠ "+warning))
+		output.Warnings = append(output.Warnings, warning)
+	} else if installVerbose {
+		installPrintf("%s %s is tagged latest for %s\n", styling.Label("Latest:"), styling.Version(resolution.Version), packageName)
 	}
+	return resolution.Version, nil
+}
 
-	// Get all version strings and sort them
-	var versionStrings []string
-	for version := range versions {
-		versionStrings = append(versionStrings, version)
+// resolveUntaggedVersion picks the version a missing latest dist-tag stands
+// for from the package's non-yanked versions. The full document is fetched
+// so registries that strip yank flags from the abbreviated one still skip them.
+func resolveUntaggedVersion(client *api.Client, packageName string) (string, error) {
+	metadata, err := client.GetPackageMetadata(packageName)
+	if err != nil {
+		return "", err
 	}
 
-	fmt.Printf("%s Available versions: %v\n", styling.Label("Found"), versionStrings)
+	var versions []string
+	for version := range unyankedVersions(metadata.Versions) {
+		versions = append(versions, version)
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("no versions available for package: %s", packageName)
+	}
 
-	// Find the highest version using semantic versioning
-	latestVersion, err := findHighestVersion(versionStrings)
+	latestVersion, err := findHighestVersion(versions)
 	if err != nil {
 		return "", fmt.Errorf("failed to determine latest version: %w", err)
 	}
-
+	if installVerbose {
+		heuristic := "the highest stable version"
+		if api.IsPrerelease(latestVersion) {
+			heuristic = "the highest prerelease, as no stable version is published"
+		}
+		installPrintf("%s %s has no latest dist-tag; using %s (%s)\n", styling.Label("Latest:"), packageName, heuristic, styling.Version(latestVersion))
+	}
 	return latestVersion, nil
 }
 
-// findHighestVersion finds the highest semantic version from a list of version strings
+// findHighestVersion picks the version a missing latest dist-tag stands for:
+// the highest stable version by semver precedence, or the highest prerelease
+// when nothing stable has been published. Versions that compare equal, such
+// as ones differing only in build metadata, are ordered by name so the pick
+// doesn't depend on map order.
 func findHighestVersion(versions []string) (string, error) {
 	if len(versions) == 0 {
 		return "", fmt.Errorf("no versions provided")
 	}
 
-	var highestVersion string
-	var highestParts []int
-
+	var highestStable, highestPrerelease string
 	for _, version := range versions {
-		parts := parseVersion(version)
-		if len(parts) == 0 {
+		if len(parseVersion(version)) == 0 {
 			continue // Skip invalid versions
 		}
-
-		if highestVersion == "" || compareVersions(parts, highestParts) > 0 {
-			highestVersion = version
-			highestParts = parts
+		highest := &highestStable
+		if api.IsPrerelease(version) {
+			highest = &highestPrerelease
+		}
+		if *highest == "" {
+			*highest = version
+			continue
+		}
+		if cmp := api.CompareVersions(version, *highest); cmp > 0 || (cmp == 0 && version > *highest) {
+			*highest = version
 		}
 	}
 
-	if highestVersion == "" {
-		return "", fmt.Errorf("no valid versions found")
+	if highestStable != "" {
+		return highestStable, nil
 	}
-
-	return highestVersion, nil
+	if highestPrerelease != "" {
+		return highestPrerelease, nil
+	}
+	return "", fmt.Errorf("no valid versions found")
 }
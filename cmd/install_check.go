@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+	"gpm.sh/gpm/gpm-cli/internal/validation"
+)
+
+// PackageDrift is a package whose installed version no longer matches what
+// the project declares for it
+type PackageDrift struct {
+	Package  string `json:"package"`
+	Declared string `json:"declared"`
+	// Installed is empty when the package isn't installed at all
+	Installed string `json:"installed,omitempty"`
+	// Source is where the declared spec comes from: package.json or the
+	// origins gpm recorded when installing
+	Source string `json:"source"`
+}
+
+// declaredSpec is a version spec and the file it was declared in
+type declaredSpec struct {
+	spec   string
+	source string
+}
+
+// checkInstall is install --check: it compares each declared package spec
+// with the installed version and reports drift without changing anything
+func checkInstall(args []string, output *InstallOutput) error {
+	if len(args) > 0 || installBundle != "" {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("--check compares the whole project and takes no packages"),
+			styling.Hint("Run 'gpm install --check' on its own"))
+	}
+
+	projectDir := installProjectDir
+	if projectDir == "" {
+		projectDir = "."
+	}
+	engineType, _, err := determineEngineType(projectDir)
+	if err != nil {
+		return fmt.Errorf("engine detection failed: %w", err)
+	}
+	adapter, err := engines.GetAdapter(engineType)
+	if err != nil {
+		return fmt.Errorf("failed to get engine adapter: %w", err)
+	}
+	if unityAdapter, ok := adapter.(*engines.UnityAdapter); ok {
+		unityAdapter.SetPackagesDir(installPackagesDir)
+		unityAdapter.SetStateDir(projectStateDir(projectDir))
+	}
+	if err := adapter.ValidateProject(projectDir); err != nil {
+		return fmt.Errorf("project validation failed: %w", err)
+	}
+	output.Engine = string(engineType)
+	output.Project = projectDir
+
+	declared, err := declaredSpecs(adapter, projectDir)
+	if err != nil {
+		return err
+	}
+	packages, err := adapter.ListPackages(projectDir)
+	if err != nil {
+		return fmt.Errorf("failed to list installed packages: %w", err)
+	}
+	installed := make(map[string]string, len(packages))
+	for _, pkg := range packages {
+		installed[pkg.Name] = pkg.Version
+	}
+
+	names := make([]string, 0, len(declared))
+	for name := range declared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	output.Drift = []PackageDrift{}
+	for _, name := range names {
+		spec := declared[name]
+		version, ok := installed[name]
+		if ok && versionMatchesSpec(version, spec.spec) {
+			continue
+		}
+		output.Drift = append(output.Drift, PackageDrift{Package: name, Declared: spec.spec, Installed: version, Source: spec.source})
+	}
+
+	if len(output.Drift) == 0 {
+		installPrintf("%s\n", styling.Success(fmt.Sprintf("✓ %d declared package(s) match what is installed", len(names))))
+		return nil
+	}
+	reinstall := make([]string, 0, len(output.Drift))
+	for _, drift := range output.Drift {
+		installed := drift.Installed
+		if installed == "" {
+			installed = "not installed"
+		}
+		installPrintf("%s %s: declared %s (%s), installed %s\n", styling.Error("✗"), styling.Package(drift.Package), styling.Version(drift.Declared), drift.Source, installed)
+		reinstall = append(reinstall, drift.Package+"@"+drift.Declared)
+	}
+	return fmt.Errorf("%s\n\n%s",
+		styling.Error(fmt.Sprintf("%d package(s) drifted from their declared versions", len(output.Drift))),
+		styling.Hint("Run 'gpm install "+strings.Join(reinstall, " ")+"' to reconcile them"))
+}
+
+// declaredSpecs returns the spec each package is declared with: its range
+// recorded by gpm, overridden by package.json's dependencies. Specs that
+// can't be checked offline, such as dist-tags, workspace links and URLs, are
+// left out.
+func declaredSpecs(adapter engines.EngineAdapter, projectDir string) (map[string]declaredSpec, error) {
+	declared := make(map[string]declaredSpec)
+	if unityAdapter, ok := adapter.(*engines.UnityAdapter); ok {
+		ranges, err := unityAdapter.RequestedRanges(projectDir)
+		if err != nil {
+			return nil, err
+		}
+		for name, versionRange := range ranges {
+			declared[name] = declaredSpec{spec: versionRange, source: engines.UnityPackagesFile}
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectDir, "package.json")) // #nosec G304 - package.json in the project directory
+	if errors.Is(err, os.ErrNotExist) {
+		return declared, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
+	}
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("invalid package.json: %w", err)
+	}
+	for _, dependencies := range []map[string]string{pkg.Dependencies, pkg.DevDependencies} {
+		for name, spec := range dependencies {
+			if spec == "*" || spec == "latest" || isVersionRange(spec) || validation.ValidateVersion(spec) == nil {
+				declared[name] = declaredSpec{spec: spec, source: "package.json"}
+			}
+		}
+	}
+	return declared, nil
+}
+
+// versionMatchesSpec reports whether an installed version satisfies a
+// declared exact version or range
+func versionMatchesSpec(version, spec string) bool {
+	switch {
+	case spec == "*" || spec == "latest":
+		return true
+	case isVersionRange(spec):
+		return api.SatisfiesRange(version, spec)
+	default:
+		return api.CompareVersions(version, spec) == 0
+	}
+}
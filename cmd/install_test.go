@@ -1,11 +1,31 @@
 package cmd
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/config"
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
 )
 
 func TestInstallCommand(t *testing.T) {
@@ -91,3 +111,1265 @@ func TestInstallCmdStructure(t *testing.T) {
 	saveDevFlag := flags.Lookup("save-dev")
 	assert.NotNil(t, saveDevFlag)
 }
+
+func TestGetVersionInfoSkipsYanked(t *testing.T) {
+	var packageInfo api.PackageMetadata
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"dist-tags": {"latest": "2.1.0"},
+		"versions": {
+			"1.9.0": {"version": "1.9.0", "dist": {"tarball": "https://registry.test/pkg-1.9.0.tgz"}},
+			"2.0.0": {"version": "2.0.0", "dist": {"tarball": "https://registry.test/pkg-2.0.0.tgz"}},
+			"2.0.1": {"version": "2.0.1", "dist": {"tarball": "https://registry.test/pkg-2.0.1.tgz"}, "gpm": {"yanked": true}},
+			"2.1.0": {"version": "2.1.0", "dist": {"tarball": "https://registry.test/pkg-2.1.0.tgz"}, "yanked": true}
+		}
+	}`), &packageInfo))
+
+	t.Run("latest", func(t *testing.T) {
+		installJSON = true
+		defer func() { installJSON = false }()
+
+		output := &InstallOutput{}
+		var actual, tarball string
+		var err error
+		stdout := captureStdout(t, func() error {
+			actual, tarball, err = getVersionInfo(&packageInfo, "latest", output)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "2.0.0", actual)
+		assert.Equal(t, "https://registry.test/pkg-2.0.0.tgz", tarball)
+		assert.Equal(t, []string{"Version 2.1.0 has been yanked; using 2.0.0 instead"}, output.Warnings)
+		assert.Empty(t, stdout, "--json output must stay parseable")
+	})
+
+	t.Run("range", func(t *testing.T) {
+		actual, _, err := getVersionInfo(&packageInfo, "^2.0.0", &InstallOutput{})
+		require.NoError(t, err)
+		assert.Equal(t, "2.0.0", actual)
+	})
+
+	t.Run("exact yanked version still installs", func(t *testing.T) {
+		actual, _, err := getVersionInfo(&packageInfo, "2.1.0", &InstallOutput{})
+		require.NoError(t, err)
+		assert.Equal(t, "2.1.0", actual)
+	})
+}
+
+func TestIsValidPackageURL(t *testing.T) {
+	config.SetConfigForTesting(&config.Config{
+		Registry:     "https://registry.gpm.sh",
+		TarballHosts: []string{"cdn.gpm.sh", "10.0.0.5"},
+	})
+	defer config.ResetConfigForTesting()
+
+	allowed := tarballAllowedHosts("registry.gpm.sh")
+
+	tests := []struct {
+		name     string
+		url      string
+		expected bool
+	}{
+		{"registry-hosted tarball", "https://registry.gpm.sh/com.test.pkg/-/com.test.pkg-1.0.0.tgz", true},
+		{"CDN-hosted tarball", "https://cdn.gpm.sh/tarballs/com.test.pkg-1.0.0.tgz", true},
+		{"unconfigured host", "https://evil.example.com/com.test.pkg-1.0.0.tgz", false},
+		{"private 10.x host even when configured", "http://10.0.0.5/com.test.pkg-1.0.0.tgz", false},
+		{"loopback", "http://127.0.0.1/com.test.pkg-1.0.0.tgz", false},
+		{"non-http scheme", "file:///etc/passwd", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isValidPackageURL(tt.url, allowed...))
+		})
+	}
+}
+
+// writeTestTarball builds a gzipped package tarball holding files, keyed by
+// their path in the archive, and returns it with its sha512 integrity
+func writeTestTarball(t testing.TB, files map[string]string) ([]byte, string) {
+	t.Helper()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		content := files[name]
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	sum := sha512.Sum512(buf.Bytes())
+	return buf.Bytes(), "sha512-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// allowLoopbackTarballs lets tarballs download from httptest servers, which
+// listen on loopback where the SSRF check would refuse them
+func allowLoopbackTarballs(t testing.TB) {
+	blockedTarballHost = func(string) bool { return false }
+	t.Cleanup(func() { blockedTarballHost = isPrivateHost })
+}
+
+func TestInstallFromTarballURL(t *testing.T) {
+	tarball, integrity := writeTestTarball(t, map[string]string{
+		"package/package.json": `{"name": "com.test.tarball", "version": "1.2.0"}`,
+		"package/Runtime/a.cs": "class A {}",
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(tarball)
+	}))
+	defer server.Close()
+	tarballURL := server.URL + "/com.test.tarball/-/com.test.tarball-1.2.0.tgz"
+
+	allowLoopbackTarballs(t)
+	installRegistry = server.URL
+	defer func() { installRegistry = "" }()
+	defer installDownloads.clear()
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{"plain URL", tarballURL, ""},
+		{"matching integrity", tarballURL + "#" + integrity, ""},
+		{"mismatched integrity", tarballURL + "#sha512-" + base64.StdEncoding.EncodeToString(make([]byte, sha512.Size)), "integrity check failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			require.NoError(t, setupUnityProject(projectDir))
+
+			spec := parsePackageSpec(tt.url)
+			require.Equal(t, "tarball", spec.Source)
+
+			output := &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+			err := installPackageWithEngine(engines.NewUnityAdapter(), projectDir, spec, output)
+			embedded := filepath.Join(projectDir, "Packages", "com.test.tarball", "Runtime", "a.cs")
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.NoFileExists(t, embedded)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"com.test.tarball@1.2.0"}, output.Packages)
+			manifest, err := os.ReadFile(filepath.Join(projectDir, "Packages", "manifest.json"))
+			require.NoError(t, err)
+			assert.Contains(t, string(manifest), `"com.test.tarball": "1.2.0"`)
+			assert.FileExists(t, embedded)
+		})
+	}
+
+	t.Run("host not allowed", func(t *testing.T) {
+		installRegistry = "https://registry.gpm.sh"
+		defer func() { installRegistry = server.URL }()
+		_, err := fetchTarball(context.Background(), tarballURL, installRegistry)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refusing to download")
+	})
+}
+
+func TestFetchTarballRedirects(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not a tarball"))
+	}))
+	defer other.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved.tgz":
+			http.Redirect(w, r, "/pkg.tgz", http.StatusFound)
+		case "/pkg.tgz":
+			_, _ = w.Write([]byte("tarball"))
+		case "/elsewhere.tgz":
+			http.Redirect(w, r, other.URL+"/pkg.tgz", http.StatusFound)
+		case "/loop.tgz":
+			http.Redirect(w, r, "/loop.tgz", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	allowLoopbackTarballs(t)
+
+	t.Run("same host", func(t *testing.T) {
+		path, err := fetchTarball(context.Background(), server.URL+"/moved.tgz", server.URL)
+		require.NoError(t, err)
+		defer func() { _ = os.Remove(path) }()
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "tarball", string(data))
+	})
+
+	t.Run("host not allowed", func(t *testing.T) {
+		_, err := fetchTarball(context.Background(), server.URL+"/elsewhere.tgz", server.URL)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refusing to follow redirect")
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		_, err := fetchTarball(context.Background(), server.URL+"/loop.tgz", server.URL)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "stopped after 10 redirects")
+	})
+}
+
+func TestDownloadCanceled(t *testing.T) {
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(1<<20))
+		_, _ = w.Write(make([]byte, 64*1024))
+		w.(http.Flusher).Flush()
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	allowLoopbackTarballs(t)
+	tempDir := t.TempDir()
+	t.Setenv("TMPDIR", tempDir)
+	packageDir := filepath.Join(t.TempDir(), "com.test.slow")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- downloadAndExtractPackage(ctx, server.URL+"/com.test.slow-1.0.0.tgz", server.URL, packageDir, "com.test.slow", "1.0.0", &InstallOutput{})
+	}()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the download kept going after it was canceled")
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the partial tarball must be removed")
+	assert.NoDirExists(t, packageDir)
+}
+
+func TestDownloadCorruptTarball(t *testing.T) {
+	tarball, _ := writeTestTarball(t, map[string]string{
+		"package/package.json": `{"name": "com.test.corrupt", "version": "1.0.0"}`,
+	})
+
+	var notTar bytes.Buffer
+	gz := gzip.NewWriter(&notTar)
+	_, err := gz.Write(bytes.Repeat([]byte("not a tar header "), 64))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	allowLoopbackTarballs(t)
+
+	tests := []struct {
+		name      string
+		responses [][]byte
+		wantErr   string
+	}{
+		{"truncated twice", [][]byte{tarball[:len(tarball)/2], tarball[:len(tarball)/2]}, "the gzip stream is cut short"},
+		{"not gzip", [][]byte{[]byte("<html>Bad gateway</html>"), []byte("<html>Bad gateway</html>")}, "it is not a valid gzip file"},
+		{"not a tar archive", [][]byte{notTar.Bytes(), notTar.Bytes()}, "the tar archive inside is malformed"},
+		{"truncated once", [][]byte{tarball[:len(tarball)/2], tarball}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(tt.responses[requests])
+				requests++
+			}))
+			defer server.Close()
+			packageDir := filepath.Join(t.TempDir(), "com.test.corrupt")
+
+			output := &InstallOutput{}
+			err := downloadAndExtractPackage(context.Background(), server.URL+"/com.test.corrupt-1.0.0.tgz", server.URL, packageDir, "com.test.corrupt", "1.0.0", output)
+			assert.Equal(t, 2, requests, "a corrupt download is fetched again once")
+			assert.Equal(t, []string{"The downloaded package appears corrupt or truncated, downloading it again"}, output.Warnings)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.FileExists(t, filepath.Join(packageDir, "package.json"))
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "downloaded package appears corrupt or truncated")
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.NoDirExists(t, packageDir)
+		})
+	}
+}
+
+func TestInstallDefaultDistTag(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.test.channel", &api.PackageMetadata{
+		Name:     "com.test.channel",
+		DistTags: map[string]string{"latest": "1.0.0", "beta": "2.0.0-beta.1"},
+		Versions: map[string]*api.PackageVersion{
+			"1.0.0":        {Name: "com.test.channel", Version: "1.0.0"},
+			"2.0.0-beta.1": {Name: "com.test.channel", Version: "2.0.0-beta.1"},
+		},
+	})
+	installRegistry = mockRegistry.URL()
+	installTag = "beta"
+	defer func() {
+		installRegistry = ""
+		installTag = ""
+	}()
+
+	projectDir := t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+	output := &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+	require.NoError(t, installPackageWithEngine(engines.NewUnityAdapter(), projectDir, parsePackageSpec("com.test.channel"), output))
+	assert.Equal(t, []string{"com.test.channel@2.0.0-beta.1"}, output.Packages)
+
+	installTag = "stable"
+	projectDir = t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+	output = &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+	require.NoError(t, installPackageWithEngine(engines.NewUnityAdapter(), projectDir, parsePackageSpec("com.test.channel"), output))
+	assert.Equal(t, []string{"com.test.channel@1.0.0"}, output.Packages)
+	require.Len(t, output.Warnings, 1)
+	assert.Contains(t, output.Warnings[0], `no "stable" dist-tag`)
+}
+
+func TestInstallTarballGUIDCollision(t *testing.T) {
+	const guid = "0123456789abcdef0123456789abcdef"
+	tarball, _ := writeTestTarball(t, map[string]string{
+		"package/package.json":      `{"name": "com.test.guid", "version": "1.0.0"}`,
+		"package/Runtime/a.cs":      "class A {}",
+		"package/Runtime/a.cs.meta": "fileFormatVersion: 2\nguid: " + guid + "\n",
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(tarball)
+	}))
+	defer server.Close()
+	tarballURL := server.URL + "/com.test.guid/-/com.test.guid-1.0.0.tgz"
+
+	allowLoopbackTarballs(t)
+	installRegistry = server.URL
+	defer func() { installRegistry = "" }()
+	defer installDownloads.clear()
+
+	setup := func(t *testing.T) string {
+		projectDir := t.TempDir()
+		require.NoError(t, setupUnityProject(projectDir))
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Assets", "Player.cs.meta"), []byte("fileFormatVersion: 2\nguid: "+guid+"\n"), 0644))
+		return projectDir
+	}
+
+	t.Run("warns", func(t *testing.T) {
+		projectDir := setup(t)
+		output := &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+		require.NoError(t, installPackageWithEngine(engines.NewUnityAdapter(), projectDir, parsePackageSpec(tarballURL), output))
+
+		assert.Equal(t, []string{"GUID collision: Packages/com.test.guid/Runtime/a.cs.meta has GUID " + guid + ", already used by Assets/Player.cs.meta"}, output.Warnings)
+		assert.FileExists(t, filepath.Join(projectDir, "Packages", "com.test.guid", "Runtime", "a.cs"))
+	})
+
+	t.Run("fails under --strict", func(t *testing.T) {
+		installStrict = true
+		defer func() { installStrict = false }()
+
+		projectDir := setup(t)
+		output := &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+		err := installPackageWithEngine(engines.NewUnityAdapter(), projectDir, parsePackageSpec(tarballURL), output)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Assets/Player.cs.meta")
+
+		assert.NoDirExists(t, filepath.Join(projectDir, "Packages", "com.test.guid"))
+		manifest, err := os.ReadFile(filepath.Join(projectDir, "Packages", "manifest.json"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(manifest), "com.test.guid")
+	})
+}
+
+func TestInstallTarballPlacementFailureRollsBack(t *testing.T) {
+	// The tarball's package.json reads fine, but the stream ends partway
+	// through the package's files
+	payload := make([]byte, 256<<10)
+	_, err := rand.Read(payload)
+	require.NoError(t, err)
+	tarball, _ := writeTestTarball(t, map[string]string{
+		"package/package.json": `{"name": "com.vendor.ui", "version": "1.1.0"}`,
+		"package/plugin.bin":   string(payload),
+	})
+	tarballPath := filepath.Join(t.TempDir(), "com.vendor.ui-1.1.0.tgz")
+	require.NoError(t, os.WriteFile(tarballPath, tarball[:len(tarball)/2], 0644))
+
+	setupGodot := func(t *testing.T) (string, []byte) {
+		projectDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "project.godot"), []byte("config_version=5\n"), 0644))
+		_, err := engines.NewGodotAdapter().InstallPackage(projectDir, &engines.PackageInstallRequest{Name: "com.vendor.ui", Version: "1.0.0"})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "addons", "com.vendor.ui", "plugin.cfg"), []byte("old"), 0644))
+		manifest, err := os.ReadFile(filepath.Join(projectDir, engines.GodotManifestFile))
+		require.NoError(t, err)
+		return projectDir, manifest
+	}
+	assertUnchanged := func(t *testing.T, manifestPath string, manifest []byte, oldFile string) {
+		data, err := os.ReadFile(manifestPath)
+		require.NoError(t, err)
+		assert.Equal(t, string(manifest), string(data))
+		data, err = os.ReadFile(oldFile)
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(data))
+
+		entries, err := os.ReadDir(filepath.Dir(filepath.Dir(oldFile)))
+		require.NoError(t, err)
+		for _, entry := range entries {
+			assert.False(t, strings.HasPrefix(entry.Name(), ".gpm-"), "left behind %s", entry.Name())
+		}
+	}
+
+	t.Run("godot extraction", func(t *testing.T) {
+		projectDir, manifest := setupGodot(t)
+
+		_, err := installTarball(engines.NewGodotAdapter(), projectDir, tarballPath, &engines.PackageInstallRequest{}, false)
+		require.Error(t, err)
+		assertUnchanged(t, filepath.Join(projectDir, engines.GodotManifestFile), manifest, filepath.Join(projectDir, "addons", "com.vendor.ui", "plugin.cfg"))
+	})
+
+	t.Run("godot move", func(t *testing.T) {
+		projectDir, manifest := setupGodot(t)
+
+		_, err := engines.NewGodotAdapter().InstallPackage(projectDir, &engines.PackageInstallRequest{
+			Name:        "com.vendor.ui",
+			Version:     "1.1.0",
+			ContentsDir: filepath.Join(projectDir, "addons", "missing"),
+		})
+		require.Error(t, err)
+		assertUnchanged(t, filepath.Join(projectDir, engines.GodotManifestFile), manifest, filepath.Join(projectDir, "addons", "com.vendor.ui", "plugin.cfg"))
+	})
+
+	t.Run("unity extraction", func(t *testing.T) {
+		projectDir := t.TempDir()
+		require.NoError(t, setupUnityProject(projectDir))
+		manifestPath := filepath.Join(projectDir, "Packages", "manifest.json")
+		manifest := []byte(`{"dependencies": {"com.vendor.ui": "1.0.0"}}`)
+		require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "Packages", "com.vendor.ui"), 0755))
+		require.NoError(t, os.WriteFile(manifestPath, manifest, 0644))
+		oldFile := filepath.Join(projectDir, "Packages", "com.vendor.ui", "package.json")
+		require.NoError(t, os.WriteFile(oldFile, []byte("old"), 0644))
+
+		_, err := installTarball(engines.NewUnityAdapter(), projectDir, tarballPath, &engines.PackageInstallRequest{}, false)
+		require.Error(t, err)
+		assertUnchanged(t, manifestPath, manifest, oldFile)
+	})
+}
+
+func TestInstallVerify(t *testing.T) {
+	tarball, _ := writeTestTarball(t, map[string]string{
+		"package/package.json": `{"name": "com.test.other", "version": "1.0.0"}`,
+		"package/Runtime/a.cs": "class A {}",
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(tarball)
+	}))
+	defer server.Close()
+
+	allowLoopbackTarballs(t)
+	installRegistry = server.URL
+	defer func() { installRegistry = "" }()
+	installVerify = true
+	defer func() { installVerify = false }()
+	defer installDownloads.clear()
+
+	t.Run("rejects a tarball naming another package", func(t *testing.T) {
+		projectDir := t.TempDir()
+		require.NoError(t, setupUnityProject(projectDir))
+		manifestPath := filepath.Join(projectDir, "Packages", "manifest.json")
+		require.NoError(t, os.MkdirAll(filepath.Dir(manifestPath), 0755))
+		before := `{"dependencies": {"com.test.kept": "1.0.0"}}`
+		require.NoError(t, os.WriteFile(manifestPath, []byte(before), 0644))
+
+		output := &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+		err := installPackageWithEngine(engines.NewUnityAdapter(), projectDir, parsePackageSpec(server.URL+"/com.test.expected/-/com.test.expected-1.0.0.tgz"), output)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Tarball for com.test.expected contains com.test.other")
+
+		after, err := os.ReadFile(manifestPath)
+		require.NoError(t, err)
+		assert.Equal(t, before, string(after))
+		assert.NoDirExists(t, filepath.Join(projectDir, "Packages", "com.test.other"))
+	})
+
+	t.Run("accepts a matching tarball", func(t *testing.T) {
+		projectDir := t.TempDir()
+		require.NoError(t, setupUnityProject(projectDir))
+
+		output := &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+		require.NoError(t, installPackageWithEngine(engines.NewUnityAdapter(), projectDir, parsePackageSpec(server.URL+"/com.test.other/-/com.test.other-1.0.0.tgz"), output))
+		assert.Equal(t, []string{"com.test.other@1.0.0"}, output.Packages)
+		assert.FileExists(t, filepath.Join(projectDir, "Packages", "com.test.other", "Runtime", "a.cs"))
+	})
+
+	t.Run("rolls back when the manifest is malformed", func(t *testing.T) {
+		projectDir := t.TempDir()
+		require.NoError(t, setupUnityProject(projectDir))
+		manifestPath := filepath.Join(projectDir, "Packages", "manifest.json")
+		require.NoError(t, os.MkdirAll(filepath.Dir(manifestPath), 0755))
+		broken := `{"dependencies": {}, "scopedRegistries": [{"name": "Stale", "url": "https://stale.example.com", "scopes": []}]}`
+		require.NoError(t, os.WriteFile(manifestPath, []byte(broken), 0644))
+
+		output := &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+		err := installPackageWithEngine(engines.NewUnityAdapter(), projectDir, parsePackageSpec(server.URL+"/com.test.other/-/com.test.other-1.0.0.tgz"), output)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `scoped registry "Stale" has no scopes`)
+
+		after, err := os.ReadFile(manifestPath)
+		require.NoError(t, err)
+		assert.Equal(t, broken, string(after))
+		assert.NoDirExists(t, filepath.Join(projectDir, "Packages", "com.test.other"))
+	})
+}
+
+func TestInstallRejectsMismatchedPackageName(t *testing.T) {
+	tarball, _ := writeTestTarball(t, map[string]string{
+		"package/package.json": `{"name": "com.evil.pkg", "version": "1.0.0"}`,
+		"package/Runtime/a.cs": "class A {}",
+	})
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".tgz") {
+			_, _ = w.Write(tarball)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"name":      "com.trusted.pkg",
+			"dist-tags": map[string]string{"latest": "1.0.0"},
+			"versions": map[string]interface{}{
+				"1.0.0": map[string]interface{}{
+					"name":    "com.trusted.pkg",
+					"version": "1.0.0",
+					"dist":    map[string]string{"tarball": server.URL + "/com.trusted.pkg/-/com.trusted.pkg-1.0.0.tgz"},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	allowLoopbackTarballs(t)
+	config.SetConfigForTesting(&config.Config{Registry: server.URL})
+	defer config.ResetConfigForTesting()
+
+	projectDir := t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(projectDir))
+	defer func() { _ = os.Chdir(oldWd) }()
+	manifestPath := filepath.Join(projectDir, "Packages", "manifest.json")
+
+	t.Run("rejected", func(t *testing.T) {
+		before, _ := os.ReadFile(manifestPath)
+		var err error
+		captureStdout(t, func() error {
+			err = downloadAndInstallPackage("com.trusted.pkg", "1.0.0", false, &InstallOutput{})
+			return nil
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Tarball for com.trusted.pkg@1.0.0 contains com.evil.pkg@1.0.0")
+		assert.NoDirExists(t, filepath.Join(projectDir, "Packages", "com.trusted.pkg"))
+		after, _ := os.ReadFile(manifestPath)
+		assert.Equal(t, string(before), string(after))
+	})
+
+	t.Run("rejected for inspection", func(t *testing.T) {
+		installNoSave = true
+		installRegistry = server.URL
+		defer func() {
+			installNoSave = false
+			installRegistry = ""
+			installDownloads.clear()
+		}()
+		output := &InstallOutput{Packages: []string{}}
+		var err error
+		captureStdout(t, func() error {
+			err = installPackageWithEngine(engines.NewUnityAdapter(), projectDir, parsePackageSpec("com.trusted.pkg@1.0.0"), output)
+			return nil
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Tarball for com.trusted.pkg@1.0.0 contains com.evil.pkg@1.0.0")
+		assert.Empty(t, output.Inspect)
+	})
+
+	t.Run("accepted with --force", func(t *testing.T) {
+		installForce = true
+		defer func() { installForce = false }()
+		out := captureStdout(t, func() error {
+			return downloadAndInstallPackage("com.trusted.pkg", "1.0.0", false, &InstallOutput{})
+		})
+		assert.Contains(t, out, "installing it anyway because of --force")
+		assert.FileExists(t, filepath.Join(projectDir, "Packages", "com.trusted.pkg", "Runtime", "a.cs"))
+	})
+}
+
+func TestTarballURLPackageName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://registry.gpm.sh/com.test.pkg/-/com.test.pkg-1.0.0.tgz", "com.test.pkg"},
+		{"https://registry.gpm.sh/npm/com.test.pkg/-/com.test.pkg-1.0.0.tgz#sha512-abc", "com.test.pkg"},
+		{"https://registry.npmjs.org/@scope/pkg/-/pkg-2.0.0.tgz", "@scope/pkg"},
+		{"https://cdn.gpm.sh/tarballs/com.test.pkg-1.0.0.tgz", ""},
+		{"https://registry.gpm.sh/com.test.pkg/-/other-1.0.0.tgz", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tarballURLPackageName(tt.url), tt.url)
+	}
+}
+
+func TestInstallNoSaveLeavesManifestUnchanged(t *testing.T) {
+	tarball, _ := writeTestTarball(t, map[string]string{
+		"package/package.json": `{"name": "com.test.trial", "version": "0.3.0"}`,
+		"package/Runtime/a.cs": "class A {}",
+	})
+
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.test.addon", &api.PackageMetadata{
+		Name:     "com.test.addon",
+		DistTags: map[string]string{"latest": "1.0.0"},
+		Versions: map[string]*api.PackageVersion{"1.0.0": {Name: "com.test.addon", Version: "1.0.0"}},
+	})
+	addon, addonIntegrity := writeTestTarball(t, map[string]string{
+		"package/package.json": `{"name": "com.test.addon", "version": "1.0.0"}`,
+		"package/plugin.cfg":   "[plugin]\n",
+	})
+	mockRegistry.AddTarball("com.test.addon", "1.0.0", addon, addonIntegrity)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(tarball)
+	}))
+	defer server.Close()
+
+	allowLoopbackTarballs(t)
+	defer func() {
+		installNoSave = false
+		installSave = true
+		installRegistry = ""
+	}()
+	defer installDownloads.clear()
+
+	t.Run("unity downloads for inspection", func(t *testing.T) {
+		installNoSave = true
+		installRegistry = server.URL
+		projectDir := t.TempDir()
+		require.NoError(t, setupUnityProject(projectDir))
+		manifestPath := filepath.Join(projectDir, "Packages", "manifest.json")
+		require.NoError(t, os.MkdirAll(filepath.Dir(manifestPath), 0755))
+		before := []byte("{\n    \"dependencies\": {}\n}\n")
+		require.NoError(t, os.WriteFile(manifestPath, before, 0644))
+
+		output := &InstallOutput{Packages: []string{}}
+		spec := parsePackageSpec(server.URL + "/com.test.trial/-/com.test.trial-0.3.0.tgz")
+		require.NoError(t, installPackageWithEngine(engines.NewUnityAdapter(), projectDir, spec, output))
+
+		after, err := os.ReadFile(manifestPath)
+		require.NoError(t, err)
+		assert.Equal(t, string(before), string(after))
+		assert.NoDirExists(t, filepath.Join(projectDir, "Packages", "com.test.trial"))
+
+		dir := output.Inspect["com.test.trial@0.3.0"]
+		require.NotEmpty(t, dir)
+		defer func() { _ = os.RemoveAll(dir) }()
+		assert.FileExists(t, filepath.Join(dir, "Runtime", "a.cs"))
+	})
+
+	t.Run("godot extracts the addon with --save=false", func(t *testing.T) {
+		installNoSave = false
+		installSave = false
+		installRegistry = mockRegistry.URL()
+		projectDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "project.godot"), []byte("config_version=5\n"), 0644))
+
+		output := &InstallOutput{Packages: []string{}}
+		require.NoError(t, installPackageWithEngine(engines.NewGodotAdapter(), projectDir, parsePackageSpec("com.test.addon@1.0.0"), output))
+
+		assert.NoFileExists(t, filepath.Join(projectDir, engines.GodotManifestFile))
+		assert.FileExists(t, filepath.Join(projectDir, engines.GodotAddonsDir, "com.test.addon", "plugin.cfg"))
+	})
+}
+
+func TestInstallIntoSeveralProjectsDownloadsOnce(t *testing.T) {
+	tarball, _ := writeTestTarball(t, map[string]string{
+		"package/package.json": `{"name": "com.test.shared", "version": "2.0.0"}`,
+		"package/Runtime/a.cs": "class A {}",
+	})
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write(tarball)
+	}))
+	defer server.Close()
+
+	unityDir := t.TempDir()
+	require.NoError(t, setupUnityProject(unityDir))
+	godotDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(godotDir, "project.godot"), []byte("config_version=5\n"), 0644))
+
+	allowLoopbackTarballs(t)
+	installRegistry = server.URL
+	installProjects = []string{unityDir, godotDir}
+	installJSON = true
+	defer func() {
+		installRegistry = ""
+		installProjects = nil
+		installJSON = false
+	}()
+
+	require.NoError(t, install(installCmd, []string{server.URL + "/com.test.shared/-/com.test.shared-2.0.0.tgz"}))
+
+	assert.Equal(t, int32(1), fetches.Load(), "the tarball is downloaded once for both projects")
+	assert.FileExists(t, filepath.Join(unityDir, "Packages", "com.test.shared", "Runtime", "a.cs"))
+	assert.FileExists(t, filepath.Join(godotDir, engines.GodotAddonsDir, "com.test.shared", "Runtime", "a.cs"))
+	assert.Empty(t, installDownloads.files, "downloads are removed when the command finishes")
+}
+
+func TestInstallCI(t *testing.T) {
+	projectDir := t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+	packagesDir := filepath.Join(projectDir, "Packages")
+	require.NoError(t, os.MkdirAll(packagesDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(packagesDir, "manifest.json"), []byte(`{
+  "dependencies": {
+    "com.studio.core": "1.2.0",
+    "com.unity.modules.audio": "1.0.0"
+  }
+}
+`), 0644))
+	cachedFile := filepath.Join(projectDir, "Library", "PackageCache", "com.studio.core@1.1.0", "package.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(cachedFile), 0755))
+	require.NoError(t, os.WriteFile(cachedFile, []byte(`{}`), 0644))
+
+	installCI = true
+	installProjectDir = projectDir
+	installJSON = true
+	defer func() {
+		installCI = false
+		installProjectDir = ""
+		installJSON = false
+	}()
+
+	t.Run("fails without a lockfile", func(t *testing.T) {
+		err := install(installCmd, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "packages-lock.json")
+		assert.FileExists(t, cachedFile)
+	})
+
+	lockPath := filepath.Join(packagesDir, "packages-lock.json")
+	writeLock := func(coreVersion string) []byte {
+		lock := []byte(`{
+  "dependencies": {
+    "com.studio.core": {"version": "` + coreVersion + `", "depth": 0, "source": "registry", "dependencies": {}},
+    "com.studio.embedded": {"version": "file:com.studio.embedded", "depth": 0, "source": "embedded", "dependencies": {}},
+    "com.unity.modules.audio": {"version": "1.0.0", "depth": 0, "source": "builtin", "dependencies": {}}
+  }
+}
+`)
+		require.NoError(t, os.WriteFile(lockPath, lock, 0644))
+		return lock
+	}
+
+	t.Run("fails when the lock disagrees with the manifest", func(t *testing.T) {
+		writeLock("1.1.0")
+		err := install(installCmd, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "disagree on 1 package")
+		assert.FileExists(t, cachedFile)
+	})
+
+	t.Run("rejects package arguments", func(t *testing.T) {
+		assert.Error(t, install(installCmd, []string{"com.studio.ads"}))
+	})
+
+	t.Run("clean install when the lock matches", func(t *testing.T) {
+		lock := writeLock("1.2.0")
+		require.NoError(t, install(installCmd, nil))
+
+		assert.NoDirExists(t, filepath.Join(projectDir, "Library", "PackageCache"))
+		after, err := os.ReadFile(lockPath)
+		require.NoError(t, err)
+		assert.Equal(t, string(lock), string(after), "the lockfile is never rewritten")
+	})
+}
+
+func TestInstallResolvesRange(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.test.ranged", &api.PackageMetadata{
+		Name:     "com.test.ranged",
+		DistTags: map[string]string{"latest": "2.0.0"},
+		Versions: map[string]*api.PackageVersion{
+			"1.2.0": {Name: "com.test.ranged", Version: "1.2.0"},
+			"1.3.0": {Name: "com.test.ranged", Version: "1.3.0"},
+			"2.0.0": {Name: "com.test.ranged", Version: "2.0.0"},
+		},
+	})
+	installRegistry = mockRegistry.URL()
+	defer func() { installRegistry = "" }()
+
+	projectDir := t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+	output := &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+	require.NoError(t, installPackageWithEngine(engines.NewUnityAdapter(), projectDir, parsePackageSpec("com.test.ranged@^1.2.0"), output))
+	assert.Equal(t, []string{"com.test.ranged@1.3.0"}, output.Packages)
+
+	manifest, err := os.ReadFile(filepath.Join(projectDir, "Packages", "manifest.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(manifest), `"com.test.ranged": "1.3.0"`)
+	ranges, err := engines.NewUnityAdapter().RequestedRanges(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"com.test.ranged": "^1.2.0"}, ranges)
+	origins, err := engines.NewUnityAdapter().PackageOrigins(projectDir)
+	require.NoError(t, err)
+	assert.Equal(t, engines.PackageOrigin{Registry: mockRegistry.URL(), Range: "^1.2.0", Version: "1.3.0"}, origins["com.test.ranged"])
+}
+
+func TestFindHighestVersionPrefersStable(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		want     string
+	}{
+		{"prerelease above stable", []string{"1.0.0", "2.0.0-beta.1", "1.5.0"}, "1.5.0"},
+		{"release above its prereleases", []string{"2.0.0-rc.1", "2.0.0", "2.0.0-rc.2"}, "2.0.0"},
+		{"only prereleases", []string{"1.0.0-alpha", "1.0.0-beta.2", "1.0.0-beta.10"}, "1.0.0-beta.10"},
+		{"numeric components", []string{"1.9.0", "1.10.0", "1.2.0"}, "1.10.0"},
+		{"build metadata ties", []string{"1.0.0+b", "1.0.0+a", "0.9.0"}, "1.0.0+b"},
+		{"invalid versions skipped", []string{"next", "0.1.0"}, "0.1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findHighestVersion(tt.versions)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := findHighestVersion([]string{"next"})
+	assert.Error(t, err)
+}
+
+func TestResolveLatestWithoutDistTag(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	mockRegistry.AddPackage("com.test.untagged", &api.PackageMetadata{
+		Name: "com.test.untagged",
+		Versions: map[string]*api.PackageVersion{
+			"1.0.0":        {Name: "com.test.untagged", Version: "1.0.0"},
+			"1.1.0":        {Name: "com.test.untagged", Version: "1.1.0"},
+			"2.0.0-beta.1": {Name: "com.test.untagged", Version: "2.0.0-beta.1"},
+		},
+	})
+	mockRegistry.AddPackage("com.test.preview", &api.PackageMetadata{
+		Name: "com.test.preview",
+		Versions: map[string]*api.PackageVersion{
+			"0.1.0-preview.2":  {Name: "com.test.preview", Version: "0.1.0-preview.2"},
+			"0.1.0-preview.10": {Name: "com.test.preview", Version: "0.1.0-preview.10"},
+		},
+	})
+	noColor := styling.NoColor
+	styling.NoColor = true
+	installVerbose = true
+	allowLoopbackTarballs(t)
+	defer func() {
+		styling.NoColor = noColor
+		installVerbose = false
+	}()
+
+	client := api.NewClient(mockRegistry.URL(), "")
+	var version string
+	out := captureStdout(t, func() error {
+		var err error
+		version, err = resolveInstallLatest(client, "com.test.untagged", &InstallOutput{})
+		return err
+	})
+	assert.Equal(t, "1.1.0", version, "a prerelease is never picked over a stable version")
+	assert.Contains(t, out, "using the highest stable version (1.1.0)")
+
+	out = captureStdout(t, func() error {
+		var err error
+		version, err = resolveInstallLatest(client, "com.test.preview", &InstallOutput{})
+		return err
+	})
+	assert.Equal(t, "0.1.0-preview.10", version)
+	assert.Contains(t, out, "using the highest prerelease")
+}
+
+func TestResolveInstallLatestSkipsYanked(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(&api.PackageMetadata{
+			Name:     "com.test.private",
+			DistTags: map[string]string{"latest": "1.1.0"},
+			Versions: map[string]*api.PackageVersion{
+				"1.0.0": {Name: "com.test.private", Version: "1.0.0"},
+				"1.1.0": {Name: "com.test.private", Version: "1.1.0", GPM: &api.GPMVersionInfo{Yanked: true}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	installJSON = true
+	defer func() { installJSON = false }()
+
+	output := &InstallOutput{}
+	var version string
+	out := captureStdout(t, func() error {
+		var err error
+		version, err = resolveInstallLatest(api.NewClient(server.URL, "secret-token"), "com.test.private", output)
+		return err
+	})
+	assert.Equal(t, "1.0.0", version)
+	assert.Equal(t, "Bearer secret-token", authorization, "private registries get the token")
+	assert.Equal(t, []string{"com.test.private@1.1.0 has been yanked; using 1.0.0 instead"}, output.Warnings)
+	assert.Empty(t, out, "--json output carries the warning instead of stdout")
+}
+
+func TestInstallRequestsAbbreviatedMetadata(t *testing.T) {
+	var fullRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			// Registry reachability check
+			return
+		}
+		if !strings.Contains(r.Header.Get("Accept"), api.AbbreviatedMetadataType) {
+			fullRequests.Add(1)
+			http.Error(w, "full packument requested", http.StatusInternalServerError)
+			return
+		}
+		// The yanked 0.9.0 shows this registry keeps yank flags in the
+		// abbreviated packument, so nothing needs the full one
+		w.Header().Set("Content-Type", api.AbbreviatedMetadataType)
+		_, _ = w.Write([]byte(`{"name": "com.test.slim", "modified": "2024-01-01T00:00:00Z", "dist-tags": {"latest": "1.1.0"}, "versions": {` +
+			`"0.9.0": {"name": "com.test.slim", "version": "0.9.0", "gpm": {"yanked": true}, "dist": {"tarball": "https://example.com/slim-0.9.0.tgz"}},` +
+			`"1.0.0": {"name": "com.test.slim", "version": "1.0.0", "dist": {"tarball": "https://example.com/slim-1.0.0.tgz"}},` +
+			`"1.1.0": {"name": "com.test.slim", "version": "1.1.0", "engines": {"unity": ">=2021.3"}, "dist": {"tarball": "https://example.com/slim-1.1.0.tgz"}}}}`))
+	}))
+	defer server.Close()
+
+	allowLoopbackTarballs(t)
+	installRegistry = server.URL
+	defer func() { installRegistry = "" }()
+
+	for _, spec := range []string{"com.test.slim@^1.0.0", "com.test.slim", "com.test.slim@1.0.0"} {
+		t.Run(spec, func(t *testing.T) {
+			projectDir := t.TempDir()
+			require.NoError(t, setupUnityProject(projectDir))
+			output := &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+			captureStdout(t, func() error {
+				return installPackageWithEngine(engines.NewUnityAdapter(), projectDir, parsePackageSpec(spec), output)
+			})
+			require.Len(t, output.Packages, 1)
+			assert.Equal(t, int32(0), fullRequests.Load(), "resolving and checking a package only needs the abbreviated packument")
+		})
+	}
+}
+
+func TestInstallCheckReportsDrift(t *testing.T) {
+	projectDir := t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "Packages"), 0755))
+	manifest := `{"dependencies": {"com.test.behind": "1.2.0", "com.test.pinned": "1.0.0", "com.test.tagged": "0.9.0", "com.test.recorded": "1.2.0"}}`
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Packages", "manifest.json"), []byte(manifest), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "package.json"), []byte(`{
+		"dependencies": {"com.test.behind": "^1.3.0", "com.test.pinned": "1.0.0", "com.test.missing": "^2.0.0"},
+		"devDependencies": {"com.test.tagged": "beta"}
+	}`), 0644))
+	// A range recorded by an earlier install, which the manifest was since
+	// hand-edited away from
+	require.NoError(t, engines.NewUnityAdapter().SetPackageOrigin(projectDir, "com.test.recorded", &engines.PackageOrigin{Range: "~1.1.0", Version: "1.1.4"}))
+
+	installProjectDir = projectDir
+	installJSON = true
+	installCheck = true
+	defer func() {
+		installProjectDir = ""
+		installJSON = false
+		installCheck = false
+	}()
+
+	var err error
+	out := captureStdout(t, func() error {
+		err = install(installCmd, nil)
+		return nil
+	})
+	require.Error(t, err, "drift fails the command for CI gating")
+	assert.Contains(t, err.Error(), "3 package(s) drifted")
+	assert.Contains(t, err.Error(), "gpm install com.test.behind@^1.3.0 com.test.missing@^2.0.0 com.test.recorded@~1.1.0")
+
+	var output InstallOutput
+	require.NoError(t, json.Unmarshal([]byte(out), &output))
+	assert.False(t, output.Success)
+	assert.Equal(t, []PackageDrift{
+		{Package: "com.test.behind", Declared: "^1.3.0", Installed: "1.2.0", Source: "package.json"},
+		{Package: "com.test.missing", Declared: "^2.0.0", Source: "package.json"},
+		{Package: "com.test.recorded", Declared: "~1.1.0", Installed: "1.2.0", Source: engines.UnityPackagesFile},
+	}, output.Drift)
+
+	data, readErr := os.ReadFile(filepath.Join(projectDir, "Packages", "manifest.json"))
+	require.NoError(t, readErr)
+	assert.Equal(t, manifest, string(data), "--check changes nothing")
+
+	t.Run("no drift", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Packages", "manifest.json"),
+			[]byte(`{"dependencies": {"com.test.behind": "1.3.2", "com.test.pinned": "1.0.0", "com.test.missing": "2.0.0", "com.test.recorded": "1.1.4"}}`), 0644))
+		out := captureStdout(t, func() error { return install(installCmd, nil) })
+		var output InstallOutput
+		require.NoError(t, json.Unmarshal([]byte(out), &output))
+		assert.True(t, output.Success)
+		assert.Empty(t, output.Drift)
+	})
+}
+
+func TestInstallJSONStream(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	for _, name := range []string{"com.test.first", "com.test.second"} {
+		mockRegistry.AddPackage(name, &api.PackageMetadata{
+			Name:     name,
+			DistTags: map[string]string{"latest": "1.0.0"},
+			Versions: map[string]*api.PackageVersion{
+				"1.0.0": {Name: name, Version: "1.0.0"},
+			},
+		})
+	}
+
+	projectDir := t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+	installRegistry = mockRegistry.URL()
+	installProjectDir = projectDir
+	installJSONStream = true
+	defer func() {
+		installRegistry = ""
+		installProjectDir = ""
+		installJSONStream = false
+	}()
+
+	out := captureStdout(t, func() error {
+		return install(installCmd, []string{"com.test.first@1.0.0", "com.test.second@^1.0.0"})
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	var events []StreamEvent
+	for _, line := range lines[:len(lines)-1] {
+		var event StreamEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &event), "every line is a JSON object: %s", line)
+		events = append(events, event)
+	}
+	assert.Equal(t, []StreamEvent{
+		{Event: EventStarted, Package: "com.test.first@1.0.0", Project: projectDir},
+		{Event: EventResolved, Package: "com.test.first", Version: "1.0.0", Project: projectDir},
+		{Event: EventInstalled, Package: "com.test.first", Version: "1.0.0", Project: projectDir},
+		{Event: EventStarted, Package: "com.test.second@^1.0.0", Project: projectDir},
+		{Event: EventResolved, Package: "com.test.second", Version: "1.0.0", Project: projectDir},
+		{Event: EventInstalled, Package: "com.test.second", Version: "1.0.0", Project: projectDir},
+	}, events)
+
+	var summary StreamSummary
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &summary))
+	assert.Equal(t, StreamSummary{
+		Event:     EventSummary,
+		Success:   true,
+		Succeeded: 2,
+		Packages:  []string{"com.test.first@1.0.0", "com.test.second@1.0.0"},
+	}, summary)
+}
+
+func TestInstallAllowlistRejectsUnlistedPackage(t *testing.T) {
+	mockRegistry := NewMockRegistry()
+	defer mockRegistry.Close()
+	for _, name := range []string{"com.homa.analytics", "com.other.ads"} {
+		mockRegistry.AddPackage(name, &api.PackageMetadata{
+			Name:     name,
+			DistTags: map[string]string{"latest": "1.0.0"},
+			Versions: map[string]*api.PackageVersion{
+				"1.0.0": {Name: name, Version: "1.0.0"},
+			},
+		})
+	}
+	config.SetConfigForTesting(&config.Config{Allowlist: []string{"com.homa"}})
+	defer config.ResetConfigForTesting()
+
+	projectDir := t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+	installRegistry = mockRegistry.URL()
+	installProjectDir = projectDir
+	installJSON = true
+	defer func() {
+		installRegistry = ""
+		installProjectDir = ""
+		installJSON = false
+	}()
+
+	var err error
+	out := captureStdout(t, func() error {
+		err = install(installCmd, []string{"com.homa.analytics@1.0.0", "com.other.ads@1.0.0"})
+		return nil
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "package com.other.ads is not on the allowlist")
+
+	var output InstallOutput
+	require.NoError(t, json.Unmarshal([]byte(out), &output), out)
+	assert.False(t, output.Success)
+	assert.Equal(t, &config.PolicyError{Package: "com.other.ads"}, output.Policy)
+	assert.Equal(t, []string{"com.homa.analytics@1.0.0"}, output.Packages, "the listed package is still installed")
+
+	manifest, readErr := os.ReadFile(filepath.Join(projectDir, "Packages", "manifest.json"))
+	require.NoError(t, readErr)
+	assert.NotContains(t, string(manifest), "com.other.ads")
+}
+
+func TestInstallRegistryFallback(t *testing.T) {
+	studio := NewMockRegistry()
+	defer studio.Close()
+	public := NewMockRegistry()
+	defer public.Close()
+	public.AddPackage("com.public.tools", &api.PackageMetadata{
+		Name:     "com.public.tools",
+		DistTags: map[string]string{"latest": "1.0.0"},
+		Versions: map[string]*api.PackageVersion{
+			"1.0.0": {Name: "com.public.tools", Version: "1.0.0"},
+		},
+	})
+	var leakedToken atomic.Bool
+	publicServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			leakedToken.Store(true)
+		}
+		public.handler(w, r)
+	}))
+	defer publicServer.Close()
+
+	config.SetConfigForTesting(&config.Config{
+		Registry:          studio.URL(),
+		Token:             "studio-token",
+		RegistryFallbacks: []string{publicServer.URL},
+	})
+	defer config.ResetConfigForTesting()
+
+	projectDir := t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+	installProjectDir = projectDir
+	installJSON = true
+	defer func() {
+		installProjectDir = ""
+		installJSON = false
+	}()
+
+	out := captureStdout(t, func() error {
+		return install(installCmd, []string{"com.public.tools@1.0.0"})
+	})
+	var output InstallOutput
+	require.NoError(t, json.Unmarshal([]byte(out), &output), out)
+	assert.True(t, output.Success)
+	assert.Equal(t, map[string]string{"com.public.tools": publicServer.URL}, output.Registries)
+	assert.False(t, leakedToken.Load(), "the studio token must not be sent to a fallback registry")
+
+	manifest, err := os.ReadFile(filepath.Join(projectDir, "Packages", "manifest.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(manifest), `"com.public.tools": "1.0.0"`)
+
+	t.Run("missing everywhere", func(t *testing.T) {
+		installJSON = false
+		defer func() { installJSON = true }()
+		err := install(installCmd, []string{"com.nowhere.pkg@1.0.0"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found in any registry")
+		assert.Contains(t, err.Error(), studio.URL())
+		assert.Contains(t, err.Error(), publicServer.URL)
+	})
+}
+
+func TestRegistryToken(t *testing.T) {
+	config.SetConfigForTesting(&config.Config{
+		Registry: "https://registry.gpm.sh",
+		Token:    "user-token",
+	})
+	defer config.ResetConfigForTesting()
+
+	tests := []struct {
+		name     string
+		registry string
+		resolved config.Resolved
+		want     string
+	}{
+		{"user registry", "https://registry.gpm.sh", config.Resolved{Value: "https://registry.gpm.sh", Source: config.SourceUser}, "user-token"},
+		{"default registry", "https://registry.gpm.sh/", config.Resolved{Value: "https://registry.gpm.sh/", Source: config.SourceDefault}, "user-token"},
+		{"flag", "https://studio.example", config.Resolved{Value: "https://studio.example", Source: config.SourceFlag}, "user-token"},
+		{"env", "https://studio.example", config.Resolved{Value: "https://studio.example", Source: config.SourceEnv}, "user-token"},
+		{"profile", "https://studio.example", config.Resolved{Value: "https://studio.example", Source: config.SourceProfile}, "user-token"},
+		{"project config elsewhere", "https://evil.example", config.Resolved{Value: "https://evil.example", Source: config.SourceProject}, ""},
+		{"project .npmrc elsewhere", "https://evil.example", config.Resolved{Value: "https://evil.example", Source: config.SourceNpmrc}, ""},
+		{"project config on the token's registry", "https://registry.gpm.sh", config.Resolved{Value: "https://registry.gpm.sh", Source: config.SourceProject}, "user-token"},
+		{"fallback", "https://public.example", config.Resolved{Value: "https://registry.gpm.sh", Source: config.SourceUser}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, registryToken(tt.registry, tt.resolved, t.TempDir()))
+		})
+	}
+}
+
+func TestInstallLinksWorkspacePackages(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"package.json":               `{"name": "studio", "private": true, "workspaces": ["packages/*", "game"]}`,
+		"packages/core/package.json": `{"name": "com.studio.core", "version": "2.3.1"}`,
+		"game/package.json":          `{"name": "com.studio.game", "version": "1.0.0", "dependencies": {"com.studio.core": "workspace:*"}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	gameDir := filepath.Join(root, "game")
+	require.NoError(t, setupUnityProject(gameDir))
+
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(gameDir))
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	require.NoError(t, installFromPackageJSON(&InstallOutput{}))
+
+	data, err := os.ReadFile(filepath.Join(gameDir, "Packages", "manifest.json"))
+	require.NoError(t, err)
+	var manifest struct {
+		Dependencies map[string]string `json:"dependencies"`
+	}
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, "file:../../packages/core", manifest.Dependencies["com.studio.core"], "the sibling is linked, not downloaded")
+}
+
+func BenchmarkFindHighestVersion(b *testing.B) {
+	versions := make([]string, 0, 5000)
+	for i := 0; i < 5000; i++ {
+		versions = append(versions, strconv.Itoa(i/1000)+"."+strconv.Itoa((i/10)%100)+"."+strconv.Itoa(i%10))
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if highest, err := findHighestVersion(versions); err != nil || highest != "4.99.9" {
+			b.Fatalf("got %s, %v", highest, err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// commandCtx is canceled when gpm is interrupted. Downloads, uploads and
+// registry requests use it, so a canceled command returns through its
+// deferred cleanups instead of leaving temporary files behind.
+var commandCtx = context.Background()
+
+// InterruptContext returns a context canceled by the first SIGINT or SIGTERM
+// and makes it the context commands run with. A second signal exits at once,
+// as the handler is released after the first. Call stop when the command is
+// done.
+func InterruptContext() (ctx context.Context, stop context.CancelFunc) {
+	ctx, stop = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	commandCtx = ctx
+	return ctx, stop
+}
+
+// Interrupted reports whether the command was canceled by a signal
+func Interrupted() bool {
+	return commandCtx.Err() != nil
+}
@@ -19,13 +19,31 @@ import (
 )
 
 var (
-	authType string
+	authType      string
+	loginUsername string
+	loginPassword string
+)
+
+const (
+	// UsernameEnv and PasswordEnv supply legacy login credentials when
+	// there is no terminal to prompt on, such as in CI
+	UsernameEnv = "GPM_USERNAME"
+	PasswordEnv = "GPM_PASSWORD"
 )
 
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Login to GPM registry",
-	Long:  `Login to the GPM registry with your credentials`,
+	Long: `Login to the GPM registry with your credentials.
+
+Legacy login prompts for a username and password. Without a terminal (CI,
+scripts) it reads them from ` + UsernameEnv + ` and ` + PasswordEnv + `, or from
+--username and --password.
+
+Examples:
+  gpm login
+  gpm login --auth-type legacy
+  ` + UsernameEnv + `=ci ` + PasswordEnv + `=... gpm login --auth-type legacy`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		switch authType {
 		case "web", "":
@@ -40,21 +58,42 @@ var loginCmd = &cobra.Command{
 
 func init() {
 	loginCmd.Flags().StringVar(&authType, "auth-type", "web", "Authentication type: 'web' (browser-based) or 'legacy' (username/password)")
+	loginCmd.Flags().StringVar(&loginUsername, "username", "", "Username for legacy login (default: $"+UsernameEnv+")")
+	loginCmd.Flags().StringVar(&loginPassword, "password", "", "Password for legacy login; prefer $"+PasswordEnv+", flags can leak into shell history")
 }
 
 func loginCLI() error {
-	cfg := config.GetConfig()
-	client := api.NewClient(cfg.Registry, "")
-
-	reader := bufio.NewReader(os.Stdin)
-
 	fmt.Println(styling.Header("🔐  User Login"))
 	fmt.Println(styling.Separator())
 
-	fmt.Print(styling.Label("Username: "))
-	username, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read username: %w\n\n%s", err, styling.Hint("Try running the command again or check your terminal settings"))
+	username := loginUsername
+	if username == "" {
+		username = os.Getenv(UsernameEnv)
+	}
+	password := loginPassword
+	if password == "" {
+		password = os.Getenv(PasswordEnv)
+	} else {
+		fmt.Println(styling.Warning("⚠ Passing --password exposes it to shell history and process lists; prefer $" + PasswordEnv))
+	}
+
+	if username != "" && password != "" {
+		return loginWithCredentials(strings.TrimSpace(username), password)
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("no terminal to prompt for credentials"),
+			styling.Hint("Set "+UsernameEnv+" and "+PasswordEnv+" to log in non-interactively"))
+	}
+
+	if username == "" {
+		reader := bufio.NewReader(os.Stdin)
+		fmt.Print(styling.Label("Username: "))
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read username: %w\n\n%s", err, styling.Hint("Try running the command again or check your terminal settings"))
+		}
+		username = input
 	}
 	username = strings.TrimSpace(username)
 
@@ -73,14 +112,25 @@ func loginCLI() error {
 		return fmt.Errorf("%s\n\n%s", styling.Error("password is required"), styling.Hint("Please enter your password when prompted"))
 	}
 
-	passwordStr := string(passwordBytes)
 	defer func() {
 		for i := range passwordBytes {
 			passwordBytes[i] = 0
 		}
-		passwordStr = ""
 	}()
 
+	return loginWithCredentials(username, string(passwordBytes))
+}
+
+// loginWithCredentials exchanges a username and password for a token and
+// stores it. The password is never printed.
+func loginWithCredentials(username, passwordStr string) error {
+	if err := validateUsername(username); err != nil {
+		return fmt.Errorf("%s\n\n%s", styling.Error(err.Error()), styling.Hint("Username must be 3-50 characters and contain only letters, numbers, dots, underscores, and hyphens"))
+	}
+
+	cfg := config.GetConfig()
+	client := api.NewClient(cfg.Registry, "")
+
 	fmt.Println(styling.Info("Authenticating..."))
 
 	req := &api.LoginRequest{
@@ -102,7 +152,7 @@ func loginCLI() error {
 	whoamiResp, err := userClient.Whoami()
 	if err == nil {
 		// Only set username if we successfully got fresh info
-		config.SetUsername(whoamiResp.Username)
+		config.CacheUsername(whoamiResp.Username)
 	}
 
 	if err := config.SaveConfig(); err != nil {
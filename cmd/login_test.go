@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 
@@ -117,3 +120,53 @@ func TestLoginCmdStructure(t *testing.T) {
 	require.Len(t, loginSubCmd, 1)
 	assert.Equal(t, "login", loginSubCmd[0].Use)
 }
+
+func TestLoginFromEnvironment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/-/v1/login":
+			var req api.LoginRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Name != "ci-bot" || req.Password != "s3cret-pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(api.LoginResponse{OK: true, Token: "env-token"})
+		case r.Method == "GET" && r.URL.Path == "/-/whoami":
+			_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "ci-bot"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("HOME", t.TempDir())
+	config.InitConfig()
+	config.GetConfig().Registry = server.URL
+
+	// stdin is a pipe, not a terminal, so nothing may be prompted for
+	originalStdin, originalStdout := os.Stdin, os.Stdout
+	stdin, _, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdin = stdin
+	defer func() { os.Stdin = originalStdin }()
+
+	t.Setenv(UsernameEnv, "")
+	t.Setenv(PasswordEnv, "")
+	assert.ErrorContains(t, loginCLI(), "no terminal")
+
+	t.Setenv(UsernameEnv, "ci-bot")
+	t.Setenv(PasswordEnv, "s3cret-pass")
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	err = loginCLI()
+	_ = w.Close()
+	os.Stdout = originalStdout
+	var out bytes.Buffer
+	_, _ = io.Copy(&out, r)
+
+	require.NoError(t, err)
+	assert.Equal(t, "env-token", config.GetToken())
+	assert.Equal(t, "ci-bot", config.GetConfig().Username)
+	assert.NotContains(t, out.String(), "s3cret-pass")
+}
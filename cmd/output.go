@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+var (
+	noColorOutput bool
+	plainOutput   bool
+)
+
+// AddOutputFlags registers the global --no-color and --plain flags on the
+// root command
+func AddOutputFlags(rootCmd *cobra.Command) {
+	rootCmd.PersistentFlags().BoolVar(&noColorOutput, "no-color", false, "Disable colored output (also NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", false, "Plain output for CI logs: no colors, emoji or box drawing, and INFO:/WARN:/ERROR: prefixes (default when CI=true)")
+}
+
+// ConfigureOutput applies --no-color and --plain before a command runs.
+// Plain output is the default when CI=true, as CI services set it. JSON
+// output never goes plain, so error messages and other strings in it are the
+// same everywhere.
+func ConfigureOutput(c *cobra.Command) {
+	if noColorOutput {
+		styling.NoColor = true
+	}
+	plain := os.Getenv("CI") == "true"
+	if f := c.Flags().Lookup("plain"); f != nil && f.Changed {
+		plain = plainOutput
+	}
+	if plain {
+		styling.Plain = true
+	}
+	for _, name := range []string{"json", "json-stream"} {
+		if f := c.Flags().Lookup(name); f != nil && f.Value.String() == "true" {
+			styling.Plain = false
+		}
+	}
+}
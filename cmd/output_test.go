@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+// newOutputTestCommand returns a root with the output flags and a --json
+// subcommand, wired like main's
+func newOutputTestCommand(t *testing.T) *cobra.Command {
+	plain, noColor := styling.Plain, styling.NoColor
+	t.Cleanup(func() {
+		styling.Plain, styling.NoColor = plain, noColor
+		plainOutput, noColorOutput = false, false
+	})
+	styling.Plain = false
+
+	rootCmd := &cobra.Command{
+		Use: "gpm",
+		PersistentPreRun: func(c *cobra.Command, args []string) {
+			ConfigureOutput(c)
+		},
+	}
+	AddOutputFlags(rootCmd)
+	sub := &cobra.Command{Use: "sub", Run: func(*cobra.Command, []string) {}}
+	sub.Flags().Bool("json", false, "")
+	rootCmd.AddCommand(sub)
+	return rootCmd
+}
+
+func TestConfigureOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		ci   string
+		args []string
+		want bool
+	}{
+		{"off by default", "", []string{"sub"}, false},
+		{"flag", "", []string{"--plain", "sub"}, true},
+		{"CI", "true", []string{"sub"}, true},
+		{"CI with --plain=false", "true", []string{"--plain=false", "sub"}, false},
+		{"JSON stays JSON", "true", []string{"sub", "--json"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CI", tt.ci)
+			rootCmd := newOutputTestCommand(t)
+			rootCmd.SetArgs(tt.args)
+			assert.NoError(t, rootCmd.Execute())
+			assert.Equal(t, tt.want, styling.Plain)
+		})
+	}
+}
+
+func TestPlainOutput(t *testing.T) {
+	plain := styling.Plain
+	styling.Plain = true
+	defer func() { styling.Plain = plain }()
+
+	output := captureStdout(t, func() error { return outputDetectionHuman(nil, t.TempDir()) })
+
+	assert.NotContains(t, output, "\033[", "no escape sequences")
+	for _, r := range output {
+		assert.False(t, unicode.Is(unicode.So, r), "unexpected symbol %q in %q", r, output)
+	}
+	assert.True(t, strings.HasPrefix(output, "Game Engine Detection\n"), output)
+	assert.Contains(t, output, "\n"+strings.Repeat("-", 51)+"\n")
+	assert.Contains(t, output, "WARN: No game engine projects detected\n")
+	assert.Contains(t, output, "INFO: GPM looks for:\n")
+	assert.Contains(t, output, "  - Unity: Assets/")
+
+	assert.Equal(t, "|-- com.test.a@1.0.0\n`-- com.test.b@1.0.0\n",
+		dependencyTreeText([]*TreeNode{{Name: "com.test.a", Version: "1.0.0"}, {Name: "com.test.b", Version: "1.0.0"}}))
+}
@@ -1,16 +1,14 @@
 package cmd
 
 import (
-	"archive/tar"
-	"compress/gzip"
-	"crypto/sha1" // #nosec G505 - Required for npm compatibility
-	"crypto/sha512"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -21,11 +19,23 @@ import (
 )
 
 var (
-	packDryRun        bool
-	packJSON          bool
-	packDestination   string
-	packScope         string
-	packIgnoreScripts bool
+	packDryRun         bool
+	packJSON           bool
+	packDestination    string
+	packScope          string
+	packIgnoreScripts  bool
+	packIfPresent      bool
+	packCompareGit     bool
+	packVerbose        bool
+	packStrict         bool
+	packChecksums      string
+	packFollowSymlinks bool
+	packIgnore         []string
+	packNormalizeEOL   bool
+	packScanSecrets    bool
+	packAllowSecrets   bool
+
+	packCompressionLevel int
 )
 
 var packCmd = &cobra.Command{
@@ -45,6 +55,12 @@ Examples:
   gpm pack --dry-run             # Show what would be packed
   gpm pack --json                # Output in JSON format
   gpm pack --pack-destination /tmp  # Output to specific directory
+  gpm pack --if-present          # No-op when there is no package.json
+  gpm pack --compare-git         # Compare packed files with git-tracked files
+  gpm pack --dry-run --verbose   # Explain why each file is included or excluded
+  gpm pack --checksums SHA512SUMS  # Also write a checksums file for 'gpm verify'
+  gpm pack --follow-symlinks     # Pack the targets of symlinks inside the package
+  gpm pack --scan-secrets        # Refuse to pack .env files, keys and tokens
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return packPackages(cmd, args)
@@ -57,6 +73,17 @@ func init() {
 	packCmd.Flags().StringVar(&packDestination, "pack-destination", "", "Specify output directory (default: current directory)")
 	packCmd.Flags().StringVar(&packScope, "scope", "", "Scope for scoped packages (e.g., @myscope)")
 	packCmd.Flags().BoolVar(&packIgnoreScripts, "ignore-scripts", false, "Skip running package scripts during packing")
+	packCmd.Flags().IntVar(&packCompressionLevel, "compression-level", -1, "Gzip level 0-9 for the tarball; affects size only (default: config compression_level or 6)")
+	packCmd.Flags().BoolVar(&packIfPresent, "if-present", false, "Skip package specs without a package.json instead of failing")
+	packCmd.Flags().BoolVar(&packCompareGit, "compare-git", false, "List untracked files that would be packed and tracked files that would not (implies --dry-run)")
+	packCmd.Flags().BoolVarP(&packVerbose, "verbose", "v", false, "Show which rule included or excluded each file")
+	packCmd.Flags().BoolVar(&packStrict, "strict", false, "Fail instead of warning when a files entry matches nothing")
+	packCmd.Flags().StringVar(&packChecksums, "checksums", "", "Write the sha1, sha512 and integrity of each tarball to this file")
+	packCmd.Flags().BoolVar(&packFollowSymlinks, "follow-symlinks", false, "Pack the files symlinks point at, as long as they stay inside the package (default: skip symlinks)")
+	packCmd.Flags().BoolVar(&packNormalizeEOL, "normalize-eol", false, "Pack text files with LF line endings (default: only files .gitattributes marks as text)")
+	packCmd.Flags().BoolVar(&packScanSecrets, "scan-secrets", false, "Fail when packed files look like they contain secrets: .env files, known key formats, high-entropy strings (default under --strict)")
+	packCmd.Flags().BoolVar(&packAllowSecrets, "allow-secrets", false, "Report secrets found by the scan as warnings instead of failing")
+	packCmd.Flags().StringArrayVar(&packIgnore, "ignore", nil, "Leave out files matching this .gpmignore-style pattern (repeatable)")
 }
 
 type PackResult struct {
@@ -70,12 +97,24 @@ type PackResult struct {
 	Sha1         string   `json:"sha1"`
 	Sha512       string   `json:"sha512"`
 	Integrity    string   `json:"integrity"`
+	// EstimatedPackedSize is set by dry runs, which compress the files
+	// in memory instead of writing a tarball
+	EstimatedPackedSize int64 `json:"estimatedPackedSize,omitempty"`
+	// GitHead is the commit the package was packed from, recorded in its
+	// package.json; empty outside a git repository
+	GitHead string `json:"gitHead,omitempty"`
+	// Git is set with --compare-git
+	Git *filtering.GitComparison `json:"git,omitempty"`
+
+	// path is where the tarball was written, for --checksums
+	path string
 }
 
 type PackOutput struct {
-	Results []PackResult `json:"results,omitempty"`
-	Success bool         `json:"success"`
-	Error   string       `json:"error,omitempty"`
+	Results  []PackResult `json:"results,omitempty"`
+	Success  bool         `json:"success"`
+	Warnings []string     `json:"warnings,omitempty"`
+	Error    string       `json:"error,omitempty"`
 }
 
 func packPackages(cmd *cobra.Command, args []string) error {
@@ -92,10 +131,17 @@ func packPackages(cmd *cobra.Command, args []string) error {
 		pkg          *validation.PackageJSON
 		sourceDir    string
 		filterResult *filtering.FilterResult
+		eol          *packaging.LineEndings
+		git          *filtering.GitComparison
 	}
 
 	var manifests []packageManifest
 	var validationErrors []string
+	var warnings []string
+
+	if packChecksums != "" && (packDryRun || packCompareGit) {
+		return fmt.Errorf("--checksums needs real tarballs; drop --dry-run and --compare-git")
+	}
 
 	// First pass: validate all packages
 	for _, spec := range packageSpecs {
@@ -106,6 +152,12 @@ func packPackages(cmd *cobra.Command, args []string) error {
 		}
 
 		if specType == "folder_no_package_json" {
+			if packIfPresent {
+				if !packJSON {
+					printIfPresentSkip("pack", spec)
+				}
+				continue
+			}
 			validationErrors = append(validationErrors, fmt.Sprintf("%s: no package.json found", spec))
 			continue
 		}
@@ -145,6 +197,11 @@ func packPackages(cmd *cobra.Command, args []string) error {
 			validationErrors = append(validationErrors, fmt.Sprintf("%s: failed to create file filter: %v", spec, err))
 			continue
 		}
+		filterEngine.SetFollowSymlinks(packFollowSymlinks)
+		if err := filterEngine.AddIgnorePatterns(packIgnore); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("%s: %v", spec, err))
+			continue
+		}
 
 		filterResult, err := filterEngine.FilterFiles()
 		if err != nil {
@@ -152,11 +209,63 @@ func packPackages(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
+		if packVerbose && !packJSON {
+			printFilterDecisions(filterResult)
+		}
+
+		if packStrict && len(filterResult.UnmatchedFiles) > 0 {
+			validationErrors = append(validationErrors, fmt.Sprintf("%s: %s", spec, unmatchedFilesMessage(filterResult.UnmatchedFiles)))
+			continue
+		}
+
+		var secretFindings []filtering.SecretFinding
+		if packScanSecrets || packStrict {
+			if secretFindings, err = filtering.ScanSecrets(filterResult); err != nil {
+				validationErrors = append(validationErrors, fmt.Sprintf("%s: %v", spec, err))
+				continue
+			}
+			if len(secretFindings) > 0 && !packAllowSecrets {
+				validationErrors = append(validationErrors, fmt.Sprintf("%s: %s", spec, secretsMessage(secretFindings)))
+				continue
+			}
+		}
+
+		eol, err := packaging.NewLineEndings(spec, packNormalizeEOL)
+		if err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("%s: %v", spec, err))
+			continue
+		}
+
+		var gitComparison *filtering.GitComparison
+		if packCompareGit {
+			if !filtering.IsGitRepo(spec) {
+				validationErrors = append(validationErrors, fmt.Sprintf("%s: --compare-git requires the package to be inside a git repository", spec))
+				continue
+			}
+			gitComparison, err = filtering.CompareWithGit(spec, filterResult)
+			if err != nil {
+				validationErrors = append(validationErrors, fmt.Sprintf("%s: failed to compare with git: %v", spec, err))
+				continue
+			}
+		}
+
+		for _, warning := range validationResult.Warnings {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", validationResult.Package.Name, warning))
+		}
+		for _, warning := range filterResult.Warnings {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", validationResult.Package.Name, warning))
+		}
+		for _, finding := range secretFindings {
+			warnings = append(warnings, fmt.Sprintf("%s: possible secret in %s", validationResult.Package.Name, finding))
+		}
+
 		manifests = append(manifests, packageManifest{
 			spec:         spec,
 			pkg:          validationResult.Package,
 			sourceDir:    spec,
 			filterResult: filterResult,
+			eol:          eol,
+			git:          gitComparison,
 		})
 	}
 
@@ -180,10 +289,10 @@ func packPackages(cmd *cobra.Command, args []string) error {
 		var result *PackResult
 		var err error
 
-		if packDryRun {
-			result, err = createDryRunResult(manifest.pkg, manifest.filterResult)
+		if packDryRun || packCompareGit {
+			result, err = createDryRunResult(manifest.sourceDir, manifest.pkg, manifest.filterResult, manifest.eol)
 		} else {
-			result, err = createPackage(manifest.sourceDir, manifest.pkg, manifest.filterResult, nil)
+			result, err = createPackage(manifest.sourceDir, manifest.pkg, manifest.filterResult, manifest.eol, nil)
 		}
 
 		if err != nil {
@@ -191,6 +300,13 @@ func packPackages(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
+		if manifest.git != nil {
+			result.Git = manifest.git
+			if !packJSON {
+				printGitComparison(manifest.git)
+			}
+		}
+
 		// npm pack behavior: overwrite if same filename already processed
 		if processedFiles[result.Filename] {
 			// Remove previous result with same filename
@@ -227,10 +343,21 @@ func packPackages(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if packChecksums != "" && len(results) > 0 {
+		entries := make([]ChecksumEntry, 0, len(results))
+		for _, result := range results {
+			entries = append(entries, checksumEntry(packChecksums, result.path, result.Sha1, result.Sha512, result.Integrity))
+		}
+		if err := writeChecksumsFile(packChecksums, entries); err != nil {
+			allErrors = append(allErrors, err.Error())
+		}
+	}
+
 	if packJSON {
 		output := PackOutput{
-			Results: results,
-			Success: len(allErrors) == 0,
+			Results:  results,
+			Success:  len(allErrors) == 0,
+			Warnings: warnings,
 		}
 		if len(allErrors) > 0 {
 			output.Error = strings.Join(allErrors, "; ")
@@ -244,6 +371,11 @@ func packPackages(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Warnings go to stderr so stdout stays a plain list of filenames
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "%s\n", styling.Warning("⚠ "+warning))
+	}
+
 	// npm pack behavior: print filenames to stdout with @ and / replaced (even in dry-run)
 	for _, result := range results {
 		// Match npm: tar.filename.replace(/^@/, '').replace(/\//, '-')
@@ -263,13 +395,14 @@ func packPackages(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func createDryRunResult(pkg *validation.PackageJSON, filterResult *filtering.FilterResult) (*PackResult, error) {
+func createDryRunResult(sourceDir string, pkg *validation.PackageJSON, filterResult *filtering.FilterResult, eol *packaging.LineEndings) (*PackResult, error) {
 	result := &PackResult{
 		Name:         pkg.Name,
 		Version:      pkg.Version,
-		Filename:     fmt.Sprintf("%s-%s.tgz", pkg.Name, pkg.Version),
+		Filename:     packaging.TarballFilename(pkg.Name, pkg.Version),
 		FileCount:    filterResult.FileCount,
 		UnpackedSize: filterResult.TotalSize,
+		GitHead:      filtering.GitHead(sourceDir),
 	}
 
 	for _, file := range filterResult.Files {
@@ -278,6 +411,14 @@ func createDryRunResult(pkg *validation.PackageJSON, filterResult *filtering.Fil
 		}
 	}
 
+	level, err := resolveCompressionLevel(packCompressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	if result.EstimatedPackedSize, err = estimatePackedSize(filterResult, level, eol); err != nil {
+		return nil, fmt.Errorf("failed to estimate packed size: %w", err)
+	}
+
 	if !packJSON {
 		fmt.Println(styling.Header("🧪 Dry Run - Would Pack"))
 		fmt.Println(styling.Separator())
@@ -285,6 +426,7 @@ func createDryRunResult(pkg *validation.PackageJSON, filterResult *filtering.Fil
 		fmt.Printf("%s %s\n", styling.Label("Output:"), styling.File(result.Filename))
 		fmt.Printf("%s %s\n", styling.Label("Files:"), styling.Value(fmt.Sprintf("%d", result.FileCount)))
 		fmt.Printf("%s %s\n", styling.Label("Unpacked Size:"), styling.Size(fmt.Sprintf("%.1f kB", float64(result.UnpackedSize)/1024)))
+		fmt.Printf("%s %s %s\n", styling.Label("Packed Size:"), styling.Size(fmt.Sprintf("%.1f kB", float64(result.EstimatedPackedSize)/1024)), styling.Muted("(estimate)"))
 		fmt.Println(styling.Separator())
 
 		if len(result.Files) > 0 {
@@ -298,7 +440,7 @@ func createDryRunResult(pkg *validation.PackageJSON, filterResult *filtering.Fil
 	return result, nil
 }
 
-func createPackage(sourceDir string, pkg *validation.PackageJSON, filterResult *filtering.FilterResult, cleanup func()) (*PackResult, error) {
+func createPackage(sourceDir string, pkg *validation.PackageJSON, filterResult *filtering.FilterResult, eol *packaging.LineEndings, cleanup func()) (*PackResult, error) {
 	if cleanup != nil {
 		defer cleanup()
 	}
@@ -308,75 +450,29 @@ func createPackage(sourceDir string, pkg *validation.PackageJSON, filterResult *
 		outputDir = "."
 	}
 
-	if !packaging.IsValidPackageNameForFilename(pkg.Name) || !packaging.IsValidVersionForFilename(pkg.Version) {
+	outputFile := packaging.TarballFilename(pkg.Name, pkg.Version)
+	if !packaging.IsValidPackageNameForFilename(strings.TrimSuffix(outputFile, "-"+pkg.Version+".tgz")) || !packaging.IsValidVersionForFilename(pkg.Version) {
 		return nil, fmt.Errorf("invalid package name or version for filename")
 	}
 
-	outputFile := fmt.Sprintf("%s-%s.tgz", pkg.Name, pkg.Version)
 	outputPath := filepath.Join(outputDir, outputFile)
 	cleanOutputPath := filepath.Clean(outputPath)
 
-	file, err := os.Create(cleanOutputPath)
+	level, err := resolveCompressionLevel(packCompressionLevel)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create output file: %w", err)
+		return nil, err
 	}
-	defer func() { _ = file.Close() }()
-
-	gw := gzip.NewWriter(file)
-	defer func() { _ = gw.Close() }()
-
-	tw := tar.NewWriter(gw)
-	defer func() { _ = tw.Close() }()
 
-	sha1Hash := sha1.New() // #nosec G401 - Required for npm compatibility
-	sha512Hash := sha512.New()
-
-	var filePaths []string
-
-	for _, filteredFile := range filterResult.Files {
-		if filteredFile.IsDir {
-			continue
-		}
-
-		filePaths = append(filePaths, filteredFile.RelativePath)
-
-		relativePath := strings.ReplaceAll(filteredFile.RelativePath, "\\", "/")
-
-		info, err := os.Stat(filteredFile.AbsolutePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to stat file %s: %w", filteredFile.RelativePath, err)
-		}
-
-		header, err := tar.FileInfoHeader(info, "")
-		if err != nil {
-			return nil, fmt.Errorf("failed to create tar header: %w", err)
-		}
-
-		header.Name = fmt.Sprintf("package/%s", relativePath)
-		if err := tw.WriteHeader(header); err != nil {
-			return nil, fmt.Errorf("failed to write tar header: %w", err)
-		}
-
-		fileData, err := os.ReadFile(filteredFile.AbsolutePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read file %s: %w", filteredFile.RelativePath, err)
-		}
-
-		if _, err := tw.Write(fileData); err != nil {
-			return nil, fmt.Errorf("failed to write file data: %w", err)
-		}
-
-		sha1Hash.Write(fileData) // #nosec G401 - Required for npm compatibility
-		sha512Hash.Write(fileData)
+	sha1Bytes, sha512Bytes, filePaths, err := writePackageTarball(cleanOutputPath, filterResult, level, eol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tarball: %w", err)
 	}
 
-	fileInfo, err := file.Stat()
+	fileInfo, err := os.Stat(cleanOutputPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file info: %w", err)
 	}
 
-	sha1Bytes := sha1Hash.Sum(nil) // #nosec G401 - Required for npm compatibility
-	sha512Bytes := sha512Hash.Sum(nil)
 	integrity := fmt.Sprintf("sha512-%s", base64.StdEncoding.EncodeToString(sha512Bytes))
 
 	result := &PackResult{
@@ -390,6 +486,8 @@ func createPackage(sourceDir string, pkg *validation.PackageJSON, filterResult *
 		Sha1:         hex.EncodeToString(sha1Bytes), // #nosec G401 - Required for npm compatibility
 		Sha512:       hex.EncodeToString(sha512Bytes),
 		Integrity:    integrity,
+		GitHead:      filtering.GitHead(sourceDir),
+		path:         cleanOutputPath,
 	}
 
 	// Output is handled in packPackages function to match npm behavior
@@ -397,6 +495,91 @@ func createPackage(sourceDir string, pkg *validation.PackageJSON, filterResult *
 	return result, nil
 }
 
+func printGitComparison(comparison *filtering.GitComparison) {
+	fmt.Println(styling.Separator())
+	if len(comparison.Untracked) == 0 && len(comparison.Omitted) == 0 {
+		fmt.Println(styling.Success("✓ Packed files match the files tracked in git"))
+		return
+	}
+
+	if len(comparison.Untracked) > 0 {
+		fmt.Println(styling.Warning("⚠ Included but not tracked in git (possible accidental inclusion):"))
+		for _, file := range comparison.Untracked {
+			fmt.Printf("  %s\n", file)
+		}
+	}
+	if len(comparison.Omitted) > 0 {
+		fmt.Println(styling.Warning("⚠ Tracked in git but excluded (possible omission):"))
+		for _, file := range comparison.Omitted {
+			fmt.Printf("  %s\n", file)
+		}
+	}
+	fmt.Println(styling.Hint("Adjust the files field or .gpmignore if any of these are unintended"))
+}
+
+// unmatchedFilesMessage describes files entries that matched nothing, which
+// --strict turns into an error
+func unmatchedFilesMessage(patterns []string) string {
+	quoted := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		quoted[i] = strconv.Quote(pattern)
+	}
+	return fmt.Sprintf("files entries matched no files: %s", strings.Join(quoted, ", "))
+}
+
+// secretsMessage lists what the secret scan found, one finding per line,
+// and how to get past it
+func secretsMessage(findings []filtering.SecretFinding) string {
+	lines := make([]string, len(findings))
+	for i, finding := range findings {
+		lines[i] = "  " + finding.String()
+	}
+	return fmt.Sprintf("possible secrets in packed files (remove them, leave the files out with --ignore or .gpmignore, or pass --allow-secrets):\n%s", strings.Join(lines, "\n"))
+}
+
+// printFilterDecisions lists each included file with its size and the rule
+// that included it, then the excluded paths and the rule that excluded them.
+// Paths under an excluded directory are folded into that directory.
+func printFilterDecisions(result *filtering.FilterResult) {
+	fmt.Println(styling.Info("🔍 Filter decisions:"))
+	for _, file := range result.Files {
+		if file.IsDir {
+			continue
+		}
+		fmt.Printf("  %s %s %s\n", styling.Success("+"), filepath.ToSlash(file.RelativePath),
+			styling.Muted(fmt.Sprintf("(%s, %s)", formatSize(file.Size), file.Rule)))
+	}
+
+	excluded := make([]string, 0, len(result.ExcludedBy))
+	for path := range result.ExcludedBy {
+		excluded = append(excluded, path)
+	}
+	sort.Strings(excluded)
+
+	folded := make(map[string]int)
+	var shown []string
+	for _, path := range excluded {
+		rule := result.ExcludedBy[path]
+		if len(shown) > 0 {
+			parent := shown[len(shown)-1]
+			if strings.HasPrefix(path, parent+string(filepath.Separator)) && result.ExcludedBy[parent] == rule {
+				folded[parent]++
+				continue
+			}
+		}
+		shown = append(shown, path)
+	}
+
+	for _, path := range shown {
+		line := filepath.ToSlash(path)
+		if count := folded[path]; count > 0 {
+			line += fmt.Sprintf("/ (+%d paths)", count)
+		}
+		fmt.Printf("  %s %s %s\n", styling.Error("-"), line, styling.Muted("("+result.ExcludedBy[path]+")"))
+	}
+	fmt.Println(styling.Separator())
+}
+
 //nolint:unused
 func printPackResult(result PackResult) {
 	fmt.Println(styling.Header("📦  GPM Package Created Successfully"))
@@ -428,11 +611,20 @@ func repackTarball(tarballPath string) (*PackResult, error) {
 		return nil, fmt.Errorf("failed to stat tarball: %w", err)
 	}
 
+	sha1Bytes, sha512Bytes, err := calculateTarballHashes(tarballPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate checksums: %w", err)
+	}
+
 	result := &PackResult{
 		Name:       packageInfo.Name,
 		Version:    packageInfo.Version,
 		Filename:   filepath.Base(tarballPath),
 		PackedSize: info.Size(),
+		Sha1:       hex.EncodeToString(sha1Bytes), // #nosec G401 - Required for npm compatibility
+		Sha512:     hex.EncodeToString(sha512Bytes),
+		Integrity:  "sha512-" + base64.StdEncoding.EncodeToString(sha512Bytes),
+		path:       tarballPath,
 	}
 
 	// Output is handled in packPackages function to match npm behavior
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// defaultPager is used when $PAGER is unset. -F quits straight away if the
+// output fits after all, -R keeps the colors and -X leaves it on screen.
+const defaultPager = "less -FRX"
+
+// stdoutIsTerminal reports whether stdout is an interactive terminal
+var stdoutIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// withPager runs print and, when stdout is a terminal and the output doesn't
+// fit on one screen, shows it through $PAGER. Piped output and output with
+// paging disabled is written straight to stdout, unchanged.
+func withPager(disabled bool, print func() error) error {
+	if disabled || !stdoutIsTerminal() {
+		return print()
+	}
+
+	original := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		return print()
+	}
+	var buf bytes.Buffer
+	copied := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&buf, r)
+		close(copied)
+	}()
+
+	os.Stdout = w
+	printErr := print()
+	_ = w.Close()
+	os.Stdout = original
+	<-copied
+	_ = r.Close()
+
+	if printErr == nil && bytes.Count(buf.Bytes(), []byte("\n")) >= screenHeight(original) {
+		if err := runPager(buf.Bytes(), original); err == nil {
+			return nil
+		}
+	}
+	_, _ = original.Write(buf.Bytes())
+	return printErr
+}
+
+// screenHeight returns the number of rows of the terminal, or 24 when the
+// size can't be read
+func screenHeight(f *os.File) int {
+	_, height, err := term.GetSize(int(f.Fd()))
+	if err != nil || height <= 0 {
+		return 24
+	}
+	return height
+}
+
+// runPager pipes output through $PAGER, falling back to less
+func runPager(output []byte, stdout *os.File) error {
+	pager := strings.TrimSpace(os.Getenv("PAGER"))
+	if pager == "" {
+		pager = defaultPager
+	}
+	fields := strings.Fields(pager)
+	pagerCmd := exec.Command(fields[0], fields[1:]...) // #nosec G204 - pager chosen by the user through $PAGER
+	pagerCmd.Stdin = bytes.NewReader(output)
+	pagerCmd.Stdout = stdout
+	pagerCmd.Stderr = os.Stderr
+	return pagerCmd.Run()
+}
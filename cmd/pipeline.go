@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+)
+
+// Default worker counts of the install pipeline. Downloads wait on the
+// network, so more of them overlap; extraction is bound by the disk.
+const (
+	defaultDownloadConcurrency = 4
+	defaultExtractConcurrency  = 2
+)
+
+// installPipeline downloads and extracts the tarballs of an install ahead of
+// the install loop, in two bounded stages: a pool of download workers feeds a
+// pool of extraction workers, so network-bound downloads overlap with
+// disk-bound extraction without either running unbounded. Only the install
+// loop writes the manifest, one package at a time; when it reaches a package
+// its tarball is waiting, already extracted into a staging directory.
+type installPipeline struct {
+	cancel context.CancelFunc
+	jobs   map[string]*pipelineJob
+	wg     sync.WaitGroup
+}
+
+// pipelineJob is one tarball URL moving through the pipeline. ready is
+// closed once it has been downloaded, and extracted when the pipeline
+// extracts.
+type pipelineJob struct {
+	url         string
+	ready       chan struct{}
+	tarballPath string
+	err         error
+}
+
+// installPrefetch is the pipeline of the project being installed into, nil
+// when tarballs are fetched as the install loop reaches them
+var installPrefetch *installPipeline
+
+// startInstallPipeline starts downloading urls with up to downloads workers,
+// and when extract is set, extracting each finished download with up to
+// extracts workers. Stop must be called once the install loop is done.
+func startInstallPipeline(ctx context.Context, urls []string, registryURL string, downloads, extracts int, extract bool) *installPipeline {
+	ctx, cancel := context.WithCancel(ctx)
+	p := &installPipeline{cancel: cancel, jobs: make(map[string]*pipelineJob, len(urls))}
+
+	downloadQueue := make(chan *pipelineJob, len(urls))
+	for _, url := range urls {
+		if _, ok := p.jobs[url]; ok {
+			continue
+		}
+		job := &pipelineJob{url: url, ready: make(chan struct{})}
+		p.jobs[url] = job
+		downloadQueue <- job
+	}
+	close(downloadQueue)
+
+	// Finished downloads queue up for extraction rather than holding up the
+	// download workers
+	extractQueue := make(chan *pipelineJob, len(p.jobs))
+	var downloaders sync.WaitGroup
+	for i := 0; i < min(downloads, len(p.jobs)); i++ {
+		downloaders.Add(1)
+		go func() {
+			defer downloaders.Done()
+			for job := range downloadQueue {
+				job.tarballPath, job.err = installDownloads.fetch(ctx, job.url, registryURL)
+				if job.err != nil || !extract {
+					close(job.ready)
+					continue
+				}
+				extractQueue <- job
+			}
+		}()
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		downloaders.Wait()
+		close(extractQueue)
+	}()
+
+	for i := 0; i < min(extracts, len(p.jobs)); i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for job := range extractQueue {
+				if ctx.Err() == nil {
+					stagedPackages.stage(job.tarballPath)
+				}
+				close(job.ready)
+			}
+		}()
+	}
+	return p
+}
+
+// wait blocks until the pipeline is done with url, returning its tarball.
+// ok is false when the pipeline wasn't given url.
+func (p *installPipeline) wait(ctx context.Context, url string) (tarballPath string, ok bool, err error) {
+	job, ok := p.jobs[url]
+	if !ok {
+		return "", false, nil
+	}
+	select {
+	case <-job.ready:
+		return job.tarballPath, true, job.err
+	case <-ctx.Done():
+		return "", true, ctx.Err()
+	}
+}
+
+// stop cancels what the install loop no longer needs, waits for the workers
+// and removes extractions nothing claimed
+func (p *installPipeline) stop() {
+	p.cancel()
+	p.wg.Wait()
+	stagedPackages.clear()
+}
+
+// stagedExtractions holds tarballs the pipeline extracted ahead of the
+// install loop, keyed by tarball path
+type stagedExtractions struct {
+	mu   sync.Mutex
+	dirs map[string]string
+}
+
+var stagedPackages = &stagedExtractions{}
+
+// stage extracts tarballPath into a new temporary directory. A tarball that
+// fails to extract isn't staged; installing it extracts it again and reports
+// the problem then.
+func (s *stagedExtractions) stage(tarballPath string) {
+	dir, err := os.MkdirTemp("", "gpm-extract-*")
+	if err != nil {
+		return
+	}
+	packageDir := filepath.Join(dir, "package")
+	file, err := os.Open(tarballPath) // #nosec G304 - temporary file written by fetchTarball
+	if err == nil {
+		err = extractPackageTarball(file, packageDir)
+		_ = file.Close()
+	}
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dirs == nil {
+		s.dirs = make(map[string]string)
+	}
+	s.dirs[tarballPath] = packageDir
+}
+
+// take returns the staged extraction of tarballPath, or "" when there is
+// none. The caller owns the directory from then on.
+func (s *stagedExtractions) take(tarballPath string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dir := s.dirs[tarballPath]
+	delete(s.dirs, tarballPath)
+	return dir
+}
+
+// clear removes every extraction nothing took
+func (s *stagedExtractions) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, dir := range s.dirs {
+		_ = os.RemoveAll(filepath.Dir(dir))
+	}
+	s.dirs = nil
+}
+
+// stagePackage puts the contents of tarballPath in a hidden directory under
+// parentDir, where they can be renamed into the project once the manifest
+// change is known to succeed; a broken tarball fails before anything in the
+// project changes. The pipeline's staged extraction is moved there when
+// there is one. cleanup removes whatever was not moved out.
+func stagePackage(tarballPath, parentDir string) (contentsDir string, cleanup func(), err error) {
+	if err := os.MkdirAll(parentDir, 0750); err != nil {
+		return "", nil, fmt.Errorf("failed to create %s: %w", parentDir, err)
+	}
+	stageDir, err := os.MkdirTemp(parentDir, ".gpm-staging-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	cleanup = func() { _ = os.RemoveAll(stageDir) }
+	contentsDir = filepath.Join(stageDir, "package")
+
+	if staged := stagedPackages.take(tarballPath); staged != "" {
+		err := moveStagedPackage(staged, contentsDir)
+		_ = os.RemoveAll(filepath.Dir(staged))
+		if err == nil {
+			return contentsDir, cleanup, nil
+		}
+	}
+
+	file, err := os.Open(tarballPath) // #nosec G304 - temporary file written by fetchTarball
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to open tarball: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	if err := extractPackageTarball(file, contentsDir); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to extract tarball: %w", err)
+	}
+	return contentsDir, cleanup, nil
+}
+
+// swapPackageDir renames a staged package to packageDir. What packageDir
+// held is moved into the staging directory, so the staging cleanup deletes
+// it; restore puts it back instead.
+func swapPackageDir(contentsDir, packageDir string) (restore func(), err error) {
+	replaced := filepath.Join(filepath.Dir(contentsDir), "replaced")
+	_, statErr := os.Stat(packageDir)
+	hadPackage := statErr == nil
+	if hadPackage {
+		if err := os.Rename(packageDir, replaced); err != nil {
+			return nil, fmt.Errorf("failed to move aside %s: %w", packageDir, err)
+		}
+	}
+	restore = func() {
+		_ = os.RemoveAll(packageDir)
+		if hadPackage {
+			_ = os.Rename(replaced, packageDir)
+		}
+	}
+	if err := os.Rename(contentsDir, packageDir); err != nil {
+		restore()
+		return nil, fmt.Errorf("failed to move package files into %s: %w", packageDir, err)
+	}
+	return restore, nil
+}
+
+// packageStagingDir is where stagePackage should put a package for adapter:
+// next to the Unity manifest, which embedded packages sit beside, or in
+// the Godot addons directory
+func packageStagingDir(adapter engines.EngineAdapter, projectDir string) (string, error) {
+	if unityAdapter, ok := adapter.(*engines.UnityAdapter); ok {
+		manifestPath, err := unityAdapter.ManifestPath(projectDir)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Dir(manifestPath), nil
+	}
+	return filepath.Join(projectDir, engines.GodotAddonsDir), nil
+}
+
+// moveStagedPackage moves a staged extraction to packageDir, copying it when
+// the two are on different filesystems
+func moveStagedPackage(staged, packageDir string) error {
+	if err := os.RemoveAll(packageDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(packageDir), 0750); err != nil {
+		return err
+	}
+	if err := os.Rename(staged, packageDir); err == nil {
+		return nil
+	}
+	if err := copyDir(staged, packageDir); err != nil {
+		_ = os.RemoveAll(packageDir)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+	"gpm.sh/gpm/gpm-cli/internal/store"
+)
+
+// pipelineTarballs serves count package tarballs from the install registry,
+// each response delayed by latency, and returns their URLs. peak records the
+// most requests the server was answering at once.
+func pipelineTarballs(tb testing.TB, count int, latency time.Duration, peak *atomic.Int32) []string {
+	tb.Helper()
+	tarballs := make(map[string][]byte, count)
+	var urls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tarball, ok := tarballs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if peak != nil {
+			inFlight := activePipelineRequests.Add(1)
+			defer activePipelineRequests.Add(-1)
+			for seen := peak.Load(); inFlight > seen && !peak.CompareAndSwap(seen, inFlight); seen = peak.Load() {
+			}
+		}
+		time.Sleep(latency)
+		_, _ = w.Write(tarball)
+	}))
+	tb.Cleanup(server.Close)
+	installRegistry = server.URL
+	tb.Cleanup(func() { installRegistry = "" })
+
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("com.test.pipeline%02d", i)
+		files := map[string]string{"package/package.json": fmt.Sprintf(`{"name": %q, "version": "1.0.0"}`, name)}
+		for j := 0; j < 50; j++ {
+			files[fmt.Sprintf("package/Runtime/File%02d.cs", j)] = strings.Repeat("class A {}\n", 200)
+		}
+		path := "/" + name + "/-/" + name + "-1.0.0.tgz"
+		tarballs[path], _ = writeTestTarball(tb, files)
+		urls = append(urls, server.URL+path)
+	}
+	return urls
+}
+
+var activePipelineRequests atomic.Int32
+
+// usePipelineTestRegistry lets tarballs download from httptest servers
+// without touching the shared tarball store
+func usePipelineTestRegistry(tb testing.TB) {
+	defaultStore := openTarballStore
+	openTarballStore = func() *store.Store { return nil }
+	allowLoopbackTarballs(tb)
+	installJSON = true
+	tb.Cleanup(func() {
+		openTarballStore = defaultStore
+		installJSON = false
+		installDownloadConcurrency = defaultDownloadConcurrency
+		installExtractConcurrency = defaultExtractConcurrency
+	})
+}
+
+func TestInstallPipeline(t *testing.T) {
+	usePipelineTestRegistry(t)
+	var peak atomic.Int32
+	urls := pipelineTarballs(t, 8, 20*time.Millisecond, &peak)
+	installDownloadConcurrency = 3
+	installExtractConcurrency = 2
+
+	projectDir := t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+	defer installDownloads.clear()
+
+	output := &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+	require.NoError(t, installIntoProject(projectDir, urls, output))
+	assert.Nil(t, installPrefetch, "the pipeline is stopped once the install is done")
+
+	require.Len(t, output.Packages, len(urls))
+	manifest, err := os.ReadFile(filepath.Join(projectDir, "Packages", "manifest.json"))
+	require.NoError(t, err)
+	for i := range urls {
+		name := fmt.Sprintf("com.test.pipeline%02d", i)
+		assert.Equal(t, name+"@1.0.0", output.Packages[i], "packages are installed in the order given")
+		assert.Contains(t, string(manifest), `"`+name+`": "1.0.0"`)
+		assert.FileExists(t, filepath.Join(projectDir, "Packages", name, "Runtime", "File49.cs"))
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(3), "downloads are bounded by --download-concurrency")
+	assert.Greater(t, peak.Load(), int32(1), "downloads overlap")
+}
+
+// BenchmarkInstallPipeline installs 20 tarballs from a registry answering
+// each download after 20ms, serially, with every download and extraction at
+// once, and with the default pipeline limits
+func BenchmarkInstallPipeline(b *testing.B) {
+	usePipelineTestRegistry(b)
+	urls := pipelineTarballs(b, 20, 20*time.Millisecond, nil)
+
+	for _, bm := range []struct {
+		name                string
+		downloads, extracts int
+	}{
+		{"serial", 1, 1},
+		{"unbounded", len(urls), len(urls)},
+		{"pipeline", defaultDownloadConcurrency, defaultExtractConcurrency},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			installDownloadConcurrency = bm.downloads
+			installExtractConcurrency = bm.extracts
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				projectDir := b.TempDir()
+				require.NoError(b, setupUnityProject(projectDir))
+				output := &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+				b.StartTimer()
+
+				require.NoError(b, installIntoProject(projectDir, urls, output))
+				installDownloads.clear()
+			}
+		})
+	}
+}
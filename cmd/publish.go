@@ -2,20 +2,26 @@ package cmd
 
 import (
 	"archive/tar"
+	"bufio"
 	"compress/gzip"
 	"crypto/sha1" // #nosec G505 - Required for npm compatibility
 	"crypto/sha512"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"gpm.sh/gpm/gpm-cli/internal/api"
 	"gpm.sh/gpm/gpm-cli/internal/config"
+	gpmerrors "gpm.sh/gpm/gpm-cli/internal/errors"
 	"gpm.sh/gpm/gpm-cli/internal/filtering"
 	"gpm.sh/gpm/gpm-cli/internal/packaging"
 	"gpm.sh/gpm/gpm-cli/internal/styling"
@@ -23,19 +29,39 @@ import (
 )
 
 var (
-	publishAccess   string
-	publishTag      string
-	publishDryRun   bool
-	publishRegistry string
+	publishAccess         string
+	publishTag            string
+	publishDryRun         bool
+	publishRegistry       string
+	publishIfPresent      bool
+	publishOTP            string
+	publishAuthOnly       bool
+	publishVerbose        bool
+	publishStrict         bool
+	publishChecksums      string
+	publishFollowSymlinks bool
+	publishIgnore         []string
+	publishNormalizeEOL   bool
+	publishScanSecrets    bool
+	publishAllowSecrets   bool
+	publishShowPayload    string
+	publishJSON           bool
+	publishFailFast       bool
+
+	publishCompressionLevel int
 )
 
 var publishCmd = &cobra.Command{
-	Use:   "publish [package-spec]",
+	Use:   "publish [package-spec...]",
 	Short: "Publish a package to GPM registry",
 	Long: `Publish a package to the GPM registry.
 
 Publishes a package to the registry so that it can be installed by name.
 If no package-spec is provided, publishes the package in the current directory.
+Several specs are published one after another with a single credentials
+check, and a failure doesn't stop the rest unless --fail-fast is given.
+Versions the registry already has are then skipped, so re-running a
+publish that partly failed only uploads what is missing.
 
 Package Specs:
   a) Current directory (default)          # gpm publish
@@ -52,28 +78,50 @@ Examples:
   gpm publish                             # Publish current directory
   gpm publish ./my-package                # Publish specific folder
   gpm publish package.tgz                 # Publish tarball
+  gpm publish ./core ./ui --json          # Publish several, with a JSON summary
   gpm publish --access=scoped             # Publish as scoped
   gpm publish --access=private            # Publish as private
   gpm publish --tag=beta                  # Publish with dist-tag
   gpm publish --registry=https://npmjs.org # Publish to specific registry
-  gpm publish --dry-run                   # Simulate publish`,
-	Args: cobra.MaximumNArgs(1),
+  gpm publish --dry-run                   # Simulate publish
+  gpm publish --dry-run --show-payload    # Also print the JSON body that would be sent
+  gpm publish --compression-level=9       # Smallest tarball, slower to pack
+  gpm publish --if-present                # No-op when there is no package.json
+  gpm publish --otp=123456                # Provide a two-factor code up front
+  gpm publish --auth-only                 # Only check credentials, don't pack or upload
+  gpm publish --scan-secrets              # Refuse to upload .env files, keys and tokens`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var packageSpec string
 		if len(args) == 0 {
-			packageSpec = "."
-		} else {
-			packageSpec = args[0]
+			args = []string{"."}
+		}
+		if len(args) == 1 && !publishJSON {
+			return publish(args[0])
 		}
-		return publish(packageSpec)
+		return publishPackages(args)
 	},
 }
 
 func init() {
 	publishCmd.Flags().StringVar(&publishAccess, "access", "", "Package access level (public, scoped, private) - auto-detected if not specified")
-	publishCmd.Flags().StringVar(&publishTag, "tag", "latest", "Dist-tag to publish under")
+	publishCmd.Flags().StringVar(&publishTag, "tag", "", "Dist-tag to publish under (default: publishConfig.tag or latest)")
 	publishCmd.Flags().BoolVar(&publishDryRun, "dry-run", false, "Simulate publish without uploading")
 	publishCmd.Flags().StringVar(&publishRegistry, "registry", "", "Registry URL to publish to (overrides config)")
+	publishCmd.Flags().IntVar(&publishCompressionLevel, "compression-level", -1, "Gzip level 0-9 for the tarball; affects size only (default: config compression_level or 6)")
+	publishCmd.Flags().BoolVar(&publishIfPresent, "if-present", false, "Succeed without publishing when no package.json is found")
+	publishCmd.Flags().StringVar(&publishOTP, "otp", "", "One-time password for registries that require two-factor authentication")
+	publishCmd.Flags().BoolVar(&publishAuthOnly, "auth-only", false, "Verify credentials and exit without packing or uploading")
+	publishCmd.Flags().BoolVarP(&publishVerbose, "verbose", "v", false, "Show which rule included or excluded each file")
+	publishCmd.Flags().BoolVar(&publishStrict, "strict", false, "Fail instead of warning when a files entry matches nothing")
+	publishCmd.Flags().StringVar(&publishChecksums, "checksums", "", "Write the published tarball's sha1, sha512 and integrity to this file, or to stderr with -")
+	publishCmd.Flags().StringVar(&publishShowPayload, "show-payload", "", "With --dry-run, print the npm request body that would be sent, or write it to this file")
+	publishCmd.Flags().Lookup("show-payload").NoOptDefVal = "-"
+	publishCmd.Flags().BoolVar(&publishFollowSymlinks, "follow-symlinks", false, "Pack the files symlinks point at, as long as they stay inside the package (default: skip symlinks)")
+	publishCmd.Flags().BoolVar(&publishNormalizeEOL, "normalize-eol", false, "Pack text files with LF line endings (default: only files .gitattributes marks as text)")
+	publishCmd.Flags().BoolVar(&publishScanSecrets, "scan-secrets", false, "Fail when packed files look like they contain secrets: .env files, known key formats, high-entropy strings (default under --strict)")
+	publishCmd.Flags().BoolVar(&publishAllowSecrets, "allow-secrets", false, "Report secrets found by the scan as warnings instead of failing")
+	publishCmd.Flags().StringArrayVar(&publishIgnore, "ignore", nil, "Leave out files matching this .gpmignore-style pattern (repeatable)")
+	publishCmd.Flags().BoolVar(&publishJSON, "json", false, "Print a JSON summary of each package published")
+	publishCmd.Flags().BoolVar(&publishFailFast, "fail-fast", false, "Stop at the first package that fails to publish")
 }
 
 type PublishInfo struct {
@@ -84,19 +132,224 @@ type PublishInfo struct {
 	Sha512        string
 	Integrity     string
 	FilteredFiles []string
+	// FileCount and UnpackedSize describe the packed files for the
+	// published dist
+	FileCount    int
+	UnpackedSize int64
+	// GitHead is the commit a folder was packed from, when it is in a git
+	// repository
+	GitHead string
+	// Warnings are non-fatal validation and file filtering diagnostics
+	Warnings []string
+}
+
+// PublishResult is the outcome of publishing one package spec
+type PublishResult struct {
+	Spec      string `json:"spec"`
+	Name      string `json:"name,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	Registry  string `json:"registry,omitempty"`
+	Integrity string `json:"integrity,omitempty"`
+	Success   bool   `json:"success"`
+	DryRun    bool   `json:"dryRun,omitempty"`
+	// Skipped is set when --if-present found no package at the spec
+	Skipped bool `json:"skipped,omitempty"`
+	// AlreadyPublished is set when the registry already had this version,
+	// so nothing was uploaded
+	AlreadyPublished bool `json:"alreadyPublished,omitempty"`
+	// Warnings are the package's validation and file filtering diagnostics
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+
+	// checksum is the --checksums entry of the uploaded tarball
+	checksum *ChecksumEntry
 }
 
+// PublishOutput is the --json summary of a publish
+type PublishOutput struct {
+	Results   []PublishResult `json:"results"`
+	Success   bool            `json:"success"`
+	Published int             `json:"published"`
+	// Skipped counts packages already published or, with --if-present,
+	// not found
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+	// Checksums lists the uploaded tarballs when --checksums is given
+	Checksums []ChecksumEntry `json:"checksums,omitempty"`
+}
+
+// publishClients are the clients that passed the credentials check during a
+// multi-package publish, by registry, so the check and any one-time password
+// prompt happen once. Nil outside publishPackages.
+var publishClients map[string]*api.Client
+
+// publish publishes a single package spec
 func publish(packageSpec string) error {
+	result := &PublishResult{Spec: packageSpec}
+	if err := publishPackage(packageSpec, result); err != nil {
+		return err
+	}
+	if publishChecksums != "" && result.checksum != nil {
+		return writePublishChecksums([]ChecksumEntry{*result.checksum})
+	}
+	return nil
+}
+
+// publishPackages publishes each spec in turn and summarizes the outcome. A
+// failure doesn't stop the rest unless --fail-fast; the command fails if any
+// package did.
+func publishPackages(packageSpecs []string) error {
+	publishClients = make(map[string]*api.Client)
+	defer func() { publishClients = nil }()
+
+	output := PublishOutput{Results: []PublishResult{}}
+	var checksums []ChecksumEntry
+	for _, spec := range packageSpecs {
+		result := PublishResult{Spec: spec}
+		var err error
+		if publishJSON {
+			err = withoutStdout(func() error { return publishPackage(spec, &result) })
+		} else {
+			if len(output.Results) > 0 {
+				fmt.Println()
+			}
+			err = publishPackage(spec, &result)
+		}
+		if err != nil {
+			result.Error = err.Error()
+			output.Failed++
+		} else {
+			result.Success = true
+			// Only an upload leaves a checksum behind
+			if result.checksum != nil {
+				output.Published++
+				checksums = append(checksums, *result.checksum)
+			}
+			if result.AlreadyPublished || result.Skipped {
+				output.Skipped++
+			}
+		}
+		output.Results = append(output.Results, result)
+		if err != nil && publishFailFast {
+			break
+		}
+	}
+	output.Success = output.Failed == 0
+
+	var checksumsErr error
+	if publishChecksums != "" && len(checksums) > 0 {
+		if publishJSON {
+			output.Checksums = checksums
+			if publishChecksums != "-" {
+				checksumsErr = writeChecksumsFile(publishChecksums, checksums)
+			}
+		} else {
+			checksumsErr = writePublishChecksums(checksums)
+		}
+	}
+
+	if publishJSON {
+		data, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON output: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printPublishSummary(output, len(packageSpecs))
+	}
+
+	if output.Failed > 0 {
+		return fmt.Errorf("failed to publish %d of %d package(s)", output.Failed, len(packageSpecs))
+	}
+	return checksumsErr
+}
+
+// printPublishSummary lists the outcome of each package after a
+// multi-package publish
+func printPublishSummary(output PublishOutput, total int) {
+	fmt.Println()
+	fmt.Println(styling.Header("Publish Summary"))
+	fmt.Println(styling.Separator())
+	for _, result := range output.Results {
+		switch {
+		case result.Error != "":
+			message, _, _ := strings.Cut(result.Error, "\n")
+			fmt.Printf("%s %s: %s\n", styling.Error("✗"), result.Spec, message)
+		case result.Skipped:
+			fmt.Printf("%s %s: no package found\n", styling.Muted("-"), result.Spec)
+		case result.AlreadyPublished:
+			fmt.Printf("%s %s@%s: already published\n", styling.Muted("-"), styling.Package(result.Name), styling.Version(result.Version))
+		case result.Name == "":
+			fmt.Printf("%s %s\n", styling.Success("✓"), result.Spec)
+		default:
+			fmt.Printf("%s %s@%s\n", styling.Success("✓"), styling.Package(result.Name), styling.Version(result.Version))
+		}
+	}
+	if notAttempted := total - len(output.Results); notAttempted > 0 {
+		fmt.Printf("%s\n", styling.Muted(fmt.Sprintf("%d not attempted after the first failure (--fail-fast)", notAttempted)))
+	}
+	fmt.Printf("%d published, %d skipped, %d failed\n", output.Published, output.Skipped, output.Failed)
+}
+
+// withoutStdout runs fn with its human-readable output discarded, so --json
+// prints only the summary
+func withoutStdout(fn func() error) error {
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		return fn()
+	}
+	original := os.Stdout
+	os.Stdout = devNull
+	defer func() {
+		os.Stdout = original
+		_ = devNull.Close()
+	}()
+	return fn()
+}
+
+// writePublishChecksums records the published tarballs in the --checksums
+// file, or prints them on stderr for "-" so stdout keeps only the publish output
+func writePublishChecksums(entries []ChecksumEntry) error {
+	if publishChecksums == "-" {
+		fmt.Fprint(os.Stderr, formatChecksums(entries))
+		return nil
+	}
+	if err := writeChecksumsFile(publishChecksums, entries); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%s %s\n", styling.Label("Checksums:"), styling.File(publishChecksums))
+	return nil
+}
+
+// publishPackage publishes one package spec, filling in result as it goes
+func publishPackage(packageSpec string, result *PublishResult) error {
+	if publishIfPresent && !packagePresent(packageSpec) {
+		printIfPresentSkip("publish", packageSpec)
+		result.Skipped = true
+		return nil
+	}
+
+	if publishShowPayload != "" && !publishDryRun {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("--show-payload only works with --dry-run"),
+			styling.Hint("Run 'gpm publish --dry-run --show-payload' to see what would be sent"))
+	}
+
 	cfg := config.GetConfig()
-	if cfg.Token == "" {
+	if config.ResolveToken("", ".").Value == "" {
 		return fmt.Errorf("not authenticated. Run 'gpm login'")
 	}
 
-	registry := cfg.Registry
-	if publishRegistry != "" {
-		registry = publishRegistry
+	peeked := peekPackage(packageSpec)
+	publishConfig := &validation.PublishConfig{}
+	if peeked.PublishConfig != nil {
+		publishConfig = peeked.PublishConfig
 	}
 
+	resolved := config.ResolveRegistry(publishRegistry, ".")
+	registry, registrySource := resolvePublishRegistry(cfg, resolved, peeked.Name, publishConfig)
+
 	// Validate registry URL format
 	if registry != "" {
 		if !strings.HasPrefix(registry, "http://") && !strings.HasPrefix(registry, "https://") {
@@ -104,6 +357,39 @@ func publish(packageSpec string) error {
 		}
 	}
 
+	tag := publishTag
+	if tag == "" {
+		tag = publishConfig.Tag
+	}
+	if tag == "" {
+		tag = "latest"
+	}
+
+	if err := validateDistTag(tag); err != nil {
+		return fmt.Errorf("invalid dist-tag: %w", err)
+	}
+	result.Registry = registry
+	result.Tag = tag
+
+	// A publishConfig or scope mapping may name another registry; it only
+	// gets a token configured for it, or the one --registry sends it
+	token := registryToken(registry, resolved, ".")
+	if token == "" {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("No token is configured for %s (%s)", registry, registrySource)),
+			styling.Hint("Pass --registry="+registry+" to publish there with your token"))
+	}
+
+	// Check credentials before spending time building the tarball
+	client, err := publishClient(registry, token)
+	if err != nil {
+		return err
+	}
+	if publishAuthOnly {
+		fmt.Println(styling.Success("✓ Ready to publish to " + registry))
+		return nil
+	}
+
 	publishInfo, cleanup, err := prepareEnhancedPackageForPublish(packageSpec)
 	if err != nil {
 		return err
@@ -114,13 +400,16 @@ func publish(packageSpec string) error {
 		}
 	}()
 
-	if err := validateDistTag(publishTag); err != nil {
-		return fmt.Errorf("invalid dist-tag: %w", err)
-	}
-
 	packageName := publishInfo.PackageInfo.Name
+	result.Name = packageName
+	result.Version = publishInfo.PackageInfo.Version
+	result.Integrity = publishInfo.Integrity
+	result.Warnings = publishInfo.Warnings
 
 	actualAccess := publishAccess
+	if actualAccess == "" {
+		actualAccess = publishConfig.Access
+	}
 	if actualAccess == "" {
 		actualAccess = string(determineRecommendedAccess())
 	}
@@ -129,11 +418,22 @@ func publish(packageSpec string) error {
 		return fmt.Errorf("access level validation failed: %w", err)
 	}
 
-	client := api.NewClient(registry, cfg.Token)
-
-	if err := performPrePublishChecks(); err != nil {
+	published, err := performPrePublishChecks(client, packageName, result.Version, actualAccess)
+	if err != nil {
 		return fmt.Errorf("pre-publish validation failed: %w", err)
 	}
+	if published != nil {
+		// Only a version with the same contents is skipped; one the registry
+		// gives no integrity for is assumed to have them
+		if published.Dist != nil && published.Dist.Integrity != "" && published.Dist.Integrity != publishInfo.Integrity {
+			return fmt.Errorf("%s\n\n%s",
+				styling.Error(fmt.Sprintf("%s@%s is already published to %s with different contents", packageName, result.Version, registry)),
+				styling.Hint("Bump the version to publish the changes"))
+		}
+		fmt.Printf("%s %s@%s is already published to %s, skipping\n", styling.Muted("-"), styling.Package(packageName), styling.Version(result.Version), styling.URL(registry))
+		result.AlreadyPublished = true
+		return nil
+	}
 
 	headerText := "📤 Publishing Package"
 	if publishDryRun {
@@ -145,23 +445,40 @@ func publish(packageSpec string) error {
 	fmt.Printf("%s %s\n", styling.Label("Package:"), styling.Package(packageName))
 	fmt.Printf("%s %s\n", styling.Label("Version:"), styling.Version(publishInfo.PackageInfo.Version))
 	fmt.Printf("%s %s\n", styling.Label("Access Level:"), styling.Value(getAccessDescription(actualAccess)))
-	fmt.Printf("%s %s\n", styling.Label("Tag:"), styling.Value(publishTag))
-	fmt.Printf("%s %s\n", styling.Label("Registry:"), styling.URL(registry))
+	fmt.Printf("%s %s\n", styling.Label("Tag:"), styling.Value(tag))
+	fmt.Printf("%s %s %s\n", styling.Label("Registry:"), styling.URL(registry), styling.Muted("("+registrySource+")"))
 	fmt.Printf("%s %s\n", styling.Label("File:"), styling.File(publishInfo.TarballPath))
 	fmt.Printf("%s %d bytes (%.1f kB)\n", styling.Label("Size:"), publishInfo.FileSize, float64(publishInfo.FileSize)/1024)
 	fmt.Printf("%s %s files\n", styling.Label("Files:"), styling.Value(fmt.Sprintf("%d", len(publishInfo.FilteredFiles))))
 	fmt.Printf("%s %s\n", styling.Label("SHA1:"), styling.Hash(publishInfo.Sha1[:20]))
 	fmt.Printf("%s %s\n", styling.Label("Integrity:"), styling.Hash(publishInfo.Integrity))
+	if publishInfo.GitHead != "" {
+		fmt.Printf("%s %s\n", styling.Label("Git HEAD:"), styling.Hash(publishInfo.GitHead))
+	}
 	if publishDryRun {
 		fmt.Printf("%s %s\n", styling.Label("Mode:"), styling.Warning("DRY RUN"))
 	}
+	for _, warning := range publishInfo.Warnings {
+		fmt.Printf("%s\n", styling.Warning("⚠ "+warning))
+	}
 	fmt.Println(styling.Separator())
 
+	req := &api.PublishRequest{
+		Name:    packageName,
+		Version: publishInfo.PackageInfo.Version,
+		Access:  actualAccess,
+		Tag:     tag,
+		Author:  publishInfo.PackageInfo.Author,
+
+		FileCount:    publishInfo.FileCount,
+		UnpackedSize: publishInfo.UnpackedSize,
+	}
+
 	if publishDryRun {
 		fmt.Println(styling.Success("✓ Dry run completed successfully!"))
 		fmt.Println(styling.Info("📋 What would be published:"))
 		fmt.Printf("  %s %s@%s\n", styling.Label("•"), styling.Package(packageName), styling.Version(publishInfo.PackageInfo.Version))
-		fmt.Printf("  %s %s\n", styling.Label("•"), styling.Value(fmt.Sprintf("Tagged as '%s'", publishTag)))
+		fmt.Printf("  %s %s\n", styling.Label("•"), styling.Value(fmt.Sprintf("Tagged as '%s'", tag)))
 		fmt.Printf("  %s %s\n", styling.Label("•"), styling.Value(fmt.Sprintf("Access level: %s", getAccessDescription(actualAccess))))
 		fmt.Printf("  %s %s\n", styling.Label("•"), styling.Value(fmt.Sprintf("Registry: %s", registry)))
 		fmt.Printf("  %s %d files\n", styling.Label("•"), len(publishInfo.FilteredFiles))
@@ -181,23 +498,27 @@ func publish(packageSpec string) error {
 			}
 		}
 
+		if publishShowPayload != "" {
+			if err := showPublishPayload(client, req, publishInfo.TarballPath, publishShowPayload); err != nil {
+				return err
+			}
+		}
+
 		fmt.Println(styling.Hint("Use 'gpm publish' without --dry-run to actually publish"))
+		result.DryRun = true
 		return nil
 	}
 
-	req := &api.PublishRequest{
-		Name:    packageName,
-		Version: publishInfo.PackageInfo.Version,
-		Access:  actualAccess,
-		Tag:     publishTag,
-	}
-
 	resp, err := client.Publish(req, publishInfo.TarballPath)
 	if err != nil {
 		return fmt.Errorf("publish failed: %v", err)
 	}
 
-	if resp.Success {
+	if resp.Recovered {
+		fmt.Println(styling.Success("✓ Package published successfully!"))
+		fmt.Println(styling.Muted("The upload response was lost, but the registry already has this version."))
+		fmt.Printf("%s %s\n", styling.Label("Integrity:"), styling.Hash(publishInfo.Integrity))
+	} else if resp.Success {
 		fmt.Println(styling.Success("✓ Package published successfully!"))
 		fmt.Printf("%s %s\n", styling.Label("Package ID:"), styling.Value(resp.Data.PackageID))
 		fmt.Printf("%s %s\n", styling.Label("Version ID:"), styling.Value(resp.Data.VersionID))
@@ -212,6 +533,61 @@ func publish(packageSpec string) error {
 		return fmt.Errorf("publish failed with unknown error")
 	}
 
+	// Folders are packed into a temporary directory, so record just the
+	// tarball's name
+	result.checksum = &ChecksumEntry{
+		Filename:  filepath.Base(publishInfo.TarballPath),
+		Sha1:      publishInfo.Sha1,
+		Sha512:    publishInfo.Sha512,
+		Integrity: publishInfo.Integrity,
+	}
+	return nil
+}
+
+// publishClient returns a client for registry whose credentials were
+// checked, reusing the one checked earlier in a multi-package publish
+func publishClient(registry, token string) (*api.Client, error) {
+	if client, ok := publishClients[registry]; ok {
+		return client, nil
+	}
+	client := api.NewClient(registry, token).WithContext(commandCtx)
+	if err := preflightPublishAuth(client); err != nil {
+		return nil, err
+	}
+	if publishClients != nil {
+		publishClients[registry] = client
+	}
+	return client, nil
+}
+
+// showPublishPayload prints the request publish would send, or writes its
+// body to path. "-" prints to stdout.
+func showPublishPayload(client *api.Client, req *api.PublishRequest, tarballPath, path string) error {
+	preview, err := client.PreviewPublish(req, tarballPath)
+	if err != nil {
+		return fmt.Errorf("failed to build publish payload: %w", err)
+	}
+
+	if path != "-" {
+		if err := os.WriteFile(path, append(preview.Body, '\n'), 0600); err != nil {
+			return fmt.Errorf("failed to write publish payload: %w", err)
+		}
+		fmt.Printf("%s %s\n", styling.Label("Payload:"), styling.File(path))
+		return nil
+	}
+
+	fmt.Println(styling.Info("📦 Request that would be sent:"))
+	fmt.Printf("%s %s\n", preview.Method, preview.URL)
+	headerNames := make([]string, 0, len(preview.Headers))
+	for name := range preview.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		fmt.Printf("%s: %s\n", name, preview.Headers[name])
+	}
+	fmt.Println()
+	fmt.Println(string(preview.Body))
 	return nil
 }
 
@@ -257,6 +633,11 @@ func prepareExistingTarball(tarballPath string) (*PublishInfo, func(), error) {
 		return nil, nil, fmt.Errorf("failed to calculate checksums: %w", err)
 	}
 
+	fileCount, unpackedSize, err := packaging.TarballContents(tarballPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read tarball contents: %w", err)
+	}
+
 	integrity := fmt.Sprintf("sha512-%s", base64.StdEncoding.EncodeToString(sha512Hash))
 
 	publishInfo := &PublishInfo{
@@ -264,11 +645,13 @@ func prepareExistingTarball(tarballPath string) (*PublishInfo, func(), error) {
 			Name:    packageInfo.Name,
 			Version: packageInfo.Version,
 		},
-		TarballPath: tarballPath,
-		FileSize:    info.Size(),
-		Sha1:        hex.EncodeToString(sha1Hash),
-		Sha512:      hex.EncodeToString(sha512Hash),
-		Integrity:   integrity,
+		TarballPath:  tarballPath,
+		FileSize:     info.Size(),
+		Sha1:         hex.EncodeToString(sha1Hash),
+		Sha512:       hex.EncodeToString(sha512Hash),
+		Integrity:    integrity,
+		FileCount:    fileCount,
+		UnpackedSize: unpackedSize,
 	}
 
 	return publishInfo, nil, nil
@@ -291,12 +674,47 @@ func prepareFolderWithFiltering(folderPath string) (*PublishInfo, func(), error)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create file filter: %w", err)
 	}
+	filterEngine.SetFollowSymlinks(publishFollowSymlinks)
+	if err := filterEngine.AddIgnorePatterns(publishIgnore); err != nil {
+		return nil, nil, err
+	}
 
 	filterResult, err := filterEngine.FilterFiles()
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to filter files: %w", err)
 	}
 
+	eol, err := packaging.NewLineEndings(folderPath, publishNormalizeEOL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if publishVerbose {
+		printFilterDecisions(filterResult)
+	}
+
+	if publishStrict && len(filterResult.UnmatchedFiles) > 0 {
+		return nil, nil, fmt.Errorf("%s\n\n%s",
+			styling.Error(unmatchedFilesMessage(filterResult.UnmatchedFiles)),
+			styling.Hint("Fix or remove these entries in package.json, or publish without --strict"))
+	}
+
+	var secretWarnings []string
+	if publishScanSecrets || publishStrict {
+		findings, err := filtering.ScanSecrets(filterResult)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(findings) > 0 && !publishAllowSecrets {
+			return nil, nil, fmt.Errorf("%s\n\n%s",
+				styling.Error(secretsMessage(findings)),
+				styling.Hint("Nothing was uploaded; check each file before publishing it"))
+		}
+		for _, finding := range findings {
+			secretWarnings = append(secretWarnings, "possible secret in "+finding.String())
+		}
+	}
+
 	tempDir, err := os.MkdirTemp("", "gpm-publish-*")
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create temp directory: %w", err)
@@ -306,10 +724,10 @@ func prepareFolderWithFiltering(folderPath string) (*PublishInfo, func(), error)
 		_ = os.RemoveAll(tempDir)
 	}
 
-	tarballName := fmt.Sprintf("%s-%s.tgz", validationResult.Package.Name, validationResult.Package.Version)
+	tarballName := packaging.TarballFilename(validationResult.Package.Name, validationResult.Package.Version)
 	tarballPath := filepath.Join(tempDir, tarballName)
 
-	sha1Hash, sha512Hash, filteredFiles, err := createFilteredTarball(tarballPath, filterResult)
+	sha1Hash, sha512Hash, filteredFiles, err := createFilteredTarball(tarballPath, filterResult, eol)
 	if err != nil {
 		cleanup()
 		return nil, nil, fmt.Errorf("failed to create tarball: %w", err)
@@ -331,26 +749,55 @@ func prepareFolderWithFiltering(folderPath string) (*PublishInfo, func(), error)
 		Sha512:        hex.EncodeToString(sha512Hash),
 		Integrity:     integrity,
 		FilteredFiles: filteredFiles,
+		FileCount:     filterResult.FileCount,
+		UnpackedSize:  filterResult.TotalSize,
+		GitHead:       filtering.GitHead(folderPath),
+		Warnings:      append(append(validationResult.Warnings, filterResult.Warnings...), secretWarnings...),
 	}
 
 	return publishInfo, cleanup, nil
 }
 
-func createFilteredTarball(tarballPath string, filterResult *filtering.FilterResult) ([]byte, []byte, []string, error) {
+func createFilteredTarball(tarballPath string, filterResult *filtering.FilterResult, eol *packaging.LineEndings) ([]byte, []byte, []string, error) {
+	level, err := resolveCompressionLevel(publishCompressionLevel)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return writePackageTarball(tarballPath, filterResult, level, eol)
+}
+
+// writePackageTarball writes the filtered files as a gzipped "package/" tarball
+// and returns the SHA-1 and SHA-512 of the compressed bytes, which is what
+// registries and npm record as shasum and integrity. The compression level
+// only changes the tarball size; integrity is always over the final file.
+// eol converts line endings as files are packed; nil packs them unchanged.
+func writePackageTarball(tarballPath string, filterResult *filtering.FilterResult, level int, eol *packaging.LineEndings) ([]byte, []byte, []string, error) {
 	file, err := os.Create(tarballPath) // #nosec G304 - Path is validated and safe
 	if err != nil {
 		return nil, nil, nil, err
 	}
 	defer func() { _ = file.Close() }()
 
-	gzWriter := gzip.NewWriter(file)
-	defer func() { _ = gzWriter.Close() }()
+	sha1Hash := sha1.New() // #nosec G401 - Required for npm compatibility
+	sha512Hash := sha512.New()
+
+	filteredFiles, err := streamPackageTarball(io.MultiWriter(file, sha1Hash, sha512Hash), filterResult, level, eol)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return sha1Hash.Sum(nil), sha512Hash.Sum(nil), filteredFiles, nil
+}
 
+// streamPackageTarball writes the filtered files to w as a gzipped "package/"
+// tarball and returns the files it wrote
+func streamPackageTarball(w io.Writer, filterResult *filtering.FilterResult, level int, eol *packaging.LineEndings) ([]string, error) {
+	gzWriter, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compression level %d: %w", level, err)
+	}
 	tarWriter := tar.NewWriter(gzWriter)
-	defer func() { _ = tarWriter.Close() }()
 
-	sha1Hash := sha1.New() // #nosec G401 - Required for npm compatibility
-	sha512Hash := sha512.New()
 	var filteredFiles []string
 
 	for _, filteredFile := range filterResult.Files {
@@ -363,33 +810,87 @@ func createFilteredTarball(tarballPath string, filterResult *filtering.FilterRes
 
 		info, err := os.Stat(filteredFile.AbsolutePath)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to stat file %s: %w", filteredFile.RelativePath, err)
+			return nil, fmt.Errorf("failed to stat file %s: %w", filteredFile.RelativePath, err)
 		}
 
 		header, err := tar.FileInfoHeader(info, "")
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to create tar header: %w", err)
-		}
-
-		header.Name = fmt.Sprintf("package/%s", relativePath)
-		if err := tarWriter.WriteHeader(header); err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to write tar header: %w", err)
+			return nil, fmt.Errorf("failed to create tar header: %w", err)
 		}
 
 		fileData, err := os.ReadFile(filteredFile.AbsolutePath)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to read file %s: %w", filteredFile.RelativePath, err)
+			return nil, fmt.Errorf("failed to read file %s: %w", filteredFile.RelativePath, err)
+		}
+		fileData = eol.Apply(relativePath, fileData)
+		// Registries can't resolve workspace: ranges, so the packed
+		// package.json, and the metadata read from it, gets real versions.
+		// It also records the commit it was packed from, like npm's gitHead.
+		if relativePath == "package.json" {
+			packageDir := filepath.Dir(filteredFile.AbsolutePath)
+			if fileData, err = packaging.RewriteWorkspaceDependencies(fileData, packageDir); err != nil {
+				return nil, err
+			}
+			fileData = packaging.EmbedGitHead(fileData, filtering.GitHead(packageDir))
+		}
+
+		header.Name = fmt.Sprintf("package/%s", relativePath)
+		header.Size = int64(len(fileData))
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to write tar header: %w", err)
 		}
 
 		if _, err := tarWriter.Write(fileData); err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to write file data: %w", err)
+			return nil, fmt.Errorf("failed to write file data: %w", err)
 		}
+	}
 
-		sha1Hash.Write(fileData) // #nosec G401 - Required for npm compatibility
-		sha512Hash.Write(fileData)
+	// Flush both writers so the output holds the complete tarball
+	if err := tarWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize tarball: %w", err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize gzip stream: %w", err)
 	}
 
-	return sha1Hash.Sum(nil), sha512Hash.Sum(nil), filteredFiles, nil
+	return filteredFiles, nil
+}
+
+// countingWriter discards what is written to it and counts the bytes
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// estimatePackedSize compresses the filtered files without writing them
+// anywhere and returns the tarball size
+func estimatePackedSize(filterResult *filtering.FilterResult, level int, eol *packaging.LineEndings) (int64, error) {
+	counter := &countingWriter{}
+	if _, err := streamPackageTarball(counter, filterResult, level, eol); err != nil {
+		return 0, err
+	}
+	return counter.n, nil
+}
+
+// resolveCompressionLevel picks the gzip level from the flag, then the
+// compression_level config, then gzip's default
+func resolveCompressionLevel(flagValue int) (int, error) {
+	level := flagValue
+	if level == -1 {
+		if configured := config.GetConfig().CompressionLevel; configured != nil {
+			level = *configured
+		} else {
+			return gzip.DefaultCompression, nil
+		}
+	}
+	if level < gzip.NoCompression || level > gzip.BestCompression {
+		return 0, fmt.Errorf("invalid compression level %d: must be between 0 and 9", level)
+	}
+	return level, nil
 }
 
 func calculateTarballHashes(tarballPath string) ([]byte, []byte, error) {
@@ -402,38 +903,189 @@ func calculateTarballHashes(tarballPath string) ([]byte, []byte, error) {
 	sha1Hash := sha1.New() // #nosec G401 - Required for npm compatibility
 	sha512Hash := sha512.New()
 
-	gzr, err := gzip.NewReader(file)
-	if err != nil {
+	if _, err := io.Copy(io.MultiWriter(sha1Hash, sha512Hash), file); err != nil {
 		return nil, nil, err
 	}
-	defer func() { _ = gzr.Close() }()
 
-	tr := tar.NewReader(gzr)
+	return sha1Hash.Sum(nil), sha512Hash.Sum(nil), nil
+}
 
-	for {
-		header, err := tr.Next()
-		if err == io.EOF {
-			break
+// resolvePublishRegistry picks the registry to publish to and describes why:
+// an explicit --registry wins, then the package's publishConfig.registry, then
+// the package scope's "@scope:registry" mapping, then the effective registry
+func resolvePublishRegistry(cfg *config.Config, resolved config.Resolved, packageName string, publishConfig *validation.PublishConfig) (string, string) {
+	if resolved.Source == config.SourceFlag {
+		return resolved.Value, "from --registry"
+	}
+	if publishConfig.Registry != "" {
+		return publishConfig.Registry, "from publishConfig"
+	}
+	if scope := config.PackageScope(packageName); scope != "" {
+		if registry := cfg.ScopedRegistries[scope]; registry != "" {
+			return registry, fmt.Sprintf("mapped from %s:registry", scope)
 		}
-		if err != nil {
-			return nil, nil, err
+	}
+	if resolved.Source == config.SourceUser || resolved.Source == config.SourceDefault {
+		return resolved.Value, "global default"
+	}
+	return resolved.Value, "from " + resolved.Origin
+}
+
+// readOTP prompts for a one-time password; a variable so tests can stub it
+var readOTP = func() (string, error) {
+	fmt.Fprint(os.Stderr, styling.Label("One-time password: "))
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// preflightPublishAuth verifies the token with whoami and collects a
+// one-time password if the registry requires one
+func preflightPublishAuth(client *api.Client) error {
+	if publishOTP != "" {
+		client.SetOTP(publishOTP)
+	}
+
+	whoami, err := client.Whoami()
+	if errors.Is(err, api.ErrOTPRequired) && publishOTP == "" {
+		otp, readErr := readOTP()
+		if readErr != nil || otp == "" {
+			return fmt.Errorf("%s\n\n%s",
+				styling.Error("This registry requires a one-time password"),
+				styling.Hint("Pass it with --otp=<code>"))
+		}
+		client.SetOTP(otp)
+		whoami, err = client.Whoami()
+	}
+	if err == nil {
+		if whoami.Username != "" {
+			fmt.Printf("%s %s\n", styling.Label("Authenticated as:"), styling.Value(whoami.Username))
 		}
+		return nil
+	}
 
-		if header.Typeflag == tar.TypeReg {
-			data, err := io.ReadAll(tr)
-			if err != nil {
-				return nil, nil, err
-			}
-			sha1Hash.Write(data) // #nosec G401 - Required for npm compatibility
-			sha512Hash.Write(data)
+	var httpErr *api.HTTPError
+	var gpmErr *gpmerrors.GPMError
+	switch {
+	case errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode == http.StatusMethodNotAllowed || httpErr.StatusCode == http.StatusNotImplemented):
+		// Registry has no whoami endpoint; the upload will still authenticate
+		return nil
+	case errors.Is(err, api.ErrOTPRequired):
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("The one-time password was rejected"),
+			styling.Hint("Check your authenticator app and try again with a fresh --otp code"))
+	case errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden),
+		errors.As(err, &gpmErr) && strings.Contains(gpmErr.Code, "UNAUTHORIZED"):
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("Authentication failed: your token is invalid or expired"),
+			styling.Hint("Run 'gpm login' and publish again"))
+	default:
+		return fmt.Errorf("failed to verify credentials: %w", err)
+	}
+}
+
+// performPrePublishChecks checks that the namespace may be published to. In
+// a multi-package publish it also returns the registry's copy of
+// name@version when that is already published, so re-running a publish that
+// partly failed skips what made it.
+func performPrePublishChecks(client *api.Client, packageName, version, access string) (*api.PackageVersion, error) {
+	if err := checkPublishPermission(client, packageName, access); err != nil {
+		return nil, err
+	}
+	if publishClients == nil {
+		return nil, nil
+	}
+	// Any failure to look is left to the upload, which is rejected if the
+	// version does exist
+	if metadata, err := client.GetAbbreviatedMetadata(packageName); err == nil {
+		return metadata.Versions[version], nil
+	}
+	return nil, nil
+}
+
+func checkPublishPermission(client *api.Client, packageName, access string) error {
+	permission, err := client.CheckPublishPermission(packageName, access)
+	if err != nil {
+		if errors.Is(err, api.ErrEndpointUnsupported) {
+			// Older registries have no permission endpoint; the upload itself
+			// will still be rejected if the namespace is not ours
+			return nil
 		}
+		return fmt.Errorf("failed to check publish permission: %w", err)
+	}
+	// GPM registries say here which upload they take natively
+	if format := permission.PublishFormat(); format != "" {
+		client.SetPublishFormat(format)
 	}
 
-	return sha1Hash.Sum(nil), sha512Hash.Sum(nil), nil
+	if permission.Allowed {
+		return nil
+	}
+
+	namespace := permission.Namespace
+	if namespace == "" {
+		namespace = packageNamespace(packageName)
+	}
+
+	message := fmt.Sprintf("you don't own the %s namespace", namespace)
+	if permission.Reason != "" {
+		message = fmt.Sprintf("%s: %s", message, permission.Reason)
+	}
+	return fmt.Errorf("%s\n\n%s", styling.Error(message),
+		styling.Hint("Publish under a namespace you or your studio own, or ask a namespace owner to grant you access"))
 }
 
-func performPrePublishChecks() error {
-	return nil
+// packagePresent reports whether a package spec names a tarball or a folder
+// with a package.json
+func packagePresent(packageSpec string) bool {
+	switch packaging.DetectPackageSpecType(packageSpec) {
+	case "tarball":
+		_, err := os.Stat(packageSpec)
+		return err == nil
+	case "folder":
+		return true
+	default:
+		return false
+	}
+}
+
+// printIfPresentSkip notes that --if-present turned a missing package into a no-op
+func printIfPresentSkip(command, packageSpec string) {
+	fmt.Printf("%s\n", styling.Muted(fmt.Sprintf("No package found in %s; skipping %s (--if-present)", packageSpec, command)))
+}
+
+// peekPackage reads the package name and publishConfig from a folder or
+// tarball without building anything. Fields that can't be determined are
+// left empty.
+func peekPackage(packageSpec string) *packaging.PackageInfo {
+	switch packaging.DetectPackageSpecType(packageSpec) {
+	case "tarball":
+		if info, err := packaging.ExtractPackageInfo(packageSpec); err == nil {
+			return info
+		}
+	case "folder":
+		data, err := os.ReadFile(filepath.Join(packageSpec, "package.json"))
+		if err != nil {
+			break
+		}
+		var info packaging.PackageInfo
+		if json.Unmarshal(data, &info) == nil {
+			return &info
+		}
+	}
+	return &packaging.PackageInfo{}
+}
+
+// packageNamespace returns the reverse-domain prefix of a package name as a
+// wildcard, e.g. "com.homa.*" for "com.homa.analytics"
+func packageNamespace(packageName string) string {
+	parts := strings.Split(packageName, ".")
+	if len(parts) < 3 {
+		return packageName
+	}
+	return strings.Join(parts[:2], ".") + ".*"
 }
 
 func validateDistTag(tag string) error {
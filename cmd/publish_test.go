@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"compress/gzip"
+	"crypto/sha512"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -13,12 +18,14 @@ import (
 	"github.com/stretchr/testify/require"
 	"gpm.sh/gpm/gpm-cli/internal/api"
 	"gpm.sh/gpm/gpm-cli/internal/config"
+	"gpm.sh/gpm/gpm-cli/internal/filtering"
 	"gpm.sh/gpm/gpm-cli/internal/packaging"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
 )
 
 func TestPublishCmd(t *testing.T) {
 	// Test command structure
-	assert.Equal(t, "publish [package-spec]", publishCmd.Use)
+	assert.Equal(t, "publish [package-spec...]", publishCmd.Use)
 	assert.Equal(t, "Publish a package to GPM registry", publishCmd.Short)
 	assert.NotNil(t, publishCmd.RunE)
 }
@@ -184,6 +191,17 @@ func TestPublishFunction(t *testing.T) {
 
 			// Create mock server
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Registry without the permission endpoint: publish must proceed
+				if r.URL.Path == "/-/v1/permissions/publish" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				if r.URL.Path == "/-/whoami" {
+					w.WriteHeader(tt.serverStatus)
+					_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "tester"})
+					return
+				}
+
 				assert.Equal(t, "PUT", r.Method)
 				assert.Equal(t, "/"+tt.packageName, r.URL.Path)
 				assert.Equal(t, "Bearer "+tt.token, r.Header.Get("Authorization"))
@@ -240,6 +258,961 @@ func TestPublishFunction(t *testing.T) {
 	}
 }
 
+func TestPublishNamespacePermission(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		expectError string
+	}{
+		{
+			name:   "allowed",
+			status: http.StatusOK,
+			body:   `{"allowed": true}`,
+		},
+		{
+			name:        "denied",
+			status:      http.StatusOK,
+			body:        `{"allowed": false, "namespace": "com.homa.*"}`,
+			expectError: "you don't own the com.homa.* namespace",
+		},
+		{
+			name:        "forbidden",
+			status:      http.StatusForbidden,
+			body:        "namespace is owned by another studio",
+			expectError: "you don't own the com.homa.* namespace: namespace is owned by another studio",
+		},
+		{
+			name:   "endpoint absent",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var uploaded bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/-/v1/permissions/publish" {
+					assert.Equal(t, "GET", r.Method)
+					assert.Equal(t, "com.homa.analytics", r.URL.Query().Get("name"))
+					assert.Equal(t, "public", r.URL.Query().Get("access"))
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte(tt.body))
+					return
+				}
+				if r.URL.Path == "/-/whoami" {
+					_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "tester"})
+					return
+				}
+
+				uploaded = true
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(api.PublishResponse{Success: true})
+			}))
+			defer server.Close()
+
+			tmpDir := t.TempDir()
+			oldWd, _ := os.Getwd()
+			require.NoError(t, os.Chdir(tmpDir))
+			defer func() { _ = os.Chdir(oldWd) }()
+
+			packageJSON := `{"name": "com.homa.analytics", "version": "1.0.0", "description": "Namespace test"}`
+			require.NoError(t, os.WriteFile("package.json", []byte(packageJSON), 0644))
+			require.NoError(t, os.MkdirAll("Runtime", 0755))
+			require.NoError(t, os.WriteFile("Runtime/Analytics.cs", []byte("// test"), 0644))
+
+			config.SetConfigForTesting(&config.Config{Registry: server.URL, Token: "valid-token"})
+			publishAccess = "public"
+			defer func() { publishAccess = "" }()
+
+			err := publish(".")
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				assert.False(t, uploaded, "tarball must not be uploaded when namespace is denied")
+			} else {
+				require.NoError(t, err)
+				assert.True(t, uploaded)
+			}
+		})
+	}
+}
+
+func TestPublishUsesRegistryPublishFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		permission  string
+		contentType string
+	}{
+		{
+			name:        "registry accepts multipart",
+			permission:  `{"allowed": true, "publishFormats": ["multipart", "attachments"]}`,
+			contentType: "multipart/form-data",
+		},
+		{
+			name:        "registry doesn't say",
+			permission:  `{"allowed": true}`,
+			contentType: "application/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var contentType string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/-/v1/permissions/publish":
+					_, _ = w.Write([]byte(tt.permission))
+				case r.URL.Path == "/-/whoami":
+					_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "tester"})
+				case r.Method == "PUT":
+					contentType = r.Header.Get("Content-Type")
+					_ = json.NewEncoder(w).Encode(api.PublishResponse{Success: true})
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			tmpDir := t.TempDir()
+			oldWd, _ := os.Getwd()
+			require.NoError(t, os.Chdir(tmpDir))
+			defer func() { _ = os.Chdir(oldWd) }()
+
+			require.NoError(t, os.WriteFile("package.json", []byte(`{"name": "com.homa.analytics", "version": "1.0.0", "description": "Format test"}`), 0644))
+			require.NoError(t, os.MkdirAll("Runtime", 0755))
+			require.NoError(t, os.WriteFile("Runtime/Analytics.cs", []byte("// test"), 0644))
+
+			config.SetConfigForTesting(&config.Config{Registry: server.URL, Token: "valid-token"})
+			require.NoError(t, publish("."))
+			assert.True(t, strings.HasPrefix(contentType, tt.contentType), "got %s", contentType)
+		})
+	}
+}
+
+func TestPublishScopedRegistrySelection(t *testing.T) {
+	newRegistry := func(hits *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/-/v1/permissions/publish" || r.URL.Path == "/-/whoami" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			*hits = append(*hits, r.Method+" "+r.URL.Path)
+			_ = json.NewEncoder(w).Encode(api.PublishResponse{Success: true})
+		}))
+	}
+
+	var globalHits, scopeHits, flagHits []string
+	globalServer := newRegistry(&globalHits)
+	defer globalServer.Close()
+	scopeServer := newRegistry(&scopeHits)
+	defer scopeServer.Close()
+	flagServer := newRegistry(&flagHits)
+	defer flagServer.Close()
+
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	writePackage := func(name string) {
+		packageJSON := `{"name": "` + name + `", "version": "1.0.0", "description": "Scope routing test"}`
+		require.NoError(t, os.WriteFile("package.json", []byte(packageJSON), 0644))
+		require.NoError(t, os.MkdirAll("Runtime", 0755))
+		require.NoError(t, os.WriteFile("Runtime/Scoped.cs", []byte("// test"), 0644))
+	}
+
+	config.SetConfigForTesting(&config.Config{
+		Registry:         globalServer.URL,
+		Token:            "valid-token",
+		ScopedRegistries: map[string]string{"@homa": scopeServer.URL},
+	})
+	publishAccess = "public"
+	defer func() { publishAccess = "" }()
+
+	t.Run("scope registry without a token is refused", func(t *testing.T) {
+		writePackage("@homa/analytics")
+		err := publish(".")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "No token is configured for "+scopeServer.URL)
+		assert.Empty(t, scopeHits)
+		assert.Empty(t, globalHits)
+	})
+
+	t.Run("other scopes fall back to global registry", func(t *testing.T) {
+		writePackage("@other/analytics")
+		require.NoError(t, publish("."))
+		assert.Len(t, globalHits, 1)
+		assert.Empty(t, scopeHits)
+	})
+
+	t.Run("registry flag overrides scope mapping", func(t *testing.T) {
+		publishRegistry = flagServer.URL
+		defer func() { publishRegistry = "" }()
+
+		writePackage("@homa/analytics")
+		require.NoError(t, publish("."))
+		assert.Len(t, flagHits, 1)
+		assert.Empty(t, scopeHits)
+	})
+}
+
+func TestPublishConfigRegistry(t *testing.T) {
+	var authorized []string
+	newRegistry := func(hits *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "" {
+				authorized = append(authorized, "http://"+r.Host)
+			}
+			if r.URL.Path == "/-/v1/permissions/publish" || r.URL.Path == "/-/whoami" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			*hits = append(*hits, r.Method+" "+r.URL.Path)
+			_ = json.NewEncoder(w).Encode(api.PublishResponse{Success: true})
+		}))
+	}
+
+	var globalHits, packageHits, flagHits []string
+	globalServer := newRegistry(&globalHits)
+	defer globalServer.Close()
+	packageServer := newRegistry(&packageHits)
+	defer packageServer.Close()
+	flagServer := newRegistry(&flagHits)
+	defer flagServer.Close()
+
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	packageJSON := `{
+		"name": "@homa/analytics",
+		"version": "1.0.0",
+		"description": "publishConfig test",
+		"publishConfig": {"registry": "` + packageServer.URL + `", "access": "public", "tag": "beta"}
+	}`
+	require.NoError(t, os.WriteFile("package.json", []byte(packageJSON), 0644))
+	require.NoError(t, os.MkdirAll("Runtime", 0755))
+	require.NoError(t, os.WriteFile("Runtime/Config.cs", []byte("// test"), 0644))
+
+	config.SetConfigForTesting(&config.Config{
+		Registry: globalServer.URL,
+		Token:    "valid-token",
+	})
+	defer config.ResetConfigForTesting()
+
+	t.Run("publishConfig registry gets no token", func(t *testing.T) {
+		err := publish(".")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "No token is configured for "+packageServer.URL)
+		assert.Contains(t, err.Error(), "--registry="+packageServer.URL)
+		assert.Empty(t, packageHits)
+		assert.NotContains(t, authorized, packageServer.URL, "the token must not reach the publishConfig registry")
+	})
+
+	t.Run("registry flag overrides publishConfig", func(t *testing.T) {
+		publishRegistry = flagServer.URL
+		defer func() { publishRegistry = "" }()
+
+		require.NoError(t, publish("."))
+		assert.Len(t, flagHits, 1)
+		assert.Empty(t, packageHits)
+		assert.Empty(t, globalHits)
+		assert.Contains(t, authorized, flagServer.URL)
+	})
+
+	t.Run("registry flag naming the publishConfig registry sends the token", func(t *testing.T) {
+		publishRegistry = packageServer.URL
+		defer func() { publishRegistry = "" }()
+
+		require.NoError(t, publish("."))
+		assert.Len(t, packageHits, 1)
+		assert.Contains(t, authorized, packageServer.URL)
+	})
+
+	t.Run("peek reads publishConfig", func(t *testing.T) {
+		info := peekPackage(".")
+		require.NotNil(t, info.PublishConfig)
+		assert.Equal(t, packageServer.URL, info.PublishConfig.Registry)
+		assert.Equal(t, "public", info.PublishConfig.Access)
+		assert.Equal(t, "beta", info.PublishConfig.Tag)
+	})
+}
+
+func TestPublishUsesEnvCredentials(t *testing.T) {
+	var authorized []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorized = append(authorized, r.Header.Get("Authorization"))
+		if r.URL.Path == "/-/v1/permissions/publish" || r.URL.Path == "/-/whoami" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(api.PublishResponse{Success: true})
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(oldWd) }()
+	require.NoError(t, os.WriteFile("package.json", []byte(`{"name": "com.test.env", "version": "1.0.0", "description": "env test"}`), 0644))
+	require.NoError(t, os.MkdirAll("Runtime", 0755))
+	require.NoError(t, os.WriteFile("Runtime/Env.cs", []byte("// test"), 0644))
+
+	config.SetConfigForTesting(&config.Config{})
+	defer config.ResetConfigForTesting()
+	t.Setenv(config.RegistryEnv, server.URL)
+	t.Setenv(config.TokenEnv, "env-token")
+	publishAccess = "public"
+	defer func() { publishAccess = "" }()
+
+	require.NoError(t, publish("."))
+	require.NotEmpty(t, authorized)
+	for _, header := range authorized {
+		assert.Equal(t, "Bearer env-token", header)
+	}
+}
+
+func TestPublishAuthPreflight(t *testing.T) {
+	var puts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/-/whoami" && r.Header.Get("Authorization") != "Bearer valid-token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/-/whoami" && r.Header.Get("npm-otp") != "123456":
+			w.Header().Set("WWW-Authenticate", "OTP")
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/-/whoami":
+			_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "tester"})
+		default:
+			puts++
+			_ = json.NewEncoder(w).Encode(api.PublishResponse{Success: true})
+		}
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	packageJSON := `{"name": "com.test.preflight", "version": "1.0.0", "description": "Preflight test"}`
+	require.NoError(t, os.WriteFile("package.json", []byte(packageJSON), 0644))
+
+	// Tarballs are built in the temp dir; keep it empty to detect packing
+	packTmp := t.TempDir()
+	t.Setenv("TMPDIR", packTmp)
+	defer config.ResetConfigForTesting()
+
+	t.Run("invalid token fails before packing", func(t *testing.T) {
+		config.SetConfigForTesting(&config.Config{Registry: server.URL, Token: "expired-token"})
+
+		err := publish(".")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "token is invalid or expired")
+
+		entries, err := os.ReadDir(packTmp)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "no tarball should be created")
+		assert.Zero(t, puts)
+	})
+
+	t.Run("otp is prompted for up front", func(t *testing.T) {
+		config.SetConfigForTesting(&config.Config{Registry: server.URL, Token: "valid-token"})
+
+		prompted := 0
+		oldReadOTP := readOTP
+		readOTP = func() (string, error) {
+			prompted++
+			return "123456", nil
+		}
+		defer func() { readOTP = oldReadOTP }()
+
+		publishAuthOnly = true
+		defer func() { publishAuthOnly = false }()
+
+		require.NoError(t, publish("."))
+		assert.Equal(t, 1, prompted)
+		assert.Zero(t, puts, "--auth-only must not upload")
+
+		entries, err := os.ReadDir(packTmp)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "--auth-only must not pack")
+	})
+
+	t.Run("wrong otp flag is rejected", func(t *testing.T) {
+		config.SetConfigForTesting(&config.Config{Registry: server.URL, Token: "valid-token"})
+		publishOTP = "000000"
+		defer func() { publishOTP = "" }()
+
+		err := publish(".")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "one-time password was rejected")
+	})
+}
+
+func TestPublishIfPresent(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	config.SetConfigForTesting(&config.Config{Registry: server.URL})
+	defer config.ResetConfigForTesting()
+
+	err := publish(".")
+	require.Error(t, err, "missing package.json is an error without --if-present")
+
+	publishIfPresent = true
+	defer func() { publishIfPresent = false }()
+
+	require.NoError(t, publish("."))
+	require.NoError(t, publish("missing.tgz"))
+	assert.Zero(t, requests, "no registry calls should be made")
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "no files should be created")
+}
+
+func TestPublishShowPayload(t *testing.T) {
+	var uploads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/-/whoami":
+			_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "tester"})
+		case "/-/v1/permissions/publish":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			uploads++
+			_ = json.NewEncoder(w).Encode(api.PublishResponse{Success: true})
+		}
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	packageJSON := `{"name": "com.test.payload", "version": "1.2.0", "description": "Payload test"}`
+	require.NoError(t, os.WriteFile("package.json", []byte(packageJSON), 0644))
+	require.NoError(t, os.WriteFile("Payload.cs", []byte("// test"), 0644))
+
+	config.SetConfigForTesting(&config.Config{Registry: server.URL, Token: "secret-token"})
+	defer config.ResetConfigForTesting()
+	publishTag = "beta"
+	defer func() { publishTag = "" }()
+
+	t.Run("requires dry run", func(t *testing.T) {
+		publishShowPayload = "-"
+		defer func() { publishShowPayload = "" }()
+
+		err := publish(".")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only works with --dry-run")
+	})
+
+	publishDryRun = true
+	defer func() { publishDryRun = false }()
+
+	t.Run("written to a file", func(t *testing.T) {
+		payloadPath := filepath.Join(t.TempDir(), "payload.json")
+		publishShowPayload = payloadPath
+		defer func() { publishShowPayload = "" }()
+
+		require.NoError(t, publish("."))
+
+		data, err := os.ReadFile(payloadPath)
+		require.NoError(t, err)
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &payload))
+
+		assert.Equal(t, "com.test.payload", payload["_id"])
+		assert.NotContains(t, payload, "dist-tags")
+		versions, ok := payload["versions"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Contains(t, versions, "1.2.0")
+
+		attachments, ok := payload["_attachments"].(map[string]interface{})
+		require.True(t, ok)
+		attachment, ok := attachments["com.test.payload-1.2.0.tgz"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, fmt.Sprintf("<%v bytes>", attachment["length"]), attachment["data"])
+		assert.NotContains(t, string(data), "secret-token")
+	})
+
+	t.Run("printed with redacted credentials", func(t *testing.T) {
+		publishShowPayload = "-"
+		defer func() { publishShowPayload = "" }()
+
+		output := captureStdout(t, func() error { return publish(".") })
+		assert.Contains(t, output, "PUT "+server.URL+"/com.test.payload")
+		assert.Contains(t, output, "Authorization: Bearer <redacted>")
+		assert.Contains(t, output, `"_id": "com.test.payload"`)
+		assert.NotContains(t, output, "secret-token")
+	})
+
+	assert.Zero(t, uploads, "a dry run must not upload")
+}
+
+func TestPublishDistFileCount(t *testing.T) {
+	var dist map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/-/whoami":
+			_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "tester"})
+		case "/-/v1/permissions/publish":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			var payload struct {
+				Versions map[string]struct {
+					Dist map[string]interface{} `json:"dist"`
+				} `json:"versions"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err == nil {
+				dist = payload.Versions["1.0.0"].Dist
+			}
+			_ = json.NewEncoder(w).Encode(api.PublishResponse{Success: true})
+		}
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	files := map[string]string{
+		"package.json":       `{"name": "com.test.dist", "version": "1.0.0", "description": "Dist test"}`,
+		"Runtime/Player.cs":  "class Player {}",
+		"Runtime/Player.asm": "{}",
+	}
+	var unpackedSize float64
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0755))
+		require.NoError(t, os.WriteFile(name, []byte(content), 0644))
+		unpackedSize += float64(len(content))
+	}
+
+	config.SetConfigForTesting(&config.Config{Registry: server.URL, Token: "secret-token"})
+	defer config.ResetConfigForTesting()
+
+	t.Run("folder", func(t *testing.T) {
+		dist = nil
+		require.NoError(t, publish("."))
+		require.NotNil(t, dist)
+		assert.Equal(t, float64(len(files)), dist["fileCount"])
+		assert.Equal(t, unpackedSize, dist["unpackedSize"])
+	})
+
+	t.Run("existing tarball", func(t *testing.T) {
+		engine, err := filtering.NewFileFilterEngine(".")
+		require.NoError(t, err)
+		filterResult, err := engine.FilterFiles()
+		require.NoError(t, err)
+		tarballPath := filepath.Join(t.TempDir(), "com.test.dist-1.0.0.tgz")
+		_, _, _, err = writePackageTarball(tarballPath, filterResult, gzip.DefaultCompression, nil)
+		require.NoError(t, err)
+
+		dist = nil
+		require.NoError(t, publish(tarballPath))
+		require.NotNil(t, dist)
+		assert.Equal(t, float64(len(files)), dist["fileCount"])
+		assert.Equal(t, unpackedSize, dist["unpackedSize"])
+	})
+}
+
+func TestPublishWorkspaceDependencies(t *testing.T) {
+	var dependencies map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/-/whoami":
+			_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "tester"})
+		case "/-/v1/permissions/publish":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			var payload struct {
+				Versions map[string]struct {
+					Dependencies map[string]string `json:"dependencies"`
+				} `json:"versions"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err == nil {
+				dependencies = payload.Versions["1.0.0"].Dependencies
+			}
+			_ = json.NewEncoder(w).Encode(api.PublishResponse{Success: true})
+		}
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	files := map[string]string{
+		"package.json":                `{"name": "studio", "private": true, "workspaces": ["packages/*"]}`,
+		"packages/core/package.json":  `{"name": "com.studio.core", "version": "2.3.1"}`,
+		"packages/tools/package.json": `{"name": "com.studio.tools", "version": "0.4.0"}`,
+		"packages/ui/Runtime/Ui.cs":   "class Ui {}",
+		"packages/ui/package.json": `{
+  "name": "com.studio.ui",
+  "version": "1.0.0",
+  "dependencies": {
+    "com.studio.core": "workspace:*",
+    "com.studio.tools": "workspace:^",
+    "com.vendor.sdk": "1.2.0"
+  }
+}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(filepath.Join(root, "packages", "ui")))
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	config.SetConfigForTesting(&config.Config{Registry: server.URL, Token: "secret-token"})
+	defer config.ResetConfigForTesting()
+
+	require.NoError(t, publish("."))
+	assert.Equal(t, map[string]string{
+		"com.studio.core":  "2.3.1",
+		"com.studio.tools": "^0.4.0",
+		"com.vendor.sdk":   "1.2.0",
+	}, dependencies)
+
+	t.Run("fails for a sibling that isn't in the workspace", func(t *testing.T) {
+		packageJSON := filepath.Join(root, "packages", "ui", "package.json")
+		require.NoError(t, os.WriteFile(packageJSON, []byte(`{"name": "com.studio.ui", "version": "1.0.0", "dependencies": {"com.studio.gone": "workspace:*"}}`), 0644))
+
+		dependencies = nil
+		err := publish(".")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no package in the workspace")
+		assert.Nil(t, dependencies, "nothing is uploaded")
+	})
+}
+
+func TestPublishRecordsGitHead(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	var gitHead string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/-/whoami":
+			_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "tester"})
+		case "/-/v1/permissions/publish":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			var payload struct {
+				Versions map[string]struct {
+					GitHead string `json:"gitHead"`
+				} `json:"versions"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err == nil {
+				gitHead = payload.Versions["1.0.0"].GitHead
+			}
+			_ = json.NewEncoder(w).Encode(api.PublishResponse{Success: true})
+		}
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	if filtering.IsGitRepo(tmpDir) {
+		t.Skip("temp directory is inside a git repository")
+	}
+	oldWd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(oldWd) }()
+	require.NoError(t, os.WriteFile("package.json", []byte("{\n  \"name\": \"com.test.traced\",\n  \"version\": \"1.0.0\"\n}\n"), 0644))
+
+	config.SetConfigForTesting(&config.Config{Registry: server.URL, Token: "secret-token"})
+	defer config.ResetConfigForTesting()
+
+	t.Run("outside a repository", func(t *testing.T) {
+		gitHead = "unset"
+		require.NoError(t, publish("."))
+		assert.Empty(t, gitHead)
+	})
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, output)
+		return strings.TrimSpace(string(output))
+	}
+	git("init", "-q")
+	git("add", "package.json")
+	git("commit", "-q", "-m", "Initial commit")
+	head := git("rev-parse", "HEAD")
+
+	t.Run("folder", func(t *testing.T) {
+		gitHead = ""
+		require.NoError(t, publish("."))
+		assert.Equal(t, head, gitHead)
+	})
+
+	t.Run("packed tarball", func(t *testing.T) {
+		engine, err := filtering.NewFileFilterEngine(".")
+		require.NoError(t, err)
+		filterResult, err := engine.FilterFiles()
+		require.NoError(t, err)
+		tarballPath := filepath.Join(t.TempDir(), "com.test.traced-1.0.0.tgz")
+		_, _, _, err = writePackageTarball(tarballPath, filterResult, gzip.DefaultCompression, nil)
+		require.NoError(t, err)
+
+		// The tarball carries the commit, so publishing it elsewhere keeps it
+		require.NoError(t, os.Chdir(t.TempDir()))
+		defer func() { _ = os.Chdir(tmpDir) }()
+		gitHead = ""
+		require.NoError(t, publish(tarballPath))
+		assert.Equal(t, head, gitHead)
+	})
+}
+
+func TestPublishMultiplePackages(t *testing.T) {
+	var whoamis int
+	var uploaded []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/-/whoami":
+			whoamis++
+			_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "tester"})
+		case r.URL.Path == "/-/v1/permissions/publish":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPut && r.URL.Path == "/com.test.conflict":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":{"code":"EPUBLISHCONFLICT","message":"cannot publish over the previously published version 1.0.0"}}`))
+		case r.Method == http.MethodPut:
+			uploaded = append(uploaded, strings.TrimPrefix(r.URL.Path, "/"))
+			_ = json.NewEncoder(w).Encode(api.PublishResponse{Success: true})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config.SetConfigForTesting(&config.Config{Registry: server.URL, Token: "token"})
+	defer config.ResetConfigForTesting()
+
+	root := t.TempDir()
+	for _, name := range []string{"com.test.fresh", "com.test.conflict"} {
+		dir := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(fmt.Sprintf(`{"name": %q, "version": "1.0.0", "description": "Multi publish test"}`, name)), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "Runtime.cs"), []byte("// test"), 0644))
+	}
+	fresh, conflict := filepath.Join(root, "com.test.fresh"), filepath.Join(root, "com.test.conflict")
+
+	t.Run("a conflict doesn't stop the rest", func(t *testing.T) {
+		whoamis, uploaded = 0, nil
+		publishJSON = true
+		defer func() { publishJSON = false }()
+
+		var err error
+		stdout := captureStdout(t, func() error {
+			err = publishPackages([]string{conflict, fresh})
+			return nil
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to publish 1 of 2 package(s)")
+
+		var output PublishOutput
+		require.NoError(t, json.Unmarshal([]byte(stdout), &output), stdout)
+		assert.False(t, output.Success)
+		assert.Equal(t, 1, output.Published)
+		assert.Equal(t, 1, output.Failed)
+		require.Len(t, output.Results, 2)
+		assert.False(t, output.Results[0].Success)
+		assert.Equal(t, "com.test.conflict", output.Results[0].Name)
+		assert.Contains(t, output.Results[0].Error, "cannot publish over the previously published version")
+		assert.True(t, output.Results[1].Success)
+		assert.Equal(t, "com.test.fresh", output.Results[1].Name)
+		assert.Equal(t, "1.0.0", output.Results[1].Version)
+		assert.Contains(t, output.Results[1].Warnings, "package.json should include 'license' field")
+		assert.Equal(t, []string{"com.test.fresh"}, uploaded)
+		assert.Equal(t, 1, whoamis, "credentials are checked once")
+	})
+
+	t.Run("--fail-fast", func(t *testing.T) {
+		whoamis, uploaded = 0, nil
+		publishFailFast = true
+		defer func() { publishFailFast = false }()
+
+		var err error
+		stdout := captureStdout(t, func() error {
+			err = publishPackages([]string{conflict, fresh})
+			return nil
+		})
+		require.Error(t, err)
+		assert.Empty(t, uploaded)
+		assert.Contains(t, stdout, "1 not attempted after the first failure")
+	})
+}
+
+func TestPublishSkipsPublishedVersions(t *testing.T) {
+	var uploaded []string
+	var coreDist string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/-/whoami":
+			_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "tester"})
+		case r.Method == http.MethodGet && r.URL.Path == "/com.test.core":
+			// Published by an earlier run that failed on a later package
+			_, _ = fmt.Fprintf(w, `{"name": "com.test.core", "versions": {"1.0.0": {"name": "com.test.core", "version": "1.0.0"%s}}}`, coreDist)
+		case r.Method == http.MethodPut:
+			uploaded = append(uploaded, strings.TrimPrefix(r.URL.Path, "/"))
+			_ = json.NewEncoder(w).Encode(api.PublishResponse{Success: true})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config.SetConfigForTesting(&config.Config{Registry: server.URL, Token: "token"})
+	defer config.ResetConfigForTesting()
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "package.json"), []byte(`{"name": "workspace", "private": true, "workspaces": ["packages/*"]}`), 0644))
+	var specs []string
+	for _, name := range []string{"com.test.core", "com.test.ui", "com.test.ads"} {
+		dir := filepath.Join(root, "packages", name)
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(fmt.Sprintf(`{"name": %q, "version": "1.0.0", "description": "Workspace publish test"}`, name)), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "Runtime.cs"), []byte("// test"), 0644))
+		specs = append(specs, dir)
+	}
+
+	t.Run("json", func(t *testing.T) {
+		uploaded = nil
+		publishJSON = true
+		defer func() { publishJSON = false }()
+
+		stdout := captureStdout(t, func() error { return publishPackages(specs) })
+		assert.Equal(t, []string{"com.test.ui", "com.test.ads"}, uploaded)
+
+		var output PublishOutput
+		require.NoError(t, json.Unmarshal([]byte(stdout), &output), stdout)
+		assert.True(t, output.Success)
+		assert.Equal(t, 2, output.Published)
+		assert.Equal(t, 1, output.Skipped)
+		assert.Equal(t, 0, output.Failed)
+		require.Len(t, output.Results, 3)
+		assert.True(t, output.Results[0].Success)
+		assert.True(t, output.Results[0].AlreadyPublished)
+		assert.False(t, output.Results[1].AlreadyPublished)
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		uploaded = nil
+		noColor := styling.NoColor
+		styling.NoColor = true
+		defer func() { styling.NoColor = noColor }()
+		stdout := captureStdout(t, func() error { return publishPackages(specs) })
+		assert.Len(t, uploaded, 2)
+		assert.Contains(t, stdout, "com.test.core@1.0.0: already published")
+		assert.Contains(t, stdout, "2 published, 1 skipped, 0 failed")
+	})
+
+	t.Run("different contents fail", func(t *testing.T) {
+		uploaded = nil
+		coreDist = `, "dist": {"integrity": "sha512-c29tZXRoaW5nIGVsc2U="}`
+		defer func() { coreDist = "" }()
+		publishJSON = true
+		defer func() { publishJSON = false }()
+
+		var err error
+		stdout := captureStdout(t, func() error {
+			err = publishPackages(specs)
+			return nil
+		})
+		require.Error(t, err)
+		assert.Equal(t, []string{"com.test.ui", "com.test.ads"}, uploaded)
+
+		var output PublishOutput
+		require.NoError(t, json.Unmarshal([]byte(stdout), &output), stdout)
+		assert.False(t, output.Success)
+		assert.Equal(t, 2, output.Published)
+		assert.Equal(t, 0, output.Skipped)
+		assert.Equal(t, 1, output.Failed)
+		require.Len(t, output.Results, 3)
+		assert.False(t, output.Results[0].Success)
+		assert.False(t, output.Results[0].AlreadyPublished)
+		assert.Contains(t, output.Results[0].Error, "com.test.core@1.0.0 is already published to "+server.URL+" with different contents")
+	})
+}
+
+func TestPublishChecksums(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/-/whoami":
+			_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "tester"})
+		case r.Method == http.MethodPut:
+			_ = json.NewEncoder(w).Encode(api.PublishResponse{Success: true})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config.SetConfigForTesting(&config.Config{Registry: server.URL, Token: "token"})
+	defer config.ResetConfigForTesting()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "com.test.sums", "version": "1.0.0", "description": "Checksums test"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Runtime.cs"), []byte("// test"), 0644))
+
+	t.Run("json", func(t *testing.T) {
+		checksumsPath := filepath.Join(t.TempDir(), "SHA512SUMS")
+		publishChecksums = checksumsPath
+		publishJSON = true
+		defer func() {
+			publishChecksums = ""
+			publishJSON = false
+		}()
+
+		stdout := captureStdout(t, func() error { return publishPackages([]string{dir}) })
+		var output PublishOutput
+		require.NoError(t, json.Unmarshal([]byte(stdout), &output), stdout)
+		require.Len(t, output.Checksums, 1)
+		assert.Equal(t, "com.test.sums-1.0.0.tgz", output.Checksums[0].Filename)
+		assert.Equal(t, output.Results[0].Integrity, output.Checksums[0].Integrity)
+
+		data, err := os.ReadFile(checksumsPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), output.Checksums[0].Sha512+"  com.test.sums-1.0.0.tgz")
+	})
+
+	t.Run("stderr", func(t *testing.T) {
+		publishChecksums = "-"
+		defer func() { publishChecksums = "" }()
+
+		stderrFile, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+		require.NoError(t, err)
+		originalStderr := os.Stderr
+		os.Stderr = stderrFile
+		stdout := captureStdout(t, func() error { return publish(dir) })
+		os.Stderr = originalStderr
+		require.NoError(t, stderrFile.Close())
+
+		stderr, err := os.ReadFile(stderrFile.Name())
+		require.NoError(t, err)
+		assert.Contains(t, string(stderr), "  com.test.sums-1.0.0.tgz")
+		assert.NotContains(t, stdout, "# gpm checksums")
+	})
+}
+
 func TestPublishCmdStructure(t *testing.T) {
 	cmd := &cobra.Command{}
 	cmd.AddCommand(publishCmd)
@@ -247,5 +1220,60 @@ func TestPublishCmdStructure(t *testing.T) {
 	// Verify command is properly registered
 	publishSubCmd := cmd.Commands()
 	require.Len(t, publishSubCmd, 1)
-	assert.Equal(t, "publish [package-spec]", publishSubCmd[0].Use)
+	assert.Equal(t, "publish [package-spec...]", publishSubCmd[0].Use)
+}
+
+func TestWritePackageTarballCompressionLevel(t *testing.T) {
+	sourceDir := t.TempDir()
+	compressible := strings.Repeat("public class Generated { int value = 42; } // padding text\n", 4000)
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "package.json"), []byte(`{"name": "com.test.compress", "version": "1.0.0"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "Generated.cs"), []byte(compressible), 0644))
+
+	engine, err := filtering.NewFileFilterEngine(sourceDir)
+	require.NoError(t, err)
+	filterResult, err := engine.FilterFiles()
+	require.NoError(t, err)
+
+	sizes := make(map[int]int64)
+	outDir := t.TempDir()
+	for _, level := range []int{1, 9} {
+		tarballPath := filepath.Join(outDir, fmt.Sprintf("level-%d.tgz", level))
+		_, sha512Sum, files, err := writePackageTarball(tarballPath, filterResult, level, nil)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"package.json", "Generated.cs"}, files)
+
+		info, err := os.Stat(tarballPath)
+		require.NoError(t, err)
+		sizes[level] = info.Size()
+
+		// Integrity is computed over the compressed tarball as written
+		data, err := os.ReadFile(tarballPath)
+		require.NoError(t, err)
+		expected := sha512.Sum512(data)
+		assert.Equal(t, expected[:], sha512Sum)
+	}
+
+	assert.Less(t, sizes[9], sizes[1], "level 9 should produce a smaller tarball than level 1")
+}
+
+func TestResolveCompressionLevel(t *testing.T) {
+	configured := 3
+	config.SetConfigForTesting(&config.Config{CompressionLevel: &configured})
+	defer config.ResetConfigForTesting()
+
+	level, err := resolveCompressionLevel(-1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, level)
+
+	level, err = resolveCompressionLevel(9)
+	require.NoError(t, err)
+	assert.Equal(t, 9, level)
+
+	_, err = resolveCompressionLevel(10)
+	assert.Error(t, err)
+
+	config.SetConfigForTesting(&config.Config{})
+	level, err = resolveCompressionLevel(-1)
+	require.NoError(t, err)
+	assert.Equal(t, gzip.DefaultCompression, level)
 }
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+var repoNoBrowser bool
+
+var repoCmd = &cobra.Command{
+	Use:   "repo <package>",
+	Short: "Open a package's source repository",
+	Long: `Open the repository of a package in the browser.
+
+The package.json repository field is converted to a browseable https URL,
+so shorthands like github:user/repo and git+ssh:// URLs work too.
+
+Examples:
+  gpm repo com.company.sdk
+  gpm repo com.company.sdk --no-browser   # Only print the URL`,
+	Args: cobra.ExactArgs(1),
+	RunE: repo,
+}
+
+func init() {
+	repoCmd.Flags().BoolVar(&repoNoBrowser, "no-browser", false, "Print the repository URL without opening a browser")
+}
+
+// hostedGitShorthands maps npm's "host:user/repo" shorthands to their web hosts
+var hostedGitShorthands = map[string]string{
+	"github":    "github.com",
+	"gitlab":    "gitlab.com",
+	"bitbucket": "bitbucket.org",
+	"gist":      "gist.github.com",
+}
+
+func repo(cmd *cobra.Command, args []string) error {
+	packageName := args[0]
+
+	client := effectiveClient("", ".")
+	metadata, err := client.GetPackageMetadata(packageName)
+	if err != nil {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("Failed to fetch package information: "+err.Error()),
+			styling.Hint("Check the package name spelling or search with 'gpm search "+packageName+"'"))
+	}
+
+	repository := metadata.Repository
+	if latest := metadata.Versions[metadata.DistTags["latest"]]; repository == nil && latest != nil {
+		repository = latest.Repository
+	}
+	repoURL := repositoryURL(repository)
+	if repoURL == "" {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("No repository URL for "+packageName),
+			styling.Hint("The package.json has no repository field"))
+	}
+
+	fmt.Println(styling.URL(repoURL))
+	if repoNoBrowser {
+		return nil
+	}
+	if err := openBrowser(repoURL); err != nil {
+		fmt.Println(styling.Muted("Could not open a browser; visit the URL above"))
+	}
+	return nil
+}
+
+// repositoryURL returns a browseable URL for a package.json repository field,
+// which is either a string or an object with a url
+func repositoryURL(repository any) string {
+	switch value := repository.(type) {
+	case string:
+		return browseableRepositoryURL(value)
+	case map[string]interface{}:
+		if raw, ok := value["url"].(string); ok {
+			return browseableRepositoryURL(raw)
+		}
+	}
+	return ""
+}
+
+// browseableRepositoryURL converts a repository URL to https for display.
+// It handles npm shorthands (github:, gitlab:, bitbucket:, gist:, and bare
+// user/repo for GitHub), git+ and git:// schemes, ssh and scp-style
+// git@host:user/repo addresses, and a trailing .git. A #committish becomes a
+// link to that ref. Values it can't interpret are returned unchanged.
+func browseableRepositoryURL(raw string) string {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return ""
+	}
+
+	value, committish, _ := strings.Cut(value, "#")
+
+	var host, path string
+	if prefix, rest, found := strings.Cut(value, ":"); found && hostedGitShorthands[prefix] != "" {
+		host, path = hostedGitShorthands[prefix], rest
+	} else if !strings.Contains(value, ":") && strings.Count(value, "/") == 1 {
+		host, path = "github.com", value
+	} else if at := strings.Index(value, "@"); at >= 0 && !strings.Contains(value, "://") {
+		// scp-style: git@github.com:user/repo.git
+		hostPart, rest, found := strings.Cut(value[at+1:], ":")
+		if !found {
+			return raw
+		}
+		host, path = hostPart, rest
+	} else {
+		parsed, err := url.Parse(strings.TrimPrefix(value, "git+"))
+		if err != nil || parsed.Hostname() == "" {
+			return raw
+		}
+		switch parsed.Scheme {
+		case "http", "https", "git", "ssh":
+		default:
+			return raw
+		}
+		host, path = parsed.Hostname(), parsed.Path
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if path == "" {
+		return raw
+	}
+	browse := "https://" + host + "/" + path
+	if committish != "" {
+		switch host {
+		case "github.com", "gitlab.com":
+			browse += "/tree/" + committish
+		case "bitbucket.org":
+			browse += "/src/" + committish
+		}
+	}
+	return browse
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrowseableRepositoryURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected string
+	}{
+		{"github shorthand", "github:user/repo", "https://github.com/user/repo"},
+		{"gitlab shorthand", "gitlab:group/repo", "https://gitlab.com/group/repo"},
+		{"bitbucket shorthand", "bitbucket:team/repo", "https://bitbucket.org/team/repo"},
+		{"gist shorthand", "gist:11081aaa281", "https://gist.github.com/11081aaa281"},
+		{"bare user/repo", "user/repo", "https://github.com/user/repo"},
+		{"git+https", "git+https://github.com/user/repo.git", "https://github.com/user/repo"},
+		{"git+ssh", "git+ssh://git@github.com/user/repo.git", "https://github.com/user/repo"},
+		{"git scheme", "git://gitlab.com/group/sub/repo.git", "https://gitlab.com/group/sub/repo"},
+		{"ssh with port", "ssh://git@git.studio.example:2222/team/repo.git", "https://git.studio.example/team/repo"},
+		{"scp-style", "git@bitbucket.org:team/repo.git", "https://bitbucket.org/team/repo"},
+		{"https with .git", "https://github.com/user/repo.git", "https://github.com/user/repo"},
+		{"already browseable", "https://github.com/user/repo", "https://github.com/user/repo"},
+		{"github committish", "github:user/repo#v1.2.0", "https://github.com/user/repo/tree/v1.2.0"},
+		{"bitbucket committish", "git+https://bitbucket.org/team/repo.git#develop", "https://bitbucket.org/team/repo/src/develop"},
+		{"unknown scheme kept", "svn://svn.example.com/repo", "svn://svn.example.com/repo"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, browseableRepositoryURL(tt.raw))
+		})
+	}
+}
+
+func TestRepositoryURLForms(t *testing.T) {
+	assert.Equal(t, "https://github.com/user/repo", repositoryURL("github:user/repo"))
+	assert.Equal(t, "https://github.com/user/repo", repositoryURL(map[string]interface{}{
+		"type": "git",
+		"url":  "git+ssh://git@github.com/user/repo.git",
+	}))
+	assert.Empty(t, repositoryURL(nil))
+	assert.Empty(t, repositoryURL(map[string]interface{}{"type": "git"}))
+
+	packument := map[string]interface{}{
+		"dist-tags": map[string]interface{}{"latest": "1.0.0"},
+		"versions": map[string]interface{}{
+			"1.0.0": map[string]interface{}{"repository": "gitlab:group/repo"},
+		},
+	}
+	assert.Equal(t, "https://gitlab.com/group/repo", packageRepositoryURL(packument))
+}
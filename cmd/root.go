@@ -10,17 +10,28 @@ func AddCommands(rootCmd *cobra.Command) {
 	rootCmd.AddCommand(whoamiCmd)
 	rootCmd.AddCommand(publishCmd)
 	rootCmd.AddCommand(packCmd)
+	rootCmd.AddCommand(verifyCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(distTagCmd)
 	rootCmd.AddCommand(searchCmd)
 	rootCmd.AddCommand(installCmd)
 	rootCmd.AddCommand(uninstallCmd)
 	rootCmd.AddCommand(addCmd)
+	rootCmd.AddCommand(bundleCmd)
+	rootCmd.AddCommand(storeCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(treeCmd)
+	rootCmd.AddCommand(checkCompatCmd)
+	rootCmd.AddCommand(sbomCmd)
 	rootCmd.AddCommand(infoCmd)
+	rootCmd.AddCommand(repoCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(importCmd)
 	rootCmd.AddCommand(updateCmd)
+	rootCmd.AddCommand(selfUpdateCmd)
 	// Multi-engine commands
 	rootCmd.AddCommand(detectCmd)
+	// Editor integration
+	rootCmd.AddCommand(commandsCmd)
 }
@@ -1,13 +1,28 @@
 package cmd
 
 import (
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"gpm.sh/gpm/gpm-cli/internal/config"
 )
 
+// TestMain points the tarball store at a temporary directory, so installs in
+// tests don't fill the real one
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "gpm-data-")
+	if err != nil {
+		panic(err)
+	}
+	_ = os.Setenv(config.DataDirEnv, dir)
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestAddCommands(t *testing.T) {
 	// Create a new root command
 	rootCmd := &cobra.Command{
@@ -28,18 +43,28 @@ func TestAddCommands(t *testing.T) {
 		"whoami",
 		"publish",
 		"pack",
+		"verify",
 		"config",
 		"dist-tag",
 		"search",
 		"install",
 		"uninstall",
 		"add",
+		"bundle",
+		"store",
 		"list",
+		"tree",
+		"check-compat",
+		"sbom",
 		"info",
+		"repo",
 		"version",
 		"init",
+		"import",
 		"update",
+		"self-update",
 		"detect",
+		"__commands",
 	}
 
 	// Verify all expected commands are present
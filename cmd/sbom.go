@@ -0,0 +1,444 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/config"
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+	"gpm.sh/gpm/gpm-cli/internal/validation"
+)
+
+var (
+	sbomFormat      string
+	sbomRegistry    string
+	sbomPackagesDir string
+)
+
+// SBOM document formats
+const (
+	sbomCycloneDX = "cyclonedx"
+	sbomSPDX      = "spdx"
+)
+
+// unknownLicense stands in for a license the registry doesn't declare
+const unknownLicense = "UNKNOWN"
+
+var sbomCmd = &cobra.Command{
+	Use:   "sbom",
+	Short: "Print a software bill of materials for the project",
+	Long: `Print a software bill of materials (SBOM) listing every package the project
+depends on, as a CycloneDX 1.5 or SPDX 2.3 JSON document.
+
+Packages are read from manifest.json and, when Unity has written it, from
+packages-lock.json, so transitive dependencies are listed too. Each package's
+license and integrity are read from the registry it was installed from. A
+package without a declared license, or that no registry knows, is listed with
+an UNKNOWN license (NOASSERTION in SPDX).
+
+Examples:
+  gpm sbom > sbom.cdx.json                 # CycloneDX
+  gpm sbom --format spdx > sbom.spdx.json  # SPDX`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectDir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		return sbom(projectDir)
+	},
+}
+
+func init() {
+	sbomCmd.Flags().StringVar(&sbomFormat, "format", sbomCycloneDX, "Document format: cyclonedx or spdx")
+	sbomCmd.Flags().StringVar(&sbomRegistry, "registry", "", "Registry to read package licenses and integrity from")
+	sbomCmd.Flags().StringVar(&sbomPackagesDir, "packages-dir", "", "Unity packages directory relative to the project (default: $"+engines.UnityPackagesDirEnv+" or Packages)")
+}
+
+// sbomPackage is one dependency listed in the SBOM
+type sbomPackage struct {
+	Name    string
+	Version string
+	License string
+	// Integrity is the registry's SRI integrity, such as sha512-...
+	Integrity string
+	// Shasum is the registry's hex sha1, used when it has no integrity
+	Shasum  string
+	Tarball string
+}
+
+func sbom(projectDir string) error {
+	if sbomFormat != sbomCycloneDX && sbomFormat != sbomSPDX {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("Unknown SBOM format %q", sbomFormat)),
+			styling.Hint("Use --format cyclonedx or --format spdx"))
+	}
+
+	packages, err := sbomPackages(projectDir)
+	if err != nil {
+		return err
+	}
+
+	var document interface{}
+	if sbomFormat == sbomSPDX {
+		document = spdxDocument(filepath.Base(projectDir), packages)
+	} else {
+		document = cycloneDXDocument(filepath.Base(projectDir), packages)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// sbomPackages lists the project's dependencies in name order with the
+// license and integrity their registries publish
+func sbomPackages(projectDir string) ([]*sbomPackage, error) {
+	adapter := engines.NewUnityAdapter()
+	adapter.SetPackagesDir(sbomPackagesDir)
+	adapter.SetStateDir(projectStateDir(projectDir))
+	if err := adapter.ValidateProject(projectDir); err != nil {
+		return nil, fmt.Errorf("%s\n\n%s",
+			styling.Error("gpm sbom needs a Unity project: "+err.Error()),
+			styling.Hint("Run it from the project's root directory"))
+	}
+
+	installed, err := adapter.ListPackages(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed packages: %w", err)
+	}
+	origins, err := adapter.PackageOrigins(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := adapter.LoadLock(projectDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	// The lock has the versions Unity resolved, transitive ones included
+	versions := make(map[string]string)
+	for _, pkg := range installed {
+		versions[pkg.Name] = pkg.Version
+	}
+	var locked map[string]*engines.UnityLockEntry
+	if lock != nil {
+		locked = lock.Dependencies
+		for name, entry := range locked {
+			if entry != nil {
+				versions[name] = entry.Version
+			}
+		}
+	}
+	names := make([]string, 0, len(versions))
+	for name := range versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	defaultRegistry := config.ResolveRegistry(sbomRegistry, projectDir)
+	clients := make(map[string]*api.Client)
+	packages := make([]*sbomPackage, 0, len(names))
+	for _, name := range names {
+		pkg := &sbomPackage{Name: name, Version: versions[name], License: unknownLicense}
+		packages = append(packages, pkg)
+
+		entry := locked[name]
+		if validation.ValidateVersion(pkg.Version) != nil || (entry != nil && entry.Source == "builtin") {
+			continue
+		}
+		registry := origins[name].Registry
+		if registry == "" && entry != nil && entry.Source == "registry" {
+			registry = strings.TrimSuffix(entry.URL, "/")
+		}
+		if registry == "" || sbomRegistry != "" {
+			registry = config.ResolveScopedRegistry(config.PackageScope(name), sbomRegistry, projectDir).Value
+		}
+		client := clients[registry]
+		if client == nil {
+			client = api.NewClient(registry, registryToken(registry, defaultRegistry, projectDir)).WithContext(commandCtx)
+			clients[registry] = client
+		}
+
+		metadata, err := client.GetPackageMetadata(name)
+		var notFound *api.PackageNotFoundError
+		if errors.As(err, &notFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from %s: %w", name, registry, err)
+		}
+		info := metadata.Versions[pkg.Version]
+		if info == nil {
+			continue
+		}
+		if strings.TrimSpace(info.License) != "" {
+			pkg.License = strings.TrimSpace(info.License)
+		}
+		if info.Dist != nil {
+			pkg.Integrity, pkg.Shasum, pkg.Tarball = info.Dist.Integrity, info.Dist.Shasum, info.Dist.Tarball
+		}
+	}
+	return packages, nil
+}
+
+// packageHash is one digest of a package's tarball
+type packageHash struct {
+	// Algorithm is the SRI name: sha1, sha256, sha384 or sha512
+	Algorithm string
+	Hex       string
+}
+
+// hashes decodes the package's integrity into hex digests, falling back to
+// its sha1 shasum
+func (p *sbomPackage) hashes() []packageHash {
+	var hashes []packageHash
+	for _, sri := range strings.Fields(p.Integrity) {
+		algorithm, digest, ok := strings.Cut(sri, "-")
+		if !ok {
+			continue
+		}
+		switch algorithm {
+		case "sha1", "sha256", "sha384", "sha512":
+		default:
+			continue
+		}
+		// SRI digests may carry ?options after the base64
+		digest, _, _ = strings.Cut(digest, "?")
+		sum, err := base64.StdEncoding.DecodeString(digest)
+		if err != nil {
+			continue
+		}
+		hashes = append(hashes, packageHash{Algorithm: algorithm, Hex: hex.EncodeToString(sum)})
+	}
+	if len(hashes) == 0 && p.Shasum != "" {
+		hashes = append(hashes, packageHash{Algorithm: "sha1", Hex: strings.ToLower(p.Shasum)})
+	}
+	return hashes
+}
+
+// purl is the package URL SBOM tools match packages by. Unity's registries
+// speak the npm protocol, so registry packages are npm packages.
+func (p *sbomPackage) purl() string {
+	if validation.ValidateVersion(p.Version) != nil {
+		return ""
+	}
+	return "pkg:npm/" + p.Name + "@" + p.Version
+}
+
+// spdxExpressionPattern matches license strings that read as SPDX license
+// expressions, such as MIT or (Apache-2.0 OR MIT)
+var spdxExpressionPattern = regexp.MustCompile(`^[A-Za-z0-9.+()-]+( (AND|OR|WITH) [A-Za-z0-9.+()-]+)*$`)
+
+// CycloneDXBOM is a CycloneDX 1.5 JSON document
+type CycloneDXBOM struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     CycloneDXMetadata    `json:"metadata"`
+	Components   []CycloneDXComponent `json:"components"`
+}
+
+// CycloneDXMetadata describes when, by what and for what the BOM was made
+type CycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     CycloneDXTools     `json:"tools"`
+	Component CycloneDXComponent `json:"component"`
+}
+
+// CycloneDXTools lists the tools that made the BOM
+type CycloneDXTools struct {
+	Components []CycloneDXComponent `json:"components"`
+}
+
+// CycloneDXComponent is one component of the BOM
+type CycloneDXComponent struct {
+	Type     string             `json:"type"`
+	BOMRef   string             `json:"bom-ref,omitempty"`
+	Name     string             `json:"name"`
+	Version  string             `json:"version,omitempty"`
+	PURL     string             `json:"purl,omitempty"`
+	Licenses []CycloneDXLicense `json:"licenses,omitempty"`
+	Hashes   []CycloneDXHash    `json:"hashes,omitempty"`
+}
+
+// CycloneDXLicense is a license choice: an SPDX expression, or a named
+// license that isn't one
+type CycloneDXLicense struct {
+	Expression string                 `json:"expression,omitempty"`
+	License    *CycloneDXNamedLicense `json:"license,omitempty"`
+}
+
+// CycloneDXNamedLicense is a license known only by name
+type CycloneDXNamedLicense struct {
+	Name string `json:"name"`
+}
+
+// CycloneDXHash is a digest of a component
+type CycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+func cycloneDXDocument(projectName string, packages []*sbomPackage) *CycloneDXBOM {
+	bom := &CycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools:     CycloneDXTools{Components: []CycloneDXComponent{{Type: "application", Name: "gpm", Version: Version}}},
+			Component: CycloneDXComponent{Type: "application", Name: projectName},
+		},
+		Components: []CycloneDXComponent{},
+	}
+	for _, pkg := range packages {
+		component := CycloneDXComponent{
+			Type:    "library",
+			BOMRef:  pkg.Name + "@" + pkg.Version,
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			PURL:    pkg.purl(),
+		}
+		if pkg.License != unknownLicense && spdxExpressionPattern.MatchString(pkg.License) {
+			component.Licenses = []CycloneDXLicense{{Expression: pkg.License}}
+		} else {
+			component.Licenses = []CycloneDXLicense{{License: &CycloneDXNamedLicense{Name: pkg.License}}}
+		}
+		for _, hash := range pkg.hashes() {
+			// CycloneDX names them SHA-1, SHA-256, ...
+			component.Hashes = append(component.Hashes, CycloneDXHash{Algorithm: "SHA-" + strings.TrimPrefix(hash.Algorithm, "sha"), Content: hash.Hex})
+		}
+		bom.Components = append(bom.Components, component)
+	}
+	return bom
+}
+
+// SPDXDocument is an SPDX 2.3 JSON document
+type SPDXDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+	Packages          []SPDXPackage      `json:"packages"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+}
+
+// SPDXCreationInfo says when and by what the document was made
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+// SPDXPackage is one package of the document
+type SPDXPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	LicenseComments  string            `json:"licenseComments,omitempty"`
+	Checksums        []SPDXChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []SPDXExternalRef `json:"externalRefs,omitempty"`
+}
+
+// SPDXChecksum is a digest of a package
+type SPDXChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+// SPDXExternalRef points at a package outside the document, such as its purl
+type SPDXExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+// SPDXRelationship relates two elements of the document
+type SPDXRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// spdxNoAssertion is SPDX's value for information the document doesn't have
+const spdxNoAssertion = "NOASSERTION"
+
+// spdxIDInvalid matches the characters SPDX identifiers can't contain
+var spdxIDInvalid = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+func spdxDocument(projectName string, packages []*sbomPackage) *SPDXDocument {
+	document := &SPDXDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              projectName,
+		DocumentNamespace: "https://gpm.sh/spdx/" + url.PathEscape(projectName) + "-" + newUUID(),
+		CreationInfo: SPDXCreationInfo{
+			Created:  time.Now().UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: gpm-" + Version},
+		},
+		Packages:      []SPDXPackage{},
+		Relationships: []SPDXRelationship{},
+	}
+	for _, pkg := range packages {
+		id := "SPDXRef-Package-" + spdxIDInvalid.ReplaceAllString(pkg.Name+"-"+pkg.Version, "-")
+		entry := SPDXPackage{
+			Name:             pkg.Name,
+			SPDXID:           id,
+			VersionInfo:      pkg.Version,
+			DownloadLocation: spdxNoAssertion,
+			LicenseConcluded: spdxNoAssertion,
+			LicenseDeclared:  spdxNoAssertion,
+		}
+		if pkg.Tarball != "" {
+			entry.DownloadLocation = pkg.Tarball
+		}
+		// SPDX only takes license expressions; anything else is kept as a comment
+		switch {
+		case pkg.License == unknownLicense:
+		case spdxExpressionPattern.MatchString(pkg.License):
+			entry.LicenseDeclared = pkg.License
+		default:
+			entry.LicenseComments = pkg.License
+		}
+		for _, hash := range pkg.hashes() {
+			entry.Checksums = append(entry.Checksums, SPDXChecksum{Algorithm: strings.ToUpper(hash.Algorithm), Value: hash.Hex})
+		}
+		if purl := pkg.purl(); purl != "" {
+			entry.ExternalRefs = []SPDXExternalRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: purl}}
+		}
+		document.Packages = append(document.Packages, entry)
+		document.Relationships = append(document.Relationships, SPDXRelationship{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: id})
+	}
+	return document
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gpm.sh/gpm/gpm-cli/internal/api"
+)
+
+func TestSBOM(t *testing.T) {
+	sum := sha512.Sum512([]byte("com.test.mit tarball"))
+	integrity := "sha512-" + base64.StdEncoding.EncodeToString(sum[:])
+	packages := map[string]*api.PackageVersion{
+		"com.test.mit":       {License: "MIT", Dist: &api.PackageDist{Integrity: integrity, Tarball: "https://registry.example/com.test.mit-1.0.0.tgz"}},
+		"com.test.dual":      {License: "(Apache-2.0 OR MIT)", Dist: &api.PackageDist{Shasum: "ABCDEF"}},
+		"com.test.unlicense": {},
+		"com.test.dep":       {License: "BSD-3-Clause"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path[1:]
+		info, ok := packages[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		info.Name, info.Version = name, "1.0.0"
+		_ = json.NewEncoder(w).Encode(&api.PackageMetadata{Name: name, Versions: map[string]*api.PackageVersion{"1.0.0": info}})
+	}))
+	defer server.Close()
+
+	projectDir := t.TempDir()
+	require.NoError(t, setupUnityProject(projectDir))
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "Packages"), 0755))
+	manifest := `{"dependencies": {
+		"com.test.mit": "1.0.0", "com.test.dual": "1.0.0", "com.test.unlicense": "1.0.0",
+		"com.test.local": "file:../local", "com.test.unpublished": "1.0.0"
+	}}`
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Packages", "manifest.json"), []byte(manifest), 0644))
+	lock := `{"dependencies": {
+		"com.test.mit": {"version": "1.0.0", "depth": 0, "source": "registry", "dependencies": {"com.test.dep": "1.0.0"}, "url": "` + server.URL + `"},
+		"com.test.dep": {"version": "1.0.0", "depth": 1, "source": "registry", "url": "` + server.URL + `"},
+		"com.unity.modules.audio": {"version": "1.0.0", "depth": 1, "source": "builtin"}
+	}}`
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Packages", "packages-lock.json"), []byte(lock), 0644))
+
+	sbomRegistry = server.URL
+	defer func() {
+		sbomRegistry = ""
+		sbomFormat = sbomCycloneDX
+	}()
+
+	// Every installed package, direct and transitive, with its license
+	wantLicenses := map[string]string{
+		"com.test.dep":            "BSD-3-Clause",
+		"com.test.dual":           "(Apache-2.0 OR MIT)",
+		"com.test.local":          "UNKNOWN",
+		"com.test.mit":            "MIT",
+		"com.test.unlicense":      "UNKNOWN",
+		"com.test.unpublished":    "UNKNOWN",
+		"com.unity.modules.audio": "UNKNOWN",
+	}
+	wantVersions := map[string]string{
+		"com.test.dep":            "1.0.0",
+		"com.test.dual":           "1.0.0",
+		"com.test.local":          "file:../local",
+		"com.test.mit":            "1.0.0",
+		"com.test.unlicense":      "1.0.0",
+		"com.test.unpublished":    "1.0.0",
+		"com.unity.modules.audio": "1.0.0",
+	}
+
+	t.Run("cyclonedx", func(t *testing.T) {
+		sbomFormat = sbomCycloneDX
+		out := captureStdout(t, func() error { return sbom(projectDir) })
+		var bom CycloneDXBOM
+		require.NoError(t, json.Unmarshal([]byte(out), &bom))
+		assert.Equal(t, "CycloneDX", bom.BOMFormat)
+		assert.Equal(t, "1.5", bom.SpecVersion)
+		assert.Regexp(t, `^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, bom.SerialNumber)
+
+		licenses := make(map[string]string)
+		versions := make(map[string]string)
+		components := make(map[string]CycloneDXComponent)
+		for _, component := range bom.Components {
+			require.Len(t, component.Licenses, 1, component.Name)
+			license := component.Licenses[0].Expression
+			if component.Licenses[0].License != nil {
+				license = component.Licenses[0].License.Name
+			}
+			licenses[component.Name] = license
+			versions[component.Name] = component.Version
+			components[component.Name] = component
+		}
+		assert.Equal(t, wantLicenses, licenses)
+		assert.Equal(t, wantVersions, versions)
+
+		mit := components["com.test.mit"]
+		assert.Equal(t, "pkg:npm/com.test.mit@1.0.0", mit.PURL)
+		assert.Equal(t, []CycloneDXHash{{Algorithm: "SHA-512", Content: hex.EncodeToString(sum[:])}}, mit.Hashes)
+		assert.Equal(t, []CycloneDXHash{{Algorithm: "SHA-1", Content: "abcdef"}}, components["com.test.dual"].Hashes)
+		assert.Empty(t, components["com.test.local"].PURL)
+	})
+
+	t.Run("spdx", func(t *testing.T) {
+		sbomFormat = sbomSPDX
+		out := captureStdout(t, func() error { return sbom(projectDir) })
+		var document SPDXDocument
+		require.NoError(t, json.Unmarshal([]byte(out), &document))
+		assert.Equal(t, "SPDX-2.3", document.SPDXVersion)
+		assert.Equal(t, filepath.Base(projectDir), document.Name)
+
+		licenses := make(map[string]string)
+		versions := make(map[string]string)
+		entries := make(map[string]SPDXPackage)
+		for _, pkg := range document.Packages {
+			license := pkg.LicenseDeclared
+			if license == "NOASSERTION" {
+				license = "UNKNOWN"
+			}
+			licenses[pkg.Name] = license
+			versions[pkg.Name] = pkg.VersionInfo
+			entries[pkg.Name] = pkg
+		}
+		assert.Equal(t, wantLicenses, licenses)
+		assert.Equal(t, wantVersions, versions)
+		assert.Len(t, document.Relationships, len(wantVersions))
+
+		mit := entries["com.test.mit"]
+		assert.Equal(t, "SPDXRef-Package-com.test.mit-1.0.0", mit.SPDXID)
+		assert.Equal(t, "https://registry.example/com.test.mit-1.0.0.tgz", mit.DownloadLocation)
+		assert.Equal(t, []SPDXChecksum{{Algorithm: "SHA512", Value: hex.EncodeToString(sum[:])}}, mit.Checksums)
+		assert.Equal(t, "SPDXRef-Package-com.test.local-file-..-local", entries["com.test.local"].SPDXID)
+		assert.Equal(t, "NOASSERTION", entries["com.test.local"].DownloadLocation)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		sbomFormat = "swid"
+		err := sbom(projectDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `Unknown SBOM format "swid"`)
+	})
+}
@@ -3,20 +3,46 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/api"
 	"gpm.sh/gpm/gpm-cli/internal/config"
 	"gpm.sh/gpm/gpm-cli/internal/styling"
+	"gpm.sh/gpm/gpm-cli/internal/validation"
 )
 
 var (
-	searchLimit  int
-	searchDetail bool
+	searchLimit      int
+	searchDetail     bool
+	searchJSON       bool
+	searchNoTruncate bool
+	searchNoPager    bool
+	searchPageNumber int
+	searchPageSize   int
 )
 
+// searchResult is the registry's /-/v1/search response
+type searchResult struct {
+	Objects []struct {
+		Package struct {
+			Name        string             `json:"name"`
+			Version     string             `json:"version"`
+			Description string             `json:"description"`
+			Keywords    []string           `json:"keywords"`
+			Author      *validation.Author `json:"author"`
+			License     string             `json:"license"`
+			Homepage    string             `json:"homepage"`
+		} `json:"package"`
+		Score struct {
+			Final float64 `json:"final"`
+		} `json:"score"`
+	} `json:"objects"`
+	Total int `json:"total"`
+}
+
 var searchCmd = &cobra.Command{
 	Use:   "search <term>",
 	Short: "Search for packages",
@@ -25,7 +51,15 @@ var searchCmd = &cobra.Command{
 Examples:
   gpm search unity
   gpm search ui --limit 20
-  gpm search analytics --detail`,
+  gpm search analytics --detail
+  gpm search unity --json
+  gpm search unity --page 2 --page-size 20
+
+Results are requested from the registry a page at a time until --limit is
+reached; --page shows a single page instead.
+
+Long output is shown through $PAGER when stdout is a terminal; piped
+output is never paged or truncated.`,
 	Args: cobra.ExactArgs(1),
 	RunE: search,
 }
@@ -33,22 +67,118 @@ Examples:
 func init() {
 	searchCmd.Flags().IntVar(&searchLimit, "limit", 10, "Maximum number of results to show")
 	searchCmd.Flags().BoolVar(&searchDetail, "detail", false, "Show detailed package information")
+	searchCmd.Flags().BoolVar(&searchJSON, "json", false, "Output in JSON format")
+	searchCmd.Flags().BoolVar(&searchNoTruncate, "no-truncate", false, "Show full descriptions instead of cutting them to one line")
+	searchCmd.Flags().BoolVar(&searchNoPager, "no-pager", false, "Don't page long output through $PAGER")
+	searchCmd.Flags().IntVar(&searchPageNumber, "page", 0, "Show only this page of results, counting from 1")
+	searchCmd.Flags().IntVar(&searchPageSize, "page-size", 0, "Results to request per page (default: --limit with --page, else as many as the registry allows)")
 }
 
 func search(cmd *cobra.Command, args []string) error {
 	searchTerm := args[0]
 
-	fmt.Println(styling.Header("🔍  Package Search"))
-	fmt.Println(styling.Separator())
-	fmt.Printf("%s %s\n", styling.Label("Search term:"), styling.Value(searchTerm))
-	fmt.Println()
+	if searchPageNumber < 0 || searchPageSize < 0 {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("--page and --page-size can't be negative"),
+			styling.Hint("Pages count from 1, e.g. --page 2 --page-size 20"))
+	}
 
 	cfg := config.GetConfig()
+	var searchResult *searchResult
+	var err error
+	if searchPageNumber > 0 {
+		size := manualPageSize()
+		searchResult, err = searchPage(cfg.Registry, searchTerm, (searchPageNumber-1)*size, size)
+		// Registries that ignore from and size return every result
+		if err == nil && size > 0 && len(searchResult.Objects) > size {
+			searchResult.Objects = searchResult.Objects[:size]
+		}
+	} else {
+		searchResult, err = searchPages(cfg.Registry, searchTerm, searchLimit, searchPageSize)
+	}
+	if err != nil {
+		return err
+	}
+
+	if searchJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(searchResult)
+	}
+
+	// Descriptions are cut to one line only for someone reading a terminal
+	truncate := !searchDetail && !searchNoTruncate && stdoutIsTerminal()
+	return withPager(searchNoPager, func() error {
+		displaySearchResults(searchTerm, searchResult, truncate)
+		return nil
+	})
+}
+
+// maxSearchPageSize is the most results asked of the registry in one
+// request, npm's own cap on size
+const maxSearchPageSize = 250
+
+// searchRegistry runs a registry search, returning at most limit results
+// when limit is positive
+func searchRegistry(registry, searchTerm string, limit int) (*searchResult, error) {
+	return searchPages(registry, searchTerm, limit, 0)
+}
+
+// searchPages requests pages of pageSize results (as many as the registry
+// allows when 0) until limit results are collected or the registry has no
+// more. Registries that ignore from and size return everything at once,
+// which is cut to limit.
+func searchPages(registry, searchTerm string, limit, pageSize int) (*searchResult, error) {
+	result := &searchResult{}
+	seen := make(map[string]bool)
+	for from := 0; limit <= 0 || len(result.Objects) < limit; {
+		size := maxSearchPageSize
+		if pageSize > 0 {
+			size = pageSize
+		}
+		if limit > 0 {
+			size = min(size, limit-len(result.Objects))
+		}
+		page, err := searchPage(registry, searchTerm, from, size)
+		if err != nil {
+			return nil, err
+		}
+		result.Total = page.Total
 
+		added := 0
+		for _, object := range page.Objects {
+			if seen[object.Package.Name] {
+				continue
+			}
+			seen[object.Package.Name] = true
+			result.Objects = append(result.Objects, object)
+			added++
+		}
+		from += len(page.Objects)
+		// An empty page, or one holding only results already seen from a
+		// registry that ignores from, means there are no more
+		if added == 0 || (page.Total > 0 && from >= page.Total) {
+			break
+		}
+	}
+
+	// Registries may ignore size, so the limit is applied here as well
+	if limit > 0 && len(result.Objects) > limit {
+		result.Objects = result.Objects[:limit]
+	}
+	if result.Total < len(result.Objects) {
+		result.Total = len(result.Objects)
+	}
+	return result, nil
+}
+
+// searchPage requests one page of search results, starting at result from.
+// size is left to the registry when 0.
+func searchPage(registry, searchTerm string, from, size int) (*searchResult, error) {
 	// Build search URL
-	baseURL, err := url.Parse(cfg.Registry)
+	baseURL, err := url.Parse(registry)
 	if err != nil {
-		return fmt.Errorf("%s\n\n%s",
+		return nil, fmt.Errorf("%s\n\n%s",
 			styling.Error("Invalid registry URL: "+err.Error()),
 			styling.Hint("Check your registry URL with 'gpm config get registry'"))
 	}
@@ -56,53 +186,55 @@ func search(cmd *cobra.Command, args []string) error {
 	searchURL := baseURL.JoinPath("/-/v1/search").String()
 	params := url.Values{}
 	params.Add("text", searchTerm)
-	if searchLimit > 0 {
-		params.Add("size", fmt.Sprintf("%d", searchLimit))
+	if size > 0 {
+		params.Add("size", fmt.Sprintf("%d", size))
+	}
+	if from > 0 {
+		params.Add("from", fmt.Sprintf("%d", from))
 	}
 	searchURL = fmt.Sprintf("%s?%s", searchURL, params.Encode())
 
 	// #nosec G107 - URL is validated using url.Parse and JoinPath above
-	resp, err := http.Get(searchURL)
+	resp, err := api.HTTPGet(searchURL)
 	if err != nil {
-		return fmt.Errorf("%s\n\n%s",
+		return nil, fmt.Errorf("%s\n\n%s",
 			styling.Error("Failed to search packages: "+err.Error()),
 			styling.Hint("Check your internet connection and registry URL"))
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("%s\n\n%s",
+		return nil, fmt.Errorf("%s\n\n%s",
 			styling.Error(fmt.Sprintf("Search failed (HTTP %d)", resp.StatusCode)),
 			styling.Hint("The registry may be experiencing issues. Try again later."))
 	}
 
-	var searchResult struct {
-		Objects []struct {
-			Package struct {
-				Name        string            `json:"name"`
-				Version     string            `json:"version"`
-				Description string            `json:"description"`
-				Keywords    []string          `json:"keywords"`
-				Author      map[string]string `json:"author"`
-				License     string            `json:"license"`
-				Homepage    string            `json:"homepage"`
-			} `json:"package"`
-			Score struct {
-				Final float64 `json:"final"`
-			} `json:"score"`
-		} `json:"objects"`
-		Total int `json:"total"`
+	var result searchResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse search results: %w", err)
 	}
+	return &result, nil
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&searchResult); err != nil {
-		return fmt.Errorf("failed to parse search results: %w", err)
+// manualPageSize is the number of results on each --page
+func manualPageSize() int {
+	if searchPageSize > 0 {
+		return searchPageSize
 	}
+	return searchLimit
+}
+
+func displaySearchResults(searchTerm string, searchResult *searchResult, truncate bool) {
+	fmt.Println(styling.Header("🔍  Package Search"))
+	fmt.Println(styling.Separator())
+	fmt.Printf("%s %s\n", styling.Label("Search term:"), styling.Value(searchTerm))
+	fmt.Println()
 
 	if len(searchResult.Objects) == 0 {
 		fmt.Printf("%s\n\n%s\n",
 			styling.Warning("No packages found matching '"+searchTerm+"'"),
 			styling.Hint("Try different search terms or check spelling"))
-		return nil
+		return
 	}
 
 	// Display results
@@ -129,7 +261,7 @@ func search(cmd *cobra.Command, args []string) error {
 		// Description
 		if pkg.Description != "" {
 			description := pkg.Description
-			if len(description) > 80 && !searchDetail {
+			if len(description) > 80 && truncate {
 				description = description[:77] + "..."
 			}
 			fmt.Printf("  %s\n", styling.Muted(description))
@@ -137,10 +269,10 @@ func search(cmd *cobra.Command, args []string) error {
 
 		if searchDetail {
 			// Author
-			if pkg.Author["name"] != "" {
-				fmt.Printf("  %s %s", styling.Label("Author:"), styling.Value(pkg.Author["name"]))
-				if pkg.Author["email"] != "" {
-					fmt.Printf(" <%s>", styling.Muted(pkg.Author["email"]))
+			if pkg.Author != nil && pkg.Author.Name != "" {
+				fmt.Printf("  %s %s", styling.Label("Author:"), styling.Value(pkg.Author.Name))
+				if pkg.Author.Email != "" {
+					fmt.Printf(" <%s>", styling.Muted(pkg.Author.Email))
 				}
 				fmt.Println()
 			}
@@ -171,7 +303,21 @@ func search(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 	fmt.Println(styling.Separator())
 
-	if searchResult.Total > len(searchResult.Objects) {
+	if searchPageNumber > 0 {
+		size := manualPageSize()
+		from := (searchPageNumber - 1) * size
+		fmt.Printf("%s Showing results %d-%d of %d (page %d)\n",
+			styling.Info("📊"),
+			from+1,
+			from+len(searchResult.Objects),
+			searchResult.Total,
+			searchPageNumber)
+		if from+len(searchResult.Objects) < searchResult.Total {
+			fmt.Printf("%s Use --page %d to see the next page\n",
+				styling.Hint("💡"),
+				searchPageNumber+1)
+		}
+	} else if searchResult.Total > len(searchResult.Objects) {
 		fmt.Printf("%s Showing %d of %d total results\n",
 			styling.Info("📊"),
 			len(searchResult.Objects),
@@ -187,8 +333,6 @@ func search(cmd *cobra.Command, args []string) error {
 		styling.Hint("💡"))
 	fmt.Printf("%s Use 'gpm install <package>' to install a package\n",
 		styling.Hint("💡"))
-
-	return nil
 }
 
 func min(a, b int) int {
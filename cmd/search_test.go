@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -213,6 +219,229 @@ func TestSearchFlags(t *testing.T) {
 	})
 }
 
+// fakeTerminalPager makes stdout look like a terminal and points $PAGER at a
+// script that records what it was given, returning the file it writes to
+func fakeTerminalPager(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	paged := filepath.Join(dir, "paged")
+	script := filepath.Join(dir, "pager.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\ncat > '"+paged+"'\n"), 0700)) // #nosec G306 - test pager must be executable
+	t.Setenv("PAGER", script)
+
+	originalTerminal := stdoutIsTerminal
+	stdoutIsTerminal = func() bool { return true }
+	t.Cleanup(func() { stdoutIsTerminal = originalTerminal })
+	return paged
+}
+
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	originalStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := fn()
+
+	_ = w.Close()
+	os.Stdout = originalStdout
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	return buf.String()
+}
+
+func TestSearchLimitJSONAndPaging(t *testing.T) {
+	tempDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	defer func() {
+		_ = os.Setenv("HOME", originalHome)
+		searchLimit = 10
+		searchDetail = false
+		searchJSON = false
+		searchNoTruncate = false
+		searchNoPager = false
+	}()
+	_ = os.Setenv("HOME", tempDir)
+
+	config.InitConfig()
+
+	longDescription := strings.Repeat("A very long package description. ", 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// This registry ignores size and always returns every match
+		var objects []map[string]interface{}
+		for i := 0; i < 8; i++ {
+			objects = append(objects, map[string]interface{}{
+				"package": map[string]interface{}{
+					"name":        fmt.Sprintf("com.test.package%d", i),
+					"version":     "1.0.0",
+					"description": longDescription,
+					"license":     "MIT",
+					"homepage":    "https://example.com",
+					"keywords":    []string{"test"},
+				},
+				"score": map[string]interface{}{"final": 0.5},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"objects": objects, "total": len(objects)})
+	}))
+	defer server.Close()
+	config.SetRegistry(server.URL)
+
+	t.Run("limit bounds printed results", func(t *testing.T) {
+		searchLimit = 3
+		output := captureStdout(t, func() error { return search(nil, []string{"test"}) })
+		assert.Equal(t, 3, strings.Count(output, "com.test.package"))
+		assert.Contains(t, output, "Showing 3 of 8 total results")
+		assert.Contains(t, output, longDescription, "piped output is not truncated")
+	})
+
+	t.Run("terminal output is truncated and paged", func(t *testing.T) {
+		paged := fakeTerminalPager(t)
+		searchLimit = 8
+		searchDetail = false
+
+		output := captureStdout(t, func() error { return search(nil, []string{"test"}) })
+		assert.Empty(t, output, "long output goes through the pager")
+		pagedOutput, err := os.ReadFile(paged) // #nosec G304 - test file
+		require.NoError(t, err)
+		assert.Equal(t, 8, strings.Count(string(pagedOutput), "com.test.package"))
+		assert.NotContains(t, string(pagedOutput), longDescription)
+
+		require.NoError(t, os.Remove(paged))
+		searchNoPager = true
+		searchNoTruncate = true
+		output = captureStdout(t, func() error { return search(nil, []string{"test"}) })
+		searchNoPager = false
+		searchNoTruncate = false
+		assert.Contains(t, output, longDescription)
+		assert.NoFileExists(t, paged)
+	})
+
+	t.Run("json is never paged or truncated", func(t *testing.T) {
+		paged := fakeTerminalPager(t)
+		searchLimit = 5
+		searchJSON = true
+
+		output := captureStdout(t, func() error { return search(nil, []string{"test"}) })
+		assert.NoFileExists(t, paged)
+
+		var result searchResult
+		require.NoError(t, json.Unmarshal([]byte(output), &result))
+		require.Len(t, result.Objects, 5)
+		assert.Equal(t, 8, result.Total)
+		for _, object := range result.Objects {
+			assert.Equal(t, longDescription, object.Package.Description)
+		}
+	})
+}
+
+func TestSearchPagination(t *testing.T) {
+	tempDir := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	defer func() {
+		_ = os.Setenv("HOME", originalHome)
+		searchLimit = 10
+		searchJSON = false
+		searchPageNumber = 0
+		searchPageSize = 0
+	}()
+	_ = os.Setenv("HOME", tempDir)
+
+	config.InitConfig()
+
+	// This registry holds 45 matches and returns at most 20 per request
+	const total, maxSize = 45, 20
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RawQuery)
+		query := r.URL.Query()
+		size, from := maxSize, 0
+		if query.Has("size") {
+			size, _ = strconv.Atoi(query.Get("size"))
+		}
+		if query.Has("from") {
+			from, _ = strconv.Atoi(query.Get("from"))
+		}
+		size = min(size, maxSize)
+
+		objects := []map[string]interface{}{}
+		for i := from; i < total && i < from+size; i++ {
+			objects = append(objects, map[string]interface{}{
+				"package": map[string]interface{}{"name": fmt.Sprintf("com.test.package%02d", i), "version": "1.0.0"},
+				"score":   map[string]interface{}{"final": 0.5},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"objects": objects, "total": total})
+	}))
+	defer server.Close()
+	config.SetRegistry(server.URL)
+
+	searchJSON = true
+	searchResults := func() *searchResult {
+		requests = nil
+		output := captureStdout(t, func() error { return search(nil, []string{"test"}) })
+		var result searchResult
+		require.NoError(t, json.Unmarshal([]byte(output), &result))
+		return &result
+	}
+
+	t.Run("limit spanning several pages", func(t *testing.T) {
+		searchLimit = 30
+		result := searchResults()
+		require.Len(t, result.Objects, 30)
+		assert.Equal(t, total, result.Total)
+		assert.Equal(t, "com.test.package00", result.Objects[0].Package.Name)
+		assert.Equal(t, "com.test.package29", result.Objects[29].Package.Name)
+		assert.Equal(t, []string{"size=30&text=test", "from=20&size=10&text=test"}, requests)
+	})
+
+	t.Run("stops when results run out", func(t *testing.T) {
+		searchLimit = 100
+		result := searchResults()
+		assert.Len(t, result.Objects, total)
+		assert.Len(t, requests, 3)
+	})
+
+	t.Run("page size", func(t *testing.T) {
+		searchLimit = 12
+		searchPageSize = 5
+		defer func() { searchPageSize = 0 }()
+		result := searchResults()
+		assert.Len(t, result.Objects, 12)
+		assert.Equal(t, []string{"size=5&text=test", "from=5&size=5&text=test", "from=10&size=2&text=test"}, requests)
+	})
+
+	t.Run("manual page", func(t *testing.T) {
+		searchPageNumber = 3
+		searchPageSize = 20
+		defer func() {
+			searchPageNumber = 0
+			searchPageSize = 0
+		}()
+		result := searchResults()
+		require.Len(t, result.Objects, 5)
+		assert.Equal(t, "com.test.package40", result.Objects[0].Package.Name)
+		assert.Equal(t, []string{"from=40&size=20&text=test"}, requests)
+
+		searchJSON = false
+		defer func() { searchJSON = true }()
+		searchPageNumber = 2
+		output := captureStdout(t, func() error { return search(nil, []string{"test"}) })
+		assert.Contains(t, output, "Showing results 21-40 of 45 (page 2)")
+		assert.Contains(t, output, "Use --page 3")
+	})
+
+	t.Run("negative page", func(t *testing.T) {
+		searchPageNumber = -1
+		defer func() { searchPageNumber = 0 }()
+		assert.Error(t, search(nil, []string{"test"}))
+	})
+}
+
 func TestMinFunction(t *testing.T) {
 	assert.Equal(t, 5, min(5, 10))
 	assert.Equal(t, 5, min(10, 5))
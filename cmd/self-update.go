@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/config"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+const (
+	// DefaultUpdateURL serves the manifest describing the latest CLI release
+	DefaultUpdateURL = "https://releases.gpm.sh/cli/latest.json"
+
+	// updateCheckInterval limits the background check to once per day
+	updateCheckInterval = 24 * time.Hour
+
+	// updateStateFile caches the last check next to ~/.gpmrc
+	updateStateFile = ".gpm-update-check"
+)
+
+var selfUpdateCheckOnly bool
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update the GPM CLI to the latest release",
+	Long: `Download the latest GPM CLI release and replace the running binary.
+
+The download is verified against the SHA-256 checksum published in the
+release manifest before the binary is replaced.
+
+With 'gpm config set update_check true', GPM also checks for a new release at
+most once a day and prints a one-line notice to stderr. The check is off by
+default and sends no identifying information; GPM_NO_UPDATE_CHECK turns it
+off again regardless of the config file.
+
+Examples:
+  gpm self-update               # Install the latest release
+  gpm self-update --check-only  # Only report whether an update is available`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return selfUpdate(selfUpdateCheckOnly)
+	},
+}
+
+func init() {
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateCheckOnly, "check-only", false, "Report whether an update is available without installing it")
+}
+
+// Release is the manifest published at the update URL
+type Release struct {
+	Version string `json:"version"`
+	// Assets are keyed by "<os>-<arch>", e.g. "linux-amd64"
+	Assets map[string]ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is a downloadable binary and its checksum
+type ReleaseAsset struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+}
+
+// updateState is the cached result of the last background check
+type updateState struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest"`
+}
+
+var updateHTTPClient = api.NewHTTPClient(30 * time.Second)
+
+func updateURL() string {
+	if u := config.GetConfig().UpdateURL; u != "" {
+		return u
+	}
+	return DefaultUpdateURL
+}
+
+// isNewerVersion reports whether latest is a newer release than current.
+// Both may carry a leading "v".
+func isNewerVersion(current, latest string) bool {
+	current = strings.TrimPrefix(strings.TrimSpace(current), "v")
+	latest = strings.TrimPrefix(strings.TrimSpace(latest), "v")
+	if latest == "" {
+		return false
+	}
+	if current == "" {
+		return true
+	}
+	return api.CompareVersions(latest, current) > 0
+}
+
+func fetchLatestRelease(client *http.Client, manifestURL string) (*Release, error) {
+	resp, err := client.Get(manifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release manifest: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch release manifest: %s", resp.Status)
+	}
+
+	var release Release
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&release); err != nil {
+		return nil, fmt.Errorf("invalid release manifest: %w", err)
+	}
+	if release.Version == "" {
+		return nil, fmt.Errorf("invalid release manifest: missing version")
+	}
+	return &release, nil
+}
+
+func selfUpdate(checkOnly bool) error {
+	release, err := fetchLatestRelease(updateHTTPClient, updateURL())
+	if err != nil {
+		return err
+	}
+
+	if !isNewerVersion(Version, release.Version) {
+		fmt.Printf("%s %s\n", styling.Success("✓ GPM CLI is up to date:"), styling.Version(Version))
+		return nil
+	}
+
+	fmt.Printf("%s %s → %s\n", styling.Label("Update available:"), styling.Version(Version), styling.Version(release.Version))
+	if checkOnly {
+		fmt.Printf("%s\n", styling.Hint("Run 'gpm self-update' to install it"))
+		return nil
+	}
+
+	platform := runtime.GOOS + "-" + runtime.GOARCH
+	asset, ok := release.Assets[platform]
+	if !ok || asset.URL == "" {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error(fmt.Sprintf("No %s build is published for %s", release.Version, platform)),
+			styling.Hint("Download the release manually or install gpm through your package manager"))
+	}
+	if asset.SHA256 == "" {
+		return fmt.Errorf("%s", styling.Error("Release manifest has no checksum for "+platform+"; refusing to install"))
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	fmt.Printf("%s %s\n", styling.Label("Downloading:"), styling.URL(asset.URL))
+	if err := replaceExecutable(updateHTTPClient, asset, executable); err != nil {
+		return err
+	}
+
+	_ = saveUpdateState(updateState{CheckedAt: time.Now(), Latest: release.Version})
+	fmt.Printf("%s %s\n", styling.Success("✓ Updated GPM CLI to"), styling.Version(release.Version))
+	return nil
+}
+
+// replaceExecutable downloads an asset next to the executable, verifies its
+// checksum, and swaps it in place
+func replaceExecutable(client *http.Client, asset ReleaseAsset, executable string) error {
+	resp, err := client.Get(asset.URL)
+	if err != nil {
+		return fmt.Errorf("failed to download update: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download update: %s", resp.Status)
+	}
+
+	// Stage the download in the same directory so the final rename is atomic
+	tmp, err := os.CreateTemp(filepath.Dir(executable), ".gpm-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to stage update (is %s writable?): %w", filepath.Dir(executable), err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to download update: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to stage update: %w", err)
+	}
+
+	if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, asset.SHA256) {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("Checksum mismatch: the downloaded binary does not match the release manifest"),
+			styling.Hint(fmt.Sprintf("Expected %s, got %s. The binary was not replaced.", asset.SHA256, sum)))
+	}
+
+	// #nosec G302 - the CLI binary must be executable
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		return fmt.Errorf("failed to mark update executable: %w", err)
+	}
+
+	// Windows cannot overwrite a running binary, but it can rename it
+	if runtime.GOOS == "windows" {
+		old := executable + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(executable, old); err != nil {
+			return fmt.Errorf("failed to move the current binary aside: %w", err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, executable); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", executable, err)
+	}
+	return nil
+}
+
+func updateStatePath() (string, error) {
+	home := os.Getenv("HOME")
+	if home == "" {
+		var err error
+		if home, err = os.UserHomeDir(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(home, updateStateFile), nil
+}
+
+func loadUpdateState() updateState {
+	var state updateState
+	path, err := updateStatePath()
+	if err != nil {
+		return state
+	}
+	// Validate path to prevent directory traversal
+	if !strings.HasPrefix(filepath.Clean(path), filepath.Dir(path)) {
+		return state
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state
+	}
+	_ = json.Unmarshal(data, &state)
+	return state
+}
+
+func saveUpdateState(state updateState) error {
+	path, err := updateStatePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// latestKnownRelease returns the newest release version, fetching the
+// manifest only when the cached answer is older than a day
+func latestKnownRelease(now time.Time) string {
+	state := loadUpdateState()
+	if !state.CheckedAt.IsZero() && now.Sub(state.CheckedAt) < updateCheckInterval {
+		return state.Latest
+	}
+
+	client := api.NewHTTPClient(2 * time.Second)
+	release, err := fetchLatestRelease(client, updateURL())
+	// Record failed checks too so an unreachable server is retried tomorrow
+	// rather than slowing down every command
+	state = updateState{CheckedAt: now}
+	if err == nil {
+		state.Latest = release.Version
+	}
+	_ = saveUpdateState(state)
+	return state.Latest
+}
+
+// NotifyUpdate prints a one-line notice to stderr when a newer release is
+// available. It only runs once update_check is enabled, and is silent in
+// quiet and JSON modes, under $GPM_NO_UPDATE_CHECK, and for the self-update
+// command itself.
+func NotifyUpdate(cmd *cobra.Command, quiet, jsonOutput bool) {
+	if quiet || jsonOutput || !config.UpdateCheckEnabled() {
+		return
+	}
+	if cmd == selfUpdateCmd {
+		return
+	}
+	if flag := cmd.Flags().Lookup("json"); flag != nil && flag.Changed {
+		return
+	}
+
+	latest := latestKnownRelease(time.Now())
+	if !isNewerVersion(Version, latest) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s\n", styling.Muted(fmt.Sprintf("A new GPM CLI release is available: %s → %s (run 'gpm self-update')", Version, latest)))
+}
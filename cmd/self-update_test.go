@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gpm.sh/gpm/gpm-cli/internal/config"
+)
+
+func TestIsNewerVersion(t *testing.T) {
+	tests := []struct {
+		current string
+		latest  string
+		want    bool
+	}{
+		{"v0.1.0-alpha.9", "v0.1.0-alpha.10", true},
+		{"v0.1.0-alpha.9", "v0.1.0", true},
+		{"v0.1.0", "v0.1.0-alpha.10", false},
+		{"v0.1.0", "0.1.0", false},
+		{"0.1.0", "v0.2.0", true},
+		{"v1.2.3", "v1.10.0", true},
+		{"v1.10.0", "v1.9.9", false},
+		{"v1.0.0", "", false},
+		{"", "v1.0.0", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isNewerVersion(tt.current, tt.latest), "isNewerVersion(%q, %q)", tt.current, tt.latest)
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	binary := []byte("new gpm binary")
+	sum := sha256.Sum256(binary)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(binary)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	executable := filepath.Join(dir, "gpm")
+	require.NoError(t, os.WriteFile(executable, []byte("old gpm binary"), 0600))
+
+	// A checksum mismatch leaves the binary untouched
+	err := replaceExecutable(server.Client(), ReleaseAsset{URL: server.URL, SHA256: "deadbeef"}, executable)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Checksum mismatch")
+	data, _ := os.ReadFile(executable)
+	assert.Equal(t, "old gpm binary", string(data))
+
+	err = replaceExecutable(server.Client(), ReleaseAsset{URL: server.URL, SHA256: hex.EncodeToString(sum[:])}, executable)
+	require.NoError(t, err)
+	data, _ = os.ReadFile(executable)
+	assert.Equal(t, string(binary), string(data))
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1, "staged download should be cleaned up")
+}
+
+func TestLatestKnownReleaseThrottle(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = fmt.Fprint(w, `{"version": "v9.0.0"}`)
+	}))
+	defer server.Close()
+
+	config.SetConfigForTesting(&config.Config{UpdateURL: server.URL})
+	defer config.ResetConfigForTesting()
+
+	now := time.Now()
+	assert.Equal(t, "v9.0.0", latestKnownRelease(now))
+	assert.Equal(t, "v9.0.0", latestKnownRelease(now.Add(time.Hour)))
+	assert.Equal(t, 1, requests, "second check within a day should use the cache")
+
+	latestKnownRelease(now.Add(25 * time.Hour))
+	assert.Equal(t, 2, requests)
+
+	assert.False(t, config.UpdateCheckEnabled(), "the update check is opt-in")
+	config.SetUpdateCheck(true)
+	assert.True(t, config.UpdateCheckEnabled())
+	t.Setenv(config.NoUpdateCheckEnv, "1")
+	assert.False(t, config.UpdateCheckEnabled())
+}
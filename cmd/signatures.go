@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/config"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+// signingKeysTTL is how long a registry's signing keys are trusted before
+// they are fetched again. A signature made with a key not in the cache
+// fetches them straight away, so rotated keys are picked up sooner.
+const signingKeysTTL = 24 * time.Hour
+
+// cachedSigningKeys is a registry's signing keys as kept in the data directory
+type cachedSigningKeys struct {
+	Registry  string            `json:"registry"`
+	FetchedAt time.Time         `json:"fetched_at"`
+	Keys      []api.RegistryKey `json:"keys"`
+}
+
+// signingKeysPath returns where registryURL's signing keys are cached, or ""
+// when there is no data directory
+func signingKeysPath(registryURL string) string {
+	dir, err := config.DataDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(registryURL))
+	return filepath.Join(dir, "keys", hex.EncodeToString(sum[:8])+".json")
+}
+
+// registrySigningKeys returns the registry's signing keys, from the cache
+// unless it is stale or refresh is set
+func registrySigningKeys(client *api.Client, registryURL string, refresh bool) ([]api.RegistryKey, error) {
+	path := signingKeysPath(registryURL)
+	if path != "" && !refresh {
+		var cached cachedSigningKeys
+		if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &cached) == nil && // #nosec G304 - cache file in the data directory
+			cached.Registry == registryURL && time.Since(cached.FetchedAt) < signingKeysTTL {
+			return cached.Keys, nil
+		}
+	}
+
+	keys, err := client.GetSigningKeys()
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		// The cache only saves a request; failing to write it isn't an error
+		if data, err := json.MarshalIndent(cachedSigningKeys{Registry: registryURL, FetchedAt: time.Now(), Keys: keys}, "", "  "); err == nil {
+			if os.MkdirAll(filepath.Dir(path), 0750) == nil {
+				_ = os.WriteFile(path, append(data, '\n'), 0600)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// auditSignature verifies the registry's signature of name@version for
+// --audit-signatures. An invalid signature is always an error; a missing one,
+// or a registry that publishes no keys, is a warning unless strict.
+func auditSignature(client *api.Client, registryURL, name, version string, metadata *api.PackageMetadata, strict bool) (string, error) {
+	info := metadata.Versions[version]
+	if info == nil {
+		return "", fmt.Errorf("no metadata found for %s@%s", name, version)
+	}
+
+	keys, err := registrySigningKeys(client, registryURL, false)
+	if err != nil {
+		return "", err
+	}
+	if len(keys) == 0 {
+		return signatureProblem(fmt.Sprintf("%s doesn't publish signing keys, so %s@%s's signature can't be checked", registryURL, name, version), strict)
+	}
+
+	published := publishTime(client, name, version, metadata, keys)
+	err = api.VerifySignatures(name, version, info.Dist, published, keys)
+	var sigErr *api.SignatureError
+	if errors.As(err, &sigErr) && sigErr.UnknownKey {
+		// The registry may have rotated to a key newer than the cache
+		if keys, err = registrySigningKeys(client, registryURL, true); err != nil {
+			return "", err
+		}
+		err = api.VerifySignatures(name, version, info.Dist, published, keys)
+	}
+	if err == nil {
+		return "", nil
+	}
+	if errors.As(err, &sigErr) && sigErr.Missing {
+		return signatureProblem(err.Error(), strict)
+	}
+	return "", fmt.Errorf("%s\n\n%s",
+		styling.Error("Registry signature check failed: "+err.Error()),
+		styling.Hint("The package may have been tampered with after it was published; don't install it until the registry confirms it"))
+}
+
+// signatureProblem returns a problem that only fails the install under
+// --strict as a warning, or as an error when strict
+func signatureProblem(problem string, strict bool) (string, error) {
+	if strict {
+		return "", fmt.Errorf("%s\n\n%s",
+			styling.Error("Registry signature check failed: "+problem),
+			styling.Hint("Drop --strict to install with a warning instead"))
+	}
+	return problem, nil
+}
+
+// publishTime returns when name@version was published, fetching the full
+// metadata for it when the abbreviated metadata lacks it and an expired key
+// makes it matter. It returns the zero time when it can't be told.
+func publishTime(client *api.Client, name, version string, metadata *api.PackageMetadata, keys []api.RegistryKey) time.Time {
+	published := metadata.Time[version]
+	if published == "" {
+		expiring := false
+		for _, key := range keys {
+			expiring = expiring || key.Expires != nil
+		}
+		if !expiring {
+			return time.Time{}
+		}
+		if full, err := client.GetPackageMetadata(name); err == nil {
+			published = full.Time[version]
+		}
+	}
+	parsed, _ := time.Parse(time.RFC3339, published)
+	return parsed
+}
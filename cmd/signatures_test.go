@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/config"
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+)
+
+// testSigningKey is a registry signing key and its /-/npm/v1/keys entry
+type testSigningKey struct {
+	private *ecdsa.PrivateKey
+	entry   api.RegistryKey
+}
+
+func newTestSigningKey(t *testing.T, keyID string) *testSigningKey {
+	t.Helper()
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	require.NoError(t, err)
+	return &testSigningKey{private: private, entry: api.RegistryKey{
+		KeyID:   keyID,
+		KeyType: "ecdsa-sha2-nistp256",
+		Scheme:  "ecdsa-sha2-nistp256",
+		Key:     base64.StdEncoding.EncodeToString(der),
+	}}
+}
+
+func (k *testSigningKey) sign(t *testing.T, name, version, integrity string) api.PackageSignature {
+	t.Helper()
+	digest := sha256.Sum256([]byte(name + "@" + version + ":" + integrity))
+	sig, err := ecdsa.SignASN1(rand.Reader, k.private, digest[:])
+	require.NoError(t, err)
+	return api.PackageSignature{KeyID: k.entry.KeyID, Sig: base64.StdEncoding.EncodeToString(sig)}
+}
+
+func TestInstallAuditSignatures(t *testing.T) {
+	t.Setenv(config.DataDirEnv, t.TempDir())
+	const name = "com.test.signed"
+	oldKey := newTestSigningKey(t, "SHA256:old")
+	newKey := newTestSigningKey(t, "SHA256:new")
+	rotatedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var mu sync.Mutex
+	keys := []api.RegistryKey{oldKey.entry}
+	keyRequests := 0
+	metadata := &api.PackageMetadata{Name: name, Versions: map[string]*api.PackageVersion{}, Time: map[string]string{}}
+	publish := func(version string, published time.Time, signer *testSigningKey, tamper bool) {
+		integrity := "sha512-" + base64.StdEncoding.EncodeToString([]byte(name+version))
+		dist := &api.PackageDist{Integrity: integrity, Tarball: "https://example.com/" + version + ".tgz"}
+		if signer != nil {
+			signed := version
+			if tamper {
+				signed = "9.9.9"
+			}
+			dist.Signatures = []api.PackageSignature{signer.sign(t, name, signed, integrity)}
+		}
+		metadata.Versions[version] = &api.PackageVersion{Name: name, Version: version, Dist: dist}
+		metadata.Time[version] = published.Format(time.RFC3339)
+	}
+	publish("1.0.0", rotatedAt.AddDate(0, -1, 0), oldKey, false)
+	publish("1.1.0", rotatedAt.AddDate(0, -1, 0), oldKey, true)
+	publish("1.2.0", rotatedAt.AddDate(0, -1, 0), nil, false)
+	publish("2.0.0", rotatedAt.AddDate(0, 1, 0), newKey, false)
+	publish("2.1.0", rotatedAt.AddDate(0, 1, 0), oldKey, false)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/":
+		case "/-/npm/v1/keys":
+			keyRequests++
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+		case "/" + name:
+			_ = json.NewEncoder(w).Encode(metadata)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	installRegistry = server.URL
+	installAuditSignatures = true
+	defer func() {
+		installRegistry = ""
+		installAuditSignatures = false
+		installStrict = false
+	}()
+
+	installVersion := func(version string) (*InstallOutput, string, error) {
+		projectDir := t.TempDir()
+		require.NoError(t, setupUnityProject(projectDir))
+		require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "Packages"), 0755))
+		output := &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+		var err error
+		captureStdout(t, func() error {
+			err = installPackageWithEngine(engines.NewUnityAdapter(), projectDir, parsePackageSpec(name+"@"+version), output)
+			return nil
+		})
+		manifest, _ := os.ReadFile(filepath.Join(projectDir, "Packages", "manifest.json"))
+		return output, string(manifest), err
+	}
+
+	t.Run("valid signature", func(t *testing.T) {
+		output, manifest, err := installVersion("1.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, []string{name + "@1.0.0"}, output.Packages)
+		assert.Contains(t, manifest, `"`+name+`": "1.0.0"`)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		_, manifest, err := installVersion("1.1.0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Registry signature check failed")
+		assert.Contains(t, err.Error(), "invalid signature for key SHA256:old")
+		assert.NotContains(t, manifest, name)
+	})
+
+	t.Run("missing signature", func(t *testing.T) {
+		output, _, err := installVersion("1.2.0")
+		require.NoError(t, err)
+		require.Len(t, output.Warnings, 1)
+		assert.Contains(t, output.Warnings[0], "has no registry signature")
+
+		installStrict = true
+		defer func() { installStrict = false }()
+		_, manifest, err := installVersion("1.2.0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has no registry signature")
+		assert.NotContains(t, manifest, name)
+	})
+
+	t.Run("keys are cached", func(t *testing.T) {
+		mu.Lock()
+		requests := keyRequests
+		mu.Unlock()
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("key rotation", func(t *testing.T) {
+		mu.Lock()
+		expiredOld := oldKey.entry
+		expiredOld.Expires = &rotatedAt
+		keys = []api.RegistryKey{expiredOld, newKey.entry}
+		mu.Unlock()
+
+		// The cached keys predate the new one, which is fetched on sight
+		_, manifest, err := installVersion("2.0.0")
+		require.NoError(t, err)
+		assert.Contains(t, manifest, `"`+name+`": "2.0.0"`)
+		mu.Lock()
+		assert.Equal(t, 2, keyRequests)
+		mu.Unlock()
+
+		// Versions signed before the rotation still verify
+		_, _, err = installVersion("1.0.0")
+		require.NoError(t, err)
+
+		// The old key no longer vouches for anything published after it
+		_, _, err = installVersion("2.1.0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "which expired on 2024-06-01")
+	})
+}
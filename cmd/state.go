@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+
+	"gpm.sh/gpm/gpm-cli/internal/config"
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+)
+
+// projectStateDir returns where gpm keeps the state of the project dir is
+// in: engines.StateDirName in the project root unless state_dir says
+// otherwise. An absolute state_dir holds one subdirectory per project, named
+// after the project and a hash of its path so same-named projects don't
+// share one.
+func projectStateDir(dir string) string {
+	root := engines.FindProjectRoot(dir)
+	stateDir := config.GetConfig().StateDir
+	if stateDir == "" {
+		return filepath.Join(root, engines.StateDirName)
+	}
+	if !filepath.IsAbs(stateDir) {
+		return filepath.Join(root, stateDir)
+	}
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(stateDir, fmt.Sprintf("%s-%x", filepath.Base(root), sum[:6]))
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/config"
+	"gpm.sh/gpm/gpm-cli/internal/store"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+var storeGCDryRun bool
+
+var storeCmd = &cobra.Command{
+	Use:   "store",
+	Short: "Manage the shared tarball store",
+	Long: `Manage the tarball store shared by every project on this machine.
+
+Downloaded package tarballs are kept in the store, named by their sha512
+digest, so installing a package another project already uses reads it from
+disk instead of the registry. The store lives in the gpm data directory:
+$` + config.DataDirEnv + `, else $XDG_DATA_HOME/gpm, else ~/.local/share/gpm.
+
+Examples:
+  gpm store gc
+  gpm store gc --dry-run`,
+}
+
+var storeGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove tarballs no project uses anymore",
+	Long: `Remove tarballs from the store that no existing project uses.
+
+Projects that have been deleted since they installed from the store are
+forgotten first, so their tarballs are removed unless another project uses
+them too.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return gcStore(storeGCDryRun)
+	},
+}
+
+func init() {
+	storeCmd.AddCommand(storeGCCmd)
+
+	storeGCCmd.Flags().BoolVar(&storeGCDryRun, "dry-run", false, "Show what would be removed without removing it")
+}
+
+// openTarballStore returns the shared tarball store, or nil when there is no
+// data directory to keep it in
+var openTarballStore = func() *store.Store {
+	dir, err := config.DataDir()
+	if err != nil {
+		return nil
+	}
+	return store.New(filepath.Join(dir, "store"))
+}
+
+// gcStore prunes the tarball store
+func gcStore(dryRun bool) error {
+	tarballStore := openTarballStore()
+	if tarballStore == nil {
+		return fmt.Errorf("%s\n\n%s",
+			styling.Error("cannot find the tarball store"),
+			styling.Hint("Set "+config.DataDirEnv+" to the directory gpm should keep it in"))
+	}
+	result, err := tarballStore.GC(dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+	for _, project := range result.Forgotten {
+		fmt.Printf("%s %s\n", styling.Label("Forgot deleted project:"), project)
+	}
+	if len(result.Removed) == 0 {
+		fmt.Printf("%s Nothing to remove from %s\n", styling.Success("✓"), tarballStore.Dir())
+		return nil
+	}
+	fmt.Printf("%s %s %d tarball(s), %s\n", styling.Success("✓"), verb, len(result.Removed), formatSize(result.Freed))
+	return nil
+}
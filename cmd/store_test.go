@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+	"gpm.sh/gpm/gpm-cli/internal/store"
+)
+
+func TestInstallSharesTarballStore(t *testing.T) {
+	tarball, integrity := writeTestTarball(t, map[string]string{
+		"package/package.json": `{"name": "com.test.stored", "version": "1.0.0"}`,
+	})
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(tarball)
+	}))
+	defer server.Close()
+	tarballURL := server.URL + "/com.test.stored/-/com.test.stored-1.0.0.tgz#" + integrity
+
+	tarballStore := store.New(t.TempDir())
+	defaultStore := openTarballStore
+	openTarballStore = func() *store.Store { return tarballStore }
+	defer func() { openTarballStore = defaultStore }()
+	allowLoopbackTarballs(t)
+	installRegistry = server.URL
+	defer func() { installRegistry = "" }()
+
+	// Each install is its own invocation, sharing nothing but the store
+	install := func(projectDir string) {
+		defer installDownloads.clear()
+		require.NoError(t, setupUnityProject(projectDir))
+		output := &InstallOutput{Packages: []string{}, Diff: engines.NewManifestDiff()}
+		require.NoError(t, installPackageWithEngine(engines.NewUnityAdapter(), projectDir, parsePackageSpec(tarballURL), output))
+		assert.Equal(t, []string{"com.test.stored@1.0.0"}, output.Packages)
+		assert.FileExists(t, filepath.Join(projectDir, "Packages", "com.test.stored", "package.json"))
+	}
+
+	first := t.TempDir()
+	install(first)
+	assert.Equal(t, int32(1), requests.Load())
+
+	second := t.TempDir()
+	install(second)
+	assert.Equal(t, int32(1), requests.Load(), "the second project should install from the store")
+
+	t.Run("gc keeps tarballs a project uses", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(first))
+		result, err := tarballStore.GC(false)
+		require.NoError(t, err)
+		assert.Len(t, result.Forgotten, 1)
+		assert.Empty(t, result.Removed)
+	})
+
+	t.Run("gc removes tarballs no project uses", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(second))
+		result, err := tarballStore.GC(false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{integrity}, result.Removed)
+		assert.Equal(t, int64(len(tarball)), result.Freed)
+
+		found, err := tarballStore.Get(integrity, filepath.Join(t.TempDir(), "a.tgz"))
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+}
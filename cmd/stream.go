@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Package events reported by --json-stream, in the order a package goes
+// through them. Not every package passes every stage: a registry package
+// Unity resolves itself is never downloaded by gpm.
+const (
+	EventStarted    = "started"
+	EventResolved   = "resolved"
+	EventDownloaded = "downloaded"
+	EventInstalled  = "installed"
+	EventFailed     = "failed"
+	EventSummary    = "summary"
+)
+
+// StreamEvent is one line of --json-stream output
+type StreamEvent struct {
+	Event string `json:"event"`
+	// Package is the spec as given until the package is resolved, then its name
+	Package string `json:"package"`
+	Version string `json:"version,omitempty"`
+	Project string `json:"project,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// StreamSummary is the last line of --json-stream output. Succeeded and
+// Failed count the packages that were started.
+type StreamSummary struct {
+	Event     string   `json:"event"`
+	Success   bool     `json:"success"`
+	Succeeded int      `json:"succeeded"`
+	Failed    int      `json:"failed"`
+	Packages  []string `json:"packages"`
+	Warnings  []string `json:"warnings,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// eventStream writes --json-stream output: newline-delimited JSON, one object
+// per package event as it happens and a summary object at the end, so CI can
+// follow long multi-package runs. A nil stream drops everything.
+type eventStream struct {
+	encoder *json.Encoder
+	started int
+	failed  int
+}
+
+func newEventStream(w io.Writer) *eventStream {
+	return &eventStream{encoder: json.NewEncoder(w)}
+}
+
+func (s *eventStream) emit(event StreamEvent) {
+	if s == nil {
+		return
+	}
+	switch event.Event {
+	case EventStarted:
+		s.started++
+	case EventFailed:
+		s.failed++
+	}
+	_ = s.encoder.Encode(event)
+}
+
+// fail emits a failed event for a package spec
+func (s *eventStream) fail(packageSpec, project string, err error) {
+	s.emit(StreamEvent{Event: EventFailed, Package: packageSpec, Project: project, Error: err.Error()})
+}
+
+func (s *eventStream) summary(summary StreamSummary) error {
+	if s == nil {
+		return nil
+	}
+	summary.Event = EventSummary
+	summary.Succeeded = s.started - s.failed
+	summary.Failed = s.failed
+	if summary.Packages == nil {
+		summary.Packages = []string{}
+	}
+	return s.encoder.Encode(summary)
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/engines"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+var (
+	treeJSON        bool
+	treeDot         bool
+	treeDepth       int
+	treePackagesDir string
+)
+
+var treeCmd = &cobra.Command{
+	Use:     "tree",
+	Aliases: []string{"graph"},
+	Short:   "Show the project's dependency tree",
+	Long: `Show the project's resolved dependencies as a tree.
+
+The tree is read from Unity's Packages/packages-lock.json, which records every
+package Unity resolved, direct and transitive. A package that already appeared
+earlier in the tree is marked (deduped) and not expanded again; a dependency
+back onto a package higher up the same branch is marked (circular).
+
+Examples:
+  gpm tree                      # Indented tree
+  gpm tree --depth 1            # Direct dependencies and what they depend on
+  gpm tree --json               # Nested JSON
+  gpm tree --dot | dot -Tsvg -o deps.svg  # Render with Graphviz`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectDir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		return tree(projectDir)
+	},
+}
+
+func init() {
+	treeCmd.Flags().BoolVar(&treeJSON, "json", false, "Output the tree as JSON")
+	treeCmd.Flags().BoolVar(&treeDot, "dot", false, "Output the graph in Graphviz DOT format")
+	treeCmd.Flags().IntVar(&treeDepth, "depth", 0, "Levels of dependencies to show below the direct ones (0: all)")
+	treeCmd.Flags().StringVar(&treePackagesDir, "packages-dir", "", "Unity packages directory relative to the project (default: $"+engines.UnityPackagesDirEnv+" or Packages)")
+}
+
+// TreeNode is one package in the dependency tree. A deduped or circular
+// node is not expanded.
+type TreeNode struct {
+	Name         string      `json:"name"`
+	Version      string      `json:"version"`
+	Deduped      bool        `json:"deduped,omitempty"`
+	Circular     bool        `json:"circular,omitempty"`
+	Missing      bool        `json:"missing,omitempty"`
+	Dependencies []*TreeNode `json:"dependencies,omitempty"`
+}
+
+func tree(projectDir string) error {
+	if treeJSON && treeDot {
+		return fmt.Errorf("--json and --dot can't be used together")
+	}
+
+	adapter := engines.NewUnityAdapter()
+	adapter.SetPackagesDir(treePackagesDir)
+	lock, err := adapter.LoadLock(projectDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%s\n\n%s",
+				styling.Error("No "+engines.UnityPackagesLockFile+" found"),
+				styling.Hint("gpm tree reads the dependencies Unity resolved; open the project in Unity to create the lockfile"))
+		}
+		return err
+	}
+
+	roots := buildDependencyTree(lock, treeDepth)
+	switch {
+	case treeJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(roots)
+	case treeDot:
+		fmt.Print(dependencyDot(roots))
+	default:
+		fmt.Println(styling.Value(filepath.Base(projectDir)))
+		fmt.Print(dependencyTreeText(roots))
+	}
+	return nil
+}
+
+// buildDependencyTree expands the lock's direct dependencies depth-first in
+// name order. depth limits the levels below the direct dependencies; 0
+// expands everything.
+func buildDependencyTree(lock *engines.UnityPackagesLock, depth int) []*TreeNode {
+	var direct []string
+	for name, entry := range lock.Dependencies {
+		if entry != nil && entry.Depth == 0 {
+			direct = append(direct, name)
+		}
+	}
+	sort.Strings(direct)
+
+	expanded := make(map[string]bool)
+	onPath := make(map[string]bool)
+	var build func(name, version string, level int) *TreeNode
+	build = func(name, version string, level int) *TreeNode {
+		entry := lock.Dependencies[name]
+		if entry == nil {
+			return &TreeNode{Name: name, Version: version, Missing: true}
+		}
+		node := &TreeNode{Name: name, Version: entry.Version}
+		switch {
+		case onPath[name]:
+			node.Circular = true
+			return node
+		case expanded[name]:
+			node.Deduped = true
+			return node
+		case depth > 0 && level >= depth:
+			return node
+		}
+
+		expanded[name] = true
+		onPath[name] = true
+		children := make([]string, 0, len(entry.Dependencies))
+		for child := range entry.Dependencies {
+			children = append(children, child)
+		}
+		sort.Strings(children)
+		for _, child := range children {
+			node.Dependencies = append(node.Dependencies, build(child, entry.Dependencies[child], level+1))
+		}
+		onPath[name] = false
+		return node
+	}
+
+	roots := make([]*TreeNode, 0, len(direct))
+	for _, name := range direct {
+		roots = append(roots, build(name, "", 0))
+	}
+	return roots
+}
+
+// dependencyTreeText draws the tree with box-drawing branches, or ASCII ones
+// in plain output
+func dependencyTreeText(roots []*TreeNode) string {
+	var b strings.Builder
+	var draw func(nodes []*TreeNode, prefix string)
+	draw = func(nodes []*TreeNode, prefix string) {
+		for i, node := range nodes {
+			branch, indent := "├── ", "│   "
+			if i == len(nodes)-1 {
+				branch, indent = "└── ", "    "
+			}
+			if styling.Plain {
+				branch, indent = strings.NewReplacer("├", "|", "└", "`", "─", "-").Replace(branch), strings.Replace(indent, "│", "|", 1)
+			}
+			fmt.Fprintf(&b, "%s%s%s@%s%s\n", prefix, branch, node.Name, node.Version, treeNodeMarker(node))
+			draw(node.Dependencies, prefix+indent)
+		}
+	}
+	draw(roots, "")
+	return b.String()
+}
+
+func treeNodeMarker(node *TreeNode) string {
+	switch {
+	case node.Missing:
+		return " (missing)"
+	case node.Circular:
+		return " (circular)"
+	case node.Deduped:
+		return " (deduped)"
+	}
+	return ""
+}
+
+// dependencyDot renders the tree as a Graphviz digraph. Each package is one
+// node, so deduped packages simply gain another incoming edge; edges that
+// close a cycle are dashed.
+func dependencyDot(roots []*TreeNode) string {
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n")
+	seen := make(map[string]bool)
+	var edges func(node *TreeNode)
+	edges = func(node *TreeNode) {
+		for _, child := range node.Dependencies {
+			edge := fmt.Sprintf("  %q -> %q", node.Name+"@"+node.Version, child.Name+"@"+child.Version)
+			switch {
+			case child.Circular:
+				edge += ` [style=dashed, label="circular"]`
+			case child.Missing:
+				edge += ` [color=red, label="missing"]`
+			}
+			if !seen[edge] {
+				seen[edge] = true
+				b.WriteString(edge + ";\n")
+			}
+			edges(child)
+		}
+	}
+	for _, root := range roots {
+		fmt.Fprintf(&b, "  %q;\n", root.Name+"@"+root.Version)
+		edges(root)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
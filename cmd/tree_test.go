@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTreeLock writes a packages-lock.json for A→B, A→C, B→C
+func writeTreeLock(t *testing.T) string {
+	projectDir := filepath.Join(t.TempDir(), "game")
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "Packages"), 0755))
+	lock := `{
+  "dependencies": {
+    "com.test.a": {"version": "1.0.0", "depth": 0, "source": "registry", "dependencies": {"com.test.b": "2.0.0", "com.test.c": "3.0.0"}},
+    "com.test.b": {"version": "2.0.0", "depth": 1, "source": "registry", "dependencies": {"com.test.c": "3.0.0"}},
+    "com.test.c": {"version": "3.0.0", "depth": 1, "source": "registry"}
+  }
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Packages", "packages-lock.json"), []byte(lock), 0644))
+	return projectDir
+}
+
+func TestTree(t *testing.T) {
+	projectDir := writeTreeLock(t)
+
+	t.Run("text", func(t *testing.T) {
+		output := captureStdout(t, func() error { return tree(projectDir) })
+		assert.Contains(t, output, "game")
+		assert.Contains(t, output, "└── com.test.a@1.0.0\n"+
+			"    ├── com.test.b@2.0.0\n"+
+			"    │   └── com.test.c@3.0.0\n"+
+			"    └── com.test.c@3.0.0 (deduped)\n")
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		treeDot = true
+		defer func() { treeDot = false }()
+
+		output := captureStdout(t, func() error { return tree(projectDir) })
+		assert.Equal(t, "digraph dependencies {\n"+
+			"  \"com.test.a@1.0.0\";\n"+
+			"  \"com.test.a@1.0.0\" -> \"com.test.b@2.0.0\";\n"+
+			"  \"com.test.b@2.0.0\" -> \"com.test.c@3.0.0\";\n"+
+			"  \"com.test.a@1.0.0\" -> \"com.test.c@3.0.0\";\n"+
+			"}\n", output)
+	})
+
+	t.Run("json with depth", func(t *testing.T) {
+		treeJSON = true
+		treeDepth = 1
+		defer func() {
+			treeJSON = false
+			treeDepth = 0
+		}()
+
+		output := captureStdout(t, func() error { return tree(projectDir) })
+		var roots []*TreeNode
+		require.NoError(t, json.Unmarshal([]byte(output), &roots))
+		require.Len(t, roots, 1)
+		require.Len(t, roots[0].Dependencies, 2)
+		b := roots[0].Dependencies[0]
+		assert.Equal(t, "com.test.b", b.Name)
+		assert.Empty(t, b.Dependencies, "depth 1 shows only the direct dependencies' dependencies")
+		assert.False(t, roots[0].Dependencies[1].Deduped, "a package cut off by --depth isn't counted as shown")
+	})
+
+	t.Run("circular", func(t *testing.T) {
+		lockPath := filepath.Join(projectDir, "Packages", "packages-lock.json")
+		cyclic := `{"dependencies": {
+  "com.test.a": {"version": "1.0.0", "depth": 0, "dependencies": {"com.test.b": "2.0.0"}},
+  "com.test.b": {"version": "2.0.0", "depth": 1, "dependencies": {"com.test.a": "1.0.0"}}
+}}`
+		require.NoError(t, os.WriteFile(lockPath, []byte(cyclic), 0644))
+
+		output := captureStdout(t, func() error { return tree(projectDir) })
+		assert.Contains(t, output, "        └── com.test.a@1.0.0 (circular)\n")
+	})
+
+	t.Run("no lockfile", func(t *testing.T) {
+		err := tree(t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "No packages-lock.json found")
+	})
+}
@@ -8,8 +8,6 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
-	"gpm.sh/gpm/gpm-cli/internal/api"
-	"gpm.sh/gpm/gpm-cli/internal/config"
 	"gpm.sh/gpm/gpm-cli/internal/styling"
 	"gpm.sh/gpm/gpm-cli/internal/validation"
 )
@@ -40,6 +38,7 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	save, _ := cmd.Flags().GetBool("save")
 	global, _ := cmd.Flags().GetBool("global")
+	registryFlag, _ := cmd.Flags().GetString("registry")
 
 	if global {
 		return fmt.Errorf("%s", styling.Error("global package updates not yet implemented"))
@@ -71,7 +70,7 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	client := api.NewClient(config.GetConfig().Registry, config.GetToken())
+	client := effectiveClient(registryFlag, ".")
 	updates := make(map[string]string)
 
 	for _, pkgName := range packagesToUpdate {
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/styling"
+)
+
+var verifyChecksums string
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify --checksums <file> [tarball...]",
+	Short: "Verify tarballs against a checksums file",
+	Long: `Verify package tarballs against a checksums file written by
+'gpm pack --checksums' or 'gpm publish --checksums'.
+
+Without tarball arguments every entry is checked, resolving file names
+relative to the checksums file. The sha512 lines are also understood by
+'sha512sum -c', run from the checksums file's directory.
+
+Examples:
+  gpm pack --pack-destination dist --checksums dist/SHA512SUMS
+  gpm verify --checksums dist/SHA512SUMS
+  gpm verify --checksums dist/SHA512SUMS downloaded.tgz`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return verify(verifyChecksums, args)
+	},
+}
+
+func init() {
+	verifyCmd.Flags().StringVar(&verifyChecksums, "checksums", "", "Checksums file to verify against (required)")
+	_ = verifyCmd.MarkFlagRequired("checksums")
+}
+
+// ChecksumEntry is one tarball in a checksums file
+type ChecksumEntry struct {
+	Filename  string `json:"filename"`
+	Sha1      string `json:"sha1"`
+	Sha512    string `json:"sha512"`
+	Integrity string `json:"integrity"`
+}
+
+// formatChecksums renders entries in sha512sum format, with the sha1 and
+// integrity of each tarball in comment lines that sha512sum skips
+func formatChecksums(entries []ChecksumEntry) string {
+	var b strings.Builder
+	b.WriteString("# gpm checksums: verify with 'gpm verify --checksums <file>' or 'sha512sum -c <file>'\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "# sha1 %s  %s\n", entry.Sha1, entry.Filename)
+		fmt.Fprintf(&b, "# integrity %s  %s\n", entry.Integrity, entry.Filename)
+		fmt.Fprintf(&b, "%s  %s\n", entry.Sha512, entry.Filename)
+	}
+	return b.String()
+}
+
+// writeChecksumsFile writes entries to path in the formatChecksums format
+func writeChecksumsFile(path string, entries []ChecksumEntry) error {
+	if err := os.WriteFile(path, []byte(formatChecksums(entries)), 0600); err != nil {
+		return fmt.Errorf("failed to write checksums file: %w", err)
+	}
+	return nil
+}
+
+// checksumEntry builds the entry for the tarball at tarballPath, naming it
+// relative to the checksums file so the two can be moved together
+func checksumEntry(checksumsPath, tarballPath, sha1Hex, sha512Hex, integrity string) ChecksumEntry {
+	filename := filepath.Base(tarballPath)
+	if dir, err := filepath.Abs(filepath.Dir(checksumsPath)); err == nil {
+		if abs, err := filepath.Abs(tarballPath); err == nil {
+			if rel, err := filepath.Rel(dir, abs); err == nil {
+				filename = filepath.ToSlash(rel)
+			}
+		}
+	}
+	return ChecksumEntry{Filename: filename, Sha1: sha1Hex, Sha512: sha512Hex, Integrity: integrity}
+}
+
+// readChecksumsFile parses a checksums file. Plain "<sha512>  <file>" lines
+// from other tools are accepted too.
+func readChecksumsFile(path string) ([]ChecksumEntry, error) {
+	file, err := os.Open(path) // #nosec G304 - checksums file named by the user
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checksums file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var entries []ChecksumEntry
+	index := make(map[string]int)
+	entry := func(name string) *ChecksumEntry {
+		i, ok := index[name]
+		if !ok {
+			i = len(entries)
+			index[name] = i
+			entries = append(entries, ChecksumEntry{Filename: name})
+		}
+		return &entries[i]
+	}
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if comment, ok := strings.CutPrefix(line, "#"); ok {
+			kind, rest, _ := strings.Cut(strings.TrimSpace(comment), " ")
+			value, name, found := strings.Cut(rest, "  ")
+			if !found || (kind != "sha1" && kind != "integrity") {
+				continue
+			}
+			if kind == "sha1" {
+				entry(name).Sha1 = value
+			} else {
+				entry(name).Integrity = value
+			}
+			continue
+		}
+
+		sum, name, found := strings.Cut(line, " ")
+		name = strings.TrimPrefix(strings.TrimPrefix(name, " "), "*")
+		if !found || name == "" || len(sum) != 128 {
+			return nil, fmt.Errorf("%s:%d: expected '<sha512>  <file>'", path, lineNumber)
+		}
+		entry(name).Sha512 = strings.ToLower(sum)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checksums file: %w", err)
+	}
+	return entries, nil
+}
+
+func verify(checksumsPath string, tarballs []string) error {
+	entries, err := readChecksumsFile(checksumsPath)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no checksums found in %s", checksumsPath)
+	}
+
+	// Explicit tarballs are matched to entries by base name
+	type check struct {
+		entry ChecksumEntry
+		path  string
+	}
+	var checks []check
+	if len(tarballs) == 0 {
+		dir := filepath.Dir(checksumsPath)
+		for _, entry := range entries {
+			checks = append(checks, check{entry, filepath.Join(dir, filepath.FromSlash(entry.Filename))})
+		}
+	} else {
+		for _, tarball := range tarballs {
+			var matched *ChecksumEntry
+			for i := range entries {
+				if filepath.Base(entries[i].Filename) == filepath.Base(tarball) {
+					matched = &entries[i]
+					break
+				}
+			}
+			if matched == nil {
+				return fmt.Errorf("%s\n\n%s",
+					styling.Error(fmt.Sprintf("%s is not listed in %s", filepath.Base(tarball), checksumsPath)),
+					styling.Hint("Tarballs are matched to checksums by file name"))
+			}
+			checks = append(checks, check{*matched, tarball})
+		}
+	}
+
+	failed := 0
+	for _, c := range checks {
+		if err := verifyChecksumEntry(c.path, c.entry); err != nil {
+			failed++
+			fmt.Printf("%s %s: %v\n", styling.Error("✗"), c.path, err)
+			continue
+		}
+		fmt.Printf("%s %s: OK\n", styling.Success("✓"), c.path)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tarball(s) failed verification", failed, len(checks))
+	}
+	return nil
+}
+
+// verifyChecksumEntry hashes the tarball and compares every checksum the
+// entry records
+func verifyChecksumEntry(tarballPath string, entry ChecksumEntry) error {
+	sha1Bytes, sha512Bytes, err := calculateTarballHashes(tarballPath)
+	if err != nil {
+		return err
+	}
+	if entry.Sha512 != "" && hex.EncodeToString(sha512Bytes) != entry.Sha512 {
+		return fmt.Errorf("sha512 mismatch")
+	}
+	if entry.Sha1 != "" && hex.EncodeToString(sha1Bytes) != entry.Sha1 {
+		return fmt.Errorf("sha1 mismatch")
+	}
+	if entry.Integrity != "" && "sha512-"+base64.StdEncoding.EncodeToString(sha512Bytes) != entry.Integrity {
+		return fmt.Errorf("integrity mismatch")
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"runtime"
 
 	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/internal/api"
+	"gpm.sh/gpm/gpm-cli/internal/config"
 	"gpm.sh/gpm/gpm-cli/internal/styling"
 )
 
@@ -34,3 +36,13 @@ func version(cmd *cobra.Command, args []string) {
 
 	fmt.Println(styling.Separator())
 }
+
+// ConfigureUserAgent sets the User-Agent sent with every request: the
+// user_agent config value when set, otherwise gpm-cli/<version> (<os>/<arch>)
+func ConfigureUserAgent() {
+	userAgent := config.GetConfig().UserAgent
+	if userAgent == "" {
+		userAgent = api.DefaultUserAgent(Version)
+	}
+	api.SetUserAgent(userAgent)
+}
@@ -4,39 +4,65 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-	"gpm.sh/gpm/gpm-cli/internal/api"
 	"gpm.sh/gpm/gpm-cli/internal/config"
 	"gpm.sh/gpm/gpm-cli/internal/styling"
 )
 
+var whoamiRefresh bool
+
 var whoamiCmd = &cobra.Command{
 	Use:   "whoami",
 	Short: "Show current user information",
-	Long:  `Display information about the currently authenticated user`,
+	Long: `Display information about the currently authenticated user.
+
+The username is cached in the config for a day after the registry confirms
+it, and dropped whenever the token changes; --refresh asks the registry again.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return whoami()
 	},
 }
 
+func init() {
+	whoamiCmd.Flags().BoolVar(&whoamiRefresh, "refresh", false, "Ask the registry instead of using the cached username")
+}
+
 func whoami() error {
-	cfg := config.GetConfig()
-	if cfg.Token == "" {
+	if config.ResolveToken("", ".").Value == "" {
 		return fmt.Errorf("not authenticated. Please run 'gpm login' first")
 	}
 
-	client := api.NewClient(cfg.Registry, cfg.Token)
-
-	fmt.Println(styling.Info("Fetching user information..."))
-
-	resp, err := client.Whoami()
+	username, err := currentUsername(whoamiRefresh)
 	if err != nil {
 		return fmt.Errorf("failed to get user info: %v", err)
 	}
 
 	fmt.Println(styling.Header("User Information"))
 	fmt.Println(styling.Separator())
-	fmt.Printf("%s %s\n", styling.Label("Username:"), styling.Value(resp.Username))
+	fmt.Printf("%s %s\n", styling.Label("Username:"), styling.Value(username))
 
 	fmt.Println(styling.Separator())
 	return nil
 }
+
+// currentUsername returns the authenticated user's name, from the config when
+// whoami confirmed it within config.IdentityCacheTTL and from the registry
+// otherwise. A fetched name is cached for the next command.
+func currentUsername(refresh bool) (string, error) {
+	if !refresh {
+		if username, ok := config.CachedUsername(config.IdentityCacheTTL); ok {
+			return username, nil
+		}
+	}
+
+	fmt.Println(styling.Info("Fetching user information..."))
+	resp, err := effectiveClient("", ".").Whoami()
+	if err != nil {
+		return "", err
+	}
+
+	config.CacheUsername(resp.Username)
+	if err := config.SaveConfig(); err != nil {
+		fmt.Printf("%s failed to cache username: %v\n", styling.Warning("⚠"), err)
+	}
+	return resp.Username, nil
+}
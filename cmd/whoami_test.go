@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gpm.sh/gpm/gpm-cli/internal/api"
@@ -128,3 +132,117 @@ func TestWhoamiCmdStructure(t *testing.T) {
 	require.Len(t, whoamiSubCmd, 1)
 	assert.Equal(t, "whoami", whoamiSubCmd[0].Use)
 }
+
+func TestWhoamiCachesUsername(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	defer func() {
+		_ = os.Setenv("HOME", originalHome)
+		config.ResetConfigForTesting()
+	}()
+	_ = os.Setenv("HOME", t.TempDir())
+	config.InitConfig()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "cacheduser"})
+	}))
+	defer server.Close()
+	config.SetRegistry(server.URL)
+	config.SetToken("token-one")
+
+	output := captureStdout(t, whoami)
+	assert.Contains(t, output, "cacheduser")
+	assert.Equal(t, 1, requests)
+
+	// Within the TTL the cached name is used, even after a restart
+	config.InitConfig()
+	output = captureStdout(t, whoami)
+	assert.Contains(t, output, "cacheduser")
+	assert.NotContains(t, output, "Fetching")
+	assert.Equal(t, 1, requests, "second read should not hit the registry")
+
+	whoamiRefresh = true
+	require.NoError(t, whoami())
+	whoamiRefresh = false
+	assert.Equal(t, 2, requests, "--refresh should ask the registry")
+
+	config.SetToken("token-two")
+	require.NoError(t, whoami())
+	assert.Equal(t, 3, requests, "a new token should invalidate the cache")
+}
+
+func TestWhoamiUsesEffectiveCredentials(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(api.WhoamiResponse{Username: "testuser"})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		gpmrc  string
+		npmrc  string
+		env    map[string]string
+		bearer string
+	}{
+		{
+			name:   "user config",
+			gpmrc:  "registry: " + server.URL + "\ntoken: user-token\n",
+			bearer: "user-token",
+		},
+		{
+			name:   "env",
+			gpmrc:  "token: user-token\n",
+			env:    map[string]string{config.RegistryEnv: server.URL, config.TokenEnv: "env-token"},
+			bearer: "env-token",
+		},
+		{
+			name:   "profile",
+			gpmrc:  "token: user-token\nprofiles:\n  ci:\n    registry: " + server.URL + "\n    token: profile-token\n",
+			env:    map[string]string{config.ProfileEnv: "ci"},
+			bearer: "profile-token",
+		},
+		{
+			name:   "project .npmrc",
+			gpmrc:  "token: user-token\n",
+			npmrc:  "registry=" + server.URL + "\n" + strings.TrimPrefix(server.URL, "http:") + "/:_authToken=npmrc-token\n",
+			bearer: "npmrc-token",
+		},
+		{
+			name:  "project .npmrc registry without its token",
+			gpmrc: "token: user-token\n",
+			npmrc: "registry=" + server.URL + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			require.NoError(t, os.WriteFile(filepath.Join(home, ".gpmrc"), []byte(tt.gpmrc), 0600))
+			projectDir := t.TempDir()
+			if tt.npmrc != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".npmrc"), []byte(tt.npmrc), 0600))
+			}
+			oldWd, _ := os.Getwd()
+			require.NoError(t, os.Chdir(projectDir))
+			defer func() { _ = os.Chdir(oldWd) }()
+			viper.Reset()
+			config.InitConfig()
+			defer config.ResetConfigForTesting()
+
+			authorization = ""
+			_, err := currentUsername(true)
+			require.NoError(t, err)
+			if tt.bearer == "" {
+				assert.Empty(t, authorization, "the user token must not follow a project-chosen registry")
+			} else {
+				assert.Equal(t, "Bearer "+tt.bearer, authorization)
+			}
+		})
+	}
+}
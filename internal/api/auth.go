@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// AuthScheme is how a Client sends its token to the registry
+type AuthScheme string
+
+const (
+	// AuthSchemeBearer sends "Authorization: Bearer <token>"
+	AuthSchemeBearer AuthScheme = "bearer"
+	// AuthSchemeBasic sends "Authorization: Basic <credentials>". The token
+	// is npm's _auth value, base64 "user:password"; a plain "user:password"
+	// token is encoded first.
+	AuthSchemeBasic AuthScheme = "basic"
+	// AuthSchemeLegacy sends "Authorization: token <token>", as older
+	// self-hosted registries expect
+	AuthSchemeLegacy AuthScheme = "legacy"
+)
+
+// authSchemeFor returns the scheme a registry takes when the Client wasn't
+// given one
+var authSchemeFor = func(registryURL string) AuthScheme { return AuthSchemeBearer }
+
+// SetAuthSchemes sets how the scheme for each registry is looked up, for
+// every Client that wasn't given one with SetAuthScheme
+func SetAuthSchemes(lookup func(registryURL string) AuthScheme) {
+	authSchemeFor = lookup
+}
+
+// SetAuthScheme fixes how the client sends its token, overriding the
+// per-registry lookup
+func (c *Client) SetAuthScheme(scheme AuthScheme) {
+	c.authScheme = scheme
+}
+
+// AuthScheme returns how the client sends its token
+func (c *Client) AuthScheme() AuthScheme {
+	if c.authScheme != "" {
+		return c.authScheme
+	}
+	return authSchemeFor(c.baseURL)
+}
+
+// authorization returns the Authorization header value carrying credentials
+// in the registry's scheme
+func (c *Client) authorization(credentials string) string {
+	switch c.AuthScheme() {
+	case AuthSchemeBasic:
+		if strings.Contains(credentials, ":") {
+			credentials = base64.StdEncoding.EncodeToString([]byte(credentials))
+		}
+		return "Basic " + credentials
+	case AuthSchemeLegacy:
+		return "token " + credentials
+	default:
+		return "Bearer " + credentials
+	}
+}
+
+// authorize adds the client's token to req in the registry's scheme
+func (c *Client) authorize(req *http.Request) {
+	if c.token != "" {
+		req.Header.Set("Authorization", c.authorization(c.token))
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_AuthScheme(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": "com.test.auth"})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		scheme AuthScheme
+		token  string
+		want   string
+	}{
+		{"bearer", AuthSchemeBearer, "secret", "Bearer secret"},
+		{"basic with npm's _auth value", AuthSchemeBasic, "dXNlcjpwYXNz", "Basic dXNlcjpwYXNz"},
+		{"basic with user:password", AuthSchemeBasic, "user:pass", "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))},
+		{"legacy", AuthSchemeLegacy, "secret", "token secret"},
+		{"no token", AuthSchemeBasic, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(server.URL, tt.token)
+			client.SetAuthScheme(tt.scheme)
+			_, err := client.GetPackageMetadata("com.test.auth")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, authorization)
+		})
+	}
+
+	t.Run("looked up per registry", func(t *testing.T) {
+		defer SetAuthSchemes(func(string) AuthScheme { return AuthSchemeBearer })
+		SetAuthSchemes(func(registryURL string) AuthScheme {
+			if registryURL == server.URL {
+				return AuthSchemeLegacy
+			}
+			return AuthSchemeBearer
+		})
+
+		_, err := NewClient(server.URL, "secret").GetPackageMetadata("com.test.auth")
+		require.NoError(t, err)
+		assert.Equal(t, "token secret", authorization)
+		assert.Equal(t, AuthSchemeBearer, NewClient("https://registry.gpm.sh", "secret").AuthScheme())
+	})
+
+	t.Run("publish preview shows the scheme", func(t *testing.T) {
+		tarballPath, _ := writeTestTarball(t, "com.test.auth", "1.0.0", nil)
+		client := NewClient(server.URL, "secret")
+		client.SetAuthScheme(AuthSchemeLegacy)
+		preview, err := client.PreviewPublish(&PublishRequest{Name: "com.test.auth", Version: "1.0.0"}, tarballPath)
+		require.NoError(t, err)
+		assert.Equal(t, "token <redacted>", preview.Headers["Authorization"])
+	})
+
+	t.Run("defaults to bearer", func(t *testing.T) {
+		_, err := NewClient(server.URL, "secret").GetPackageMetadata("com.test.auth")
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer secret", authorization)
+	})
+}
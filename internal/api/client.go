@@ -2,17 +2,20 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -20,12 +23,19 @@ import (
 	"compress/gzip"
 
 	gpmerrors "gpm.sh/gpm/gpm-cli/internal/errors"
+	"gpm.sh/gpm/gpm-cli/internal/validation"
 )
 
 type Client struct {
 	baseURL    string
 	token      string
+	otp        string
+	authScheme AuthScheme
 	httpClient *http.Client
+	// ctx cancels every request the client makes; nil means Background
+	ctx context.Context
+	// publishFormat is the format Publish uploads in, when set or detected
+	publishFormat PublishFormat
 }
 
 type PublishRequest struct {
@@ -39,7 +49,13 @@ type PublishRequest struct {
 	License     string   `json:"license,omitempty"`
 	Homepage    string   `json:"homepage,omitempty"`
 	Repository  string   `json:"repository,omitempty"`
-	Author      string   `json:"author,omitempty"`
+
+	Author *validation.Author `json:"author,omitempty"`
+
+	// FileCount and UnpackedSize describe the packed files and are recorded
+	// in the version's dist when set
+	FileCount    int   `json:"fileCount,omitempty"`
+	UnpackedSize int64 `json:"unpackedSize,omitempty"`
 }
 
 type PackageInfo struct {
@@ -60,6 +76,12 @@ type PackageMetadata struct {
 	Repository  interface{}                `json:"repository,omitempty"`
 	Homepage    string                     `json:"homepage,omitempty"`
 	Keywords    []string                   `json:"keywords,omitempty"`
+	// Modified is when the package last changed; abbreviated packuments carry
+	// it instead of Time
+	Modified string `json:"modified,omitempty"`
+	// Abbreviated is set when the registry answered with the abbreviated
+	// packument, so fields outside it are missing rather than empty
+	Abbreviated bool `json:"-"`
 }
 
 // PackageVersion represents a specific version of a package
@@ -73,10 +95,28 @@ type PackageVersion struct {
 	Homepage     string            `json:"homepage,omitempty"`
 	Keywords     []string          `json:"keywords,omitempty"`
 	Dependencies map[string]string `json:"dependencies,omitempty"`
+	Engines      map[string]string `json:"engines,omitempty"`
 	Dist         *PackageDist      `json:"dist,omitempty"`
 	Unity        string            `json:"unity,omitempty"`
+	UnityRelease string            `json:"unityRelease,omitempty"`
 	DisplayName  string            `json:"displayName,omitempty"`
 	Category     string            `json:"category,omitempty"`
+	Yanked       bool              `json:"yanked,omitempty"`
+	GPM          *GPMVersionInfo   `json:"gpm,omitempty"`
+}
+
+// GPMVersionInfo holds GPM-specific registry flags for a package version
+type GPMVersionInfo struct {
+	Yanked     bool   `json:"yanked,omitempty"`
+	YankReason string `json:"yankReason,omitempty"`
+}
+
+// IsYanked reports whether the registry has withdrawn this version
+func (v *PackageVersion) IsYanked() bool {
+	if v == nil {
+		return false
+	}
+	return v.Yanked || (v.GPM != nil && v.GPM.Yanked)
 }
 
 // PackageDist represents distribution metadata for a package version
@@ -85,6 +125,8 @@ type PackageDist struct {
 	Shasum    string `json:"shasum,omitempty"`
 	Tarball   string `json:"tarball,omitempty"`
 	FileSize  int64  `json:"fileSize,omitempty"`
+	// Signatures are the registry's signatures over the version's integrity
+	Signatures []PackageSignature `json:"signatures,omitempty"`
 }
 
 type PublishData struct {
@@ -99,6 +141,9 @@ type PublishResponse struct {
 	Success bool           `json:"success"`
 	Data    PublishData    `json:"data,omitempty"`
 	Error   *ErrorResponse `json:"error,omitempty"`
+	// Recovered is set when the upload response was lost but the registry
+	// metadata shows the version was committed
+	Recovered bool `json:"-"`
 }
 
 type LoginRequest struct {
@@ -141,6 +186,65 @@ type WhoamiResponse struct {
 	Username string `json:"username"`
 }
 
+// DownloadStats are a package's download counts, as served by npm-style
+// /downloads/point endpoints
+type DownloadStats struct {
+	LastWeek  int64 `json:"lastWeek"`
+	LastMonth int64 `json:"lastMonth"`
+}
+
+// PublishPermission is the registry's answer to whether the authenticated
+// user or studio may publish a package name at a given access level
+type PublishPermission struct {
+	Allowed   bool   `json:"allowed"`
+	Namespace string `json:"namespace,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	// PublishFormats lists the publish formats the registry accepts, for
+	// registries that accept more than the npm attachments document
+	PublishFormats []PublishFormat `json:"publishFormats,omitempty"`
+}
+
+// HTTPError is returned for non-2xx responses that carry no structured GPM error
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// PackageNotFoundError is returned when the registry has no such package
+type PackageNotFoundError struct {
+	Name string
+}
+
+func (e *PackageNotFoundError) Error() string {
+	return fmt.Sprintf("package '%s' not found", e.Name)
+}
+
+// NoLatestTagError is returned when a package resolved through latest has no
+// latest dist-tag, or no dist-tags at all
+type NoLatestTagError struct {
+	Name       string
+	NoDistTags bool
+}
+
+func (e *NoLatestTagError) Error() string {
+	if e.NoDistTags {
+		return fmt.Sprintf("package '%s' has no dist-tags - no default version available", e.Name)
+	}
+	return fmt.Sprintf("package '%s' has no 'latest' dist-tag - no default version available", e.Name)
+}
+
+// ErrEndpointUnsupported is returned when the registry does not implement an
+// optional GPM endpoint, so callers can skip the feature instead of failing
+var ErrEndpointUnsupported = errors.New("registry does not support this endpoint")
+
+// ErrOTPRequired is returned when the registry requires a one-time password
+// (two-factor authentication) for the request
+var ErrOTPRequired = errors.New("one-time password required")
+
 // OAuth 2.0 Authorization Code with PKCE structures
 type OAuthAuthorizationRequest struct {
 	ClientID            string `json:"client_id"`
@@ -193,11 +297,9 @@ type WebLoginStatus struct {
 
 func NewClient(baseURL, token string) *Client {
 	return &Client{
-		baseURL: strings.TrimSuffix(baseURL, "/"),
-		token:   token,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		httpClient: apiHTTPClient,
 	}
 }
 
@@ -205,6 +307,21 @@ func (c *Client) SetToken(token string) {
 	c.token = token
 }
 
+// WithContext returns a copy of the client whose requests, including retry
+// waits, stop as soon as ctx is canceled
+func (c *Client) WithContext(ctx context.Context) *Client {
+	clone := *c
+	clone.ctx = ctx
+	return &clone
+}
+
+func (c *Client) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
 func (c *Client) GetPackageInfo(name, version string) (*PackageInfo, error) {
 	endpoint := fmt.Sprintf("/-/v1/packages/%s", name)
 	if version != "" && version != "latest" {
@@ -225,29 +342,83 @@ func (c *Client) GetPackageInfo(name, version string) (*PackageInfo, error) {
 	return &info, nil
 }
 
+// AbbreviatedMetadataType is the content type of the abbreviated packument:
+// dist-tags and the install-relevant fields of each version, without readmes
+// or full manifests
+const AbbreviatedMetadataType = "application/vnd.npm.install-v1+json"
+
+// AbbreviatedMetadataAccept asks npm-compatible registries for the
+// abbreviated packument. Registries that don't support it answer with the
+// full document.
+const AbbreviatedMetadataAccept = AbbreviatedMetadataType + "; q=1.0, application/json; q=0.8, */*"
+
+// IsAbbreviatedMetadata reports whether a response's Content-Type is the
+// abbreviated packument
+func IsAbbreviatedMetadata(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.EqualFold(strings.TrimSpace(mediaType), AbbreviatedMetadataType)
+}
+
 // GetPackageMetadata retrieves complete package metadata including all versions and dist-tags
 func (c *Client) GetPackageMetadata(name string) (*PackageMetadata, error) {
+	return c.getPackageMetadata(name, nil)
+}
+
+// GetAbbreviatedMetadata retrieves the abbreviated packument, which is much
+// smaller for packages with thousands of versions. Only the name, dist-tags
+// and each version's dependencies, engines and dist are reliable in it; use
+// GetPackageMetadata when anything else is needed. Yank flags aren't part of
+// the npm format, so only gpm.sh registries are known to keep them. Registries
+// that refuse the abbreviated type with 406 Not Acceptable are asked for the
+// full document instead.
+func (c *Client) GetAbbreviatedMetadata(name string) (*PackageMetadata, error) {
+	metadata, err := c.getPackageMetadata(name, map[string]string{"Accept": AbbreviatedMetadataAccept})
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotAcceptable {
+		return c.getPackageMetadata(name, nil)
+	}
+	return metadata, err
+}
+
+// GetPackageDocument retrieves the full packument as the registry sent it,
+// keeping fields PackageMetadata doesn't model
+func (c *Client) GetPackageDocument(name string) (map[string]interface{}, error) {
+	resp, err := c.makeRequest("GET", "/"+name, nil, nil)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return nil, &PackageNotFoundError{Name: name}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var document map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to decode package document: %w", err)
+	}
+	return document, nil
+}
+
+func (c *Client) getPackageMetadata(name string, headers map[string]string) (*PackageMetadata, error) {
 	// Try registry-specific endpoint first
 	endpoint := fmt.Sprintf("/%s", name)
 
-	resp, err := c.makeRequest("GET", endpoint, nil, nil)
+	resp, err := c.makeRequest("GET", endpoint, nil, headers)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return nil, &PackageNotFoundError{Name: name}
+	}
 	if err != nil {
-		// Check for 404 to provide better error message
-		if resp != nil && resp.StatusCode == 404 {
-			return nil, fmt.Errorf("package '%s' not found", name)
-		}
 		return nil, fmt.Errorf("failed to fetch package metadata: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode == 404 {
-		return nil, fmt.Errorf("package '%s' not found", name)
-	}
-
 	var metadata PackageMetadata
 	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
 		return nil, fmt.Errorf("failed to decode package metadata: %w", err)
 	}
+	metadata.Abbreviated = IsAbbreviatedMetadata(resp.Header.Get("Content-Type"))
 
 	// Validate that we got a valid package
 	if metadata.Name == "" {
@@ -259,11 +430,12 @@ func (c *Client) GetPackageMetadata(name string) (*PackageMetadata, error) {
 
 // CheckPackageExists checks if a package exists in the registry
 func (c *Client) CheckPackageExists(name string) (bool, error) {
-	_, err := c.GetPackageMetadata(name)
+	_, err := c.GetAbbreviatedMetadata(name)
+	var notFound *PackageNotFoundError
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			return false, nil
-		}
 		return false, err
 	}
 	return true, nil
@@ -271,12 +443,12 @@ func (c *Client) CheckPackageExists(name string) (bool, error) {
 
 // GetPackageVersions returns all available versions for a package
 func (c *Client) GetPackageVersions(name string) ([]string, error) {
-	metadata, err := c.GetPackageMetadata(name)
+	metadata, err := c.GetAbbreviatedMetadata(name)
 	if err != nil {
 		return nil, err
 	}
 
-	var versions []string
+	versions := make([]string, 0, len(metadata.Versions))
 	for version := range metadata.Versions {
 		versions = append(versions, version)
 	}
@@ -284,38 +456,208 @@ func (c *Client) GetPackageVersions(name string) ([]string, error) {
 	return versions, nil
 }
 
+// VersionResolution describes how a version specification was resolved
+type VersionResolution struct {
+	Version string
+	// Yanked is set when an explicitly requested version has been yanked
+	Yanked bool
+	// SkippedYanked is the yanked version that was passed over, if any
+	SkippedYanked string
+	// MissingTag is the dist-tag that was asked for but doesn't exist, when
+	// latest was used instead
+	MissingTag string
+	// Range is the npm range the version was picked from, when one was
+	// requested instead of an exact version
+	Range string
+	// Tag is the dist-tag the version was picked through, latest for a
+	// package requested without a version
+	Tag string
+	// Info is the registry metadata for the resolved version
+	Info *PackageVersion
+}
+
 // ResolvePackageVersion resolves a version specification to a concrete version
 func (c *Client) ResolvePackageVersion(name, versionSpec string) (string, error) {
-	metadata, err := c.GetPackageMetadata(name)
+	resolution, err := c.ResolveVersion(name, versionSpec)
 	if err != nil {
 		return "", err
 	}
+	return resolution.Version, nil
+}
+
+// ResolveVersion resolves a version specification, skipping yanked versions
+// when resolving "latest". A dist-tag name resolves to the version it points
+// at. An exact request for a yanked version is honoured but flagged so the
+// caller can warn.
+func (c *Client) ResolveVersion(name, versionSpec string) (*VersionResolution, error) {
+	metadata, err := c.resolutionMetadata(name)
+	if err != nil {
+		return nil, err
+	}
+	return resolveVersion(metadata, name, versionSpec)
+}
+
+// ResolveTaggedVersion resolves a package requested without a version through
+// the given dist-tag instead of latest. When the package has no such tag,
+// latest is used and MissingTag is set so the caller can warn.
+func (c *Client) ResolveTaggedVersion(name, tag string) (*VersionResolution, error) {
+	metadata, err := c.resolutionMetadata(name)
+	if err != nil {
+		return nil, err
+	}
+	if tag == "" || tag == "latest" {
+		return resolveVersion(metadata, name, "")
+	}
+	if _, ok := metadata.DistTags[tag]; ok {
+		return resolveVersion(metadata, name, tag)
+	}
+
+	resolution, err := resolveVersion(metadata, name, "")
+	if err != nil {
+		return nil, err
+	}
+	resolution.MissingTag = tag
+	return resolution, nil
+}
+
+// resolutionMetadata returns the metadata versions are resolved from. The
+// abbreviated packument is enough when it carries yank flags: gpm.sh
+// registries keep them, and a version marked yanked shows the registry does.
+// Other registries, such as Verdaccio, strip the custom fields, so the full
+// document is fetched there to keep skipping yanked versions.
+func (c *Client) resolutionMetadata(name string) (*PackageMetadata, error) {
+	metadata, err := c.GetAbbreviatedMetadata(name)
+	if err != nil || !metadata.Abbreviated || isGPMHost(c.baseURL) {
+		return metadata, err
+	}
+	for _, info := range metadata.Versions {
+		if info.IsYanked() {
+			return metadata, nil
+		}
+	}
+	return c.GetPackageMetadata(name)
+}
 
+// resolveVersion resolves versionSpec against metadata already fetched for
+// the package
+func resolveVersion(metadata *PackageMetadata, name, versionSpec string) (*VersionResolution, error) {
 	// If no version specified, or "latest", use latest dist-tag
 	if versionSpec == "" || versionSpec == "latest" {
 		if metadata.DistTags == nil {
-			return "", fmt.Errorf("package '%s' has no dist-tags - no default version available", name)
+			return nil, &NoLatestTagError{Name: name, NoDistTags: true}
 		}
 
 		latestVersion, exists := metadata.DistTags["latest"]
 		if !exists || latestVersion == "" {
-			return "", fmt.Errorf("package '%s' has no 'latest' dist-tag - no default version available", name)
+			return nil, &NoLatestTagError{Name: name}
 		}
 
 		// Verify the latest version actually exists
 		if metadata.Versions == nil || metadata.Versions[latestVersion] == nil {
-			return "", fmt.Errorf("package '%s' latest version '%s' is invalid", name, latestVersion)
+			return nil, fmt.Errorf("package '%s' latest version '%s' is invalid", name, latestVersion)
+		}
+
+		if !metadata.Versions[latestVersion].IsYanked() {
+			return &VersionResolution{Version: latestVersion, Tag: "latest", Info: metadata.Versions[latestVersion]}, nil
 		}
 
-		return latestVersion, nil
+		fallback := HighestUnyankedVersion(metadata.Versions, latestVersion)
+		if fallback == "" {
+			return nil, fmt.Errorf("package '%s' latest version '%s' has been yanked and no earlier version is available", name, latestVersion)
+		}
+		return &VersionResolution{Version: fallback, SkippedYanked: latestVersion, Tag: "latest", Info: metadata.Versions[fallback]}, nil
+	}
+
+	// Other dist-tags name a version; an exact version wins over a tag
+	// with the same name
+	tag := ""
+	if tagged, ok := metadata.DistTags[versionSpec]; ok && metadata.Versions[versionSpec] == nil {
+		tag = versionSpec
+		versionSpec = tagged
 	}
 
-	// If specific version requested, verify it exists
+	// If specific version requested, verify it exists; anything else is
+	// tried as a range
 	if metadata.Versions == nil || metadata.Versions[versionSpec] == nil {
-		return "", fmt.Errorf("version '%s' not available for package '%s'", versionSpec, name)
+		if version := highestSatisfying(metadata.Versions, versionSpec); version != "" {
+			return &VersionResolution{Version: version, Range: versionSpec, Info: metadata.Versions[version]}, nil
+		}
+		return nil, fmt.Errorf("version '%s' not available for package '%s'", versionSpec, name)
+	}
+
+	return &VersionResolution{
+		Version: versionSpec,
+		Yanked:  metadata.Versions[versionSpec].IsYanked(),
+		Tag:     tag,
+		Info:    metadata.Versions[versionSpec],
+	}, nil
+}
+
+// highestSatisfying returns the highest non-yanked version in versionRange,
+// or "" if there is none. Prereleases are only picked when the range names
+// one, as npm does.
+func highestSatisfying(versions map[string]*PackageVersion, versionRange string) string {
+	allowPrerelease := strings.Contains(versionRange, "-") && !strings.Contains(versionRange, " - ")
+	var best string
+	for version, info := range versions {
+		if info.IsYanked() || (IsPrerelease(version) && !allowPrerelease) || !SatisfiesRange(version, versionRange) {
+			continue
+		}
+		if best == "" || CompareVersions(version, best) > 0 {
+			best = version
+		}
+	}
+	return best
+}
+
+// HighestUnyankedVersion returns the highest stable, non-yanked version below
+// ceiling, or "" if there is none
+func HighestUnyankedVersion(versions map[string]*PackageVersion, ceiling string) string {
+	var best string
+	for version, info := range versions {
+		if info.IsYanked() || IsPrerelease(version) || CompareVersions(version, ceiling) >= 0 {
+			continue
+		}
+		if best == "" || CompareVersions(version, best) > 0 {
+			best = version
+		}
+	}
+	return best
+}
+
+// CheckPublishPermission asks the registry whether the authenticated user may
+// publish name at the given access level. Registries without the endpoint
+// return ErrEndpointUnsupported.
+func (c *Client) CheckPublishPermission(name, access string) (*PublishPermission, error) {
+	params := url.Values{}
+	params.Set("name", name)
+	params.Set("access", access)
+
+	resp, err := c.makeRequest("GET", "/-/v1/permissions/publish?"+params.Encode(), nil, nil)
+	if err != nil {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			switch httpErr.StatusCode {
+			case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+				return nil, ErrEndpointUnsupported
+			case http.StatusForbidden:
+				return &PublishPermission{Allowed: false, Reason: strings.TrimSpace(httpErr.Body)}, nil
+			}
+		}
+		var gpmErr *gpmerrors.GPMError
+		if errors.As(err, &gpmErr) && strings.Contains(strings.ToUpper(gpmErr.Code), "FORBIDDEN") {
+			return &PublishPermission{Allowed: false, Reason: gpmErr.Message}, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var permission PublishPermission
+	if err := json.NewDecoder(resp.Body).Decode(&permission); err != nil {
+		return nil, fmt.Errorf("failed to decode publish permission response: %w", err)
 	}
 
-	return versionSpec, nil
+	return &permission, nil
 }
 
 func (c *Client) Login(req *LoginRequest) (*LoginResponse, error) {
@@ -369,6 +711,11 @@ func (c *Client) Register(req *RegisterRequest) (*RegisterResponse, error) {
 	return &registerResp, nil
 }
 
+// SetOTP sets the one-time password sent with subsequent requests
+func (c *Client) SetOTP(otp string) {
+	c.otp = otp
+}
+
 func (c *Client) Whoami() (*WhoamiResponse, error) {
 	resp, err := c.makeRequest("GET", "/-/whoami", nil, nil)
 	if err != nil {
@@ -384,6 +731,35 @@ func (c *Client) Whoami() (*WhoamiResponse, error) {
 	return &whoamiResp, nil
 }
 
+// GetDownloadStats fetches a package's downloads over the last week and
+// month from the registry's npm-style /downloads/point endpoint. It returns
+// nil without an error when the registry doesn't count downloads.
+func (c *Client) GetDownloadStats(name string) (*DownloadStats, error) {
+	var counts [2]int64
+	for i, period := range []string{"last-week", "last-month"} {
+		resp, err := c.makeRequest("GET", "/downloads/point/"+period+"/"+name, nil, nil)
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode == http.StatusMethodNotAllowed || httpErr.StatusCode == http.StatusNotImplemented) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch download stats: %w", err)
+		}
+		var point struct {
+			Downloads *int64 `json:"downloads"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&point)
+		_ = resp.Body.Close()
+		if err != nil || point.Downloads == nil {
+			// An endpoint answering with something else, such as a web
+			// page, doesn't count downloads either
+			return nil, nil
+		}
+		counts[i] = *point.Downloads
+	}
+	return &DownloadStats{LastWeek: counts[0], LastMonth: counts[1]}, nil
+}
+
 // OAuth 2.0 Authorization Code with PKCE methods
 func (c *Client) StartOAuthFlow(authorizationURL string) (string, error) {
 	// Open browser to authorization URL
@@ -463,6 +839,144 @@ func (c *Client) CheckWebLogin(sessionID string) (*WebLoginStatus, error) {
 }
 
 func (c *Client) Publish(req *PublishRequest, tarballPath string) (*PublishResponse, error) {
+	tarballData, err := readPublishTarball(tarballPath)
+	if err != nil {
+		return nil, err
+	}
+
+	npmRequest, packageInfo, err := publishPayload(req, tarballData)
+	if err != nil {
+		return nil, err
+	}
+
+	format := c.PublishFormat()
+	resp, err := c.uploadPublish(npmRequest, packageInfo, tarballData, format)
+	if format == PublishFormatMultipart && isUnsupportedPublishFormat(err) {
+		// The registry only takes the npm document after all
+		c.publishFormat = PublishFormatAttachments
+		resp, err = c.uploadPublish(npmRequest, packageInfo, tarballData, PublishFormatAttachments)
+	}
+	return resp, err
+}
+
+// uploadPublish sends the publish document in format, retrying transient
+// failures
+func (c *Client) uploadPublish(npmRequest map[string]interface{}, packageInfo *PackageInfo, tarballData []byte, format PublishFormat) (*PublishResponse, error) {
+	requestBody, contentType, err := publishBody(npmRequest, packageInfo, tarballData, format)
+	if err != nil {
+		return nil, err
+	}
+
+	integrity := "sha512-" + generateSHA512(tarballData)
+	headers := map[string]string{
+		"Content-Type": contentType,
+		// Deterministic per tarball so retries, including a re-run CI job,
+		// are recognised by the registry as the same publish
+		"Idempotency-Key": publishIdempotencyKey(packageInfo.Name, packageInfo.Version, tarballData),
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= PublishMaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(PublishRetryDelay * time.Duration(attempt-1)):
+			case <-c.context().Done():
+				return nil, c.context().Err()
+			}
+		}
+
+		resp, err := c.makeRequest("PUT", "/"+packageInfo.Name, requestBody, headers)
+		if err == nil {
+			return parsePublishResponse(resp)
+		}
+		lastErr = err
+
+		if ctxErr := c.context().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if !isRetryablePublishError(err) {
+			return nil, err
+		}
+
+		// The registry may have committed the version even though the
+		// response never reached us; don't report failure or upload twice
+		if c.versionPublished(packageInfo.Name, packageInfo.Version, integrity) {
+			return &PublishResponse{Success: true, Recovered: true}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("publish failed after %d attempts: %w", PublishMaxAttempts, lastErr)
+}
+
+// PublishPreview is the request Publish would send to the registry
+type PublishPreview struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    []byte
+}
+
+// PreviewPublish builds the request Publish would send without sending it.
+// The base64 tarball is replaced by a "<N bytes>" placeholder and
+// credentials are redacted, so the result is safe to print or share.
+func (c *Client) PreviewPublish(req *PublishRequest, tarballPath string) (*PublishPreview, error) {
+	tarballData, err := readPublishTarball(tarballPath)
+	if err != nil {
+		return nil, err
+	}
+
+	npmRequest, packageInfo, err := publishPayload(req, tarballData)
+	if err != nil {
+		return nil, err
+	}
+	placeholder := fmt.Sprintf("<%d bytes>", len(tarballData))
+	if attachments, ok := npmRequest["_attachments"].(map[string]interface{}); ok {
+		for _, attachment := range attachments {
+			if fields, ok := attachment.(map[string]interface{}); ok {
+				fields["data"] = placeholder
+			}
+		}
+	}
+
+	contentType := "application/json"
+	var body []byte
+	if c.PublishFormat() == PublishFormatMultipart {
+		body, contentType, err = publishBody(npmRequest, packageInfo, []byte(placeholder), PublishFormatMultipart)
+	} else {
+		body, err = json.MarshalIndent(npmRequest, "", "  ")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal npm request: %w", err)
+	}
+	if c.token != "" {
+		body = bytes.ReplaceAll(body, []byte(c.token), []byte("<redacted>"))
+	}
+
+	headers := map[string]string{
+		"Content-Type":    contentType,
+		"Idempotency-Key": publishIdempotencyKey(packageInfo.Name, packageInfo.Version, tarballData),
+	}
+	if c.token != "" {
+		headers["Authorization"] = c.authorization("<redacted>")
+	}
+	if c.otp != "" {
+		headers["npm-otp"] = "<redacted>"
+	}
+	for key, value := range extraHeaders {
+		headers[key] = redactedHeader(key, value)
+	}
+
+	return &PublishPreview{
+		Method:  "PUT",
+		URL:     c.baseURL + "/" + packageInfo.Name,
+		Headers: headers,
+		Body:    body,
+	}, nil
+}
+
+// readPublishTarball reads the tarball to upload, refusing anything that
+// isn't a .tgz
+func readPublishTarball(tarballPath string) ([]byte, error) {
 	// Security: Validate the tarball path
 	cleanPath := filepath.Clean(tarballPath)
 	if !strings.HasSuffix(cleanPath, ".tgz") && !strings.HasSuffix(cleanPath, ".tar.gz") {
@@ -475,16 +989,38 @@ func (c *Client) Publish(req *PublishRequest, tarballPath string) (*PublishRespo
 	}
 	defer func() { _ = file.Close() }()
 
-	// Read tarball data
 	tarballData, err := io.ReadAll(file)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read tarball: %w", err)
 	}
+	return tarballData, nil
+}
 
+// publishPayload builds the npm publish body for tarballData from the
+// package.json inside it
+func publishPayload(req *PublishRequest, tarballData []byte) (map[string]interface{}, *PackageInfo, error) {
 	// First extract the actual package.json from the tarball
 	packageInfo, err := extractPackageInfoWithTarballData(tarballData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract package info: %w", err)
+		return nil, nil, fmt.Errorf("failed to extract package info: %w", err)
+	}
+
+	// Registries expect the object form of author; package.json may use
+	// either form
+	author := req.Author
+	if author == nil {
+		author = validation.AuthorFromValue(packageInfo.RawData["author"])
+	}
+	if author != nil && !author.IsZero() && packageInfo.RawData != nil {
+		packageInfo.RawData["author"] = author
+	}
+	if dist, ok := packageInfo.RawData["dist"].(map[string]interface{}); ok {
+		if req.FileCount > 0 {
+			dist["fileCount"] = req.FileCount
+		}
+		if req.UnpackedSize > 0 {
+			dist["unpackedSize"] = req.UnpackedSize
+		}
 	}
 
 	// Create npm publish format request using the actual package.json data
@@ -506,24 +1042,65 @@ func (c *Client) Publish(req *PublishRequest, tarballPath string) (*PublishRespo
 			"created":  time.Now().Format(time.RFC3339),
 			"modified": time.Now().Format(time.RFC3339),
 		},
-		"maintainers":    []interface{}{},
-		"readme":         "A Unity Package Manager compatible package",
-		"readmeFilename": "README.md",
+		"maintainers": []interface{}{},
 	}
 
-	// Marshal the npm request
-	requestBody, err := json.Marshal(npmRequest)
+	// Registries render the readme on the package page; send nothing rather
+	// than a placeholder when the package has none
+	readmeName, readme, err := extractReadmeWithTarballData(tarballData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal npm request: %w", err)
+		return nil, nil, fmt.Errorf("failed to read README from tarball: %w", err)
+	}
+	if readmeName != "" {
+		npmRequest["readme"] = readme
+		npmRequest["readmeFilename"] = readmeName
 	}
 
-	// Send the request to the npm publish endpoint
-	resp, err := c.makeRequest("PUT", "/"+packageInfo.Name, requestBody, map[string]string{
-		"Content-Type": "application/json",
-	})
+	return npmRequest, packageInfo, nil
+}
+
+// Retry policy for publish uploads; variables so tests can shorten the delay
+var (
+	PublishMaxAttempts = 3
+	PublishRetryDelay  = 2 * time.Second
+)
+
+// isRetryablePublishError reports whether a publish failure may be transient:
+// network errors and gateway/server errors, but not rejections
+func isRetryablePublishError(err error) bool {
+	var gpmErr *gpmerrors.GPMError
+	if errors.As(err, &gpmErr) {
+		return gpmErr.Code == "E_NETWORK_FAILED"
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500 || httpErr.StatusCode == http.StatusRequestTimeout || httpErr.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// versionPublished checks the registry for a version matching the tarball we
+// tried to upload. A version with different integrity does not count.
+func (c *Client) versionPublished(name, version, integrity string) bool {
+	metadata, err := c.GetPackageMetadata(name)
 	if err != nil {
-		return nil, err
+		return false
+	}
+	published := metadata.Versions[version]
+	if published == nil {
+		return false
 	}
+	if published.Dist != nil && published.Dist.Integrity != "" {
+		return published.Dist.Integrity == integrity
+	}
+	return true
+}
+
+func publishIdempotencyKey(name, version string, tarballData []byte) string {
+	return generateSHA256(append([]byte(name+"@"+version+"\x00"), tarballData...))
+}
+
+func parsePublishResponse(resp *http.Response) (*PublishResponse, error) {
 	defer func() { _ = resp.Body.Close() }()
 
 	// Read response body for flexible handling
@@ -622,6 +1199,49 @@ func extractPackageInfoWithTarballData(tarballData []byte) (*PackageInfo, error)
 	return nil, fmt.Errorf("package.json not found in tarball")
 }
 
+// readmeNames are the README file names npm recognises, most preferred first
+var readmeNames = []string{"readme.md", "readme.markdown", "readme"}
+
+// extractReadmeWithTarballData returns the file name and content of the
+// package's top-level README, matched case-insensitively. The name is empty
+// when the package has no README.
+func extractReadmeWithTarballData(tarballData []byte) (string, string, error) {
+	gzr, err := gzip.NewReader(bytes.NewReader(tarballData))
+	if err != nil {
+		return "", "", err
+	}
+	defer func() { _ = gzr.Close() }()
+
+	tr := tar.NewReader(gzr)
+	var name, content string
+	best := len(readmeNames)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", "", err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		base := strings.TrimPrefix(header.Name, "package/")
+		rank := slices.Index(readmeNames, strings.ToLower(base))
+		if rank < 0 || rank >= best {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return "", "", err
+		}
+		name, content, best = base, string(data), rank
+	}
+
+	return name, content, nil
+}
+
 // Helper function to generate SHA512 hash
 func generateSHA512(data []byte) string {
 	hash := sha512.Sum512(data)
@@ -641,25 +1261,33 @@ func (c *Client) makeRequest(method, endpoint string, body []byte, headers map[s
 	var err error
 
 	if body != nil {
-		req, err = http.NewRequest(method, url, bytes.NewReader(body))
+		req, err = http.NewRequestWithContext(c.context(), method, url, bytes.NewReader(body))
 	} else {
-		req, err = http.NewRequest(method, url, nil)
+		req, err = http.NewRequestWithContext(c.context(), method, url, nil)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if c.token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.token)
+	c.authorize(req)
+	if c.otp != "" {
+		req.Header.Set("npm-otp", c.otp)
 	}
 
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
+	for key, value := range extraHeaders {
+		req.Header.Set(key, value)
+	}
+	logRequest(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		if ctxErr := c.context().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, gpmerrors.ErrNetworkFailed(err)
 	}
 
@@ -667,6 +1295,11 @@ func (c *Client) makeRequest(method, endpoint string, body []byte, headers map[s
 		body, _ := io.ReadAll(resp.Body)
 		_ = resp.Body.Close()
 
+		// npm-compatible registries signal 2FA with "WWW-Authenticate: OTP"
+		if resp.StatusCode == http.StatusUnauthorized && strings.Contains(strings.ToLower(resp.Header.Get("WWW-Authenticate")), "otp") {
+			return nil, ErrOTPRequired
+		}
+
 		var apiError struct {
 			Error struct {
 				Code    string `json:"code"`
@@ -675,13 +1308,16 @@ func (c *Client) makeRequest(method, endpoint string, body []byte, headers map[s
 		}
 
 		if err := json.Unmarshal(body, &apiError); err == nil && apiError.Error.Code != "" {
+			if apiError.Error.Code == "EOTP" || apiError.Error.Code == "E_OTP_REQUIRED" {
+				return nil, ErrOTPRequired
+			}
 			return nil, &gpmerrors.GPMError{
 				Code:    apiError.Error.Code,
 				Message: apiError.Error.Message,
 			}
 		}
 
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	return resp, nil
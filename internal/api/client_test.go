@@ -1,11 +1,20 @@
 package api
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -188,3 +197,643 @@ func TestClient_makeRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_UserAgent(t *testing.T) {
+	var agents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.Header.Get("User-Agent"))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	original := UserAgent()
+	defer SetUserAgent(original)
+
+	SetUserAgent(DefaultUserAgent("v1.2.3"))
+	client := NewClient(server.URL, "")
+	resp, err := client.makeRequest("GET", "/test", nil, nil)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	SetUserAgent("studio-ci/1.0")
+	resp, err = HTTPGet(server.URL + "/tarball.tgz")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	require.Len(t, agents, 2)
+	assert.Regexp(t, `^gpm-cli/v1\.2\.3 \([a-z0-9]+/[a-z0-9]+\)$`, agents[0])
+	assert.Equal(t, "studio-ci/1.0", agents[1])
+}
+
+func TestClient_ResolveVersionSkipsYanked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"name": "com.test.yanked",
+			"dist-tags": {"latest": "1.2.0"},
+			"versions": {
+				"1.0.0": {"name": "com.test.yanked", "version": "1.0.0"},
+				"1.1.0": {"name": "com.test.yanked", "version": "1.1.0"},
+				"1.1.5": {"name": "com.test.yanked", "version": "1.1.5", "gpm": {"yanked": true}},
+				"1.2.0-beta.1": {"name": "com.test.yanked", "version": "1.2.0-beta.1"},
+				"1.2.0": {"name": "com.test.yanked", "version": "1.2.0", "yanked": true}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "")
+
+	t.Run("latest falls back to prior good version", func(t *testing.T) {
+		resolution, err := client.ResolveVersion("com.test.yanked", "latest")
+		require.NoError(t, err)
+		assert.Equal(t, "1.1.0", resolution.Version)
+		assert.Equal(t, "1.2.0", resolution.SkippedYanked)
+		assert.False(t, resolution.Yanked)
+
+		version, err := client.ResolvePackageVersion("com.test.yanked", "")
+		require.NoError(t, err)
+		assert.Equal(t, "1.1.0", version)
+	})
+
+	t.Run("explicit yanked version is allowed but flagged", func(t *testing.T) {
+		resolution, err := client.ResolveVersion("com.test.yanked", "1.1.5")
+		require.NoError(t, err)
+		assert.Equal(t, "1.1.5", resolution.Version)
+		assert.True(t, resolution.Yanked)
+	})
+
+	t.Run("explicit good version is not flagged", func(t *testing.T) {
+		resolution, err := client.ResolveVersion("com.test.yanked", "1.0.0")
+		require.NoError(t, err)
+		assert.False(t, resolution.Yanked)
+		assert.Empty(t, resolution.SkippedYanked)
+	})
+}
+
+// writeTestTarball packs a package.json plus any extra files, keyed by their
+// path inside package/
+func writeTestTarball(t *testing.T, name, version string, files map[string]string) (string, []byte) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	packageJSON := []byte(`{"name": "` + name + `", "version": "` + version + `"}`)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "package/package.json", Mode: 0644, Size: int64(len(packageJSON))}))
+	_, err := tw.Write(packageJSON)
+	require.NoError(t, err)
+	for path, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "package/" + path, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+
+	path := filepath.Join(t.TempDir(), name+"-"+version+".tgz")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	return path, buf.Bytes()
+}
+
+func TestClient_PublishCanceled(t *testing.T) {
+	originalDelay := PublishRetryDelay
+	PublishRetryDelay = time.Minute
+	defer func() { PublishRetryDelay = originalDelay }()
+
+	tarballPath, _ := writeTestTarball(t, "com.test.cancel", "1.0.0", nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var puts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			puts++
+			cancel()
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	_, err := NewClient(server.URL, "token").WithContext(ctx).Publish(&PublishRequest{Name: "com.test.cancel", Version: "1.0.0"}, tarballPath)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 10*time.Second, "the retry wait must stop on cancel")
+	assert.Equal(t, 1, puts)
+
+	_, err = NewClient(server.URL, "token").WithContext(ctx).Whoami()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestClient_PublishRecoversCommittedVersion(t *testing.T) {
+	originalDelay := PublishRetryDelay
+	PublishRetryDelay = time.Millisecond
+	defer func() { PublishRetryDelay = originalDelay }()
+
+	tarballPath, tarballData := writeTestTarball(t, "com.test.retry", "1.0.0", nil)
+	integrity := "sha512-" + generateSHA512(tarballData)
+
+	tests := []struct {
+		name              string
+		storedIntegrity   string
+		expectError       bool
+		expectedPutCount  int
+		expectedRecovered bool
+	}{
+		{
+			name:              "response dropped after commit",
+			storedIntegrity:   integrity,
+			expectedPutCount:  1,
+			expectedRecovered: true,
+		},
+		{
+			name:             "different tarball already published",
+			storedIntegrity:  "sha512-someoneelse",
+			expectError:      true,
+			expectedPutCount: PublishMaxAttempts,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var committed bool
+			var idempotencyKeys []string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				if r.Method == "PUT" {
+					idempotencyKeys = append(idempotencyKeys, r.Header.Get("Idempotency-Key"))
+					committed = true
+
+					// Commit, then drop the connection before responding
+					hijacker, ok := w.(http.Hijacker)
+					require.True(t, ok)
+					conn, _, err := hijacker.Hijack()
+					require.NoError(t, err)
+					_ = conn.Close()
+					return
+				}
+
+				if !committed {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(PackageMetadata{
+					Name: "com.test.retry",
+					Versions: map[string]*PackageVersion{
+						"1.0.0": {Name: "com.test.retry", Version: "1.0.0", Dist: &PackageDist{Integrity: tt.storedIntegrity}},
+					},
+				})
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "token")
+			resp, err := client.Publish(&PublishRequest{Name: "com.test.retry", Version: "1.0.0"}, tarballPath)
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, resp.Success)
+				assert.Equal(t, tt.expectedRecovered, resp.Recovered)
+			}
+
+			// net/http may also replay keyed PUTs on a reused connection, so
+			// only a lower bound on uploads is meaningful
+			require.GreaterOrEqual(t, len(idempotencyKeys), tt.expectedPutCount)
+			if tt.expectedRecovered {
+				assert.Len(t, idempotencyKeys, 1, "a committed upload must not be repeated")
+			}
+			assert.NotEmpty(t, idempotencyKeys[0])
+			for _, key := range idempotencyKeys {
+				assert.Equal(t, idempotencyKeys[0], key, "retries must reuse the idempotency key")
+			}
+		})
+	}
+}
+
+func TestClient_PublishDoesNotRetryRejections(t *testing.T) {
+	tarballPath, _ := writeTestTarball(t, "com.test.rejected", "1.0.0", nil)
+
+	var puts, gets int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			puts++
+		} else {
+			gets++
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "bad-token")
+	_, err := client.Publish(&PublishRequest{Name: "com.test.rejected", Version: "1.0.0"}, tarballPath)
+	assert.Error(t, err)
+	assert.Equal(t, 1, puts)
+	assert.Equal(t, 0, gets)
+}
+
+func TestClient_PublishSendsPackageReadme(t *testing.T) {
+	tests := []struct {
+		name             string
+		files            map[string]string
+		expectedFilename string
+		expectedReadme   string
+	}{
+		{
+			name:             "markdown readme",
+			files:            map[string]string{"README.md": "# Retry\n\nReal docs.", "Docs/readme.md": "nested"},
+			expectedFilename: "README.md",
+			expectedReadme:   "# Retry\n\nReal docs.",
+		},
+		{
+			name:             "lowercase readme without extension",
+			files:            map[string]string{"readme": "plain docs"},
+			expectedFilename: "readme",
+			expectedReadme:   "plain docs",
+		},
+		{
+			name:             "prefers .md over .markdown",
+			files:            map[string]string{"Readme.markdown": "markdown", "readme.MD": "md"},
+			expectedFilename: "readme.MD",
+			expectedReadme:   "md",
+		},
+		{
+			name:  "no readme",
+			files: map[string]string{"Runtime/a.cs": "class A {}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tarballPath, _ := writeTestTarball(t, "com.test.readme", "1.0.0", tt.files)
+
+			var payload map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "token")
+			_, err := client.Publish(&PublishRequest{Name: "com.test.readme", Version: "1.0.0"}, tarballPath)
+			require.NoError(t, err)
+
+			if tt.expectedFilename == "" {
+				assert.NotContains(t, payload, "readme")
+				assert.NotContains(t, payload, "readmeFilename")
+				return
+			}
+			assert.Equal(t, tt.expectedReadme, payload["readme"])
+			assert.Equal(t, tt.expectedFilename, payload["readmeFilename"])
+		})
+	}
+}
+
+func TestClient_DecodesGzipResponses(t *testing.T) {
+	packument := []byte(`{"name":"com.test.gzip","dist-tags":{"latest":"1.0.0"},"versions":{"1.0.0":{"name":"com.test.gzip","version":"1.0.0"}}}`)
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = gz.Write(packument)
+	require.NoError(t, gz.Close())
+
+	// The registry gzips every response, whether or not it was asked to
+	encoding := "gzip"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/com.test.gzip" {
+			w.Header().Set("Content-Encoding", encoding)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", encoding)
+		_, _ = w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "")
+
+	t.Run("transparent gzip", func(t *testing.T) {
+		metadata, err := client.GetPackageMetadata("com.test.gzip")
+		require.NoError(t, err)
+		assert.Equal(t, "com.test.gzip", metadata.Name)
+		assert.Contains(t, metadata.Versions, "1.0.0")
+	})
+
+	t.Run("explicit Accept-Encoding", func(t *testing.T) {
+		resp, err := client.makeRequest("GET", "/com.test.gzip", nil, map[string]string{"Accept-Encoding": "gzip"})
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+
+		var metadata PackageMetadata
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&metadata))
+		assert.Equal(t, "com.test.gzip", metadata.Name)
+	})
+
+	t.Run("x-gzip", func(t *testing.T) {
+		encoding = "x-gzip"
+		defer func() { encoding = "gzip" }()
+		metadata, err := client.GetPackageMetadata("com.test.gzip")
+		require.NoError(t, err)
+		assert.Equal(t, "com.test.gzip", metadata.Name)
+	})
+
+	t.Run("empty gzip-labelled error body", func(t *testing.T) {
+		_, err := client.GetPackageMetadata("com.test.missing")
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "gzip")
+	})
+}
+
+func TestClient_Redirects(t *testing.T) {
+	var crossHostAuth []string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		crossHostAuth = append(crossHostAuth, r.Header.Get("Authorization")+"|"+r.Header.Get("npm-otp"))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer other.Close()
+
+	var sameHostAuth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, "/target", http.StatusMovedPermanently)
+		case "/target":
+			sameHostAuth = append(sameHostAuth, r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`{}`))
+		case "/cdn":
+			http.Redirect(w, r, other.URL+"/package", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token")
+	client.SetOTP("123456")
+
+	t.Run("same host keeps credentials", func(t *testing.T) {
+		resp, err := client.makeRequest("GET", "/moved", nil, nil)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, []string{"Bearer secret-token"}, sameHostAuth)
+	})
+
+	t.Run("cross host drops credentials", func(t *testing.T) {
+		resp, err := client.makeRequest("GET", "/cdn", nil, nil)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, []string{"|"}, crossHostAuth)
+	})
+
+	t.Run("redirect loop", func(t *testing.T) {
+		_, err := client.makeRequest("GET", "/loop", nil, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "stopped after 10 redirects")
+	})
+}
+
+// syntheticPackument builds a packument with count versions, each carrying a
+// readme-sized description like long-lived Unity packages accumulate
+func syntheticPackument(name string, count int) map[string]interface{} {
+	versions := make(map[string]interface{}, count)
+	times := make(map[string]interface{}, count)
+	latest := ""
+	for i := 0; i < count; i++ {
+		version := fmt.Sprintf("%d.%d.%d", i/1000, (i/10)%100, i%10)
+		versions[version] = map[string]interface{}{
+			"name":        name,
+			"version":     version,
+			"description": strings.Repeat("Release notes. ", 40),
+			"dist":        map[string]interface{}{"tarball": "https://registry.test/" + name + "-" + version + ".tgz"},
+		}
+		times[version] = "2024-01-01T00:00:00Z"
+		latest = version
+	}
+	return map[string]interface{}{
+		"name":      name,
+		"dist-tags": map[string]string{"latest": latest},
+		"versions":  versions,
+		"time":      times,
+	}
+}
+
+// abbreviate keeps what the abbreviated packument format keeps
+func abbreviate(packument map[string]interface{}) map[string]interface{} {
+	versions := make(map[string]interface{})
+	for version, info := range packument["versions"].(map[string]interface{}) {
+		fields := info.(map[string]interface{})
+		versions[version] = map[string]interface{}{"name": fields["name"], "version": version, "dist": fields["dist"]}
+	}
+	return map[string]interface{}{"name": packument["name"], "dist-tags": packument["dist-tags"], "versions": versions}
+}
+
+func TestClient_AbbreviatedMetadata(t *testing.T) {
+	var accepts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepts = append(accepts, r.Header.Get("Accept"))
+		_ = json.NewEncoder(w).Encode(syntheticPackument("com.test.huge", 3))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "")
+
+	exists, err := client.CheckPackageExists("com.test.huge")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	versions, err := client.GetPackageVersions("com.test.huge")
+	require.NoError(t, err)
+	assert.Len(t, versions, 3)
+	_, err = client.GetPackageMetadata("com.test.huge")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{AbbreviatedMetadataAccept, AbbreviatedMetadataAccept, ""}, accepts)
+}
+
+func TestClient_AbbreviatedContentType(t *testing.T) {
+	packument := syntheticPackument("com.test.pkg", 3)
+	var accepts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepts = append(accepts, r.Header.Get("Accept"))
+		if strings.Contains(r.Header.Get("Accept"), AbbreviatedMetadataType) {
+			w.Header().Set("Content-Type", AbbreviatedMetadataType+"; charset=utf-8")
+			_ = json.NewEncoder(w).Encode(abbreviate(packument))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(packument)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "")
+
+	abbreviated, err := client.GetAbbreviatedMetadata("com.test.pkg")
+	require.NoError(t, err)
+	assert.True(t, abbreviated.Abbreviated)
+	full, err := client.GetPackageMetadata("com.test.pkg")
+	require.NoError(t, err)
+	assert.False(t, full.Abbreviated)
+	assert.Equal(t, []string{AbbreviatedMetadataAccept, ""}, accepts)
+
+	t.Run("falls back when the registry refuses it", func(t *testing.T) {
+		accepts = nil
+		strict := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accepts = append(accepts, r.Header.Get("Accept"))
+			if r.Header.Get("Accept") != "" {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(packument)
+		}))
+		defer strict.Close()
+
+		metadata, err := NewClient(strict.URL, "").GetAbbreviatedMetadata("com.test.pkg")
+		require.NoError(t, err)
+		assert.False(t, metadata.Abbreviated)
+		assert.Len(t, metadata.Versions, 3)
+		assert.Equal(t, []string{AbbreviatedMetadataAccept, ""}, accepts)
+
+		resp, err := HTTPGetPackageDocument(strict.URL+"/com.test.pkg", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestClient_ResolveVersionYankFlags(t *testing.T) {
+	packument := syntheticPackument("com.test.pkg", 3)
+	packument["versions"].(map[string]interface{})["0.0.2"].(map[string]interface{})["gpm"] = map[string]interface{}{"yanked": true}
+
+	// serve answers with the abbreviated packument, keeping the yank flags in
+	// it when keepYanked is set, as gpm.sh does
+	serve := func(keepYanked bool, accepts *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*accepts = append(*accepts, r.Header.Get("Accept"))
+			if !strings.Contains(r.Header.Get("Accept"), AbbreviatedMetadataType) {
+				_ = json.NewEncoder(w).Encode(packument)
+				return
+			}
+			abbreviated := abbreviate(packument)
+			if keepYanked {
+				for version, info := range packument["versions"].(map[string]interface{}) {
+					if flags, ok := info.(map[string]interface{})["gpm"]; ok {
+						abbreviated["versions"].(map[string]interface{})[version].(map[string]interface{})["gpm"] = flags
+					}
+				}
+			}
+			w.Header().Set("Content-Type", AbbreviatedMetadataType)
+			_ = json.NewEncoder(w).Encode(abbreviated)
+		}))
+	}
+
+	for _, tt := range []struct {
+		name       string
+		keepYanked bool
+		want       []string
+	}{
+		{"kept in the abbreviated packument", true, []string{AbbreviatedMetadataAccept, AbbreviatedMetadataAccept}},
+		{"stripped from the abbreviated packument", false, []string{AbbreviatedMetadataAccept, "", AbbreviatedMetadataAccept, ""}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var accepts []string
+			server := serve(tt.keepYanked, &accepts)
+			defer server.Close()
+			client := NewClient(server.URL, "")
+
+			resolution, err := client.ResolveVersion("com.test.pkg", ">=0.0.1")
+			require.NoError(t, err)
+			assert.Equal(t, "0.0.1", resolution.Version, "the yanked 0.0.2 is skipped")
+			resolution, err = client.ResolveTaggedVersion("com.test.pkg", "latest")
+			require.NoError(t, err)
+			assert.Equal(t, "0.0.1", resolution.Version)
+			assert.Equal(t, "0.0.2", resolution.SkippedYanked)
+			assert.Equal(t, tt.want, accepts)
+		})
+	}
+}
+
+func BenchmarkGetPackageVersions(b *testing.B) {
+	full, err := json.Marshal(syntheticPackument("com.test.huge", 5000))
+	require.NoError(b, err)
+	abbreviated, err := json.Marshal(abbreviate(syntheticPackument("com.test.huge", 5000)))
+	require.NoError(b, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.Header.Get("Accept"), "application/vnd.npm.install-v1+json") {
+			_, _ = w.Write(abbreviated)
+			return
+		}
+		_, _ = w.Write(full)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "")
+
+	b.Run("full", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := client.GetPackageMetadata("com.test.huge"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("abbreviated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := client.GetPackageVersions("com.test.huge"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestClient_GetDownloadStats(t *testing.T) {
+	t.Run("registry counts downloads", func(t *testing.T) {
+		var paths []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			downloads := 120
+			if strings.Contains(r.URL.Path, "last-month") {
+				downloads = 510
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"downloads": downloads, "package": "com.test.pkg"})
+		}))
+		defer server.Close()
+
+		stats, err := NewClient(server.URL, "").GetDownloadStats("com.test.pkg")
+		require.NoError(t, err)
+		assert.Equal(t, &DownloadStats{LastWeek: 120, LastMonth: 510}, stats)
+		assert.Equal(t, []string{"/downloads/point/last-week/com.test.pkg", "/downloads/point/last-month/com.test.pkg"}, paths)
+	})
+
+	t.Run("registry without the endpoint", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		}))
+		defer server.Close()
+
+		stats, err := NewClient(server.URL, "").GetDownloadStats("com.test.pkg")
+		require.NoError(t, err)
+		assert.Nil(t, stats)
+	})
+}
+
+func TestClient_PackageNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"Not found"}`))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "")
+
+	var notFound *PackageNotFoundError
+	_, err := client.GetPackageMetadata("com.test.missing")
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "com.test.missing", notFound.Name)
+
+	_, err = client.GetAbbreviatedMetadata("com.test.missing")
+	require.ErrorAs(t, err, &notFound)
+
+	exists, err := client.CheckPackageExists("com.test.missing")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzipTransport decodes gzip-encoded responses that Go's transport leaves
+// compressed. The transport only decodes a response when it asked for gzip
+// itself, so a request that sets Accept-Encoding, or a registry that answers
+// with x-gzip, would otherwise hand JSON decoders compressed bytes.
+type gzipTransport struct {
+	base http.RoundTripper
+}
+
+func (t *gzipTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Uncompressed || req.Method == http.MethodHead {
+		return resp, err
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding != "gzip" && encoding != "x-gzip" {
+		return resp, nil
+	}
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return resp, nil
+	}
+
+	body, err := newGzipBody(resp.Body)
+	switch {
+	case errors.Is(err, io.EOF):
+		// An empty body has nothing to decode
+		_ = resp.Body.Close()
+		resp.Body = http.NoBody
+	case err != nil:
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to decode gzip response from %s: %w", req.URL.Redacted(), err)
+	default:
+		resp.Body = body
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// gzipBody closes both the gzip reader and the underlying response body
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func newGzipBody(body io.ReadCloser) (*gzipBody, error) {
+	reader, err := gzip.NewReader(body)
+	if err != nil {
+		return nil, err
+	}
+	return &gzipBody{Reader: reader, body: body}, nil
+}
+
+func (b *gzipBody) Close() error {
+	_ = b.Reader.Close()
+	return b.body.Close()
+}
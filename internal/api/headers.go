@@ -0,0 +1,108 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// extraHeaders are added to every registry request, for registries behind an
+// API gateway that wants its own key
+var extraHeaders map[string]string
+
+// protectedHeaders carry credentials or describe the request body. Extra
+// headers may only replace them when explicitly allowed.
+var protectedHeaders = []string{"Authorization", "npm-otp", "Cookie", "Host", "Content-Type", "Content-Length", "Transfer-Encoding"}
+
+// sensitiveHeaders are redacted wherever requests are logged or printed, on
+// top of any header whose name mentions a key, token, secret or password
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "npm-otp", "Cookie"}
+
+// debugRequests logs every registry request and its headers
+var debugRequests = false
+
+// ParseHeader splits a "Key: Value" header as given to --header
+func ParseHeader(header string) (string, string, error) {
+	name, value, ok := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t\r\n") {
+		return "", "", fmt.Errorf("invalid header %q: expected 'Key: Value'", header)
+	}
+	value = strings.TrimSpace(value)
+	if strings.ContainsAny(value, "\r\n") {
+		return "", "", fmt.Errorf("invalid header %q: the value spans several lines", name)
+	}
+	return http.CanonicalHeaderKey(name), value, nil
+}
+
+// IsProtectedHeader reports whether name is a header extra headers may not
+// replace by default
+func IsProtectedHeader(name string) bool {
+	for _, protected := range protectedHeaders {
+		if strings.EqualFold(name, protected) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetExtraHeaders sets the headers added to every registry request. Callers
+// check them with IsProtectedHeader first.
+func SetExtraHeaders(headers map[string]string) {
+	extraHeaders = headers
+}
+
+// AddSensitiveHeaders marks more headers to redact in debug output
+func AddSensitiveHeaders(names ...string) {
+	sensitiveHeaders = append(sensitiveHeaders, names...)
+}
+
+// SetDebug turns logging of registry requests on or off
+func SetDebug(enabled bool) {
+	debugRequests = enabled
+}
+
+// isSensitiveHeader reports whether a header's value must not be shown
+func isSensitiveHeader(name string) bool {
+	for _, sensitive := range sensitiveHeaders {
+		if strings.EqualFold(name, sensitive) {
+			return true
+		}
+	}
+	lower := strings.ToLower(name)
+	for _, word := range []string{"key", "token", "secret", "password", "auth"} {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedHeader returns a header value as it may be logged
+func redactedHeader(name, value string) string {
+	if isSensitiveHeader(name) {
+		return "<redacted>"
+	}
+	return value
+}
+
+// logRequest logs a request and its headers, sensitive values redacted
+func logRequest(req *http.Request) {
+	if !debugRequests {
+		return
+	}
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s", req.Method, req.URL.Redacted())
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n  %s: %s", name, redactedHeader(name, req.Header.Get(name)))
+	}
+	log.Print(b.String())
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtraHeaders(t *testing.T) {
+	sensitive := sensitiveHeaders
+	defer func() {
+		SetExtraHeaders(nil)
+		SetDebug(false)
+		sensitiveHeaders = sensitive
+	}()
+
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		_, _ = w.Write([]byte(`{"username":"dev"}`))
+	}))
+	defer server.Close()
+
+	SetExtraHeaders(map[string]string{"X-Api-Key": "key-123", "X-Studio-Gateway": "gw-secret", "X-Team": "tools"})
+	AddSensitiveHeaders("X-Studio-Gateway")
+	SetDebug(true)
+	var logged bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logged)
+
+	_, err := NewClient(server.URL, "token-abc").Whoami()
+	require.NoError(t, err)
+
+	assert.Equal(t, "key-123", received.Get("X-Api-Key"))
+	assert.Equal(t, "gw-secret", received.Get("X-Studio-Gateway"))
+	assert.Equal(t, "Bearer token-abc", received.Get("Authorization"), "extra headers leave credentials alone")
+
+	output := logged.String()
+	assert.Contains(t, output, "GET "+server.URL+"/-/whoami")
+	assert.Contains(t, output, "X-Studio-Gateway: <redacted>")
+	assert.Contains(t, output, "X-Api-Key: <redacted>")
+	assert.Contains(t, output, "X-Team: tools")
+	for _, secret := range []string{"gw-secret", "key-123", "token-abc"} {
+		assert.NotContains(t, output, secret)
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	name, value, err := ParseHeader("x-api-key:  abc:def ")
+	require.NoError(t, err)
+	assert.Equal(t, "X-Api-Key", name)
+	assert.Equal(t, "abc:def", value)
+
+	for _, header := range []string{"X-Api-Key", ": value", "X Api: value", "X-Api: a\r\nHost: evil"} {
+		_, _, err := ParseHeader(header)
+		assert.Error(t, err, header)
+	}
+	assert.True(t, IsProtectedHeader("authorization"))
+	assert.False(t, IsProtectedHeader("X-Api-Key"))
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// RegistryUnreachableError reports a registry that could not be connected to
+// within the preflight timeout
+type RegistryUnreachableError struct {
+	Registry string
+	Err      error
+}
+
+func (e *RegistryUnreachableError) Error() string {
+	return fmt.Sprintf("registry unreachable: %s (%v)", e.Registry, e.Err)
+}
+
+func (e *RegistryUnreachableError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	reachabilityMu sync.Mutex
+	// reachability remembers each host's preflight result for the rest of the
+	// invocation, so later packages don't wait on a registry known to be down
+	reachability = make(map[string]error)
+)
+
+// CheckRegistryReachable sends a HEAD request to the registry with the given
+// timeout, failing fast instead of letting each request stall for the full
+// client timeout. Any HTTP response counts as reachable. The result is cached
+// per host.
+func CheckRegistryReachable(registryURL string, timeout time.Duration) error {
+	parsed, err := url.Parse(registryURL)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("invalid registry URL: %s", registryURL)
+	}
+
+	reachabilityMu.Lock()
+	defer reachabilityMu.Unlock()
+	if err, checked := reachability[parsed.Host]; checked {
+		if err != nil {
+			return &RegistryUnreachableError{Registry: registryURL, Err: err}
+		}
+		return nil
+	}
+
+	resp, err := NewHTTPClient(timeout).Head(parsed.String())
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+	reachability[parsed.Host] = err
+	if err != nil {
+		return &RegistryUnreachableError{Registry: registryURL, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// PublishFormat is the shape of the body Publish uploads a version in
+type PublishFormat string
+
+const (
+	// PublishFormatAttachments is the CouchDB-style document npm and
+	// verdaccio expect, with the tarball base64-encoded in _attachments
+	PublishFormatAttachments PublishFormat = "attachments"
+	// PublishFormatMultipart is GPM's native upload: the version document
+	// and the raw tarball as parts of a multipart/form-data body
+	PublishFormatMultipart PublishFormat = "multipart"
+)
+
+// SetPublishFormat fixes the format Publish uploads in, overriding the one
+// picked from the registry's host
+func (c *Client) SetPublishFormat(format PublishFormat) {
+	c.publishFormat = format
+}
+
+// PublishFormat returns the format Publish uploads in: the one set,
+// otherwise GPM's native upload for registries on gpm.sh and the npm
+// attachments document for everything else
+func (c *Client) PublishFormat() PublishFormat {
+	switch {
+	case c.publishFormat != "":
+		return c.publishFormat
+	case isGPMHost(c.baseURL):
+		return PublishFormatMultipart
+	default:
+		return PublishFormatAttachments
+	}
+}
+
+// PublishFormat returns the format the registry asked for in its publish
+// permission answer: GPM's native upload when it accepts it, otherwise the
+// attachments document. It is "" when the registry didn't say.
+func (p *PublishPermission) PublishFormat() PublishFormat {
+	switch {
+	case len(p.PublishFormats) == 0:
+		return ""
+	case slices.Contains(p.PublishFormats, PublishFormatMultipart):
+		return PublishFormatMultipart
+	default:
+		return PublishFormatAttachments
+	}
+}
+
+// isGPMHost reports whether registryURL is gpm.sh or one of its subdomains
+func isGPMHost(registryURL string) bool {
+	parsed, err := url.Parse(registryURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	return host == "gpm.sh" || strings.HasSuffix(host, ".gpm.sh")
+}
+
+// publishBody encodes the publish document in format. tarball is the raw
+// tarball for the multipart upload; the attachments document already
+// carries it.
+func publishBody(document map[string]interface{}, packageInfo *PackageInfo, tarball []byte, format PublishFormat) ([]byte, string, error) {
+	if format != PublishFormatMultipart {
+		body, err := json.Marshal(document)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal npm request: %w", err)
+		}
+		return body, "application/json", nil
+	}
+
+	versionDocument := make(map[string]interface{}, len(document))
+	for key, value := range document {
+		if key != "_attachments" {
+			versionDocument[key] = value
+		}
+	}
+	metadata, err := json.Marshal(versionDocument)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal npm request: %w", err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="package"`)
+	header.Set("Content-Type", "application/json")
+	part, err := writer.CreatePart(header)
+	if err == nil {
+		_, err = part.Write(metadata)
+	}
+	if err == nil {
+		part, err = writer.CreateFormFile("tarball", fmt.Sprintf("%s-%s.tgz", packageInfo.Name, packageInfo.Version))
+	}
+	if err == nil {
+		_, err = part.Write(tarball)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build multipart publish body: %w", err)
+	}
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
+
+// isUnsupportedPublishFormat reports whether the registry rejected the
+// upload's content type, so the other publish format may still be accepted
+func isUnsupportedPublishFormat(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnsupportedMediaType
+}
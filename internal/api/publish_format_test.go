@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_PublishFormatByRegistry(t *testing.T) {
+	assert.Equal(t, PublishFormatMultipart, NewClient("https://registry.gpm.sh", "").PublishFormat())
+	assert.Equal(t, PublishFormatMultipart, NewClient("https://gpm.sh/", "").PublishFormat())
+	assert.Equal(t, PublishFormatAttachments, NewClient("https://notgpm.sh", "").PublishFormat())
+	assert.Equal(t, PublishFormatAttachments, NewClient("https://registry.npmjs.org", "").PublishFormat())
+	assert.Equal(t, PublishFormatAttachments, NewClient("http://localhost:4873", "").PublishFormat(), "verdaccio")
+
+	client := NewClient("https://registry.gpm.sh", "")
+	client.SetPublishFormat(PublishFormatAttachments)
+	assert.Equal(t, PublishFormatAttachments, client.PublishFormat())
+
+	assert.Equal(t, PublishFormat(""), (&PublishPermission{Allowed: true}).PublishFormat())
+	assert.Equal(t, PublishFormatMultipart, (&PublishPermission{PublishFormats: []PublishFormat{PublishFormatAttachments, PublishFormatMultipart}}).PublishFormat())
+	assert.Equal(t, PublishFormatAttachments, (&PublishPermission{PublishFormats: []PublishFormat{"something-newer"}}).PublishFormat())
+}
+
+// publishUpload is what a test registry received in a publish PUT
+type publishUpload struct {
+	contentType string
+	document    map[string]interface{}
+	tarball     []byte
+}
+
+func readPublishUpload(t *testing.T, r *http.Request) publishUpload {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	upload := publishUpload{contentType: mediaType}
+	if mediaType != "multipart/form-data" {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&upload.document))
+		return upload
+	}
+
+	reader := multipart.NewReader(r.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return upload
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(part)
+		require.NoError(t, err)
+		switch part.FormName() {
+		case "package":
+			assert.Equal(t, "application/json", part.Header.Get("Content-Type"))
+			require.NoError(t, json.Unmarshal(data, &upload.document))
+		case "tarball":
+			assert.Equal(t, "com.test.shape-1.0.0.tgz", part.FileName())
+			upload.tarball = data
+		}
+	}
+}
+
+func TestClient_PublishShape(t *testing.T) {
+	tarballPath, tarballData := writeTestTarball(t, "com.test.shape", "1.0.0", nil)
+
+	t.Run("npm-compatible registries get the attachments document", func(t *testing.T) {
+		var uploads []publishUpload
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			uploads = append(uploads, readPublishUpload(t, r))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+		}))
+		defer server.Close()
+
+		_, err := NewClient(server.URL, "token").Publish(&PublishRequest{Name: "com.test.shape", Version: "1.0.0"}, tarballPath)
+		require.NoError(t, err)
+		require.Len(t, uploads, 1)
+		assert.Equal(t, "application/json", uploads[0].contentType)
+		assert.Contains(t, uploads[0].document, "_attachments")
+	})
+
+	t.Run("GPM registries get the multipart upload", func(t *testing.T) {
+		var uploads []publishUpload
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			uploads = append(uploads, readPublishUpload(t, r))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, "token")
+		client.SetPublishFormat(PublishFormatMultipart)
+		_, err := client.Publish(&PublishRequest{Name: "com.test.shape", Version: "1.0.0", Tag: "beta"}, tarballPath)
+		require.NoError(t, err)
+		require.Len(t, uploads, 1)
+		assert.Equal(t, "multipart/form-data", uploads[0].contentType)
+		assert.NotContains(t, uploads[0].document, "_attachments")
+		assert.Equal(t, "com.test.shape", uploads[0].document["name"])
+		assert.NotContains(t, uploads[0].document, "dist-tags")
+		assert.Equal(t, tarballData, uploads[0].tarball, "the tarball is sent raw, not base64")
+	})
+
+	t.Run("falls back to attachments when multipart is refused", func(t *testing.T) {
+		var uploads []publishUpload
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upload := readPublishUpload(t, r)
+			uploads = append(uploads, upload)
+			if upload.contentType != "application/json" {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL, "token")
+		client.SetPublishFormat(PublishFormatMultipart)
+		resp, err := client.Publish(&PublishRequest{Name: "com.test.shape", Version: "1.0.0"}, tarballPath)
+		require.NoError(t, err)
+		assert.True(t, resp.Success)
+		require.Len(t, uploads, 2)
+		assert.Equal(t, "multipart/form-data", uploads[0].contentType)
+		assert.Contains(t, uploads[1].document, "_attachments")
+		assert.Equal(t, PublishFormatAttachments, client.PublishFormat(), "later publishes skip the refused format")
+	})
+
+	t.Run("preview shows the chosen shape", func(t *testing.T) {
+		client := NewClient("https://registry.gpm.sh", "token")
+		preview, err := client.PreviewPublish(&PublishRequest{Name: "com.test.shape", Version: "1.0.0"}, tarballPath)
+		require.NoError(t, err)
+		assert.Contains(t, preview.Headers["Content-Type"], "multipart/form-data; boundary=")
+		assert.Contains(t, string(preview.Body), `name="tarball"; filename="com.test.shape-1.0.0.tgz"`)
+		assert.NotContains(t, string(preview.Body), "_attachments")
+	})
+}
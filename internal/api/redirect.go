@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxRedirects caps the redirects a single request follows, so a redirect
+// loop fails fast instead of spinning until the timeout
+const maxRedirects = 10
+
+// checkRedirect is the redirect policy of every GPM client. Credentials are
+// only meant for the host they were configured for, so they are dropped as
+// soon as a redirect leaves it.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+		req.Header.Del("Authorization")
+		req.Header.Del("npm-otp")
+	}
+	return nil
+}
+
+// HTTPGetAllowed is HTTPGet for URLs that were checked against an allowlist:
+// allowed is asked again about every redirect target, and a redirect it
+// rejects fails the request. Canceling ctx aborts the request and any read of
+// its body.
+func HTTPGetAllowed(ctx context.Context, url string, allowed func(target string) bool) (*http.Response, error) {
+	client := NewHTTPClient(0)
+	client.CheckRedirect = allowedRedirects(allowed)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+// allowedRedirects is checkRedirect, also failing redirects allowed rejects
+func allowedRedirects(allowed func(target string) bool) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if err := checkRedirect(req, via); err != nil {
+			return err
+		}
+		if !allowed(req.URL.String()) {
+			return fmt.Errorf("refusing to follow redirect to %s: host is not allowed", req.URL.Redacted())
+		}
+		return nil
+	}
+}
+
+// HTTPGetPackageDocument fetches a package document outside a Client, asking
+// for the abbreviated packument like GetAbbreviatedMetadata. allowed, when
+// set, vets every redirect target as in HTTPGetAllowed.
+func HTTPGetPackageDocument(url string, allowed func(target string) bool) (*http.Response, error) {
+	client := NewHTTPClient(0)
+	if allowed != nil {
+		client.CheckRedirect = allowedRedirects(allowed)
+	}
+	get := func(accept string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		return client.Do(req)
+	}
+
+	resp, err := get(AbbreviatedMetadataAccept)
+	if err != nil || resp.StatusCode != http.StatusNotAcceptable {
+		return resp, err
+	}
+	_ = resp.Body.Close()
+	return get("")
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PackageSignature is a registry's signature over a version's identity and
+// integrity, as found in dist.signatures
+type PackageSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// RegistryKey is a public key a registry signs packages with, as served by
+// /-/npm/v1/keys. Expires is set once the key has been rotated out: it only
+// vouches for versions published before then.
+type RegistryKey struct {
+	KeyID   string     `json:"keyid"`
+	KeyType string     `json:"keytype"`
+	Scheme  string     `json:"scheme"`
+	Key     string     `json:"key"`
+	Expires *time.Time `json:"expires"`
+}
+
+// SignatureError reports a version whose registry signature is missing or
+// doesn't verify
+type SignatureError struct {
+	Name    string
+	Version string
+	Reason  string
+	// Missing is set when the version carries no signature at all
+	Missing bool
+	// UnknownKey is set when no signature was made with a key the caller
+	// knows, which fetching the registry's current keys may fix
+	UnknownKey bool
+}
+
+func (e *SignatureError) Error() string {
+	return fmt.Sprintf("%s@%s %s", e.Name, e.Version, e.Reason)
+}
+
+// GetSigningKeys fetches the public keys the registry signs packages with.
+// It returns nil without an error when the registry doesn't sign packages.
+func (c *Client) GetSigningKeys() ([]RegistryKey, error) {
+	resp, err := c.makeRequest("GET", "/-/npm/v1/keys", nil, nil)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode == http.StatusMethodNotAllowed || httpErr.StatusCode == http.StatusNotImplemented) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch registry signing keys: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var body struct {
+		Keys []RegistryKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		// An endpoint answering with something else, such as a web page,
+		// doesn't serve keys either
+		return nil, nil
+	}
+	return body.Keys, nil
+}
+
+// VerifySignatures checks a version's registry signatures against keys. The
+// signed message is "<name>@<version>:<integrity>", as npm registries sign
+// it. One valid signature is enough; published is when the version was
+// published, checked against the expiry of the key that signed it.
+func VerifySignatures(name, version string, dist *PackageDist, published time.Time, keys []RegistryKey) error {
+	if dist == nil || len(dist.Signatures) == 0 {
+		return &SignatureError{Name: name, Version: version, Reason: "has no registry signature", Missing: true}
+	}
+	if dist.Integrity == "" {
+		return &SignatureError{Name: name, Version: version, Reason: "has signatures but no integrity to check them against"}
+	}
+
+	message := sha256.Sum256([]byte(name + "@" + version + ":" + dist.Integrity))
+	var problems []string
+	known := false
+	for _, signature := range dist.Signatures {
+		key := findKey(keys, signature.KeyID)
+		if key == nil {
+			problems = append(problems, fmt.Sprintf("signed with unknown key %s", signature.KeyID))
+			continue
+		}
+		known = true
+		if key.Expires != nil && (published.IsZero() || !published.Before(*key.Expires)) {
+			problems = append(problems, fmt.Sprintf("signed with key %s, which expired on %s before this version was published", key.KeyID, key.Expires.Format(time.DateOnly)))
+			continue
+		}
+		publicKey, err := parseRegistryKey(key)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(signature.Sig)
+		if err == nil && ecdsa.VerifyASN1(publicKey, message[:], sig) {
+			return nil
+		}
+		problems = append(problems, fmt.Sprintf("has an invalid signature for key %s", key.KeyID))
+	}
+	return &SignatureError{Name: name, Version: version, Reason: strings.Join(problems, "; "), UnknownKey: !known}
+}
+
+// findKey returns the key with keyID, or nil
+func findKey(keys []RegistryKey, keyID string) *RegistryKey {
+	for i := range keys {
+		if keys[i].KeyID == keyID {
+			return &keys[i]
+		}
+	}
+	return nil
+}
+
+// parseRegistryKey decodes a base64 DER public key, which npm registries
+// publish as ECDSA P-256
+func parseRegistryKey(key *RegistryKey) (*ecdsa.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(key.Key)
+	if err != nil {
+		return nil, fmt.Errorf("registry key %s is not valid base64", key.KeyID)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("registry key %s can't be parsed: %v", key.KeyID, err)
+	}
+	publicKey, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("registry key %s is not an ECDSA key", key.KeyID)
+	}
+	return publicKey, nil
+}
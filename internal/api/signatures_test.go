@@ -0,0 +1,80 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySignatures(t *testing.T) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	require.NoError(t, err)
+	key := RegistryKey{KeyID: "SHA256:test", Key: base64.StdEncoding.EncodeToString(der)}
+
+	const integrity = "sha512-abc"
+	digest := sha256.Sum256([]byte("com.test.pkg@1.0.0:" + integrity))
+	sig, err := ecdsa.SignASN1(rand.Reader, private, digest[:])
+	require.NoError(t, err)
+	dist := &PackageDist{Integrity: integrity, Signatures: []PackageSignature{{KeyID: key.KeyID, Sig: base64.StdEncoding.EncodeToString(sig)}}}
+	published := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, VerifySignatures("com.test.pkg", "1.0.0", dist, published, []RegistryKey{key}))
+
+	var sigErr *SignatureError
+	err = VerifySignatures("com.test.pkg", "1.0.1", dist, published, []RegistryKey{key})
+	require.True(t, errors.As(err, &sigErr))
+	assert.Contains(t, sigErr.Reason, "invalid signature")
+
+	err = VerifySignatures("com.test.pkg", "1.0.0", &PackageDist{Integrity: integrity}, published, []RegistryKey{key})
+	require.True(t, errors.As(err, &sigErr))
+	assert.True(t, sigErr.Missing)
+
+	err = VerifySignatures("com.test.pkg", "1.0.0", dist, published, []RegistryKey{{KeyID: "SHA256:other"}})
+	require.True(t, errors.As(err, &sigErr))
+	assert.True(t, sigErr.UnknownKey)
+
+	expired := key
+	expires := published.Add(-time.Hour)
+	expired.Expires = &expires
+	err = VerifySignatures("com.test.pkg", "1.0.0", dist, published, []RegistryKey{expired})
+	require.True(t, errors.As(err, &sigErr))
+	assert.Contains(t, sigErr.Reason, "expired")
+}
+
+func TestClient_GetSigningKeys(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/-/npm/v1/keys" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"keys": [{"keyid": "SHA256:a", "keytype": "ecdsa-sha2-nistp256", "scheme": "ecdsa-sha2-nistp256", "key": "MFk=", "expires": null},` +
+			`{"keyid": "SHA256:b", "key": "MFk=", "expires": "2025-01-29T00:00:00.000Z"}]}`))
+	}))
+	defer server.Close()
+
+	keys, err := NewClient(server.URL, "").GetSigningKeys()
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Nil(t, keys[0].Expires)
+	require.NotNil(t, keys[1].Expires)
+	assert.Equal(t, 2025, keys[1].Expires.Year())
+
+	unsigned := httptest.NewServer(http.NotFoundHandler())
+	defer unsigned.Close()
+	keys, err = NewClient(unsigned.URL, "").GetSigningKeys()
+	require.NoError(t, err)
+	assert.Nil(t, keys)
+}
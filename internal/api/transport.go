@@ -0,0 +1,65 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// maxIdleConnsPerHost keeps enough connections to a registry open for a
+	// multi-package install; Go's default of 2 makes most requests dial again
+	maxIdleConnsPerHost = 16
+
+	// maxDrainedBytes bounds how much of an unread response body is discarded
+	// on Close to return its connection to the pool; larger leftovers are
+	// cheaper to drop with the connection
+	maxDrainedBytes = 256 << 10
+)
+
+// sharedTransport is the connection pool behind every GPM HTTP client, so
+// all registry traffic in one invocation reuses keep-alive connections.
+// Clients still set their own timeout and redirect policy, and proxies still
+// come from the environment as with http.DefaultTransport.
+var sharedTransport = newTransport()
+
+// sharedRoundTripper adds the User-Agent and gzip decoding on top of the
+// shared pool
+var sharedRoundTripper http.RoundTripper = &userAgentTransport{base: &gzipTransport{base: &drainingTransport{base: sharedTransport}}}
+
+// apiHTTPClient is shared by every Client; they all use the same timeout and
+// redirect policy
+var apiHTTPClient = NewHTTPClient(30 * time.Second)
+
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.IdleConnTimeout = 90 * time.Second
+	return transport
+}
+
+// drainingTransport makes closing a partly read response body, such as one
+// a JSON decoder stopped reading before the trailing newline, read the rest
+// first. Go only reuses a connection whose body was read to the end.
+type drainingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *drainingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Body == nil || resp.Body == http.NoBody {
+		return resp, err
+	}
+	resp.Body = &drainingBody{ReadCloser: resp.Body}
+	return resp, nil
+}
+
+type drainingBody struct {
+	io.ReadCloser
+}
+
+func (b *drainingBody) Close() error {
+	_, _ = io.CopyN(io.Discard, b.ReadCloser, maxDrainedBytes)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingServer serves package metadata and tarballs, counting the
+// connections clients open
+func newCountingServer(t testing.TB, useTLS bool) (*httptest.Server, *int32) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".tgz") {
+			_, _ = w.Write(make([]byte, 4096))
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		// Trailing whitespace the JSON decoder stops short of, so the body is
+		// only read to the end when Close drains it
+		_, _ = fmt.Fprintf(w, `{"name":%q,"dist-tags":{"latest":"1.0.0"},"versions":{"1.0.0":{"name":%q,"version":"1.0.0"}}}%s`, name, name, strings.Repeat(" ", 8192))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	if useTLS {
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+	t.Cleanup(server.Close)
+	return server, &conns
+}
+
+// installPackages fetches the metadata and tarball of count packages one
+// after another, as a multi-package install does
+func installPackages(client *Client, baseURL string, count int) error {
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("com.test.package%d", i)
+		if _, err := client.GetPackageMetadata(name); err != nil {
+			return err
+		}
+		resp, err := client.httpClient.Get(baseURL + "/" + name + "/-/" + name + "-1.0.0.tgz")
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestSharedTransportReusesConnections(t *testing.T) {
+	server, conns := newCountingServer(t, false)
+	defer sharedTransport.CloseIdleConnections()
+
+	for i := 0; i < 20; i++ {
+		_, err := NewClient(server.URL, "").GetPackageMetadata(fmt.Sprintf("com.test.package%d", i))
+		require.NoError(t, err)
+		resp, err := HTTPGet(server.URL + "/com.test.package.tgz")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(conns), "clients should share one pooled connection")
+}
+
+func TestSharedTransportSettings(t *testing.T) {
+	// Proxies still come from HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	assert.NotNil(t, sharedTransport.Proxy)
+	assert.True(t, sharedTransport.ForceAttemptHTTP2)
+	assert.Equal(t, maxIdleConnsPerHost, sharedTransport.MaxIdleConnsPerHost)
+}
+
+// BenchmarkInstallConnectionReuse compares a 20-package install against a
+// local TLS registry through the shared pool with one that dials and
+// handshakes for every request
+func BenchmarkInstallConnectionReuse(b *testing.B) {
+	server, _ := newCountingServer(b, true)
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	previous := sharedTransport.TLSClientConfig
+	sharedTransport.TLSClientConfig = tlsConfig
+	defer func() {
+		sharedTransport.CloseIdleConnections()
+		sharedTransport.TLSClientConfig = previous
+	}()
+
+	b.Run("shared pool", func(b *testing.B) {
+		client := NewClient(server.URL, "")
+		for i := 0; i < b.N; i++ {
+			if err := installPackages(client, server.URL, 20); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("no keep-alive", func(b *testing.B) {
+		transport := newTransport()
+		transport.DisableKeepAlives = true
+		transport.TLSClientConfig = tlsConfig.Clone()
+		client := NewClient(server.URL, "")
+		client.httpClient = &http.Client{Transport: &userAgentTransport{base: &gzipTransport{base: transport}}}
+		for i := 0; i < b.N; i++ {
+			if err := installPackages(client, server.URL, 20); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
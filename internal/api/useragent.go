@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+var userAgent = DefaultUserAgent("dev")
+
+// DefaultUserAgent returns the User-Agent GPM identifies itself with:
+// gpm-cli/<version> (<os>/<arch>). It carries no user or machine identifiers.
+func DefaultUserAgent(version string) string {
+	return fmt.Sprintf("gpm-cli/%s (%s/%s)", version, runtime.GOOS, runtime.GOARCH)
+}
+
+// SetUserAgent sets the User-Agent sent with every request
+func SetUserAgent(ua string) {
+	userAgent = ua
+}
+
+// UserAgent returns the User-Agent sent with every request
+func UserAgent() string {
+	return userAgent
+}
+
+// userAgentTransport adds the GPM User-Agent to requests that don't set one
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// NewHTTPClient returns an http.Client that sends the GPM User-Agent, decodes
+// gzip responses and follows redirects by checkRedirect. A zero timeout means
+// no timeout. Every client shares one connection pool.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	// The shared transport keeps compression enabled, so gzip is requested
+	// and decoded transparently; gzipTransport covers the rest
+	return &http.Client{
+		Timeout:       timeout,
+		Transport:     sharedRoundTripper,
+		CheckRedirect: checkRedirect,
+	}
+}
+
+// HTTPGet issues a GET with the GPM User-Agent, for requests made outside a
+// Client such as tarball downloads
+func HTTPGet(url string) (*http.Response, error) {
+	return NewHTTPClient(0).Get(url)
+}
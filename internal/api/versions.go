@@ -0,0 +1,228 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two version strings following semver precedence,
+// including prerelease ordering (1.0.0-alpha < 1.0.0-beta.2 < 1.0.0).
+// Build metadata is ignored.
+func CompareVersions(a, b string) int {
+	aCore, aPre := splitPrerelease(a)
+	bCore, bPre := splitPrerelease(b)
+
+	if cmp := compareCore(aCore, bCore); cmp != 0 {
+		return cmp
+	}
+
+	// A release version has higher precedence than any of its prereleases
+	switch {
+	case aPre == "" && bPre == "":
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+
+	aIDs := strings.Split(aPre, ".")
+	bIDs := strings.Split(bPre, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aNum, aErr := strconv.Atoi(aIDs[i])
+		bNum, bErr := strconv.Atoi(bIDs[i])
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				if aNum < bNum {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil:
+			// Numeric identifiers sort before alphanumeric ones
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if cmp := strings.Compare(aIDs[i], bIDs[i]); cmp != 0 {
+				return cmp
+			}
+		}
+	}
+
+	switch {
+	case len(aIDs) < len(bIDs):
+		return -1
+	case len(aIDs) > len(bIDs):
+		return 1
+	}
+	return 0
+}
+
+// IsPrerelease reports whether a version carries a prerelease suffix
+func IsPrerelease(version string) bool {
+	_, pre := splitPrerelease(version)
+	return pre != ""
+}
+
+// splitPrerelease separates "1.2.3-beta.1+build" into "1.2.3" and "beta.1"
+func splitPrerelease(version string) (string, string) {
+	version = strings.TrimPrefix(version, "v")
+	if idx := strings.Index(version, "+"); idx >= 0 {
+		version = version[:idx]
+	}
+	if idx := strings.Index(version, "-"); idx >= 0 {
+		return version[:idx], version[idx+1:]
+	}
+	return version, ""
+}
+
+// compareCore compares dotted numeric version cores, treating missing
+// components as zero. Trailing letters in a component are ignored so Unity
+// versions such as 2021.3.5f1 compare by their numbers.
+func compareCore(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aVal, bVal int
+		if i < len(aParts) {
+			aVal = leadingInt(aParts[i])
+		}
+		if i < len(bParts) {
+			bVal = leadingInt(bParts[i])
+		}
+		if aVal != bVal {
+			if aVal < bVal {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// leadingInt parses the leading digits of a version component
+func leadingInt(part string) int {
+	end := 0
+	for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(part[:end])
+	return n
+}
+
+// SatisfiesRange reports whether version satisfies an npm-style range:
+// comparators (>=, >, <=, <, =) joined by spaces, caret and tilde ranges,
+// x-ranges such as 2021.x, hyphen ranges (1.0 - 2.0), and alternatives
+// joined by "||". An empty range or "*" matches everything.
+func SatisfiesRange(version, versionRange string) bool {
+	for _, alternative := range strings.Split(versionRange, "||") {
+		if satisfiesAll(version, alternative) {
+			return true
+		}
+	}
+	return false
+}
+
+func satisfiesAll(version, comparators string) bool {
+	fields := strings.Fields(comparators)
+	// "a - b" is an inclusive range
+	if len(fields) == 3 && fields[1] == "-" {
+		fields = []string{">=" + fields[0], "<=" + fields[2]}
+	}
+	for _, comparator := range fields {
+		if !satisfiesComparator(version, comparator) {
+			return false
+		}
+	}
+	return true
+}
+
+func satisfiesComparator(version, comparator string) bool {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if target, ok := strings.CutPrefix(comparator, op); ok {
+			cmp := CompareVersions(version, target)
+			switch op {
+			case ">=":
+				return cmp >= 0
+			case "<=":
+				return cmp <= 0
+			case ">":
+				return cmp > 0
+			case "<":
+				return cmp < 0
+			default:
+				return cmp == 0
+			}
+		}
+	}
+
+	if base, ok := strings.CutPrefix(comparator, "^"); ok {
+		parts := versionComponents(base)
+		upper := make([]int, len(parts))
+		// Bump the first non-zero component, or the last one given
+		for i := range parts {
+			if parts[i] != 0 || i == len(parts)-1 {
+				upper[i] = parts[i] + 1
+				upper = upper[:i+1]
+				break
+			}
+		}
+		return CompareVersions(version, base) >= 0 && CompareVersions(version, joinComponents(upper)) < 0
+	}
+
+	if base, ok := strings.CutPrefix(comparator, "~"); ok {
+		parts := versionComponents(base)
+		if len(parts) > 2 {
+			parts = parts[:2]
+		}
+		if len(parts) == 1 {
+			parts[0]++
+		} else {
+			parts[1]++
+		}
+		return CompareVersions(version, base) >= 0 && CompareVersions(version, joinComponents(parts)) < 0
+	}
+
+	// A bare version matches every version sharing its given components, so
+	// "2021.3" and "2021.3.x" both accept 2021.3.5f1
+	core, _ := splitPrerelease(version)
+	have := strings.Split(core, ".")
+	for i, want := range strings.Split(strings.TrimPrefix(comparator, "v"), ".") {
+		if want == "x" || want == "X" || want == "*" {
+			return true
+		}
+		if i >= len(have) || leadingInt(have[i]) != leadingInt(want) {
+			return false
+		}
+	}
+	if _, pre := splitPrerelease(comparator); pre != "" {
+		return CompareVersions(version, comparator) == 0
+	}
+	return true
+}
+
+func versionComponents(version string) []int {
+	core, _ := splitPrerelease(version)
+	var parts []int
+	for _, part := range strings.Split(core, ".") {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		parts = append(parts, leadingInt(part))
+	}
+	if len(parts) == 0 {
+		parts = []int{0}
+	}
+	return parts
+}
+
+func joinComponents(parts []int) string {
+	strs := make([]string, len(parts))
+	for i, part := range parts {
+		strs[i] = strconv.Itoa(part)
+	}
+	return strings.Join(strs, ".")
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSatisfiesRange(t *testing.T) {
+	tests := []struct {
+		version string
+		rng     string
+		want    bool
+	}{
+		{"2021.3.5f1", ">=2021.3", true},
+		{"2020.3.1f1", ">=2021.3", false},
+		{"2022.1.0f1", ">=2021.3 <2022", false},
+		{"2021.3.5f1", "2021.3", true},
+		{"2021.3.5f1", "2021.x", true},
+		{"2022.1.0", "2021.x || 2022.x", true},
+		{"1.4.2", "^1.2.0", true},
+		{"2.0.0", "^1.2.0", false},
+		{"0.2.5", "^0.2.1", true},
+		{"0.3.0", "^0.2.1", false},
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"1.5.0", "1.0.0 - 2.0.0", true},
+		{"0.1.0-alpha.9", ">=0.1.0-alpha.1", true},
+		{"1.0.0", "*", true},
+		{"1.0.0", "", true},
+		{"1.0.0", "=1.0.0", true},
+		{"1.0.1", "<1.0.1", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, SatisfiesRange(tt.version, tt.rng), "%s in %q", tt.version, tt.rng)
+	}
+}
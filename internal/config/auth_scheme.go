@@ -0,0 +1,83 @@
+package config
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Ways of sending the token to a registry. Bearer is the default; basic
+// sends npm's _auth form (base64 "user:password"); legacy sends
+// "Authorization: token <token>".
+const (
+	AuthSchemeBearer = "bearer"
+	AuthSchemeBasic  = "basic"
+	AuthSchemeLegacy = "legacy"
+)
+
+// ValidAuthSchemes lists the values an auth scheme may take
+var ValidAuthSchemes = []string{AuthSchemeBearer, AuthSchemeBasic, AuthSchemeLegacy}
+
+// RegistryAuthScheme picks how credentials are sent to one registry.
+// Registry is npm's URL-less key for it, such as "//registry.homa.io/"; it is
+// a list entry rather than a map key because viper splits keys on dots.
+type RegistryAuthScheme struct {
+	Registry string `mapstructure:"registry"`
+	Scheme   string `mapstructure:"scheme"`
+}
+
+// RegistryKey returns npm's URL-less key for a registry, such as
+// "//registry.gpm.sh/", or "" when registry isn't a URL
+func RegistryKey(registry string) string {
+	parsed, err := url.Parse(registry)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	path := strings.TrimSuffix(parsed.Path, "/") + "/"
+	return strings.ToLower("//" + parsed.Host + path)
+}
+
+// ParseAuthSchemeKey extracts the registry from an npm-style
+// "//host/path/:auth_scheme" configuration key
+func ParseAuthSchemeKey(key string) (string, bool) {
+	registry, ok := strings.CutSuffix(key, ":auth_scheme")
+	if !ok || !strings.HasPrefix(registry, "//") {
+		return "", false
+	}
+	registryKey := RegistryKey("https:" + registry)
+	if registryKey == "" {
+		return "", false
+	}
+	return registryKey, true
+}
+
+// SetAuthScheme sets the scheme for the registry with the given key
+func SetAuthScheme(registryKey, scheme string) {
+	cfg := GetConfig()
+	for i := range cfg.AuthSchemes {
+		if cfg.AuthSchemes[i].Registry == registryKey {
+			cfg.AuthSchemes[i].Scheme = scheme
+			return
+		}
+	}
+	cfg.AuthSchemes = append(cfg.AuthSchemes, RegistryAuthScheme{Registry: registryKey, Scheme: scheme})
+}
+
+// AuthSchemeFor returns the scheme for sending credentials to registryURL:
+// the one set for the longest registry key the URL falls under, otherwise
+// bearer
+func AuthSchemeFor(registryURL string) string {
+	registryKey := RegistryKey(registryURL)
+	scheme, matched := AuthSchemeBearer, 0
+	for _, entry := range GetConfig().AuthSchemes {
+		if registryKey != "" && strings.HasPrefix(registryKey, entry.Registry) && len(entry.Registry) > matched {
+			scheme, matched = entry.Scheme, len(entry.Registry)
+		}
+	}
+	return scheme
+}
+
+// validAuthScheme reports whether scheme is one of ValidAuthSchemes
+func validAuthScheme(scheme string) bool {
+	return slices.Contains(ValidAuthSchemes, scheme)
+}
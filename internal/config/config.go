@@ -2,10 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -14,6 +16,75 @@ type Config struct {
 	Registry string `mapstructure:"registry"`
 	Token    string `mapstructure:"token"`
 	Username string `mapstructure:"username"`
+	// UsernameCheckedAt is when Username was last confirmed with whoami, in
+	// RFC 3339; empty means it must be fetched again
+	UsernameCheckedAt string `mapstructure:"username_checked_at"`
+	// ScopedRegistries maps an npm-style scope ("@homa") to its registry URL
+	ScopedRegistries map[string]string `mapstructure:"scoped_registries"`
+	// TarballHosts lists extra hosts (e.g. a CDN) trusted to serve tarballs
+	TarballHosts []string `mapstructure:"tarball_hosts"`
+	// RegistryFallbacks are registries install tries, in order, for a
+	// package the registry doesn't have and whose scope isn't mapped. They
+	// are sent no credentials.
+	RegistryFallbacks []string `mapstructure:"registry_fallbacks"`
+	// CompressionLevel is the default gzip level for pack/publish; nil means
+	// gzip's default
+	CompressionLevel *int `mapstructure:"compression_level"`
+	// Studio is the studio these credentials belong to
+	Studio string `mapstructure:"studio"`
+	// Profiles are named bundles of registry, credentials, and scope mappings
+	Profiles map[string]*Profile `mapstructure:"profiles"`
+	// ActiveProfile is the profile selected with `gpm config profile use`;
+	// $GPM_PROFILE overrides it
+	ActiveProfile string `mapstructure:"active_profile"`
+	// UpdateCheck enables the daily new-release check; nil means disabled
+	UpdateCheck *bool `mapstructure:"update_check"`
+	// UpdateURL is where the latest release manifest is fetched from
+	UpdateURL string `mapstructure:"update_url"`
+	// UserAgent overrides the User-Agent sent with every request
+	UserAgent string `mapstructure:"user_agent"`
+	// RegistryTimeout bounds the connect preflight add and install run
+	// against the registry, as a duration such as "3s"
+	RegistryTimeout string `mapstructure:"registry_timeout"`
+	// AutoScopedRegistry lets add and install write Unity scopedRegistries
+	// entries; nil means enabled
+	AutoScopedRegistry *bool `mapstructure:"auto_scoped_registry"`
+	// DefaultTag is the dist-tag add and install resolve packages requested
+	// without a version through; empty means latest
+	DefaultTag string `mapstructure:"default_tag"`
+	// AuthSchemes picks how the token is sent to registries that don't take
+	// a bearer token
+	AuthSchemes []RegistryAuthScheme `mapstructure:"auth_schemes"`
+	// Defaults holds flag defaults by command path and flag name, such as
+	// access: private under publish. They replace the built-in defaults
+	// before the command line is parsed, so explicit flags still win.
+	Defaults map[string]map[string]string `mapstructure:"defaults"`
+	// Allowlist, when set, restricts add and install to packages matching
+	// one of its entries; see MatchesPackagePattern
+	Allowlist []string `mapstructure:"allowlist"`
+	// Blocklist lists packages add and install refuse, with the reasons
+	Blocklist []BlockedPackage `mapstructure:"blocklist"`
+	// ExtraHeaders are sent with every registry request, e.g. the key an API
+	// gateway in front of the registry expects
+	ExtraHeaders map[string]string `mapstructure:"extra_headers"`
+	// SensitiveHeaders are redacted in debug output, on top of the built-in
+	// credential headers
+	SensitiveHeaders []string `mapstructure:"sensitive_headers"`
+	// AllowHeaderOverride lets extra headers replace Authorization and the
+	// other headers gpm sets itself
+	AllowHeaderOverride bool `mapstructure:"allow_header_override"`
+	// StateDir is where gpm keeps project state such as backups: a path
+	// relative to the project root, or an absolute directory holding one
+	// subdirectory per project. Empty means .gpm in the project root.
+	StateDir string `mapstructure:"state_dir"`
+
+	// profile is the profile overlaid on the fields above and base holds
+	// the top-level values it hides
+	profile string
+	base    Profile
+	// warnings are problems met while loading, for the command to print;
+	// config never writes to stdout, which may carry JSON or an SBOM
+	warnings []string
 }
 
 type ValidationError struct {
@@ -25,11 +96,22 @@ func (e ValidationError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Field, e.Message)
 }
 
+// IdentityCacheTTL is how long a username confirmed with whoami is trusted
+// before it is fetched again
+const IdentityCacheTTL = 24 * time.Hour
+
+// DefaultRegistryTimeout is the registry connect preflight timeout when
+// registry_timeout is unset
+const DefaultRegistryTimeout = 3 * time.Second
+
+// NoUpdateCheckEnv disables the daily update check when set to any value
+const NoUpdateCheckEnv = "GPM_NO_UPDATE_CHECK"
+
 var config *Config
 
 func InitConfig() {
 	// Set default values
-	viper.SetDefault("registry", "https://registry.gpm.sh")
+	viper.SetDefault("registry", DefaultRegistry)
 	viper.SetDefault("token", "")
 	viper.SetDefault("username", "")
 
@@ -60,18 +142,25 @@ func InitConfig() {
 	// Try to read config file
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			// Only log non-ConfigFileNotFound errors
-			fmt.Printf("Warning: Error reading config file: %v\n", err)
+			// Only report non-ConfigFileNotFound errors
+			config.warnings = append(config.warnings, fmt.Sprintf("Error reading config file: %v", err))
 		}
 		// Continue with defaults even if config file is not found
 	}
 
 	// Unmarshal config into struct
 	if err := viper.Unmarshal(config); err != nil {
-		fmt.Printf("Warning: Error unmarshaling config: %v\n", err)
+		config.warnings = append(config.warnings, fmt.Sprintf("Error unmarshaling config: %v", err))
 		// Continue with defaults if unmarshaling fails
 	}
 
+	config.selectProfile()
+}
+
+// Warnings returns the problems met while loading the config, such as an
+// unknown $GPM_PROFILE
+func Warnings() []string {
+	return GetConfig().warnings
 }
 
 func GetConfig() *Config {
@@ -83,7 +172,7 @@ func GetConfig() *Config {
 	if config == nil {
 		// Fallback to default config if InitConfig fails
 		config = &Config{
-			Registry: "https://registry.gpm.sh",
+			Registry: DefaultRegistry,
 			Token:    "",
 			Username: "",
 		}
@@ -99,9 +188,83 @@ func SaveConfig() error {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	viper.Set("registry", cfg.Registry)
-	viper.Set("token", cfg.Token)
-	viper.Set("username", cfg.Username)
+	// Edits made while a profile is active belong to that profile
+	cfg.syncProfile()
+	top := cfg.topLevel()
+
+	viper.Set("registry", top.Registry)
+	viper.Set("token", top.Token)
+	viper.Set("username", top.Username)
+	viper.Set("username_checked_at", top.UsernameCheckedAt)
+	if top.Studio != "" {
+		viper.Set("studio", top.Studio)
+	}
+	if len(top.ScopedRegistries) > 0 {
+		viper.Set("scoped_registries", top.ScopedRegistries)
+	}
+	if cfg.Profiles != nil {
+		viper.Set("profiles", profilesForSave(cfg.Profiles))
+	}
+	viper.Set("active_profile", cfg.ActiveProfile)
+	if cfg.TarballHosts != nil {
+		viper.Set("tarball_hosts", cfg.TarballHosts)
+	}
+	if cfg.RegistryFallbacks != nil {
+		viper.Set("registry_fallbacks", cfg.RegistryFallbacks)
+	}
+	if cfg.CompressionLevel != nil {
+		viper.Set("compression_level", *cfg.CompressionLevel)
+	}
+	if cfg.UpdateCheck != nil {
+		viper.Set("update_check", *cfg.UpdateCheck)
+	}
+	if cfg.UpdateURL != "" {
+		viper.Set("update_url", cfg.UpdateURL)
+	}
+	if cfg.UserAgent != "" {
+		viper.Set("user_agent", cfg.UserAgent)
+	}
+	if cfg.RegistryTimeout != "" {
+		viper.Set("registry_timeout", cfg.RegistryTimeout)
+	}
+	if cfg.AutoScopedRegistry != nil {
+		viper.Set("auto_scoped_registry", *cfg.AutoScopedRegistry)
+	}
+	if cfg.DefaultTag != "" {
+		viper.Set("default_tag", cfg.DefaultTag)
+	}
+	if len(cfg.AuthSchemes) > 0 {
+		authSchemes := make([]map[string]string, 0, len(cfg.AuthSchemes))
+		for _, entry := range cfg.AuthSchemes {
+			authSchemes = append(authSchemes, map[string]string{"registry": entry.Registry, "scheme": entry.Scheme})
+		}
+		viper.Set("auth_schemes", authSchemes)
+	}
+	if len(cfg.Defaults) > 0 {
+		viper.Set("defaults", cfg.Defaults)
+	}
+	if cfg.Allowlist != nil {
+		viper.Set("allowlist", cfg.Allowlist)
+	}
+	if cfg.Blocklist != nil {
+		blocklist := make([]map[string]string, 0, len(cfg.Blocklist))
+		for _, blocked := range cfg.Blocklist {
+			blocklist = append(blocklist, map[string]string{"package": blocked.Package, "reason": blocked.Reason})
+		}
+		viper.Set("blocklist", blocklist)
+	}
+	if len(cfg.ExtraHeaders) > 0 {
+		viper.Set("extra_headers", cfg.ExtraHeaders)
+	}
+	if cfg.SensitiveHeaders != nil {
+		viper.Set("sensitive_headers", cfg.SensitiveHeaders)
+	}
+	if cfg.AllowHeaderOverride {
+		viper.Set("allow_header_override", true)
+	}
+	if cfg.StateDir != "" {
+		viper.Set("state_dir", cfg.StateDir)
+	}
 
 	configFile := viper.ConfigFileUsed()
 	if configFile == "" {
@@ -128,8 +291,13 @@ func SetRegistry(registry string) {
 	cfg.Registry = registry
 }
 
+// SetToken sets the auth token. A different token may belong to someone
+// else, so the cached username has to be confirmed again.
 func SetToken(token string) {
 	cfg := GetConfig()
+	if token != cfg.Token {
+		cfg.UsernameCheckedAt = ""
+	}
 	cfg.Token = token
 }
 
@@ -138,10 +306,37 @@ func SetUsername(username string) {
 	cfg.Username = username
 }
 
+func SetStudio(studio string) {
+	cfg := GetConfig()
+	cfg.Studio = studio
+}
+
 func ResetAuthData() {
 	cfg := GetConfig()
 	cfg.Token = ""
 	cfg.Username = ""
+	cfg.UsernameCheckedAt = ""
+}
+
+// CacheUsername records a username just confirmed with whoami
+func CacheUsername(username string) {
+	cfg := GetConfig()
+	cfg.Username = username
+	cfg.UsernameCheckedAt = time.Now().UTC().Format(time.RFC3339)
+}
+
+// CachedUsername returns the username if it was confirmed with whoami less
+// than ttl ago
+func CachedUsername(ttl time.Duration) (string, bool) {
+	cfg := GetConfig()
+	if cfg.Username == "" || cfg.UsernameCheckedAt == "" {
+		return "", false
+	}
+	checkedAt, err := time.Parse(time.RFC3339, cfg.UsernameCheckedAt)
+	if err != nil || time.Since(checkedAt) >= ttl {
+		return "", false
+	}
+	return cfg.Username, true
 }
 
 func GetRegistry() string {
@@ -159,6 +354,158 @@ func GetUsername() string {
 	return cfg.Username
 }
 
+// SetTarballHosts replaces the trusted tarball hosts from a comma-separated list
+func SetTarballHosts(hosts string) {
+	cfg := GetConfig()
+	cfg.TarballHosts = []string{}
+	for _, host := range strings.Split(hosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			cfg.TarballHosts = append(cfg.TarballHosts, host)
+		}
+	}
+}
+
+// SetRegistryFallbacks replaces the registry fallback chain from a
+// comma-separated list of URLs
+func SetRegistryFallbacks(registries string) {
+	cfg := GetConfig()
+	cfg.RegistryFallbacks = []string{}
+	for _, registry := range strings.Split(registries, ",") {
+		if registry = strings.TrimSpace(registry); registry != "" {
+			cfg.RegistryFallbacks = append(cfg.RegistryFallbacks, registry)
+		}
+	}
+}
+
+func SetCompressionLevel(level int) {
+	cfg := GetConfig()
+	cfg.CompressionLevel = &level
+}
+
+func SetUpdateCheck(enabled bool) {
+	cfg := GetConfig()
+	cfg.UpdateCheck = &enabled
+}
+
+func SetUpdateURL(updateURL string) {
+	cfg := GetConfig()
+	cfg.UpdateURL = updateURL
+}
+
+func SetUserAgent(userAgent string) {
+	cfg := GetConfig()
+	cfg.UserAgent = userAgent
+}
+
+func SetRegistryTimeout(timeout string) {
+	cfg := GetConfig()
+	cfg.RegistryTimeout = timeout
+}
+
+func SetDefaultTag(tag string) {
+	cfg := GetConfig()
+	cfg.DefaultTag = tag
+}
+
+func SetStateDir(dir string) {
+	cfg := GetConfig()
+	cfg.StateDir = dir
+}
+
+func SetAutoScopedRegistry(enabled bool) {
+	cfg := GetConfig()
+	cfg.AutoScopedRegistry = &enabled
+}
+
+// SetFlagDefault records the default of a flag for a command path such as
+// "publish" or "dist-tag add"
+func SetFlagDefault(command, flag, value string) {
+	cfg := GetConfig()
+	if cfg.Defaults == nil {
+		cfg.Defaults = make(map[string]map[string]string)
+	}
+	if cfg.Defaults[command] == nil {
+		cfg.Defaults[command] = make(map[string]string)
+	}
+	cfg.Defaults[command][flag] = value
+}
+
+// GetFlagDefault returns the configured default of a command's flag
+func GetFlagDefault(command, flag string) (string, bool) {
+	value, ok := GetConfig().Defaults[command][flag]
+	return value, ok
+}
+
+// FlagDefaults returns every configured flag default, by command path and
+// then flag name
+func FlagDefaults() map[string]map[string]string {
+	return GetConfig().Defaults
+}
+
+// AutoScopedRegistryEnabled reports whether add and install may add or update
+// Unity scoped registries
+func AutoScopedRegistryEnabled() bool {
+	cfg := GetConfig()
+	return cfg.AutoScopedRegistry == nil || *cfg.AutoScopedRegistry
+}
+
+// GetRegistryTimeout returns the registry connect preflight timeout, falling
+// back to DefaultRegistryTimeout when unset or invalid
+func GetRegistryTimeout() time.Duration {
+	cfg := GetConfig()
+	if timeout, err := time.ParseDuration(cfg.RegistryTimeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return DefaultRegistryTimeout
+}
+
+// UpdateCheckEnabled reports whether the daily update check may run. It is
+// opt-in through update_check, and setting $GPM_NO_UPDATE_CHECK disables it
+// regardless of the config file.
+func UpdateCheckEnabled() bool {
+	if os.Getenv(NoUpdateCheckEnv) != "" {
+		return false
+	}
+	cfg := GetConfig()
+	return cfg.UpdateCheck != nil && *cfg.UpdateCheck
+}
+
+// ParseScopeRegistryKey extracts the scope from an npm-style "@scope:registry"
+// configuration key
+func ParseScopeRegistryKey(key string) (string, bool) {
+	scope, ok := strings.CutSuffix(key, ":registry")
+	if !ok || !strings.HasPrefix(scope, "@") || len(scope) < 2 || strings.ContainsAny(scope, "/: ") {
+		return "", false
+	}
+	return strings.ToLower(scope), true
+}
+
+// PackageScope returns the "@scope" part of a scoped package name such as
+// "@homa/analytics", or "" for unscoped names
+func PackageScope(packageName string) string {
+	if !strings.HasPrefix(packageName, "@") {
+		return ""
+	}
+	scope, _, found := strings.Cut(packageName, "/")
+	if !found {
+		return ""
+	}
+	return strings.ToLower(scope)
+}
+
+func SetScopedRegistry(scope, registry string) {
+	cfg := GetConfig()
+	if cfg.ScopedRegistries == nil {
+		cfg.ScopedRegistries = make(map[string]string)
+	}
+	cfg.ScopedRegistries[strings.ToLower(scope)] = registry
+}
+
+func GetScopedRegistry(scope string) string {
+	cfg := GetConfig()
+	return cfg.ScopedRegistries[strings.ToLower(scope)]
+}
+
 // SetConfigForTesting allows tests to override the global config
 func SetConfigForTesting(testConfig *Config) {
 	config = testConfig
@@ -169,6 +516,28 @@ func ResetConfigForTesting() {
 	config = nil
 }
 
+// SecureURL reports whether raw is an https URL, or an http one on the
+// local machine such as a test registry at http://localhost:4873
+func SecureURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := u.Hostname()
+		if host == "localhost" {
+			return true
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback()
+	default:
+		return false
+	}
+}
+
 func validateConfig(cfg *Config) error {
 	if cfg.Registry != "" {
 		if _, err := url.Parse(cfg.Registry); err != nil {
@@ -179,6 +548,50 @@ func validateConfig(cfg *Config) error {
 		}
 	}
 
+	for scope, registry := range cfg.ScopedRegistries {
+		if !strings.HasPrefix(registry, "http://") && !strings.HasPrefix(registry, "https://") {
+			return ValidationError{Field: scope + ":registry", Message: "registry URL must use http or https"}
+		}
+	}
+
+	for _, entry := range cfg.AuthSchemes {
+		if !validAuthScheme(entry.Scheme) {
+			return ValidationError{Field: entry.Registry + ":auth_scheme", Message: "must be one of " + strings.Join(ValidAuthSchemes, ", ")}
+		}
+	}
+
+	for _, registry := range cfg.RegistryFallbacks {
+		if !strings.HasPrefix(registry, "http://") && !strings.HasPrefix(registry, "https://") {
+			return ValidationError{Field: "registry_fallbacks", Message: "registry URL must use http or https"}
+		}
+	}
+
+	for name, profile := range cfg.Profiles {
+		if profile.Registry != "" && !strings.HasPrefix(profile.Registry, "http://") && !strings.HasPrefix(profile.Registry, "https://") {
+			return ValidationError{Field: "profiles." + name + ".registry", Message: "registry URL must use http or https"}
+		}
+		for scope, registry := range profile.ScopedRegistries {
+			if !strings.HasPrefix(registry, "http://") && !strings.HasPrefix(registry, "https://") {
+				return ValidationError{Field: "profiles." + name + "." + scope + ":registry", Message: "registry URL must use http or https"}
+			}
+		}
+	}
+
+	// self-update installs whatever binary the manifest points at
+	if cfg.UpdateURL != "" && !SecureURL(cfg.UpdateURL) {
+		return ValidationError{Field: "update_url", Message: "update URL must use https"}
+	}
+
+	if cfg.RegistryTimeout != "" {
+		if timeout, err := time.ParseDuration(cfg.RegistryTimeout); err != nil || timeout <= 0 {
+			return ValidationError{Field: "registry_timeout", Message: "must be a positive duration such as 3s or 500ms"}
+		}
+	}
+
+	if cfg.CompressionLevel != nil && (*cfg.CompressionLevel < 0 || *cfg.CompressionLevel > 9) {
+		return ValidationError{Field: "compression_level", Message: "must be between 0 and 9"}
+	}
+
 	if cfg.Username != "" {
 		if len(cfg.Username) < 3 || len(cfg.Username) > 50 {
 			return ValidationError{Field: "username", Message: "username must be between 3 and 50 characters"}
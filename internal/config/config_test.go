@@ -93,4 +93,233 @@ func TestConfigSetters(t *testing.T) {
 	assert.Equal(t, "https://test.gpm.sh", GetRegistry())
 	assert.Equal(t, "new-token", GetToken())
 	assert.Equal(t, "newuser", GetUsername())
+
+	// Scoped registry injection defaults on until explicitly disabled
+	assert.True(t, AutoScopedRegistryEnabled())
+	SetAutoScopedRegistry(false)
+	assert.False(t, AutoScopedRegistryEnabled())
+}
+
+func TestScopedRegistries(t *testing.T) {
+	config = nil
+	viper.Reset()
+	InitConfig()
+
+	scope, ok := ParseScopeRegistryKey("@Homa:registry")
+	require.True(t, ok)
+	assert.Equal(t, "@homa", scope)
+
+	for _, key := range []string{"registry", "homa:registry", "@:registry", "@homa/x:registry", "@homa:token"} {
+		_, ok := ParseScopeRegistryKey(key)
+		assert.False(t, ok, key)
+	}
+
+	assert.Equal(t, "@homa", PackageScope("@homa/analytics"))
+	assert.Equal(t, "", PackageScope("com.homa.analytics"))
+	assert.Equal(t, "", PackageScope("@homa"))
+
+	SetScopedRegistry("@homa", "https://registry.homa.io")
+	assert.Equal(t, "https://registry.homa.io", GetScopedRegistry("@HOMA"))
+	assert.Equal(t, "", GetScopedRegistry("@other"))
+
+	SetScopedRegistry("@bad", "ftp://registry.bad.io")
+	assert.Error(t, validateConfig(GetConfig()))
+}
+
+func TestUpdateURLRequiresHTTPS(t *testing.T) {
+	config = nil
+	viper.Reset()
+	InitConfig()
+	defer ResetConfigForTesting()
+
+	SetUpdateURL("https://releases.gpm.sh/cli/latest.json")
+	assert.NoError(t, validateConfig(GetConfig()))
+	SetUpdateURL("http://127.0.0.1:8080/latest.json")
+	assert.NoError(t, validateConfig(GetConfig()), "a local test server may use http")
+	SetUpdateURL("http://releases.gpm.sh/cli/latest.json")
+	assert.Error(t, validateConfig(GetConfig()))
+}
+
+func TestAuthSchemes(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	reload := func() {
+		config = nil
+		viper.Reset()
+		InitConfig()
+	}
+	reload()
+	defer ResetConfigForTesting()
+
+	registryKey, ok := ParseAuthSchemeKey("//Registry.Homa.io/npm:auth_scheme")
+	require.True(t, ok)
+	assert.Equal(t, "//registry.homa.io/npm/", registryKey)
+	for _, key := range []string{"auth_scheme", "registry.homa.io/:auth_scheme", "//:auth_scheme", "//registry.homa.io/:_authToken"} {
+		_, ok := ParseAuthSchemeKey(key)
+		assert.False(t, ok, key)
+	}
+
+	assert.Equal(t, AuthSchemeBearer, AuthSchemeFor("https://registry.homa.io"), "bearer by default")
+	SetAuthScheme("//registry.homa.io/", AuthSchemeLegacy)
+	SetAuthScheme("//registry.homa.io/npm/", AuthSchemeBasic)
+	require.NoError(t, SaveConfig())
+
+	// Host names have dots, which viper would read as nesting in map keys
+	reload()
+	assert.Equal(t, AuthSchemeLegacy, AuthSchemeFor("https://registry.homa.io/"))
+	assert.Equal(t, AuthSchemeBasic, AuthSchemeFor("https://registry.homa.io/npm"), "the longest registry key wins")
+	assert.Equal(t, AuthSchemeBearer, AuthSchemeFor("https://registry.gpm.sh"))
+
+	SetAuthScheme("//registry.homa.io/", "digest")
+	assert.Error(t, validateConfig(GetConfig()))
+}
+
+func TestProfiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv(ProfileEnv, "")
+	configContent := `registry: "https://registry.gpm.sh"
+token: "personal-token"`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gpmrc"), []byte(configContent), 0600))
+
+	reload := func() {
+		config = nil
+		viper.Reset()
+		InitConfig()
+	}
+	reload()
+	defer ResetConfigForTesting()
+
+	require.NoError(t, CreateProfile("Homa", Profile{
+		Registry:         "https://registry.homa.io",
+		Token:            "homa-token",
+		Studio:           "homa",
+		ScopedRegistries: map[string]string{"@homa": "https://npm.homa.io"},
+	}))
+	assert.Error(t, CreateProfile("homa", Profile{}), "duplicate profile")
+	assert.Error(t, CreateProfile("default", Profile{}), "reserved name")
+	assert.Error(t, CreateProfile("bad name", Profile{}), "invalid name")
+	require.NoError(t, SaveConfig())
+
+	// Creating a profile does not switch to it
+	assert.Equal(t, DefaultProfile, CurrentProfile())
+	assert.Equal(t, "personal-token", GetToken())
+
+	require.NoError(t, UseProfile("homa"))
+	assert.Equal(t, "homa", CurrentProfile())
+	assert.Equal(t, "https://registry.homa.io", GetRegistry())
+	assert.Equal(t, "homa-token", GetToken())
+	assert.Equal(t, "https://npm.homa.io", GetScopedRegistry("@homa"))
+	assert.Error(t, UseProfile("missing"))
+	assert.Error(t, DeleteProfile("homa"), "active profile cannot be deleted")
+
+	// Edits while a profile is active land in the profile, not the top level
+	SetToken("rotated-token")
+	require.NoError(t, SaveConfig())
+
+	reload()
+	assert.Equal(t, "homa", CurrentProfile())
+	assert.Equal(t, "rotated-token", GetToken())
+
+	require.NoError(t, UseProfile(DefaultProfile))
+	assert.Equal(t, "https://registry.gpm.sh", GetRegistry())
+	assert.Equal(t, "personal-token", GetToken())
+	assert.Equal(t, "", GetScopedRegistry("@homa"))
+	require.NoError(t, SaveConfig())
+
+	// GPM_PROFILE overrides the stored active profile
+	t.Setenv(ProfileEnv, "homa")
+	reload()
+	assert.Equal(t, "homa", CurrentProfile())
+	assert.Equal(t, "rotated-token", GetToken())
+
+	t.Setenv(ProfileEnv, "missing")
+	reload()
+	assert.Equal(t, DefaultProfile, CurrentProfile())
+	assert.Equal(t, "personal-token", GetToken())
+	assert.Equal(t, []string{`profile "missing" not found, using top-level settings`}, Warnings())
+}
+
+func TestResolveEffective(t *testing.T) {
+	t.Setenv(RegistryEnv, "")
+	t.Setenv(TokenEnv, "")
+	viper.Reset()
+	defer ResetConfigForTesting()
+
+	SetConfigForTesting(&Config{
+		Registry:         "https://user.example.com",
+		Token:            "user-token",
+		ScopedRegistries: map[string]string{"@homa": "https://user-homa.example.com"},
+	})
+
+	projectDir := t.TempDir()
+	resolved := ResolveRegistry("", projectDir)
+	assert.Equal(t, "https://user.example.com", resolved.Value)
+	assert.Equal(t, SourceUser, resolved.Source)
+
+	// A project .npmrc beats the user config
+	t.Setenv("NPM_TOKEN", "npmrc-token")
+	npmrc := "registry=https://project.example.com/\n" +
+		"@homa:registry=https://project-homa.example.com\n" +
+		"//project.example.com/:_authToken=${NPM_TOKEN}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".npmrc"), []byte(npmrc), 0600))
+
+	resolved = ResolveRegistry("", projectDir)
+	assert.Equal(t, "https://project.example.com/", resolved.Value)
+	assert.Equal(t, SourceNpmrc, resolved.Source)
+	assert.Equal(t, Resolved{Value: "npmrc-token", Source: SourceNpmrc, Origin: filepath.Join(projectDir, ".npmrc")}, ResolveToken("", projectDir))
+	assert.Equal(t, "https://project-homa.example.com", ResolveScopedRegistry("@homa", "", projectDir).Value)
+
+	// Env beats the .npmrc, and a flag beats env
+	t.Setenv(RegistryEnv, "https://env.example.com")
+	assert.Equal(t, Resolved{Value: "https://env.example.com", Source: SourceEnv, Origin: RegistryEnv}, ResolveRegistry("", projectDir))
+	assert.Equal(t, SourceFlag, ResolveRegistry("https://flag.example.com", projectDir).Source)
+
+	// An active profile beats the .npmrc and user config
+	t.Setenv(RegistryEnv, "")
+	cfg := GetConfig()
+	cfg.Profiles = map[string]*Profile{"homa": {Registry: "https://profile.example.com", Token: "profile-token"}}
+	cfg.applyProfile("homa")
+	assert.Equal(t, Resolved{Value: "https://profile.example.com", Source: SourceProfile, Origin: "homa"}, ResolveRegistry("", projectDir))
+	assert.Equal(t, SourceProfile, ResolveToken("", projectDir).Source)
+
+	// Unmapped scopes fall back to the effective registry
+	assert.Equal(t, "https://profile.example.com", ResolveScopedRegistry("@other", "", projectDir).Value)
+
+	// Nothing set resolves to the default
+	SetConfigForTesting(&Config{})
+	assert.Equal(t, Resolved{Value: DefaultRegistry, Source: SourceDefault}, ResolveRegistry("", t.TempDir()))
+}
+
+func TestResolveProjectConfig(t *testing.T) {
+	t.Setenv(RegistryEnv, "")
+	viper.Reset()
+	defer ResetConfigForTesting()
+
+	SetConfigForTesting(&Config{Registry: "https://user.example.com"})
+
+	// package.json's "gpm" block is used when there is no .gpmrc
+	projectDir := t.TempDir()
+	packageJSON := `{"name": "com.studio.game", "gpm": {"registry": "https://package.example.com", "scopedRegistries": {"@Homa": "https://package-homa.example.com"}}}`
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "package.json"), []byte(packageJSON), 0600))
+	assert.Equal(t, Resolved{Value: "https://package.example.com", Source: SourceProject, Origin: filepath.Join(projectDir, "package.json")}, ResolveRegistry("", projectDir))
+	assert.Equal(t, "https://package-homa.example.com", ResolveScopedRegistry("@homa", "", projectDir).Value)
+
+	// A project .gpmrc beats package.json and the project .npmrc
+	gpmrc := "registry: https://project.example.com\nscoped_registries:\n  \"@homa\": https://project-homa.example.com\n"
+	gpmrcPath := filepath.Join(projectDir, ProjectConfigFile)
+	require.NoError(t, os.WriteFile(gpmrcPath, []byte(gpmrc), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".npmrc"), []byte("registry=https://npmrc.example.com\n"), 0600))
+	assert.Equal(t, Resolved{Value: "https://project.example.com", Source: SourceProject, Origin: gpmrcPath}, ResolveRegistry("", projectDir))
+	assert.Equal(t, Resolved{Value: "https://project-homa.example.com", Source: SourceProject, Origin: gpmrcPath}, ResolveScopedRegistry("@homa", "", projectDir))
+
+	// Env and flags still override the project
+	t.Setenv(RegistryEnv, "https://env.example.com")
+	assert.Equal(t, SourceEnv, ResolveRegistry("", projectDir).Source)
+	assert.Equal(t, Resolved{Value: "https://flag.example.com", Source: SourceFlag, Origin: "--registry"}, ResolveRegistry("https://flag.example.com", projectDir))
+	assert.Equal(t, SourceFlag, ResolveScopedRegistry("@homa", "https://flag.example.com", projectDir).Source)
+
+	// Other projects fall back to the user config
+	t.Setenv(RegistryEnv, "")
+	assert.Equal(t, SourceUser, ResolveRegistry("", t.TempDir()).Source)
 }
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// DataDirEnv overrides the directory gpm keeps machine-wide data in
+const DataDirEnv = "GPM_DATA_DIR"
+
+// DataDir returns the directory gpm keeps data shared by every project in,
+// such as the tarball store: $GPM_DATA_DIR, else $XDG_DATA_HOME/gpm, else
+// ~/.local/share/gpm. macOS and Windows use gpm in the user config directory.
+func DataDir() (string, error) {
+	if dir := os.Getenv(DataDirEnv); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "gpm"), nil
+	}
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot determine the data directory: %w", err)
+		}
+		return filepath.Join(dir, "gpm"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "gpm"), nil
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// BlockedPackage is a blocklist entry: a package name glob ("com.vendor.*"),
+// an npm-style scope ("@vendor") or a reverse-domain prefix ("com.vendor"),
+// with the reason it may not be installed
+type BlockedPackage struct {
+	Package string `mapstructure:"package"`
+	Reason  string `mapstructure:"reason"`
+}
+
+// PolicyError is returned for a package the blocklist or allowlist rules out
+type PolicyError struct {
+	Package string `json:"package"`
+	// Rule is the blocklist entry that matched; it is empty when the package
+	// is refused for missing from the allowlist
+	Rule   string `json:"rule,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (e *PolicyError) Error() string {
+	if e.Rule == "" {
+		return fmt.Sprintf("package %s is not on the allowlist, so it can't be installed", e.Package)
+	}
+	if e.Reason == "" {
+		return fmt.Sprintf("package %s is blocked by the blocklist entry %s", e.Package, e.Rule)
+	}
+	return fmt.Sprintf("package %s is blocked by the blocklist entry %s: %s", e.Package, e.Rule, e.Reason)
+}
+
+// CheckPackagePolicy refuses packages matching a blocklist entry and, when
+// an allowlist is configured, packages matching none of its entries. The
+// blocklist wins when a package matches both.
+func CheckPackagePolicy(packageName string) error {
+	cfg := GetConfig()
+	for _, blocked := range cfg.Blocklist {
+		if MatchesPackagePattern(blocked.Package, packageName) {
+			return &PolicyError{Package: packageName, Rule: blocked.Package, Reason: blocked.Reason}
+		}
+	}
+	if len(cfg.Allowlist) == 0 {
+		return nil
+	}
+	for _, allowed := range cfg.Allowlist {
+		if MatchesPackagePattern(allowed, packageName) {
+			return nil
+		}
+	}
+	return &PolicyError{Package: packageName}
+}
+
+// MatchesPackagePattern reports whether a package name matches an allowlist
+// or blocklist entry. Names compare case-insensitively, as registries do.
+func MatchesPackagePattern(pattern, packageName string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	packageName = strings.ToLower(packageName)
+	if pattern == "" {
+		return false
+	}
+	if strings.HasPrefix(pattern, "@") && !strings.Contains(pattern, "/") {
+		return PackageScope(packageName) == pattern
+	}
+	if matched, _ := path.Match(pattern, packageName); matched {
+		return true
+	}
+	// A reverse-domain prefix covers its packages, like a Unity scope
+	return !strings.ContainsAny(pattern, "*?[") && strings.HasPrefix(packageName, pattern+".")
+}
+
+// ValidatePackagePattern checks an allowlist or blocklist entry is a valid
+// name glob
+func ValidatePackagePattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" || strings.ContainsAny(pattern, " :") {
+		return fmt.Errorf("invalid package pattern %q", pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid package pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// SetAllowlist replaces the allowlist from a comma-separated list; an empty
+// list turns allowlist mode off
+func SetAllowlist(patterns string) {
+	cfg := GetConfig()
+	cfg.Allowlist = splitPatterns(patterns)
+}
+
+// SetBlocklist replaces the blocklist from a comma-separated list of
+// patterns, keeping the reasons of entries that stay
+func SetBlocklist(patterns string) {
+	cfg := GetConfig()
+	reasons := make(map[string]string, len(cfg.Blocklist))
+	for _, blocked := range cfg.Blocklist {
+		reasons[blocked.Package] = blocked.Reason
+	}
+	cfg.Blocklist = []BlockedPackage{}
+	for _, pattern := range splitPatterns(patterns) {
+		cfg.Blocklist = append(cfg.Blocklist, BlockedPackage{Package: pattern, Reason: reasons[pattern]})
+	}
+}
+
+// BlockPackage adds a blocklist entry, or updates the reason of an existing one
+func BlockPackage(pattern, reason string) {
+	cfg := GetConfig()
+	for i, blocked := range cfg.Blocklist {
+		if blocked.Package == pattern {
+			cfg.Blocklist[i].Reason = reason
+			return
+		}
+	}
+	cfg.Blocklist = append(cfg.Blocklist, BlockedPackage{Package: pattern, Reason: reason})
+}
+
+// BlocklistPatterns returns the blocklist entries' patterns
+func BlocklistPatterns() []string {
+	cfg := GetConfig()
+	patterns := make([]string, 0, len(cfg.Blocklist))
+	for _, blocked := range cfg.Blocklist {
+		patterns = append(patterns, blocked.Package)
+	}
+	return patterns
+}
+
+func splitPatterns(patterns string) []string {
+	list := []string{}
+	for _, pattern := range strings.Split(patterns, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			list = append(list, pattern)
+		}
+	}
+	return list
+}
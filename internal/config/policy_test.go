@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesPackagePattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"com.vendor.sdk", "com.vendor.sdk", true},
+		{"com.vendor.*", "com.vendor.ads", true},
+		{"com.vendor.*", "com.vendorx.ads", false},
+		{"com.vendor", "com.vendor.ads", true},
+		{"com.vendor", "com.vendorx.ads", false},
+		{"@vendor", "@vendor/ads", true},
+		{"@vendor", "@vendorx/ads", false},
+		{"@vendor/*", "@vendor/ads", true},
+		{"COM.Vendor.*", "com.vendor.ads", true},
+		{"", "com.vendor.ads", false},
+	} {
+		assert.Equal(t, tc.want, MatchesPackagePattern(tc.pattern, tc.name), "%s against %s", tc.pattern, tc.name)
+	}
+	assert.Error(t, ValidatePackagePattern("com.vendor.["))
+	assert.Error(t, ValidatePackagePattern("@vendor:registry"))
+}
+
+func TestCheckPackagePolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gpmrc"), []byte("registry: https://registry.gpm.sh\n"), 0600))
+	reload := func() {
+		config = nil
+		viper.Reset()
+		InitConfig()
+	}
+	reload()
+	defer ResetConfigForTesting()
+
+	assert.NoError(t, CheckPackagePolicy("com.vendor.ads"), "no policy allows everything")
+
+	BlockPackage("com.vendor.gpl-*", "GPL-3.0 is not allowed in shipped games")
+	SetAllowlist("com.vendor, @homa")
+	require.NoError(t, SaveConfig())
+	reload()
+
+	err := CheckPackagePolicy("com.vendor.gpl-physics")
+	var policyErr *PolicyError
+	require.True(t, errors.As(err, &policyErr))
+	assert.Equal(t, PolicyError{Package: "com.vendor.gpl-physics", Rule: "com.vendor.gpl-*", Reason: "GPL-3.0 is not allowed in shipped games"}, *policyErr)
+	assert.EqualError(t, err, "package com.vendor.gpl-physics is blocked by the blocklist entry com.vendor.gpl-*: GPL-3.0 is not allowed in shipped games")
+
+	assert.NoError(t, CheckPackagePolicy("com.vendor.ads"))
+	assert.NoError(t, CheckPackagePolicy("@homa/analytics"))
+	err = CheckPackagePolicy("com.other.ads")
+	require.True(t, errors.As(err, &policyErr))
+	assert.Empty(t, policyErr.Rule)
+	assert.Contains(t, err.Error(), "not on the allowlist")
+
+	// Replacing the blocklist keeps the reasons of entries that stay
+	SetBlocklist("com.vendor.gpl-*,@sketchy")
+	assert.Equal(t, []BlockedPackage{
+		{Package: "com.vendor.gpl-*", Reason: "GPL-3.0 is not allowed in shipped games"},
+		{Package: "@sketchy"},
+	}, GetConfig().Blocklist)
+
+	SetAllowlist("")
+	SetBlocklist("")
+	assert.NoError(t, CheckPackagePolicy("com.vendor.gpl-physics"))
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// ProfileEnv selects a profile for a single invocation, overriding the
+	// active profile stored in the config file
+	ProfileEnv = "GPM_PROFILE"
+
+	// DefaultProfile names the top-level settings that apply when no profile
+	// is active
+	DefaultProfile = "default"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Profile bundles the registry, credentials, and scope mappings used for one
+// studio so contractors can switch between them in one step
+type Profile struct {
+	Registry         string            `mapstructure:"registry"`
+	Token            string            `mapstructure:"token"`
+	Username         string            `mapstructure:"username"`
+	Studio           string            `mapstructure:"studio"`
+	ScopedRegistries map[string]string `mapstructure:"scoped_registries"`
+	// UsernameCheckedAt is when Username was last confirmed with whoami
+	UsernameCheckedAt string `mapstructure:"username_checked_at"`
+}
+
+// NormalizeProfileName lowercases a profile name, matching how the config
+// file stores keys
+func NormalizeProfileName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// CreateProfile adds a new named profile. An empty registry falls back to the
+// default registry.
+func CreateProfile(name string, profile Profile) error {
+	name = NormalizeProfileName(name)
+	if name == DefaultProfile {
+		return fmt.Errorf("profile name %q is reserved for the top-level settings", DefaultProfile)
+	}
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, numbers, dots, underscores, and hyphens", name)
+	}
+
+	cfg := GetConfig()
+	if _, exists := cfg.Profiles[name]; exists {
+		return fmt.Errorf("profile %s already exists", name)
+	}
+	if profile.Registry == "" {
+		profile.Registry = DefaultRegistry
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]*Profile)
+	}
+	cfg.Profiles[name] = &profile
+	return nil
+}
+
+// UseProfile makes a profile the active one. Passing DefaultProfile returns
+// to the top-level settings.
+func UseProfile(name string) error {
+	name = NormalizeProfileName(name)
+	cfg := GetConfig()
+	if name != DefaultProfile {
+		if _, exists := cfg.Profiles[name]; !exists {
+			return fmt.Errorf("profile %s does not exist", name)
+		}
+	}
+
+	cfg.unapplyProfile()
+	if name == DefaultProfile {
+		cfg.ActiveProfile = ""
+		return nil
+	}
+	cfg.ActiveProfile = name
+	cfg.applyProfile(name)
+	return nil
+}
+
+// DeleteProfile removes a profile. The active profile cannot be deleted.
+func DeleteProfile(name string) error {
+	name = NormalizeProfileName(name)
+	cfg := GetConfig()
+	if _, exists := cfg.Profiles[name]; !exists {
+		return fmt.Errorf("profile %s does not exist", name)
+	}
+	if name == cfg.profile {
+		return fmt.Errorf("profile %s is in use; switch with 'gpm config profile use' first", name)
+	}
+	delete(cfg.Profiles, name)
+	return nil
+}
+
+// CurrentProfile returns the profile applied to the effective settings, or
+// DefaultProfile when none is
+func CurrentProfile() string {
+	cfg := GetConfig()
+	if cfg.profile == "" {
+		return DefaultProfile
+	}
+	return cfg.profile
+}
+
+// ProfileNames returns the configured profile names in sorted order
+func ProfileNames() []string {
+	cfg := GetConfig()
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileRegistry returns the registry a profile resolves to, with
+// DefaultProfile meaning the top-level settings
+func ProfileRegistry(name string) string {
+	cfg := GetConfig()
+	name = NormalizeProfileName(name)
+	if name == cfg.profile {
+		return cfg.Registry
+	}
+	if profile, ok := cfg.Profiles[name]; ok && profile.Registry != "" {
+		return profile.Registry
+	}
+	return cfg.topLevel().Registry
+}
+
+// selectProfile applies the profile named by $GPM_PROFILE, or else the
+// stored active profile
+func (c *Config) selectProfile() {
+	name := NormalizeProfileName(os.Getenv(ProfileEnv))
+	if name == "" {
+		name = NormalizeProfileName(c.ActiveProfile)
+	}
+	if name == "" || name == DefaultProfile {
+		return
+	}
+	if _, exists := c.Profiles[name]; !exists {
+		c.warnings = append(c.warnings, fmt.Sprintf("profile %q not found, using top-level settings", name))
+		return
+	}
+	c.applyProfile(name)
+}
+
+// applyProfile overlays a profile onto the effective settings, keeping the
+// top-level values so SaveConfig can write them back untouched
+func (c *Config) applyProfile(name string) {
+	profile := c.Profiles[name]
+	c.base = Profile{
+		Registry:          c.Registry,
+		Token:             c.Token,
+		Username:          c.Username,
+		UsernameCheckedAt: c.UsernameCheckedAt,
+		Studio:            c.Studio,
+		ScopedRegistries:  c.ScopedRegistries,
+	}
+	c.profile = name
+
+	c.Registry = profile.Registry
+	if c.Registry == "" {
+		c.Registry = c.base.Registry
+	}
+	c.Token = profile.Token
+	c.Username = profile.Username
+	c.UsernameCheckedAt = profile.UsernameCheckedAt
+	c.Studio = profile.Studio
+	c.ScopedRegistries = profile.ScopedRegistries
+}
+
+// unapplyProfile stores edits back into the applied profile and restores the
+// top-level settings
+func (c *Config) unapplyProfile() {
+	if c.profile == "" {
+		return
+	}
+	c.syncProfile()
+	c.Registry = c.base.Registry
+	c.Token = c.base.Token
+	c.Username = c.base.Username
+	c.UsernameCheckedAt = c.base.UsernameCheckedAt
+	c.Studio = c.base.Studio
+	c.ScopedRegistries = c.base.ScopedRegistries
+	c.base = Profile{}
+	c.profile = ""
+}
+
+// syncProfile copies the effective settings into the applied profile
+func (c *Config) syncProfile() {
+	if profile, ok := c.Profiles[c.profile]; ok {
+		profile.Registry = c.Registry
+		profile.Token = c.Token
+		profile.Username = c.Username
+		profile.UsernameCheckedAt = c.UsernameCheckedAt
+		profile.Studio = c.Studio
+		profile.ScopedRegistries = c.ScopedRegistries
+	}
+}
+
+// topLevel returns the settings that belong at the top of the config file
+func (c *Config) topLevel() Profile {
+	if c.profile != "" {
+		return c.base
+	}
+	return Profile{
+		Registry:          c.Registry,
+		Token:             c.Token,
+		Username:          c.Username,
+		UsernameCheckedAt: c.UsernameCheckedAt,
+		Studio:            c.Studio,
+		ScopedRegistries:  c.ScopedRegistries,
+	}
+}
+
+func profilesForSave(profiles map[string]*Profile) map[string]any {
+	out := make(map[string]any, len(profiles))
+	for name, profile := range profiles {
+		entry := map[string]any{
+			"registry": profile.Registry,
+			"token":    profile.Token,
+			"username": profile.Username,
+		}
+		if profile.UsernameCheckedAt != "" {
+			entry["username_checked_at"] = profile.UsernameCheckedAt
+		}
+		if profile.Studio != "" {
+			entry["studio"] = profile.Studio
+		}
+		if len(profile.ScopedRegistries) > 0 {
+			entry["scoped_registries"] = profile.ScopedRegistries
+		}
+		out[name] = entry
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ProjectConfigFile is the project-level gpm settings file, committed next to
+// the project's package.json. It uses the same YAML keys as the user config.
+const ProjectConfigFile = ".gpmrc"
+
+// projectConfig is the part of the user config a project may pin for every
+// clone: the registry and scope mappings
+type projectConfig struct {
+	Registry         string            `mapstructure:"registry" json:"registry"`
+	ScopedRegistries map[string]string `mapstructure:"scoped_registries" json:"scopedRegistries"`
+}
+
+// readProjectConfig reads the project's .gpmrc or, without one, the "gpm"
+// block of its package.json. It returns nil when the project sets neither,
+// along with the file the settings came from.
+func readProjectConfig(projectDir string) (*projectConfig, string) {
+	if projectDir == "" {
+		return nil, ""
+	}
+
+	path := filepath.Join(projectDir, ProjectConfigFile)
+	// A project in the home directory shares its .gpmrc with the user config
+	if _, err := os.Stat(path); err == nil && !sameFile(path, viper.ConfigFileUsed()) {
+		v := viper.New()
+		v.SetConfigFile(path)
+		v.SetConfigType("yaml")
+		var project projectConfig
+		if v.ReadInConfig() == nil && v.Unmarshal(&project) == nil {
+			return project.normalized(), path
+		}
+	}
+
+	path = filepath.Join(projectDir, "package.json")
+	data, err := os.ReadFile(path) // #nosec G304 - fixed file name inside the project directory
+	if err != nil {
+		return nil, ""
+	}
+	var packageJSON struct {
+		GPM *projectConfig `json:"gpm"`
+	}
+	if json.Unmarshal(data, &packageJSON) != nil || packageJSON.GPM == nil {
+		return nil, ""
+	}
+	return packageJSON.GPM.normalized(), path
+}
+
+// normalized lowercases scope keys the way SetScopedRegistry stores them
+func (p *projectConfig) normalized() *projectConfig {
+	scopes := make(map[string]string, len(p.ScopedRegistries))
+	for scope, registry := range p.ScopedRegistries {
+		scopes[strings.ToLower(scope)] = registry
+	}
+	p.ScopedRegistries = scopes
+	return p
+}
+
+func sameFile(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	aInfo, aErr := os.Stat(a)
+	bInfo, bErr := os.Stat(b)
+	return aErr == nil && bErr == nil && os.SameFile(aInfo, bInfo)
+}
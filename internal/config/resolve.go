@@ -0,0 +1,175 @@
+package config
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// DefaultRegistry is used when no source sets a registry
+	DefaultRegistry = "https://registry.gpm.sh"
+
+	// RegistryEnv overrides the registry for a single invocation
+	RegistryEnv = "GPM_REGISTRY"
+
+	// TokenEnv overrides the auth token for a single invocation
+	TokenEnv = "GPM_TOKEN"
+)
+
+// Sources a setting can resolve from, highest precedence first
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceProfile = "profile"
+	SourceProject = "project config"
+	SourceNpmrc   = "project .npmrc"
+	SourceUser    = "user config"
+	SourceDefault = "default"
+)
+
+// Resolved is an effective setting and where it came from
+type Resolved struct {
+	Value  string
+	Source string
+	// Origin names the flag, variable, profile, or file that set the value
+	Origin string
+}
+
+// ResolveRegistry returns the effective registry. Precedence is flag > env >
+// profile > project config (.gpmrc or package.json "gpm") > project .npmrc >
+// user config > default; flagValue is the --registry value, if any, and
+// projectDir holds the project's files.
+func ResolveRegistry(flagValue, projectDir string) Resolved {
+	cfg := GetConfig()
+	if flagValue != "" {
+		return Resolved{Value: flagValue, Source: SourceFlag, Origin: "--registry"}
+	}
+	if env := os.Getenv(RegistryEnv); env != "" {
+		return Resolved{Value: env, Source: SourceEnv, Origin: RegistryEnv}
+	}
+	if profile := cfg.activeProfile(); profile != nil && profile.Registry != "" {
+		return Resolved{Value: profile.Registry, Source: SourceProfile, Origin: cfg.profile}
+	}
+	if project, path := readProjectConfig(projectDir); project != nil && project.Registry != "" {
+		return Resolved{Value: project.Registry, Source: SourceProject, Origin: path}
+	}
+	if npmrc, path := readProjectNpmrc(projectDir); npmrc["registry"] != "" {
+		return Resolved{Value: npmrc["registry"], Source: SourceNpmrc, Origin: path}
+	}
+	if top := cfg.topLevel(); top.Registry != "" && (top.Registry != DefaultRegistry || viper.InConfig("registry")) {
+		return Resolved{Value: top.Registry, Source: SourceUser, Origin: userConfigFile()}
+	}
+	return Resolved{Value: DefaultRegistry, Source: SourceDefault}
+}
+
+// ResolveToken returns the effective auth token for the effective registry,
+// with the same precedence as ResolveRegistry. A project .npmrc supplies
+// tokens through npm's "//host/path/:_authToken" keys.
+func ResolveToken(flagValue, projectDir string) Resolved {
+	cfg := GetConfig()
+	if flagValue != "" {
+		return Resolved{Value: flagValue, Source: SourceFlag, Origin: "--token"}
+	}
+	if env := os.Getenv(TokenEnv); env != "" {
+		return Resolved{Value: env, Source: SourceEnv, Origin: TokenEnv}
+	}
+	if profile := cfg.activeProfile(); profile != nil && profile.Token != "" {
+		return Resolved{Value: profile.Token, Source: SourceProfile, Origin: cfg.profile}
+	}
+	registry := ResolveRegistry("", projectDir).Value
+	if npmrc, path := readProjectNpmrc(projectDir); npmrc != nil {
+		if token := npmrc[npmrcAuthKey(registry)]; token != "" {
+			return Resolved{Value: token, Source: SourceNpmrc, Origin: path}
+		}
+	}
+	if top := cfg.topLevel(); top.Token != "" {
+		return Resolved{Value: top.Token, Source: SourceUser, Origin: userConfigFile()}
+	}
+	return Resolved{Source: SourceDefault}
+}
+
+// ResolveScopedRegistry returns the effective registry for an "@scope".
+// Precedence is flag > profile > project config > project .npmrc > user
+// config; a scope with no mapping resolves to the effective registry.
+func ResolveScopedRegistry(scope, flagValue, projectDir string) Resolved {
+	cfg := GetConfig()
+	scope = strings.ToLower(scope)
+	if flagValue != "" {
+		return Resolved{Value: flagValue, Source: SourceFlag, Origin: "--registry"}
+	}
+	if profile := cfg.activeProfile(); profile != nil && profile.ScopedRegistries[scope] != "" {
+		return Resolved{Value: profile.ScopedRegistries[scope], Source: SourceProfile, Origin: cfg.profile}
+	}
+	if project, path := readProjectConfig(projectDir); project != nil && project.ScopedRegistries[scope] != "" {
+		return Resolved{Value: project.ScopedRegistries[scope], Source: SourceProject, Origin: path}
+	}
+	if npmrc, path := readProjectNpmrc(projectDir); npmrc[scope+":registry"] != "" {
+		return Resolved{Value: npmrc[scope+":registry"], Source: SourceNpmrc, Origin: path}
+	}
+	if registry := cfg.topLevel().ScopedRegistries[scope]; registry != "" {
+		return Resolved{Value: registry, Source: SourceUser, Origin: userConfigFile()}
+	}
+	return ResolveRegistry("", projectDir)
+}
+
+// activeProfile returns the applied profile, or nil when none is
+func (c *Config) activeProfile() *Profile {
+	if c.profile == "" {
+		return nil
+	}
+	return c.Profiles[c.profile]
+}
+
+func userConfigFile() string {
+	if file := viper.ConfigFileUsed(); file != "" {
+		return file
+	}
+	return "~/.gpmrc"
+}
+
+// readProjectNpmrc parses the .npmrc in projectDir into its key/value pairs,
+// expanding ${VAR} references like npm does. It returns nil when there is no
+// such file.
+func readProjectNpmrc(projectDir string) (map[string]string, string) {
+	if projectDir == "" {
+		return nil, ""
+	}
+	path := filepath.Join(projectDir, ".npmrc")
+	file, err := os.Open(path) // #nosec G304 - fixed file name inside the project directory
+	if err != nil {
+		return nil, ""
+	}
+	defer func() { _ = file.Close() }()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		values[strings.ToLower(strings.TrimSpace(key))] = os.ExpandEnv(value)
+	}
+	return values, path
+}
+
+// npmrcAuthKey returns the .npmrc key holding the token for a registry, e.g.
+// "//registry.gpm.sh/:_authtoken" (lowercased, as readProjectNpmrc stores keys)
+func npmrcAuthKey(registry string) string {
+	parsed, err := url.Parse(registry)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	path := strings.TrimSuffix(parsed.Path, "/") + "/"
+	return strings.ToLower("//" + parsed.Host + path + ":_authToken")
+}
@@ -2,20 +2,62 @@ package engines
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+
+	"gpm.sh/gpm/gpm-cli/internal/validation"
 )
 
 // PackageInstallRequest represents a package installation request
 type PackageInstallRequest struct {
-	Name      string         `json:"name"`
-	Version   string         `json:"version"`
-	Registry  string         `json:"registry,omitempty"`
-	AuthToken string         `json:"auth_token,omitempty"`
-	IsDev     bool           `json:"is_dev,omitempty"`
-	Options   map[string]any `json:"options,omitempty"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Registry  string `json:"registry,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+	IsDev     bool   `json:"is_dev,omitempty"`
+	// SideBySide keeps already installed versions of the package instead of
+	// replacing them. Adapters whose engine cannot hold several versions
+	// reject the request when another version is present.
+	SideBySide bool `json:"side_by_side,omitempty"`
+	// DryRun computes the manifest change without writing anything; the
+	// result's Diff describes what would change
+	DryRun bool `json:"dry_run,omitempty"`
+	// Force moves a scope already mapped to another registry over to this
+	// request's registry instead of failing, and replaces a manifest the
+	// engine couldn't load with a new one
+	Force bool `json:"force,omitempty"`
+	// NoScopedRegistry leaves scopedRegistries untouched and only updates
+	// dependencies; the user is responsible for Unity resolving the package
+	NoScopedRegistry bool `json:"no_scoped_registry,omitempty"`
+	// Normalize rewrites the manifest with the default two-space indent
+	// instead of keeping its existing indentation
+	Normalize bool `json:"normalize,omitempty"`
+	// NoSave installs the package's files without recording it in the
+	// manifest. Adapters whose engine only knows packages through the
+	// manifest reject it.
+	NoSave bool `json:"no_save,omitempty"`
+	// Alias records the package under this dependency key instead of its
+	// name, like npm's "alias@npm:name@version". Adapters whose manifest
+	// keys must be the real package name reject it.
+	Alias string `json:"alias,omitempty"`
+	// ContentsDir holds the package's extracted files for adapters that
+	// install files themselves. It is renamed into place before the
+	// manifest is saved, so it must be on the project's filesystem.
+	ContentsDir string         `json:"-"`
+	Options     map[string]any `json:"options,omitempty"`
+}
+
+// DependencyKey is the manifest key the request installs under: the alias
+// when one is set, otherwise the package name
+func (r *PackageInstallRequest) DependencyKey() string {
+	if r.Alias != "" {
+		return r.Alias
+	}
+	return r.Name
 }
 
 // PackageInstallResult represents the result of a package installation
@@ -27,6 +69,12 @@ type PackageInstallResult struct {
 	InstallPath string         `json:"install_path,omitempty"`
 	Message     string         `json:"message,omitempty"`
 	Details     map[string]any `json:"details,omitempty"`
+	// Diff is how the install changed (or, for dry runs, would change) the
+	// project manifest
+	Diff *ManifestDiff `json:"diff,omitempty"`
+	// Warnings are changes the user should know about, such as a
+	// dependency renamed to fix its case
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // PackageInfo represents installed package information
@@ -44,6 +92,10 @@ type EngineAdapter interface {
 	// GetEngineType returns the engine type this adapter handles
 	GetEngineType() EngineType
 
+	// SupportsMultiVersion reports whether several versions of the same
+	// package can be installed side-by-side
+	SupportsMultiVersion() bool
+
 	// ValidateProject checks if the project is valid for this engine
 	ValidateProject(projectPath string) error
 
@@ -63,18 +115,87 @@ type EngineAdapter interface {
 	ConfigureRegistry(projectPath string, registryURL string, patterns []string) error
 }
 
+const (
+	// DefaultUnityPackagesDir is where Unity keeps manifest.json unless relocated
+	DefaultUnityPackagesDir = "Packages"
+
+	// UnityPackagesDirEnv overrides the Unity packages directory when no
+	// directory is configured on the adapter
+	UnityPackagesDirEnv = "GPM_UNITY_PACKAGES_DIR"
+)
+
 // UnityAdapter implements EngineAdapter for Unity projects
-type UnityAdapter struct{}
+type UnityAdapter struct {
+	packagesDir string
+	stateDir    string
+}
 
 // NewUnityAdapter creates a new Unity adapter
 func NewUnityAdapter() *UnityAdapter {
 	return &UnityAdapter{}
 }
 
+// SetPackagesDir overrides the packages directory for this adapter. Relative
+// paths are resolved against the project root.
+func (u *UnityAdapter) SetPackagesDir(dir string) {
+	u.packagesDir = dir
+}
+
+// SetStateDir sets where this adapter keeps gpm's project state, such as
+// UnityPackagesFile. Empty means StateDirName in the project root.
+func (u *UnityAdapter) SetStateDir(dir string) {
+	u.stateDir = dir
+}
+
+// ResolveUnityPackagesDir returns the absolute packages directory for a Unity
+// project. An empty dir falls back to $GPM_UNITY_PACKAGES_DIR and then to
+// Packages/. The resolved directory must stay within the project.
+func ResolveUnityPackagesDir(projectPath, dir string) (string, error) {
+	if dir == "" {
+		dir = os.Getenv(UnityPackagesDirEnv)
+	}
+	if dir == "" {
+		dir = DefaultUnityPackagesDir
+	}
+
+	projectAbs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve project path: %w", err)
+	}
+
+	packagesDir := dir
+	if !filepath.IsAbs(packagesDir) {
+		packagesDir = filepath.Join(projectAbs, packagesDir)
+	}
+	packagesDir = filepath.Clean(packagesDir)
+
+	rel, err := filepath.Rel(projectAbs, packagesDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("packages directory %s is outside the project %s", dir, projectAbs)
+	}
+
+	return packagesDir, nil
+}
+
+// ManifestPath returns the path of the manifest.json this adapter reads and writes
+func (u *UnityAdapter) ManifestPath(projectPath string) (string, error) {
+	packagesDir, err := ResolveUnityPackagesDir(projectPath, u.packagesDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(packagesDir, "manifest.json"), nil
+}
+
 func (u *UnityAdapter) GetEngineType() EngineType {
 	return EngineUnity
 }
 
+// SupportsMultiVersion returns false: Unity's manifest maps each package
+// name to exactly one version
+func (u *UnityAdapter) SupportsMultiVersion() bool {
+	return false
+}
+
 func (u *UnityAdapter) ValidateProject(projectPath string) error {
 	assetsDir := filepath.Join(projectPath, "Assets")
 	projectSettingsDir := filepath.Join(projectPath, "ProjectSettings")
@@ -95,19 +216,50 @@ func (u *UnityAdapter) InstallPackage(projectPath string, req *PackageInstallReq
 		return nil, fmt.Errorf("project validation failed: %w", err)
 	}
 
-	manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+	if req.NoSave {
+		return nil, fmt.Errorf("unity resolves packages only through its manifest, so %s can't be installed without saving it", req.Name)
+	}
+	if req.Alias != "" && req.Alias != req.Name {
+		return nil, fmt.Errorf("unity requires manifest dependencies to use the package's own name, so %s can't be installed as %s", req.Name, req.Alias)
+	}
 
-	// Ensure Packages directory exists
-	packagesDir := filepath.Dir(manifestPath)
-	if err := os.MkdirAll(packagesDir, 0750); err != nil {
-		return nil, fmt.Errorf("failed to create Packages directory: %w", err)
+	manifestPath, err := u.ManifestPath(projectPath)
+	if err != nil {
+		return nil, err
 	}
 
-	// Load existing manifest or create new one
+	// Hold the manifest for the whole read-modify-write
+	if !req.DryRun {
+		if err := CheckManifestWritable(manifestPath); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(manifestPath), 0750); err != nil {
+			return nil, fmt.Errorf("failed to create Packages directory: %w", err)
+		}
+		unlock, err := lockManifest(manifestPath)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	// Load existing manifest or create new one. One Unity can't load is only
+	// replaced when forced.
+	var warnings []string
 	manifest, err := u.loadManifest(manifestPath)
-	if err != nil {
+	var invalid *InvalidManifestError
+	if errors.As(err, &invalid) && req.Force {
+		manifest = &UnityManifest{Dependencies: make(map[string]string)}
+		warnings = append(warnings, fmt.Sprintf("replaced %s, which Unity can't load, with a new manifest; its previous dependencies were dropped (%v)", filepath.Base(manifestPath), invalid.Err))
+	} else if invalid != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w; fix it or pass --force to replace it", err)
+	} else if err != nil {
 		return nil, fmt.Errorf("failed to load manifest: %w", err)
 	}
+	if req.Normalize {
+		manifest.format = manifestFormat{indent: defaultManifestIndent}
+	}
+	before := manifest.clone()
 
 	// Add package to dependencies
 	if manifest.Dependencies == nil {
@@ -121,23 +273,32 @@ func (u *UnityAdapter) InstallPackage(projectPath string, req *PackageInstallReq
 		versionSpec = "*"
 	}
 
+	if existing := caseCollision(manifest.Dependencies, req.Name); existing != "" {
+		if validation.ValidatePackageName(req.Name) != nil {
+			return nil, fmt.Errorf("%s differs from %s in manifest.json only by case; package names are lowercase, use %s", req.Name, existing, strings.ToLower(req.Name))
+		}
+		// Only the requested spelling is a valid name, so it replaces the
+		// existing entry instead of sitting next to it
+		delete(manifest.Dependencies, existing)
+		warnings = append(warnings, fmt.Sprintf("replaced %s with %s in manifest.json; package names are lowercase", existing, req.Name))
+	}
+
+	if existing, ok := manifest.Dependencies[req.Name]; ok && req.SideBySide && existing != versionSpec {
+		return nil, fmt.Errorf("unity does not support multiple versions of %s side-by-side (%s is already installed)", req.Name, existing)
+	}
+
 	manifest.Dependencies[req.Name] = versionSpec
 
 	// Configure scoped registry if needed
-	if req.Registry != "" && req.Registry != "https://packages.unity.com" {
+	if req.Registry != "" && req.Registry != "https://packages.unity.com" && !req.NoScopedRegistry {
 		// Derive scope from package name (first two labels)
 		scope := DeriveScopeFromPackageName(req.Name)
-		if err := u.configureScopedRegistry(manifest, req.Registry, scope); err != nil {
+		if err := u.configureScopedRegistry(manifest, req.Registry, req.Force, scope); err != nil {
 			return nil, fmt.Errorf("failed to configure scoped registry: %w", err)
 		}
 	}
 
-	// Save manifest
-	if err := u.saveManifest(manifestPath, manifest); err != nil {
-		return nil, fmt.Errorf("failed to save manifest: %w", err)
-	}
-
-	return &PackageInstallResult{
+	result := &PackageInstallResult{
 		Success:     true,
 		PackageName: req.Name,
 		Version:     versionSpec,
@@ -147,11 +308,38 @@ func (u *UnityAdapter) InstallPackage(projectPath string, req *PackageInstallReq
 		Details: map[string]any{
 			"manifest_path": manifestPath,
 		},
-	}, nil
+		Diff:     DiffUnityManifests(before, manifest),
+		Warnings: warnings,
+	}
+	if req.DryRun {
+		result.Message = fmt.Sprintf("Would add %s@%s to Unity manifest", req.Name, versionSpec)
+		return result, nil
+	}
+
+	// Save manifest
+	if err := u.saveManifest(manifestPath, manifest); err != nil {
+		return nil, fmt.Errorf("failed to save manifest: %w", err)
+	}
+
+	return result, nil
 }
 
 func (u *UnityAdapter) RemovePackage(projectPath string, packageName string) error {
-	manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+	manifestPath, err := u.ManifestPath(projectPath)
+	if err != nil {
+		return err
+	}
+
+	if fileExists(manifestPath) {
+		if err := CheckManifestWritable(manifestPath); err != nil {
+			return err
+		}
+		unlock, err := lockManifest(manifestPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
 
 	manifest, err := u.loadManifest(manifestPath)
 	if err != nil {
@@ -172,7 +360,10 @@ func (u *UnityAdapter) RemovePackage(projectPath string, packageName string) err
 }
 
 func (u *UnityAdapter) ListPackages(projectPath string) ([]*PackageInfo, error) {
-	manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+	manifestPath, err := u.ManifestPath(projectPath)
+	if err != nil {
+		return nil, err
+	}
 
 	manifest, err := u.loadManifest(manifestPath)
 	if err != nil {
@@ -195,7 +386,10 @@ func (u *UnityAdapter) ListPackages(projectPath string) ([]*PackageInfo, error)
 }
 
 func (u *UnityAdapter) GetPackageInfo(projectPath string, packageName string) (*PackageInfo, error) {
-	manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+	manifestPath, err := u.ManifestPath(projectPath)
+	if err != nil {
+		return nil, err
+	}
 
 	manifest, err := u.loadManifest(manifestPath)
 	if err != nil {
@@ -219,20 +413,60 @@ func (u *UnityAdapter) GetPackageInfo(projectPath string, packageName string) (*
 }
 
 func (u *UnityAdapter) ConfigureRegistry(projectPath string, registryURL string, patterns []string) error {
-	manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+	manifestPath, err := u.ManifestPath(projectPath)
+	if err != nil {
+		return err
+	}
 
 	manifest, err := u.loadManifest(manifestPath)
 	if err != nil {
 		return fmt.Errorf("failed to load manifest: %w", err)
 	}
 
-	return u.configureScopedRegistry(manifest, registryURL, patterns...)
+	return u.configureScopedRegistry(manifest, registryURL, false, patterns...)
+}
+
+// ScopedRegistries returns the scoped registries declared in the project's
+// manifest
+func (u *UnityAdapter) ScopedRegistries(projectPath string) ([]*ScopedRegistry, error) {
+	manifestPath, err := u.ManifestPath(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := u.loadManifest(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	return manifest.ScopedRegistries, nil
+}
+
+// ScopedRegistryFor returns the scoped registry Unity resolves packageName
+// from: the one with the most specific scope matching it, or nil when the
+// package comes from Unity's own registry
+func ScopedRegistryFor(registries []*ScopedRegistry, packageName string) *ScopedRegistry {
+	var match *ScopedRegistry
+	matched := ""
+	for _, registry := range registries {
+		if registry == nil {
+			continue
+		}
+		for _, scope := range registry.Scopes {
+			if (packageName == scope || strings.HasPrefix(packageName, scope+".")) && len(scope) > len(matched) {
+				match, matched = registry, scope
+			}
+		}
+	}
+	return match
 }
 
 // UnityManifest represents Unity's Packages/manifest.json structure
 type UnityManifest struct {
 	Dependencies     map[string]string `json:"dependencies,omitempty"`
 	ScopedRegistries []*ScopedRegistry `json:"scopedRegistries,omitempty"`
+
+	// format is how the file was laid out when loaded
+	format manifestFormat
 }
 
 // ScopedRegistry represents a Unity scoped registry configuration
@@ -242,6 +476,22 @@ type ScopedRegistry struct {
 	Scopes []string `json:"scopes"`
 }
 
+// clone returns a deep copy of the manifest
+func (m *UnityManifest) clone() *UnityManifest {
+	copied := &UnityManifest{Dependencies: make(map[string]string, len(m.Dependencies))}
+	for name, version := range m.Dependencies {
+		copied.Dependencies[name] = version
+	}
+	for _, registry := range m.ScopedRegistries {
+		copied.ScopedRegistries = append(copied.ScopedRegistries, &ScopedRegistry{
+			Name:   registry.Name,
+			URL:    registry.URL,
+			Scopes: append([]string{}, registry.Scopes...),
+		})
+	}
+	return copied
+}
+
 func (u *UnityAdapter) loadManifest(manifestPath string) (*UnityManifest, error) {
 	if !fileExists(manifestPath) {
 		// Create default manifest
@@ -258,11 +508,15 @@ func (u *UnityAdapter) loadManifest(manifestPath string) (*UnityManifest, error)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkUnityManifest(data); err != nil {
+		return nil, &InvalidManifestError{Path: manifestPath, Err: err}
+	}
 
 	var manifest UnityManifest
 	if err := json.Unmarshal(data, &manifest); err != nil {
-		return nil, err
+		return nil, &InvalidManifestError{Path: manifestPath, Err: err}
 	}
+	manifest.format = detectManifestFormat(data)
 
 	if manifest.Dependencies == nil {
 		manifest.Dependencies = make(map[string]string)
@@ -271,33 +525,53 @@ func (u *UnityAdapter) loadManifest(manifestPath string) (*UnityManifest, error)
 	return &manifest, nil
 }
 
+// saveManifest writes manifest after checking that Unity could load what is
+// about to be written, so a malformed change never replaces a good manifest
 func (u *UnityAdapter) saveManifest(manifestPath string, manifest *UnityManifest) error {
-	data, err := json.MarshalIndent(manifest, "", "  ")
+	data, err := marshalManifest(manifest, manifest.format)
 	if err != nil {
 		return err
 	}
+	if err := checkUnityManifest(data); err != nil {
+		return fmt.Errorf("refusing to write %s: %w", filepath.Base(manifestPath), err)
+	}
 
-	return os.WriteFile(manifestPath, data, 0600)
+	return WriteManifest(manifestPath, data)
 }
 
-func (u *UnityAdapter) configureScopedRegistry(manifest *UnityManifest, registryURL string, patterns ...string) error {
-	if manifest.ScopedRegistries == nil {
-		manifest.ScopedRegistries = []*ScopedRegistry{}
+// configureScopedRegistry maps patterns to registryURL. Unity resolves a scope
+// claimed by two registries unpredictably, so a pattern already mapped to a
+// different registry is an error unless force moves it. Registries that share
+// a URL are merged first.
+func (u *UnityAdapter) configureScopedRegistry(manifest *UnityManifest, registryURL string, force bool, patterns ...string) error {
+	manifest.dedupeScopedRegistries()
+
+	moved := false
+	for _, pattern := range patterns {
+		for _, registry := range manifest.ScopedRegistries {
+			if sameRegistryURL(registry.URL, registryURL) || !slices.Contains(registry.Scopes, pattern) {
+				continue
+			}
+			if !force {
+				return fmt.Errorf("scope %s is already mapped to %s; installing from %s would make Unity resolve it unpredictably (use --force to move the scope)", pattern, registry.URL, registryURL)
+			}
+			registry.Scopes = slices.DeleteFunc(registry.Scopes, func(scope string) bool { return scope == pattern })
+			moved = true
+		}
+	}
+	if moved {
+		// A registry whose last scope moved away serves nothing
+		manifest.ScopedRegistries = slices.DeleteFunc(manifest.ScopedRegistries, func(registry *ScopedRegistry) bool {
+			return len(registry.Scopes) == 0
+		})
 	}
 
 	// Check if registry already exists
 	for _, registry := range manifest.ScopedRegistries {
-		if registry.URL == registryURL {
+		if sameRegistryURL(registry.URL, registryURL) {
 			// Add new patterns to existing registry
 			for _, pattern := range patterns {
-				found := false
-				for _, existingScope := range registry.Scopes {
-					if existingScope == pattern {
-						found = true
-						break
-					}
-				}
-				if !found {
+				if !slices.Contains(registry.Scopes, pattern) {
 					registry.Scopes = append(registry.Scopes, pattern)
 				}
 			}
@@ -321,6 +595,35 @@ func (u *UnityAdapter) configureScopedRegistry(manifest *UnityManifest, registry
 	return nil
 }
 
+// dedupeScopedRegistries merges scoped registries that share a URL into the
+// first of them
+func (m *UnityManifest) dedupeScopedRegistries() {
+	var merged []*ScopedRegistry
+	for _, registry := range m.ScopedRegistries {
+		index := slices.IndexFunc(merged, func(existing *ScopedRegistry) bool {
+			return sameRegistryURL(existing.URL, registry.URL)
+		})
+		if index < 0 {
+			merged = append(merged, registry)
+			continue
+		}
+		for _, scope := range registry.Scopes {
+			if !slices.Contains(merged[index].Scopes, scope) {
+				merged[index].Scopes = append(merged[index].Scopes, scope)
+			}
+		}
+	}
+	if merged == nil {
+		merged = []*ScopedRegistry{}
+	}
+	m.ScopedRegistries = merged
+}
+
+// sameRegistryURL compares registry URLs, ignoring a trailing slash
+func sameRegistryURL(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
 // DeriveScopeFromPackageName extracts the first two labels from a reverse-DNS package name
 // e.g., com.tapnation.analytics → com.tapnation
 func DeriveScopeFromPackageName(packageName string) string {
@@ -330,19 +633,3 @@ func DeriveScopeFromPackageName(packageName string) string {
 	}
 	return packageName
 }
-
-// GetAdapter returns the appropriate engine adapter for the given engine type
-func GetAdapter(engineType EngineType) (EngineAdapter, error) {
-	switch engineType {
-	case EngineUnity:
-		return NewUnityAdapter(), nil
-	case EngineUnreal:
-		return nil, fmt.Errorf("unreal Engine adapter not yet implemented")
-	case EngineGodot:
-		return nil, fmt.Errorf("godot adapter not yet implemented")
-	case EngineCocos:
-		return nil, fmt.Errorf("cocos Creator adapter not yet implemented")
-	default:
-		return nil, fmt.Errorf("unknown engine type: %s", engineType)
-	}
-}
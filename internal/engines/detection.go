@@ -69,7 +69,8 @@ func (dr DetectionResults) HasAmbiguous() bool {
 	return dr[0].Confidence >= ConfidenceHigh && dr[1].Confidence >= ConfidenceHigh
 }
 
-// DetectEngine scans the given directory for game engine projects
+// DetectEngine scans the given directory for game engine projects using the
+// registered detectors
 func DetectEngine(projectPath string) (DetectionResults, error) {
 	if projectPath == "" {
 		var err error
@@ -80,25 +81,10 @@ func DetectEngine(projectPath string) (DetectionResults, error) {
 	}
 
 	var results DetectionResults
-
-	// Check for Unreal Engine (highest confidence)
-	if result := detectUnreal(projectPath); result.Confidence > ConfidenceNone {
-		results = append(results, result)
-	}
-
-	// Check for Cocos Creator (high confidence)
-	if result := detectCocos(projectPath); result.Confidence > ConfidenceNone {
-		results = append(results, result)
-	}
-
-	// Check for Unity (medium-high confidence)
-	if result := detectUnity(projectPath); result.Confidence > ConfidenceNone {
-		results = append(results, result)
-	}
-
-	// Check for Godot (medium confidence)
-	if result := detectGodot(projectPath); result.Confidence > ConfidenceNone {
-		results = append(results, result)
+	for _, registered := range registeredDetectors() {
+		if result := registered.detect(projectPath); result != nil && result.Confidence > ConfidenceNone {
+			results = append(results, result)
+		}
 	}
 
 	return results, nil
@@ -356,6 +342,9 @@ func (e EngineType) String() string {
 	case EngineCocos:
 		return "Cocos Creator"
 	default:
+		if e != "" && HasAdapter(e) {
+			return string(e)
+		}
 		return "Unknown"
 	}
 }
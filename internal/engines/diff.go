@@ -0,0 +1,190 @@
+package engines
+
+import (
+	"slices"
+	"sort"
+)
+
+// ManifestDiff describes how an operation changes a project manifest. Dry runs
+// report it instead of writing the manifest.
+type ManifestDiff struct {
+	Added            []DependencyChange     `json:"added"`
+	Updated          []DependencyChange     `json:"updated"`
+	Removed          []DependencyChange     `json:"removed"`
+	ScopedRegistries []ScopedRegistryChange `json:"scoped_registries"`
+}
+
+// DependencyChange is one dependency entry that changed. From is empty for
+// added dependencies and To is empty for removed ones.
+type DependencyChange struct {
+	Name string `json:"name"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// ScopedRegistryChange is a scoped registry that was added or removed, or an
+// existing one whose scopes changed. Scopes lists only the scopes the change
+// adds and RemovedScopes those it takes away, as when --force moves a scope
+// to another registry.
+type ScopedRegistryChange struct {
+	Name          string   `json:"name"`
+	URL           string   `json:"url"`
+	Action        string   `json:"action"` // "added", "updated" or "removed"
+	Scopes        []string `json:"scopes"`
+	RemovedScopes []string `json:"removed_scopes,omitempty"`
+}
+
+// NewManifestDiff returns an empty diff
+func NewManifestDiff() *ManifestDiff {
+	return &ManifestDiff{
+		Added:            []DependencyChange{},
+		Updated:          []DependencyChange{},
+		Removed:          []DependencyChange{},
+		ScopedRegistries: []ScopedRegistryChange{},
+	}
+}
+
+// IsEmpty reports whether the diff records no changes
+func (d *ManifestDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Updated) == 0 && len(d.Removed) == 0 && len(d.ScopedRegistries) == 0
+}
+
+// DiffDependencies compares two dependency maps
+func DiffDependencies(before, after map[string]string) *ManifestDiff {
+	diff := NewManifestDiff()
+	for name, to := range after {
+		from, existed := before[name]
+		switch {
+		case !existed:
+			diff.Added = append(diff.Added, DependencyChange{Name: name, To: to})
+		case from != to:
+			diff.Updated = append(diff.Updated, DependencyChange{Name: name, From: from, To: to})
+		}
+	}
+	for name, from := range before {
+		if _, exists := after[name]; !exists {
+			diff.Removed = append(diff.Removed, DependencyChange{Name: name, From: from})
+		}
+	}
+	diff.sort()
+	return diff
+}
+
+// DiffUnityManifests compares dependencies and scoped registries of two
+// Unity manifests
+func DiffUnityManifests(before, after *UnityManifest) *ManifestDiff {
+	diff := DiffDependencies(before.Dependencies, after.Dependencies)
+
+	existing := make(map[string]*ScopedRegistry, len(before.ScopedRegistries))
+	for _, registry := range before.ScopedRegistries {
+		existing[registry.URL] = registry
+	}
+	remaining := make(map[string]bool, len(after.ScopedRegistries))
+	for _, registry := range after.ScopedRegistries {
+		remaining[registry.URL] = true
+	}
+	for _, registry := range after.ScopedRegistries {
+		previous, ok := existing[registry.URL]
+		if !ok {
+			diff.ScopedRegistries = append(diff.ScopedRegistries, ScopedRegistryChange{
+				Name:   registry.Name,
+				URL:    registry.URL,
+				Action: "added",
+				Scopes: append([]string{}, registry.Scopes...),
+			})
+			continue
+		}
+
+		added := []string{}
+		for _, scope := range registry.Scopes {
+			if !slices.Contains(previous.Scopes, scope) {
+				added = append(added, scope)
+			}
+		}
+		var removed []string
+		for _, scope := range previous.Scopes {
+			if !slices.Contains(registry.Scopes, scope) {
+				removed = append(removed, scope)
+			}
+		}
+		if len(added) > 0 || len(removed) > 0 {
+			diff.ScopedRegistries = append(diff.ScopedRegistries, ScopedRegistryChange{
+				Name:          registry.Name,
+				URL:           registry.URL,
+				Action:        "updated",
+				Scopes:        added,
+				RemovedScopes: removed,
+			})
+		}
+	}
+	for _, registry := range before.ScopedRegistries {
+		if !remaining[registry.URL] {
+			diff.ScopedRegistries = append(diff.ScopedRegistries, ScopedRegistryChange{
+				Name:          registry.Name,
+				URL:           registry.URL,
+				Action:        "removed",
+				Scopes:        []string{},
+				RemovedScopes: append([]string{}, registry.Scopes...),
+			})
+		}
+	}
+	return diff
+}
+
+// Merge folds another diff into d, as when several packages are installed in
+// one command. Later changes to the same dependency win.
+func (d *ManifestDiff) Merge(other *ManifestDiff) {
+	d.Added = mergeChanges(d.Added, other.Added)
+	d.Updated = mergeChanges(d.Updated, other.Updated)
+	d.Removed = mergeChanges(d.Removed, other.Removed)
+
+	for _, change := range other.ScopedRegistries {
+		merged := false
+		for i := range d.ScopedRegistries {
+			if d.ScopedRegistries[i].URL == change.URL {
+				for _, scope := range change.Scopes {
+					if !slices.Contains(d.ScopedRegistries[i].Scopes, scope) {
+						d.ScopedRegistries[i].Scopes = append(d.ScopedRegistries[i].Scopes, scope)
+					}
+				}
+				for _, scope := range change.RemovedScopes {
+					if !slices.Contains(d.ScopedRegistries[i].RemovedScopes, scope) {
+						d.ScopedRegistries[i].RemovedScopes = append(d.ScopedRegistries[i].RemovedScopes, scope)
+					}
+				}
+				if change.Action == "removed" {
+					d.ScopedRegistries[i].Action = "removed"
+				}
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			d.ScopedRegistries = append(d.ScopedRegistries, change)
+		}
+	}
+	d.sort()
+}
+
+func mergeChanges(existing, incoming []DependencyChange) []DependencyChange {
+	for _, change := range incoming {
+		replaced := false
+		for i := range existing {
+			if existing[i].Name == change.Name {
+				existing[i] = change
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			existing = append(existing, change)
+		}
+	}
+	return existing
+}
+
+func (d *ManifestDiff) sort() {
+	for _, changes := range [][]DependencyChange{d.Added, d.Updated, d.Removed} {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	}
+}
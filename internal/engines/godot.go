@@ -0,0 +1,402 @@
+package engines
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// GodotAddonsDir is where Godot projects keep installed addons
+	GodotAddonsDir = "addons"
+
+	// GodotManifestFile records the packages GPM installed into a Godot
+	// project, relative to the project root
+	GodotManifestFile = "gpm-addons.json"
+)
+
+// GodotAdapter implements EngineAdapter for Godot projects. Godot has no
+// package manager of its own, so installed versions are tracked in
+// gpm-addons.json and each version gets its own directory under addons/.
+type GodotAdapter struct{}
+
+// NewGodotAdapter creates a new Godot adapter
+func NewGodotAdapter() *GodotAdapter {
+	return &GodotAdapter{}
+}
+
+// GodotManifest represents the gpm-addons.json structure
+type GodotManifest struct {
+	Dependencies map[string][]string `json:"dependencies"`
+	// Aliases maps dependency keys installed under another name to the
+	// registry package they came from
+	Aliases map[string]string `json:"aliases,omitempty"`
+
+	// format is how the file was laid out when loaded
+	format manifestFormat
+}
+
+func (g *GodotAdapter) GetEngineType() EngineType {
+	return EngineGodot
+}
+
+// SupportsMultiVersion returns true: addons are plain directories, so
+// versions can live next to each other in versioned directories
+func (g *GodotAdapter) SupportsMultiVersion() bool {
+	return true
+}
+
+func (g *GodotAdapter) ValidateProject(projectPath string) error {
+	projectFile := filepath.Join(projectPath, "project.godot")
+	if !fileExists(projectFile) {
+		return fmt.Errorf("godot project.godot not found at %s", projectFile)
+	}
+	return nil
+}
+
+// AddonPath returns the directory a package version is installed into. The
+// first installed version uses addons/<name>; side-by-side versions use
+// addons/<name>@<version>.
+func (g *GodotAdapter) AddonPath(projectPath, packageName, version string, sideBySide bool) string {
+	dir := packageName
+	if sideBySide {
+		dir = packageName + "@" + version
+	}
+	return filepath.Join(projectPath, GodotAddonsDir, dir)
+}
+
+func (g *GodotAdapter) InstallPackage(projectPath string, req *PackageInstallRequest) (*PackageInstallResult, error) {
+	if err := g.ValidateProject(projectPath); err != nil {
+		return nil, fmt.Errorf("project validation failed: %w", err)
+	}
+
+	if req.Version == "" || req.Version == "latest" || req.Version == "*" {
+		return nil, fmt.Errorf("godot packages require an exact version, got %q", req.Version)
+	}
+
+	manifestPath := filepath.Join(projectPath, GodotManifestFile)
+	if !req.DryRun {
+		if err := CheckManifestWritable(manifestPath); err != nil {
+			return nil, err
+		}
+		unlock, err := lockManifest(manifestPath)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	manifest, err := g.loadManifest(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	if req.Normalize {
+		manifest.format = manifestFormat{indent: defaultManifestIndent}
+	}
+
+	// An aliased package is recorded, and installed, under its alias
+	key := req.DependencyKey()
+	if existing := caseCollision(manifest.Dependencies, key); existing != "" {
+		// On case-insensitive file systems both spellings share one addon
+		// directory, so neither can safely replace the other
+		return nil, fmt.Errorf("%s is already installed as %s, which differs only by case; uninstall %s first", key, existing, existing)
+	}
+	before := manifest.versions()
+	installed := manifest.Dependencies[key]
+	if current := manifest.packageName(key); req.SideBySide && len(installed) > 0 && current != req.Name {
+		return nil, fmt.Errorf("%s holds %s, so %s can't be installed next to it; use another alias", key, current, req.Name)
+	}
+	if req.NoSave && len(installed) > 0 {
+		// Swapping the addon under a recorded version would leave the
+		// manifest describing files that are gone
+		return nil, fmt.Errorf("package %s is already in %s; install it without --no-save to change its version", key, GodotManifestFile)
+	}
+	sideBySide := false
+	if req.SideBySide && len(installed) > 0 {
+		for _, version := range installed {
+			if version == req.Version {
+				return nil, fmt.Errorf("package %s@%s is already installed", key, req.Version)
+			}
+		}
+		sideBySide = true
+		manifest.Dependencies[key] = append(append([]string{}, installed...), req.Version)
+	} else {
+		manifest.Dependencies[key] = []string{req.Version}
+	}
+	manifest.setAlias(key, req.Name)
+
+	addonPath := g.AddonPath(projectPath, key, req.Version, sideBySide)
+	result := &PackageInstallResult{
+		Success:     true,
+		PackageName: req.Name,
+		Version:     req.Version,
+		Registry:    req.Registry,
+		InstallPath: addonPath,
+		Message:     fmt.Sprintf("Added %s@%s to %s", req.Name, req.Version, filepath.Join(GodotAddonsDir, filepath.Base(addonPath))),
+		Details: map[string]any{
+			"manifest_path": manifestPath,
+			"side_by_side":  sideBySide,
+		},
+		Diff: DiffDependencies(before, manifest.versions()),
+	}
+	if key != req.Name {
+		result.Details["alias"] = key
+	}
+	if req.NoSave {
+		result.Message = fmt.Sprintf("Installed %s@%s to %s without saving it to %s", req.Name, req.Version, filepath.Join(GodotAddonsDir, filepath.Base(addonPath)), GodotManifestFile)
+		result.Diff = NewManifestDiff()
+	}
+	if req.DryRun {
+		result.Message = fmt.Sprintf("Would add %s@%s to %s", req.Name, req.Version, filepath.Join(GodotAddonsDir, filepath.Base(addonPath)))
+		return result, nil
+	}
+
+	// The requested version replaces every installed one. They're moved
+	// aside rather than removed, so a failure below can put them back.
+	replaced := []string{addonPath}
+	if !sideBySide {
+		for i, version := range installed {
+			replaced = append(replaced, g.AddonPath(projectPath, key, version, i > 0))
+		}
+	}
+	aside, err := moveAside(filepath.Join(projectPath, GodotAddonsDir), replaced)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.placeAddon(addonPath, req.ContentsDir); err != nil {
+		aside.restore(addonPath)
+		return nil, err
+	}
+	if !req.NoSave {
+		if err := g.saveManifest(manifestPath, manifest); err != nil {
+			aside.restore(addonPath)
+			return nil, fmt.Errorf("failed to save manifest: %w", err)
+		}
+	}
+	aside.discard()
+
+	return result, nil
+}
+
+// placeAddon moves the package's staged files to addonPath, or creates an
+// empty addon directory when the caller places the files itself
+func (g *GodotAdapter) placeAddon(addonPath, contentsDir string) error {
+	if contentsDir == "" {
+		if err := os.MkdirAll(addonPath, 0750); err != nil {
+			return fmt.Errorf("failed to create addon directory: %w", err)
+		}
+		return nil
+	}
+	if err := os.Rename(contentsDir, addonPath); err != nil {
+		return fmt.Errorf("failed to move package files into %s: %w", addonPath, err)
+	}
+	return nil
+}
+
+// movedDirs records directories moved into a hidden directory while their
+// replacement is put in place
+type movedDirs struct {
+	dir   string
+	paths map[string]string
+}
+
+// moveAside moves each existing directory in paths into a hidden directory
+// under parent, putting back the ones already moved if one can't be
+func moveAside(parent string, paths []string) (*movedDirs, error) {
+	if err := os.MkdirAll(parent, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", parent, err)
+	}
+	dir, err := os.MkdirTemp(parent, ".gpm-replaced-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", parent, err)
+	}
+	moved := &movedDirs{dir: dir, paths: make(map[string]string)}
+	for _, path := range paths {
+		if _, ok := moved.paths[path]; ok || !dirExists(path) {
+			continue
+		}
+		target := filepath.Join(dir, fmt.Sprint(len(moved.paths)))
+		if err := os.Rename(path, target); err != nil {
+			moved.restore("")
+			return nil, fmt.Errorf("failed to move aside %s: %w", path, err)
+		}
+		moved.paths[path] = target
+	}
+	return moved, nil
+}
+
+// restore removes the partly installed directory, when there is one, and
+// moves every directory back where it was
+func (m *movedDirs) restore(installed string) {
+	if installed != "" {
+		_ = os.RemoveAll(installed)
+	}
+	for path, target := range m.paths {
+		_ = os.Rename(target, path)
+	}
+	_ = os.RemoveAll(m.dir)
+}
+
+// discard deletes the moved directories once their replacement is in place
+func (m *movedDirs) discard() {
+	_ = os.RemoveAll(m.dir)
+}
+
+func (g *GodotAdapter) RemovePackage(projectPath string, packageName string) error {
+	manifestPath := filepath.Join(projectPath, GodotManifestFile)
+	if err := CheckManifestWritable(manifestPath); err != nil {
+		return err
+	}
+	unlock, err := lockManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	manifest, err := g.loadManifest(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+
+	installed, exists := manifest.Dependencies[packageName]
+	if !exists {
+		return fmt.Errorf("package %s is not installed", packageName)
+	}
+
+	for i, version := range installed {
+		if err := os.RemoveAll(g.AddonPath(projectPath, packageName, version, i > 0)); err != nil {
+			return fmt.Errorf("failed to remove %s@%s: %w", packageName, version, err)
+		}
+	}
+	delete(manifest.Dependencies, packageName)
+	manifest.setAlias(packageName, packageName)
+
+	return g.saveManifest(manifestPath, manifest)
+}
+
+func (g *GodotAdapter) ListPackages(projectPath string) ([]*PackageInfo, error) {
+	manifest, err := g.loadManifest(filepath.Join(projectPath, GodotManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+
+	names := make([]string, 0, len(manifest.Dependencies))
+	for name := range manifest.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var packages []*PackageInfo
+	for _, name := range names {
+		for i, version := range manifest.Dependencies[name] {
+			packages = append(packages, &PackageInfo{
+				Name:        name,
+				Version:     version,
+				InstallPath: g.AddonPath(projectPath, name, version, i > 0),
+			})
+		}
+	}
+
+	return packages, nil
+}
+
+// GetPackageInfo returns the primary (first installed) version of a package
+func (g *GodotAdapter) GetPackageInfo(projectPath string, packageName string) (*PackageInfo, error) {
+	manifest, err := g.loadManifest(filepath.Join(projectPath, GodotManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+
+	installed := manifest.Dependencies[packageName]
+	if len(installed) == 0 {
+		return nil, fmt.Errorf("package %s is not installed", packageName)
+	}
+
+	return &PackageInfo{
+		Name:        packageName,
+		Version:     installed[0],
+		InstallPath: g.AddonPath(projectPath, packageName, installed[0], false),
+	}, nil
+}
+
+// ConfigureRegistry is a no-op: Godot has no registry configuration and
+// packages are fetched by GPM directly
+func (g *GodotAdapter) ConfigureRegistry(projectPath string, registryURL string, patterns []string) error {
+	return nil
+}
+
+// packageName returns the registry package installed under a dependency key
+func (m *GodotManifest) packageName(key string) string {
+	if name, ok := m.Aliases[key]; ok {
+		return name
+	}
+	return key
+}
+
+// setAlias records that key holds packageName, dropping the alias entry when
+// the key is the package's own name
+func (m *GodotManifest) setAlias(key, packageName string) {
+	if key == packageName {
+		delete(m.Aliases, key)
+		if len(m.Aliases) == 0 {
+			m.Aliases = nil
+		}
+		return
+	}
+	if m.Aliases == nil {
+		m.Aliases = make(map[string]string)
+	}
+	m.Aliases[key] = packageName
+}
+
+// versions flattens the manifest to one comma-separated version list per
+// package, for diffing
+func (m *GodotManifest) versions() map[string]string {
+	flat := make(map[string]string, len(m.Dependencies))
+	for name, versions := range m.Dependencies {
+		flat[name] = strings.Join(versions, ", ")
+	}
+	return flat
+}
+
+func (g *GodotAdapter) loadManifest(manifestPath string) (*GodotManifest, error) {
+	if !fileExists(manifestPath) {
+		return &GodotManifest{
+			Dependencies: make(map[string][]string),
+		}, nil
+	}
+
+	// Validate path to prevent directory traversal
+	if !strings.HasPrefix(filepath.Clean(manifestPath), filepath.Dir(manifestPath)) {
+		return nil, fmt.Errorf("invalid manifest path")
+	}
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest GodotManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	manifest.format = detectManifestFormat(data)
+
+	if manifest.Dependencies == nil {
+		manifest.Dependencies = make(map[string][]string)
+	}
+
+	return &manifest, nil
+}
+
+func (g *GodotAdapter) saveManifest(manifestPath string, manifest *GodotManifest) error {
+	data, err := marshalManifest(manifest, manifest.format)
+	if err != nil {
+		return err
+	}
+
+	return WriteManifest(manifestPath, data)
+}
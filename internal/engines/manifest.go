@@ -0,0 +1,221 @@
+package engines
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultManifestIndent is used for new manifests and under --normalize
+	defaultManifestIndent = "  "
+
+	// manifestLockTimeout bounds how long a command waits for another gpm
+	// process to finish editing the same manifest
+	manifestLockTimeout = 10 * time.Second
+
+	// manifestLockStale is the age after which a lock file is assumed to be
+	// left behind by a crashed process
+	manifestLockStale = time.Minute
+
+	// manifestWriteAttempts and manifestWriteBackoff bound the retries when
+	// another program (Unity or an IDE on Windows) briefly holds the manifest
+	// open; the backoff doubles after each failed attempt
+	manifestWriteAttempts = 5
+	manifestWriteBackoff  = 50 * time.Millisecond
+)
+
+// caseCollision returns the dependency key that differs from name only by
+// case. Registries may match names case-insensitively, so both spellings
+// would install the same package twice.
+func caseCollision[V any](dependencies map[string]V, name string) string {
+	for key := range dependencies {
+		if key != name && strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return ""
+}
+
+// writeFile is replaced in tests to simulate a manifest held by another program
+var writeFile = os.WriteFile
+
+// manifestFormat is the whitespace style of a manifest on disk, kept so that
+// saving it doesn't churn diffs for users who indent differently
+type manifestFormat struct {
+	indent          string
+	trailingNewline bool
+}
+
+// detectManifestFormat reads the indentation of the first indented line and
+// whether the file ends in a newline
+func detectManifestFormat(data []byte) manifestFormat {
+	format := manifestFormat{
+		indent:          defaultManifestIndent,
+		trailingNewline: bytes.HasSuffix(data, []byte("\n")),
+	}
+	for _, line := range bytes.Split(data, []byte("\n"))[1:] {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		trimmed := bytes.TrimLeft(line, " \t")
+		if len(trimmed) > 0 && len(trimmed) < len(line) {
+			format.indent = string(line[:len(line)-len(trimmed)])
+			break
+		}
+	}
+	return format
+}
+
+// marshalManifest encodes a manifest in the given format
+func marshalManifest(v any, format manifestFormat) ([]byte, error) {
+	if format.indent == "" {
+		format.indent = defaultManifestIndent
+	}
+	data, err := json.MarshalIndent(v, "", format.indent)
+	if err != nil {
+		return nil, err
+	}
+	if format.trailingNewline {
+		data = append(data, '\n')
+	}
+	return data, nil
+}
+
+// ReadOnlyHint suggests how to fix a ReadOnlyError
+const ReadOnlyHint = "Check the folder's permissions, that the project isn't on a read-only mount, and that files under version control such as Perforce are checked out"
+
+// ReadOnlyError reports that a project can't be changed because its manifest
+// or Packages directory isn't writable
+type ReadOnlyError struct {
+	Path string
+	Err  error
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("cannot write to %s: project appears read-only or you lack permissions", e.Path)
+}
+
+func (e *ReadOnlyError) Unwrap() error {
+	return e.Err
+}
+
+// CheckManifestWritable makes sure a manifest can be changed before anything
+// is touched: its directory must accept new files, for the lock and backup,
+// and the manifest itself, if present, must open for writing. A directory
+// that doesn't exist yet is checked through its nearest existing parent.
+func CheckManifestWritable(manifestPath string) error {
+	dir := filepath.Dir(manifestPath)
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	probe, err := os.CreateTemp(dir, ".gpm-write-check-*")
+	if err != nil {
+		if isReadOnlyError(err) {
+			return &ReadOnlyError{Path: dir, Err: err}
+		}
+		return fmt.Errorf("failed to check %s is writable: %w", dir, err)
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
+
+	file, err := os.OpenFile(manifestPath, os.O_WRONLY, 0) // #nosec G304 - manifest resolved by the adapter
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		if isReadOnlyError(err) {
+			return &ReadOnlyError{Path: manifestPath, Err: err}
+		}
+		return nil
+	}
+	return file.Close()
+}
+
+// lockManifest takes an advisory lock on a manifest by exclusively creating
+// "<manifest>.lock" next to it, so concurrent gpm processes (an editor plugin
+// firing several adds, say) don't overwrite each other's read-modify-write.
+// The returned func releases the lock.
+func lockManifest(manifestPath string) (func(), error) {
+	lockPath := manifestPath + ".lock"
+	deadline := time.Now().Add(manifestLockTimeout)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600) // #nosec G304 - lock file next to the resolved manifest
+		if err == nil {
+			_, _ = fmt.Fprintf(file, "%d\n", os.Getpid())
+			_ = file.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock manifest: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > manifestLockStale {
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s; remove it if no other gpm command is running", lockPath)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// WriteManifest writes a manifest, retrying with backoff when the write fails
+// because another program holds the file. The current contents are copied to
+// "<manifest>.bak" first and put back if every attempt fails, so a write cut
+// short never leaves a truncated manifest behind.
+func WriteManifest(manifestPath string, data []byte) error {
+	backupPath := manifestPath + ".bak"
+	original, err := os.ReadFile(manifestPath) // #nosec G304 - manifest resolved by the adapter
+	hasOriginal := err == nil
+	if hasOriginal {
+		if err := os.WriteFile(backupPath, original, 0600); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", filepath.Base(manifestPath), err)
+		}
+	}
+
+	delay := manifestWriteBackoff
+	for attempt := 1; ; attempt++ {
+		err = writeFile(manifestPath, data, 0600)
+		if err == nil {
+			if hasOriginal {
+				_ = os.Remove(backupPath)
+			}
+			return nil
+		}
+		if attempt == manifestWriteAttempts {
+			break
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	if hasOriginal {
+		if restoreErr := os.WriteFile(manifestPath, original, 0600); restoreErr != nil {
+			return fmt.Errorf("%w; the previous manifest is saved at %s", manifestWriteError(manifestPath, err), backupPath)
+		}
+		_ = os.Remove(backupPath)
+	}
+	return manifestWriteError(manifestPath, err)
+}
+
+// manifestWriteError describes a manifest write that failed on every attempt
+func manifestWriteError(manifestPath string, err error) error {
+	name := filepath.Base(manifestPath)
+	if isFileLockedError(err) {
+		return fmt.Errorf("%s is locked by another program, is Unity or an editor open? Close it and try again: %w", name, err)
+	}
+	return fmt.Errorf("failed to write %s after %d attempts: %w", name, manifestWriteAttempts, err)
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package engines
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// isFileLockedError reports whether a write failed because another program
+// holds the file. Only Windows locks files this way; elsewhere it covers
+// mandatory locks, which are rare.
+func isFileLockedError(err error) bool {
+	return errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.ETXTBSY)
+}
+
+// isReadOnlyError reports whether a write failed because the file or its
+// directory isn't writable by the user, or sits on a read-only file system
+func isReadOnlyError(err error) bool {
+	return errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.EROFS)
+}
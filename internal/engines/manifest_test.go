@@ -0,0 +1,150 @@
+package engines
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingWrites makes the first failures manifest writes fail after
+// truncating the file, like a write interrupted by another program
+func failingWrites(t *testing.T, failures int) *int {
+	t.Helper()
+	attempts := 0
+	writeFile = func(name string, data []byte, perm os.FileMode) error {
+		attempts++
+		if attempts <= failures {
+			_ = os.Truncate(name, 0)
+			return errors.New("the process cannot access the file because it is being used by another process")
+		}
+		return os.WriteFile(name, data, perm)
+	}
+	t.Cleanup(func() { writeFile = os.WriteFile })
+	return &attempts
+}
+
+func TestWriteManifestRetriesTransientFailures(t *testing.T) {
+	original := []byte("{\n  \"dependencies\": {}\n}\n")
+	updated := []byte("{\n  \"dependencies\": {\n    \"com.x.pkg\": \"1.0.0\"\n  }\n}\n")
+
+	t.Run("succeeds on retry", func(t *testing.T) {
+		manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+		require.NoError(t, os.WriteFile(manifestPath, original, 0600))
+		attempts := failingWrites(t, 2)
+
+		require.NoError(t, WriteManifest(manifestPath, updated))
+		assert.Equal(t, 3, *attempts)
+		data, err := os.ReadFile(manifestPath)
+		require.NoError(t, err)
+		assert.Equal(t, string(updated), string(data))
+		assert.NoFileExists(t, manifestPath+".bak")
+	})
+
+	t.Run("restores the manifest when every attempt fails", func(t *testing.T) {
+		manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+		require.NoError(t, os.WriteFile(manifestPath, original, 0600))
+		attempts := failingWrites(t, manifestWriteAttempts)
+
+		err := WriteManifest(manifestPath, updated)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "manifest.json")
+		assert.Equal(t, manifestWriteAttempts, *attempts)
+		data, err := os.ReadFile(manifestPath)
+		require.NoError(t, err)
+		assert.Equal(t, string(original), string(data), "a failed write must not leave a truncated manifest")
+		assert.NoFileExists(t, manifestPath+".bak")
+	})
+}
+
+func TestInstallPackageReadOnlyProject(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("directory permissions don't stop root, and Windows ignores them")
+	}
+	projectPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projectPath, "Assets"), 0750))
+	require.NoError(t, os.MkdirAll(filepath.Join(projectPath, "ProjectSettings"), 0750))
+	packagesDir := filepath.Join(projectPath, "Packages")
+	require.NoError(t, os.MkdirAll(packagesDir, 0750))
+	original := []byte("{\n  \"dependencies\": {}\n}\n")
+	require.NoError(t, os.WriteFile(filepath.Join(packagesDir, "manifest.json"), original, 0400))
+	require.NoError(t, os.Chmod(packagesDir, 0500))
+	t.Cleanup(func() { _ = os.Chmod(packagesDir, 0750) })
+
+	_, err := NewUnityAdapter().InstallPackage(projectPath, &PackageInstallRequest{Name: "com.x.pkg", Version: "1.0.0"})
+	var readOnly *ReadOnlyError
+	require.ErrorAs(t, err, &readOnly)
+	assert.Equal(t, packagesDir, readOnly.Path)
+	assert.Contains(t, err.Error(), "project appears read-only or you lack permissions")
+
+	data, err := os.ReadFile(filepath.Join(packagesDir, "manifest.json"))
+	require.NoError(t, err)
+	assert.Equal(t, string(original), string(data))
+}
+
+func TestInstallPackageInvalidManifest(t *testing.T) {
+	setup := func(t *testing.T, manifest string) (string, string) {
+		projectPath := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(projectPath, "Assets"), 0750))
+		require.NoError(t, os.MkdirAll(filepath.Join(projectPath, "ProjectSettings"), 0750))
+		manifestPath := filepath.Join(projectPath, "Packages", "manifest.json")
+		require.NoError(t, os.MkdirAll(filepath.Dir(manifestPath), 0750))
+		require.NoError(t, os.WriteFile(manifestPath, []byte(manifest), 0600))
+		return projectPath, manifestPath
+	}
+
+	corrupt := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{"truncated", `{"dependencies": {"com.x.other": "1.0.0"`, "not a JSON object"},
+		{"dependencies not an object", `{"dependencies": ["com.x.other"]}`, "dependencies must be an object"},
+		{"scoped registry without scopes", `{"dependencies": {}, "scopedRegistries": [{"name": "X", "url": "https://x.example"}]}`, `scoped registry "X" has no scopes`},
+	}
+	for _, tt := range corrupt {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath, manifestPath := setup(t, tt.manifest)
+			req := &PackageInstallRequest{Name: "com.x.pkg", Version: "1.0.0"}
+
+			_, err := NewUnityAdapter().InstallPackage(projectPath, req)
+			var invalid *InvalidManifestError
+			require.ErrorAs(t, err, &invalid)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Contains(t, err.Error(), "--force")
+			data, err := os.ReadFile(manifestPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.manifest, string(data), "a manifest that didn't load must not be overwritten")
+
+			req.Force = true
+			result, err := NewUnityAdapter().InstallPackage(projectPath, req)
+			require.NoError(t, err)
+			require.Len(t, result.Warnings, 1)
+			assert.Contains(t, result.Warnings[0], "replaced manifest.json")
+			data, err = os.ReadFile(manifestPath)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"dependencies": {"com.x.pkg": "1.0.0"}}`, string(data))
+		})
+	}
+
+	t.Run("malformed change is not written", func(t *testing.T) {
+		original := "{\n  \"dependencies\": {}\n}\n"
+		_, manifestPath := setup(t, original)
+		manifest := &UnityManifest{
+			Dependencies:     map[string]string{"com.x.pkg": "1.0.0"},
+			ScopedRegistries: []*ScopedRegistry{{Name: "X", URL: "x.example", Scopes: []string{"com.x"}}},
+		}
+
+		err := NewUnityAdapter().saveManifest(manifestPath, manifest)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refusing to write manifest.json")
+		assert.Contains(t, err.Error(), `invalid url "x.example"`)
+		data, err := os.ReadFile(manifestPath)
+		require.NoError(t, err)
+		assert.Equal(t, original, string(data))
+	})
+}
@@ -0,0 +1,36 @@
+package engines
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Windows error codes for a file opened by another process without sharing
+const (
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+)
+
+// errorWriteProtect is returned for write-protected media
+const errorWriteProtect syscall.Errno = 19
+
+// isFileLockedError reports whether a write failed because another program,
+// typically Unity or an IDE, holds the file open
+func isFileLockedError(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	return errno == errorSharingViolation || errno == errorLockViolation || errno == syscall.ERROR_ACCESS_DENIED
+}
+
+// isReadOnlyError reports whether a write failed because the file is marked
+// read-only, the user lacks permission, or the media is write-protected
+func isReadOnlyError(err error) bool {
+	var errno syscall.Errno
+	if errors.As(err, &errno) && errno == errorWriteProtect {
+		return true
+	}
+	return errors.Is(err, os.ErrPermission)
+}
@@ -0,0 +1,93 @@
+package engines
+
+import (
+	"fmt"
+	"sync"
+)
+
+// AdapterFactory creates an adapter for one engine type
+type AdapterFactory func() EngineAdapter
+
+// Detector inspects a project directory and reports how confident it is that
+// the project uses its engine. A result with ConfidenceNone means no match.
+type Detector func(projectPath string) *DetectionResult
+
+type registeredDetector struct {
+	engine EngineType
+	detect Detector
+}
+
+var (
+	registryMu       sync.RWMutex
+	adapterFactories = make(map[EngineType]AdapterFactory)
+	detectors        []registeredDetector
+)
+
+func init() {
+	// Detectors run in registration order; Unreal and Cocos have no adapter
+	// yet but are still detected so users get a clear error
+	RegisterDetector(EngineUnreal, detectUnreal)
+	RegisterDetector(EngineCocos, detectCocos)
+	RegisterDetector(EngineUnity, detectUnity)
+	RegisterDetector(EngineGodot, detectGodot)
+
+	RegisterAdapter(EngineUnity, func() EngineAdapter { return NewUnityAdapter() })
+	RegisterAdapter(EngineGodot, func() EngineAdapter { return NewGodotAdapter() })
+}
+
+// RegisterAdapter makes an adapter available through GetAdapter, replacing
+// any adapter already registered for the engine type. Engines outside this
+// package (GameMaker, Defold, in-house engines) register themselves from an
+// init function.
+func RegisterAdapter(engineType EngineType, factory AdapterFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	adapterFactories[engineType] = factory
+}
+
+// RegisterDetector adds a detector that DetectEngine runs, replacing any
+// detector already registered for the engine type
+func RegisterDetector(engineType EngineType, detector Detector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, registered := range detectors {
+		if registered.engine == engineType {
+			detectors[i].detect = detector
+			return
+		}
+	}
+	detectors = append(detectors, registeredDetector{engine: engineType, detect: detector})
+}
+
+// HasAdapter reports whether an adapter is registered for the engine type
+func HasAdapter(engineType EngineType) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	_, ok := adapterFactories[engineType]
+	return ok
+}
+
+// GetAdapter returns the appropriate engine adapter for the given engine type
+func GetAdapter(engineType EngineType) (EngineAdapter, error) {
+	registryMu.RLock()
+	factory, ok := adapterFactories[engineType]
+	registryMu.RUnlock()
+	if ok {
+		return factory(), nil
+	}
+
+	switch engineType {
+	case EngineUnreal:
+		return nil, fmt.Errorf("unreal Engine adapter not yet implemented")
+	case EngineCocos:
+		return nil, fmt.Errorf("cocos Creator adapter not yet implemented")
+	default:
+		return nil, fmt.Errorf("unknown engine type: %s", engineType)
+	}
+}
+
+func registeredDetectors() []registeredDetector {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]registeredDetector(nil), detectors...)
+}
@@ -0,0 +1,65 @@
+package engines
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const engineDefold EngineType = "defold"
+
+type fakeDefoldAdapter struct {
+	EngineAdapter
+}
+
+func (fakeDefoldAdapter) GetEngineType() EngineType { return engineDefold }
+
+func TestRegisteredEngineIsDetectedAndAdapted(t *testing.T) {
+	RegisterAdapter(engineDefold, func() EngineAdapter { return fakeDefoldAdapter{} })
+	RegisterDetector(engineDefold, func(projectPath string) *DetectionResult {
+		result := &DetectionResult{Engine: engineDefold, ProjectPath: projectPath}
+		if fileExists(filepath.Join(projectPath, "game.project")) {
+			result.Confidence = ConfidenceHigh
+		}
+		return result
+	})
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		delete(adapterFactories, engineDefold)
+		detectors = detectors[:len(detectors)-1]
+	})
+
+	projectDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "game.project"), []byte("[project]\n"), 0600))
+
+	results, err := DetectEngine(projectDir)
+	require.NoError(t, err)
+	best := results.Best()
+	assert.Equal(t, engineDefold, best.Engine)
+	assert.Equal(t, "defold", best.Engine.String())
+
+	adapter, err := GetAdapter(engineDefold)
+	require.NoError(t, err)
+	assert.Equal(t, engineDefold, adapter.GetEngineType())
+	assert.True(t, HasAdapter(engineDefold))
+}
+
+func TestBuiltinAdaptersRegistered(t *testing.T) {
+	adapter, err := GetAdapter(EngineUnity)
+	require.NoError(t, err)
+	assert.Equal(t, EngineUnity, adapter.GetEngineType())
+
+	adapter, err = GetAdapter(EngineGodot)
+	require.NoError(t, err)
+	assert.Equal(t, EngineGodot, adapter.GetEngineType())
+
+	_, err = GetAdapter(EngineUnreal)
+	assert.ErrorContains(t, err, "not yet implemented")
+	_, err = GetAdapter("gamemaker")
+	assert.ErrorContains(t, err, "unknown engine type")
+	assert.Equal(t, "Unknown", EngineType("gamemaker").String())
+}
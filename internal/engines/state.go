@@ -0,0 +1,38 @@
+package engines
+
+import (
+	"path/filepath"
+)
+
+// StateDirName is the per-project directory gpm keeps its own state in, such
+// as backups and UnityPackagesFile. It sits at the project root, outside
+// anything the engine imports.
+const StateDirName = ".gpm"
+
+// FindProjectRoot returns dir or the nearest parent that looks like a Unity
+// or Godot project root, so project state is found from any subdirectory.
+// It returns dir when no parent does.
+func FindProjectRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for current := dir; ; {
+		if isProjectRoot(current) {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// isProjectRoot reports whether dir holds a Unity or Godot project
+func isProjectRoot(dir string) bool {
+	if dirExists(filepath.Join(dir, "Assets")) && dirExists(filepath.Join(dir, "ProjectSettings")) {
+		return true
+	}
+	return fileExists(filepath.Join(dir, "project.godot"))
+}
@@ -0,0 +1,106 @@
+package engines
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GUIDCollisions compares the asset GUIDs in the .meta files under
+// packageDir with those of the project's Assets/ folder. Unity keys imported
+// assets by GUID, so a package reusing one breaks the import of whichever
+// asset loses. Each collision names both .meta files, relative to the
+// project.
+func (u *UnityAdapter) GUIDCollisions(projectPath, packageDir string) ([]string, error) {
+	assetGUIDs, err := metaGUIDs(filepath.Join(projectPath, "Assets"))
+	if err != nil {
+		return nil, err
+	}
+	if len(assetGUIDs) == 0 {
+		return nil, nil
+	}
+	packageGUIDs, err := metaGUIDs(packageDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var collisions []string
+	for guid, packageMeta := range packageGUIDs {
+		assetMeta, ok := assetGUIDs[guid]
+		if !ok {
+			continue
+		}
+		collisions = append(collisions, fmt.Sprintf("%s has GUID %s, already used by %s",
+			projectRelative(projectPath, packageMeta), guid, projectRelative(projectPath, assetMeta)))
+	}
+	sort.Strings(collisions)
+	return collisions, nil
+}
+
+// metaGUIDs maps the GUID of every .meta file under root to its path. A
+// missing root has no GUIDs.
+func metaGUIDs(root string) (map[string]string, error) {
+	guids := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".meta") {
+			return nil
+		}
+		guid, err := readMetaGUID(path)
+		if err != nil {
+			return err
+		}
+		if guid != "" {
+			guids[guid] = path
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan .meta files in %s: %w", root, err)
+	}
+	return guids, nil
+}
+
+// readMetaGUID returns the "guid:" value of a Unity .meta file
+func readMetaGUID(path string) (string, error) {
+	file, err := os.Open(path) // #nosec G304 - .meta file found by walking the project
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if guid, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "guid:"); ok {
+			return strings.ToLower(strings.TrimSpace(guid)), nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// projectRelative returns path relative to the project, with forward
+// slashes, or path unchanged if it is outside the project
+func projectRelative(projectPath, path string) string {
+	absProject, err := filepath.Abs(projectPath)
+	if err != nil {
+		return path
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(absProject, absPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,114 @@
+package engines
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// UnityPackagesLockFile is the lockfile Unity writes next to manifest.json
+	UnityPackagesLockFile = "packages-lock.json"
+
+	// UnityPackageCacheDir is where Unity extracts the packages it resolves
+	UnityPackageCacheDir = "Library/PackageCache"
+)
+
+// UnityPackagesLock represents Unity's Packages/packages-lock.json
+type UnityPackagesLock struct {
+	Dependencies map[string]*UnityLockEntry `json:"dependencies"`
+}
+
+// UnityLockEntry is one resolved package in packages-lock.json. Depth 0
+// entries are the manifest's direct dependencies.
+type UnityLockEntry struct {
+	Version      string            `json:"version"`
+	Depth        int               `json:"depth"`
+	Source       string            `json:"source"`
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+	URL          string            `json:"url,omitempty"`
+}
+
+// LockPath returns the path of the packages-lock.json next to the manifest
+func (u *UnityAdapter) LockPath(projectPath string) (string, error) {
+	manifestPath, err := u.ManifestPath(projectPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(manifestPath), UnityPackagesLockFile), nil
+}
+
+// LoadLock reads the project's packages-lock.json. A missing lock is an error
+// wrapping os.ErrNotExist.
+func (u *UnityAdapter) LoadLock(projectPath string) (*UnityPackagesLock, error) {
+	lockPath, err := u.LockPath(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(lockPath) // #nosec G304 - fixed file name next to the resolved manifest
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", UnityPackagesLockFile, err)
+	}
+
+	var lock UnityPackagesLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", UnityPackagesLockFile, err)
+	}
+	if lock.Dependencies == nil {
+		lock.Dependencies = make(map[string]*UnityLockEntry)
+	}
+	return &lock, nil
+}
+
+// LockMismatches compares the manifest's dependencies with the direct
+// dependencies recorded in packages-lock.json and describes each difference.
+// Embedded packages live in the packages directory rather than the manifest,
+// so they are not expected there.
+func (u *UnityAdapter) LockMismatches(projectPath string) ([]string, error) {
+	lock, err := u.LoadLock(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	manifestPath, err := u.ManifestPath(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := u.loadManifest(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+
+	var mismatches []string
+	for name, version := range manifest.Dependencies {
+		entry := lock.Dependencies[name]
+		switch {
+		case entry == nil:
+			mismatches = append(mismatches, fmt.Sprintf("%s@%s is in manifest.json but not in %s", name, version, UnityPackagesLockFile))
+		case entry.Version != version:
+			mismatches = append(mismatches, fmt.Sprintf("%s is %s in manifest.json but %s in %s", name, version, entry.Version, UnityPackagesLockFile))
+		case entry.Depth != 0:
+			mismatches = append(mismatches, fmt.Sprintf("%s is a direct dependency but %s records it as transitive", name, UnityPackagesLockFile))
+		}
+	}
+	for name, entry := range lock.Dependencies {
+		if entry == nil || entry.Depth != 0 || entry.Source == "embedded" {
+			continue
+		}
+		if _, ok := manifest.Dependencies[name]; !ok {
+			mismatches = append(mismatches, fmt.Sprintf("%s@%s is in %s but not in manifest.json", name, entry.Version, UnityPackagesLockFile))
+		}
+	}
+	sort.Strings(mismatches)
+	return mismatches, nil
+}
+
+// CleanPackageCache removes the extracted packages under Library/ so Unity
+// installs exactly the locked versions the next time it opens the project
+func (u *UnityAdapter) CleanPackageCache(projectPath string) error {
+	if err := os.RemoveAll(filepath.Join(projectPath, filepath.FromSlash(UnityPackageCacheDir))); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", UnityPackageCacheDir, err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package engines
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// UnityPackagesFile records, in the project's state directory, where each
+// package gpm added came from and what was asked for. Unity only understands
+// exact versions, so the manifest holds the version a dist-tag or npm range
+// resolved to, and this file keeps the tag or range. Older gpm versions kept
+// it next to manifest.json; that copy is read when the state directory has
+// none, and removed once it is written.
+const UnityPackagesFile = "gpm-packages.json"
+
+// UnityRangesFile is where older gpm versions recorded requested ranges, next
+// to manifest.json. It is read when there is no UnityPackagesFile yet, and
+// removed once that is written.
+const UnityRangesFile = "gpm-ranges.json"
+
+// PackageOrigin is a dependency's entry in UnityPackagesFile. Tag and Range
+// are empty for a package asked for by exact version.
+type PackageOrigin struct {
+	Registry string `json:"registry,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	Range    string `json:"range,omitempty"`
+	Version  string `json:"version"`
+}
+
+// packagesFilePath returns the path of name next to the manifest
+func (u *UnityAdapter) packagesFilePath(projectPath, name string) (string, error) {
+	manifestPath, err := u.ManifestPath(projectPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(manifestPath), name), nil
+}
+
+// originsPath returns the path of UnityPackagesFile in the state directory
+func (u *UnityAdapter) originsPath(projectPath string) string {
+	stateDir := u.stateDir
+	if stateDir == "" {
+		stateDir = filepath.Join(projectPath, StateDirName)
+	}
+	return filepath.Join(stateDir, UnityPackagesFile)
+}
+
+// PackageOrigins returns the recorded origin of each package, keyed by
+// manifest dependency name. A missing file means none were recorded.
+func (u *UnityAdapter) PackageOrigins(projectPath string) (map[string]PackageOrigin, error) {
+	data, err := os.ReadFile(u.originsPath(projectPath)) // #nosec G304 - fixed file name in the state directory
+	if errors.Is(err, os.ErrNotExist) {
+		return u.legacyOrigins(projectPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", UnityPackagesFile, err)
+	}
+	origins := make(map[string]PackageOrigin)
+	if err := json.Unmarshal(data, &origins); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", UnityPackagesFile, err)
+	}
+	return origins, nil
+}
+
+// legacyOrigins reads the UnityPackagesFile older gpm versions kept next to
+// the manifest, or else UnityRangesFile
+func (u *UnityAdapter) legacyOrigins(projectPath string) (map[string]PackageOrigin, error) {
+	packagesPath, err := u.packagesFilePath(projectPath, UnityPackagesFile)
+	if err != nil {
+		return nil, err
+	}
+	origins := make(map[string]PackageOrigin)
+	data, err := os.ReadFile(packagesPath) // #nosec G304 - fixed file name next to the resolved manifest
+	if errors.Is(err, os.ErrNotExist) {
+		return u.legacyRanges(projectPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", UnityPackagesFile, err)
+	}
+	if err := json.Unmarshal(data, &origins); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", UnityPackagesFile, err)
+	}
+	return origins, nil
+}
+
+// legacyRanges reads UnityRangesFile as origins that only know their range
+func (u *UnityAdapter) legacyRanges(projectPath string) (map[string]PackageOrigin, error) {
+	rangesPath, err := u.packagesFilePath(projectPath, UnityRangesFile)
+	if err != nil {
+		return nil, err
+	}
+	origins := make(map[string]PackageOrigin)
+	data, err := os.ReadFile(rangesPath) // #nosec G304 - fixed file name next to the resolved manifest
+	if errors.Is(err, os.ErrNotExist) {
+		return origins, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", UnityRangesFile, err)
+	}
+	var ranges map[string]string
+	if err := json.Unmarshal(data, &ranges); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", UnityRangesFile, err)
+	}
+	for name, versionRange := range ranges {
+		origins[name] = PackageOrigin{Range: versionRange}
+	}
+	return origins, nil
+}
+
+// RequestedRanges returns the recorded range of each package requested with
+// one, keyed by manifest dependency name
+func (u *UnityAdapter) RequestedRanges(projectPath string) (map[string]string, error) {
+	origins, err := u.PackageOrigins(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	ranges := make(map[string]string)
+	for name, origin := range origins {
+		if origin.Range != "" {
+			ranges[name] = origin.Range
+		}
+	}
+	return ranges, nil
+}
+
+// SetPackageOrigin records where a package came from. A nil origin forgets
+// it, and the file is removed once it holds no packages.
+func (u *UnityAdapter) SetPackageOrigin(projectPath, name string, origin *PackageOrigin) error {
+	origins, err := u.PackageOrigins(projectPath)
+	if err != nil {
+		return err
+	}
+	if current, ok := origins[name]; (!ok && origin == nil) || (ok && origin != nil && current == *origin) {
+		return nil
+	}
+	if origin == nil {
+		delete(origins, name)
+	} else {
+		origins[name] = *origin
+	}
+
+	originsPath := u.originsPath(projectPath)
+	if len(origins) == 0 {
+		if err := os.Remove(originsPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", UnityPackagesFile, err)
+		}
+	} else {
+		data, err := json.MarshalIndent(origins, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", UnityPackagesFile, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(originsPath), 0750); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(originsPath), err)
+		}
+		if err := os.WriteFile(originsPath, append(data, '\n'), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", UnityPackagesFile, err)
+		}
+	}
+
+	// The legacy files next to the manifest now live in the state directory
+	for _, name := range []string{UnityPackagesFile, UnityRangesFile} {
+		legacyPath, err := u.packagesFilePath(projectPath, name)
+		if err != nil {
+			return err
+		}
+		if filepath.Clean(legacyPath) == filepath.Clean(originsPath) {
+			continue
+		}
+		if err := os.Remove(legacyPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", name, err)
+		}
+	}
+	return nil
+}
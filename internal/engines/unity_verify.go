@@ -0,0 +1,115 @@
+package engines
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// InvalidManifestError reports a manifest.json that isn't a manifest Unity
+// can load. gpm refuses to rewrite one unless the install is forced.
+type InvalidManifestError struct {
+	Path string
+	Err  error
+}
+
+func (e *InvalidManifestError) Error() string {
+	return fmt.Sprintf("%s is not a valid Unity manifest: %v", filepath.Base(e.Path), e.Err)
+}
+
+func (e *InvalidManifestError) Unwrap() error {
+	return e.Err
+}
+
+// checkUnityManifest parses data the way Unity reads manifest.json and
+// reports the first thing it would reject: a root that isn't an object,
+// dependencies that aren't an object of version strings, or a scoped
+// registry without a name, an http(s) url or scopes
+func checkUnityManifest(data []byte) error {
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("not a JSON object: %w", err)
+	}
+	if raw, ok := root["dependencies"]; ok {
+		var dependencies map[string]string
+		if err := json.Unmarshal(raw, &dependencies); err != nil {
+			return errors.New("dependencies must be an object mapping package names to versions")
+		}
+	}
+	if raw, ok := root["scopedRegistries"]; ok {
+		var registries []*ScopedRegistry
+		if err := json.Unmarshal(raw, &registries); err != nil {
+			return errors.New("scopedRegistries must be a list of objects with a name, url and scopes")
+		}
+		if problems := scopedRegistryProblems(registries); len(problems) > 0 {
+			return errors.New(strings.Join(problems, "; "))
+		}
+	}
+	return nil
+}
+
+// scopedRegistryProblems lists the scoped registries Unity would reject
+func scopedRegistryProblems(registries []*ScopedRegistry) []string {
+	var problems []string
+	for i, registry := range registries {
+		if registry == nil {
+			problems = append(problems, fmt.Sprintf("scoped registry %d is empty", i+1))
+			continue
+		}
+		if registry.Name == "" {
+			problems = append(problems, fmt.Sprintf("scoped registry %s has no name", registry.URL))
+		}
+		if parsed, err := url.Parse(registry.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			problems = append(problems, fmt.Sprintf("scoped registry %q has an invalid url %q", registry.Name, registry.URL))
+		}
+		if len(registry.Scopes) == 0 {
+			problems = append(problems, fmt.Sprintf("scoped registry %q has no scopes", registry.Name))
+		}
+	}
+	return problems
+}
+
+// VerifyInstall re-reads the manifest after an install and reports what would
+// stop Unity from resolving the package: the dependency missing or at another
+// version than installed, a malformed scoped registry, or, unless the request
+// skipped scoped registries, no scoped registry mapping the package's scope
+// to the request's registry. An empty result means the install checks out.
+func (u *UnityAdapter) VerifyInstall(projectPath string, req *PackageInstallRequest, version string) ([]string, error) {
+	manifestPath, err := u.ManifestPath(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	if !fileExists(manifestPath) {
+		return []string{"manifest.json was not written"}, nil
+	}
+	manifest, err := u.loadManifest(manifestPath)
+	if err != nil {
+		return []string{fmt.Sprintf("manifest.json no longer parses: %v", err)}, nil
+	}
+
+	var problems []string
+	installed, ok := manifest.Dependencies[req.Name]
+	switch {
+	case !ok:
+		problems = append(problems, fmt.Sprintf("%s is missing from dependencies", req.Name))
+	case installed != version:
+		problems = append(problems, fmt.Sprintf("dependencies has %s@%s, expected %s", req.Name, installed, version))
+	}
+
+	problems = append(problems, scopedRegistryProblems(manifest.ScopedRegistries)...)
+
+	if req.Registry != "" && req.Registry != "https://packages.unity.com" && !req.NoScopedRegistry {
+		scope := DeriveScopeFromPackageName(req.Name)
+		mapped := slices.ContainsFunc(manifest.ScopedRegistries, func(registry *ScopedRegistry) bool {
+			return registry != nil && sameRegistryURL(registry.URL, req.Registry) && slices.Contains(registry.Scopes, scope)
+		})
+		if !mapped {
+			problems = append(problems, fmt.Sprintf("no scoped registry maps %s to %s", scope, req.Registry))
+		}
+	}
+	return problems, nil
+}
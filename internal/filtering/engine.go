@@ -17,6 +17,24 @@ type FileFilterEngine struct {
 	hasFilesField   bool
 	builtinExcludes []Pattern
 	builtinIncludes []Pattern
+	// parentIgnores holds .gpmignore files from directories above rootDir,
+	// nearest first, up to the enclosing repository root
+	parentIgnores []ignoreLayer
+	// followSymlinks packs the targets of symlinks inside the package root
+	// instead of excluding the links
+	followSymlinks bool
+	// extraExcludes come from --ignore and apply on top of every other rule
+	// except the builtin includes
+	extraExcludes []Pattern
+}
+
+// ignoreLayer is a parent-directory .gpmignore. Its patterns are relative to
+// the directory holding the file, so prefix is the package root relative to
+// that directory (e.g. "packages/analytics/").
+type ignoreLayer struct {
+	prefix          string
+	includePatterns []Pattern
+	excludePatterns []Pattern
 }
 
 type Pattern struct {
@@ -31,6 +49,9 @@ type FilteredFile struct {
 	AbsolutePath string
 	IsDir        bool
 	Size         int64
+	// Rule is the filter decision that included the file, e.g.
+	// "files: Runtime/" or "default"
+	Rule string
 }
 
 type FilterResult struct {
@@ -39,6 +60,14 @@ type FilterResult struct {
 	FileCount  int
 	Excluded   []string
 	IncludedBy string // "files", "gpmignore", "npmignore", "gitignore", or "builtin"
+	// ExcludedBy maps each excluded path to the rule that excluded it
+	ExcludedBy map[string]string
+	// Warnings are non-fatal diagnostics, such as files entries that
+	// matched nothing
+	Warnings []string
+	// UnmatchedFiles lists the package.json files entries that matched no
+	// packed path, usually a typo
+	UnmatchedFiles []string
 }
 
 var builtinAlwaysInclude = []string{
@@ -87,11 +116,38 @@ func NewFileFilterEngine(rootDir string) (*FileFilterEngine, error) {
 		if err := engine.loadIgnoreFiles(); err != nil {
 			return nil, fmt.Errorf("failed to load ignore files: %w", err)
 		}
+		if err := engine.loadParentIgnoreFiles(); err != nil {
+			return nil, fmt.Errorf("failed to load parent ignore files: %w", err)
+		}
 	}
 
 	return engine, nil
 }
 
+// SetFollowSymlinks makes FilterFiles resolve symlinks and include their
+// targets. Links that resolve outside the package root are an error.
+func (e *FileFilterEngine) SetFollowSymlinks(follow bool) {
+	e.followSymlinks = follow
+}
+
+// AddIgnorePatterns excludes paths matching patterns, written like
+// .gpmignore lines, for this engine only. They are applied even when
+// package.json has a files field.
+func (e *FileFilterEngine) AddIgnorePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		compiled, err := compilePattern(pattern, false)
+		if err != nil {
+			return fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+		}
+		e.extraExcludes = append(e.extraExcludes, compiled)
+	}
+	return nil
+}
+
 func (e *FileFilterEngine) loadBuiltinPatterns() error {
 	for _, pattern := range builtinAlwaysInclude {
 		compiled, err := compilePattern(pattern, false)
@@ -164,12 +220,67 @@ func (e *FileFilterEngine) loadIgnoreFiles() error {
 }
 
 func (e *FileFilterEngine) loadIgnoreFile(filename string) error {
+	excludes, includes, err := parseIgnoreFile(filename)
+	if err != nil {
+		return err
+	}
+	e.excludePatterns = append(e.excludePatterns, excludes...)
+	e.includePatterns = append(e.includePatterns, includes...)
+	return nil
+}
+
+// loadParentIgnoreFiles collects .gpmignore files from the directories above
+// the package root, stopping at the repository root (the first directory
+// containing .git). Packages outside a repository get no parent layers.
+func (e *FileFilterEngine) loadParentIgnoreFiles() error {
+	absRoot, err := filepath.Abs(e.rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve package root: %w", err)
+	}
+
+	// A package that is itself the repository root has no parents to inherit
+	if _, err := os.Stat(filepath.Join(absRoot, ".git")); err == nil {
+		return nil
+	}
+
+	var layers []ignoreLayer
+	for dir := filepath.Dir(absRoot); ; dir = filepath.Dir(dir) {
+		ignorePath := filepath.Join(dir, ".gpmignore")
+		if _, err := os.Stat(ignorePath); err == nil {
+			excludes, includes, err := parseIgnoreFile(ignorePath)
+			if err != nil {
+				return err
+			}
+			prefix, err := filepath.Rel(dir, absRoot)
+			if err != nil {
+				return fmt.Errorf("failed to resolve %s: %w", ignorePath, err)
+			}
+			layers = append(layers, ignoreLayer{
+				prefix:          filepath.ToSlash(prefix) + "/",
+				excludePatterns: excludes,
+				includePatterns: includes,
+			})
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			e.parentIgnores = layers
+			return nil
+		}
+		if filepath.Dir(dir) == dir {
+			return nil
+		}
+	}
+}
+
+func parseIgnoreFile(filename string) ([]Pattern, []Pattern, error) {
 	file, err := os.Open(filename) // #nosec G304 - Filename is validated and safe
 	if err != nil {
-		return fmt.Errorf("failed to open %s: %w", filename, err)
+		return nil, nil, fmt.Errorf("failed to open %s: %w", filename, err)
 	}
 	defer func() { _ = file.Close() }()
 
+	var excludes, includes []Pattern
+
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -189,13 +300,13 @@ func (e *FileFilterEngine) loadIgnoreFile(filename string) error {
 		}
 
 		if isNegated {
-			e.includePatterns = append(e.includePatterns, compiled)
+			includes = append(includes, compiled)
 		} else {
-			e.excludePatterns = append(e.excludePatterns, compiled)
+			excludes = append(excludes, compiled)
 		}
 	}
 
-	return scanner.Err()
+	return excludes, includes, scanner.Err()
 }
 
 func compilePattern(pattern string, isNegated bool) (Pattern, error) {
@@ -245,8 +356,9 @@ func patternToRegex(pattern string) string {
 
 func (e *FileFilterEngine) FilterFiles() (*FilterResult, error) {
 	result := &FilterResult{
-		Files:    []FilteredFile{},
-		Excluded: []string{},
+		Files:      []FilteredFile{},
+		Excluded:   []string{},
+		ExcludedBy: make(map[string]string),
 	}
 
 	err := filepath.Walk(e.rootDir, func(path string, info os.FileInfo, err error) error {
@@ -263,41 +375,148 @@ func (e *FileFilterEngine) FilterFiles() (*FilterResult, error) {
 			return nil
 		}
 
-		if info.Mode()&os.ModeSymlink != 0 {
-			result.Excluded = append(result.Excluded, relPath+" (symlink)")
-			return nil
-		}
+		return e.visit(result, path, relPath, info, nil)
+	})
+	if err != nil {
+		return result, err
+	}
 
-		normalizedPath := filepath.ToSlash(relPath)
+	if e.hasFilesField {
+		for _, pattern := range e.includePatterns {
+			if !patternMatchesAny(pattern, result.Files) {
+				result.UnmatchedFiles = append(result.UnmatchedFiles, pattern.Pattern)
+				result.Warnings = append(result.Warnings, fmt.Sprintf("files entry %q matched no files", pattern.Pattern))
+			}
+		}
+	}
 
-		shouldInclude, reason := e.shouldInclude(normalizedPath, info.IsDir())
+	return result, nil
+}
 
-		if shouldInclude {
-			filteredFile := FilteredFile{
-				RelativePath: relPath,
-				AbsolutePath: path,
-				IsDir:        info.IsDir(),
-			}
+// visit filters one walked path. followed lists the resolved directories of
+// the symlinks being followed to reach it, to catch cycles.
+func (e *FileFilterEngine) visit(result *FilterResult, path, relPath string, info os.FileInfo, followed []string) error {
+	if info.Mode()&os.ModeSymlink == 0 {
+		e.filter(result, path, relPath, info, "")
+		return nil
+	}
 
-			if !info.IsDir() {
-				filteredFile.Size = info.Size()
-				result.TotalSize += info.Size()
-				result.FileCount++
-			}
+	if !e.followSymlinks {
+		result.Excluded = append(result.Excluded, relPath+" (symlink)")
+		result.ExcludedBy[relPath] = "symlink (use --follow-symlinks to pack its target)"
+		return nil
+	}
 
-			result.Files = append(result.Files, filteredFile)
+	target, err := e.resolveSymlink(path, relPath)
+	if err != nil {
+		return err
+	}
+	targetInfo, err := os.Stat(target)
+	if err != nil {
+		return fmt.Errorf("failed to stat symlink target of %s: %w", filepath.ToSlash(relPath), err)
+	}
+	if !targetInfo.IsDir() {
+		e.filter(result, target, relPath, targetInfo, "symlink")
+		return nil
+	}
 
-			if result.IncludedBy == "" {
-				result.IncludedBy = reason
-			}
-		} else {
-			result.Excluded = append(result.Excluded, relPath)
+	for _, dir := range followed {
+		if dir == target {
+			return fmt.Errorf("symlink %s creates a cycle", filepath.ToSlash(relPath))
 		}
+	}
+	followed = append(followed, target)
 
-		return nil
+	e.filter(result, target, relPath, targetInfo, "symlink")
+	return filepath.Walk(target, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(target, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		return e.visit(result, path, filepath.Join(relPath, rel), info, followed)
 	})
+}
 
-	return result, err
+// resolveSymlink returns the real path a symlink points at, rejecting links
+// that leave the package root
+func (e *FileFilterEngine) resolveSymlink(path, relPath string) (string, error) {
+	root, err := filepath.EvalSymlinks(e.rootDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve package root: %w", err)
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve symlink %s: %w", filepath.ToSlash(relPath), err)
+	}
+	target, err = filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+
+	if rel, err := filepath.Rel(root, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("symlink %s points outside the package root (%s)", filepath.ToSlash(relPath), target)
+	}
+	return target, nil
+}
+
+// filter applies the include rules to one path, reading the file from
+// absPath. via notes how the path was reached, e.g. "symlink".
+func (e *FileFilterEngine) filter(result *FilterResult, absPath, relPath string, info os.FileInfo, via string) {
+	normalizedPath := filepath.ToSlash(relPath)
+
+	shouldInclude, reason, pattern := e.shouldInclude(normalizedPath, info.IsDir())
+
+	rule := reason
+	if pattern != "" {
+		rule += ": " + pattern
+	}
+	if via != "" {
+		rule += ", via " + via
+	}
+
+	if !shouldInclude {
+		result.Excluded = append(result.Excluded, relPath)
+		result.ExcludedBy[relPath] = rule
+		return
+	}
+
+	filteredFile := FilteredFile{
+		RelativePath: relPath,
+		AbsolutePath: absPath,
+		IsDir:        info.IsDir(),
+		Rule:         rule,
+	}
+
+	if !info.IsDir() {
+		filteredFile.Size = info.Size()
+		result.TotalSize += info.Size()
+		result.FileCount++
+	}
+
+	result.Files = append(result.Files, filteredFile)
+
+	if result.IncludedBy == "" {
+		result.IncludedBy = reason
+	}
+}
+
+func patternMatchesAny(pattern Pattern, files []FilteredFile) bool {
+	for _, file := range files {
+		if !pattern.IsDir && file.IsDir {
+			continue
+		}
+		if pattern.Regex.MatchString(filepath.ToSlash(file.RelativePath)) {
+			return true
+		}
+	}
+	return false
 }
 
 // HasFilesField returns whether the engine has a files field configured
@@ -310,95 +529,163 @@ func (e *FileFilterEngine) GetIncludePatterns() []Pattern {
 	return e.includePatterns
 }
 
-func (e *FileFilterEngine) shouldInclude(normalizedPath string, isDir bool) (bool, string) {
+// shouldInclude decides whether a path is packed. It also returns the kind of
+// rule that decided and the pattern that matched, if any.
+func (e *FileFilterEngine) shouldInclude(normalizedPath string, isDir bool) (bool, string, string) {
+	// --ignore patterns win over everything but the files npm always packs
+	if len(e.extraExcludes) > 0 {
+		if _, builtin := e.matchesBuiltinInclude(normalizedPath); !builtin {
+			if pattern, matches := matchesPatterns(e.extraExcludes, normalizedPath, isDir); matches {
+				return false, "--ignore", pattern
+			}
+		}
+	}
+
 	// If files field is present, it takes precedence over everything else
 	if e.hasFilesField {
-		matches := e.matchesFilesField(normalizedPath, isDir)
-		if matches {
-			return true, "files"
+		if pattern, matches := e.matchesFilesField(normalizedPath, isDir); matches {
+			return true, "files", pattern
 		}
-		return false, "files"
+		return false, "files", "no entry matched"
 	}
 
 	// Builtin includes (always included regardless of other rules)
-	if e.matchesBuiltinInclude(normalizedPath) {
-		return true, "builtin"
+	if pattern, matches := e.matchesBuiltinInclude(normalizedPath); matches {
+		return true, "builtin", pattern
 	}
 
 	// Builtin excludes (always excluded regardless of other rules)
-	if e.matchesBuiltinExclude(normalizedPath, isDir) {
-		return false, "builtin"
+	if pattern, matches := e.matchesBuiltinExclude(normalizedPath, isDir); matches {
+		return false, "builtin", pattern
 	}
 
 	// Check ignore patterns
-	if e.matchesExcludePattern(normalizedPath, isDir) {
-		if e.matchesIncludePattern(normalizedPath, isDir) {
-			return true, "gpmignore/npmignore/gitignore"
+	if excludedBy, matches := e.matchesExcludePattern(normalizedPath, isDir); matches {
+		if includedBy, matches := e.matchesIncludePattern(normalizedPath, isDir); matches {
+			return true, "gpmignore/npmignore/gitignore", "!" + includedBy
 		}
-		return false, "gpmignore/npmignore/gitignore"
+		return false, "gpmignore/npmignore/gitignore", excludedBy
 	}
 
-	return true, "default"
+	// A local negation overrides anything a parent .gpmignore excludes
+	if len(e.parentIgnores) > 0 {
+		if includedBy, matches := e.matchesIncludePattern(normalizedPath, isDir); matches {
+			return true, "gpmignore/npmignore/gitignore", "!" + includedBy
+		}
+	}
+
+	// Parent .gpmignore files, nearest first; the first that matches decides
+	for _, layer := range e.parentIgnores {
+		if excludedBy, matches := layer.matches(layer.excludePatterns, normalizedPath, isDir, false); matches {
+			if includedBy, matches := layer.matches(layer.includePatterns, normalizedPath, isDir, true); matches {
+				return true, "parent gpmignore", "!" + includedBy
+			}
+			return false, "parent gpmignore", excludedBy
+		}
+		if includedBy, matches := layer.matches(layer.includePatterns, normalizedPath, isDir, true); matches {
+			return true, "parent gpmignore", "!" + includedBy
+		}
+	}
+
+	return true, "default", ""
+}
+
+// matches tests patterns from a parent ignore file against a package path and
+// returns the first that matches. Patterns without a slash float like git's
+// and match at any depth below the ignore file; the others are anchored to
+// the ignore file's directory.
+func (l ignoreLayer) matches(patterns []Pattern, normalizedPath string, isDir, negated bool) (string, bool) {
+	layerPath := l.prefix + normalizedPath
+	for _, pattern := range patterns {
+		if negated && pattern.IsDir && !isDir {
+			continue
+		}
+		if !negated && !pattern.IsDir && isDir {
+			continue
+		}
+
+		if strings.Contains(strings.TrimSuffix(pattern.Pattern, "/"), "/") {
+			if pattern.Regex.MatchString(layerPath) {
+				return pattern.Pattern, true
+			}
+			continue
+		}
+
+		for candidate := layerPath; candidate != ""; {
+			if pattern.Regex.MatchString(candidate) {
+				return pattern.Pattern, true
+			}
+			idx := strings.Index(candidate, "/")
+			if idx < 0 {
+				break
+			}
+			candidate = candidate[idx+1:]
+		}
+	}
+	return "", false
 }
 
-func (e *FileFilterEngine) matchesBuiltinInclude(normalizedPath string) bool {
+func (e *FileFilterEngine) matchesBuiltinInclude(normalizedPath string) (string, bool) {
 	for _, pattern := range e.builtinIncludes {
 		if pattern.Regex.MatchString(normalizedPath) {
-			return true
+			return pattern.Pattern, true
 		}
 	}
-	return false
+	return "", false
 }
 
-func (e *FileFilterEngine) matchesBuiltinExclude(normalizedPath string, isDir bool) bool {
+func (e *FileFilterEngine) matchesBuiltinExclude(normalizedPath string, isDir bool) (string, bool) {
 	for _, pattern := range e.builtinExcludes {
 		if pattern.IsDir && !isDir {
 			continue
 		}
 		if pattern.Regex.MatchString(normalizedPath) {
-			return true
+			return pattern.Pattern, true
 		}
 	}
-	return false
+	return "", false
 }
 
-func (e *FileFilterEngine) matchesFilesField(normalizedPath string, isDir bool) bool {
+func (e *FileFilterEngine) matchesFilesField(normalizedPath string, isDir bool) (string, bool) {
 	for _, pattern := range e.includePatterns {
 		// Directory patterns should match both directories and files within them
 		// File patterns should only match files
 		if !pattern.IsDir && isDir {
 			continue
 		}
-		matches := pattern.Regex.MatchString(normalizedPath)
-		if matches {
-			return true
+		if pattern.Regex.MatchString(normalizedPath) {
+			return pattern.Pattern, true
 		}
 	}
-	return false
+	return "", false
+}
+
+func (e *FileFilterEngine) matchesExcludePattern(normalizedPath string, isDir bool) (string, bool) {
+	return matchesPatterns(e.excludePatterns, normalizedPath, isDir)
 }
 
-func (e *FileFilterEngine) matchesExcludePattern(normalizedPath string, isDir bool) bool {
-	for _, pattern := range e.excludePatterns {
+func matchesPatterns(patterns []Pattern, normalizedPath string, isDir bool) (string, bool) {
+	for _, pattern := range patterns {
 		// Directory patterns should match both directories and files within them
 		// File patterns should only match files (not directories)
 		if !pattern.IsDir && isDir {
 			continue
 		}
 		if pattern.Regex.MatchString(normalizedPath) {
-			return true
+			return pattern.Pattern, true
 		}
 	}
-	return false
+	return "", false
 }
 
-func (e *FileFilterEngine) matchesIncludePattern(normalizedPath string, isDir bool) bool {
+func (e *FileFilterEngine) matchesIncludePattern(normalizedPath string, isDir bool) (string, bool) {
 	for _, pattern := range e.includePatterns {
 		if pattern.IsDir && !isDir {
 			continue
 		}
 		if pattern.Regex.MatchString(normalizedPath) {
-			return true
+			return pattern.Pattern, true
 		}
 	}
-	return false
+	return "", false
 }
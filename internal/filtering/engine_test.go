@@ -3,6 +3,7 @@ package filtering
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -216,3 +217,313 @@ coverage/`
 
 	t.Logf("GPM ignore priority test passed. .gpmignore takes precedence over .npmignore")
 }
+
+func TestParentGpmignore(t *testing.T) {
+	repoDir := t.TempDir()
+
+	writeFile := func(path, content string) {
+		fullPath := filepath.Join(repoDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", path, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	includedFiles := func(packageDir string) map[string]bool {
+		engine, err := NewFileFilterEngine(filepath.Join(repoDir, packageDir))
+		if err != nil {
+			t.Fatalf("Failed to create file filter engine: %v", err)
+		}
+		result, err := engine.FilterFiles()
+		if err != nil {
+			t.Fatalf("Failed to filter files: %v", err)
+		}
+		included := make(map[string]bool)
+		for _, file := range result.Files {
+			if !file.IsDir {
+				included[filepath.ToSlash(file.RelativePath)] = true
+			}
+		}
+		return included
+	}
+
+	packageJSON := `{"name": "com.test.package", "version": "1.0.0"}`
+
+	// Shared ignore file at the repository root
+	if err := os.MkdirAll(filepath.Join(repoDir, ".git"), 0755); err != nil {
+		t.Fatalf("Failed to create .git: %v", err)
+	}
+	writeFile(".gpmignore", "*.tmp\nTemp/\n")
+
+	// Package relying entirely on the root .gpmignore
+	writeFile("packages/analytics/package.json", packageJSON)
+	writeFile("packages/analytics/Runtime/Analytics.cs", "// code")
+	writeFile("packages/analytics/scratch.tmp", "junk")
+	writeFile("packages/analytics/Runtime/cache.tmp", "junk")
+	writeFile("packages/analytics/Temp/build.log", "junk")
+
+	included := includedFiles("packages/analytics")
+	for _, expected := range []string{"package.json", "Runtime/Analytics.cs"} {
+		if !included[expected] {
+			t.Errorf("Expected %s to be included", expected)
+		}
+	}
+	for _, excluded := range []string{"scratch.tmp", "Runtime/cache.tmp", "Temp/build.log"} {
+		if included[excluded] {
+			t.Errorf("Expected %s to be excluded by the root .gpmignore", excluded)
+		}
+	}
+
+	// Package whose own .gpmignore takes precedence over the root one
+	writeFile("packages/ads/package.json", packageJSON)
+	writeFile("packages/ads/.gpmignore", "!keep.tmp\nDocs/\n")
+	writeFile("packages/ads/keep.tmp", "wanted")
+	writeFile("packages/ads/other.tmp", "junk")
+	writeFile("packages/ads/Docs/index.md", "docs")
+	writeFile("packages/ads/Runtime/Ads.cs", "// code")
+
+	included = includedFiles("packages/ads")
+	for _, expected := range []string{"keep.tmp", "Runtime/Ads.cs"} {
+		if !included[expected] {
+			t.Errorf("Expected %s to be included", expected)
+		}
+	}
+	for _, excluded := range []string{"other.tmp", "Docs/index.md"} {
+		if included[excluded] {
+			t.Errorf("Expected %s to be excluded", excluded)
+		}
+	}
+}
+
+func TestParentGpmignoreOutsideRepository(t *testing.T) {
+	// Without an enclosing .git directory, parent ignore files are not applied
+	baseDir := t.TempDir()
+	packageDir := filepath.Join(baseDir, "package")
+	if err := os.MkdirAll(packageDir, 0755); err != nil {
+		t.Fatalf("Failed to create package directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, ".gpmignore"), []byte("*.tmp\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .gpmignore: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(packageDir, "package.json"), []byte(`{"name": "com.test.package", "version": "1.0.0"}`), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(packageDir, "notes.tmp"), []byte("kept"), 0644); err != nil {
+		t.Fatalf("Failed to write notes.tmp: %v", err)
+	}
+
+	engine, err := NewFileFilterEngine(packageDir)
+	if err != nil {
+		t.Fatalf("Failed to create file filter engine: %v", err)
+	}
+	result, err := engine.FilterFiles()
+	if err != nil {
+		t.Fatalf("Failed to filter files: %v", err)
+	}
+
+	found := false
+	for _, file := range result.Files {
+		if filepath.ToSlash(file.RelativePath) == "notes.tmp" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected notes.tmp to be included when no repository root is found")
+	}
+}
+
+func TestUnmatchedFilesEntries(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"package.json":   `{"name": "com.test.pkg", "version": "1.0.0", "files": ["Runitme/", "Editor/", "*.md"]}`,
+		"Runtime/a.cs":   "class A {}",
+		"Editor/b.cs":    "class B {}",
+		"Docs/guide.txt": "guide",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	engine, err := NewFileFilterEngine(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create filter engine: %v", err)
+	}
+	result, err := engine.FilterFiles()
+	if err != nil {
+		t.Fatalf("Failed to filter files: %v", err)
+	}
+
+	if len(result.UnmatchedFiles) != 2 || result.UnmatchedFiles[0] != "Runitme/" || result.UnmatchedFiles[1] != "*.md" {
+		t.Errorf("Expected Runitme/ and *.md to be unmatched, got %v", result.UnmatchedFiles)
+	}
+	if len(result.Warnings) != 2 {
+		t.Errorf("Expected a warning per unmatched entry, got %v", result.Warnings)
+	}
+}
+
+func TestSymlinks(t *testing.T) {
+	root := t.TempDir()
+	packageDir := filepath.Join(root, "package")
+	files := map[string]string{
+		"package/package.json":       `{"name": "com.test.pkg", "version": "1.0.0"}`,
+		"package/Shared/common.cs":   "class Common {}",
+		"package/Runtime/runtime.cs": "class Runtime {}",
+		"outside/secret.txt":         "secret",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	links := map[string]string{
+		"Runtime/common.cs": filepath.Join("..", "Shared", "common.cs"),
+		"Editor":            "Shared",
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(packageDir, link)); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	filter := func(follow bool) (*FilterResult, error) {
+		engine, err := NewFileFilterEngine(packageDir)
+		if err != nil {
+			t.Fatalf("Failed to create filter engine: %v", err)
+		}
+		engine.SetFollowSymlinks(follow)
+		return engine.FilterFiles()
+	}
+	packed := func(result *FilterResult) map[string]string {
+		paths := make(map[string]string)
+		for _, file := range result.Files {
+			if !file.IsDir {
+				paths[filepath.ToSlash(file.RelativePath)] = file.AbsolutePath
+			}
+		}
+		return paths
+	}
+
+	t.Run("excluded by default", func(t *testing.T) {
+		result, err := filter(false)
+		if err != nil {
+			t.Fatalf("Failed to filter files: %v", err)
+		}
+		paths := packed(result)
+		for _, link := range []string{"Runtime/common.cs", "Editor/common.cs"} {
+			if _, ok := paths[link]; ok {
+				t.Errorf("Expected %s to be excluded without --follow-symlinks", link)
+			}
+		}
+		if rule := result.ExcludedBy[filepath.Join("Runtime", "common.cs")]; rule == "" {
+			t.Errorf("Expected the excluded symlink to be explained, got %v", result.ExcludedBy)
+		}
+		if len(result.Warnings) != 0 {
+			t.Errorf("Expected skipped symlinks to be reported only in verbose output, got %v", result.Warnings)
+		}
+	})
+
+	t.Run("in-package symlinks are followed", func(t *testing.T) {
+		result, err := filter(true)
+		if err != nil {
+			t.Fatalf("Failed to filter files: %v", err)
+		}
+		paths := packed(result)
+		for _, link := range []string{"Runtime/common.cs", "Editor/common.cs"} {
+			target, ok := paths[link]
+			if !ok {
+				t.Errorf("Expected %s to be packed, got %v", link, paths)
+				continue
+			}
+			data, err := os.ReadFile(target)
+			if err != nil || string(data) != "class Common {}" {
+				t.Errorf("Expected %s to read the link target, got %q (%v)", link, data, err)
+			}
+		}
+	})
+
+	t.Run("escaping symlinks are rejected", func(t *testing.T) {
+		escaping := filepath.Join(packageDir, "secret.txt")
+		if err := os.Symlink(filepath.Join("..", "outside", "secret.txt"), escaping); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+		defer func() { _ = os.Remove(escaping) }()
+
+		_, err := filter(true)
+		if err == nil || !strings.Contains(err.Error(), "outside the package root") {
+			t.Errorf("Expected an escaping symlink error, got %v", err)
+		}
+	})
+
+	t.Run("cycles are rejected", func(t *testing.T) {
+		cycle := filepath.Join(packageDir, "Shared", "loop")
+		if err := os.Symlink(".", cycle); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+		defer func() { _ = os.Remove(cycle) }()
+
+		_, err := filter(true)
+		if err == nil || !strings.Contains(err.Error(), "cycle") {
+			t.Errorf("Expected a symlink cycle error, got %v", err)
+		}
+	})
+}
+
+func TestAddIgnorePatterns(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"package.json":         `{"name": "com.test.pkg", "version": "1.0.0", "files": ["Runtime/", "notes.local"]}`,
+		"notes.local":          "notes",
+		"Runtime/runtime.cs":   "class Runtime {}",
+		"Runtime/Tests/foo.cs": "class Foo {}",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	engine, err := NewFileFilterEngine(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create filter engine: %v", err)
+	}
+	if err := engine.AddIgnorePatterns([]string{"*.local", "Runtime/Tests/"}); err != nil {
+		t.Fatalf("AddIgnorePatterns failed: %v", err)
+	}
+	result, err := engine.FilterFiles()
+	if err != nil {
+		t.Fatalf("FilterFiles failed: %v", err)
+	}
+
+	packed := make(map[string]bool)
+	for _, file := range result.Files {
+		packed[filepath.ToSlash(file.RelativePath)] = true
+	}
+	if !packed["Runtime/runtime.cs"] {
+		t.Errorf("Expected Runtime/runtime.cs to be packed")
+	}
+	for _, path := range []string{"notes.local", "Runtime/Tests/foo.cs"} {
+		if packed[path] {
+			t.Errorf("Expected %s to be ignored, even though the files field lists it", path)
+		}
+	}
+	if rule := result.ExcludedBy["notes.local"]; rule != "--ignore: *.local" {
+		t.Errorf("Expected notes.local to be excluded by --ignore, got %q", rule)
+	}
+}
@@ -0,0 +1,94 @@
+package filtering
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GitComparison contrasts the files a pack would include with the files git
+// tracks under the package root
+type GitComparison struct {
+	// Untracked are files the filter includes that git does not track, which
+	// may ship by accident (build output, local notes, secrets)
+	Untracked []string `json:"untracked"`
+	// Omitted are tracked files the filter excludes, which may be missing
+	// from the files field
+	Omitted []string `json:"omitted"`
+}
+
+// IsGitRepo reports whether dir is inside a git work tree
+func IsGitRepo(dir string) bool {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--is-inside-work-tree").Output() // #nosec G204 - fixed git subcommand
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// GitHead returns the commit SHA checked out in the repository containing
+// dir, or "" outside a repository or before the first commit
+func GitHead(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--verify", "-q", "HEAD").Output() // #nosec G204 - fixed git subcommand
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// GitTrackedFiles lists the files git tracks under dir, relative to dir and
+// slash-separated
+func GitTrackedFiles(dir string) ([]string, error) {
+	out, err := exec.Command("git", "-C", dir, "ls-files", "-z", "--cached").Output() // #nosec G204 - fixed git subcommand
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed: %w", err)
+	}
+
+	var files []string
+	for _, name := range bytes.Split(out, []byte{0}) {
+		if len(name) > 0 {
+			files = append(files, string(name))
+		}
+	}
+	return files, nil
+}
+
+// CompareWithGit compares a filter result for rootDir against git's tracked
+// files. rootDir must be inside a git work tree.
+func CompareWithGit(rootDir string, result *FilterResult) (*GitComparison, error) {
+	if !IsGitRepo(rootDir) {
+		return nil, fmt.Errorf("%s is not inside a git repository", rootDir)
+	}
+
+	tracked, err := GitTrackedFiles(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	trackedSet := make(map[string]bool, len(tracked))
+	for _, file := range tracked {
+		trackedSet[file] = true
+	}
+
+	comparison := &GitComparison{Untracked: []string{}, Omitted: []string{}}
+	included := make(map[string]bool, len(result.Files))
+	for _, file := range result.Files {
+		if file.IsDir {
+			continue
+		}
+		path := filepath.ToSlash(file.RelativePath)
+		included[path] = true
+		if !trackedSet[path] {
+			comparison.Untracked = append(comparison.Untracked, path)
+		}
+	}
+	for _, file := range tracked {
+		if !included[file] {
+			comparison.Omitted = append(comparison.Omitted, file)
+		}
+	}
+
+	sort.Strings(comparison.Untracked)
+	sort.Strings(comparison.Omitted)
+	return comparison, nil
+}
@@ -0,0 +1,72 @@
+package filtering
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareWithGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tempDir := t.TempDir()
+	if IsGitRepo(tempDir) {
+		t.Skip("temp directory is inside a git repository")
+	}
+
+	files := map[string]string{
+		"package.json":   `{"name": "com.test.pkg", "version": "1.0.0", "files": ["package.json", "Runtime/", "dist/"]}`,
+		"Runtime/a.cs":   "class A {}",
+		"Editor/b.cs":    "class B {}",
+		"dist/bundle.js": "// build output",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	engine, err := NewFileFilterEngine(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create filter engine: %v", err)
+	}
+	result, err := engine.FilterFiles()
+	if err != nil {
+		t.Fatalf("Failed to filter files: %v", err)
+	}
+
+	if _, err := CompareWithGit(tempDir, result); err == nil {
+		t.Errorf("Expected an error outside a git repository")
+	}
+
+	// Track everything except the build output
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "package.json", "Runtime/a.cs", "Editor/b.cs"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tempDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	comparison, err := CompareWithGit(tempDir, result)
+	if err != nil {
+		t.Fatalf("CompareWithGit failed: %v", err)
+	}
+
+	if len(comparison.Untracked) != 1 || comparison.Untracked[0] != "dist/bundle.js" {
+		t.Errorf("Expected dist/bundle.js to be untracked but included, got %v", comparison.Untracked)
+	}
+	if len(comparison.Omitted) != 1 || comparison.Omitted[0] != "Editor/b.cs" {
+		t.Errorf("Expected Editor/b.cs to be tracked but excluded, got %v", comparison.Omitted)
+	}
+}
@@ -0,0 +1,149 @@
+package filtering
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SecretFinding is a likely credential in a file about to be packed. The
+// secret itself is never recorded, so findings are safe to print in CI logs.
+type SecretFinding struct {
+	File string
+	// Line is 1-based; 0 means the whole file, e.g. a .env file
+	Line int
+	Kind string
+}
+
+func (f SecretFinding) String() string {
+	if f.Line == 0 {
+		return fmt.Sprintf("%s: %s", f.File, f.Kind)
+	}
+	return fmt.Sprintf("%s:%d: %s", f.File, f.Line, f.Kind)
+}
+
+// secretPatterns are well-known credential formats
+var secretPatterns = []struct {
+	kind  string
+	regex *regexp.Regexp
+}{
+	{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"private key", regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )?PRIVATE KEY-----`)},
+	{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"npm token", regexp.MustCompile(`\bnpm_[A-Za-z0-9]{36}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+}
+
+// assignedValue finds long token-like values assigned or quoted in code and
+// config, the candidates for the entropy check
+var assignedValue = regexp.MustCompile(`[=:]\s*["']?([A-Za-z0-9+/_\-]{32,100})["']?|["']([A-Za-z0-9+/_\-]{32,100})["']`)
+
+// minSecretEntropy is the Shannon entropy, in bits per character, above
+// which an assigned value looks random. Hex strings such as Unity GUIDs top
+// out at 4 and stay below it.
+const minSecretEntropy = 4.5
+
+// maxScannedFileSize skips large files, which are assets rather than code
+const maxScannedFileSize = 1 << 20
+
+// ScanSecrets looks through the files about to be packed for .env files,
+// known key formats and high-entropy assigned values. Binary and very large
+// files are skipped.
+func ScanSecrets(result *FilterResult) ([]SecretFinding, error) {
+	var findings []SecretFinding
+	for _, file := range result.Files {
+		if file.IsDir {
+			continue
+		}
+		relativePath := filepath.ToSlash(file.RelativePath)
+		if isEnvFile(path.Base(relativePath)) {
+			findings = append(findings, SecretFinding{File: relativePath, Kind: "environment file"})
+			continue
+		}
+		if file.Size > maxScannedFileSize {
+			continue
+		}
+		fileFindings, err := scanFileForSecrets(file.AbsolutePath, relativePath)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, fileFindings...)
+	}
+	return findings, nil
+}
+
+// isEnvFile matches .env and .env.local style files, but not the templates
+// packages ship on purpose
+func isEnvFile(name string) bool {
+	if name != ".env" && !strings.HasPrefix(name, ".env.") {
+		return false
+	}
+	switch strings.TrimPrefix(name, ".env.") {
+	case "example", "sample", "template", "dist":
+		return false
+	}
+	return true
+}
+
+func scanFileForSecrets(absolutePath, relativePath string) ([]SecretFinding, error) {
+	data, err := os.ReadFile(absolutePath) // #nosec G304 - path comes from the filtered package files
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", relativePath, err)
+	}
+	sniff := data
+	if len(sniff) > 8000 {
+		sniff = sniff[:8000]
+	}
+	if bytes.IndexByte(sniff, 0) >= 0 {
+		return nil, nil
+	}
+
+	var findings []SecretFinding
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxScannedFileSize)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if kind := lineSecretKind(scanner.Text()); kind != "" {
+			findings = append(findings, SecretFinding{File: relativePath, Line: lineNumber, Kind: kind})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", relativePath, err)
+	}
+	return findings, nil
+}
+
+// lineSecretKind names the first kind of secret on a line, or returns ""
+func lineSecretKind(line string) string {
+	for _, pattern := range secretPatterns {
+		if pattern.regex.MatchString(line) {
+			return pattern.kind
+		}
+	}
+	for _, match := range assignedValue.FindAllStringSubmatch(line, -1) {
+		value := match[1] + match[2]
+		if strings.ContainsAny(value, "0123456789") && shannonEntropy(value) > minSecretEntropy {
+			return "high-entropy string"
+		}
+	}
+	return ""
+}
+
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	entropy := 0.0
+	length := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -2,18 +2,23 @@ package packaging
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+
+	"gpm.sh/gpm/gpm-cli/internal/validation"
 )
 
 type PackageInfo struct {
-	Name    string `json:"name"`
-	Version string `json:"version"`
+	Name          string                    `json:"name"`
+	Version       string                    `json:"version"`
+	PublishConfig *validation.PublishConfig `json:"publishConfig,omitempty"`
 }
 
 func DetectPackageSpecType(packageSpec string) string {
@@ -79,6 +84,87 @@ func ExtractPackageInfo(tarballPath string) (*PackageInfo, error) {
 	return nil, fmt.Errorf("package.json not found in tarball")
 }
 
+// TarballContents returns the number of regular files in a package tarball
+// and their total size once unpacked
+func TarballContents(tarballPath string) (int, int64, error) {
+	file, err := os.Open(filepath.Clean(tarballPath))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = file.Close() }()
+
+	gzr, err := gzip.NewReader(file)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = gzr.Close() }()
+
+	var fileCount int
+	var unpackedSize int64
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		if header.Typeflag == tar.TypeReg {
+			fileCount++
+			unpackedSize += header.Size
+		}
+	}
+	return fileCount, unpackedSize, nil
+}
+
+// firstKeyIndent matches the indentation of the first key in a package.json
+var firstKeyIndent = regexp.MustCompile(`\{\s*?\n([ \t]+)"`)
+
+// EmbedGitHead adds a "gitHead" field holding sha to a package.json, just
+// before its closing brace so the rest of the file keeps its formatting. A
+// package.json that already records a gitHead, or isn't an object, comes back
+// unchanged.
+func EmbedGitHead(packageJSON []byte, sha string) []byte {
+	var fields map[string]json.RawMessage
+	if sha == "" || json.Unmarshal(packageJSON, &fields) != nil {
+		return packageJSON
+	}
+	if _, ok := fields["gitHead"]; ok {
+		return packageJSON
+	}
+	end := bytes.LastIndexByte(packageJSON, '}')
+	body := bytes.TrimRight(packageJSON[:end], " \t\r\n")
+
+	separator := ", "
+	if match := firstKeyIndent.FindSubmatch(packageJSON); match != nil {
+		newline := "\n"
+		if bytes.Contains(packageJSON, []byte("\r\n")) {
+			newline = "\r\n"
+		}
+		separator = "," + newline + string(match[1])
+	}
+	if len(fields) == 0 {
+		separator = strings.TrimPrefix(separator, ",")
+	}
+
+	embedded := make([]byte, 0, len(packageJSON)+len(sha)+20)
+	embedded = append(embedded, body...)
+	embedded = append(embedded, separator...)
+	embedded = append(embedded, `"gitHead": "`+sha+`"`...)
+	embedded = append(embedded, packageJSON[len(body):]...)
+	return embedded
+}
+
+// TarballFilename returns the npm-style tarball name for a package, flattening
+// scoped names so "@homa/analytics" becomes "homa-analytics-1.0.0.tgz"
+func TarballFilename(name, version string) string {
+	if strings.HasPrefix(name, "@") {
+		name = strings.Replace(strings.TrimPrefix(name, "@"), "/", "-", 1)
+	}
+	return fmt.Sprintf("%s-%s.tgz", name, version)
+}
+
 func IsValidPackageNameForFilename(name string) bool {
 	if len(name) == 0 || len(name) > 214 {
 		return false
@@ -142,3 +142,29 @@ func TestJSONOutput(t *testing.T) {
 
 	t.Log("JSON output test passed")
 }
+
+func TestEmbedGitHead(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"indented", "{\n  \"name\": \"com.test.pkg\"\n}\n", "{\n  \"name\": \"com.test.pkg\",\n  \"gitHead\": \"" + sha + "\"\n}\n"},
+		{"tabs and CRLF", "{\r\n\t\"name\": \"com.test.pkg\"\r\n}", "{\r\n\t\"name\": \"com.test.pkg\",\r\n\t\"gitHead\": \"" + sha + "\"\r\n}"},
+		{"single line", `{"name": "com.test.pkg"}`, `{"name": "com.test.pkg", "gitHead": "` + sha + `"}`},
+		{"already recorded", `{"name": "com.test.pkg", "gitHead": "abc"}`, `{"name": "com.test.pkg", "gitHead": "abc"}`},
+		{"invalid", `{"name": `, `{"name": `},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embedded := string(EmbedGitHead([]byte(tt.input), sha))
+			if embedded != tt.expected {
+				t.Errorf("EmbedGitHead() = %q, want %q", embedded, tt.expected)
+			}
+			if tt.name != "invalid" && !json.Valid([]byte(embedded)) {
+				t.Errorf("EmbedGitHead() produced invalid JSON: %s", embedded)
+			}
+		})
+	}
+}
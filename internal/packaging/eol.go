@@ -0,0 +1,111 @@
+package packaging
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// binarySniffLength is how much of a file is checked for NUL bytes, the same
+// heuristic git uses to tell binary from text
+const binarySniffLength = 8000
+
+// LineEndings decides which packed files have their CRLF line endings
+// converted to LF. Files on disk are never changed.
+type LineEndings struct {
+	normalizeAll bool
+	rules        []attributeRule
+}
+
+// attributeRule is one .gitattributes line that affects line endings. text is
+// true for text, text=auto and eol=lf, false for -text, binary and eol=crlf.
+type attributeRule struct {
+	pattern string
+	text    bool
+}
+
+// NewLineEndings reads the .gitattributes in the package root. Without
+// normalizeAll only the files it marks as text are converted; with it every
+// text file is, except those .gitattributes marks binary or eol=crlf.
+func NewLineEndings(rootDir string, normalizeAll bool) (*LineEndings, error) {
+	l := &LineEndings{normalizeAll: normalizeAll}
+
+	file, err := os.Open(filepath.Join(rootDir, ".gitattributes")) // #nosec G304 - fixed file name in the package root
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .gitattributes: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		// The last attribute that mentions line endings wins, as in git
+		for _, attribute := range fields[1:] {
+			switch attribute {
+			case "text", "text=auto", "eol=lf":
+				l.rules = append(l.rules, attributeRule{pattern: fields[0], text: true})
+			case "-text", "binary", "eol=crlf":
+				l.rules = append(l.rules, attributeRule{pattern: fields[0], text: false})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read .gitattributes: %w", err)
+	}
+	return l, nil
+}
+
+// Apply returns data as it should be packed at relativePath: with CRLF
+// converted to LF when the file is text and selected for conversion, and
+// unchanged otherwise
+func (l *LineEndings) Apply(relativePath string, data []byte) []byte {
+	if l == nil || !l.selects(filepath.ToSlash(relativePath)) || isBinary(data) {
+		return data
+	}
+	return bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+}
+
+// selects reports whether a path's line endings are normalized. Later
+// .gitattributes lines override earlier ones.
+func (l *LineEndings) selects(relativePath string) bool {
+	selected := l.normalizeAll
+	for _, rule := range l.rules {
+		if rule.matches(relativePath) {
+			selected = rule.text
+		}
+	}
+	return selected
+}
+
+// matches follows .gitattributes pattern rules: a pattern without a slash
+// matches the file name at any depth, one with a slash the whole path
+func (r attributeRule) matches(relativePath string) bool {
+	pattern := strings.TrimPrefix(r.pattern, "/")
+	if !strings.Contains(r.pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(relativePath))
+		return matched
+	}
+	if strings.HasSuffix(pattern, "/**") {
+		return strings.HasPrefix(relativePath, strings.TrimSuffix(pattern, "**"))
+	}
+	matched, _ := path.Match(pattern, relativePath)
+	return matched
+}
+
+func isBinary(data []byte) bool {
+	if len(data) > binarySniffLength {
+		data = data[:binarySniffLength]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
@@ -0,0 +1,166 @@
+package packaging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// WorkspaceProtocol prefixes dependency ranges that point at a sibling
+// package in the same workspace, as in "workspace:^" or "workspace:*"
+const WorkspaceProtocol = "workspace:"
+
+// Workspace is a monorepo whose root package.json lists its member packages
+// in "workspaces", npm and yarn style
+type Workspace struct {
+	Root string
+	// Packages maps member package names to their directories and versions
+	Packages map[string]WorkspacePackage
+}
+
+// WorkspacePackage is one member of a workspace
+type WorkspacePackage struct {
+	Name    string
+	Version string
+	Dir     string
+}
+
+// FindWorkspace looks for the workspace dir belongs to, checking dir and
+// then each parent for a package.json with "workspaces". It returns nil when
+// there is none.
+func FindWorkspace(dir string) (*Workspace, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	for {
+		patterns, err := readWorkspacePatterns(filepath.Join(dir, "package.json"))
+		if err != nil {
+			return nil, err
+		}
+		if patterns != nil {
+			return loadWorkspace(dir, patterns)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// readWorkspacePatterns returns the member globs of a package.json, which
+// may be a list or, as in yarn, {"packages": [...]}. It returns nil when the
+// file is missing or has no workspaces.
+func readWorkspacePatterns(packageJSONPath string) ([]string, error) {
+	data, err := os.ReadFile(packageJSONPath) // #nosec G304 - package.json in the package directory or one of its parents
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", packageJSONPath, err)
+	}
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil || len(pkg.Workspaces) == 0 {
+		return nil, nil
+	}
+	var patterns []string
+	if json.Unmarshal(pkg.Workspaces, &patterns) != nil {
+		var nested struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(pkg.Workspaces, &nested); err != nil {
+			return nil, fmt.Errorf("invalid workspaces in %s: %w", packageJSONPath, err)
+		}
+		patterns = nested.Packages
+	}
+	if patterns == nil {
+		patterns = []string{}
+	}
+	return patterns, nil
+}
+
+func loadWorkspace(root string, patterns []string) (*Workspace, error) {
+	workspace := &Workspace{Root: root, Packages: make(map[string]WorkspacePackage)}
+	for _, pattern := range patterns {
+		dirs, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace pattern %q: %w", pattern, err)
+		}
+		for _, dir := range dirs {
+			data, err := os.ReadFile(filepath.Join(dir, "package.json")) // #nosec G304 - member directory matched under the workspace root
+			if err != nil {
+				continue
+			}
+			var member PackageInfo
+			if json.Unmarshal(data, &member) != nil || member.Name == "" {
+				continue
+			}
+			workspace.Packages[member.Name] = WorkspacePackage{Name: member.Name, Version: member.Version, Dir: dir}
+		}
+	}
+	return workspace, nil
+}
+
+// ResolveRange turns a workspace: range into the range to publish:
+// "workspace:*" becomes the sibling's exact version, "workspace:^" and
+// "workspace:~" that version with the operator, and anything else, such as
+// "workspace:^1.2.0", drops the protocol.
+func (w *Workspace) ResolveRange(name, versionRange string) (string, error) {
+	rest, ok := strings.CutPrefix(versionRange, WorkspaceProtocol)
+	if !ok {
+		return versionRange, nil
+	}
+	member, found := w.Packages[name]
+	if !found {
+		return "", fmt.Errorf("dependency %s is %s, but no package in the workspace at %s is named %s", name, versionRange, w.Root, name)
+	}
+	switch rest {
+	case "*", "":
+		return member.Version, nil
+	case "^", "~":
+		return rest + member.Version, nil
+	}
+	return rest, nil
+}
+
+// workspaceDependency matches a "name": "workspace:..." entry in package.json
+var workspaceDependency = regexp.MustCompile(`"((?:[^"\\]|\\.)+)"(\s*:\s*)"` + WorkspaceProtocol + `([^"]*)"`)
+
+// RewriteWorkspaceDependencies replaces the workspace: ranges in a
+// package.json with the versions they resolve to, keeping its formatting.
+// dir is the package directory, used to find the workspace. Files without
+// workspace: ranges come back unchanged.
+func RewriteWorkspaceDependencies(packageJSON []byte, dir string) ([]byte, error) {
+	if !workspaceDependency.Match(packageJSON) {
+		return packageJSON, nil
+	}
+	workspace, err := FindWorkspace(dir)
+	if err != nil {
+		return nil, err
+	}
+	if workspace == nil {
+		return nil, fmt.Errorf("package.json uses the %s protocol, but %s is not inside a workspace (no parent package.json lists \"workspaces\")", WorkspaceProtocol, dir)
+	}
+
+	var resolveErr error
+	rewritten := workspaceDependency.ReplaceAllFunc(packageJSON, func(match []byte) []byte {
+		parts := workspaceDependency.FindSubmatch(match)
+		resolved, err := workspace.ResolveRange(string(parts[1]), WorkspaceProtocol+string(parts[3]))
+		if err != nil {
+			resolveErr = errors.Join(resolveErr, err)
+			return match
+		}
+		return []byte(`"` + string(parts[1]) + `"` + string(parts[2]) + `"` + resolved + `"`)
+	})
+	if resolveErr != nil {
+		return nil, resolveErr
+	}
+	return rewritten, nil
+}
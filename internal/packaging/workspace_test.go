@@ -0,0 +1,62 @@
+package packaging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkspaceResolveRange(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"package.json":               `{"name": "studio", "workspaces": {"packages": ["packages/*"]}}`,
+		"packages/core/package.json": `{"name": "com.studio.core", "version": "2.3.1"}`,
+		"packages/ui/package.json":   `{"name": "com.studio.ui", "version": "1.0.0"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	workspace, err := FindWorkspace(filepath.Join(root, "packages", "ui"))
+	if err != nil || workspace == nil {
+		t.Fatalf("expected a workspace, got %v, %v", workspace, err)
+	}
+	if workspace.Root != root {
+		t.Errorf("expected root %s, got %s", root, workspace.Root)
+	}
+
+	tests := []struct {
+		versionRange string
+		want         string
+	}{
+		{"workspace:*", "2.3.1"},
+		{"workspace:^", "^2.3.1"},
+		{"workspace:~", "~2.3.1"},
+		{"workspace:^2.0.0", "^2.0.0"},
+		{"1.0.0", "1.0.0"},
+	}
+	for _, tt := range tests {
+		got, err := workspace.ResolveRange("com.studio.core", tt.versionRange)
+		if err != nil || got != tt.want {
+			t.Errorf("ResolveRange(%q) = %q, %v; want %q", tt.versionRange, got, err, tt.want)
+		}
+	}
+
+	if _, err := workspace.ResolveRange("com.studio.gone", "workspace:*"); err == nil {
+		t.Error("expected an error for a package outside the workspace")
+	}
+
+	rewritten, err := RewriteWorkspaceDependencies([]byte("{\n  \"dependencies\": {\n    \"com.studio.core\":  \"workspace:^\"\n  }\n}\n"), filepath.Join(root, "packages", "ui"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n  \"dependencies\": {\n    \"com.studio.core\":  \"^2.3.1\"\n  }\n}\n"; string(rewritten) != want {
+		t.Errorf("expected formatting to be kept, got:\n%s", rewritten)
+	}
+}
@@ -0,0 +1,292 @@
+// Package store keeps package tarballs in a content-addressable directory
+// shared by every project on a machine, so a package several projects use is
+// downloaded and kept on disk once.
+package store
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Store is a directory of tarballs named by their sha512 digest, along with
+// a record of the projects that use each one
+type Store struct {
+	dir string
+}
+
+// projectRecord lists the tarballs one project uses
+type projectRecord struct {
+	Project  string   `json:"project"`
+	Tarballs []string `json:"tarballs"`
+}
+
+// GCResult is what GC removed, or would remove on a dry run
+type GCResult struct {
+	// Removed are the integrities of the tarballs no project uses
+	Removed []string `json:"removed"`
+	// Freed is the size of the removed tarballs, in bytes
+	Freed int64 `json:"freed"`
+	// Forgotten are the recorded projects that no longer exist
+	Forgotten []string `json:"forgotten"`
+}
+
+// New returns the store kept in dir. The directory is created on first use.
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir returns the directory the store is kept in
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Key returns the hex sha512 digest the store files a tarball under, or ""
+// for an integrity that isn't a sha512 one
+func Key(integrity string) string {
+	digest, ok := strings.CutPrefix(integrity, "sha512-")
+	if !ok {
+		return ""
+	}
+	raw, err := base64.StdEncoding.DecodeString(digest)
+	if err != nil || len(raw) != sha512.Size {
+		return ""
+	}
+	return hex.EncodeToString(raw)
+}
+
+// integrityOf is the inverse of Key
+func integrityOf(key string) string {
+	raw, _ := hex.DecodeString(key)
+	return "sha512-" + base64.StdEncoding.EncodeToString(raw)
+}
+
+func (s *Store) entryPath(key string) string {
+	return filepath.Join(s.dir, "sha512", key[:2], key+".tgz")
+}
+
+func (s *Store) projectsDir() string {
+	return filepath.Join(s.dir, "projects")
+}
+
+// projectPath returns where the record of project is kept
+func (s *Store) projectPath(project string) string {
+	sum := sha256.Sum256([]byte(project))
+	return filepath.Join(s.projectsDir(), hex.EncodeToString(sum[:8])+".json")
+}
+
+// Get links the tarball with integrity to dest, copying it where links
+// aren't possible, and reports whether the store had it. An entry that no
+// longer matches its digest is removed rather than returned.
+func (s *Store) Get(integrity, dest string) (bool, error) {
+	key := Key(integrity)
+	if key == "" {
+		return false, nil
+	}
+	path := s.entryPath(key)
+	actual, err := fileKey(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if actual != key {
+		_ = os.Remove(path)
+		return false, nil
+	}
+	if err := linkOrCopy(path, dest); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Add files the tarball at path under integrity, which the caller has
+// checked it matches. A tarball already in the store is left as it is.
+func (s *Store) Add(path, integrity string) error {
+	key := Key(integrity)
+	if key == "" {
+		return fmt.Errorf("the store needs a sha512 integrity, got %q", integrity)
+	}
+	entry := s.entryPath(key)
+	if _, err := os.Stat(entry); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(entry), 0750); err != nil {
+		return fmt.Errorf("failed to create store directory: %w", err)
+	}
+	// Linked or copied under a temporary name first, so a concurrent Get
+	// never sees a partial entry
+	temp := fmt.Sprintf("%s.%d.tmp", entry, os.Getpid())
+	if err := linkOrCopy(path, temp); err != nil {
+		return err
+	}
+	if err := os.Rename(temp, entry); err != nil {
+		_ = os.Remove(temp)
+		return fmt.Errorf("failed to add tarball to the store: %w", err)
+	}
+	return nil
+}
+
+// Use records that project uses the tarball with integrity, so GC keeps it
+// for as long as the project exists
+func (s *Store) Use(project, integrity string) error {
+	key := Key(integrity)
+	if key == "" {
+		return nil
+	}
+	recordPath := s.projectPath(project)
+	record, err := readProjectRecord(recordPath)
+	if err != nil {
+		return err
+	}
+	record.Project = project
+	if slices.Contains(record.Tarballs, key) {
+		return nil
+	}
+	record.Tarballs = append(record.Tarballs, key)
+	slices.Sort(record.Tarballs)
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(recordPath), 0750); err != nil {
+		return fmt.Errorf("failed to create store directory: %w", err)
+	}
+	temp := fmt.Sprintf("%s.%d.tmp", recordPath, os.Getpid())
+	if err := os.WriteFile(temp, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to record the project's tarballs: %w", err)
+	}
+	if err := os.Rename(temp, recordPath); err != nil {
+		_ = os.Remove(temp)
+		return fmt.Errorf("failed to record the project's tarballs: %w", err)
+	}
+	return nil
+}
+
+// GC removes the tarballs no existing project uses, forgetting the projects
+// that no longer exist. A dry run only reports what it would remove.
+func (s *Store) GC(dryRun bool) (*GCResult, error) {
+	result := &GCResult{Removed: []string{}, Forgotten: []string{}}
+	used := make(map[string]bool)
+
+	records, err := os.ReadDir(s.projectsDir())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read the store: %w", err)
+	}
+	for _, entry := range records {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		recordPath := filepath.Join(s.projectsDir(), entry.Name())
+		record, err := readProjectRecord(recordPath)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(record.Project); record.Project == "" || errors.Is(err, os.ErrNotExist) {
+			result.Forgotten = append(result.Forgotten, record.Project)
+			if !dryRun {
+				if err := os.Remove(recordPath); err != nil {
+					return nil, fmt.Errorf("failed to forget %s: %w", record.Project, err)
+				}
+			}
+			continue
+		}
+		for _, key := range record.Tarballs {
+			used[key] = true
+		}
+	}
+
+	err = filepath.WalkDir(filepath.Join(s.dir, "sha512"), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		key, ok := strings.CutSuffix(d.Name(), ".tgz")
+		if d.IsDir() || !ok || used[key] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		result.Removed = append(result.Removed, integrityOf(key))
+		result.Freed += info.Size()
+		if dryRun {
+			return nil
+		}
+		return os.Remove(path)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to clean the store: %w", err)
+	}
+	return result, nil
+}
+
+// readProjectRecord reads a project record, returning an empty one when
+// there is none yet
+func readProjectRecord(path string) (*projectRecord, error) {
+	record := &projectRecord{}
+	data, err := os.ReadFile(path) // #nosec G304 - record inside the store directory
+	if errors.Is(err, os.ErrNotExist) {
+		return record, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return record, nil
+}
+
+// fileKey returns the hex sha512 digest of a file
+func fileKey(path string) (string, error) {
+	file, err := os.Open(path) // #nosec G304 - entry inside the store directory
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+	hasher := sha512.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// linkOrCopy hard-links src to dst, copying it when the two are on different
+// file systems or the file system has no hard links. dst is replaced.
+func linkOrCopy(src, dst string) error {
+	_ = os.Remove(dst)
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src) // #nosec G304 - paths chosen by the store and its callers
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600) // #nosec G304 - paths chosen by the store and its callers
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTarball(t *testing.T, content string) (string, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "package.tgz")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	sum := sha512.Sum512([]byte(content))
+	return path, "sha512-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestKey(t *testing.T) {
+	_, integrity := writeTarball(t, "tarball")
+	assert.Len(t, Key(integrity), 128)
+	assert.Equal(t, integrity, integrityOf(Key(integrity)))
+	assert.Empty(t, Key("sha1-2jmj7l5rSw0yVb/vlWAYkK/YBwk="))
+	assert.Empty(t, Key("sha512-not base64"))
+	assert.Empty(t, Key(""))
+}
+
+func TestStoreAddGet(t *testing.T) {
+	s := New(t.TempDir())
+	path, integrity := writeTarball(t, "tarball")
+	dest := filepath.Join(t.TempDir(), "copy.tgz")
+
+	found, err := s.Get(integrity, dest)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, s.Add(path, integrity))
+	require.NoError(t, s.Add(path, integrity), "adding a stored tarball again is a no-op")
+	found, err = s.Get(integrity, dest)
+	require.NoError(t, err)
+	assert.True(t, found)
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "tarball", string(data))
+
+	t.Run("corrupt entry", func(t *testing.T) {
+		entry := s.entryPath(Key(integrity))
+		require.NoError(t, os.Remove(entry))
+		require.NoError(t, os.WriteFile(entry, []byte("damaged"), 0600))
+
+		found, err := s.Get(integrity, filepath.Join(t.TempDir(), "copy.tgz"))
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.NoFileExists(t, entry)
+	})
+
+	t.Run("not sha512", func(t *testing.T) {
+		assert.Error(t, s.Add(path, "sha1-2jmj7l5rSw0yVb/vlWAYkK/YBwk="))
+	})
+}
+
+func TestStoreGC(t *testing.T) {
+	s := New(t.TempDir())
+	usedPath, used := writeTarball(t, "used")
+	unusedPath, unused := writeTarball(t, "unused")
+	require.NoError(t, s.Add(usedPath, used))
+	require.NoError(t, s.Add(unusedPath, unused))
+
+	project := t.TempDir()
+	require.NoError(t, s.Use(project, used))
+	require.NoError(t, s.Use(project, used))
+	gone := filepath.Join(t.TempDir(), "deleted")
+	require.NoError(t, s.Use(gone, unused))
+
+	result, err := s.GC(true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{unused}, result.Removed)
+	assert.Equal(t, []string{gone}, result.Forgotten)
+	assert.FileExists(t, s.entryPath(Key(unused)), "a dry run removes nothing")
+
+	result, err = s.GC(false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{unused}, result.Removed)
+	assert.Equal(t, int64(len("unused")), result.Freed)
+	assert.NoFileExists(t, s.entryPath(Key(unused)))
+	assert.FileExists(t, s.entryPath(Key(used)))
+
+	result, err = s.GC(false)
+	require.NoError(t, err)
+	assert.Empty(t, result.Removed)
+	assert.Empty(t, result.Forgotten)
+}
@@ -3,6 +3,7 @@ package styling
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 const (
@@ -70,6 +71,9 @@ func init() {
 }
 
 func Colorize(color, text string) string {
+	if Plain {
+		return plainText(text)
+	}
 	if NoColor {
 		return text
 	}
@@ -93,18 +97,30 @@ func MakeUnderline(text string) string {
 }
 
 func Success(text string) string {
+	if Plain {
+		return plainMessage(levelInfo, text)
+	}
 	return Colorize(BrightGreen, text)
 }
 
 func Error(text string) string {
+	if Plain {
+		return plainMessage(levelError, text)
+	}
 	return Colorize(BrightRed, text)
 }
 
 func Warning(text string) string {
+	if Plain {
+		return plainMessage(levelWarn, text)
+	}
 	return Colorize(BrightYellow, text)
 }
 
 func Info(text string) string {
+	if Plain {
+		return plainMessage(levelInfo, text)
+	}
 	return Colorize(BrightBlue, text)
 }
 
@@ -141,6 +157,9 @@ func Hash(text string) string {
 }
 
 func Separator() string {
+	if Plain {
+		return strings.Repeat("-", 51)
+	}
 	return Colorize(Dim, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 }
 
@@ -169,6 +188,12 @@ func URL(text string) string {
 }
 
 func Status(text string, isSuccess bool) string {
+	if Plain && isSuccess {
+		return plainMessage(levelInfo, "✓ "+text)
+	}
+	if Plain {
+		return plainMessage(levelError, "✗ "+text)
+	}
 	if isSuccess {
 		return Colorize(BrightGreen, "✓ "+text)
 	}
@@ -177,12 +202,16 @@ func Status(text string, isSuccess bool) string {
 
 func Progress(current, total int) string {
 	percentage := float64(current) / float64(total) * 100
+	filled, empty := "█", "░"
+	if Plain {
+		filled, empty = "#", "-"
+	}
 	bar := "["
 	for i := 0; i < 20; i++ {
 		if float64(i)/20*100 <= percentage {
-			bar += "█"
+			bar += filled
 		} else {
-			bar += "░"
+			bar += empty
 		}
 	}
 	bar += "]"
@@ -190,6 +219,9 @@ func Progress(current, total int) string {
 }
 
 func Hint(text string) string {
+	if Plain {
+		return plainMessage(levelInfo, "💡 "+text)
+	}
 	return Colorize(Dim+Italic, fmt.Sprintf("💡 %s", text))
 }
 
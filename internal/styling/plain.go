@@ -0,0 +1,81 @@
+package styling
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Plain makes every style render as plain ASCII-friendly text for CI log
+// files: no ANSI escapes, no emoji or box drawing, and INFO:, WARN: and
+// ERROR: prefixes in place of the status symbols messages start with.
+var Plain = false
+
+// Level prefixes plain output starts messages with
+const (
+	levelInfo  = "INFO:"
+	levelWarn  = "WARN:"
+	levelError = "ERROR:"
+)
+
+// plainSymbols spells out the symbols that carry meaning mid-message
+var plainSymbols = strings.NewReplacer(
+	"✓", "ok",
+	"✗", "failed",
+	"→", "->",
+	"•", "-",
+	"…", "...",
+)
+
+// plainText renders text for Plain mode
+func plainText(text string) string {
+	if first := []rune(text + " ")[0]; isSymbol(first) {
+		// Drop the gap a leading emoji leaves, as in "📦  Package Created"
+		return strings.TrimLeft(stripSymbols(text), " ")
+	}
+	return stripSymbols(text)
+}
+
+// plainMessage renders a status message for Plain mode, replacing the symbol
+// or "Warning:"/"Error:" it starts with by the level prefix. Text without
+// one, such as a "+" in a diff, is only stripped.
+func plainMessage(level, text string) string {
+	rest, ok := cutStatusMarker(text)
+	if !ok {
+		return plainText(text)
+	}
+	rest = strings.TrimLeft(stripSymbols(rest), " ")
+	if rest == "" {
+		return level
+	}
+	return level + " " + rest
+}
+
+func cutStatusMarker(text string) (string, bool) {
+	for _, word := range []string{"Warning:", "Error:"} {
+		if rest, ok := strings.CutPrefix(text, word); ok {
+			return rest, true
+		}
+	}
+	first, size := utf8.DecodeRuneInString(text)
+	if isSymbol(first) || first == 'ℹ' {
+		return text[size:], true
+	}
+	return "", false
+}
+
+// stripSymbols removes emoji and other pictographs, keeping letters in any
+// script
+func stripSymbols(text string) string {
+	return strings.Map(func(r rune) rune {
+		if isSymbol(r) {
+			return -1
+		}
+		return r
+	}, plainSymbols.Replace(text))
+}
+
+func isSymbol(r rune) bool {
+	// U+FE0F and U+200D are the variation selector and joiner emoji use
+	return unicode.Is(unicode.So, r) || r == '\uFE0F' || r == '\u200D'
+}
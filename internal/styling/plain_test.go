@@ -0,0 +1,37 @@
+package styling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlain(t *testing.T) {
+	Plain = true
+	defer func() { Plain = false }()
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"success symbol", Success("✓ Installed"), "INFO: Installed"},
+		{"lone error symbol", Error("✗"), "ERROR:"},
+		{"warning symbol", Warning("⚠️ Version 1.0.0 has been yanked"), "WARN: Version 1.0.0 has been yanked"},
+		{"error word", Error("Error: not found"), "ERROR: not found"},
+		{"info symbol", Info("ℹ Already installed"), "INFO: Already installed"},
+		{"hint", Hint("Run 'gpm login' first"), "INFO: Run 'gpm login' first"},
+		{"status", Status("Uploaded", false), "ERROR: Uploaded"},
+		{"no marker", Success("+"), "+"},
+		{"header emoji", Header("📦  GPM Package Created"), "GPM Package Created"},
+		{"inline symbols", Muted("1.0.0 → 2.0.0 ✓"), "1.0.0 -> 2.0.0 ok"},
+		{"non-Latin text kept", Value("パッケージ"), "パッケージ"},
+		{"separator", Separator(), "---------------------------------------------------"},
+		{"progress", Progress(1, 2), "[###########---------] 50.0%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.got)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Author is a package.json person. package.json accepts either the string
+// form "Name <email> (url)" or an object with name, email, and url; both
+// decode into this struct and it always encodes as the object form.
+type Author struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// ParseAuthor parses the npm string form "Name <email> (url)". Email and url
+// are optional.
+func ParseAuthor(s string) Author {
+	var author Author
+	rest := strings.TrimSpace(s)
+
+	if start := strings.Index(rest, "("); start >= 0 {
+		if end := strings.Index(rest[start:], ")"); end > 0 {
+			author.URL = strings.TrimSpace(rest[start+1 : start+end])
+			rest = rest[:start] + rest[start+end+1:]
+		}
+	}
+	if start := strings.Index(rest, "<"); start >= 0 {
+		if end := strings.Index(rest[start:], ">"); end > 0 {
+			author.Email = strings.TrimSpace(rest[start+1 : start+end])
+			rest = rest[:start] + rest[start+end+1:]
+		}
+	}
+	author.Name = strings.TrimSpace(rest)
+
+	return author
+}
+
+// AuthorFromValue normalizes a decoded JSON author (string or object), as
+// found in registry metadata, into an Author. It returns nil when the value
+// holds no author.
+func AuthorFromValue(value any) *Author {
+	var author Author
+	switch v := value.(type) {
+	case string:
+		author = ParseAuthor(v)
+	case map[string]any:
+		author.Name, _ = v["name"].(string)
+		author.Email, _ = v["email"].(string)
+		author.URL, _ = v["url"].(string)
+	default:
+		return nil
+	}
+	if author.IsZero() {
+		return nil
+	}
+	return &author
+}
+
+// UnmarshalJSON accepts both the string and the object form
+func (a *Author) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*a = ParseAuthor(s)
+		return nil
+	}
+
+	type authorObject Author
+	var obj authorObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("author must be a string or an object with name, email, and url: %w", err)
+	}
+	*a = Author(obj)
+	return nil
+}
+
+// IsZero reports whether no author field is set
+func (a Author) IsZero() bool {
+	return a.Name == "" && a.Email == "" && a.URL == ""
+}
+
+// String formats the author in the npm string form
+func (a Author) String() string {
+	parts := []string{}
+	if a.Name != "" {
+		parts = append(parts, a.Name)
+	}
+	if a.Email != "" {
+		parts = append(parts, "<"+a.Email+">")
+	}
+	if a.URL != "" {
+		parts = append(parts, "("+a.URL+")")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,69 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAuthor(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Author
+	}{
+		{"Jane Doe <jane@homa.io> (https://homa.io)", Author{Name: "Jane Doe", Email: "jane@homa.io", URL: "https://homa.io"}},
+		{"Jane Doe <jane@homa.io>", Author{Name: "Jane Doe", Email: "jane@homa.io"}},
+		{"Jane Doe (https://homa.io)", Author{Name: "Jane Doe", URL: "https://homa.io"}},
+		{"Jane Doe", Author{Name: "Jane Doe"}},
+		{"  <jane@homa.io>  ", Author{Email: "jane@homa.io"}},
+		{"", Author{}},
+	}
+
+	for _, tt := range tests {
+		got := ParseAuthor(tt.input)
+		if got != tt.want {
+			t.Errorf("ParseAuthor(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+
+	full := Author{Name: "Jane Doe", Email: "jane@homa.io", URL: "https://homa.io"}
+	if got := ParseAuthor(full.String()); got != full {
+		t.Errorf("String() did not round-trip: got %+v", got)
+	}
+}
+
+func TestAuthorJSON(t *testing.T) {
+	var pkg PackageJSON
+	if err := json.Unmarshal([]byte(`{"name": "x", "author": "Jane Doe <jane@homa.io>"}`), &pkg); err != nil {
+		t.Fatalf("failed to decode string author: %v", err)
+	}
+	if pkg.Author == nil || pkg.Author.Name != "Jane Doe" || pkg.Author.Email != "jane@homa.io" {
+		t.Errorf("unexpected string author: %+v", pkg.Author)
+	}
+
+	object := `{"name":"Jane Doe","email":"jane@homa.io","url":"https://homa.io"}`
+	var author Author
+	if err := json.Unmarshal([]byte(object), &author); err != nil {
+		t.Fatalf("failed to decode object author: %v", err)
+	}
+	data, err := json.Marshal(author)
+	if err != nil {
+		t.Fatalf("failed to encode author: %v", err)
+	}
+	if string(data) != object {
+		t.Errorf("object form did not round-trip: got %s, want %s", data, object)
+	}
+
+	if err := json.Unmarshal([]byte(`42`), &author); err == nil {
+		t.Errorf("expected an error for a numeric author")
+	}
+
+	if got := AuthorFromValue(map[string]any{"name": "Jane Doe"}); got == nil || got.Name != "Jane Doe" {
+		t.Errorf("AuthorFromValue(object) = %+v", got)
+	}
+	if got := AuthorFromValue("Jane Doe <jane@homa.io>"); got == nil || got.Email != "jane@homa.io" {
+		t.Errorf("AuthorFromValue(string) = %+v", got)
+	}
+	if got := AuthorFromValue(nil); got != nil {
+		t.Errorf("AuthorFromValue(nil) = %+v, want nil", got)
+	}
+}
@@ -25,7 +25,7 @@ type PackageJSON struct {
 	Name         string            `json:"name"`
 	Version      string            `json:"version"`
 	Description  string            `json:"description,omitempty"`
-	Author       string            `json:"author,omitempty"`
+	Author       *Author           `json:"author,omitempty"`
 	License      string            `json:"license,omitempty"`
 	Repository   string            `json:"repository,omitempty"`
 	Homepage     string            `json:"homepage,omitempty"`
@@ -36,6 +36,16 @@ type PackageJSON struct {
 	Unity        string            `json:"unity,omitempty"`
 	DisplayName  string            `json:"displayName,omitempty"`
 	Category     string            `json:"category,omitempty"`
+	// PublishConfig overrides publish defaults; CLI flags still win
+	PublishConfig *PublishConfig `json:"publishConfig,omitempty"`
+}
+
+// PublishConfig is npm's publishConfig: the registry, access level, and
+// dist-tag a package publishes with when no flag says otherwise
+type PublishConfig struct {
+	Registry string `json:"registry,omitempty"`
+	Access   string `json:"access,omitempty"`
+	Tag      string `json:"tag,omitempty"`
 }
 
 // Legacy ValidationResult for backward compatibility
@@ -189,7 +199,7 @@ func validateOptionalFields(result *PackageValidationResult) {
 		result.Warnings = append(result.Warnings, "package.json should include 'license' field")
 	}
 
-	if pkg.Author == "" {
+	if pkg.Author == nil || pkg.Author.Name == "" {
 		result.Warnings = append(result.Warnings, "package.json should include 'author' field")
 	}
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"gpm.sh/gpm/gpm-cli/cmd"
+	"gpm.sh/gpm/gpm-cli/internal/api"
 	"gpm.sh/gpm/gpm-cli/internal/config"
 	"gpm.sh/gpm/gpm-cli/internal/styling"
 )
@@ -32,8 +33,16 @@ Features:
 - Explicit visibility controls
 - Plan-based publishing permissions`,
 		Version: cmd.Version,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(c *cobra.Command, args []string) error {
 			setupLogging()
+			cmd.ConfigureOutput(c)
+			if err := cmd.ConfigureHeaders(); err != nil {
+				return err
+			}
+			return cmd.ChangeWorkingDir()
+		},
+		PersistentPostRun: func(c *cobra.Command, args []string) {
+			cmd.NotifyUpdate(c, Quiet, JSONOutput)
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
@@ -44,12 +53,32 @@ Features:
 	rootCmd.PersistentFlags().BoolVar(&Debug, "debug", false, "Enable debug output")
 	rootCmd.PersistentFlags().BoolVarP(&Quiet, "quiet", "q", false, "Suppress non-essential output")
 	rootCmd.PersistentFlags().BoolVar(&JSONOutput, "json", false, "Output in JSON format")
+	cmd.AddCwdFlag(rootCmd)
+	cmd.AddOutputFlags(rootCmd)
+	cmd.AddHeaderFlags(rootCmd)
 
 	config.InitConfig()
+	cmd.PrintConfigWarnings()
+	cmd.ConfigureUserAgent()
+	cmd.ConfigureAuthSchemes()
 
 	cmd.AddCommands(rootCmd)
+	if err := cmd.ApplyFlagDefaults(rootCmd); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", styling.Warning(fmt.Sprintf("Warning: ignoring flag defaults from config: %v", err)))
+	}
 
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := cmd.InterruptContext()
+	err := rootCmd.ExecuteContext(ctx)
+	interrupted := err != nil && cmd.Interrupted()
+	stop()
+	if interrupted {
+		// Deferred cleanups have run by now; 130 is the shell's code for Ctrl-C
+		if !Quiet {
+			fmt.Fprintf(os.Stderr, "%s\n", styling.Warning("Canceled"))
+		}
+		os.Exit(130)
+	}
+	if err != nil {
 		if !Quiet {
 			if JSONOutput {
 				fmt.Fprintf(os.Stderr, `{"error":{"message":"%s"}}`+"\n", err.Error())
@@ -67,4 +96,5 @@ func setupLogging() {
 	} else {
 		log.SetOutput(os.Stderr)
 	}
+	api.SetDebug(Debug)
 }
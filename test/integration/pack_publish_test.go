@@ -44,6 +44,9 @@ func (s *PackPublishSuite) SetupSuite() {
 		s.T().Skipf("Failed to build binary for integration tests: %v\nOutput: %s", err, output)
 	}
 
+	// Keep the built binary from reaching the release server
+	s.T().Setenv(config.NoUpdateCheckEnv, "1")
+
 	config.InitConfig()
 	cfg := config.GetConfig()
 	s.testServer = cfg.Registry
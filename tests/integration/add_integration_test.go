@@ -98,8 +98,7 @@ func TestAddCommand_Integration(t *testing.T) {
 			wantExitCode: 1,
 			wantContains: []string{
 				`"success": false`,
-				`failed to check package existence`,
-				`HTTP 404`,
+				`package 'com.nonexistent.package' not found in registry`,
 			},
 		},
 		{
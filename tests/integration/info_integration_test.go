@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"gpm.sh/gpm/gpm-cli/cmd"
+	"gpm.sh/gpm/gpm-cli/internal/config"
+)
+
+func TestInfoCommand_PrivatePackage(t *testing.T) {
+	registry := NewRegistryMock()
+	defer registry.Close()
+
+	registry.AddPackage(CreateTestPackage("com.private.package", "1.0.0", "private"))
+	registry.AddUser(&User{
+		Username: "testuser",
+		Email:    "test@example.com",
+		Token:    "test-token-123",
+	})
+
+	t.Run("authenticated info shows the package", func(t *testing.T) {
+		config.SetConfigForTesting(&config.Config{
+			Registry: registry.URL(),
+			Token:    "test-token-123",
+		})
+
+		output, err := executeInfo("com.private.package", "--json")
+		if err != nil {
+			t.Fatalf("expected info to succeed, got: %v", err)
+		}
+		if !strings.Contains(output, `"name": "com.private.package"`) {
+			t.Errorf("expected package document, got: %s", output)
+		}
+	})
+
+	t.Run("unauthenticated info reports the access error", func(t *testing.T) {
+		config.SetConfigForTesting(&config.Config{
+			Registry: registry.URL(),
+		})
+
+		_, err := executeInfo("com.private.package")
+		if err == nil {
+			t.Fatal("expected an error without credentials")
+		}
+		if !strings.Contains(err.Error(), "Authentication required") || !strings.Contains(err.Error(), "gpm login") {
+			t.Errorf("expected authentication required error, got: %v", err)
+		}
+		if strings.Contains(err.Error(), "Package not found") {
+			t.Errorf("a private package should not be reported as missing, got: %v", err)
+		}
+	})
+
+	t.Run("missing package is not an access error", func(t *testing.T) {
+		config.SetConfigForTesting(&config.Config{
+			Registry: registry.URL(),
+			Token:    "test-token-123",
+		})
+
+		_, err := executeInfo("com.private.missing")
+		if err == nil || !strings.Contains(err.Error(), "Package not found or no access") {
+			t.Errorf("expected not found or no access error, got: %v", err)
+		}
+	})
+}
+
+// executeInfo runs gpm info, returning what it printed to stdout and its
+// error; info writes to os.Stdout rather than the command's writer
+func executeInfo(args ...string) (string, error) {
+	rootCmd := &cobra.Command{Use: "gpm", SilenceUsage: true, SilenceErrors: true}
+	cmd.AddCommands(rootCmd)
+	rootCmd.SetArgs(append([]string{"info"}, args...))
+
+	originalStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	err := rootCmd.Execute()
+	_ = w.Close()
+	os.Stdout = originalStdout
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	return buf.String(), err
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 )
 
@@ -280,10 +281,19 @@ func (rm *RegistryMock) handleSearch(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Like real registries, return the page from and size ask for
+	total := len(results)
+	if from, err := strconv.Atoi(r.URL.Query().Get("from")); err == nil && from > 0 {
+		results = results[min(from, len(results)):]
+	}
+	if size, err := strconv.Atoi(r.URL.Query().Get("size")); err == nil && size >= 0 && size < len(results) {
+		results = results[:size]
+	}
+
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
 		"objects": results,
-		"total":   len(results),
+		"total":   total,
 		"time":    "Wed Oct 11 2023 12:34:56 GMT+0000 (UTC)",
 	})
 }